@@ -0,0 +1,355 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/account"
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/auth"
+	"github.com/szaher/vibeboard/backend/internal/lobby"
+	"github.com/szaher/vibeboard/backend/internal/password"
+)
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type OAuthLoginRequest struct {
+	Code         string `json:"code" binding:"required"`
+	RedirectURI  string `json:"redirect_uri"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+type LoginLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type LoginLinkLoginRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type VerifyLoginRequest struct {
+	ChallengeID string `json:"challenge_id" binding:"required"`
+	Code        string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type ChangeUsernameRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=20"`
+}
+
+// ChangePassword replaces the requesting user's password; the current
+// password is required.
+func (h *Handler) ChangePassword(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	if err := h.accounts.ChangePassword(userID, req.CurrentPassword, req.NewPassword); err != nil {
+		h.accountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed"})
+}
+
+// ChangeEmail mails a verification link to the new address; the email
+// changes once VerifyEmail is called with its token.
+func (h *Handler) ChangeEmail(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	if err := h.accounts.RequestEmailChange(userID, req.Password, req.Email); err != nil {
+		h.accountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Verification email sent"})
+}
+
+// VerifyEmail confirms a pending email change. It needs no login, as the
+// link may be opened on another device.
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	user, err := h.accounts.ConfirmEmailChange(req.Token)
+	if err != nil {
+		h.accountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// ChangeUsername renames the requesting user, at most once per cooldown.
+func (h *Handler) ChangeUsername(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var req ChangeUsernameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	user, err := h.accounts.ChangeUsername(userID, req.Username)
+	if err != nil {
+		h.accountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// DeleteAccount deletes the requesting user's account and takes them out of
+// matchmaking and the weekly leaderboards. Their past games stay, showing
+// a placeholder name.
+func (h *Handler) DeleteAccount(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var req DeleteAccountRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+
+	if err := h.accounts.DeleteAccount(userID, req.Password); err != nil {
+		h.accountError(c, err)
+		return
+	}
+
+	if err := h.matchmaker.CancelQueue(userID); err != nil {
+		log.Printf("Error removing deleted user %s from matchmaking: %v", userID, err)
+	}
+	if err := h.matchmaker.LeaveParty(userID); err != nil && !errors.Is(err, lobby.ErrPartyNotFound) {
+		log.Printf("Error removing deleted user %s from party: %v", userID, err)
+	}
+	if err := h.leaderboards.RemoveUser(userID); err != nil {
+		log.Printf("Error removing deleted user %s from leaderboards: %v", userID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted"})
+}
+
+// OAuthLogin signs in with an authorization code from the provider in the
+// path. The first login creates an account, or links one whose email the
+// provider has verified.
+func (h *Handler) OAuthLogin(c *gin.Context) {
+	var req OAuthLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	user, created, err := h.accounts.SignInWithOAuth(c.Request.Context(), c.Param("provider"), req.Code, req.RedirectURI, req.CodeVerifier)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrUnknownProvider):
+			apierror.Respond(c, http.StatusNotFound, "unknown_provider", "Unknown or disabled login provider")
+		case errors.Is(err, auth.ErrOAuthExchange), errors.Is(err, auth.ErrInvalidIDToken):
+			log.Printf("OAuth login failed: %v", err)
+			apierror.Respond(c, http.StatusUnauthorized, "oauth_failed", "Could not verify the sign-in with the provider")
+		case errors.Is(err, account.ErrEmailRequired):
+			apierror.Respond(c, http.StatusBadRequest, "email_required", "The provider did not share an email address")
+		case errors.Is(err, account.ErrAccountExists):
+			apierror.Respond(c, http.StatusConflict, "account_exists", "An account already uses this email; sign in with your password")
+		default:
+			log.Printf("OAuth login error: %v", err)
+			apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to sign in")
+		}
+		return
+	}
+
+	if !user.IsActive {
+		apierror.Respond(c, http.StatusUnauthorized, "account_disabled", "Account is disabled")
+		return
+	}
+	h.accounts.RecordLogin(user, h.accounts.NewLoginAttempt(c.Param("provider"), c.ClientIP(), c.Request.Header))
+
+	tokens, err := h.jwtManager.GenerateTokenPair(user.ID, user.Username)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to generate tokens")
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{
+		"user":    user,
+		"tokens":  tokens,
+		"created": created,
+	})
+}
+
+// RequestLoginLink mails a one-time login link. It always answers 202, so
+// it cannot be used to find out which emails have accounts.
+func (h *Handler) RequestLoginLink(c *gin.Context) {
+	var req LoginLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	if err := h.accounts.RequestLoginLink(req.Email); err != nil {
+		h.accountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If an account uses that email, a sign-in link has been sent"})
+}
+
+// LoginWithLink exchanges the token from a login link for a token pair.
+func (h *Handler) LoginWithLink(c *gin.Context) {
+	var req LoginLinkLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	user, err := h.accounts.RedeemLoginLink(req.Token)
+	if err != nil {
+		h.accountError(c, err)
+		return
+	}
+
+	if !user.IsActive {
+		apierror.Respond(c, http.StatusUnauthorized, "account_disabled", "Account is disabled")
+		return
+	}
+	h.accounts.RecordLogin(user, h.accounts.NewLoginAttempt(account.LoginMethodMagicLink, c.ClientIP(), c.Request.Header))
+
+	tokens, err := h.jwtManager.GenerateTokenPair(user.ID, user.Username)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to generate tokens")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":   user,
+		"tokens": tokens,
+	})
+}
+
+// VerifyLogin completes a password login that was challenged for coming
+// from a new device or location, using the code emailed to the user.
+func (h *Handler) VerifyLogin(c *gin.Context) {
+	var req VerifyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	user, err := h.accounts.VerifyLoginChallenge(req.ChallengeID, req.Code)
+	if err != nil {
+		h.accountError(c, err)
+		return
+	}
+
+	if !user.IsActive {
+		apierror.Respond(c, http.StatusUnauthorized, "account_disabled", "Account is disabled")
+		return
+	}
+
+	tokens, err := h.jwtManager.GenerateTokenPair(user.ID, user.Username)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to generate tokens")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":   user,
+		"tokens": tokens,
+	})
+}
+
+// GetLoginHistory lists the requesting user's recent logins, with the ones
+// flagged as suspicious marked.
+func (h *Handler) GetLoginHistory(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	logins, err := h.accounts.GetLoginHistory(userID, 50)
+	if err != nil {
+		log.Printf("Error getting login history for %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get login history")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"logins": logins})
+}
+
+// passwordPolicyError responds with each policy violation as an error on
+// the password field.
+func passwordPolicyError(c *gin.Context, field string, violations []password.Violation) {
+	fields := make([]apierror.FieldError, 0, len(violations))
+	for _, v := range violations {
+		fields = append(fields, apierror.FieldError{Field: field, Code: v.Code, Message: v.Message})
+	}
+	apierror.RespondFields(c, http.StatusBadRequest, apierror.CodeValidationFailed, "Password does not meet the password policy", fields)
+}
+
+func (h *Handler) accountError(c *gin.Context, err error) {
+	var cooldown *account.CooldownError
+	var weak *account.PolicyError
+	switch {
+	case errors.As(err, &weak):
+		passwordPolicyError(c, "new_password", weak.Violations)
+	case errors.As(err, &cooldown):
+		apierror.Respond(c, http.StatusTooManyRequests, "username_change_cooldown", "Username can be changed again after "+cooldown.Until.UTC().Format(time.RFC3339))
+	case errors.Is(err, account.ErrUserNotFound):
+		apierror.Respond(c, http.StatusNotFound, "user_not_found", "User not found")
+	case errors.Is(err, account.ErrIncorrectPassword):
+		apierror.Respond(c, http.StatusForbidden, "incorrect_password", "Current password is incorrect")
+	case errors.Is(err, account.ErrEmailTaken):
+		apierror.Respond(c, http.StatusConflict, "email_taken", "Email is already in use")
+	case errors.Is(err, account.ErrEmailUnchanged):
+		apierror.Respond(c, http.StatusBadRequest, "email_unchanged", "That is already your email")
+	case errors.Is(err, account.ErrUsernameTaken):
+		apierror.Respond(c, http.StatusConflict, "username_taken", "Username is already taken")
+	case errors.Is(err, account.ErrUsernameUnchanged):
+		apierror.Respond(c, http.StatusBadRequest, "username_unchanged", "That is already your username")
+	case errors.Is(err, account.ErrInvalidLoginLink):
+		apierror.Respond(c, http.StatusUnauthorized, "invalid_login_link", "Invalid or expired login link")
+	case errors.Is(err, account.ErrInvalidLoginChallenge):
+		apierror.Respond(c, http.StatusUnauthorized, "invalid_login_challenge", "Invalid or expired verification code")
+	case errors.Is(err, account.ErrInvalidVerification):
+		apierror.Respond(c, http.StatusBadRequest, "invalid_verification_token", "Invalid or expired verification token")
+	default:
+		log.Printf("Account error: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to update account")
+	}
+}
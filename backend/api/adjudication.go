@@ -0,0 +1,243 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/game"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+type AdjudicationRequest struct {
+	Reason  models.AdjudicationReason `json:"reason" binding:"required,oneof=stuck abuse wrong_result other"`
+	Details string                    `json:"details" binding:"max=1000"`
+}
+
+// ResolveAdjudicationRequest settles the game with a decision, or, with
+// dismiss, closes the request leaving the game as it is.
+type ResolveAdjudicationRequest struct {
+	Decision string     `json:"decision" binding:"required,oneof=winner draw void dismiss"`
+	WinnerID *uuid.UUID `json:"winner_id"`
+	Note     string     `json:"note" binding:"max=1000"`
+}
+
+// AdjudicationResponse is a request along with the game it is about.
+type AdjudicationResponse struct {
+	*models.Adjudication
+	Game *models.Game `json:"game"`
+}
+
+// RequestAdjudication asks moderators to settle a game the player is stuck
+// in or disputes the result of.
+func (h *Handler) RequestAdjudication(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
+		return
+	}
+
+	var req AdjudicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	gameRecord, err := h.db.GetGame(gameID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+	if !gameRecord.HasPlayer(userID) {
+		apierror.Respond(c, http.StatusForbidden, "not_in_game", "You are not playing this game")
+		return
+	}
+	if gameRecord.Status != models.GameStatusInProgress && gameRecord.Status != models.GameStatusCompleted {
+		apierror.Respond(c, http.StatusConflict, "game_not_adjudicable", "Only games in progress or completed can be adjudicated")
+		return
+	}
+
+	adjudication := &models.Adjudication{
+		ID:          uuid.New(),
+		GameID:      gameID,
+		RequesterID: userID,
+		Reason:      req.Reason,
+		Details:     req.Details,
+	}
+	created, err := h.db.CreateAdjudication(adjudication)
+	if err != nil {
+		log.Printf("Error creating adjudication request for game %s: %v", gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to request adjudication")
+		return
+	}
+	if !created {
+		apierror.Respond(c, http.StatusConflict, "adjudication_open", "Game already has an open adjudication request")
+		return
+	}
+
+	log.Printf("User %s asked for game %s to be adjudicated for %s", userID, gameID, req.Reason)
+	c.JSON(http.StatusCreated, adjudication)
+}
+
+// GetAdjudications lists adjudication requests for review, oldest first.
+// ?status defaults to open.
+func (h *Handler) GetAdjudications(c *gin.Context) {
+	status := models.AdjudicationStatus(c.DefaultQuery("status", string(models.AdjudicationStatusOpen)))
+	switch status {
+	case models.AdjudicationStatusOpen, models.AdjudicationStatusResolved, models.AdjudicationStatusDismissed:
+	default:
+		apierror.Respond(c, http.StatusBadRequest, "invalid_status", "Invalid status")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	adjudications, err := h.db.GetAdjudications(status, limit, offset)
+	if err != nil {
+		log.Printf("Error getting adjudication requests: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get adjudication requests")
+		return
+	}
+	if adjudications == nil {
+		adjudications = []*models.Adjudication{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"adjudications": adjudications})
+}
+
+// GetAdjudication returns a request with its game.
+func (h *Handler) GetAdjudication(c *gin.Context) {
+	adjudication, ok := h.loadAdjudication(c)
+	if !ok {
+		return
+	}
+
+	gameRecord, err := h.db.GetGame(adjudication.GameID)
+	if err != nil {
+		log.Printf("Error getting game %s: %v", adjudication.GameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get game")
+		return
+	}
+
+	c.JSON(http.StatusOK, AdjudicationResponse{Adjudication: adjudication, Game: gameRecord})
+}
+
+// ResolveAdjudication settles the request's game as the moderator decided,
+// adjusting the players' stats, and closes the request.
+func (h *Handler) ResolveAdjudication(c *gin.Context) {
+	adminID := c.MustGet("userID").(uuid.UUID)
+
+	var req ResolveAdjudicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+	decision := models.AdjudicationDecision(req.Decision)
+	if (decision == models.AdjudicationDecisionWinner) != (req.WinnerID != nil) {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_winner", "winner_id is required for, and only for, the winner decision")
+		return
+	}
+
+	adjudication, ok := h.loadAdjudication(c)
+	if !ok {
+		return
+	}
+	if adjudication.Status != models.AdjudicationStatusOpen {
+		apierror.Respond(c, http.StatusConflict, "adjudication_closed", "Adjudication request is already closed")
+		return
+	}
+
+	gameRecord, err := h.db.GetGame(adjudication.GameID)
+	if err != nil {
+		log.Printf("Error getting game %s: %v", adjudication.GameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get game")
+		return
+	}
+	before := newResultSnapshot(gameRecord)
+
+	adjudication.Status = models.AdjudicationStatusDismissed
+	if req.Decision != "dismiss" {
+		adjudication.Status = models.AdjudicationStatusResolved
+		adjudication.Decision = &decision
+		adjudication.WinnerID = req.WinnerID
+
+		gameRecord, err = h.hub.AdjudicateGame(adjudication.GameID, decision, req.WinnerID)
+		switch {
+		case errors.Is(err, game.ErrNotInGame):
+			apierror.Respond(c, http.StatusBadRequest, "invalid_winner", "Winner is not playing this game")
+			return
+		case errors.Is(err, game.ErrGameNotAdjudicable):
+			apierror.Respond(c, http.StatusConflict, "game_not_adjudicable", "Game is no longer in progress or completed")
+			return
+		case err != nil:
+			log.Printf("Error adjudicating game %s: %v", adjudication.GameID, err)
+			apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to adjudicate game")
+			return
+		}
+	}
+	adjudication.ResolvedBy = &adminID
+	adjudication.ResolutionNote = req.Note
+
+	resolved, err := h.db.ResolveAdjudication(adjudication)
+	if err != nil {
+		log.Printf("Error resolving adjudication request %s: %v", adjudication.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to resolve adjudication request")
+		return
+	}
+	if !resolved {
+		apierror.Respond(c, http.StatusConflict, "adjudication_closed", "Adjudication request is already closed")
+		return
+	}
+
+	log.Printf("Adjudication request %s for game %s %s by admin %s", adjudication.ID, adjudication.GameID, req.Decision, adminID)
+	h.audit(c, models.AuditActionAdjudicate, models.AuditTargetAdjudication, adjudication.ID.String(), req.Note,
+		before, newResultSnapshot(gameRecord))
+	c.JSON(http.StatusOK, AdjudicationResponse{Adjudication: adjudication, Game: gameRecord})
+}
+
+func (h *Handler) loadAdjudication(c *gin.Context) (*models.Adjudication, bool) {
+	adjudicationID, err := uuid.Parse(c.Param("adjudicationId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_adjudication_id", "Invalid adjudication ID")
+		return nil, false
+	}
+
+	adjudication, err := h.db.GetAdjudication(adjudicationID)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, "adjudication_not_found", "Adjudication request not found")
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error getting adjudication request %s: %v", adjudicationID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get adjudication request")
+		return nil, false
+	}
+	return adjudication, true
+}
+
+// resultSnapshot is the part of a game an adjudication can change, for the
+// audit log.
+type resultSnapshot struct {
+	GameID    uuid.UUID         `json:"game_id"`
+	Status    models.GameStatus `json:"status"`
+	WinnerID  *uuid.UUID        `json:"winner_id,omitempty"`
+	EndReason models.EndReason  `json:"end_reason,omitempty"`
+}
+
+func newResultSnapshot(game *models.Game) resultSnapshot {
+	return resultSnapshot{
+		GameID:    game.ID,
+		Status:    game.Status,
+		WinnerID:  game.WinnerID,
+		EndReason: game.EndReason,
+	}
+}
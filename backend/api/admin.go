@@ -0,0 +1,174 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/models"
+	"github.com/szaher/vibeboard/backend/internal/websocket"
+)
+
+type GameTypeStatus struct {
+	GameType models.GameType `json:"game_type"`
+	Enabled  bool            `json:"enabled"`
+}
+
+type SetGameTypeEnabledRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+type DisconnectClientRequest struct {
+	Reason string `json:"reason" binding:"max=200"`
+}
+
+type AnnouncementRequest struct {
+	Message string                      `json:"message" binding:"required,max=500"`
+	Level   websocket.AnnouncementLevel `json:"level" binding:"omitempty,oneof=info warning critical"`
+}
+
+func (h *Handler) GetGameTypes(c *gin.Context) {
+	types := h.registry.GetSupportedTypes()
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	statuses := make([]GameTypeStatus, 0, len(types))
+	for _, gameType := range types {
+		statuses = append(statuses, GameTypeStatus{
+			GameType: gameType,
+			Enabled:  h.registry.IsEnabled(gameType),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"game_types": statuses})
+}
+
+func (h *Handler) SetGameTypeEnabled(c *gin.Context) {
+	var req SetGameTypeEnabledRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	gameType := models.GameType(c.Param("gameType"))
+	if _, err := h.registry.GetEngine(gameType); err != nil {
+		apierror.Respond(c, http.StatusNotFound, "game_type_not_found", "Game type not found")
+		return
+	}
+	wasEnabled := h.registry.IsEnabled(gameType)
+	if err := h.registry.SetEnabled(gameType, *req.Enabled); err != nil {
+		log.Printf("Error setting game type %s enabled=%t: %v", gameType, *req.Enabled, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to update game type")
+		return
+	}
+
+	log.Printf("Game type %s enabled=%t by admin %v", gameType, *req.Enabled, c.MustGet("userID"))
+	h.audit(c, models.AuditActionGameTypeEnable, models.AuditTargetGameType, string(gameType), "",
+		gin.H{"enabled": wasEnabled}, gin.H{"enabled": *req.Enabled})
+
+	c.JSON(http.StatusOK, GameTypeStatus{
+		GameType: gameType,
+		Enabled:  *req.Enabled,
+	})
+}
+
+// Hub admin handlers. The hub only knows about connections on the instance
+// serving the request.
+func (h *Handler) GetHubRooms(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"rooms": h.hub.ListRooms()})
+}
+
+func (h *Handler) GetHubClients(c *gin.Context) {
+	var userID uuid.UUID
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		var err error
+		userID, err = uuid.Parse(userIDStr)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"clients": h.hub.ListClients(userID)})
+}
+
+func (h *Handler) GetHubClient(c *gin.Context) {
+	clientID, err := uuid.Parse(c.Param("clientId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_client_id", "Invalid client ID")
+		return
+	}
+
+	client, exists := h.hub.GetClient(clientID)
+	if !exists {
+		apierror.Respond(c, http.StatusNotFound, "client_not_found", "Client not connected to this instance")
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
+
+func (h *Handler) DisconnectHubClient(c *gin.Context) {
+	clientID, err := uuid.Parse(c.Param("clientId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_client_id", "Invalid client ID")
+		return
+	}
+
+	// The body is optional
+	var req DisconnectClientRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+
+	log.Printf("Client %s disconnect requested by admin %v", clientID, c.MustGet("userID"))
+	h.audit(c, models.AuditActionClientDisconnect, models.AuditTargetClient, clientID.String(), req.Reason, nil, nil)
+
+	if h.hub.DisconnectClient(clientID, req.Reason) {
+		c.JSON(http.StatusOK, gin.H{"message": "Client disconnected"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "Disconnect forwarded to other instances"})
+}
+
+func (h *Handler) CreateAnnouncement(c *gin.Context) {
+	var req AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	if req.Level == "" {
+		req.Level = websocket.AnnouncementLevelInfo
+	}
+
+	if err := h.hub.Announce(req.Message, req.Level); err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to send announcement")
+		return
+	}
+
+	log.Printf("Announcement (%s) sent by admin %v", req.Level, c.MustGet("userID"))
+	h.audit(c, models.AuditActionAnnouncement, models.AuditTargetAnnouncement, "", "", nil, req)
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Announcement sent"})
+}
+
+func (h *Handler) GetMatchmakingStats(c *gin.Context) {
+	stats, err := h.matchmaker.GetStats()
+	if err != nil {
+		log.Printf("Error getting matchmaking stats: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get matchmaking stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"game_types": stats,
+		"tolerance":  h.matchmaker.GetTolerancePolicy(),
+	})
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// audit records a privileged action taken by the requesting admin. before
+// and after are snapshots of the target and may be nil. The action has
+// already happened, so a failure to record it is only logged.
+func (h *Handler) audit(c *gin.Context, action models.AuditAction, targetType models.AuditTarget, targetID, reason string, before, after interface{}) {
+	entry := &models.AuditEntry{
+		ID:         uuid.New(),
+		ActorID:    c.MustGet("userID").(uuid.UUID),
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+		Before:     auditSnapshot(before),
+		After:      auditSnapshot(after),
+		IP:         c.ClientIP(),
+		RequestID:  c.GetString(apierror.RequestIDKey),
+	}
+	if err := h.db.CreateAuditEntry(entry); err != nil {
+		log.Printf("Error recording audit entry %s on %s %s by %s: %v", action, targetType, targetID, entry.ActorID, err)
+	}
+}
+
+func auditSnapshot(value interface{}) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Error encoding audit snapshot: %v", err)
+		return nil
+	}
+	return data
+}
+
+// GetAuditLog lists audit log entries, newest first, filtered by
+// ?actor_id, ?action, ?target_type, ?target_id and an RFC 3339 ?since and
+// ?until.
+func (h *Handler) GetAuditLog(c *gin.Context) {
+	filter := models.AuditFilter{
+		Action:     models.AuditAction(c.Query("action")),
+		TargetType: models.AuditTarget(c.Query("target_type")),
+		TargetID:   c.Query("target_id"),
+	}
+
+	if value := c.Query("actor_id"); value != "" {
+		actorID, err := uuid.Parse(value)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+			return
+		}
+		filter.ActorID = actorID
+	}
+
+	for param, dest := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid_time", "Invalid "+param+" time")
+			return
+		}
+		*dest = t
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	entries, err := h.db.GetAuditEntries(filter, limit, offset)
+	if err != nil {
+		log.Printf("Error getting audit log: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get audit log")
+		return
+	}
+	if entries == nil {
+		entries = []*models.AuditEntry{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/game"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+type CreateBotGameRequest struct {
+	Level       models.BotLevel `json:"level" binding:"required,oneof=easy medium hard"`
+	TimeControl string          `json:"time_control" binding:"max=32"`
+}
+
+// GetBots lists the chess bots to play against.
+func (h *Handler) GetBots(c *gin.Context) {
+	bots := h.bots.Bots()
+	if bots == nil {
+		bots = []*models.Bot{}
+	}
+	c.JSON(http.StatusOK, gin.H{"bots": bots})
+}
+
+// CreateBotGame starts a casual chess game against a bot. The game is in
+// progress at once; the bot moves as soon as it is its turn.
+func (h *Handler) CreateBotGame(c *gin.Context) {
+	playerID := c.MustGet("userID").(uuid.UUID)
+
+	var req CreateBotGameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	if !h.registry.IsEnabled(models.GameTypeChess) {
+		apierror.Respond(c, http.StatusServiceUnavailable, "game_type_disabled", "Game type is temporarily disabled")
+		return
+	}
+
+	settings := models.GameSettings{TimeControl: req.TimeControl}
+	created, err := h.bots.NewGame(playerID, req.Level, settings)
+	switch {
+	case errors.Is(err, game.ErrUnknownBot):
+		apierror.Respond(c, http.StatusNotFound, "bot_not_found", "No bot plays at that level")
+		return
+	case errors.Is(err, game.ErrInvalidSettings):
+		apierror.Respond(c, http.StatusBadRequest, "invalid_settings", err.Error())
+		return
+	case err != nil:
+		log.Printf("Error creating bot game for %s: %v", playerID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create game")
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/lobby"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+type ChallengeRequest struct {
+	GameType    models.GameType `json:"game_type" binding:"required"`
+	Variant     string          `json:"variant" binding:"max=32"`
+	BoardSize   int             `json:"board_size" binding:"min=0"`
+	TimeControl string          `json:"time_control" binding:"max=32"`
+	// Rated defaults to true
+	Rated *bool `json:"rated"`
+	// DaysPerMove is only allowed for correspondence games
+	DaysPerMove int `json:"days_per_move" binding:"min=0,max=14"`
+	// BestOf starts a series of that many games
+	BestOf int `json:"best_of" binding:"min=0,max=9"`
+}
+
+func (h *Handler) CreateChallenge(c *gin.Context) {
+	challengerID := c.MustGet("userID").(uuid.UUID)
+
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+		return
+	}
+
+	var req ChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	engine, err := h.registry.GetEngine(req.GameType)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_type", "Invalid game type")
+		return
+	}
+
+	if !validDaysPerMove(req.TimeControl, req.DaysPerMove) {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_days_per_move", "Days per move only apply to correspondence games")
+		return
+	}
+
+	target, err := h.db.GetUser(targetID)
+	if err != nil || !target.IsActive {
+		apierror.Respond(c, http.StatusNotFound, "user_not_found", "User not found")
+		return
+	}
+
+	settings := models.GameSettings{
+		Variant:     req.Variant,
+		BoardSize:   req.BoardSize,
+		TimeControl: req.TimeControl,
+		Rated:       ratedOrDefault(req.Rated),
+		DaysPerMove: req.DaysPerMove,
+		BestOf:      req.BestOf,
+	}
+	if !applySettings(c, engine, &settings) {
+		return
+	}
+
+	challenge, err := h.matchmaker.CreateChallenge(challengerID, targetID, req.GameType, settings)
+	if err != nil {
+		h.challengeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, challenge)
+}
+
+func (h *Handler) GetChallenges(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	challenges, err := h.matchmaker.GetIncomingChallenges(userID)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get challenges")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"challenges": challenges})
+}
+
+func (h *Handler) AcceptChallenge(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	challengeID, err := uuid.Parse(c.Param("challengeId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_challenge_id", "Invalid challenge ID")
+		return
+	}
+
+	game, err := h.matchmaker.AcceptChallenge(userID, challengeID)
+	if err != nil {
+		h.challengeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, game)
+}
+
+func (h *Handler) DeclineChallenge(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	challengeID, err := uuid.Parse(c.Param("challengeId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_challenge_id", "Invalid challenge ID")
+		return
+	}
+
+	if err := h.matchmaker.DeclineChallenge(userID, challengeID); err != nil {
+		h.challengeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Challenge declined"})
+}
+
+func (h *Handler) challengeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, lobby.ErrChallengeNotFound):
+		apierror.Respond(c, http.StatusNotFound, "challenge_not_found", "Challenge not found or expired")
+	case errors.Is(err, lobby.ErrCannotChallengeSelf):
+		apierror.Respond(c, http.StatusBadRequest, "cannot_challenge_self", "Cannot challenge yourself")
+	case errors.Is(err, lobby.ErrChallengeBlocked):
+		apierror.Respond(c, http.StatusForbidden, "challenge_blocked", "Cannot challenge this user")
+	case errors.Is(err, lobby.ErrChallengePending):
+		apierror.Respond(c, http.StatusConflict, "challenge_pending", "You already have a pending challenge to this user")
+	case errors.Is(err, lobby.ErrGameTypeDisabled):
+		apierror.Respond(c, http.StatusServiceUnavailable, "game_type_disabled", "Game type is temporarily disabled")
+	default:
+		log.Printf("Challenge error: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to process challenge")
+	}
+}
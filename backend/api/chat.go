@@ -0,0 +1,320 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// reportChatLimit is how many of the room's messages, up to and including
+// the reported one, are attached to a chat report.
+const reportChatLimit = 50
+
+type ReportChatMessageRequest struct {
+	Reason  models.ReportReason `json:"reason" binding:"required,oneof=harassment cheating spam offensive_name other"`
+	Details string              `json:"details" binding:"max=1000"`
+}
+
+type DeleteChatMessageRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type MuteRequest struct {
+	// Minutes is how long the mute lasts, at most a year
+	Minutes int    `json:"minutes" binding:"required,min=1,max=525600"`
+	Reason  string `json:"reason" binding:"max=500"`
+}
+
+// ReportChatMessage reports a room chat message the requesting user could
+// see, attaching the room's chat leading up to it.
+func (h *Handler) ReportChatMessage(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	messageID, err := uuid.Parse(c.Param("messageId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_message_id", "Invalid message ID")
+		return
+	}
+
+	var req ReportChatMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	message, err := h.db.GetChatMessage(messageID)
+	if err != nil || message.DeletedAt != nil {
+		apierror.Respond(c, http.StatusNotFound, "chat_message_not_found", "Chat message not found")
+		return
+	}
+	if message.SenderID == userID {
+		apierror.Respond(c, http.StatusBadRequest, "cannot_report_self", "Cannot report yourself")
+		return
+	}
+
+	// Only those who may see the game saw the message
+	gameID, err := uuid.Parse(message.RoomID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "chat_message_not_found", "Chat message not found")
+		return
+	}
+	game, err := h.db.GetGame(gameID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "chat_message_not_found", "Chat message not found")
+		return
+	}
+	allowed, err := h.db.CanWatchGame(game, userID)
+	if err != nil {
+		log.Printf("Error checking whether %s may watch game %s: %v", userID, gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to report chat message")
+		return
+	}
+	if !allowed {
+		apierror.Respond(c, http.StatusNotFound, "chat_message_not_found", "Chat message not found")
+		return
+	}
+
+	report := &models.Report{
+		ID:            uuid.New(),
+		ReporterID:    userID,
+		ReportedID:    message.SenderID,
+		Reason:        req.Reason,
+		Details:       req.Details,
+		GameID:        &game.ID,
+		ChatMessageID: &message.ID,
+	}
+	report.Context.Game = game
+	report.Context.ChatMessages, err = h.db.GetRoomChatBefore(message.RoomID, message.CreatedAt, reportChatLimit)
+	if err != nil {
+		log.Printf("Error getting room chat for report: %v", err)
+	}
+
+	if err := h.db.CreateReport(report); err != nil {
+		log.Printf("Error creating report: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create report")
+		return
+	}
+
+	log.Printf("User %s reported chat message %s from %s for %s", userID, messageID, message.SenderID, req.Reason)
+	c.JSON(http.StatusCreated, gin.H{"id": report.ID, "status": report.Status})
+}
+
+// GetChatMessages lists room chat for moderators, newest first and
+// including deleted messages. ?room_id and ?user_id filter it.
+func (h *Handler) GetChatMessages(c *gin.Context) {
+	var senderID uuid.UUID
+	if value := c.Query("user_id"); value != "" {
+		var err error
+		senderID, err = uuid.Parse(value)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+			return
+		}
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	messages, err := h.db.GetChatMessages(c.Query("room_id"), senderID, limit, offset)
+	if err != nil {
+		log.Printf("Error getting chat messages: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get chat messages")
+		return
+	}
+	if messages == nil {
+		messages = []*models.ChatMessage{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"messages": messages})
+}
+
+// GetGameChat returns a game's chat, newest first, so reconnecting players
+// and replays can show the conversation. Only users who may see the game get
+// its chat.
+func (h *Handler) GetGameChat(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
+		return
+	}
+
+	game, err := h.db.GetGame(gameID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+	if !h.authorizeGameViewer(c, game, userID) {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	messages, err := h.db.GetRoomChat(gameID.String(), userID, limit, offset)
+	if err != nil {
+		log.Printf("Error getting chat for game %s: %v", gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get chat messages")
+		return
+	}
+	if messages == nil {
+		messages = []*models.ChatMessage{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"messages": messages})
+}
+
+// DeleteChatMessage hides a chat message from its room. Connected clients
+// receive a chat_deleted message.
+func (h *Handler) DeleteChatMessage(c *gin.Context) {
+	adminID := c.MustGet("userID").(uuid.UUID)
+
+	messageID, err := uuid.Parse(c.Param("messageId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_message_id", "Invalid message ID")
+		return
+	}
+
+	// The body is optional
+	var req DeleteChatMessageRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+
+	message, err := h.db.GetChatMessage(messageID)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, "chat_message_not_found", "Chat message not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting chat message %s: %v", messageID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to delete chat message")
+		return
+	}
+
+	deleted, err := h.db.DeleteChatMessage(messageID, adminID)
+	if err != nil {
+		log.Printf("Error deleting chat message %s: %v", messageID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to delete chat message")
+		return
+	}
+	if !deleted {
+		apierror.Respond(c, http.StatusConflict, "chat_message_deleted", "Chat message is already deleted")
+		return
+	}
+
+	h.hub.DeleteChatMessage(message.RoomID, messageID)
+
+	log.Printf("Chat message %s deleted by admin %s", messageID, adminID)
+	h.audit(c, models.AuditActionChatMessageDelete, models.AuditTargetChatMessage, messageID.String(), req.Reason, message, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Chat message deleted"})
+}
+
+// MuteUser stops a player from sending room chat and direct messages for
+// the given number of minutes, replacing any current mute.
+func (h *Handler) MuteUser(c *gin.Context) {
+	adminID := c.MustGet("userID").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+		return
+	}
+
+	var req MuteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	if _, err := h.db.GetUser(userID); err != nil {
+		apierror.Respond(c, http.StatusNotFound, "user_not_found", "User not found")
+		return
+	}
+
+	mutedUntil, err := h.db.GetMutedUntil(userID)
+	if err != nil {
+		log.Printf("Error getting mute for %s: %v", userID, err)
+	}
+
+	mute := &models.Mute{
+		UserID:     userID,
+		MutedUntil: time.Now().Add(time.Duration(req.Minutes) * time.Minute),
+		Reason:     req.Reason,
+		MutedBy:    adminID,
+	}
+	if err := h.db.SetMute(mute); err != nil {
+		log.Printf("Error muting %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to mute user")
+		return
+	}
+	h.hub.UpdateMute(userID, mute.MutedUntil)
+
+	log.Printf("User %s muted until %s by admin %s", userID, mute.MutedUntil.Format(time.RFC3339), adminID)
+	h.audit(c, models.AuditActionUserMute, models.AuditTargetUser, userID.String(), req.Reason, muteSnapshot(mutedUntil), mute)
+	c.JSON(http.StatusOK, mute)
+}
+
+func (h *Handler) UnmuteUser(c *gin.Context) {
+	adminID := c.MustGet("userID").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+		return
+	}
+
+	mutedUntil, err := h.db.GetMutedUntil(userID)
+	if err != nil {
+		log.Printf("Error getting mute for %s: %v", userID, err)
+	}
+
+	unmuted, err := h.db.DeleteMute(userID)
+	if err != nil {
+		log.Printf("Error unmuting %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to unmute user")
+		return
+	}
+	if !unmuted {
+		apierror.Respond(c, http.StatusNotFound, "user_not_muted", "User is not muted")
+		return
+	}
+	h.hub.UpdateMute(userID, time.Time{})
+
+	log.Printf("User %s unmuted by admin %s", userID, adminID)
+	h.audit(c, models.AuditActionUserUnmute, models.AuditTargetUser, userID.String(), "", muteSnapshot(mutedUntil), nil)
+	c.JSON(http.StatusOK, gin.H{"message": "User unmuted"})
+}
+
+// muteSnapshot is the audit snapshot of a mute ending at mutedUntil, or nil
+// if the user is not muted.
+func muteSnapshot(mutedUntil time.Time) interface{} {
+	if mutedUntil.IsZero() {
+		return nil
+	}
+	return gin.H{"muted_until": mutedUntil}
+}
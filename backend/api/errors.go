@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+)
+
+func init() {
+	// Report validation failures under the JSON names clients send.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
+
+// bindError responds to a request body ShouldBindJSON rejected, listing
+// each failing field when the body parsed but did not validate.
+func bindError(c *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]apierror.FieldError, len(validationErrs))
+		for i, fe := range validationErrs {
+			fields[i] = apierror.FieldError{
+				Field:   fe.Field(),
+				Code:    fe.Tag(),
+				Message: fieldMessage(fe),
+			}
+		}
+		apierror.RespondFields(c, http.StatusBadRequest, apierror.CodeValidationFailed, "Request validation failed", fields)
+		return
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		apierror.RespondFields(c, http.StatusBadRequest, apierror.CodeValidationFailed, "Request validation failed", []apierror.FieldError{{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: "must be a " + typeErr.Type.String(),
+		}})
+		return
+	}
+
+	apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body")
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "len":
+		return "must have length " + fe.Param()
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	case "email":
+		return "must be a valid email address"
+	case "uuid", "uuid4":
+		return "must be a UUID"
+	}
+	if fe.Param() != "" {
+		return fmt.Sprintf("failed %s=%s", fe.Tag(), fe.Param())
+	}
+	return "failed " + fe.Tag()
+}
+
+// noRoute answers unmatched requests with the error envelope instead of
+// gin's plain-text 404.
+func noRoute(c *gin.Context) {
+	apierror.Respond(c, http.StatusNotFound, apierror.CodeNotFound, "Not found")
+}
+
+// recoverPanic answers a panicking handler with internal_error; gin's
+// recovery middleware has already logged the stack.
+func recoverPanic(c *gin.Context, _ interface{}) {
+	apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Internal server error")
+}
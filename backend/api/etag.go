@@ -0,0 +1,40 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// gameETag identifies a version of a game. updated_at moves on every write
+// and the state hash covers writes that land within the same timestamp.
+// Spectator counts are recorded without moving updated_at, so they are
+// hashed too.
+func gameETag(game *models.Game) string {
+	hash := sha256.New()
+	hash.Write(game.ID[:])
+	hash.Write([]byte(game.UpdatedAt.UTC().Format(time.RFC3339Nano)))
+	hash.Write([]byte(game.Status))
+	hash.Write(game.GameState)
+	hash.Write([]byte(strconv.Itoa(game.Spectators)))
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header lists the ETag. Weak
+// validators match too, as the header uses weak comparison.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
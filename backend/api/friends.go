@@ -0,0 +1,132 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/friends"
+)
+
+func (h *Handler) SendFriendRequest(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	friendID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+		return
+	}
+
+	friend, err := h.db.GetUser(friendID)
+	if err != nil || !friend.IsActive {
+		apierror.Respond(c, http.StatusNotFound, "user_not_found", "User not found")
+		return
+	}
+
+	status, err := h.friends.SendRequest(userID, friendID)
+	if err != nil {
+		h.friendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"user_id": friendID, "status": status})
+}
+
+func (h *Handler) GetFriends(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	list, err := h.friends.List(userID)
+	if err != nil {
+		h.friendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"friends": list})
+}
+
+func (h *Handler) GetFriendRequests(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	requests, err := h.friends.Requests(userID)
+	if err != nil {
+		h.friendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requests": requests})
+}
+
+func (h *Handler) AcceptFriendRequest(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	requesterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+		return
+	}
+
+	if err := h.friends.Accept(userID, requesterID); err != nil {
+		h.friendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Friend request accepted"})
+}
+
+func (h *Handler) DeclineFriendRequest(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	requesterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+		return
+	}
+
+	if err := h.friends.Decline(userID, requesterID); err != nil {
+		h.friendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Friend request declined"})
+}
+
+func (h *Handler) RemoveFriend(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	friendID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+		return
+	}
+
+	if err := h.friends.Remove(userID, friendID); err != nil {
+		h.friendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Friend removed"})
+}
+
+func (h *Handler) friendError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, friends.ErrCannotFriendSelf):
+		apierror.Respond(c, http.StatusBadRequest, "cannot_friend_self", "Cannot add yourself as a friend")
+	case errors.Is(err, friends.ErrFriendBlocked):
+		apierror.Respond(c, http.StatusForbidden, "friend_blocked", "Cannot add this user as a friend")
+	case errors.Is(err, friends.ErrAlreadyFriends):
+		apierror.Respond(c, http.StatusConflict, "already_friends", "Already friends")
+	case errors.Is(err, friends.ErrFriendRequestPending):
+		apierror.Respond(c, http.StatusConflict, "friend_request_pending", "Friend request already pending")
+	case errors.Is(err, friends.ErrFriendRequestNotFound):
+		apierror.Respond(c, http.StatusNotFound, "friend_request_not_found", "Friend request not found")
+	case errors.Is(err, friends.ErrFriendNotFound):
+		apierror.Respond(c, http.StatusNotFound, "friend_not_found", "Friend not found")
+	default:
+		log.Printf("Friend error: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to process friend request")
+	}
+}
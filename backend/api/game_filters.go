@@ -0,0 +1,140 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// maxSavedGameFilters is how many game filters each user may save.
+const maxSavedGameFilters = 20
+
+type GameFilterRequest struct {
+	Status models.GameStatus `json:"status" binding:"omitempty,oneof=waiting in_progress completed abandoned"`
+	Type   models.GameType   `json:"type"`
+	Rated  *bool             `json:"rated"`
+	Tag    models.GameTag    `json:"tag" binding:"omitempty,oneof=tournament friendly study"`
+}
+
+type SaveGameFilterRequest struct {
+	Name   string            `json:"name" binding:"required,max=50"`
+	Filter GameFilterRequest `json:"filter"`
+}
+
+// GetSavedGameFilters lists the requesting user's saved game filters by
+// name.
+func (h *Handler) GetSavedGameFilters(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	filters, err := h.db.GetSavedGameFilters(userID)
+	if err != nil {
+		log.Printf("Error getting saved game filters of %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get saved filters")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"filters": filters})
+}
+
+// SaveGameFilter keeps a named game filter on the requesting user's
+// profile, for use with GET /games?filter=.
+func (h *Handler) SaveGameFilter(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var req SaveGameFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+	if req.Filter.Type != "" {
+		if _, err := h.registry.GetEngine(req.Filter.Type); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid_game_type", "Invalid game type")
+			return
+		}
+	}
+
+	count, err := h.db.CountSavedGameFilters(userID)
+	if err != nil {
+		log.Printf("Error counting saved game filters of %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to save filter")
+		return
+	}
+	if count >= maxSavedGameFilters {
+		apierror.Respond(c, http.StatusConflict, "too_many_filters", "Delete a saved filter before saving another")
+		return
+	}
+
+	filter := &models.SavedGameFilter{
+		ID:   uuid.New(),
+		Name: req.Name,
+		Filter: models.GameFilter{
+			Status: req.Filter.Status,
+			Type:   req.Filter.Type,
+			Rated:  req.Filter.Rated,
+			Tag:    req.Filter.Tag,
+		},
+	}
+	created, err := h.db.CreateSavedGameFilter(userID, filter)
+	if err != nil {
+		log.Printf("Error saving game filter for %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to save filter")
+		return
+	}
+	if !created {
+		apierror.Respond(c, http.StatusConflict, "filter_name_taken", "You already have a filter with this name")
+		return
+	}
+
+	c.JSON(http.StatusCreated, filter)
+}
+
+func (h *Handler) DeleteSavedGameFilter(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	filterID, err := uuid.Parse(c.Param("filterId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_filter_id", "Invalid filter ID")
+		return
+	}
+
+	deleted, err := h.db.DeleteSavedGameFilter(userID, filterID)
+	if err != nil {
+		log.Printf("Error deleting game filter %s of %s: %v", filterID, userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to delete filter")
+		return
+	}
+	if !deleted {
+		apierror.Respond(c, http.StatusNotFound, "filter_not_found", "Filter not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Filter deleted"})
+}
+
+// savedGameFilter loads the user's saved filter with the given ID,
+// responding with an error if there is none.
+func (h *Handler) savedGameFilter(c *gin.Context, userID uuid.UUID, value string) (*models.SavedGameFilter, bool) {
+	filterID, err := uuid.Parse(value)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_filter_id", "Invalid filter ID")
+		return nil, false
+	}
+
+	filter, err := h.db.GetSavedGameFilter(userID, filterID)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, "filter_not_found", "Filter not found")
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error getting game filter %s of %s: %v", filterID, userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get filter")
+		return nil, false
+	}
+	return filter, true
+}
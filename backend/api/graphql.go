@@ -0,0 +1,708 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/szaher/vibeboard/backend/internal/leaderboard"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// graphqlSchema exposes the read side of the REST API, so clients can fetch
+// what a screen needs in one request. Users are shown as the REST profile
+// shows them: blocked and inactive users are null, and rating, stats and
+// recent games are null when the profile's visibility hides them.
+const graphqlSchema = `
+schema {
+	query: Query
+}
+
+scalar Time
+scalar JSON
+
+type Query {
+	me: User!
+	user(id: ID!): User
+	game(id: ID!): Game
+	# Lists public games, newest first
+	# tag is tournament, friendly or study
+	games(status: String, type: String, rated: Boolean, tag: String, limit: Int = 20, offset: Int = 0): [Game!]!
+	# view is global, weekly or friends
+	leaderboard(gameType: String!, view: String = "global", limit: Int = 50): Leaderboard!
+}
+
+type User {
+	id: ID!
+	username: String!
+	avatarUrl: String!
+	joinedAt: Time!
+	restricted: Boolean!
+	rating: Int
+	stats: [GameTypeStats!]
+	recentGames(limit: Int = 10): [GameSummary!]
+}
+
+type GameTypeStats {
+	gameType: String!
+	gamesPlayed: Int!
+	gamesWon: Int!
+	gamesLost: Int!
+	gamesDrawn: Int!
+	winRate: Float!
+	currentWinStreak: Int!
+	bestWinStreak: Int!
+	averageDurationSeconds: Int!
+}
+
+type GameSummary {
+	id: ID!
+	gameType: String!
+	opponent: User
+	result: String!
+	endReason: String
+	endedAt: Time
+}
+
+type Game {
+	id: ID!
+	type: String!
+	status: String!
+	private: Boolean!
+	# public, unlisted or friends
+	visibility: String!
+	# Casual games do not count toward stats, ratings or leaderboards
+	rated: Boolean!
+	# The rules in effect; games from before settings were recorded have none
+	variant: String
+	boardSize: Int
+	timeControl: String
+	player1: User
+	player2: User
+	# Every seat in order, with the color and team played
+	players: [GamePlayer!]!
+	tags: [String!]!
+	winnerId: ID
+	# checkmate, resignation, timeout, abandonment, agreement, blocked, domino, cancelled or adjudication
+	endReason: String
+	currentTurn: ID
+	state: JSON!
+	createdAt: Time!
+	startedAt: Time
+	endedAt: Time
+	moveCount: Int!
+	lastMoveAt: Time
+	durationSeconds: Int
+	spectators: Int!
+	peakSpectators: Int!
+	pausedAt: Time
+	pausedByAdmin: Boolean!
+	pauseRequestedBy: ID
+	turnStartedAt: Time
+	moveDeadline: Time
+	seriesId: ID
+	seriesGame: Int
+	# Valid moves in the order they were played
+	moves: [Move!]!
+}
+
+type GamePlayer {
+	seat: Int!
+	user: User
+	color: String
+	team: Int
+}
+
+type Move {
+	id: ID!
+	playerId: ID!
+	kind: String!
+	data: JSON!
+	createdAt: Time!
+}
+
+type Leaderboard {
+	gameType: String!
+	view: String!
+	week: String
+	entries: [LeaderboardEntry!]!
+	me: LeaderboardEntry
+}
+
+type LeaderboardEntry {
+	rank: Int!
+	user: User
+	username: String!
+	rating: Int!
+	gamesPlayed: Int!
+	gamesWon: Int!
+	weeklyWins: Int
+}
+`
+
+const (
+	graphqlMaxDepth   = 8
+	graphqlMaxGames   = 50
+	graphqlMaxSummary = 50
+)
+
+type GraphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func newGraphQLSchema(h *Handler) *graphql.Schema {
+	return graphql.MustParseSchema(graphqlSchema, &graphqlResolver{h: h}, graphql.MaxDepth(graphqlMaxDepth))
+}
+
+// GraphQL executes a query for the requesting user. Like other GraphQL
+// servers it answers 200 with an errors list when fields fail.
+func (h *Handler) GraphQL(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var req GraphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), graphqlViewerKey{}, &graphqlViewer{
+		id:    userID,
+		users: make(map[uuid.UUID]*userResolver),
+	})
+	c.JSON(http.StatusOK, h.graphql.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
+
+type graphqlViewerKey struct{}
+
+// graphqlViewer is the requesting user. It caches the users a query
+// resolves, which often repeat across games and leaderboard entries.
+type graphqlViewer struct {
+	id    uuid.UUID
+	mu    sync.Mutex
+	users map[uuid.UUID]*userResolver
+}
+
+func viewerFrom(ctx context.Context) *graphqlViewer {
+	return ctx.Value(graphqlViewerKey{}).(*graphqlViewer)
+}
+
+var errGraphQLInternal = errors.New("internal error")
+
+type graphqlResolver struct {
+	h *Handler
+}
+
+func (r *graphqlResolver) Me(ctx context.Context) (*userResolver, error) {
+	user, err := r.h.loadUser(ctx, viewerFrom(ctx).id)
+	if err == nil && user == nil {
+		err = errors.New("user not found")
+	}
+	return user, err
+}
+
+func (r *graphqlResolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	userID, err := uuid.Parse(string(args.ID))
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+	return r.h.loadUser(ctx, userID)
+}
+
+func (r *graphqlResolver) Game(ctx context.Context, args struct{ ID graphql.ID }) (*gameResolver, error) {
+	gameID, err := uuid.Parse(string(args.ID))
+	if err != nil {
+		return nil, errors.New("invalid game ID")
+	}
+
+	game, err := r.h.db.GetGame(gameID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Error getting game %s: %v", gameID, err)
+		return nil, errGraphQLInternal
+	}
+
+	// Games the viewer may not watch are hidden as if they did not exist
+	viewerID := viewerFrom(ctx).id
+	allowed, err := r.h.db.CanWatchGame(game, viewerID)
+	if err != nil {
+		log.Printf("Error checking whether %s may watch game %s: %v", viewerID, gameID, err)
+		return nil, errGraphQLInternal
+	}
+	if !allowed {
+		return nil, nil
+	}
+
+	tags, err := r.h.db.GetGameTags([]uuid.UUID{gameID})
+	if err != nil {
+		log.Printf("Error getting tags of game %s: %v", gameID, err)
+		return nil, errGraphQLInternal
+	}
+	game.Tags = tags[gameID]
+	return &gameResolver{h: r.h, game: game}, nil
+}
+
+func (r *graphqlResolver) Games(ctx context.Context, args struct {
+	Status *string
+	Type   *string
+	Rated  *bool
+	Tag    *string
+	Limit  int32
+	Offset int32
+}) ([]*gameResolver, error) {
+	if args.Limit <= 0 || args.Limit > graphqlMaxGames {
+		return nil, fmt.Errorf("limit must be between 1 and %d", graphqlMaxGames)
+	}
+	if args.Offset < 0 {
+		return nil, errors.New("offset must not be negative")
+	}
+
+	filter := models.GameFilter{Rated: args.Rated}
+	if args.Status != nil {
+		filter.Status = models.GameStatus(*args.Status)
+	}
+	if args.Type != nil {
+		filter.Type = models.GameType(*args.Type)
+	}
+	if args.Tag != nil {
+		filter.Tag = models.GameTag(*args.Tag)
+	}
+
+	games, err := r.h.db.GetGames(viewerFrom(ctx).id, filter, int(args.Limit), int(args.Offset))
+	if err != nil {
+		log.Printf("Error getting games: %v", err)
+		return nil, errGraphQLInternal
+	}
+
+	resolvers := make([]*gameResolver, len(games))
+	for i, game := range games {
+		resolvers[i] = &gameResolver{h: r.h, game: game}
+	}
+	return resolvers, nil
+}
+
+func (r *graphqlResolver) Leaderboard(ctx context.Context, args struct {
+	GameType string
+	View     string
+	Limit    int32
+}) (*leaderboardResolver, error) {
+	gameType := models.GameType(args.GameType)
+	if _, err := r.h.registry.GetEngine(gameType); err != nil {
+		return nil, errors.New("invalid game type")
+	}
+	if args.Limit <= 0 {
+		return nil, errors.New("invalid limit")
+	}
+
+	board, err := r.h.leaderboards.Get(gameType, args.View, viewerFrom(ctx).id, int(args.Limit))
+	if errors.Is(err, leaderboard.ErrInvalidView) {
+		return nil, errors.New("invalid view")
+	}
+	if err != nil {
+		log.Printf("Error getting %s leaderboard: %v", gameType, err)
+		return nil, errGraphQLInternal
+	}
+	return &leaderboardResolver{h: r.h, board: board}, nil
+}
+
+// loadUser returns the user as the viewer may see them, or nil when they
+// may not see them at all.
+func (h *Handler) loadUser(ctx context.Context, userID uuid.UUID) (*userResolver, error) {
+	viewer := viewerFrom(ctx)
+	viewer.mu.Lock()
+	defer viewer.mu.Unlock()
+
+	if user, ok := viewer.users[userID]; ok {
+		return user, nil
+	}
+
+	user, err := h.db.GetUser(userID)
+	if err == sql.ErrNoRows || (err == nil && !user.IsActive) {
+		viewer.users[userID] = nil
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Error getting user %s: %v", userID, err)
+		return nil, errGraphQLInternal
+	}
+
+	if userID != viewer.id {
+		blocked, err := h.db.IsBlocked(viewer.id, userID)
+		if err != nil {
+			log.Printf("Error checking block between %s and %s: %v", viewer.id, userID, err)
+			return nil, errGraphQLInternal
+		}
+		if blocked {
+			viewer.users[userID] = nil
+			return nil, nil
+		}
+	}
+
+	visible, err := h.profileVisible(user, viewer.id)
+	if err != nil {
+		log.Printf("Error checking profile visibility of %s: %v", userID, err)
+		return nil, errGraphQLInternal
+	}
+
+	resolver := &userResolver{h: h, user: user, visible: visible}
+	viewer.users[userID] = resolver
+	return resolver, nil
+}
+
+func (h *Handler) loadOptionalUser(ctx context.Context, userID *uuid.UUID) (*userResolver, error) {
+	if userID == nil {
+		return nil, nil
+	}
+	return h.loadUser(ctx, *userID)
+}
+
+type userResolver struct {
+	h       *Handler
+	user    *models.User
+	visible bool
+}
+
+func (r *userResolver) ID() graphql.ID         { return graphql.ID(r.user.ID.String()) }
+func (r *userResolver) Username() string       { return r.user.Username }
+func (r *userResolver) AvatarURL() string      { return r.user.AvatarURL }
+func (r *userResolver) JoinedAt() graphql.Time { return graphql.Time{Time: r.user.CreatedAt} }
+func (r *userResolver) Restricted() bool       { return !r.visible }
+
+func (r *userResolver) Rating() *int32 {
+	if !r.visible {
+		return nil
+	}
+	rating := int32(1000) // Default rating
+	if stats, err := r.h.db.GetUserStats(r.user.ID); err == nil {
+		rating = int32(stats.Rating)
+	}
+	return &rating
+}
+
+func (r *userResolver) Stats() (*[]*gameTypeStatsResolver, error) {
+	if !r.visible {
+		return nil, nil
+	}
+
+	stats, err := r.h.db.GetGameTypeStats(r.user.ID)
+	if err != nil {
+		log.Printf("Error getting stats for %s: %v", r.user.ID, err)
+		return nil, errGraphQLInternal
+	}
+
+	resolvers := make([]*gameTypeStatsResolver, len(stats))
+	for i, s := range stats {
+		resolvers[i] = &gameTypeStatsResolver{stats: s}
+	}
+	return &resolvers, nil
+}
+
+func (r *userResolver) RecentGames(args struct{ Limit int32 }) (*[]*gameSummaryResolver, error) {
+	if !r.visible {
+		return nil, nil
+	}
+	if args.Limit <= 0 || args.Limit > graphqlMaxSummary {
+		return nil, fmt.Errorf("limit must be between 1 and %d", graphqlMaxSummary)
+	}
+
+	games, err := r.h.db.GetRecentGames(r.user.ID, int(args.Limit))
+	if err != nil {
+		log.Printf("Error getting recent games for %s: %v", r.user.ID, err)
+		return nil, errGraphQLInternal
+	}
+
+	resolvers := make([]*gameSummaryResolver, len(games))
+	for i, game := range games {
+		resolvers[i] = &gameSummaryResolver{h: r.h, summary: game}
+	}
+	return &resolvers, nil
+}
+
+type gameTypeStatsResolver struct {
+	stats *models.GameTypeStats
+}
+
+func (r *gameTypeStatsResolver) GameType() string   { return string(r.stats.GameType) }
+func (r *gameTypeStatsResolver) GamesPlayed() int32 { return int32(r.stats.GamesPlayed) }
+func (r *gameTypeStatsResolver) GamesWon() int32    { return int32(r.stats.GamesWon) }
+func (r *gameTypeStatsResolver) GamesLost() int32   { return int32(r.stats.GamesLost) }
+func (r *gameTypeStatsResolver) GamesDrawn() int32  { return int32(r.stats.GamesDrawn) }
+func (r *gameTypeStatsResolver) WinRate() float64   { return r.stats.WinRate }
+func (r *gameTypeStatsResolver) CurrentWinStreak() int32 {
+	return int32(r.stats.CurrentWinStreak)
+}
+func (r *gameTypeStatsResolver) BestWinStreak() int32 { return int32(r.stats.BestWinStreak) }
+func (r *gameTypeStatsResolver) AverageDurationSeconds() int32 {
+	return int32(r.stats.AverageDurationSeconds)
+}
+
+type gameSummaryResolver struct {
+	h       *Handler
+	summary *models.GameSummary
+}
+
+func (r *gameSummaryResolver) ID() graphql.ID   { return graphql.ID(r.summary.ID.String()) }
+func (r *gameSummaryResolver) GameType() string { return string(r.summary.GameType) }
+func (r *gameSummaryResolver) Result() string   { return string(r.summary.Result) }
+
+func (r *gameSummaryResolver) EndReason() *string {
+	return optionalString(string(r.summary.EndReason))
+}
+
+func (r *gameSummaryResolver) EndedAt() *graphql.Time {
+	return optionalTime(r.summary.EndedAt)
+}
+
+func (r *gameSummaryResolver) Opponent(ctx context.Context) (*userResolver, error) {
+	return r.h.loadOptionalUser(ctx, r.summary.OpponentID)
+}
+
+type gameResolver struct {
+	h    *Handler
+	game *models.Game
+}
+
+func (r *gameResolver) ID() graphql.ID                { return graphql.ID(r.game.ID.String()) }
+func (r *gameResolver) Type() string                  { return string(r.game.Type) }
+func (r *gameResolver) Status() string                { return string(r.game.Status) }
+func (r *gameResolver) Private() bool                 { return r.game.Private }
+func (r *gameResolver) Visibility() string            { return string(r.game.Visibility) }
+func (r *gameResolver) Rated() bool                   { return r.game.Settings.Rated }
+func (r *gameResolver) Variant() *string              { return optionalString(r.game.Settings.Variant) }
+func (r *gameResolver) TimeControl() *string          { return optionalString(r.game.Settings.TimeControl) }
+func (r *gameResolver) WinnerID() *graphql.ID         { return optionalID(r.game.WinnerID) }
+func (r *gameResolver) CurrentTurn() *graphql.ID      { return optionalID(r.game.CurrentTurn) }
+func (r *gameResolver) State() graphqlJSON            { return graphqlJSON(r.game.GameState) }
+func (r *gameResolver) CreatedAt() graphql.Time       { return graphql.Time{Time: r.game.CreatedAt} }
+func (r *gameResolver) StartedAt() *graphql.Time      { return optionalTime(r.game.StartedAt) }
+func (r *gameResolver) EndedAt() *graphql.Time        { return optionalTime(r.game.EndedAt) }
+func (r *gameResolver) MoveCount() int32              { return int32(r.game.MoveCount) }
+func (r *gameResolver) LastMoveAt() *graphql.Time     { return optionalTime(r.game.LastMoveAt) }
+func (r *gameResolver) Spectators() int32             { return int32(r.game.Spectators) }
+func (r *gameResolver) PeakSpectators() int32         { return int32(r.game.PeakSpectators) }
+func (r *gameResolver) PausedAt() *graphql.Time       { return optionalTime(r.game.PausedAt) }
+func (r *gameResolver) PausedByAdmin() bool           { return r.game.PausedByAdmin }
+func (r *gameResolver) PauseRequestedBy() *graphql.ID { return optionalID(r.game.PauseRequestedBy) }
+func (r *gameResolver) TurnStartedAt() *graphql.Time  { return optionalTime(r.game.TurnStartedAt) }
+func (r *gameResolver) MoveDeadline() *graphql.Time   { return optionalTime(r.game.MoveDeadline) }
+func (r *gameResolver) EndReason() *string            { return optionalString(string(r.game.EndReason)) }
+func (r *gameResolver) SeriesID() *graphql.ID         { return optionalID(r.game.SeriesID) }
+
+func (r *gameResolver) BoardSize() *int32 {
+	if r.game.Settings.BoardSize == 0 {
+		return nil
+	}
+	size := int32(r.game.Settings.BoardSize)
+	return &size
+}
+
+func (r *gameResolver) SeriesGame() *int32 {
+	if r.game.SeriesID == nil {
+		return nil
+	}
+	number := int32(r.game.SeriesGame)
+	return &number
+}
+
+func (r *gameResolver) DurationSeconds() *int32 {
+	if r.game.DurationSeconds == nil {
+		return nil
+	}
+	seconds := int32(*r.game.DurationSeconds)
+	return &seconds
+}
+
+func (r *gameResolver) Player1(ctx context.Context) (*userResolver, error) {
+	return r.h.loadUser(ctx, r.game.Player1ID)
+}
+
+func (r *gameResolver) Player2(ctx context.Context) (*userResolver, error) {
+	return r.h.loadOptionalUser(ctx, r.game.Player2ID)
+}
+
+func (r *gameResolver) Tags() []string {
+	tags := make([]string, len(r.game.Tags))
+	for i, tag := range r.game.Tags {
+		tags[i] = string(tag)
+	}
+	return tags
+}
+
+func (r *gameResolver) Players() ([]*gamePlayerResolver, error) {
+	players, err := r.h.db.GetGamePlayers(r.game.ID)
+	if err != nil {
+		log.Printf("Error getting players of game %s: %v", r.game.ID, err)
+		return nil, errGraphQLInternal
+	}
+
+	resolvers := make([]*gamePlayerResolver, len(players))
+	for i := range players {
+		resolvers[i] = &gamePlayerResolver{h: r.h, player: players[i]}
+	}
+	return resolvers, nil
+}
+
+type gamePlayerResolver struct {
+	h      *Handler
+	player models.GamePlayer
+}
+
+func (r *gamePlayerResolver) Seat() int32 { return int32(r.player.Seat) }
+
+func (r *gamePlayerResolver) User(ctx context.Context) (*userResolver, error) {
+	return r.h.loadOptionalUser(ctx, &r.player.UserID)
+}
+
+func (r *gamePlayerResolver) Color() *string {
+	if r.player.Color == "" {
+		return nil
+	}
+	return &r.player.Color
+}
+
+func (r *gamePlayerResolver) Team() *int32 {
+	if r.player.Team == nil {
+		return nil
+	}
+	team := int32(*r.player.Team)
+	return &team
+}
+
+func (r *gameResolver) Moves() ([]*moveResolver, error) {
+	moves, err := r.h.db.GetGameMoves(r.game.ID)
+	if err != nil {
+		log.Printf("Error getting moves for game %s: %v", r.game.ID, err)
+		return nil, errGraphQLInternal
+	}
+
+	resolvers := make([]*moveResolver, 0, len(moves))
+	for _, move := range moves {
+		if move.IsValid {
+			resolvers = append(resolvers, &moveResolver{move: move})
+		}
+	}
+	return resolvers, nil
+}
+
+type moveResolver struct {
+	move *models.Move
+}
+
+func (r *moveResolver) ID() graphql.ID          { return graphql.ID(r.move.ID.String()) }
+func (r *moveResolver) PlayerID() graphql.ID    { return graphql.ID(r.move.PlayerID.String()) }
+func (r *moveResolver) Kind() string            { return string(r.move.Kind) }
+func (r *moveResolver) Data() graphqlJSON       { return graphqlJSON(r.move.MoveData) }
+func (r *moveResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.move.CreatedAt} }
+
+type leaderboardResolver struct {
+	h     *Handler
+	board *leaderboard.Leaderboard
+}
+
+func (r *leaderboardResolver) GameType() string { return string(r.board.GameType) }
+func (r *leaderboardResolver) View() string     { return r.board.View }
+
+func (r *leaderboardResolver) Week() *string {
+	if r.board.Week == "" {
+		return nil
+	}
+	return &r.board.Week
+}
+
+func (r *leaderboardResolver) Entries() []*leaderboardEntryResolver {
+	resolvers := make([]*leaderboardEntryResolver, len(r.board.Entries))
+	for i, entry := range r.board.Entries {
+		resolvers[i] = &leaderboardEntryResolver{h: r.h, entry: entry, weekly: r.board.View == leaderboard.ViewWeekly}
+	}
+	return resolvers
+}
+
+func (r *leaderboardResolver) Me() *leaderboardEntryResolver {
+	if r.board.Me == nil {
+		return nil
+	}
+	return &leaderboardEntryResolver{h: r.h, entry: r.board.Me, weekly: r.board.View == leaderboard.ViewWeekly}
+}
+
+type leaderboardEntryResolver struct {
+	h      *Handler
+	entry  *models.LeaderboardEntry
+	weekly bool
+}
+
+func (r *leaderboardEntryResolver) Rank() int32        { return int32(r.entry.Rank) }
+func (r *leaderboardEntryResolver) Username() string   { return r.entry.Username }
+func (r *leaderboardEntryResolver) Rating() int32      { return int32(r.entry.Rating) }
+func (r *leaderboardEntryResolver) GamesPlayed() int32 { return int32(r.entry.GamesPlayed) }
+func (r *leaderboardEntryResolver) GamesWon() int32    { return int32(r.entry.GamesWon) }
+
+func (r *leaderboardEntryResolver) WeeklyWins() *int32 {
+	if !r.weekly {
+		return nil
+	}
+	wins := int32(r.entry.WeeklyWins)
+	return &wins
+}
+
+func (r *leaderboardEntryResolver) User(ctx context.Context) (*userResolver, error) {
+	return r.h.loadUser(ctx, r.entry.UserID)
+}
+
+// graphqlJSON is the JSON scalar, used for game states and move data whose
+// shape depends on the game type.
+type graphqlJSON json.RawMessage
+
+func (graphqlJSON) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+func (j *graphqlJSON) UnmarshalGraphQL(input interface{}) error {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	*j = data
+	return nil
+}
+
+func (j graphqlJSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func optionalID(id *uuid.UUID) *graphql.ID {
+	if id == nil {
+		return nil
+	}
+	gqlID := graphql.ID(id.String())
+	return &gqlID
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func optionalTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
@@ -1,35 +1,69 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/szaher/vibeboard/backend/internal/account"
+	"github.com/szaher/vibeboard/backend/internal/apierror"
 	"github.com/szaher/vibeboard/backend/internal/auth"
 	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/friends"
+	"github.com/szaher/vibeboard/backend/internal/game"
+	"github.com/szaher/vibeboard/backend/internal/leaderboard"
+	"github.com/szaher/vibeboard/backend/internal/lobby"
 	"github.com/szaher/vibeboard/backend/internal/models"
+	"github.com/szaher/vibeboard/backend/internal/websocket"
 )
 
 type Handler struct {
-	db         *database.DB
-	jwtManager *auth.JWTManager
+	db           *database.DB
+	jwtManager   *auth.JWTManager
+	registry     *game.EngineRegistry
+	hub          *websocket.Hub
+	lobbies      *lobby.PrivateLobbyService
+	matchmaker   *lobby.MatchmakingService
+	leaderboards *leaderboard.Service
+	friends      *friends.Service
+	accounts     *account.Service
+	bots         *game.BotService
+	dependencies []DependencyCheck
+	graphql      *graphql.Schema
 }
 
-func NewHandler(db *database.DB, jwtManager *auth.JWTManager) *Handler {
-	return &Handler{
-		db:         db,
-		jwtManager: jwtManager,
-	}
+func NewHandler(db *database.DB, jwtManager *auth.JWTManager, registry *game.EngineRegistry, hub *websocket.Hub, lobbies *lobby.PrivateLobbyService, matchmaker *lobby.MatchmakingService, leaderboards *leaderboard.Service, friendships *friends.Service, accounts *account.Service, bots *game.BotService, dependencies []DependencyCheck) *Handler {
+	h := &Handler{
+		db:           db,
+		jwtManager:   jwtManager,
+		registry:     registry,
+		hub:          hub,
+		lobbies:      lobbies,
+		matchmaker:   matchmaker,
+		leaderboards: leaderboards,
+		friends:      friendships,
+		accounts:     accounts,
+		bots:         bots,
+		dependencies: dependencies,
+	}
+	h.graphql = newGraphQLSchema(h)
+	return h
 }
 
 // Auth handlers
 type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Username string `json:"username" binding:"required,min=3,max=20"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
 }
 
 type LoginRequest struct {
@@ -40,21 +74,26 @@ type LoginRequest struct {
 func (h *Handler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		bindError(c, err)
 		return
 	}
 
 	// Check if user already exists
 	existingUser, _ := h.db.GetUserByEmail(req.Email)
 	if existingUser != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
+		apierror.Respond(c, http.StatusConflict, "user_exists", "User already exists")
+		return
+	}
+
+	if violations := h.accounts.CheckPassword(c.Request.Context(), req.Password, req.Username, req.Email); violations != nil {
+		passwordPolicyError(c, "password", violations)
 		return
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to hash password")
 		return
 	}
 
@@ -68,14 +107,15 @@ func (h *Handler) Register(c *gin.Context) {
 	}
 
 	if err := h.db.CreateUser(user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create user")
 		return
 	}
+	h.accounts.RecordLogin(user, h.accounts.NewLoginAttempt(account.LoginMethodPassword, c.ClientIP(), c.Request.Header))
 
 	// Generate tokens
 	tokens, err := h.jwtManager.GenerateTokenPair(user.ID, user.Username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to generate tokens")
 		return
 	}
 
@@ -88,32 +128,50 @@ func (h *Handler) Register(c *gin.Context) {
 func (h *Handler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		bindError(c, err)
 		return
 	}
 
 	// Get user by email
 	user, err := h.db.GetUserByEmail(req.Email)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		apierror.Respond(c, http.StatusUnauthorized, "invalid_credentials", "Invalid credentials")
 		return
 	}
 
 	// Check password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		apierror.Respond(c, http.StatusUnauthorized, "invalid_credentials", "Invalid credentials")
 		return
 	}
 
 	if !user.IsActive {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is disabled"})
+		apierror.Respond(c, http.StatusUnauthorized, "account_disabled", "Account is disabled")
+		return
+	}
+
+	// Logins from a new device or an improbable location must be confirmed
+	// with an emailed code
+	attempt := h.accounts.NewLoginAttempt(account.LoginMethodPassword, c.ClientIP(), c.Request.Header)
+	if err := h.accounts.CheckLogin(user, attempt); err != nil {
+		var challenge *account.ChallengeError
+		if errors.As(err, &challenge) {
+			c.JSON(http.StatusAccepted, gin.H{
+				"verification_required": true,
+				"challenge_id":          challenge.ChallengeID,
+				"expires_at":            challenge.ExpiresAt,
+			})
+			return
+		}
+		log.Printf("Error checking login for user %s: %v", user.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to sign in")
 		return
 	}
 
 	// Generate tokens
 	tokens, err := h.jwtManager.GenerateTokenPair(user.ID, user.Username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to generate tokens")
 		return
 	}
 
@@ -123,6 +181,13 @@ func (h *Handler) Login(c *gin.Context) {
 	})
 }
 
+// GetJWKS publishes the token signing keys, so other services can validate
+// access tokens without the signing secret.
+func (h *Handler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtManager.JWKS())
+}
+
 type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
@@ -130,13 +195,26 @@ type RefreshRequest struct {
 func (h *Handler) RefreshToken(c *gin.Context) {
 	var req RefreshRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		bindError(c, err)
 		return
 	}
 
 	tokens, err := h.jwtManager.RefreshToken(req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		apierror.Respond(c, http.StatusUnauthorized, "invalid_refresh_token", "Invalid refresh token")
+		return
+	}
+
+	// Deactivated and deleted accounts keep valid tokens until they expire,
+	// but get no new ones
+	claims, err := h.jwtManager.ValidateToken(req.RefreshToken)
+	if err != nil {
+		apierror.Respond(c, http.StatusUnauthorized, "invalid_refresh_token", "Invalid refresh token")
+		return
+	}
+	user, err := h.db.GetUser(claims.UserID)
+	if err != nil || !user.IsActive {
+		apierror.Respond(c, http.StatusUnauthorized, "account_disabled", "Account is disabled")
 		return
 	}
 
@@ -146,118 +224,328 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 // Game handlers
 type CreateGameRequest struct {
 	GameType string `json:"game_type" binding:"required"`
+	Private  bool   `json:"private"`
+	// Rated defaults to true; casual games leave stats and ratings alone
+	Rated *bool `json:"rated"`
+	// Visibility defaults to public
+	Visibility models.GameVisibility `json:"visibility" binding:"omitempty,oneof=public unlisted friends"`
+	// Variant and BoardSize default to the game type's first options
+	Variant     string `json:"variant" binding:"max=32"`
+	BoardSize   int    `json:"board_size" binding:"min=0"`
+	TimeControl string `json:"time_control" binding:"max=32"`
+	// DaysPerMove is only allowed for correspondence games
+	DaysPerMove int `json:"days_per_move" binding:"min=0,max=14"`
+	// BestOf starts a series of that many games
+	BestOf int              `json:"best_of" binding:"min=0,max=9"`
+	Tags   []models.GameTag `json:"tags" binding:"max=3,unique,dive,oneof=tournament friendly study"`
+}
+
+// validDaysPerMove reports whether days per move may be given with the time
+// control; only correspondence games have move deadlines.
+func validDaysPerMove(timeControl string, days int) bool {
+	return days == 0 || timeControl == models.TimeControlCorrespondence
+}
+
+// applySettings checks settings against the options the engine offers and
+// fills in its defaults, responding with an error if it doesn't offer them.
+func applySettings(c *gin.Context, engine game.GameEngine, settings *models.GameSettings) bool {
+	if err := game.ApplySettings(engine, settings); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_settings", err.Error())
+		return false
+	}
+	return true
+}
+
+// ratedOrDefault is whether a new game is rated: games are rated unless
+// asked to be casual.
+func ratedOrDefault(rated *bool) bool {
+	return rated == nil || *rated
 }
 
 func (h *Handler) CreateGame(c *gin.Context) {
-	userID := c.GetHeader("X-User-ID")
-	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	playerID := c.MustGet("userID").(uuid.UUID)
+
+	var req CreateGameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
 		return
 	}
 
-	playerID, err := uuid.Parse(userID)
+	gameType := models.GameType(req.GameType)
+	engine, err := h.registry.GetEngine(gameType)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_type", "Invalid game type")
 		return
 	}
 
-	var req CreateGameRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !h.registry.IsEnabled(gameType) {
+		apierror.Respond(c, http.StatusServiceUnavailable, "game_type_disabled", "Game type is temporarily disabled")
 		return
 	}
 
-	gameType := models.GameType(req.GameType)
-	if gameType != models.GameTypeDominoes && gameType != models.GameTypeChess {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid game type"})
+	if !validDaysPerMove(req.TimeControl, req.DaysPerMove) {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_days_per_move", "Days per move only apply to correspondence games")
 		return
 	}
 
 	game := &models.Game{
-		ID:        uuid.New(),
-		Type:      gameType,
-		Status:    models.GameStatusWaiting,
-		Player1ID: playerID,
+		ID:         uuid.New(),
+		Type:       gameType,
+		Status:     models.GameStatusWaiting,
+		Private:    req.Private,
+		Visibility: req.Visibility,
+		Settings: models.GameSettings{
+			Variant:     req.Variant,
+			BoardSize:   req.BoardSize,
+			TimeControl: req.TimeControl,
+			Rated:       ratedOrDefault(req.Rated),
+			DaysPerMove: req.DaysPerMove,
+			BestOf:      req.BestOf,
+		},
+		Tags: req.Tags,
+	}
+	game.SetPlayers(playerID)
+	if !applySettings(c, engine, &game.Settings) {
+		return
+	}
+
+	// Issue the code first so rate-limited users don't leave unjoinable games
+	var joinCode *lobby.JoinCode
+	if game.Private {
+		joinCode, err = h.lobbies.CreateJoinCode(playerID, game.ID)
+		if err != nil {
+			h.joinCodeError(c, err)
+			return
+		}
 	}
 
 	if err := h.db.CreateGame(game); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create game"})
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create game")
+		return
+	}
+
+	if joinCode != nil {
+		c.JSON(http.StatusCreated, PrivateGameResponse{
+			Game:              game,
+			JoinCode:          joinCode.Code,
+			JoinCodeExpiresAt: joinCode.ExpiresAt,
+		})
 		return
 	}
 
 	c.JSON(http.StatusCreated, game)
 }
 
+// GameTypeOptions is a game type accepting new games and the settings it
+// offers.
+type GameTypeOptions struct {
+	GameType models.GameType `json:"game_type"`
+	game.SettingsSchema
+}
+
+// GetGameTypeOptions lists the game types accepting new games, with the
+// variants and board sizes each offers; the first of each is the default.
+func (h *Handler) GetGameTypeOptions(c *gin.Context) {
+	types := h.registry.GetEnabledTypes()
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	options := make([]GameTypeOptions, 0, len(types))
+	for _, gameType := range types {
+		engine, err := h.registry.GetEngine(gameType)
+		if err != nil {
+			continue
+		}
+		options = append(options, GameTypeOptions{GameType: gameType, SettingsSchema: game.SchemaFor(engine)})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"game_types": options})
+}
+
 func (h *Handler) JoinGame(c *gin.Context) {
-	userID := c.GetHeader("X-User-ID")
-	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	playerID := c.MustGet("userID").(uuid.UUID)
+
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
 		return
 	}
 
-	playerID, err := uuid.Parse(userID)
+	game, err := h.db.GetGame(gameID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+
+	if game.Private {
+		apierror.Respond(c, http.StatusForbidden, "join_code_required", "Private games can only be joined with a join code")
 		return
 	}
 
+	h.seatPlayer(c, game, playerID)
+}
+
+// seatPlayer takes the open seat of a waiting game for the player and
+// writes the response. It reports whether the player was seated.
+func (h *Handler) seatPlayer(c *gin.Context, game *models.Game, playerID uuid.UUID) bool {
+	if game.Status != models.GameStatusWaiting {
+		apierror.Respond(c, http.StatusBadRequest, "game_not_waiting", "Game is not waiting for players")
+		return false
+	}
+
+	if game.HasPlayer(playerID) {
+		apierror.Respond(c, http.StatusBadRequest, "cannot_join_own_game", "Cannot join your own game")
+		return false
+	}
+
+	if game.IsFull() {
+		apierror.Respond(c, http.StatusBadRequest, "game_full", "Game is already full")
+		return false
+	}
+
+	// The game starts once every player passes the ready check in the game
+	// room
+	game.SeatPlayer(playerID)
+
+	// Only the seat is written, and only while the game is still waiting,
+	// so a cancel or another join racing this one wins outright
+	seated, err := h.db.ClaimSeat(game)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to join game")
+		return false
+	}
+	if !seated {
+		apierror.Respond(c, http.StatusConflict, "game_not_joinable", "Game was cancelled, started or filled while joining")
+		return false
+	}
+
+	// Only the seated players may take player slots in the game room now
+	if game.IsFull() {
+		h.hub.LockRoom(game.ID.String(), game.PlayerIDs())
+	}
+
+	c.JSON(http.StatusOK, game)
+	return true
+}
+
+// CancelGame lets the creator of a game that has not started call it off.
+// A player who took the second seat is told through the game room.
+func (h *Handler) CancelGame(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
 	gameID, err := uuid.Parse(c.Param("gameId"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid game ID"})
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
 		return
 	}
 
-	game, err := h.db.GetGame(gameID)
+	gameRecord, err := h.db.GetGame(gameID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Game not found"})
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
 		return
 	}
 
-	if game.Status != models.GameStatusWaiting {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Game is not waiting for players"})
+	if gameRecord.Creator() != userID {
+		apierror.Respond(c, http.StatusForbidden, "not_game_creator", "Only the game's creator can cancel it")
 		return
 	}
 
-	if game.Player1ID == playerID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot join your own game"})
+	// The update only applies while the game is waiting, in case it starts
+	// in the meantime
+	cancelled, err := h.db.CancelGame(gameID, userID)
+	if err != nil {
+		log.Printf("Error cancelling game %s: %v", gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to cancel game")
 		return
 	}
-
-	if game.Player2ID != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Game is already full"})
+	if !cancelled {
+		apierror.Respond(c, http.StatusConflict, "game_not_waiting", "Only games waiting for players can be cancelled")
 		return
 	}
 
-	game.Player2ID = &playerID
-	game.Status = models.GameStatusInProgress
+	h.lobbies.RevokeJoinCode(gameID)
 
-	if err := h.db.UpdateGame(game); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join game"})
-		return
-	}
+	now := time.Now()
+	gameRecord.Status = models.GameStatusAbandoned
+	gameRecord.EndReason = models.EndReasonCancelled
+	gameRecord.EndedAt = &now
+	h.hub.NotifyGameEnded(gameRecord, game.EndReasonCancelled)
 
-	c.JSON(http.StatusOK, game)
+	c.JSON(http.StatusOK, gin.H{"message": "Game cancelled"})
 }
 
 func (h *Handler) GetGame(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
 	gameID, err := uuid.Parse(c.Param("gameId"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid game ID"})
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
 		return
 	}
 
 	game, err := h.db.GetGame(gameID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Game not found"})
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+	if !h.authorizeGameViewer(c, game, userID) {
+		return
+	}
+
+	// Polling clients revalidate with If-None-Match instead of downloading
+	// an unchanged state again
+	etag := gameETag(game)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	tags, err := h.db.GetGameTags([]uuid.UUID{gameID})
+	if err != nil {
+		log.Printf("Error getting tags of game %s: %v", gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get game")
 		return
 	}
+	game.Tags = tags[gameID]
 
 	c.JSON(http.StatusOK, game)
 }
 
+// GetGames lists games, filtered by ?status, ?type, ?rated and ?tag. ?filter
+// starts from one of the user's saved filters, which the others override.
 func (h *Handler) GetGames(c *gin.Context) {
-	status := c.Query("status")
-	gameType := c.Query("type")
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var filter models.GameFilter
+	if value := c.Query("filter"); value != "" {
+		saved, ok := h.savedGameFilter(c, userID, value)
+		if !ok {
+			return
+		}
+		filter = saved.Filter
+	}
+	if status := c.Query("status"); status != "" {
+		filter.Status = models.GameStatus(status)
+	}
+	if gameType := c.Query("type"); gameType != "" {
+		filter.Type = models.GameType(gameType)
+	}
+	if value := c.Query("rated"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid_rated", "rated must be true or false")
+			return
+		}
+		filter.Rated = &parsed
+	}
+	tag, ok := gameTagParam(c)
+	if !ok {
+		return
+	}
+	if tag != "" {
+		filter.Tag = tag
+	}
 
 	limitStr := c.DefaultQuery("limit", "20")
 	limit, err := strconv.Atoi(limitStr)
@@ -271,9 +559,9 @@ func (h *Handler) GetGames(c *gin.Context) {
 		offset = 0
 	}
 
-	games, err := h.db.GetGames(status, gameType, limit, offset)
+	games, err := h.db.GetGames(userID, filter, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get games"})
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get games")
 		return
 	}
 
@@ -285,75 +573,65 @@ type MakeMoveRequest struct {
 }
 
 func (h *Handler) MakeMove(c *gin.Context) {
-	userID := c.GetHeader("X-User-ID")
-	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	playerID, err := uuid.Parse(userID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-		return
-	}
+	playerID := c.MustGet("userID").(uuid.UUID)
 
 	gameID, err := uuid.Parse(c.Param("gameId"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid game ID"})
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
 		return
 	}
 
 	var req MakeMoveRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		bindError(c, err)
 		return
 	}
 
-	game, err := h.db.GetGame(gameID)
+	move, err := json.Marshal(req.MoveData)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Game not found"})
+		apierror.Respond(c, http.StatusBadRequest, "invalid_move", "Invalid move data")
 		return
 	}
 
-	if game.Status != models.GameStatusInProgress {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Game is not in progress"})
+	updated, err := h.hub.MakeMove(gameID, playerID, move)
+	switch {
+	case errors.Is(err, game.ErrGameNotFound):
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
 		return
-	}
-
-	// Check if player is in the game
-	if game.Player1ID != playerID && (game.Player2ID == nil || *game.Player2ID != playerID) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Player not in this game"})
+	case errors.Is(err, game.ErrGameNotInProgress):
+		apierror.Respond(c, http.StatusBadRequest, "game_not_in_progress", "Game is not in progress")
+		return
+	case errors.Is(err, game.ErrGamePaused):
+		apierror.Respond(c, http.StatusConflict, "game_paused", "Game is paused")
+		return
+	case errors.Is(err, game.ErrNotInGame):
+		apierror.Respond(c, http.StatusForbidden, "not_a_player", "Player not in this game")
+		return
+	case errors.Is(err, game.ErrInvalidMove):
+		apierror.Respond(c, http.StatusBadRequest, "invalid_move", err.Error())
+		return
+	case errors.Is(err, game.ErrGameChanged):
+		apierror.Respond(c, http.StatusConflict, "game_changed", "Game changed while the move was made")
+		return
+	case errors.Is(err, websocket.ErrMovesUnavailable):
+		apierror.Respond(c, http.StatusServiceUnavailable, "moves_unavailable", "Moves are unavailable")
+		return
+	case err != nil:
+		log.Printf("Error processing move in game %s: %v", gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to make move")
 		return
 	}
 
-	// TODO: Validate and apply move using game engine
-	// This would involve:
-	// 1. Get the appropriate game engine
-	// 2. Validate the move
-	// 3. Apply the move
-	// 4. Update game state
-	// 5. Check for game end conditions
-
-	c.JSON(http.StatusOK, gin.H{"message": "Move processing not yet implemented"})
+	c.JSON(http.StatusOK, updated)
 }
 
 // User handlers
 func (h *Handler) GetProfile(c *gin.Context) {
-	userID := c.GetHeader("X-User-ID")
-	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	uid, err := uuid.Parse(userID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-		return
-	}
+	uid := c.MustGet("userID").(uuid.UUID)
 
 	user, err := h.db.GetUser(uid)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		apierror.Respond(c, http.StatusNotFound, "user_not_found", "User not found")
 		return
 	}
 
@@ -366,20 +644,22 @@ func (h *Handler) GetProfile(c *gin.Context) {
 			GamesWon:    0,
 			GamesLost:   0,
 			Rating:      1000, // Default rating
+			RankedTier:  models.RankedTier{Tier: models.TierUnranked},
+			Provisional: true,
 		}
 	}
+	stats.PlacementGamesLeft = game.PlacementGamesLeft(stats.RankedTier, stats.RatedGames)
 
-	c.JSON(http.StatusOK, gin.H{
-		"user":  user,
-		"stats": stats,
-	})
-}
+	filters, err := h.db.GetSavedGameFilters(uid)
+	if err != nil {
+		log.Printf("Error getting saved game filters of %s: %v", uid, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get profile")
+		return
+	}
 
-// Health check
-func (h *Handler) HealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"status":  "healthy",
-		"service": "vibe-arcade-backend",
-		"version": "1.0.0",
+		"user":         user,
+		"stats":        stats,
+		"game_filters": filters,
 	})
 }
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// healthCheckTimeout bounds each dependency ping, keeping /health and
+// /ready within a probe's timeout.
+const healthCheckTimeout = 2 * time.Second
+
+const (
+	HealthStatusHealthy   = "healthy"
+	HealthStatusDegraded  = "degraded"
+	HealthStatusUnhealthy = "unhealthy"
+)
+
+// DependencyCheck is a dependency pinged by /health and /ready.
+type DependencyCheck struct {
+	Name string
+	// Critical dependencies are needed to serve requests, so the instance
+	// is not ready while one is down. Others only degrade it
+	Critical bool
+	Ping     func(ctx context.Context) error
+}
+
+type DependencyStatus struct {
+	Name      string  `json:"name"`
+	Up        bool    `json:"up"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+type HealthResponse struct {
+	Status       string             `json:"status"`
+	Service      string             `json:"service"`
+	Version      string             `json:"version"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+// HealthCheck reports the status of the instance and each dependency. It
+// answers 200 whenever the process can serve it, so liveness probes do not
+// restart instances over an outage elsewhere; use /ready to take the
+// instance out of rotation.
+func (h *Handler) HealthCheck(c *gin.Context) {
+	c.JSON(http.StatusOK, h.checkHealth(c.Request.Context()))
+}
+
+// Ready answers 503 while a critical dependency is down or the server is
+// shutting down, and 200 otherwise.
+func (h *Handler) Ready(c *gin.Context) {
+	health := h.checkHealth(c.Request.Context())
+	if health.Status == HealthStatusUnhealthy || h.hub.ShuttingDown() {
+		health.Status = HealthStatusUnhealthy
+		c.JSON(http.StatusServiceUnavailable, health)
+		return
+	}
+	c.JSON(http.StatusOK, health)
+}
+
+// checkHealth pings every dependency at once.
+func (h *Handler) checkHealth(ctx context.Context) HealthResponse {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	statuses := make([]DependencyStatus, len(h.dependencies))
+	var wg sync.WaitGroup
+	for i, check := range h.dependencies {
+		wg.Add(1)
+		go func(i int, check DependencyCheck) {
+			defer wg.Done()
+			start := time.Now()
+			err := check.Ping(ctx)
+			statuses[i] = DependencyStatus{
+				Name:      check.Name,
+				Up:        err == nil,
+				Critical:  check.Critical,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			// Errors may name internal hosts, so they are only logged
+			if err != nil {
+				log.Printf("Health check %s failed: %v", check.Name, err)
+			}
+		}(i, check)
+	}
+	wg.Wait()
+
+	status := HealthStatusHealthy
+	for _, dependency := range statuses {
+		switch {
+		case dependency.Up:
+		case dependency.Critical:
+			status = HealthStatusUnhealthy
+		case status == HealthStatusHealthy:
+			status = HealthStatusDegraded
+		}
+	}
+
+	return HealthResponse{
+		Status:       status,
+		Service:      "vibe-arcade-backend",
+		Version:      "1.0.0",
+		Dependencies: statuses,
+	}
+}
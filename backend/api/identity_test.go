@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/auth"
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.NewDB(&config.DatabaseConfig{Driver: database.DriverSQLite, SQLitePath: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := db.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrations.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func createTestUser(t *testing.T, db *database.DB, username string) *models.User {
+	t.Helper()
+	user := &models.User{
+		ID:       uuid.New(),
+		Email:    username + "@example.com",
+		Username: username,
+		IsActive: true,
+	}
+	if err := db.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// The acting user comes from the bearer token alone; an X-User-ID request
+// header naming someone else must not change who the request acts as.
+func TestForgedUserIDHeaderIsIgnored(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	alice := createTestUser(t, db, "alice")
+	mallory := createTestUser(t, db, "mallory")
+
+	jwtManager := auth.NewJWTManager("test-secret", time.Hour, time.Hour)
+	tokens, err := jwtManager.GenerateTokenPair(mallory.ID, mallory.Username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := SetupRoutes(db, jwtManager, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	t.Run("without a token", func(t *testing.T) {
+		gameID := uuid.NewString()
+		for _, route := range []struct{ method, path string }{
+			{http.MethodGet, "/api/v1/user/profile"},
+			{http.MethodPost, "/api/v1/games/"},
+			{http.MethodPost, "/api/v1/games/" + gameID + "/join"},
+			{http.MethodPost, "/api/v1/games/" + gameID + "/move"},
+		} {
+			req := httptest.NewRequest(route.method, route.path, nil)
+			req.Header.Set("X-User-ID", alice.ID.String())
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("%s %s: status = %d, want %d", route.method, route.path, rec.Code, http.StatusUnauthorized)
+			}
+		}
+	})
+
+	t.Run("with another user's token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/user/profile", nil)
+		req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+		req.Header.Set("X-User-ID", alice.ID.String())
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+		}
+		var profile struct {
+			User models.User `json:"user"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &profile); err != nil {
+			t.Fatal(err)
+		}
+		if profile.User.ID != mallory.ID {
+			t.Errorf("profile of %s returned, want the token's user %s", profile.User.Username, mallory.Username)
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/lobby"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+type GameInviteRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+// InviteToGame invites a player to take the open seat of a waiting game
+// the requesting user created. Invites also work for private games.
+func (h *Handler) InviteToGame(c *gin.Context) {
+	playerID := c.MustGet("userID").(uuid.UUID)
+
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
+		return
+	}
+
+	var req GameInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	game, err := h.db.GetGame(gameID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+
+	if game.Creator() != playerID {
+		apierror.Respond(c, http.StatusForbidden, "not_game_creator", "Only the game's creator can invite players")
+		return
+	}
+
+	if game.Status != models.GameStatusWaiting || game.IsFull() {
+		apierror.Respond(c, http.StatusBadRequest, "game_not_waiting", "Game is not waiting for players")
+		return
+	}
+
+	invitee, err := h.db.GetUser(req.UserID)
+	if err != nil || !invitee.IsActive {
+		apierror.Respond(c, http.StatusNotFound, "user_not_found", "User not found")
+		return
+	}
+
+	blocked, err := h.db.IsBlocked(playerID, req.UserID)
+	if err != nil {
+		log.Printf("Error checking block between %s and %s: %v", playerID, req.UserID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to send invite")
+		return
+	}
+	if blocked {
+		apierror.Respond(c, http.StatusForbidden, "invite_blocked", "Cannot invite this user")
+		return
+	}
+
+	invite, err := h.lobbies.CreateInvite(game, playerID, req.UserID)
+	if err != nil {
+		h.inviteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invite)
+}
+
+func (h *Handler) GetInvites(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	invites, err := h.lobbies.GetInvites(userID)
+	if err != nil {
+		h.inviteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invites": invites})
+}
+
+// AcceptInvite seats the player in the invite's game, as JoinGame would.
+func (h *Handler) AcceptInvite(c *gin.Context) {
+	playerID := c.MustGet("userID").(uuid.UUID)
+
+	inviteID, err := uuid.Parse(c.Param("inviteId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_invite_id", "Invalid invite ID")
+		return
+	}
+
+	invite, err := h.lobbies.ClaimInvite(playerID, inviteID)
+	if err != nil {
+		h.inviteError(c, err)
+		return
+	}
+
+	game, err := h.db.GetGame(invite.GameID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+
+	if h.seatPlayer(c, game, playerID) {
+		h.lobbies.RevokeJoinCode(game.ID)
+		h.lobbies.AnswerInvite(invite, true)
+	}
+}
+
+func (h *Handler) DeclineInvite(c *gin.Context) {
+	playerID := c.MustGet("userID").(uuid.UUID)
+
+	inviteID, err := uuid.Parse(c.Param("inviteId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_invite_id", "Invalid invite ID")
+		return
+	}
+
+	invite, err := h.lobbies.ClaimInvite(playerID, inviteID)
+	if err != nil {
+		h.inviteError(c, err)
+		return
+	}
+	h.lobbies.AnswerInvite(invite, false)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invite declined"})
+}
+
+func (h *Handler) inviteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, lobby.ErrGameInviteNotFound):
+		apierror.Respond(c, http.StatusNotFound, "invite_not_found", "Invite not found or expired")
+	case errors.Is(err, lobby.ErrCannotInviteSelf):
+		apierror.Respond(c, http.StatusBadRequest, "cannot_invite_self", "Cannot invite yourself")
+	default:
+		log.Printf("Invite error: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to process invite")
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/leaderboard"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// GetLeaderboard returns a game type's leaderboard. The view is global,
+// weekly or friends and defaults to global.
+func (h *Handler) GetLeaderboard(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	gameType := models.GameType(c.Param("gameType"))
+	if _, err := h.registry.GetEngine(gameType); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_type", "Invalid game type")
+		return
+	}
+
+	limit := leaderboard.DefaultLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			apierror.Respond(c, http.StatusBadRequest, "invalid_limit", "Invalid limit")
+			return
+		}
+	}
+
+	board, err := h.leaderboards.Get(gameType, c.DefaultQuery("view", leaderboard.ViewGlobal), userID, limit)
+	if errors.Is(err, leaderboard.ErrInvalidView) {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_view", "Invalid view")
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting %s leaderboard: %v", gameType, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get leaderboard")
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
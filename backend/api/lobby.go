@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/lobby"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+type PrivateGameResponse struct {
+	*models.Game
+	JoinCode          string    `json:"join_code"`
+	JoinCodeExpiresAt time.Time `json:"join_code_expires_at"`
+}
+
+type JoinByCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+func (h *Handler) JoinGameByCode(c *gin.Context) {
+	playerID := c.MustGet("userID").(uuid.UUID)
+
+	var req JoinByCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	gameID, err := h.lobbies.ResolveJoinCode(playerID, req.Code)
+	if err != nil {
+		h.joinCodeError(c, err)
+		return
+	}
+
+	game, err := h.db.GetGame(gameID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "join_code_invalid", "Invalid or expired join code")
+		return
+	}
+
+	// Codes are single use
+	if h.seatPlayer(c, game, playerID) {
+		h.lobbies.RevokeJoinCode(game.ID)
+	}
+}
+
+// CreateJoinCode issues a fresh code for a private game that is still
+// waiting for an opponent, replacing the previous one.
+func (h *Handler) CreateJoinCode(c *gin.Context) {
+	playerID := c.MustGet("userID").(uuid.UUID)
+
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
+		return
+	}
+
+	game, err := h.db.GetGame(gameID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+
+	if game.Creator() != playerID {
+		apierror.Respond(c, http.StatusForbidden, "not_game_creator", "Only the game's creator can issue join codes")
+		return
+	}
+
+	if !game.Private || game.Status != models.GameStatusWaiting || game.IsFull() {
+		apierror.Respond(c, http.StatusBadRequest, "game_not_private_waiting", "Game is not a private game waiting for players")
+		return
+	}
+
+	joinCode, err := h.lobbies.CreateJoinCode(playerID, game.ID)
+	if err != nil {
+		h.joinCodeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, joinCode)
+}
+
+func (h *Handler) joinCodeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, lobby.ErrInvalidJoinCode):
+		apierror.Respond(c, http.StatusNotFound, "join_code_invalid", "Invalid or expired join code")
+	case errors.Is(err, lobby.ErrJoinCodeRateLimited):
+		apierror.Respond(c, http.StatusTooManyRequests, apierror.CodeRateLimited, "Too many join code requests, try again later")
+	default:
+		log.Printf("Join code error: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to process join code")
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/game"
+	"github.com/szaher/vibeboard/backend/internal/lobby"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// JoinMatchmakingRequest queues for one game type, or for several at once
+// with game_types ("quick play").
+type JoinMatchmakingRequest struct {
+	GameType    models.GameType      `json:"game_type"`
+	GameTypes   []models.GameType    `json:"game_types" binding:"max=10"`
+	Preferences MatchPreferencesBody `json:"preferences"`
+}
+
+type MatchPreferencesBody struct {
+	TimeControls []string `json:"time_controls" binding:"max=10,dive,min=1,max=32"`
+	Variants     []string `json:"variants" binding:"max=10,dive,min=1,max=32"`
+	Rated        *bool    `json:"rated"`
+}
+
+func (h *Handler) JoinMatchmaking(c *gin.Context) {
+	playerID := c.MustGet("userID").(uuid.UUID)
+
+	var req JoinMatchmakingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	gameTypes := req.GameTypes
+	if req.GameType != "" {
+		gameTypes = append(gameTypes, req.GameType)
+	}
+	if len(gameTypes) == 0 {
+		apierror.Respond(c, http.StatusBadRequest, "game_type_required", "game_type or game_types is required")
+		return
+	}
+	for _, gameType := range gameTypes {
+		engine, err := h.registry.GetEngine(gameType)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid_game_type", "Invalid game type")
+			return
+		}
+		if !h.registry.IsEnabled(gameType) {
+			apierror.Respond(c, http.StatusServiceUnavailable, "game_type_disabled", "Game type is temporarily disabled")
+			return
+		}
+		// Any variant may be negotiated, so every game type must offer it
+		schema := game.SchemaFor(engine)
+		for _, variant := range req.Preferences.Variants {
+			if !schema.OffersVariant(variant) {
+				apierror.Respond(c, http.StatusBadRequest, "invalid_settings", fmt.Sprintf("%s has no %q variant", gameType, variant))
+				return
+			}
+		}
+	}
+	for _, timeControl := range req.Preferences.TimeControls {
+		if !game.ValidTimeControl(timeControl) {
+			apierror.Respond(c, http.StatusBadRequest, "invalid_settings", fmt.Sprintf("Unknown time control %q", timeControl))
+			return
+		}
+	}
+
+	rating := 1000 // Default rating
+	if stats, err := h.db.GetUserStats(playerID); err == nil {
+		rating = stats.Rating
+	}
+
+	preferences := lobby.MatchPreferences{
+		TimeControls: req.Preferences.TimeControls,
+		Variants:     req.Preferences.Variants,
+		Rated:        req.Preferences.Rated,
+	}
+
+	if err := h.matchmaker.JoinQuickPlay(playerID, gameTypes, rating, preferences); err != nil {
+		if errors.Is(err, lobby.ErrAlreadyQueued) {
+			apierror.Respond(c, http.StatusConflict, "already_in_queue", "Already in matchmaking queue")
+			return
+		}
+		log.Printf("Error joining matchmaking for %s: %v", playerID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to join matchmaking queue")
+		return
+	}
+
+	status, err := h.matchmaker.GetQueueStatus(playerID)
+	if err != nil {
+		c.JSON(http.StatusAccepted, gin.H{"message": "Queued"})
+		return
+	}
+	c.JSON(http.StatusAccepted, status)
+}
+
+func (h *Handler) GetMatchmakingStatus(c *gin.Context) {
+	playerID := c.MustGet("userID").(uuid.UUID)
+
+	status, err := h.matchmaker.GetQueueStatus(playerID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "not_in_queue", "Not in matchmaking queue")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *Handler) LeaveMatchmaking(c *gin.Context) {
+	playerID := c.MustGet("userID").(uuid.UUID)
+
+	if err := h.matchmaker.CancelQueue(playerID); err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to leave matchmaking queue")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left matchmaking queue"})
+}
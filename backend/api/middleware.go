@@ -1,50 +1,113 @@
 package api
 
 import (
+	"log"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/apierror"
 	"github.com/szaher/vibeboard/backend/internal/auth"
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+	"github.com/szaher/vibeboard/backend/internal/ratelimit"
 )
 
 func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
+			apierror.Respond(c, http.StatusUnauthorized, "authorization_required", "Authorization header required")
 			return
 		}
 
 		bearerToken := strings.Split(authHeader, " ")
 		if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
+			apierror.Respond(c, http.StatusUnauthorized, "invalid_authorization_header", "Invalid authorization header format")
 			return
 		}
 
 		token := bearerToken[1]
 		claims, err := jwtManager.ValidateToken(token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+			apierror.Respond(c, http.StatusUnauthorized, "invalid_token", "Invalid token")
 			return
 		}
 
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
+		if claims.Scoped() {
+			c.Set("scope", claims.Scope)
+		}
+		if claims.ExpiresAt != nil {
+			c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
+		}
 		c.Header("X-User-ID", claims.UserID.String())
 		c.Next()
 	}
 }
 
+// StreamAuthMiddleware lets connections opened with a ?ticket= through to
+// the hub, which redeems it; others need a bearer token as usual.
+func StreamAuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+	bearer := AuthMiddleware(jwtManager)
+	return func(c *gin.Context) {
+		if c.Query("ticket") != "" {
+			c.Next()
+			return
+		}
+		bearer(c)
+	}
+}
+
+func AdminMiddleware(db *database.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userID")
+		if !exists {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+			return
+		}
+
+		user, err := db.GetUser(userID.(uuid.UUID))
+		if err != nil || !user.IsActive || user.Role != models.UserRoleAdmin {
+			apierror.Respond(c, http.StatusForbidden, "admin_required", "Admin access required")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// requestIDPattern bounds the client-supplied request IDs that are echoed
+// back and logged.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestIDMiddleware tags each request with the caller's X-Request-ID,
+// or a fresh one, and echoes it in the response header and any error
+// envelope.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(apierror.RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Set(apierror.RequestIDKey, requestID)
+		c.Header(apierror.RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, If-None-Match, X-Request-ID, X-Device-ID")
+		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
+		c.Header("Access-Control-Expose-Headers", "ETag, X-Request-ID, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -55,9 +118,49 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
-func RateLimitMiddleware() gin.HandlerFunc {
-	// TODO: Implement rate limiting using Redis
+// RateLimitMiddleware counts requests against the user's quota once
+// AuthMiddleware has run, or the client IP's otherwise, and reports the
+// quota in RateLimit-* headers. Requests are let through if Redis is
+// unavailable.
+func RateLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		var result ratelimit.Result
+		var err error
+		if userID, ok := c.Get("userID"); ok {
+			result, err = limiter.AllowUser(c.Request.Context(), userID.(uuid.UUID))
+		} else {
+			result, err = limiter.AllowIP(c.Request.Context(), c.ClientIP())
+		}
+		if err != nil {
+			log.Printf("Error checking rate limit: %v", err)
+			c.Next()
+			return
+		}
+		if result.Limit == 0 {
+			c.Next()
+			return
+		}
+
+		reset := strconv.Itoa(ceilSeconds(result.Reset))
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", reset)
+		c.Header("RateLimit-Policy", strconv.Itoa(result.Limit)+";w="+strconv.Itoa(ceilSeconds(result.Window)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", reset)
+			apierror.Respond(c, http.StatusTooManyRequests, apierror.CodeRateLimited, "Rate limit exceeded")
+			return
+		}
 		c.Next()
 	}
 }
+
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,254 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// reportMessageLimit is how many direct messages between the two players
+// are attached to a report.
+const reportMessageLimit = 50
+
+type ReportRequest struct {
+	Reason  models.ReportReason `json:"reason" binding:"required,oneof=harassment cheating spam offensive_name other"`
+	Details string              `json:"details" binding:"max=1000"`
+	GameID  *uuid.UUID          `json:"game_id"`
+}
+
+type ResolveReportRequest struct {
+	Status models.ReportStatus `json:"status" binding:"required,oneof=resolved dismissed"`
+	Note   string              `json:"note" binding:"max=1000"`
+}
+
+// BlockUser hides the two players from each other: they are not matched,
+// cannot message, challenge or invite each other, and do not see each
+// other's chat. Any friendship between them ends.
+func (h *Handler) BlockUser(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+		return
+	}
+	if targetID == userID {
+		apierror.Respond(c, http.StatusBadRequest, "cannot_block_self", "Cannot block yourself")
+		return
+	}
+
+	if _, err := h.db.GetUser(targetID); err != nil {
+		apierror.Respond(c, http.StatusNotFound, "user_not_found", "User not found")
+		return
+	}
+
+	if err := h.db.BlockUser(userID, targetID); err != nil {
+		log.Printf("Error blocking %s for %s: %v", targetID, userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to block user")
+		return
+	}
+	if _, err := h.db.DeleteFriendship(userID, targetID); err != nil {
+		log.Printf("Error removing friendship between %s and %s: %v", userID, targetID, err)
+	}
+
+	h.hub.UpdateBlock(userID, targetID, true)
+	h.matchmaker.RefreshBlocks(userID, targetID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "User blocked"})
+}
+
+func (h *Handler) UnblockUser(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+		return
+	}
+
+	unblocked, err := h.db.UnblockUser(userID, targetID)
+	if err != nil {
+		log.Printf("Error unblocking %s for %s: %v", targetID, userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to unblock user")
+		return
+	}
+	if !unblocked {
+		apierror.Respond(c, http.StatusNotFound, "user_not_blocked", "User is not blocked")
+		return
+	}
+
+	// The other player may have blocked this one too
+	blocked, err := h.db.IsBlocked(userID, targetID)
+	if err != nil {
+		log.Printf("Error checking block between %s and %s: %v", userID, targetID, err)
+	} else if !blocked {
+		h.hub.UpdateBlock(userID, targetID, false)
+	}
+	h.matchmaker.RefreshBlocks(userID, targetID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unblocked"})
+}
+
+// ReportUser files a report for admins to review, attaching the two
+// players' recent direct messages and, when a game is given, the game and
+// its room chat.
+func (h *Handler) ReportUser(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	reportedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+		return
+	}
+	if reportedID == userID {
+		apierror.Respond(c, http.StatusBadRequest, "cannot_report_self", "Cannot report yourself")
+		return
+	}
+
+	var req ReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	if _, err := h.db.GetUser(reportedID); err != nil {
+		apierror.Respond(c, http.StatusNotFound, "user_not_found", "User not found")
+		return
+	}
+
+	report := &models.Report{
+		ID:         uuid.New(),
+		ReporterID: userID,
+		ReportedID: reportedID,
+		Reason:     req.Reason,
+		Details:    req.Details,
+		GameID:     req.GameID,
+	}
+
+	if req.GameID != nil {
+		game, err := h.db.GetGame(*req.GameID)
+		if err != nil || !game.HasPlayer(reportedID) {
+			apierror.Respond(c, http.StatusBadRequest, "reported_user_not_in_game", "Reported user did not play in this game")
+			return
+		}
+		report.Context.Game = game
+		report.Context.RoomChat = h.hub.RoomChat(game.ID.String(), userID, reportedID)
+	}
+
+	report.Context.DirectMessages, err = h.db.GetDirectMessagesBetween(userID, reportedID, reportMessageLimit)
+	if err != nil {
+		log.Printf("Error getting direct messages for report: %v", err)
+	}
+
+	if err := h.db.CreateReport(report); err != nil {
+		log.Printf("Error creating report: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create report")
+		return
+	}
+
+	log.Printf("User %s reported %s for %s", userID, reportedID, req.Reason)
+	c.JSON(http.StatusCreated, gin.H{"id": report.ID, "status": report.Status})
+}
+
+// GetReports lists reports for review, oldest first. ?status defaults to
+// open.
+func (h *Handler) GetReports(c *gin.Context) {
+	status := models.ReportStatus(c.DefaultQuery("status", string(models.ReportStatusOpen)))
+	switch status {
+	case models.ReportStatusOpen, models.ReportStatusResolved, models.ReportStatusDismissed:
+	default:
+		apierror.Respond(c, http.StatusBadRequest, "invalid_status", "Invalid status")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	reports, err := h.db.GetReports(status, limit, offset)
+	if err != nil {
+		log.Printf("Error getting reports: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get reports")
+		return
+	}
+	if reports == nil {
+		reports = []*models.Report{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reports": reports})
+}
+
+func (h *Handler) GetReport(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("reportId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_report_id", "Invalid report ID")
+		return
+	}
+
+	report, err := h.db.GetReport(reportID)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, "report_not_found", "Report not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting report %s: %v", reportID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get report")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *Handler) ResolveReport(c *gin.Context) {
+	adminID := c.MustGet("userID").(uuid.UUID)
+
+	reportID, err := uuid.Parse(c.Param("reportId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_report_id", "Invalid report ID")
+		return
+	}
+
+	var req ResolveReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	report, err := h.db.GetReport(reportID)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, "report_not_found", "Report not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting report %s: %v", reportID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get report")
+		return
+	}
+
+	before := gin.H{"status": report.Status}
+	report.Status = req.Status
+	report.ResolvedBy = &adminID
+	report.ResolutionNote = req.Note
+
+	resolved, err := h.db.ResolveReport(report)
+	if err != nil {
+		log.Printf("Error resolving report %s: %v", reportID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to resolve report")
+		return
+	}
+	if !resolved {
+		apierror.Respond(c, http.StatusConflict, "report_closed", "Report is already closed")
+		return
+	}
+
+	log.Printf("Report %s %s by admin %s", reportID, req.Status, adminID)
+	h.audit(c, models.AuditActionReportResolve, models.AuditTargetReport, reportID.String(), req.Note,
+		before, gin.H{"status": report.Status, "resolved_at": report.ResolvedAt})
+	c.JSON(http.StatusOK, report)
+}
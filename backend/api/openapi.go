@@ -0,0 +1,546 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/auth"
+	"github.com/szaher/vibeboard/backend/internal/leaderboard"
+	"github.com/szaher/vibeboard/backend/internal/lobby"
+	"github.com/szaher/vibeboard/backend/internal/models"
+	"github.com/szaher/vibeboard/backend/internal/websocket"
+)
+
+// apiOperation documents one route. Request and response bodies are example
+// values whose types describe the schema.
+type apiOperation struct {
+	method  string
+	path    string // as registered with gin
+	tag     string
+	summary string
+	public  bool
+	query   []apiParam
+	request interface{}
+	status  int // success status, 200 if unset
+	// response is unset for endpoints that only return {"message": "..."}
+	response interface{}
+}
+
+type apiParam struct {
+	name        string
+	description string
+}
+
+var messageResponse = gin.H{"message": ""}
+
+// apiOperations is the API contract served at /openapi.json. Every route
+// registered in SetupRoutes must be listed; missing ones are logged at
+// startup.
+var apiOperations = []apiOperation{
+	// System
+	{method: "GET", path: "/health", tag: "system", summary: "Status of the service and each dependency, with ping latency", public: true,
+		response: HealthResponse{}},
+	{method: "GET", path: "/ready", tag: "system", summary: "Readiness probe; 503 while a critical dependency is down or the server is shutting down", public: true,
+		response: HealthResponse{}},
+	{method: "GET", path: "/metrics", tag: "system", summary: "Prometheus metrics in the text exposition format", public: true},
+	{method: "GET", path: "/.well-known/jwks.json", tag: "system", summary: "Public keys that sign access tokens; empty when tokens are signed with a shared secret", public: true,
+		response: auth.JWKSet{}},
+	{method: "GET", path: "/openapi.json", tag: "system", summary: "This OpenAPI document", public: true},
+	{method: "GET", path: "/docs", tag: "system", summary: "Swagger UI for this API", public: true},
+
+	// Auth
+	{method: "POST", path: "/api/v1/auth/register", tag: "auth", summary: "Create an account", public: true,
+		request: RegisterRequest{}, status: http.StatusCreated, response: gin.H{"user": models.User{}, "tokens": auth.TokenPair{}}},
+	{method: "POST", path: "/api/v1/auth/login", tag: "auth", summary: "Log in with email and password", public: true,
+		request: LoginRequest{}, response: gin.H{"user": models.User{}, "tokens": auth.TokenPair{}}},
+	{method: "POST", path: "/api/v1/auth/login/verify", tag: "auth", summary: "Finish a login from a new device or location with the emailed code", public: true,
+		request: VerifyLoginRequest{}, response: gin.H{"user": models.User{}, "tokens": auth.TokenPair{}}},
+	{method: "POST", path: "/api/v1/auth/refresh", tag: "auth", summary: "Exchange a refresh token for a new token pair", public: true,
+		request: RefreshRequest{}, response: gin.H{"tokens": auth.TokenPair{}}},
+	{method: "POST", path: "/api/v1/auth/verify-email", tag: "auth", summary: "Confirm an email change with the token from the verification link", public: true,
+		request: VerifyEmailRequest{}, response: gin.H{"user": models.User{}}},
+	{method: "POST", path: "/api/v1/auth/magic-link", tag: "auth", summary: "Email a one-time login link", public: true,
+		request: LoginLinkRequest{}, response: gin.H{"message": ""}},
+	{method: "POST", path: "/api/v1/auth/magic-link/login", tag: "auth", summary: "Log in with the token from a login link", public: true,
+		request: LoginLinkLoginRequest{}, response: gin.H{"user": models.User{}, "tokens": auth.TokenPair{}}},
+	{method: "POST", path: "/api/v1/auth/oauth/:provider", tag: "auth", summary: "Sign in with a Google or Apple authorization code, creating the account on first login", public: true,
+		request: OAuthLoginRequest{}, response: gin.H{"user": models.User{}, "tokens": auth.TokenPair{}, "created": false}},
+
+	// Users
+	{method: "GET", path: "/api/v1/user/profile", tag: "users", summary: "Get your account and stats",
+		response: gin.H{"user": models.User{}, "stats": models.UserStats{}, "game_filters": []models.SavedGameFilter{}}},
+	{method: "PATCH", path: "/api/v1/user/profile", tag: "users", summary: "Update your avatar and profile visibility",
+		request: UpdateProfileRequest{}, response: gin.H{"user": models.User{}}},
+	{method: "DELETE", path: "/api/v1/user", tag: "users", summary: "Delete your account; past games show a placeholder name",
+		request: DeleteAccountRequest{}},
+	{method: "POST", path: "/api/v1/user/tokens", tag: "users", summary: "Issue an access token restricted to the spectate or stats:read scope",
+		request: CreateScopedTokenRequest{}, response: ScopedTokenResponse{}},
+	{method: "GET", path: "/api/v1/user/rating-history", tag: "users", summary: "List your latest rating changes, oldest first",
+		query: []apiParam{
+			{"game_type", "Only list changes from games of this type"},
+			{"since", "RFC 3339 time of the earliest change"},
+			{"limit", "Number of changes, default 100, at most 500"},
+		},
+		response: gin.H{"history": []models.RatingChange{}}},
+	{method: "GET", path: "/api/v1/user/season-rewards", tag: "seasons", summary: "List the rewards you earned in past seasons",
+		response: gin.H{"rewards": []models.SeasonRewardGrant{}}},
+	{method: "GET", path: "/api/v1/user/tournaments", tag: "tournaments", summary: "Your tournaments and their rounds still to start, soonest first",
+		response: TournamentSchedule{}},
+	{method: "GET", path: "/api/v1/user/game-filters", tag: "users", summary: "List your saved game filters by name",
+		response: gin.H{"filters": []models.SavedGameFilter{}}},
+	{method: "POST", path: "/api/v1/user/game-filters", tag: "users", summary: "Save a named game filter for GET /games?filter=",
+		request: SaveGameFilterRequest{}, status: http.StatusCreated, response: models.SavedGameFilter{}},
+	{method: "DELETE", path: "/api/v1/user/game-filters/:filterId", tag: "users", summary: "Delete a saved game filter"},
+	{method: "GET", path: "/api/v1/user/logins", tag: "users", summary: "List your recent logins, flagging suspicious ones",
+		response: gin.H{"logins": []models.LoginEvent{}}},
+	{method: "PUT", path: "/api/v1/user/password", tag: "users", summary: "Change your password",
+		request: ChangePasswordRequest{}},
+	{method: "POST", path: "/api/v1/user/email", tag: "users", summary: "Start changing your email by mailing a verification link to the new address",
+		request: ChangeEmailRequest{}, status: http.StatusAccepted},
+	{method: "PUT", path: "/api/v1/user/username", tag: "users", summary: "Change your username, at most once per cooldown",
+		request: ChangeUsernameRequest{}, response: gin.H{"user": models.User{}}},
+	{method: "GET", path: "/api/v1/user/games", tag: "users", summary: "Get your active, waiting and recently finished games",
+		query: []apiParam{
+			{"recent", "Number of finished games, default 10, at most 50"},
+			{"tag", "Only list games with this tag"},
+		},
+		response: UserGamesResponse{}},
+	{method: "POST", path: "/api/v1/users/stats", tag: "users", summary: "Get the overall records of up to 100 players",
+		request: UsersStatsRequest{}, response: gin.H{"stats": []models.PlayerStats{}}},
+	{method: "GET", path: "/api/v1/users/search", tag: "users", summary: "Search players by username prefix or similar name",
+		query: []apiParam{
+			{"q", "Username or part of one, 1 to 50 characters"},
+			{"limit", "Number of results, default 20, at most 50"},
+		},
+		response: gin.H{"users": []models.UserSummary{}}},
+	{method: "GET", path: "/api/v1/users/:id/profile", tag: "users", summary: "Get a player's public profile",
+		response: PublicProfileResponse{}},
+	{method: "GET", path: "/api/v1/users/:id/stats", tag: "users", summary: "Get a player's record, win rate, win streaks and average game length per game type",
+		response: gin.H{"stats": []models.GameTypeStats{}}},
+	{method: "GET", path: "/api/v1/users/:id/head-to-head", tag: "users", summary: "Get your record against a player",
+		response: gin.H{"head_to_head": models.HeadToHead{}}},
+	{method: "POST", path: "/api/v1/users/:id/challenge", tag: "challenges", summary: "Challenge a player to a game",
+		request: ChallengeRequest{}, status: http.StatusCreated, response: lobby.Challenge{}},
+	{method: "POST", path: "/api/v1/users/:id/friend", tag: "friends", summary: "Send a friend request, or accept theirs if they already sent one",
+		status: http.StatusCreated, response: gin.H{"user_id": "", "status": models.FriendshipStatusPending}},
+	{method: "POST", path: "/api/v1/users/:id/block", tag: "users", summary: "Block a player"},
+	{method: "DELETE", path: "/api/v1/users/:id/block", tag: "users", summary: "Unblock a player"},
+	{method: "POST", path: "/api/v1/users/:id/report", tag: "users", summary: "Report a player to the admins",
+		request: ReportRequest{}, status: http.StatusCreated, response: gin.H{"id": "", "status": models.ReportStatusOpen}},
+	{method: "POST", path: "/api/v1/chat/messages/:messageId/report", tag: "users", summary: "Report a room chat message to the admins",
+		request: ReportChatMessageRequest{}, status: http.StatusCreated, response: gin.H{"id": "", "status": models.ReportStatusOpen}},
+
+	// Games
+	{method: "POST", path: "/api/v1/games/", tag: "games", summary: "Create a game; private games include a join code",
+		request: CreateGameRequest{}, status: http.StatusCreated, response: PrivateGameResponse{}},
+	{method: "GET", path: "/api/v1/games/", tag: "games", summary: "List games",
+		query: []apiParam{
+			{"status", "waiting, in_progress, completed or abandoned"},
+			{"type", "Game type"},
+			{"rated", "true for rated games only, false for casual games only"},
+			{"tag", "tournament, friendly or study"},
+			{"filter", "ID of one of your saved filters; the other parameters override it"},
+			{"limit", "Page size, default 20"},
+			{"offset", "Page offset, default 0"},
+		},
+		response: gin.H{"games": []models.Game{}}},
+	{method: "GET", path: "/api/v1/games/bots", tag: "games", summary: "List the chess bots to play against, weakest first",
+		response: gin.H{"bots": []models.Bot{}}},
+	{method: "POST", path: "/api/v1/games/bots", tag: "games", summary: "Start a casual chess game against a bot",
+		request: CreateBotGameRequest{}, status: http.StatusCreated, response: models.Game{}},
+	{method: "GET", path: "/api/v1/games/types", tag: "games", summary: "List game types accepting new games and the settings each offers",
+		response: gin.H{"game_types": []GameTypeOptions{}}},
+	{method: "GET", path: "/api/v1/games/live", tag: "spectating", summary: "List in-progress public games to spectate",
+		query: []apiParam{
+			{"type", "Game type"},
+			{"tag", "tournament, friendly or study"},
+			{"min_rating", "Lowest rating of both players"},
+			{"max_rating", "Highest rating of both players"},
+			{"sort", "recent (default) for the newest games first, or popular for the most watched"},
+			{"limit", "Page size, default 20, at most 50"},
+			{"offset", "Page offset, default 0"},
+		},
+		response: gin.H{"games": []models.LiveGame{}}},
+	{method: "GET", path: "/api/v1/games/:gameId", tag: "games", summary: "Get a game; send its ETag in If-None-Match to get 304 when unchanged",
+		response: models.Game{}},
+	{method: "DELETE", path: "/api/v1/games/:gameId", tag: "games", summary: "Cancel your game while it waits for players"},
+	{method: "PUT", path: "/api/v1/games/:gameId/visibility", tag: "games", summary: "Change who can find and watch your game",
+		request: GameVisibilityRequest{}, response: models.Game{}},
+	{method: "PUT", path: "/api/v1/games/:gameId/tags", tag: "games", summary: "Replace your game's tags",
+		request: GameTagsRequest{}, response: models.Game{}},
+	{method: "GET", path: "/api/v1/games/:gameId/replay", tag: "games", summary: "Rebuild a game's state after a number of moves",
+		query:    []apiParam{{"move", "Number of moves to apply, default all"}},
+		response: GameReplayResponse{}},
+	{method: "GET", path: "/api/v1/games/:gameId/chat", tag: "games", summary: "List a game's chat, newest first",
+		query: []apiParam{
+			{"limit", "Page size, default 50, at most 200"},
+			{"offset", "Page offset, default 0"},
+		},
+		response: gin.H{"messages": []models.ChatMessage{}}},
+	{method: "POST", path: "/api/v1/games/:gameId/join", tag: "games", summary: "Take the open seat of a public waiting game",
+		response: models.Game{}},
+	{method: "POST", path: "/api/v1/games/join-by-code", tag: "games", summary: "Join a private game with its join code",
+		request: JoinByCodeRequest{}, response: models.Game{}},
+	{method: "POST", path: "/api/v1/games/:gameId/join-code", tag: "games", summary: "Issue a new join code for your private game",
+		status: http.StatusCreated, response: lobby.JoinCode{}},
+	{method: "POST", path: "/api/v1/games/:gameId/invite", tag: "invites", summary: "Invite a player to your waiting game",
+		request: GameInviteRequest{}, status: http.StatusCreated, response: lobby.GameInvite{}},
+	{method: "POST", path: "/api/v1/games/:gameId/spectate", tag: "spectating", summary: "Join your WebSocket connections to a game's room as spectators",
+		response: SpectateResponse{}},
+	{method: "POST", path: "/api/v1/games/:gameId/move", tag: "games", summary: "Submit a move; moves are normally sent over the WebSocket",
+		request: MakeMoveRequest{}},
+	{method: "POST", path: "/api/v1/games/:gameId/pause", tag: "games", summary: "Ask to pause a game, or agree to the other player's request",
+		response: models.Game{}},
+	{method: "POST", path: "/api/v1/games/:gameId/resume", tag: "games", summary: "Ask to resume a paused game, or agree to the other player's request",
+		response: models.Game{}},
+	{method: "DELETE", path: "/api/v1/games/:gameId/pause-request", tag: "games", summary: "Withdraw or decline a pending pause or resume request",
+		response: models.Game{}},
+	{method: "POST", path: "/api/v1/games/:gameId/adjudication", tag: "games", summary: "Ask moderators to settle a stuck or disputed game",
+		request: AdjudicationRequest{}, status: http.StatusCreated, response: models.Adjudication{}},
+	{method: "GET", path: "/api/v1/series/:seriesId", tag: "games", summary: "Get a best-of-N series with its score and games",
+		response: models.Series{}},
+
+	// Game invites
+	{method: "GET", path: "/api/v1/invites", tag: "invites", summary: "List pending game invites sent to you",
+		response: gin.H{"invites": []lobby.GameInvite{}}},
+	{method: "POST", path: "/api/v1/invites/:inviteId/accept", tag: "invites", summary: "Accept an invite and join its game",
+		response: models.Game{}},
+	{method: "POST", path: "/api/v1/invites/:inviteId/decline", tag: "invites", summary: "Decline an invite"},
+
+	// Challenges
+	{method: "GET", path: "/api/v1/challenges", tag: "challenges", summary: "List pending challenges sent to you",
+		response: gin.H{"challenges": []lobby.Challenge{}}},
+	{method: "POST", path: "/api/v1/challenges/:challengeId/accept", tag: "challenges", summary: "Accept a challenge and create its game",
+		status: http.StatusCreated, response: models.Game{}},
+	{method: "POST", path: "/api/v1/challenges/:challengeId/decline", tag: "challenges", summary: "Decline a challenge"},
+
+	// Friends
+	{method: "GET", path: "/api/v1/friends", tag: "friends", summary: "List your friends and whether they are online",
+		response: gin.H{"friends": []models.Friend{}}},
+	{method: "DELETE", path: "/api/v1/friends/:id", tag: "friends", summary: "Remove a friend or withdraw a request"},
+	{method: "GET", path: "/api/v1/friends/requests", tag: "friends", summary: "List pending friend requests sent to you",
+		response: gin.H{"requests": []models.Friend{}}},
+	{method: "POST", path: "/api/v1/friends/requests/:id/accept", tag: "friends", summary: "Accept a friend request"},
+	{method: "POST", path: "/api/v1/friends/requests/:id/decline", tag: "friends", summary: "Decline a friend request"},
+
+	// Leaderboards
+	{method: "GET", path: "/api/v1/leaderboards/:gameType", tag: "leaderboards", summary: "Get a game type's leaderboard with your own entry",
+		query: []apiParam{
+			{"view", "global, weekly or friends; default global"},
+			{"limit", "Number of entries, default 50, at most 100"},
+		},
+		response: leaderboard.Leaderboard{}},
+
+	// Seasons
+	{method: "GET", path: "/api/v1/seasons", tag: "seasons", summary: "List competitive seasons, latest first",
+		query: []apiParam{
+			{"limit", "Page size, default 20"},
+			{"offset", "Page offset, default 0"},
+		},
+		response: gin.H{"seasons": []models.Season{}}},
+	{method: "GET", path: "/api/v1/seasons/current", tag: "seasons", summary: "Get the season running now",
+		response: models.Season{}},
+	{method: "GET", path: "/api/v1/seasons/:seasonId", tag: "seasons", summary: "Get a season",
+		response: models.Season{}},
+	{method: "GET", path: "/api/v1/seasons/:seasonId/leaderboards/:gameType", tag: "seasons", summary: "Get a game type's season leaderboard with your own entry",
+		query: []apiParam{
+			{"limit", "Number of entries, default 50, at most 100"},
+		},
+		response: SeasonLeaderboard{}},
+
+	// Tournaments
+	{method: "GET", path: "/api/v1/tournaments", tag: "tournaments", summary: "List tournaments open for registration, soonest first",
+		query: []apiParam{
+			{"game_type", "Only list tournaments of this game type"},
+			{"limit", "Page size, default 20"},
+			{"offset", "Page offset, default 0"},
+		},
+		response: gin.H{"tournaments": []models.Tournament{}}},
+	{method: "GET", path: "/api/v1/tournaments/:tournamentId", tag: "tournaments", summary: "Get a tournament and whether you are registered",
+		response: TournamentResponse{}},
+	{method: "POST", path: "/api/v1/tournaments/:tournamentId/registration", tag: "tournaments", summary: "Register for a tournament that has not started",
+		status: http.StatusCreated, response: TournamentResponse{}},
+	{method: "DELETE", path: "/api/v1/tournaments/:tournamentId/registration", tag: "tournaments", summary: "Withdraw before a tournament starts"},
+
+	// GraphQL
+	{method: "POST", path: "/api/v1/graphql", tag: "graphql", summary: "Run a GraphQL query over users, games, moves, stats and leaderboards",
+		request: GraphQLRequest{}, response: gin.H{"data": map[string]interface{}{}, "errors": []gin.H{{"message": ""}}}},
+
+	// Matchmaking
+	{method: "POST", path: "/api/v1/matchmaking/queue", tag: "matchmaking", summary: "Join the matchmaking queue",
+		request: JoinMatchmakingRequest{}, status: http.StatusAccepted, response: lobby.MatchmakingRequest{}},
+	{method: "GET", path: "/api/v1/matchmaking/queue", tag: "matchmaking", summary: "Get your queue entry",
+		response: lobby.MatchmakingRequest{}},
+	{method: "DELETE", path: "/api/v1/matchmaking/queue", tag: "matchmaking", summary: "Leave the queue"},
+
+	// Real-time transports
+	{method: "POST", path: "/api/v1/ws/ticket", tag: "realtime", summary: "Get a short-lived one-time ticket for opening a WebSocket or SSE connection",
+		response: gin.H{"ticket": "", "expires_in": 30}},
+	{method: "GET", path: "/api/v1/ws", tag: "realtime", summary: "Upgrade to a WebSocket connection, authenticated by a bearer token or ?ticket="},
+	{method: "GET", path: "/api/v1/sse", tag: "realtime", summary: "Open a Server-Sent Events stream, the fallback for clients without WebSockets, authenticated by a bearer token or ?ticket="},
+	{method: "POST", path: "/api/v1/sse/:clientId/messages", tag: "realtime", summary: "Send a message on an SSE connection",
+		request: websocket.Message{}},
+
+	// Admin
+	{method: "GET", path: "/api/v1/admin/game-types", tag: "admin", summary: "List game types and whether they accept new games",
+		response: gin.H{"game_types": []GameTypeStatus{}}},
+	{method: "PUT", path: "/api/v1/admin/game-types/:gameType", tag: "admin", summary: "Enable or disable a game type",
+		request: SetGameTypeEnabledRequest{}, response: GameTypeStatus{}},
+	{method: "GET", path: "/api/v1/admin/hub/rooms", tag: "admin", summary: "List this instance's rooms",
+		response: gin.H{"rooms": []websocket.RoomInfo{}}},
+	{method: "GET", path: "/api/v1/admin/hub/clients", tag: "admin", summary: "List this instance's connections",
+		query:    []apiParam{{"user_id", "Only list this user's connections"}},
+		response: gin.H{"clients": []websocket.ClientInfo{}}},
+	{method: "GET", path: "/api/v1/admin/hub/clients/:clientId", tag: "admin", summary: "Inspect a connection",
+		response: websocket.ClientInfo{}},
+	{method: "DELETE", path: "/api/v1/admin/hub/clients/:clientId", tag: "admin", summary: "Disconnect a connection on any instance",
+		request: DisconnectClientRequest{}},
+	{method: "POST", path: "/api/v1/admin/hub/announcements", tag: "admin", summary: "Send an announcement to every connection",
+		request: AnnouncementRequest{}},
+	{method: "GET", path: "/api/v1/admin/matchmaking/stats", tag: "admin", summary: "Matchmaking queue stats per game type",
+		response: gin.H{"game_types": []lobby.MatchmakingStats{}, "tolerance": lobby.TolerancePolicy{}}},
+	{method: "GET", path: "/api/v1/admin/reports", tag: "admin", summary: "List player reports, oldest first",
+		query: []apiParam{
+			{"status", "open, resolved or dismissed; default open"},
+			{"limit", "Page size, default 20"},
+			{"offset", "Page offset, default 0"},
+		},
+		response: gin.H{"reports": []models.Report{}}},
+	{method: "GET", path: "/api/v1/admin/reports/:reportId", tag: "admin", summary: "Get a report with its attached context",
+		response: models.Report{}},
+	{method: "POST", path: "/api/v1/admin/reports/:reportId/resolve", tag: "admin", summary: "Close an open report",
+		request: ResolveReportRequest{}, response: models.Report{}},
+	{method: "GET", path: "/api/v1/admin/chat/messages", tag: "admin", summary: "List room chat, newest first, including deleted messages",
+		query: []apiParam{
+			{"room_id", "Only list this room's chat"},
+			{"user_id", "Only list this user's messages"},
+			{"limit", "Page size, default 50, at most 200"},
+			{"offset", "Page offset, default 0"},
+		},
+		response: gin.H{"messages": []models.ChatMessage{}}},
+	{method: "DELETE", path: "/api/v1/admin/chat/messages/:messageId", tag: "admin", summary: "Delete a chat message and hide it from its room",
+		request: DeleteChatMessageRequest{}},
+	{method: "PUT", path: "/api/v1/admin/users/:id/mute", tag: "admin", summary: "Mute a player's chat and direct messages for some minutes",
+		request: MuteRequest{}, response: models.Mute{}},
+	{method: "DELETE", path: "/api/v1/admin/users/:id/mute", tag: "admin", summary: "Lift a player's mute"},
+	{method: "POST", path: "/api/v1/admin/games/:gameId/pause", tag: "admin", summary: "Pause an in-progress game until an admin resumes it",
+		request: AdminPauseRequest{}, response: models.Game{}},
+	{method: "POST", path: "/api/v1/admin/games/:gameId/resume", tag: "admin", summary: "Resume a paused game",
+		request: AdminPauseRequest{}, response: models.Game{}},
+	{method: "GET", path: "/api/v1/admin/adjudications", tag: "admin", summary: "List game adjudication requests, oldest first",
+		query: []apiParam{
+			{"status", "open, resolved or dismissed; default open"},
+			{"limit", "Page size, default 20"},
+			{"offset", "Page offset, default 0"},
+		},
+		response: gin.H{"adjudications": []models.Adjudication{}}},
+	{method: "GET", path: "/api/v1/admin/adjudications/:adjudicationId", tag: "admin", summary: "Get an adjudication request with its game",
+		response: AdjudicationResponse{}},
+	{method: "POST", path: "/api/v1/admin/adjudications/:adjudicationId/resolve", tag: "admin", summary: "Declare a winner, a draw or void the game, or dismiss the request",
+		request: ResolveAdjudicationRequest{}, response: AdjudicationResponse{}},
+	{method: "POST", path: "/api/v1/admin/seasons", tag: "admin", summary: "Schedule a season; seasons may not overlap",
+		request: CreateSeasonRequest{}, status: http.StatusCreated, response: models.Season{}},
+	{method: "DELETE", path: "/api/v1/admin/seasons/:seasonId", tag: "admin", summary: "Delete a season that has not started"},
+	{method: "POST", path: "/api/v1/admin/tournaments", tag: "admin", summary: "Schedule a tournament",
+		request: CreateTournamentRequest{}, status: http.StatusCreated, response: models.Tournament{}},
+	{method: "POST", path: "/api/v1/admin/tournaments/:tournamentId/cancel", tag: "admin", summary: "Cancel a tournament that has not ended and tell its players",
+		request: CancelTournamentRequest{}, response: models.Tournament{}},
+	{method: "GET", path: "/api/v1/admin/audit-log", tag: "admin", summary: "List privileged actions taken by admins",
+		query: []apiParam{
+			{"actor_id", "Only list this admin's actions"},
+			{"action", "Only list this action, e.g. user.mute"},
+			{"target_type", "Only list actions on this kind of target"},
+			{"target_id", "Only list actions on this target"},
+			{"since", "RFC 3339 time of the earliest entry"},
+			{"until", "RFC 3339 time before the latest entry"},
+			{"limit", "Page size, default 50, at most 200"},
+			{"offset", "Page offset, default 0"},
+		},
+		response: gin.H{"entries": []models.AuditEntry{}}},
+}
+
+var (
+	openAPISpec     []byte
+	openAPISpecOnce sync.Once
+	ginParamPattern = regexp.MustCompile(`:(\w+)`)
+)
+
+// GetOpenAPISpec serves the API contract as an OpenAPI 3 document.
+func (h *Handler) GetOpenAPISpec(c *gin.Context) {
+	openAPISpecOnce.Do(func() {
+		var err error
+		openAPISpec, err = json.Marshal(buildOpenAPISpec())
+		if err != nil {
+			log.Printf("Error building OpenAPI spec: %v", err)
+		}
+	})
+	c.Data(http.StatusOK, "application/json", openAPISpec)
+}
+
+// GetAPIDocs serves Swagger UI for the spec.
+func (h *Handler) GetAPIDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
+
+func buildOpenAPISpec() map[string]interface{} {
+	schemas := newSchemaRegistry()
+	errorResponse := map[string]interface{}{
+		"description": "Error",
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": schemas.schemaOf(apierror.Response{}),
+			},
+		},
+	}
+
+	paths := make(map[string]map[string]interface{})
+	for _, op := range apiOperations {
+		path := ginParamPattern.ReplaceAllString(op.path, "{$1}")
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+
+		var parameters []interface{}
+		for _, match := range ginParamPattern.FindAllStringSubmatch(op.path, -1) {
+			parameters = append(parameters, map[string]interface{}{
+				"name":     match[1],
+				"in":       "path",
+				"required": true,
+				"schema":   pathParamSchema(match[1]),
+			})
+		}
+		for _, param := range op.query {
+			parameters = append(parameters, map[string]interface{}{
+				"name":        param.name,
+				"in":          "query",
+				"description": param.description,
+				"schema":      map[string]interface{}{"type": "string"},
+			})
+		}
+
+		status := op.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := map[string]interface{}{"description": http.StatusText(status)}
+		response := op.response
+		if response == nil && op.method != "GET" {
+			response = messageResponse
+		}
+		if response != nil {
+			success["content"] = map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": schemas.schemaOf(response)},
+			}
+		}
+
+		operation := map[string]interface{}{
+			"tags":        []string{op.tag},
+			"summary":     op.summary,
+			"operationId": operationID(op),
+			"responses": map[string]interface{}{
+				strconv.Itoa(status): success,
+				"default":            errorResponse,
+			},
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+		if op.request != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": schemas.schemaOf(op.request)},
+				},
+			}
+		}
+		if op.public {
+			operation["security"] = []interface{}{}
+		}
+
+		paths[path][strings.ToLower(op.method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Vibe Arcade API",
+			"version":     "1.0.0",
+			"description": "REST API of the Vibe Arcade backend. Real-time messages are documented in the README.",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+		"security": []interface{}{map[string]interface{}{"bearerAuth": []string{}}},
+	}
+}
+
+// checkAPIDocs logs routes missing from apiOperations, so the spec does not
+// silently fall behind.
+func checkAPIDocs(routes gin.RoutesInfo) {
+	documented := make(map[string]bool, len(apiOperations))
+	for _, op := range apiOperations {
+		documented[op.method+" "+op.path] = true
+	}
+
+	var missing []string
+	for _, route := range routes {
+		if !documented[route.Method+" "+route.Path] {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+	sort.Strings(missing)
+	for _, route := range missing {
+		log.Printf("Route %s is missing from the OpenAPI spec", route)
+	}
+}
+
+// pathParamSchema describes a path parameter. Parameters named id or
+// ending in Id are UUIDs.
+func pathParamSchema(name string) map[string]interface{} {
+	if name == "id" || strings.HasSuffix(name, "Id") {
+		return map[string]interface{}{"type": "string", "format": "uuid"}
+	}
+	return map[string]interface{}{"type": "string"}
+}
+
+// operationID derives a stable identifier such as postApiV1GamesGameIdJoin.
+func operationID(op apiOperation) string {
+	var id strings.Builder
+	id.WriteString(strings.ToLower(op.method))
+	for _, part := range strings.FieldsFunc(op.path, func(r rune) bool {
+		return r == '/' || r == ':' || r == '-' || r == '.' || r == '_'
+	}) {
+		id.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return id.String()
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Vibe Arcade API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
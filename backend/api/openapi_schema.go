@@ -0,0 +1,205 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	uuidType       = reflect.TypeOf(uuid.UUID{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaRegistry derives JSON schemas from the Go types handlers bind and
+// return, so the spec follows the code. Named structs become components.
+type schemaRegistry struct {
+	components map[string]interface{}
+	// names maps each registered type to its component name
+	names map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		components: make(map[string]interface{}),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// schemaOf describes a value. gin.H values describe an object with their
+// keys, using the dynamic type of each value.
+func (r *schemaRegistry) schemaOf(v interface{}) map[string]interface{} {
+	if fields, ok := v.(gin.H); ok {
+		properties := make(map[string]interface{}, len(fields))
+		for name, value := range fields {
+			properties[name] = r.schemaOf(value)
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	}
+	return r.schemaFor(reflect.TypeOf(v))
+}
+
+func (r *schemaRegistry) schemaFor(t reflect.Type) map[string]interface{} {
+	if t == nil {
+		return map[string]interface{}{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]interface{}{"type": "integer", "description": "nanoseconds"}
+	case uuidType:
+		return map[string]interface{}{"type": "string", "format": "uuid"}
+	case rawMessageType:
+		return map[string]interface{}{"description": "Game or message specific JSON"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": r.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return r.structRef(t)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// structRef registers a named struct as a component and refers to it.
+// Anonymous structs are described inline.
+func (r *schemaRegistry) structRef(t reflect.Type) map[string]interface{} {
+	if t.Name() == "" {
+		return r.structSchema(t)
+	}
+
+	name, ok := r.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := r.components[name]; taken {
+			name = pathBase(t.PkgPath()) + name
+		}
+		r.names[t] = name
+		// Reserve the name before describing fields, which may refer back
+		r.components[name] = nil
+		r.components[name] = r.structSchema(t)
+	}
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	r.addFields(t, properties, &required)
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (r *schemaRegistry) addFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs without a name are flattened, as encoding/json does
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				r.addFields(embedded, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := r.schemaFor(field.Type)
+		if applyBinding(schema, field.Tag.Get("binding")) {
+			*required = append(*required, name)
+		}
+		properties[name] = schema
+	}
+}
+
+// applyBinding adds the validation rules of a binding tag to a field's
+// schema and reports whether the field is required.
+func applyBinding(schema map[string]interface{}, binding string) bool {
+	if binding == "" {
+		return false
+	}
+
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		if rule == "dive" {
+			break // the rest applies to the elements
+		}
+
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			schema["format"] = "email"
+		case "oneof":
+			schema["enum"] = strings.Fields(value)
+		case "min", "max":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			schema[boundKeyword(schema["type"], key)] = n
+		}
+	}
+	return required
+}
+
+func boundKeyword(schemaType interface{}, bound string) string {
+	suffix := "imum"
+	switch schemaType {
+	case "string":
+		suffix = "Length"
+	case "array":
+		suffix = "Items"
+	}
+	return bound + suffix
+}
+
+func pathBase(pkgPath string) string {
+	base := pkgPath[strings.LastIndex(pkgPath, "/")+1:]
+	if base == "" {
+		return ""
+	}
+	return strings.ToUpper(base[:1]) + base[1:]
+}
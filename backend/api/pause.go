@@ -0,0 +1,299 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// AdminPauseRequest is the optional body for admin pauses and resumes
+type AdminPauseRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// pauseSnapshot is the part of a game an admin pause or resume changes,
+// recorded in the audit log.
+type pauseSnapshot struct {
+	PausedAt         *time.Time `json:"paused_at"`
+	PausedByAdmin    bool       `json:"paused_by_admin"`
+	PauseRequestedBy *uuid.UUID `json:"pause_requested_by,omitempty"`
+}
+
+func newPauseSnapshot(game *models.Game) pauseSnapshot {
+	return pauseSnapshot{
+		PausedAt:         game.PausedAt,
+		PausedByAdmin:    game.PausedByAdmin,
+		PauseRequestedBy: game.PauseRequestedBy,
+	}
+}
+
+// playerGame loads the in-progress game named in the path for one of its
+// players, responding with an error and returning nil otherwise.
+func (h *Handler) playerGame(c *gin.Context, userID uuid.UUID) *models.Game {
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
+		return nil
+	}
+
+	game, err := h.db.GetGame(gameID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return nil
+	}
+	if !game.HasPlayer(userID) {
+		apierror.Respond(c, http.StatusForbidden, "not_a_player", "Player not in this game")
+		return nil
+	}
+	if game.Status != models.GameStatusInProgress {
+		apierror.Respond(c, http.StatusConflict, "game_not_in_progress", "Game is not in progress")
+		return nil
+	}
+	return game
+}
+
+// pauseChanged reloads a game after its pause state changed, tells its room
+// and responds with it.
+func (h *Handler) pauseChanged(c *gin.Context, gameID uuid.UUID, status int) {
+	game, err := h.db.GetGame(gameID)
+	if err != nil {
+		log.Printf("Error reloading game %s: %v", gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to load game")
+		return
+	}
+	h.hub.NotifyPauseChanged(game)
+	c.JSON(status, game)
+}
+
+// PauseGame asks to pause a game, or agrees to pause it if the other player
+// already asked. The clock stops and moves are refused until it resumes.
+func (h *Handler) PauseGame(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	game := h.playerGame(c, userID)
+	if game == nil {
+		return
+	}
+	if game.PausedAt != nil {
+		apierror.Respond(c, http.StatusConflict, "game_already_paused", "Game is already paused")
+		return
+	}
+	if game.PauseRequestedBy != nil && *game.PauseRequestedBy == userID {
+		apierror.Respond(c, http.StatusConflict, "pause_already_requested", "Waiting for the other player to agree")
+		return
+	}
+
+	if game.PauseRequestedBy != nil {
+		paused, err := h.db.AcceptPause(game.ID, *game.PauseRequestedBy)
+		if err != nil {
+			log.Printf("Error pausing game %s: %v", game.ID, err)
+			apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to pause game")
+			return
+		}
+		if !paused {
+			apierror.Respond(c, http.StatusConflict, "pause_request_changed", "The pause request was withdrawn")
+			return
+		}
+		h.pauseChanged(c, game.ID, http.StatusOK)
+		return
+	}
+
+	requested, err := h.db.RequestPauseChange(game.ID, userID, false)
+	if err != nil {
+		log.Printf("Error requesting pause of game %s: %v", game.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to request pause")
+		return
+	}
+	if !requested {
+		apierror.Respond(c, http.StatusConflict, "pause_request_changed", "The game's pause state changed, try again")
+		return
+	}
+	h.pauseChanged(c, game.ID, http.StatusAccepted)
+}
+
+// ResumeGame asks to resume a paused game, or agrees to resume it if the
+// other player already asked. Games paused by an admin can only be resumed
+// by an admin.
+func (h *Handler) ResumeGame(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	game := h.playerGame(c, userID)
+	if game == nil {
+		return
+	}
+	if game.PausedAt == nil {
+		apierror.Respond(c, http.StatusConflict, "game_not_paused", "Game is not paused")
+		return
+	}
+	if game.PausedByAdmin {
+		apierror.Respond(c, http.StatusForbidden, "paused_by_admin", "Game was paused by an admin")
+		return
+	}
+	if game.PauseRequestedBy != nil && *game.PauseRequestedBy == userID {
+		apierror.Respond(c, http.StatusConflict, "resume_already_requested", "Waiting for the other player to agree")
+		return
+	}
+
+	if game.PauseRequestedBy != nil {
+		resumed, err := h.db.ResumeGame(game, game.PauseRequestedBy)
+		if err != nil {
+			log.Printf("Error resuming game %s: %v", game.ID, err)
+			apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to resume game")
+			return
+		}
+		if !resumed {
+			apierror.Respond(c, http.StatusConflict, "pause_request_changed", "The resume request was withdrawn")
+			return
+		}
+		h.pauseChanged(c, game.ID, http.StatusOK)
+		return
+	}
+
+	requested, err := h.db.RequestPauseChange(game.ID, userID, true)
+	if err != nil {
+		log.Printf("Error requesting resume of game %s: %v", game.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to request resume")
+		return
+	}
+	if !requested {
+		apierror.Respond(c, http.StatusConflict, "pause_request_changed", "The game's pause state changed, try again")
+		return
+	}
+	h.pauseChanged(c, game.ID, http.StatusAccepted)
+}
+
+// CancelPauseRequest withdraws the caller's pause or resume request, or
+// declines the other player's.
+func (h *Handler) CancelPauseRequest(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	game := h.playerGame(c, userID)
+	if game == nil {
+		return
+	}
+
+	cancelled, err := h.db.CancelPauseRequest(game.ID)
+	if err != nil {
+		log.Printf("Error cancelling pause request for game %s: %v", game.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to cancel request")
+		return
+	}
+	if !cancelled {
+		apierror.Respond(c, http.StatusNotFound, "no_pause_request", "No pause or resume request is pending")
+		return
+	}
+	h.pauseChanged(c, game.ID, http.StatusOK)
+}
+
+// AdminPauseGame pauses an in-progress game, for example during an
+// incident. Only an admin can resume it.
+func (h *Handler) AdminPauseGame(c *gin.Context) {
+	adminID := c.MustGet("userID").(uuid.UUID)
+
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
+		return
+	}
+
+	// The body is optional
+	var req AdminPauseRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+
+	game, err := h.db.GetGame(gameID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+	if game.Status != models.GameStatusInProgress {
+		apierror.Respond(c, http.StatusConflict, "game_not_in_progress", "Game is not in progress")
+		return
+	}
+
+	paused, err := h.db.ForcePause(gameID, adminID)
+	if err != nil {
+		log.Printf("Error pausing game %s: %v", gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to pause game")
+		return
+	}
+	if !paused {
+		apierror.Respond(c, http.StatusConflict, "game_already_paused", "Game is already paused by an admin")
+		return
+	}
+
+	updated, err := h.db.GetGame(gameID)
+	if err != nil {
+		log.Printf("Error reloading game %s: %v", gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to load game")
+		return
+	}
+	h.hub.NotifyPauseChanged(updated)
+
+	log.Printf("Game %s paused by admin %s", gameID, adminID)
+	h.audit(c, models.AuditActionGamePause, models.AuditTargetGame, gameID.String(), req.Reason, newPauseSnapshot(game), newPauseSnapshot(updated))
+	c.JSON(http.StatusOK, updated)
+}
+
+// AdminResumeGame resumes a paused game, whoever paused it.
+func (h *Handler) AdminResumeGame(c *gin.Context) {
+	adminID := c.MustGet("userID").(uuid.UUID)
+
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
+		return
+	}
+
+	// The body is optional
+	var req AdminPauseRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+
+	game, err := h.db.GetGame(gameID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+	if game.Status != models.GameStatusInProgress || game.PausedAt == nil {
+		apierror.Respond(c, http.StatusConflict, "game_not_paused", "Game is not paused")
+		return
+	}
+
+	resumed, err := h.db.ResumeGame(game, nil)
+	if err != nil {
+		log.Printf("Error resuming game %s: %v", gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to resume game")
+		return
+	}
+	if !resumed {
+		apierror.Respond(c, http.StatusConflict, "game_not_paused", "Game was resumed or ended in the meantime")
+		return
+	}
+
+	updated, err := h.db.GetGame(gameID)
+	if err != nil {
+		log.Printf("Error reloading game %s: %v", gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to load game")
+		return
+	}
+	h.hub.NotifyPauseChanged(updated)
+
+	log.Printf("Game %s resumed by admin %s", gameID, adminID)
+	h.audit(c, models.AuditActionGameResume, models.AuditTargetGame, gameID.String(), req.Reason, newPauseSnapshot(game), newPauseSnapshot(updated))
+	c.JSON(http.StatusOK, updated)
+}
@@ -0,0 +1,332 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// recentGamesLimit is how many completed games a public profile lists.
+const recentGamesLimit = 10
+
+type PublicProfileResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	AvatarURL string    `json:"avatar_url"`
+	JoinedAt  time.Time `json:"joined_at"`
+	// Restricted is set when the player's visibility hides their rating,
+	// stats and recent games from the viewer
+	Restricted  bool                    `json:"restricted"`
+	Rating      *int                    `json:"rating,omitempty"`
+	Tier        models.Tier             `json:"tier,omitempty"`
+	Division    int                     `json:"division,omitempty"`
+	Provisional bool                    `json:"provisional,omitempty"`
+	Stats       []*models.GameTypeStats `json:"stats,omitempty"`
+	RecentGames []*models.GameSummary   `json:"recent_games,omitempty"`
+}
+
+type UsersStatsRequest struct {
+	UserIDs []uuid.UUID `json:"user_ids" binding:"required,min=1,max=100"`
+}
+
+type UpdateProfileRequest struct {
+	AvatarURL         *string                   `json:"avatar_url" binding:"omitempty,max=500"`
+	ProfileVisibility *models.ProfileVisibility `json:"profile_visibility" binding:"omitempty,oneof=public friends private"`
+}
+
+// SearchUsers finds players by username for ?q=, matching a prefix or a
+// similar name regardless of case. Prefix matches are listed first.
+func (h *Handler) SearchUsers(c *gin.Context) {
+	viewerID := c.MustGet("userID").(uuid.UUID)
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" || len(query) > 50 {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_query", "Query must be 1 to 50 characters")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 50 {
+		limit = 20
+	}
+
+	users, err := h.db.SearchUsers(query, viewerID, limit)
+	if err != nil {
+		log.Printf("Error searching users for %q: %v", query, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to search users")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+// GetRatingHistory lists the requesting user's latest rating changes,
+// oldest first, for drawing a rating graph. ?game_type and an RFC 3339
+// ?since narrow the changes listed.
+func (h *Handler) GetRatingHistory(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var since time.Time
+	if value := c.Query("since"); value != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid_time", "Invalid since time")
+			return
+		}
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	history, err := h.db.GetRatingHistory(userID, c.Query("game_type"), since, limit)
+	if err != nil {
+		log.Printf("Error getting rating history for %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get rating history")
+		return
+	}
+	if history == nil {
+		history = []*models.RatingChange{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+// GetPublicProfile returns another player's profile as the requesting user
+// is allowed to see it.
+func (h *Handler) GetPublicProfile(c *gin.Context) {
+	viewerID := c.MustGet("userID").(uuid.UUID)
+
+	user, ok := h.lookupUser(c, viewerID, "Failed to get profile")
+	if !ok {
+		return
+	}
+	userID := user.ID
+
+	response := PublicProfileResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		AvatarURL: user.AvatarURL,
+		JoinedAt:  user.CreatedAt,
+	}
+
+	visible, err := h.profileVisible(user, viewerID)
+	if err != nil {
+		log.Printf("Error checking profile visibility of %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get profile")
+		return
+	}
+	if !visible {
+		response.Restricted = true
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	rating := 1000 // Default rating
+	response.Tier = models.TierUnranked
+	response.Provisional = true
+	if stats, err := h.db.GetUserStats(userID); err == nil {
+		rating = stats.Rating
+		response.Tier, response.Division = stats.Tier, stats.Division
+		response.Provisional = stats.Provisional
+	}
+	response.Rating = &rating
+
+	response.Stats, err = h.db.GetGameTypeStats(userID)
+	if err != nil {
+		log.Printf("Error getting stats for %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get profile")
+		return
+	}
+
+	response.RecentGames, err = h.db.GetRecentGames(userID, recentGamesLimit)
+	if err != nil {
+		log.Printf("Error getting recent games for %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateProfile changes the requesting user's avatar and profile visibility.
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	user, err := h.db.GetUser(userID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "user_not_found", "User not found")
+		return
+	}
+
+	if req.AvatarURL != nil {
+		user.AvatarURL = *req.AvatarURL
+	}
+	if req.ProfileVisibility != nil {
+		user.ProfileVisibility = *req.ProfileVisibility
+	}
+
+	if err := h.db.UpdateUser(user); err != nil {
+		log.Printf("Error updating profile of %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to update profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// GetUsersStats returns the overall records of up to 100 players at once,
+// so lists of players need not fetch each profile. Players the requesting
+// user may not see are left out.
+func (h *Handler) GetUsersStats(c *gin.Context) {
+	viewerID := c.MustGet("userID").(uuid.UUID)
+
+	var req UsersStatsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	stats, err := h.db.GetPlayerStats(viewerID, req.UserIDs)
+	if err != nil {
+		log.Printf("Error getting stats of %d users: %v", len(req.UserIDs), err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get stats")
+		return
+	}
+	if stats == nil {
+		stats = []*models.PlayerStats{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stats": stats})
+}
+
+// GetUserStats returns a player's per game type record, win rate, win
+// streaks and average game length, if their profile is visible to the
+// requesting user.
+func (h *Handler) GetUserStats(c *gin.Context) {
+	viewerID := c.MustGet("userID").(uuid.UUID)
+
+	user, ok := h.lookupUser(c, viewerID, "Failed to get stats")
+	if !ok {
+		return
+	}
+
+	visible, err := h.profileVisible(user, viewerID)
+	if err != nil {
+		log.Printf("Error checking profile visibility of %s: %v", user.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get stats")
+		return
+	}
+	if !visible {
+		apierror.Respond(c, http.StatusForbidden, "profile_restricted", "This player's stats are not visible to you")
+		return
+	}
+
+	stats, err := h.db.GetGameTypeStats(user.ID)
+	if err != nil {
+		log.Printf("Error getting stats for %s: %v", user.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get stats")
+		return
+	}
+	if stats == nil {
+		stats = []*models.GameTypeStats{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stats": stats})
+}
+
+// GetHeadToHead returns the requesting user's record against another
+// player. It only covers games the requesting user played, so the other
+// player's profile visibility does not apply.
+func (h *Handler) GetHeadToHead(c *gin.Context) {
+	viewerID := c.MustGet("userID").(uuid.UUID)
+
+	opponent, ok := h.lookupUser(c, viewerID, "Failed to get head-to-head record")
+	if !ok {
+		return
+	}
+	if opponent.ID == viewerID {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_opponent", "You cannot have a record against yourself")
+		return
+	}
+
+	record, err := h.db.GetHeadToHead(viewerID, opponent.ID)
+	if err != nil {
+		log.Printf("Error getting head-to-head record of %s against %s: %v", viewerID, opponent.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get head-to-head record")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"head_to_head": record})
+}
+
+// lookupUser loads the active user in the :id path parameter, treating
+// users blocked either way as missing. It responds and returns false if the
+// user cannot be shown, using failure as the message for internal errors.
+func (h *Handler) lookupUser(c *gin.Context, viewerID uuid.UUID, failure string) (*models.User, bool) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+		return nil, false
+	}
+
+	user, err := h.db.GetUser(userID)
+	if err == sql.ErrNoRows || (err == nil && !user.IsActive) {
+		apierror.Respond(c, http.StatusNotFound, "user_not_found", "User not found")
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error getting user %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, failure)
+		return nil, false
+	}
+
+	if userID != viewerID {
+		blocked, err := h.db.IsBlocked(viewerID, userID)
+		if err != nil {
+			log.Printf("Error checking block between %s and %s: %v", viewerID, userID, err)
+			apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, failure)
+			return nil, false
+		}
+		if blocked {
+			apierror.Respond(c, http.StatusNotFound, "user_not_found", "User not found")
+			return nil, false
+		}
+	}
+
+	return user, true
+}
+
+// profileVisible reports whether the viewer may see the user's stats and
+// recent games.
+func (h *Handler) profileVisible(user *models.User, viewerID uuid.UUID) (bool, error) {
+	if user.ID == viewerID {
+		return true, nil
+	}
+
+	switch user.ProfileVisibility {
+	case models.ProfileVisibilityPublic:
+		return true, nil
+	case models.ProfileVisibilityFriends:
+		return h.db.AreFriends(user.ID, viewerID)
+	default:
+		return false, nil
+	}
+}
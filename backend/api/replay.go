@@ -0,0 +1,110 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/game"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+type GameReplayResponse struct {
+	GameID     uuid.UUID       `json:"game_id"`
+	Move       int             `json:"move"`
+	TotalMoves int             `json:"total_moves"`
+	GameState  json.RawMessage `json:"game_state"`
+	NextPlayer *uuid.UUID      `json:"next_player,omitempty"`
+	// LastMove is the move that led to this state; unset at move 0
+	LastMove *models.Move `json:"last_move,omitempty"`
+}
+
+// GetGameReplay returns the game state after ?move=N moves, or the latest
+// state when no move is given, to users who may see the game.
+func (h *Handler) GetGameReplay(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
+		return
+	}
+
+	gameRecord, err := h.db.GetGame(gameID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+	if !h.authorizeGameViewer(c, gameRecord, userID) {
+		return
+	}
+
+	engine, err := h.registry.GetEngine(gameRecord.Type)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Game engine not found")
+		return
+	}
+
+	allMoves, err := h.db.GetGameMoves(gameID)
+	if err != nil {
+		log.Printf("Error getting moves for game %s: %v", gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get moves")
+		return
+	}
+	moves := make([]*models.Move, 0, len(allMoves))
+	for _, move := range allMoves {
+		if move.IsValid {
+			moves = append(moves, move)
+		}
+	}
+
+	moveNumber := len(moves)
+	if moveStr := c.Query("move"); moveStr != "" {
+		moveNumber, err = strconv.Atoi(moveStr)
+		if err != nil || moveNumber < 0 || moveNumber > len(moves) {
+			apierror.Respond(c, http.StatusBadRequest, "invalid_move_number",
+				fmt.Sprintf("Move number must be between 0 and %d", len(moves)))
+			return
+		}
+	}
+
+	// Start from the latest snapshot at or before the move when there is one
+	var state json.RawMessage
+	snapshot, err := h.db.GetGameSnapshot(gameID, moveNumber)
+	switch {
+	case err == nil:
+		state, err = game.ReplayFrom(engine, snapshot, moves, moveNumber)
+	case errors.Is(err, sql.ErrNoRows):
+		state, err = game.Replay(engine, gameRecord, moves, moveNumber)
+	}
+	if errors.Is(err, game.ErrReplayUnavailable) {
+		apierror.Respond(c, http.StatusNotFound, "replay_unavailable", "Replay not available for this game")
+		return
+	}
+	if err != nil {
+		log.Printf("Error replaying game %s: %v", gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to replay game")
+		return
+	}
+
+	response := GameReplayResponse{
+		GameID:     gameID,
+		Move:       moveNumber,
+		TotalMoves: len(moves),
+		GameState:  state,
+		NextPlayer: engine.GetGameStatus(state).NextPlayer,
+	}
+	if moveNumber > 0 {
+		response.LastMove = moves[moveNumber-1]
+	}
+
+	c.JSON(http.StatusOK, response)
+}
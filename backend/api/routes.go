@@ -2,59 +2,238 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/szaher/vibeboard/backend/internal/account"
 	"github.com/szaher/vibeboard/backend/internal/auth"
 	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/friends"
+	"github.com/szaher/vibeboard/backend/internal/game"
+	"github.com/szaher/vibeboard/backend/internal/leaderboard"
+	"github.com/szaher/vibeboard/backend/internal/lobby"
+	"github.com/szaher/vibeboard/backend/internal/ratelimit"
 	"github.com/szaher/vibeboard/backend/internal/websocket"
 )
 
-func SetupRoutes(db *database.DB, jwtManager *auth.JWTManager, hub *websocket.Hub) *gin.Engine {
-	router := gin.Default()
+func SetupRoutes(db *database.DB, jwtManager *auth.JWTManager, hub *websocket.Hub, registry *game.EngineRegistry, lobbies *lobby.PrivateLobbyService, matchmaker *lobby.MatchmakingService, leaderboards *leaderboard.Service, friendships *friends.Service, accounts *account.Service, bots *game.BotService, limiter *ratelimit.Limiter, dependencies []DependencyCheck) *gin.Engine {
+	router := gin.New()
 
 	// Middleware
+	router.Use(RequestIDMiddleware())
+	router.Use(gin.Logger(), gin.CustomRecovery(recoverPanic))
 	router.Use(CORSMiddleware())
-	router.Use(RateLimitMiddleware())
 
 	// Initialize handler
-	handler := NewHandler(db, jwtManager)
+	handler := NewHandler(db, jwtManager, registry, hub, lobbies, matchmaker, leaderboards, friendships, accounts, bots, dependencies)
 
-	// Health check
+	// Health and readiness checks
 	router.GET("/health", handler.HealthCheck)
+	router.GET("/ready", handler.Ready)
+
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// Public keys for validating access tokens
+	router.GET("/.well-known/jwks.json", handler.GetJWKS)
+
+	// API documentation
+	router.GET("/openapi.json", handler.GetOpenAPISpec)
+	router.GET("/docs", handler.GetAPIDocs)
 
 	// API routes
 	api := router.Group("/api/v1")
 	{
 		// Auth routes (no authentication required)
 		auth := api.Group("/auth")
+		auth.Use(RateLimitMiddleware(limiter))
 		{
 			auth.POST("/register", handler.Register)
 			auth.POST("/login", handler.Login)
+			auth.POST("/login/verify", handler.VerifyLogin)
 			auth.POST("/refresh", handler.RefreshToken)
+			auth.POST("/verify-email", handler.VerifyEmail)
+			auth.POST("/oauth/:provider", handler.OAuthLogin)
+			auth.POST("/magic-link", handler.RequestLoginLink)
+			auth.POST("/magic-link/login", handler.LoginWithLink)
+		}
+
+		// Connections authenticated by a one-time ticket, so browsers need
+		// not put the access token in the URL
+		streams := api.Group("")
+		streams.Use(StreamAuthMiddleware(jwtManager), ScopeMiddleware(), RateLimitMiddleware(limiter))
+		{
+			// WebSocket endpoint
+			streams.GET("/ws", hub.HandleWebSocket)
+
+			// SSE fallback transport
+			streams.GET("/sse", hub.HandleSSE)
 		}
 
 		// Protected routes
 		protected := api.Group("")
-		protected.Use(AuthMiddleware(jwtManager))
+		protected.Use(AuthMiddleware(jwtManager), ScopeMiddleware(), RateLimitMiddleware(limiter))
 		{
 			// User routes
 			user := protected.Group("/user")
 			{
 				user.GET("/profile", handler.GetProfile)
+				user.DELETE("", handler.DeleteAccount)
+				user.PATCH("/profile", handler.UpdateProfile)
+				user.PUT("/password", handler.ChangePassword)
+				user.POST("/email", handler.ChangeEmail)
+				user.PUT("/username", handler.ChangeUsername)
+				user.GET("/games", handler.GetUserGames)
+				user.POST("/tokens", handler.CreateScopedToken)
+				user.GET("/logins", handler.GetLoginHistory)
+				user.GET("/rating-history", handler.GetRatingHistory)
+				user.GET("/game-filters", handler.GetSavedGameFilters)
+				user.POST("/game-filters", handler.SaveGameFilter)
+				user.DELETE("/game-filters/:filterId", handler.DeleteSavedGameFilter)
+				user.GET("/season-rewards", handler.GetSeasonRewards)
+				user.GET("/tournaments", handler.GetTournamentSchedule)
 			}
 
+			users := protected.Group("/users")
+			{
+				users.POST("/stats", handler.GetUsersStats)
+				users.GET("/search", handler.SearchUsers)
+				users.GET("/:id/profile", handler.GetPublicProfile)
+				users.GET("/:id/stats", handler.GetUserStats)
+				users.GET("/:id/head-to-head", handler.GetHeadToHead)
+				users.POST("/:id/challenge", handler.CreateChallenge)
+				users.POST("/:id/friend", handler.SendFriendRequest)
+				users.POST("/:id/block", handler.BlockUser)
+				users.DELETE("/:id/block", handler.UnblockUser)
+				users.POST("/:id/report", handler.ReportUser)
+			}
+
+			protected.POST("/chat/messages/:messageId/report", handler.ReportChatMessage)
+
 			// Game routes
 			games := protected.Group("/games")
 			{
 				games.POST("/", handler.CreateGame)
 				games.GET("/", handler.GetGames)
+				games.GET("/live", handler.GetLiveGames)
+				games.GET("/types", handler.GetGameTypeOptions)
+				games.GET("/bots", handler.GetBots)
+				games.POST("/bots", handler.CreateBotGame)
 				games.GET("/:gameId", handler.GetGame)
+				games.DELETE("/:gameId", handler.CancelGame)
+				games.PUT("/:gameId/visibility", handler.SetGameVisibility)
+				games.PUT("/:gameId/tags", handler.SetGameTags)
+				games.GET("/:gameId/replay", handler.GetGameReplay)
+				games.GET("/:gameId/chat", handler.GetGameChat)
 				games.POST("/:gameId/join", handler.JoinGame)
+				games.POST("/join-by-code", handler.JoinGameByCode)
+				games.POST("/:gameId/join-code", handler.CreateJoinCode)
+				games.POST("/:gameId/invite", handler.InviteToGame)
+				games.POST("/:gameId/spectate", handler.SpectateGame)
 				games.POST("/:gameId/move", handler.MakeMove)
+				games.POST("/:gameId/pause", handler.PauseGame)
+				games.POST("/:gameId/resume", handler.ResumeGame)
+				games.DELETE("/:gameId/pause-request", handler.CancelPauseRequest)
+				games.POST("/:gameId/adjudication", handler.RequestAdjudication)
 			}
+			protected.GET("/series/:seriesId", handler.GetSeries)
 
-			// WebSocket endpoint
-			protected.GET("/ws", hub.HandleWebSocket)
+			// Game invite routes
+			invites := protected.Group("/invites")
+			{
+				invites.GET("", handler.GetInvites)
+				invites.POST("/:inviteId/accept", handler.AcceptInvite)
+				invites.POST("/:inviteId/decline", handler.DeclineInvite)
+			}
+
+			// Challenge routes
+			challenges := protected.Group("/challenges")
+			{
+				challenges.GET("", handler.GetChallenges)
+				challenges.POST("/:challengeId/accept", handler.AcceptChallenge)
+				challenges.POST("/:challengeId/decline", handler.DeclineChallenge)
+			}
+
+			// Friend routes
+			friendRoutes := protected.Group("/friends")
+			{
+				friendRoutes.GET("", handler.GetFriends)
+				friendRoutes.DELETE("/:id", handler.RemoveFriend)
+				friendRoutes.GET("/requests", handler.GetFriendRequests)
+				friendRoutes.POST("/requests/:id/accept", handler.AcceptFriendRequest)
+				friendRoutes.POST("/requests/:id/decline", handler.DeclineFriendRequest)
+			}
+
+			// Leaderboard routes
+			protected.GET("/leaderboards/:gameType", handler.GetLeaderboard)
+
+			// Season routes
+			seasons := protected.Group("/seasons")
+			{
+				seasons.GET("", handler.GetSeasons)
+				seasons.GET("/current", handler.GetCurrentSeason)
+				seasons.GET("/:seasonId", handler.GetSeason)
+				seasons.GET("/:seasonId/leaderboards/:gameType", handler.GetSeasonLeaderboard)
+			}
+
+			// Tournament routes
+			tournaments := protected.Group("/tournaments")
+			{
+				tournaments.GET("", handler.GetTournaments)
+				tournaments.GET("/:tournamentId", handler.GetTournament)
+				tournaments.POST("/:tournamentId/registration", handler.RegisterForTournament)
+				tournaments.DELETE("/:tournamentId/registration", handler.WithdrawFromTournament)
+			}
+
+			// GraphQL
+			protected.POST("/graphql", handler.GraphQL)
+
+			// Matchmaking routes
+			matchmaking := protected.Group("/matchmaking")
+			{
+				matchmaking.POST("/queue", handler.JoinMatchmaking)
+				matchmaking.GET("/queue", handler.GetMatchmakingStatus)
+				matchmaking.DELETE("/queue", handler.LeaveMatchmaking)
+			}
+
+			// Connection tickets and SSE messages
+			protected.POST("/ws/ticket", hub.HandleTicket)
+			protected.POST("/sse/:clientId/messages", hub.HandleSSEMessage)
+
+			// Admin routes
+			admin := protected.Group("/admin")
+			admin.Use(AdminMiddleware(db))
+			{
+				admin.GET("/game-types", handler.GetGameTypes)
+				admin.PUT("/game-types/:gameType", handler.SetGameTypeEnabled)
+				admin.GET("/hub/rooms", handler.GetHubRooms)
+				admin.GET("/hub/clients", handler.GetHubClients)
+				admin.GET("/hub/clients/:clientId", handler.GetHubClient)
+				admin.DELETE("/hub/clients/:clientId", handler.DisconnectHubClient)
+				admin.POST("/hub/announcements", handler.CreateAnnouncement)
+				admin.GET("/matchmaking/stats", handler.GetMatchmakingStats)
+				admin.GET("/reports", handler.GetReports)
+				admin.GET("/reports/:reportId", handler.GetReport)
+				admin.POST("/reports/:reportId/resolve", handler.ResolveReport)
+				admin.GET("/chat/messages", handler.GetChatMessages)
+				admin.DELETE("/chat/messages/:messageId", handler.DeleteChatMessage)
+				admin.PUT("/users/:id/mute", handler.MuteUser)
+				admin.DELETE("/users/:id/mute", handler.UnmuteUser)
+				admin.POST("/games/:gameId/pause", handler.AdminPauseGame)
+				admin.POST("/games/:gameId/resume", handler.AdminResumeGame)
+				admin.GET("/adjudications", handler.GetAdjudications)
+				admin.GET("/adjudications/:adjudicationId", handler.GetAdjudication)
+				admin.POST("/adjudications/:adjudicationId/resolve", handler.ResolveAdjudication)
+				admin.POST("/seasons", handler.CreateSeason)
+				admin.DELETE("/seasons/:seasonId", handler.DeleteSeason)
+				admin.POST("/tournaments", handler.CreateTournament)
+				admin.POST("/tournaments/:tournamentId/cancel", handler.CancelTournament)
+				admin.GET("/audit-log", handler.GetAuditLog)
+			}
 		}
 	}
 
+	router.NoRoute(noRoute)
+
+	checkAPIDocs(router.Routes())
+
 	return router
 }
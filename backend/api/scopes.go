@@ -0,0 +1,108 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/auth"
+)
+
+// scopedRoutes lists the routes a scoped token may call and the scope each
+// needs. Every other route needs a full-access token.
+var scopedRoutes = map[string]string{
+	"GET /api/v1/games/live":              auth.ScopeSpectate,
+	"GET /api/v1/games/:gameId":           auth.ScopeSpectate,
+	"GET /api/v1/games/:gameId/replay":    auth.ScopeSpectate,
+	"GET /api/v1/games/:gameId/chat":      auth.ScopeSpectate,
+	"POST /api/v1/games/:gameId/spectate": auth.ScopeSpectate,
+	"GET /api/v1/series/:seriesId":        auth.ScopeSpectate,
+	"POST /api/v1/ws/ticket":              auth.ScopeSpectate,
+	"GET /api/v1/ws":                      auth.ScopeSpectate,
+	"GET /api/v1/sse":                     auth.ScopeSpectate,
+	"POST /api/v1/sse/:clientId/messages": auth.ScopeSpectate,
+
+	"GET /api/v1/user/profile":                             auth.ScopeStatsRead,
+	"GET /api/v1/user/games":                               auth.ScopeStatsRead,
+	"GET /api/v1/user/rating-history":                      auth.ScopeStatsRead,
+	"GET /api/v1/users/:id/profile":                        auth.ScopeStatsRead,
+	"GET /api/v1/users/:id/stats":                          auth.ScopeStatsRead,
+	"GET /api/v1/users/:id/head-to-head":                   auth.ScopeStatsRead,
+	"POST /api/v1/users/stats":                             auth.ScopeStatsRead,
+	"GET /api/v1/leaderboards/:gameType":                   auth.ScopeStatsRead,
+	"GET /api/v1/user/season-rewards":                      auth.ScopeStatsRead,
+	"GET /api/v1/seasons":                                  auth.ScopeStatsRead,
+	"GET /api/v1/seasons/current":                          auth.ScopeStatsRead,
+	"GET /api/v1/seasons/:seasonId":                        auth.ScopeStatsRead,
+	"GET /api/v1/seasons/:seasonId/leaderboards/:gameType": auth.ScopeStatsRead,
+}
+
+type CreateScopedTokenRequest struct {
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=spectate stats:read"`
+	// ExpiresInHours defaults to, and may not exceed, JWT_SCOPED_TOKEN_MAX_TTL
+	ExpiresInHours int `json:"expires_in_hours" binding:"min=0"`
+}
+
+type ScopedTokenResponse struct {
+	AccessToken string    `json:"access_token"`
+	Scope       string    `json:"scope"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// ScopeMiddleware confines scoped tokens to the routes their scopes allow.
+// It must run after AuthMiddleware; full-access tokens pass through.
+func ScopeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := c.GetString("scope")
+		if scope == "" {
+			c.Next()
+			return
+		}
+
+		required, ok := scopedRoutes[c.Request.Method+" "+c.FullPath()]
+		claims := auth.Claims{Scope: scope}
+		if !ok || !claims.HasScope(required) {
+			apierror.Respond(c, http.StatusForbidden, "insufficient_scope", "Token scope does not allow this request")
+			return
+		}
+		c.Next()
+	}
+}
+
+// CreateScopedToken issues a restricted access token for the requesting
+// user, for kiosks, stream overlays and integrations that must not make
+// moves or change the account.
+func (h *Handler) CreateScopedToken(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+	username := c.GetString("username")
+
+	var req CreateScopedTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	ttl := time.Duration(req.ExpiresInHours) * time.Hour
+	token, expiresAt, err := h.jwtManager.GenerateScopedToken(userID, username, req.Scopes, ttl)
+	if errors.Is(err, auth.ErrScopedTTLTooLong) {
+		apierror.Respond(c, http.StatusBadRequest, "expiry_too_long", "Scoped tokens may last at most "+h.jwtManager.ScopedTokenMaxTTL().String())
+		return
+	}
+	if err != nil {
+		log.Printf("Error generating scoped token for %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to generate token")
+		return
+	}
+
+	c.JSON(http.StatusCreated, ScopedTokenResponse{
+		AccessToken: token,
+		Scope:       strings.Join(req.Scopes, " "),
+		ExpiresAt:   expiresAt,
+	})
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/leaderboard"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+type CreateSeasonRequest struct {
+	Name     string    `json:"name" binding:"required,max=50"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required,gtfield=StartsAt"`
+}
+
+// SeasonLeaderboard ranks a game type's players by season rating.
+type SeasonLeaderboard struct {
+	Season   *models.Season             `json:"season"`
+	GameType models.GameType            `json:"game_type"`
+	Entries  []*models.LeaderboardEntry `json:"entries"`
+}
+
+// GetSeasons lists seasons, latest first.
+func (h *Handler) GetSeasons(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	seasons, err := h.db.GetSeasons(limit, offset)
+	if err != nil {
+		log.Printf("Error getting seasons: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get seasons")
+		return
+	}
+	if seasons == nil {
+		seasons = []*models.Season{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"seasons": seasons})
+}
+
+// GetCurrentSeason returns the season running now.
+func (h *Handler) GetCurrentSeason(c *gin.Context) {
+	season, err := h.db.GetSeasonAt(time.Now())
+	if errors.Is(err, sql.ErrNoRows) {
+		apierror.Respond(c, http.StatusNotFound, "no_current_season", "No season is running")
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting current season: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get season")
+		return
+	}
+
+	c.JSON(http.StatusOK, season)
+}
+
+func (h *Handler) GetSeason(c *gin.Context) {
+	season, ok := h.loadSeason(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, season)
+}
+
+// GetSeasonLeaderboard returns a game type's leaderboard for the season,
+// with the caller's own entry if they played it.
+func (h *Handler) GetSeasonLeaderboard(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	season, ok := h.loadSeason(c)
+	if !ok {
+		return
+	}
+
+	gameType := models.GameType(c.Param("gameType"))
+	if _, err := h.registry.GetEngine(gameType); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_type", "Invalid game type")
+		return
+	}
+
+	limit := leaderboard.DefaultLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			apierror.Respond(c, http.StatusBadRequest, "invalid_limit", "Invalid limit")
+			return
+		}
+	}
+	if limit > leaderboard.MaxLimit {
+		limit = leaderboard.MaxLimit
+	}
+
+	entries, err := h.db.GetSeasonLeaderboard(season.ID, gameType, limit, userID)
+	if err != nil {
+		log.Printf("Error getting season %s %s leaderboard: %v", season.ID, gameType, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get leaderboard")
+		return
+	}
+	if entries == nil {
+		entries = []*models.LeaderboardEntry{}
+	}
+
+	c.JSON(http.StatusOK, SeasonLeaderboard{Season: season, GameType: gameType, Entries: entries})
+}
+
+// GetSeasonRewards lists the rewards the caller earned in past seasons.
+func (h *Handler) GetSeasonRewards(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	rewards, err := h.db.GetUserSeasonRewards(userID)
+	if err != nil {
+		log.Printf("Error getting season rewards for %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get season rewards")
+		return
+	}
+	if rewards == nil {
+		rewards = []*models.SeasonRewardGrant{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rewards": rewards})
+}
+
+// CreateSeason schedules a season. Seasons may not overlap.
+func (h *Handler) CreateSeason(c *gin.Context) {
+	var req CreateSeasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	season := &models.Season{
+		ID:       uuid.New(),
+		Name:     req.Name,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+	}
+	created, err := h.db.CreateSeason(season)
+	if err != nil {
+		log.Printf("Error creating season: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create season")
+		return
+	}
+	if !created {
+		apierror.Respond(c, http.StatusConflict, "season_overlaps", "Season overlaps another season")
+		return
+	}
+
+	h.audit(c, models.AuditActionSeasonCreate, models.AuditTargetSeason, season.ID.String(), "", nil, season)
+	c.JSON(http.StatusCreated, season)
+}
+
+// DeleteSeason removes a season that has not started yet.
+func (h *Handler) DeleteSeason(c *gin.Context) {
+	season, ok := h.loadSeason(c)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.db.DeleteSeason(season.ID)
+	if err != nil {
+		log.Printf("Error deleting season %s: %v", season.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to delete season")
+		return
+	}
+	if !deleted {
+		apierror.Respond(c, http.StatusConflict, "season_started", "Only seasons that have not started can be deleted")
+		return
+	}
+
+	h.audit(c, models.AuditActionSeasonDelete, models.AuditTargetSeason, season.ID.String(), "", season, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Season deleted"})
+}
+
+// loadSeason loads the season named by the :seasonId parameter, responding
+// with an error if it can't.
+func (h *Handler) loadSeason(c *gin.Context) (*models.Season, bool) {
+	seasonID, err := uuid.Parse(c.Param("seasonId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_season_id", "Invalid season ID")
+		return nil, false
+	}
+
+	season, err := h.db.GetSeason(seasonID)
+	if errors.Is(err, sql.ErrNoRows) {
+		apierror.Respond(c, http.StatusNotFound, "season_not_found", "Season not found")
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error getting season %s: %v", seasonID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get season")
+		return nil, false
+	}
+	return season, true
+}
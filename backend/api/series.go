@@ -0,0 +1,44 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+)
+
+// GetSeries returns a best-of-N series with its score and games. Series of
+// private games are only shown to their players.
+func (h *Handler) GetSeries(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	seriesID, err := uuid.Parse(c.Param("seriesId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_series_id", "Invalid series ID")
+		return
+	}
+
+	series, err := h.db.GetSeries(seriesID)
+	if errors.Is(err, sql.ErrNoRows) {
+		apierror.Respond(c, http.StatusNotFound, "series_not_found", "Series not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting series %s: %v", seriesID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get series")
+		return
+	}
+
+	isPlayer := userID == series.Player1ID || userID == series.Player2ID
+	if !isPlayer && len(series.Games) > 0 && series.Games[0].Private {
+		apierror.Respond(c, http.StatusNotFound, "series_not_found", "Series not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// liveGamesMaxLimit bounds a page of live games.
+const liveGamesMaxLimit = 50
+
+type SpectateResponse struct {
+	RoomID string       `json:"room_id"`
+	Game   *models.Game `json:"game"`
+}
+
+// GetLiveGames lists in-progress games listed to the user to spectate with their
+// spectator counts. ?type filters by game type, ?tag by tag and ?min_rating
+// and ?max_rating by both players' ratings; ?sort=popular lists the most
+// watched games first.
+func (h *Handler) GetLiveGames(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	gameType := c.Query("type")
+	if gameType != "" {
+		if _, err := h.registry.GetEngine(models.GameType(gameType)); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid_game_type", "Invalid game type")
+			return
+		}
+	}
+
+	tag, ok := gameTagParam(c)
+	if !ok {
+		return
+	}
+
+	var bounds [2]int
+	for i, param := range []string{"min_rating", "max_rating"} {
+		if value := c.Query(param); value != "" {
+			rating, err := strconv.Atoi(value)
+			if err != nil || rating <= 0 {
+				apierror.Respond(c, http.StatusBadRequest, "invalid_"+param, "Invalid "+param)
+				return
+			}
+			bounds[i] = rating
+		}
+	}
+
+	var popular bool
+	switch c.DefaultQuery("sort", "recent") {
+	case "recent":
+	case "popular":
+		popular = true
+	default:
+		apierror.Respond(c, http.StatusBadRequest, "invalid_sort", "Invalid sort")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > liveGamesMaxLimit {
+		limit = 20
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	games, err := h.db.GetLiveGames(userID, gameType, tag, bounds[0], bounds[1], popular, limit, offset)
+	if err != nil {
+		log.Printf("Error getting live games: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get live games")
+		return
+	}
+	if games == nil {
+		games = []*models.LiveGame{}
+	}
+
+	roomIDs := make([]string, len(games))
+	for i, game := range games {
+		roomIDs[i] = game.ID.String()
+	}
+	counts := h.hub.SpectatorCounts(roomIDs)
+	for _, game := range games {
+		game.Spectators = counts[game.ID.String()]
+	}
+
+	c.JSON(http.StatusOK, gin.H{"games": games})
+}
+
+// SpectateGame joins the requesting user's open connections to an
+// in-progress game's room as read-only spectators, if its visibility lets
+// them watch.
+func (h *Handler) SpectateGame(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
+		return
+	}
+
+	game, err := h.db.GetGame(gameID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+
+	if game.Private {
+		apierror.Respond(c, http.StatusForbidden, "game_private", "Private games cannot be spectated")
+		return
+	}
+	allowed, err := h.db.CanWatchGame(game, userID)
+	if err != nil {
+		log.Printf("Error checking whether %s may watch game %s: %v", userID, gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to spectate game")
+		return
+	}
+	if !allowed {
+		apierror.Respond(c, http.StatusForbidden, "game_friends_only", "Only the players' friends can spectate this game")
+		return
+	}
+	if game.Status != models.GameStatusInProgress {
+		apierror.Respond(c, http.StatusBadRequest, "game_not_in_progress", "Game is not in progress")
+		return
+	}
+	if game.HasPlayer(userID) {
+		apierror.Respond(c, http.StatusBadRequest, "cannot_spectate_own_game", "Cannot spectate your own game")
+		return
+	}
+
+	for _, playerID := range game.PlayerIDs() {
+		blocked, err := h.db.IsBlocked(userID, playerID)
+		if err != nil {
+			log.Printf("Error checking block between %s and %s: %v", userID, playerID, err)
+			apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to spectate game")
+			return
+		}
+		if blocked {
+			apierror.Respond(c, http.StatusForbidden, "spectate_blocked", "Cannot spectate this game")
+			return
+		}
+	}
+
+	roomID := game.ID.String()
+	h.hub.Spectate(userID, roomID)
+
+	c.JSON(http.StatusOK, SpectateResponse{RoomID: roomID, Game: game})
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+type GameTagsRequest struct {
+	Tags []models.GameTag `json:"tags" binding:"required,max=3,unique,dive,oneof=tournament friendly study"`
+}
+
+// SetGameTags lets either player replace their game's tags; an empty list
+// clears them.
+func (h *Handler) SetGameTags(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
+		return
+	}
+
+	var req GameTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	game, err := h.db.GetGame(gameID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+	if !game.HasPlayer(userID) {
+		apierror.Respond(c, http.StatusForbidden, "not_a_player", "Player not in this game")
+		return
+	}
+
+	updated, err := h.db.SetGameTags(gameID, req.Tags)
+	if err != nil {
+		log.Printf("Error setting tags of game %s: %v", gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to update game")
+		return
+	}
+	if !updated {
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+
+	game.Tags = req.Tags
+	c.JSON(http.StatusOK, game)
+}
+
+// gameTagParam reads the ?tag query parameter, which is empty when not
+// given, responding with 400 if it is not a known tag.
+func gameTagParam(c *gin.Context) (models.GameTag, bool) {
+	tag := models.GameTag(c.Query("tag"))
+	switch tag {
+	case "", models.GameTagTournament, models.GameTagFriendly, models.GameTagStudy:
+		return tag, true
+	}
+	apierror.Respond(c, http.StatusBadRequest, "invalid_tag", "Invalid tag")
+	return "", false
+}
@@ -0,0 +1,324 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/game"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+type CreateTournamentRequest struct {
+	Name         string          `json:"name" binding:"required,max=100"`
+	GameType     models.GameType `json:"game_type" binding:"required"`
+	TimeControl  string          `json:"time_control"`
+	StartsAt     time.Time       `json:"starts_at" binding:"required"`
+	Rounds       int             `json:"rounds" binding:"required,min=1,max=20"`
+	RoundMinutes int             `json:"round_minutes" binding:"required,min=1,max=10080"`
+	Capacity     int             `json:"capacity" binding:"required,min=2,max=1024"`
+	MinRating    *int            `json:"min_rating" binding:"omitempty,min=0"`
+	MaxRating    *int            `json:"max_rating" binding:"omitempty,min=0"`
+}
+
+// TournamentResponse is a tournament along with whether the caller is
+// registered for it.
+type TournamentResponse struct {
+	*models.Tournament
+	Registered bool `json:"registered"`
+}
+
+// TournamentSchedule lists the tournaments a player is registered for and
+// their rounds still to start.
+type TournamentSchedule struct {
+	Tournaments []*models.Tournament     `json:"tournaments"`
+	Rounds      []models.TournamentRound `json:"rounds"`
+}
+
+// GetTournaments lists tournaments open for registration, soonest first.
+func (h *Handler) GetTournaments(c *gin.Context) {
+	gameType := models.GameType(c.Query("game_type"))
+	if gameType != "" {
+		if _, err := h.registry.GetEngine(gameType); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid_game_type", "Invalid game type")
+			return
+		}
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	tournaments, err := h.db.GetUpcomingTournaments(gameType, limit, offset)
+	if err != nil {
+		log.Printf("Error getting tournaments: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get tournaments")
+		return
+	}
+	if tournaments == nil {
+		tournaments = []*models.Tournament{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tournaments": tournaments})
+}
+
+func (h *Handler) GetTournament(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	tournament, ok := h.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	registered, err := h.db.IsRegisteredForTournament(tournament.ID, userID)
+	if err != nil {
+		log.Printf("Error checking registration for tournament %s: %v", tournament.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get tournament")
+		return
+	}
+
+	c.JSON(http.StatusOK, TournamentResponse{Tournament: tournament, Registered: registered})
+}
+
+// RegisterForTournament registers the caller for a tournament that has not
+// started, if it has room and their rating is within its band.
+func (h *Handler) RegisterForTournament(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	tournament, ok := h.loadTournament(c)
+	if !ok {
+		return
+	}
+	if tournament.Status != models.TournamentStatusScheduled {
+		apierror.Respond(c, http.StatusConflict, "registration_closed", "Registration for this tournament is closed")
+		return
+	}
+
+	rating := 1000 // Default rating
+	if stats, err := h.db.GetUserStats(userID); err == nil {
+		rating = stats.Rating
+	}
+	if !tournament.AcceptsRating(rating) {
+		apierror.Respond(c, http.StatusForbidden, "rating_out_of_band", "Your rating is outside this tournament's rating band")
+		return
+	}
+
+	registered, err := h.db.RegisterForTournament(tournament.ID, userID)
+	if err != nil {
+		log.Printf("Error registering %s for tournament %s: %v", userID, tournament.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to register for tournament")
+		return
+	}
+	if !registered {
+		apierror.Respond(c, http.StatusConflict, "tournament_full", "Tournament is full")
+		return
+	}
+
+	tournament, err = h.db.GetTournament(tournament.ID)
+	if err != nil {
+		log.Printf("Error getting tournament %s: %v", tournament.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get tournament")
+		return
+	}
+
+	c.JSON(http.StatusCreated, TournamentResponse{Tournament: tournament, Registered: true})
+}
+
+// WithdrawFromTournament removes the caller's registration before the
+// tournament starts.
+func (h *Handler) WithdrawFromTournament(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	tournament, ok := h.loadTournament(c)
+	if !ok {
+		return
+	}
+	if tournament.Status != models.TournamentStatusScheduled {
+		apierror.Respond(c, http.StatusConflict, "registration_closed", "Registration for this tournament is closed")
+		return
+	}
+
+	withdrawn, err := h.db.WithdrawFromTournament(tournament.ID, userID)
+	if err != nil {
+		log.Printf("Error withdrawing %s from tournament %s: %v", userID, tournament.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to withdraw from tournament")
+		return
+	}
+	if !withdrawn {
+		apierror.Respond(c, http.StatusNotFound, "not_registered", "You are not registered for this tournament")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Withdrawn from tournament"})
+}
+
+// GetTournamentSchedule returns the tournaments the caller is registered for
+// that have not ended, and their rounds still to start, soonest first.
+func (h *Handler) GetTournamentSchedule(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	tournaments, err := h.db.GetUserTournaments(userID)
+	if err != nil {
+		log.Printf("Error getting tournaments of %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get tournament schedule")
+		return
+	}
+
+	schedule := TournamentSchedule{
+		Tournaments: tournaments,
+		Rounds:      []models.TournamentRound{},
+	}
+	if schedule.Tournaments == nil {
+		schedule.Tournaments = []*models.Tournament{}
+	}
+
+	now := time.Now()
+	for _, tournament := range tournaments {
+		next, ok := tournament.NextRound(now)
+		if !ok {
+			continue
+		}
+		for round := next; round <= tournament.Rounds; round++ {
+			schedule.Rounds = append(schedule.Rounds, models.TournamentRound{
+				TournamentID:   tournament.ID,
+				TournamentName: tournament.Name,
+				GameType:       tournament.GameType,
+				Round:          round,
+				StartsAt:       tournament.RoundStartsAt(round),
+			})
+		}
+	}
+	sort.SliceStable(schedule.Rounds, func(i, j int) bool {
+		return schedule.Rounds[i].StartsAt.Before(schedule.Rounds[j].StartsAt)
+	})
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// CreateTournament schedules a tournament.
+func (h *Handler) CreateTournament(c *gin.Context) {
+	adminID := c.MustGet("userID").(uuid.UUID)
+
+	var req CreateTournamentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+	if _, err := h.registry.GetEngine(req.GameType); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_type", "Invalid game type")
+		return
+	}
+	if !game.ValidTimeControl(req.TimeControl) {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_settings", "Unknown time control")
+		return
+	}
+	if !req.StartsAt.After(time.Now()) {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_start", "Tournament must start in the future")
+		return
+	}
+	if req.MinRating != nil && req.MaxRating != nil && *req.MinRating > *req.MaxRating {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_rating_band", "min_rating must not exceed max_rating")
+		return
+	}
+
+	tournament := &models.Tournament{
+		ID:           uuid.New(),
+		Name:         req.Name,
+		GameType:     req.GameType,
+		TimeControl:  req.TimeControl,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.StartsAt.Add(time.Duration(req.Rounds*req.RoundMinutes) * time.Minute),
+		Rounds:       req.Rounds,
+		RoundMinutes: req.RoundMinutes,
+		Capacity:     req.Capacity,
+		MinRating:    req.MinRating,
+		MaxRating:    req.MaxRating,
+		CreatedBy:    &adminID,
+	}
+	if err := h.db.CreateTournament(tournament); err != nil {
+		log.Printf("Error creating tournament: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create tournament")
+		return
+	}
+
+	h.audit(c, models.AuditActionTournamentCreate, models.AuditTargetTournament, tournament.ID.String(), "", nil, tournament)
+	c.JSON(http.StatusCreated, tournament)
+}
+
+// CancelTournamentRequest is the optional body for cancelling a tournament
+type CancelTournamentRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// CancelTournament cancels a tournament that has not ended and tells its
+// registered players.
+func (h *Handler) CancelTournament(c *gin.Context) {
+	tournament, ok := h.loadTournament(c)
+	if !ok {
+		return
+	}
+
+	// The body is optional
+	var req CancelTournamentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+	before := *tournament
+
+	cancelled, err := h.db.CancelTournament(tournament)
+	if err != nil {
+		log.Printf("Error cancelling tournament %s: %v", tournament.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to cancel tournament")
+		return
+	}
+	if !cancelled {
+		apierror.Respond(c, http.StatusConflict, "tournament_over", "Tournament has already ended or been cancelled")
+		return
+	}
+
+	h.audit(c, models.AuditActionTournamentCancel, models.AuditTargetTournament, tournament.ID.String(), req.Reason, before, tournament)
+
+	players, err := h.db.GetTournamentRegistrants(tournament.ID)
+	if err != nil {
+		log.Printf("Error getting players of tournament %s: %v", tournament.ID, err)
+	}
+	for _, playerID := range players {
+		if err := h.hub.NotifyUser(playerID, game.EventTournamentCancelled, tournament); err != nil {
+			log.Printf("Error notifying %s of cancelled tournament %s: %v", playerID, tournament.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, tournament)
+}
+
+// loadTournament loads the tournament named by the :tournamentId parameter,
+// responding with an error if it can't.
+func (h *Handler) loadTournament(c *gin.Context) (*models.Tournament, bool) {
+	tournamentID, err := uuid.Parse(c.Param("tournamentId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_tournament_id", "Invalid tournament ID")
+		return nil, false
+	}
+
+	tournament, err := h.db.GetTournament(tournamentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		apierror.Respond(c, http.StatusNotFound, "tournament_not_found", "Tournament not found")
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error getting tournament %s: %v", tournamentID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get tournament")
+		return nil, false
+	}
+	return tournament, true
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+const (
+	// userGamesOpenLimit bounds the in-progress and waiting games returned
+	userGamesOpenLimit   = 50
+	userGamesRecentLimit = 10
+	userGamesMaxRecent   = 50
+)
+
+type UserGamesResponse struct {
+	// YourTurn counts the active games waiting on the user's move
+	YourTurn int                `json:"your_turn"`
+	Active   []*models.UserGame `json:"active"`
+	Waiting  []*models.UserGame `json:"waiting"`
+	Recent   []*models.UserGame `json:"recent"`
+}
+
+// GetUserGames returns the requesting user's in-progress and waiting games,
+// with the ones waiting on their move first, and their recently finished
+// games. ?recent sets how many finished games to include and ?tag lists only
+// games with that tag.
+func (h *Handler) GetUserGames(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	recentLimit, err := strconv.Atoi(c.DefaultQuery("recent", strconv.Itoa(userGamesRecentLimit)))
+	if err != nil || recentLimit < 0 || recentLimit > userGamesMaxRecent {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_recent", "Invalid recent")
+		return
+	}
+
+	tag, ok := gameTagParam(c)
+	if !ok {
+		return
+	}
+
+	open, err := h.db.GetUserGames(userID, []models.GameStatus{models.GameStatusInProgress, models.GameStatusWaiting}, tag, userGamesOpenLimit)
+	if err != nil {
+		log.Printf("Error getting games of %s: %v", userID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get games")
+		return
+	}
+
+	response := UserGamesResponse{
+		Active:  []*models.UserGame{},
+		Waiting: []*models.UserGame{},
+		Recent:  []*models.UserGame{},
+	}
+	for _, game := range open {
+		if game.Status == models.GameStatusWaiting {
+			response.Waiting = append(response.Waiting, game)
+			continue
+		}
+		response.Active = append(response.Active, game)
+		if game.YourTurn {
+			response.YourTurn++
+		}
+	}
+
+	if recentLimit > 0 {
+		recent, err := h.db.GetUserGames(userID, []models.GameStatus{models.GameStatusCompleted, models.GameStatusAbandoned}, tag, recentLimit)
+		if err != nil {
+			log.Printf("Error getting recent games of %s: %v", userID, err)
+			apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get games")
+			return
+		}
+		if recent != nil {
+			response.Recent = recent
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+type GameVisibilityRequest struct {
+	Visibility models.GameVisibility `json:"visibility" binding:"required,oneof=public unlisted friends"`
+}
+
+// SetGameVisibility lets either player change who can find and watch their
+// game. Spectators already watching are not removed.
+func (h *Handler) SetGameVisibility(c *gin.Context) {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_game_id", "Invalid game ID")
+		return
+	}
+
+	var req GameVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	game, err := h.db.GetGame(gameID)
+	if err != nil {
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+	if !game.HasPlayer(userID) {
+		apierror.Respond(c, http.StatusForbidden, "not_a_player", "Player not in this game")
+		return
+	}
+
+	updated, err := h.db.SetGameVisibility(gameID, req.Visibility)
+	if err != nil {
+		log.Printf("Error setting visibility of game %s: %v", gameID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to update game")
+		return
+	}
+	if !updated {
+		apierror.Respond(c, http.StatusNotFound, "game_not_found", "Game not found")
+		return
+	}
+
+	game.Visibility = req.Visibility
+	c.JSON(http.StatusOK, game)
+}
+
+// authorizeGameViewer checks that the user may see the game: its players
+// always, and others as its privacy and visibility allow. Otherwise it
+// responds with the errors SpectateGame uses and returns false.
+func (h *Handler) authorizeGameViewer(c *gin.Context, game *models.Game, userID uuid.UUID) bool {
+	if game.Private && !game.HasPlayer(userID) {
+		apierror.Respond(c, http.StatusForbidden, "game_private", "Private games can only be viewed by their players")
+		return false
+	}
+
+	allowed, err := h.db.CanWatchGame(game, userID)
+	if err != nil {
+		log.Printf("Error checking whether %s may watch game %s: %v", userID, game.ID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get game")
+		return false
+	}
+	if !allowed {
+		apierror.Respond(c, http.StatusForbidden, "game_friends_only", "Only the players' friends can view this game")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/game"
+)
+
+const gamesUsage = "usage: server games [verify|rebuild] <game-id>"
+
+// runGames handles `server games`, for checking a game's stored state
+// against its move log and rebuilding it from the log.
+func runGames(db *database.DB, registry *game.EngineRegistry, args []string) error {
+	if len(args) != 2 {
+		return errors.New(gamesUsage)
+	}
+	gameID, err := uuid.Parse(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid game ID: %w", err)
+	}
+
+	switch args[0] {
+	case "verify":
+		record, err := db.GetGame(gameID)
+		if err != nil {
+			return err
+		}
+		engine, err := registry.GetEngine(record.Type)
+		if err != nil {
+			return err
+		}
+		moves, err := db.GetGameMoves(gameID)
+		if err != nil {
+			return err
+		}
+		snapshots, err := db.GetGameSnapshots(gameID)
+		if err != nil {
+			return err
+		}
+		if err := game.Verify(engine, record, moves, snapshots); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "%s: %d moves and %d snapshots replay to the stored state\n", gameID, len(moves), len(snapshots))
+		return nil
+	case "rebuild":
+		rebuilt, err := game.Rebuild(db, registry, gameID)
+		if err != nil {
+			return err
+		}
+		if rebuilt {
+			fmt.Fprintf(os.Stdout, "%s: state rebuilt from the move log\n", gameID)
+		} else {
+			fmt.Fprintf(os.Stdout, "%s: stored state already matches the move log\n", gameID)
+		}
+		return nil
+	default:
+		return errors.New(gamesUsage)
+	}
+}
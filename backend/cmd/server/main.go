@@ -1,17 +1,31 @@
 package main
 
 import (
+	"context"
+	"crypto"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/joho/godotenv"
 	"github.com/redis/go-redis/v9"
 
 	"github.com/szaher/vibeboard/backend/api"
+	"github.com/szaher/vibeboard/backend/internal/account"
 	"github.com/szaher/vibeboard/backend/internal/auth"
 	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/friends"
 	"github.com/szaher/vibeboard/backend/internal/game"
+	"github.com/szaher/vibeboard/backend/internal/leaderboard"
 	"github.com/szaher/vibeboard/backend/internal/lobby"
+	"github.com/szaher/vibeboard/backend/internal/mail"
 	"github.com/szaher/vibeboard/backend/internal/models"
+	"github.com/szaher/vibeboard/backend/internal/password"
+	"github.com/szaher/vibeboard/backend/internal/ratelimit"
+	"github.com/szaher/vibeboard/backend/internal/retention"
 	"github.com/szaher/vibeboard/backend/internal/websocket"
 	"github.com/szaher/vibeboard/backend/pkg/config"
 )
@@ -36,6 +50,35 @@ func main() {
 		}
 	}()
 
+	// `server migrate ...` manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+	if cfg.Database.AutoMigrate {
+		migrateOnStartup(db)
+	}
+	db.SetSnapshotInterval(cfg.Game.SnapshotInterval)
+
+	// Initialize game engines
+	registry := game.NewEngineRegistry()
+	registry.Register(models.GameTypeDominoes, game.NewDominoEngine())
+	registry.Register(models.GameTypeChess, game.NewChessEngine())
+	registry.SetStore(db)
+	if err := registry.Reload(); err != nil {
+		log.Printf("Error loading disabled game types: %v", err)
+	}
+
+	// `server games ...` checks or rebuilds a game and exits
+	if len(os.Args) > 1 && os.Args[1] == "games" {
+		if err := runGames(db, registry, os.Args[2:]); err != nil {
+			log.Fatalf("Game command failed: %v", err)
+		}
+		return
+	}
+
 	// Initialize Redis
 	redisClient := redis.NewClient(&redis.Options{
 		Addr:     cfg.Redis.Host + ":" + cfg.Redis.Port,
@@ -45,22 +88,138 @@ func main() {
 
 	// Initialize JWT manager
 	jwtManager := auth.NewJWTManager(cfg.JWT.Secret, cfg.JWT.AccessTokenTTL, cfg.JWT.RefreshTokenTTL)
+	jwtManager.SetIssuer(cfg.JWT.Issuer, cfg.JWT.Audience)
+	jwtManager.SetScopedTokenMaxTTL(cfg.JWT.ScopedTokenMaxTTL)
+	if cfg.JWT.KeyFile != "" {
+		key, err := auth.LoadPrivateKey(cfg.JWT.KeyFile)
+		if err != nil {
+			log.Fatalf("Failed to load JWT signing key: %v", err)
+		}
+		var retired []crypto.PublicKey
+		for _, path := range cfg.JWT.RetiredKeyFiles {
+			public, err := auth.LoadPublicKey(path)
+			if err != nil {
+				log.Fatalf("Failed to load retired JWT key: %v", err)
+			}
+			retired = append(retired, public)
+		}
+		if err := jwtManager.UseKeyPair(key, retired...); err != nil {
+			log.Fatalf("Failed to configure JWT signing key: %v", err)
+		}
+	}
 
 	// Initialize WebSocket hub
-	hub := websocket.NewHub()
-	go hub.Run()
+	hub := websocket.NewHub(&cfg.Hub)
+	if cfg.Hub.Backplane == "redis" {
+		hub.SetBackplane(websocket.NewRedisBackplane(redisClient))
+	}
+	hub.SetRoomLimits(websocket.GameRoomLimits(db, cfg.Hub.MaxSpectators))
+	hub.SetSpectatorAccess(websocket.GameSpectatorAccess(db))
+	hub.SetDirectMessageStore(db)
+	hub.SetBlockStore(db)
+	hub.SetChatStore(db)
+	hub.SetResumeStore(websocket.NewRedisResumeStore(redisClient))
+	hub.SetTicketStore(websocket.NewRedisTicketStore(redisClient, cfg.Hub.TicketTTL))
+	presence := websocket.NewRedisPresenceStore(redisClient)
+	hub.SetPresenceStore(presence)
+	hub.SetSpectatorStore(websocket.NewRedisSpectatorStore(redisClient))
+	hub.SetSpectatorRecorder(db)
+	hub.SetTokenValidator(jwtManager)
+	hub.SetGameTypes(registry)
+	registry.SetNotifier(hub)
 
-	// Initialize game engines
-	registry := game.NewEngineRegistry()
-	registry.Register(models.GameTypeDominoes, game.NewDominoEngine())
-	registry.Register(models.GameTypeChess, game.NewChessEngine())
+	leaderboards := leaderboard.NewService(db, redisClient, &cfg.Leaderboard)
+	leaderboards.Start()
+	decay := game.NewRatingDecayService(db, cfg.Game.RatingSystem, &cfg.Game.RatingDecay)
+	decay.SetLeaderboard(leaderboards)
+	decay.Start()
+	series := game.NewSeriesService(db, registry)
+	series.SetNotifier(hub)
+	seasons := game.NewSeasonService(db, cfg.Game.SeasonInterval, cfg.Game.SeasonResetFactor)
+	seasons.SetNotifier(hub)
+	seasons.Start()
+	results := game.ResultRecorders{game.NewStatsRecorder(db), game.NewRatingRecorder(db, cfg.Game.RatingSystem, cfg.Game.RatingSystems), leaderboards, series, seasons}
+	moves := game.NewMoveService(db, registry)
+	moves.SetResultRecorder(results)
+	hub.SetMoveProcessor(moves)
+	starters := game.NewStarterSelector(db, cfg.Game.StarterPolicy)
+	lifecycle := game.NewLifecycleService(db, registry, starters)
+	lifecycle.SetSeries(series)
+	hub.SetGameLifecycle(lifecycle)
+	hub.SetReadyStore(websocket.NewRedisReadyStore(redisClient))
+	bots := game.NewBotService(db, registry, moves, starters)
+	bots.SetNotifier(hub)
 
 	// Initialize matchmaking service
-	matchmaking := lobby.NewMatchmakingService(db, redisClient, registry)
+	matchmaking := lobby.NewMatchmakingService(db, redisClient, registry, starters, &cfg.Lobby)
+	matchmaking.SetNotifier(hub)
+	matchmaking.SetSeries(series)
+	hub.SetPartyCoordinator(matchmaking)
+	hub.SetMatchConfirmer(matchmaking)
+	matchmaking.SetPresenceChecker(presence)
+	go hub.Run()
 	matchmaking.Start()
 
+	// Initialize chess bots
+	bots.Start()
+
+	// Initialize turn timer
+	turnTimer := game.NewTurnTimerService(db, moves, cfg.Game.TurnTimeout)
+	turnTimer.Start()
+
+	// Initialize correspondence deadlines
+	correspondence := game.NewCorrespondenceService(db, moves, cfg.Game.CorrespondenceInterval)
+	correspondence.Start()
+
+	// Initialize turn reminders
+	reminders := game.NewTurnReminderService(db, cfg.Game.TurnReminderInterval, cfg.Game.TurnReminderLimit, cfg.Game.CorrespondenceReminder)
+	reminders.SetNotifier(hub)
+	reminders.Start()
+
+	// Initialize tournament round reminders
+	tournamentReminders := game.NewTournamentReminderService(db, cfg.Game.TournamentReminder)
+	tournamentReminders.SetNotifier(hub)
+	tournamentReminders.Start()
+
+	// Initialize abandonment detection
+	abandonment := game.NewAbandonmentService(db, cfg.Game.AbandonTimeout)
+	abandonment.SetPresenceChecker(bots.Presence(presence))
+	abandonment.SetNotifier(hub)
+	abandonment.SetResultRecorder(results)
+	abandonment.Start()
+
+	// Initialize data retention
+	retention.NewJanitor(db, &cfg.Retention).Start()
+
 	// Setup routes
-	router := api.SetupRoutes(db, jwtManager, hub)
+	lobbies := lobby.NewPrivateLobbyService(redisClient, &cfg.Lobby)
+	lobbies.SetNotifier(hub)
+	friendships := friends.NewService(db)
+	friendships.SetNotifier(hub)
+	friendships.SetPresenceChecker(presence)
+	accounts := account.NewService(db, redisClient, mail.NewMailer(&cfg.Mail), &cfg.Account)
+	oauthProviders, err := auth.NewOAuthProviders(&cfg.OAuth)
+	if err != nil {
+		log.Fatalf("Failed to configure OAuth providers: %v", err)
+	}
+	accounts.SetOAuthProviders(oauthProviders)
+	passwords, err := password.NewPolicy(&cfg.Password)
+	if err != nil {
+		log.Fatalf("Failed to load password policy: %v", err)
+	}
+	accounts.SetPasswordPolicy(passwords)
+	limiter := ratelimit.NewLimiter(redisClient, &cfg.API)
+	dependencies := []api.DependencyCheck{
+		{Name: "postgres", Critical: true, Ping: db.Ping},
+		{Name: "redis", Critical: true, Ping: func(ctx context.Context) error { return redisClient.Ping(ctx).Err() }},
+	}
+	if db.HasReplicas() {
+		dependencies = append(dependencies, api.DependencyCheck{Name: "postgres_replicas", Ping: db.PingReplicas})
+	}
+	router := api.SetupRoutes(db, jwtManager, hub, registry, lobbies, matchmaking, leaderboards, friendships, accounts, bots, limiter, dependencies)
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
 
 	// Start server
 	port := cfg.Server.Port
@@ -68,8 +227,43 @@ func main() {
 		port = "8181"
 	}
 
-	log.Printf("Starting server on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	srv := &http.Server{
+		Addr:         ":" + port,
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.Hub.GameChangeEvents {
+		go func() {
+			if err := db.ListenGameChanges(ctx, hub.GameChanged); err != nil {
+				log.Printf("Game change events disabled: %v", err)
+			}
+		}()
+	}
+
+	go func() {
+		log.Printf("Starting server on port %s", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Println("Shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	// Drain WebSockets first; they are hijacked and not tracked by srv.Shutdown
+	if err := hub.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error draining WebSocket hub: %v", err)
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
 	}
 }
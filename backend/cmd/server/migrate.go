@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/szaher/vibeboard/backend/internal/database"
+)
+
+const migrateUsage = "usage: server migrate [up|down|status|version]"
+
+// runMigrate handles `server migrate`, for applying migrations as a deploy
+// step instead of at startup.
+func runMigrate(db *database.DB, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	if len(args) > 1 {
+		return errors.New(migrateUsage)
+	}
+
+	migrations, err := db.Migrations()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		return migrations.Up(ctx)
+	case "down":
+		return migrations.Down(ctx)
+	case "status":
+		statuses, err := migrations.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if !status.AppliedAt.IsZero() {
+				appliedAt = status.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(os.Stdout, "%-20s %s\n", appliedAt, status.Source.Path)
+		}
+		return nil
+	case "version":
+		version, err := migrations.Version(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stdout, version)
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+// migrateOnStartup applies pending migrations if DB_AUTO_MIGRATE is set.
+func migrateOnStartup(db *database.DB) {
+	migrations, err := db.Migrations()
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if err := migrations.Up(context.Background()); err != nil {
+		log.Fatalf("Failed to apply migrations: %v", err)
+	}
+}
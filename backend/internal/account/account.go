@@ -0,0 +1,463 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/szaher/vibeboard/backend/internal/auth"
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/mail"
+	"github.com/szaher/vibeboard/backend/internal/models"
+	"github.com/szaher/vibeboard/backend/internal/password"
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+var (
+	ErrUserNotFound          = errors.New("user_not_found")
+	ErrIncorrectPassword     = errors.New("incorrect_password")
+	ErrEmailTaken            = errors.New("email_taken")
+	ErrEmailUnchanged        = errors.New("email_unchanged")
+	ErrUsernameTaken         = errors.New("username_taken")
+	ErrUsernameUnchanged     = errors.New("username_unchanged")
+	ErrInvalidVerification   = errors.New("invalid_verification_token")
+	ErrEmailRequired         = errors.New("email_required")
+	ErrAccountExists         = errors.New("account_exists")
+	ErrInvalidLoginLink      = errors.New("invalid_login_link")
+	ErrInvalidLoginChallenge = errors.New("invalid_login_challenge")
+)
+
+// usernameAttempts is how many generated usernames are tried for a new
+// social login before giving up.
+const usernameAttempts = 5
+
+const (
+	// emailChangeKey holds a pending email change by the SHA-256 of its
+	// token, so a leaked Redis dump cannot confirm changes
+	emailChangeKey = "account:email_change:%s" // token hash
+	// emailChangeUserKey points at a user's pending change, which a new
+	// request replaces
+	emailChangeUserKey = "account:email_change:user:%s" // user ID
+	// loginLinkKey holds a magic-link login by the SHA-256 of its token
+	loginLinkKey = "account:login_link:%s" // token hash
+	// loginLinkSentKey limits how often links are mailed to an address
+	loginLinkSentKey = "account:login_link:sent:%s" // email
+)
+
+// CooldownError is returned when a username is changed again too soon.
+type CooldownError struct {
+	Until time.Time
+}
+
+func (e *CooldownError) Error() string {
+	return "username_change_cooldown"
+}
+
+// PolicyError is returned when a new password breaks the password policy.
+type PolicyError struct {
+	Violations []password.Violation
+}
+
+func (e *PolicyError) Error() string {
+	return "weak_password"
+}
+
+type pendingEmailChange struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email"`
+}
+
+// Service changes the credentials and identity of existing accounts.
+type Service struct {
+	db          *database.DB
+	redisClient *redis.Client
+	mailer      mail.Mailer
+	cfg         *config.AccountConfig
+	providers   map[string]auth.OAuthProvider
+	passwords   *password.Policy
+}
+
+func NewService(db *database.DB, redisClient *redis.Client, mailer mail.Mailer, cfg *config.AccountConfig) *Service {
+	return &Service{db: db, redisClient: redisClient, mailer: mailer, cfg: cfg}
+}
+
+// SetPasswordPolicy must be called before passwords are set. Without it any
+// password is accepted.
+func (s *Service) SetPasswordPolicy(policy *password.Policy) {
+	s.passwords = policy
+}
+
+// CheckPassword returns the ways a new password for the account breaks the
+// password policy, or nil if it is acceptable.
+func (s *Service) CheckPassword(ctx context.Context, newPassword, username, email string) []password.Violation {
+	if s.passwords == nil {
+		return nil
+	}
+	return s.passwords.Check(ctx, newPassword, username, email)
+}
+
+// SetOAuthProviders must be called before social logins are accepted.
+func (s *Service) SetOAuthProviders(providers map[string]auth.OAuthProvider) {
+	s.providers = providers
+}
+
+// SignInWithOAuth exchanges an authorization code with the provider and
+// returns the user it signs in, reporting whether the user was created. A
+// provider account is linked to an existing user with the same email only
+// when the provider has verified that email.
+func (s *Service) SignInWithOAuth(ctx context.Context, provider, code, redirectURI, codeVerifier string) (*models.User, bool, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, false, auth.ErrUnknownProvider
+	}
+
+	identity, err := p.Exchange(ctx, code, redirectURI, codeVerifier)
+	if err != nil {
+		return nil, false, err
+	}
+
+	user, err := s.db.GetUserByIdentity(identity.Provider, identity.Subject)
+	if err == nil {
+		return user, false, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to get user by identity: %w", err)
+	}
+
+	if identity.Email == "" {
+		return nil, false, ErrEmailRequired
+	}
+
+	if existing, err := s.db.GetUserByEmail(identity.Email); err == nil {
+		if !identity.EmailVerified {
+			return nil, false, ErrAccountExists
+		}
+		if err := s.db.CreateIdentity(identity.Provider, identity.Subject, existing.ID, identity.Email); err != nil && !database.IsUniqueViolation(err) {
+			return nil, false, fmt.Errorf("failed to link identity: %w", err)
+		}
+		return existing, false, nil
+	}
+
+	return s.createOAuthUser(identity)
+}
+
+// RequestLoginLink mails a one-time login link to the account with the
+// given email. Unknown and disabled accounts, and addresses sent a link
+// within LoginLinkInterval, are silently ignored, so the response does not
+// reveal which emails are registered.
+func (s *Service) RequestLoginLink(email string) error {
+	user, err := s.db.GetUserByEmail(email)
+	if err != nil || !user.IsActive {
+		return nil
+	}
+
+	ctx := context.Background()
+	sent, err := s.redisClient.SetNX(ctx, fmt.Sprintf(loginLinkSentKey, strings.ToLower(email)), 1, s.cfg.LoginLinkInterval).Result()
+	if err != nil {
+		return fmt.Errorf("failed to check login link interval: %w", err)
+	}
+	if !sent {
+		return nil
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return err
+	}
+	if err := s.redisClient.Set(ctx, fmt.Sprintf(loginLinkKey, hashToken(token)), user.ID.String(), s.cfg.LoginLinkTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store login link: %w", err)
+	}
+
+	link := s.cfg.LoginLinkURL + "?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hi %s,\n\nSign in to Vibe Arcade by opening this link within %s:\n\n%s\n\nThe link works once. If you did not ask to sign in, you can ignore this message.\n",
+		user.Username, s.cfg.LoginLinkTTL, link)
+	return s.mailer.Send(user.Email, "Your Vibe Arcade sign-in link", body)
+}
+
+// RedeemLoginLink returns the user a login link was issued to. Each link
+// works once.
+func (s *Service) RedeemLoginLink(token string) (*models.User, error) {
+	value, err := s.redisClient.GetDel(context.Background(), fmt.Sprintf(loginLinkKey, hashToken(token))).Result()
+	if err == redis.Nil {
+		return nil, ErrInvalidLoginLink
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login link: %w", err)
+	}
+
+	userID, err := uuid.Parse(value)
+	if err != nil {
+		return nil, ErrInvalidLoginLink
+	}
+	user, err := s.db.GetUser(userID)
+	if err != nil {
+		return nil, ErrInvalidLoginLink
+	}
+	return user, nil
+}
+
+// createOAuthUser creates a passwordless user for a first social login,
+// deriving the username from the email.
+func (s *Service) createOAuthUser(identity *auth.OAuthIdentity) (*models.User, bool, error) {
+	base := usernameFromEmail(identity.Email)
+
+	for attempt := 0; attempt < usernameAttempts; attempt++ {
+		username := base
+		if attempt > 0 {
+			suffix, err := rand.Int(rand.Reader, big.NewInt(10000))
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to generate username: %w", err)
+			}
+			username = fmt.Sprintf("%s%04d", base, suffix.Int64())
+		}
+
+		user := &models.User{
+			ID:       uuid.New(),
+			Email:    identity.Email,
+			Username: username,
+			IsActive: true,
+		}
+		err := s.db.CreateUserWithIdentity(user, identity.Provider, identity.Subject)
+		if err == nil {
+			return user, true, nil
+		}
+		if !database.IsUniqueViolation(err) {
+			return nil, false, fmt.Errorf("failed to create user: %w", err)
+		}
+
+		// A concurrent sign-in may have created the user or claimed the
+		// email; only a taken username is worth another attempt
+		if user, err := s.db.GetUserByIdentity(identity.Provider, identity.Subject); err == nil {
+			return user, false, nil
+		}
+		if _, err := s.db.GetUserByEmail(identity.Email); err == nil {
+			return nil, false, ErrAccountExists
+		}
+	}
+
+	return nil, false, ErrUsernameTaken
+}
+
+// ChangePassword replaces the user's password after checking the current
+// one.
+func (s *Service) ChangePassword(userID uuid.UUID, currentPassword, newPassword string) error {
+	user, err := s.authenticate(userID, currentPassword)
+	if err != nil {
+		return err
+	}
+	if violations := s.CheckPassword(context.Background(), newPassword, user.Username, user.Email); violations != nil {
+		return &PolicyError{Violations: violations}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.db.UpdatePassword(user.ID, string(hash)); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	return nil
+}
+
+// RequestEmailChange mails a verification link to the new address. The
+// account keeps its current email until ConfirmEmailChange is called with
+// the link's token.
+func (s *Service) RequestEmailChange(userID uuid.UUID, password, email string) error {
+	user, err := s.authenticate(userID, password)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(user.Email, email) {
+		return ErrEmailUnchanged
+	}
+	if err := s.checkEmailFree(email); err != nil {
+		return err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(pendingEmailChange{UserID: userID, Email: email})
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	userKey := fmt.Sprintf(emailChangeUserKey, userID)
+	if previous, err := s.redisClient.Get(ctx, userKey).Result(); err == nil {
+		s.redisClient.Del(ctx, fmt.Sprintf(emailChangeKey, previous))
+	}
+
+	tokenHash := hashToken(token)
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, fmt.Sprintf(emailChangeKey, tokenHash), data, s.cfg.EmailVerificationTTL)
+		pipe.Set(ctx, userKey, tokenHash, s.cfg.EmailVerificationTTL)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store email change: %w", err)
+	}
+
+	link := s.cfg.EmailVerificationURL + "?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hi %s,\n\nConfirm your new Vibe Arcade email address by opening this link within %s:\n\n%s\n\nIf you did not ask to change your email, you can ignore this message.\n",
+		user.Username, s.cfg.EmailVerificationTTL, link)
+	return s.mailer.Send(email, "Confirm your new email address", body)
+}
+
+// ConfirmEmailChange applies the pending change a verification token was
+// issued for. Each token works once.
+func (s *Service) ConfirmEmailChange(token string) (*models.User, error) {
+	ctx := context.Background()
+	data, err := s.redisClient.GetDel(ctx, fmt.Sprintf(emailChangeKey, hashToken(token))).Bytes()
+	if err == redis.Nil {
+		return nil, ErrInvalidVerification
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email change: %w", err)
+	}
+
+	var change pendingEmailChange
+	if err := json.Unmarshal(data, &change); err != nil {
+		return nil, fmt.Errorf("failed to decode email change: %w", err)
+	}
+	s.redisClient.Del(ctx, fmt.Sprintf(emailChangeUserKey, change.UserID))
+
+	if err := s.checkEmailFree(change.Email); err != nil {
+		return nil, err
+	}
+	if err := s.db.UpdateEmail(change.UserID, change.Email); err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrEmailTaken
+		}
+		return nil, fmt.Errorf("failed to update email: %w", err)
+	}
+
+	user, err := s.db.GetUser(change.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// ChangeUsername renames the user unless they did so within the cooldown.
+func (s *Service) ChangeUsername(userID uuid.UUID, username string) (*models.User, error) {
+	user, err := s.db.GetUser(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.Username == username {
+		return nil, ErrUsernameUnchanged
+	}
+
+	now := time.Now()
+	changed, err := s.db.UpdateUsername(userID, username, now.Add(-s.cfg.UsernameCooldown))
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrUsernameTaken
+		}
+		return nil, fmt.Errorf("failed to update username: %w", err)
+	}
+	if !changed {
+		until := now
+		if user.UsernameChangedAt != nil {
+			until = user.UsernameChangedAt.Add(s.cfg.UsernameCooldown)
+		}
+		return nil, &CooldownError{Until: until}
+	}
+
+	user.Username = username
+	user.UsernameChangedAt = &now
+	return user, nil
+}
+
+// DeleteAccount soft-deletes the user, scrubbing their personal data while
+// keeping their past games. Users who signed up with OAuth have no password
+// to confirm.
+func (s *Service) DeleteAccount(userID uuid.UUID, password string) error {
+	user, err := s.db.GetUser(userID)
+	if err != nil || user.Deleted() {
+		return ErrUserNotFound
+	}
+	if user.Password != "" {
+		if _, err := s.authenticate(userID, password); err != nil {
+			return err
+		}
+	}
+
+	deleted, err := s.db.SoftDeleteUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if !deleted {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (s *Service) authenticate(userID uuid.UUID, password string) (*models.User, error) {
+	user, err := s.db.GetUser(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return nil, ErrIncorrectPassword
+	}
+	return user, nil
+}
+
+func (s *Service) checkEmailFree(email string) error {
+	existing, err := s.db.GetUserByEmail(email)
+	if err == nil && existing != nil {
+		return ErrEmailTaken
+	}
+	return nil
+}
+
+// usernameFromEmail keeps the letters, digits and underscores of the
+// email's local part, leaving room for a numeric suffix.
+func usernameFromEmail(email string) string {
+	local, _, _ := strings.Cut(email, "@")
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(local) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		}
+		if b.Len() == 16 {
+			break
+		}
+	}
+
+	username := b.String()
+	if len(username) < 3 {
+		username = "player" + username
+	}
+	return username
+}
+
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,265 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// Login methods.
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodMagicLink = "magic_link"
+)
+
+const (
+	// loginChallengeKey holds a login waiting on its emailed code
+	loginChallengeKey = "account:login_challenge:%s" // challenge ID
+	// loginChallengeAttemptsKey counts the codes tried against a challenge
+	loginChallengeAttemptsKey = "account:login_challenge:%s:attempts" // challenge ID
+	// loginChallengeAttempts is how many codes may be tried per challenge
+	loginChallengeAttempts = 5
+)
+
+// ChallengeError is returned when a login from a new device or an
+// improbable location must be confirmed with a code mailed to the user.
+type ChallengeError struct {
+	ChallengeID string
+	ExpiresAt   time.Time
+}
+
+func (e *ChallengeError) Error() string {
+	return "login_verification_required"
+}
+
+// LoginAttempt describes where a login comes from.
+type LoginAttempt struct {
+	Method    string `json:"method"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	// DeviceID is an app install ID sent as X-Device-ID; browsers are told
+	// apart by user agent instead
+	DeviceID string `json:"device_id,omitempty"`
+	Country  string `json:"country,omitempty"`
+}
+
+type loginChallenge struct {
+	UserID   uuid.UUID    `json:"user_id"`
+	CodeHash string       `json:"code_hash"`
+	Attempt  LoginAttempt `json:"attempt"`
+}
+
+// NewLoginAttempt reads a login's device and, if ACCOUNT_COUNTRY_HEADER is
+// set, country from its request headers.
+func (s *Service) NewLoginAttempt(method, ip string, header http.Header) LoginAttempt {
+	attempt := LoginAttempt{
+		Method:    method,
+		IP:        ip,
+		UserAgent: header.Get("User-Agent"),
+		DeviceID:  header.Get("X-Device-ID"),
+	}
+	if s.cfg.CountryHeader != "" {
+		country := strings.ToUpper(strings.TrimSpace(header.Get(s.cfg.CountryHeader)))
+		// Proxies use XX or T1 for unknown and Tor exits
+		if len(country) == 2 && country != "XX" && country != "T1" {
+			attempt.Country = country
+		}
+	}
+	return attempt
+}
+
+// CheckLogin records a password login, unless it comes from a new device
+// or an improbable location, in which case a code is mailed to the user
+// and a *ChallengeError returned; the login completes with
+// VerifyLoginChallenge.
+func (s *Service) CheckLogin(user *models.User, attempt LoginAttempt) error {
+	deviceHash := hashToken(attempt.device())
+	suspicious, err := s.isSuspicious(user.ID, deviceHash, attempt)
+	if err != nil {
+		return err
+	}
+	if !suspicious || !s.cfg.VerifyNewDevices {
+		s.recordLogin(user, attempt, suspicious)
+		return nil
+	}
+
+	code, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return fmt.Errorf("failed to generate login code: %w", err)
+	}
+	codeString := fmt.Sprintf("%06d", code.Int64())
+	challengeID, err := generateToken()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(loginChallenge{UserID: user.ID, CodeHash: hashToken(codeString), Attempt: attempt})
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(s.cfg.LoginChallengeTTL)
+	if err := s.redisClient.Set(context.Background(), fmt.Sprintf(loginChallengeKey, challengeID), data, s.cfg.LoginChallengeTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store login challenge: %w", err)
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nSomeone signed in to your Vibe Arcade account from a new device or location:\n\n%s\n\nIf this was you, enter this code within %s to finish signing in:\n\n%s\n\nIf it was not you, change your password now.\n",
+		user.Username, attempt.describe(), s.cfg.LoginChallengeTTL, codeString)
+	if err := s.mailer.Send(user.Email, "Confirm your sign-in", body); err != nil {
+		return err
+	}
+
+	return &ChallengeError{ChallengeID: challengeID, ExpiresAt: expiresAt}
+}
+
+// VerifyLoginChallenge completes a challenged login with the mailed code.
+// A challenge is dropped once loginChallengeAttempts codes have been tried
+// without the right one.
+func (s *Service) VerifyLoginChallenge(challengeID, code string) (*models.User, error) {
+	ctx := context.Background()
+	key := fmt.Sprintf(loginChallengeKey, challengeID)
+
+	data, err := s.redisClient.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrInvalidLoginChallenge
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login challenge: %w", err)
+	}
+	var challenge loginChallenge
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		return nil, fmt.Errorf("failed to decode login challenge: %w", err)
+	}
+
+	// Attempts are counted before the code is checked, so concurrent guesses
+	// cannot get past the limit. The count is left to expire rather than
+	// deleted, so requests still in flight stay counted.
+	attemptsKey := fmt.Sprintf(loginChallengeAttemptsKey, challengeID)
+	attempts, err := s.redisClient.Incr(ctx, attemptsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count login challenge attempts: %w", err)
+	}
+	if attempts == 1 {
+		s.redisClient.Expire(ctx, attemptsKey, s.cfg.LoginChallengeTTL)
+	}
+	if attempts > loginChallengeAttempts {
+		s.redisClient.Del(ctx, key)
+		return nil, ErrInvalidLoginChallenge
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(code)), []byte(challenge.CodeHash)) != 1 {
+		if attempts == loginChallengeAttempts {
+			s.redisClient.Del(ctx, key)
+		}
+		return nil, ErrInvalidLoginChallenge
+	}
+
+	// Only the request that deletes the challenge may use it
+	deleted, err := s.redisClient.Del(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete login challenge: %w", err)
+	}
+	if deleted == 0 {
+		return nil, ErrInvalidLoginChallenge
+	}
+
+	user, err := s.db.GetUser(challenge.UserID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	s.recordLogin(user, challenge.Attempt, true)
+	return user, nil
+}
+
+// RecordLogin records a login whose method already proved the user's
+// identity, such as a magic link or an OAuth provider, mailing the user if
+// it came from a new device or an improbable location.
+func (s *Service) RecordLogin(user *models.User, attempt LoginAttempt) {
+	suspicious, err := s.isSuspicious(user.ID, hashToken(attempt.device()), attempt)
+	if err != nil {
+		log.Printf("Error checking login for user %s: %v", user.ID, err)
+	}
+	s.recordLogin(user, attempt, suspicious)
+	if suspicious {
+		body := fmt.Sprintf("Hi %s,\n\nYour Vibe Arcade account was signed in to from a new device or location:\n\n%s\n\nIf this was not you, change your password and your email account's password now.\n",
+			user.Username, attempt.describe())
+		if err := s.mailer.Send(user.Email, "New sign-in to your account", body); err != nil {
+			log.Printf("Error sending login alert to user %s: %v", user.ID, err)
+		}
+	}
+}
+
+// GetLoginHistory returns the user's most recent logins, newest first.
+func (s *Service) GetLoginHistory(userID uuid.UUID, limit int) ([]*models.LoginEvent, error) {
+	return s.db.GetLoginEvents(userID, limit)
+}
+
+// isSuspicious reports whether a login comes from a device the user has
+// not verified, or from a different country than a login shortly before.
+// A user's first login after this check was introduced is trusted.
+func (s *Service) isSuspicious(userID uuid.UUID, deviceHash string, attempt LoginAttempt) (bool, error) {
+	trusted, hasTrusted, err := s.db.GetDeviceTrust(userID, deviceHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to check device: %w", err)
+	}
+	if hasTrusted && !trusted {
+		return true, nil
+	}
+
+	if attempt.Country == "" {
+		return false, nil
+	}
+	last, err := s.db.GetLastLoginEvent(userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get last login: %w", err)
+	}
+	return last != nil && last.Country != "" && last.Country != attempt.Country &&
+		time.Since(last.CreatedAt) < s.cfg.ImpossibleTravelWindow, nil
+}
+
+func (s *Service) recordLogin(user *models.User, attempt LoginAttempt, suspicious bool) {
+	deviceHash := hashToken(attempt.device())
+	event := &models.LoginEvent{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		Method:     attempt.Method,
+		IP:         attempt.IP,
+		UserAgent:  attempt.UserAgent,
+		DeviceHash: deviceHash,
+		Country:    attempt.Country,
+		Suspicious: suspicious,
+	}
+	if err := s.db.CreateLoginEvent(event); err != nil {
+		log.Printf("Error recording login for user %s: %v", user.ID, err)
+	}
+	if err := s.db.TrustDevice(user.ID, deviceHash); err != nil {
+		log.Printf("Error trusting device for user %s: %v", user.ID, err)
+	}
+}
+
+func (a LoginAttempt) device() string {
+	if a.DeviceID != "" {
+		return "id:" + a.DeviceID
+	}
+	return "ua:" + a.UserAgent
+}
+
+func (a LoginAttempt) describe() string {
+	lines := []string{"IP address: " + a.IP, "Device: " + a.UserAgent}
+	if a.Country != "" {
+		lines = append(lines, "Country: "+a.Country)
+	}
+	lines = append(lines, "Time: "+time.Now().UTC().Format(time.RFC1123))
+	return "  " + strings.Join(lines, "\n  ")
+}
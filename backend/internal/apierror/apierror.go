@@ -0,0 +1,63 @@
+// Package apierror defines the error envelope every HTTP endpoint returns:
+//
+//	{"error": {"code": "game_not_found", "message": "Game not found", "request_id": "..."}}
+//
+// Clients branch on the code; the message is for humans and may change.
+package apierror
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the request ID in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the gin context key the request ID is stored under.
+const RequestIDKey = "requestID"
+
+// Generic codes shared by many endpoints. Endpoint-specific codes are
+// passed as literals at the call site.
+const (
+	CodeInvalidRequest     = "invalid_request"
+	CodeValidationFailed   = "validation_failed"
+	CodeUnauthorized       = "unauthorized"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not_found"
+	CodeRateLimited        = "rate_limited"
+	CodeInternal           = "internal_error"
+	CodeServiceUnavailable = "service_unavailable"
+)
+
+type Response struct {
+	Error Error `json:"error"`
+}
+
+type Error struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Respond aborts the request with an error envelope.
+func Respond(c *gin.Context, status int, code, message string) {
+	RespondFields(c, status, code, message, nil)
+}
+
+// RespondFields aborts the request with an error envelope listing the
+// fields that failed validation.
+func RespondFields(c *gin.Context, status int, code, message string, fields []FieldError) {
+	c.AbortWithStatusJSON(status, Response{Error: Error{
+		Code:      code,
+		Message:   message,
+		Fields:    fields,
+		RequestID: c.GetString(RequestIDKey),
+	}})
+}
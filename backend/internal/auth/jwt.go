@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"errors"
 	"time"
 
@@ -11,6 +14,9 @@ import (
 type Claims struct {
 	UserID   uuid.UUID `json:"user_id"`
 	Username string    `json:"username"`
+	// Scope is the space-separated scopes of a restricted token; empty
+	// means full access
+	Scope string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -23,23 +29,89 @@ type JWTManager struct {
 	secretKey       string
 	accessTokenTTL  time.Duration
 	refreshTokenTTL time.Duration
+	issuer          string
+	audience        string
+	// scopedTokenMaxTTL caps how long scoped tokens last
+	scopedTokenMaxTTL time.Duration
+
+	// Set by UseKeyPair; without them tokens are signed with secretKey
+	method     jwt.SigningMethod
+	signingKey crypto.Signer
+	keyID      string
+	publicKeys map[string]crypto.PublicKey
+	jwks       JWKSet
 }
 
 func NewJWTManager(secretKey string, accessTTL, refreshTTL time.Duration) *JWTManager {
 	return &JWTManager{
-		secretKey:       secretKey,
-		accessTokenTTL:  accessTTL,
-		refreshTokenTTL: refreshTTL,
+		secretKey:         secretKey,
+		accessTokenTTL:    accessTTL,
+		refreshTokenTTL:   refreshTTL,
+		method:            jwt.SigningMethodHS256,
+		scopedTokenMaxTTL: refreshTTL,
 	}
 }
 
+// UseKeyPair signs tokens with an RSA (RS256) or Ed25519 (EdDSA) key instead
+// of the shared secret, and publishes its public half through JWKS. Tokens
+// signed by a retired key are still accepted, so keys can be rotated
+// without logging everyone out. It must be called before tokens are issued.
+func (j *JWTManager) UseKeyPair(key crypto.Signer, retired ...crypto.PublicKey) error {
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		j.method = jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		j.method = jwt.SigningMethodEdDSA
+	default:
+		return errors.New("signing key must be RSA or Ed25519")
+	}
+
+	j.signingKey = key
+	j.publicKeys = make(map[string]crypto.PublicKey)
+	j.jwks = JWKSet{Keys: []JWK{}}
+	for i, public := range append([]crypto.PublicKey{key.Public()}, retired...) {
+		jwk, err := publicJWK(public)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			j.keyID = jwk.Kid
+		}
+		if _, exists := j.publicKeys[jwk.Kid]; exists {
+			continue
+		}
+		j.publicKeys[jwk.Kid] = public
+		j.jwks.Keys = append(j.jwks.Keys, jwk)
+	}
+	return nil
+}
+
+// SetIssuer stamps tokens with the given iss and aud claims and rejects
+// tokens whose claims differ, so tokens minted for another environment or
+// service are not accepted. An empty value leaves that claim out. It must be
+// called before tokens are issued.
+func (j *JWTManager) SetIssuer(issuer, audience string) {
+	j.issuer = issuer
+	j.audience = audience
+}
+
+// JWKS returns the public keys tokens may be signed with, current key
+// first. It is empty when tokens are signed with the shared secret.
+func (j *JWTManager) JWKS() JWKSet {
+	if j.jwks.Keys == nil {
+		return JWKSet{Keys: []JWK{}}
+	}
+	return j.jwks
+}
+
 func (j *JWTManager) GenerateTokenPair(userID uuid.UUID, username string) (*TokenPair, error) {
-	accessToken, err := j.generateToken(userID, username, j.accessTokenTTL)
+	now := time.Now()
+	accessToken, err := j.generateToken(userID, username, "", now, now.Add(j.accessTokenTTL))
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := j.generateToken(userID, username, j.refreshTokenTTL)
+	refreshToken, err := j.generateToken(userID, username, "", now, now.Add(j.refreshTokenTTL))
 	if err != nil {
 		return nil, err
 	}
@@ -50,29 +122,40 @@ func (j *JWTManager) GenerateTokenPair(userID uuid.UUID, username string) (*Toke
 	}, nil
 }
 
-func (j *JWTManager) generateToken(userID uuid.UUID, username string, ttl time.Duration) (string, error) {
-	now := time.Now()
+func (j *JWTManager) generateToken(userID uuid.UUID, username, scope string, now, expiresAt time.Time) (string, error) {
 	claims := Claims{
 		UserID:   userID,
 		Username: username,
+		Scope:    scope,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			Issuer:    j.issuer,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
+	if j.audience != "" {
+		claims.Audience = jwt.ClaimStrings{j.audience}
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
+	token := jwt.NewWithClaims(j.method, claims)
+	if j.signingKey == nil {
+		return token.SignedString([]byte(j.secretKey))
+	}
+	token.Header["kid"] = j.keyID
+	return token.SignedString(j.signingKey)
 }
 
 func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-		return []byte(j.secretKey), nil
-	})
+	var options []jwt.ParserOption
+	if j.issuer != "" {
+		options = append(options, jwt.WithIssuer(j.issuer))
+	}
+	if j.audience != "" {
+		options = append(options, jwt.WithAudience(j.audience))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.verificationKey, options...)
 
 	if err != nil {
 		return nil, err
@@ -85,11 +168,41 @@ func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
+func (j *JWTManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	if j.publicKeys == nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return []byte(j.secretKey), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.publicKeys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	// The algorithm must match the key, never just the token's header
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+	case ed25519.PublicKey:
+		if _, ok := token.Method.(*jwt.SigningMethodEd25519); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+	}
+	return key, nil
+}
+
 func (j *JWTManager) RefreshToken(refreshTokenString string) (*TokenPair, error) {
 	claims, err := j.ValidateToken(refreshTokenString)
 	if err != nil {
 		return nil, err
 	}
+	if claims.Scoped() {
+		return nil, ErrScopedRefresh
+	}
 
 	return j.GenerateTokenPair(claims.UserID, claims.Username)
 }
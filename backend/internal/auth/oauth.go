@@ -0,0 +1,320 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+// OAuth providers.
+const (
+	ProviderGoogle = "google"
+	ProviderApple  = "apple"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown_provider")
+	ErrOAuthExchange   = errors.New("oauth_exchange_failed")
+	ErrInvalidIDToken  = errors.New("invalid_id_token")
+)
+
+// jwksMinRefresh stops unknown key IDs from making us refetch a provider's
+// keys on every request.
+const (
+	jwksMaxAge     = time.Hour
+	jwksMinRefresh = time.Minute
+)
+
+// OAuthIdentity is the account a provider says signed in.
+type OAuthIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// OAuthProvider completes the authorization code flow with one provider.
+type OAuthProvider interface {
+	// Exchange trades an authorization code for the identity it was issued
+	// to. codeVerifier is the PKCE verifier, if the client used one.
+	Exchange(ctx context.Context, code, redirectURI, codeVerifier string) (*OAuthIdentity, error)
+}
+
+// NewOAuthProviders returns the providers with credentials configured.
+func NewOAuthProviders(cfg *config.OAuthConfig) (map[string]OAuthProvider, error) {
+	providers := make(map[string]OAuthProvider)
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	if cfg.GoogleClientID != "" {
+		providers[ProviderGoogle] = &oidcProvider{
+			name:     ProviderGoogle,
+			tokenURL: "https://oauth2.googleapis.com/token",
+			issuers:  []string{"https://accounts.google.com", "accounts.google.com"},
+			clientID: cfg.GoogleClientID,
+			secret:   func() (string, error) { return cfg.GoogleClientSecret, nil },
+			keys:     &jwks{url: "https://www.googleapis.com/oauth2/v3/certs", client: client},
+			client:   client,
+		}
+	}
+
+	if cfg.AppleClientID != "" {
+		key, err := parseApplePrivateKey(cfg.ApplePrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		providers[ProviderApple] = &oidcProvider{
+			name:     ProviderApple,
+			tokenURL: "https://appleid.apple.com/auth/token",
+			issuers:  []string{"https://appleid.apple.com"},
+			clientID: cfg.AppleClientID,
+			secret: func() (string, error) {
+				return appleClientSecret(cfg.AppleClientID, cfg.AppleTeamID, cfg.AppleKeyID, key)
+			},
+			keys:   &jwks{url: "https://appleid.apple.com/auth/keys", client: client},
+			client: client,
+		}
+	}
+
+	return providers, nil
+}
+
+// oidcProvider exchanges codes at an OpenID Connect token endpoint and
+// verifies the ID token that comes back against the provider's keys.
+type oidcProvider struct {
+	name     string
+	tokenURL string
+	issuers  []string
+	clientID string
+	secret   func() (string, error)
+	keys     *jwks
+	client   *http.Client
+}
+
+type idTokenClaims struct {
+	Email string `json:"email"`
+	// EmailVerified is a boolean from Google and a string from Apple
+	EmailVerified interface{} `json:"email_verified"`
+	jwt.RegisteredClaims
+}
+
+func (p *oidcProvider) Exchange(ctx context.Context, code, redirectURI, codeVerifier string) (*OAuthIdentity, error) {
+	secret, err := p.secret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s client secret: %w", p.name, err)
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"client_id":     {p.clientID},
+		"client_secret": {secret},
+	}
+	if redirectURI != "" {
+		form.Set("redirect_uri", redirectURI)
+	}
+	if codeVerifier != "" {
+		form.Set("code_verifier", codeVerifier)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode %s token response: %w", p.name, err)
+	}
+	if resp.StatusCode != http.StatusOK || body.IDToken == "" {
+		return nil, fmt.Errorf("%w: %s returned %d %s", ErrOAuthExchange, p.name, resp.StatusCode, body.Error)
+	}
+
+	return p.verify(ctx, body.IDToken)
+}
+
+func (p *oidcProvider) verify(ctx context.Context, idToken string) (*OAuthIdentity, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.keys.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithAudience(p.clientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	validIssuer := false
+	for _, issuer := range p.issuers {
+		if claims.Issuer == issuer {
+			validIssuer = true
+			break
+		}
+	}
+	if !validIssuer || claims.Subject == "" {
+		return nil, ErrInvalidIDToken
+	}
+
+	verified := false
+	switch v := claims.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = v == "true"
+	}
+
+	return &OAuthIdentity{
+		Provider:      p.name,
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(claims.Email),
+		EmailVerified: verified,
+	}, nil
+}
+
+// jwks caches a provider's published RSA signing keys by key ID.
+type jwks struct {
+	url     string
+	client  *http.Client
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+}
+
+func (k *jwks) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	key, ok := k.keys[kid]
+	age := time.Since(k.fetched)
+	if (ok && age < jwksMaxAge) || (!ok && age < jwksMinRefresh) {
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return key, nil
+	}
+
+	if err := k.refresh(ctx); err != nil {
+		if ok {
+			// Keep using a known key if the provider is briefly unreachable
+			return key, nil
+		}
+		return nil, err
+	}
+	if key, ok = k.keys[kid]; !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// refresh refetches the keys. The caller must hold k.mu.
+func (k *jwks) refresh(ctx context.Context) error {
+	k.fetched = time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch signing keys: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode signing keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	k.keys = keys
+	return nil
+}
+
+// appleClientSecret signs the short-lived JWT Apple accepts as a client
+// secret.
+func appleClientSecret(clientID, teamID, keyID string, key *ecdsa.PrivateKey) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+		Issuer:    teamID,
+		Subject:   clientID,
+		Audience:  jwt.ClaimStrings{"https://appleid.apple.com"},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+	})
+	token.Header["kid"] = keyID
+	return token.SignedString(key)
+}
+
+// parseApplePrivateKey reads the PKCS #8 key downloaded from Apple. Newlines
+// may be written as \n so the key fits in an environment variable.
+func parseApplePrivateKey(value string) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(strings.ReplaceAll(value, `\n`, "\n")))
+	if block == nil {
+		return nil, errors.New("invalid Apple private key: no PEM block")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Apple private key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid Apple private key: not an EC key")
+	}
+	return key, nil
+}
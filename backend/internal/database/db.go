@@ -1,117 +1,461 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/szaher/vibeboard/backend/internal/database/queries"
 	"github.com/szaher/vibeboard/backend/internal/models"
 	"github.com/szaher/vibeboard/backend/pkg/config"
 )
 
 type DB struct {
-	conn *sql.DB
+	conn    *sql.DB
+	queries *queries.Queries
+	// replicas serve reads that can tolerate replication lag; see reader
+	replicas []*sql.DB
+	next     atomic.Uint32
+	// dsn opens dedicated connections, such as the game change listener's
+	dsn string
+	// driver is DriverPostgres or DriverSQLite
+	driver string
+	// cleanup runs after the pool closes
+	cleanup func()
+	// snapshotInterval is how many moves apart game state is snapshotted;
+	// zero disables snapshots
+	snapshotInterval int
 }
 
 func NewDB(cfg *config.DatabaseConfig) (*DB, error) {
+	switch cfg.Driver {
+	case DriverPostgres, "":
+	case DriverSQLite:
+		conn, cleanup, err := openSQLite(cfg.SQLitePath, cfg)
+		if err != nil {
+			return nil, err
+		}
+		registerPoolMetrics(conn, cfg.Name)
+		return &DB{conn: conn, queries: queries.New(conn), driver: DriverSQLite, cleanup: cleanup}, nil
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+	}
+
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode)
 
-	conn, err := sql.Open("postgres", dsn)
+	conn, err := openPool("postgres", dsn, cfg)
+	if err != nil {
+		return nil, err
+	}
+	registerPoolMetrics(conn, cfg.Name)
+	db := &DB{conn: conn, queries: queries.New(conn), dsn: dsn, driver: DriverPostgres}
+
+	for i, replicaDSN := range cfg.ReplicaDSNs {
+		replica, err := openPool("postgres", replicaDSN, cfg)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("replica %d: %w", i+1, err)
+		}
+		registerPoolMetrics(replica, fmt.Sprintf("%s_replica_%d", cfg.Name, i+1))
+		db.replicas = append(db.replicas, replica)
+	}
+
+	return db, nil
+}
+
+func openPool(driverName, dsn string, cfg *config.DatabaseConfig) (*sql.DB, error) {
+	conn, err := sql.Open(driverName, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	conn.SetMaxOpenConns(cfg.MaxOpenConns)
+	conn.SetMaxIdleConns(cfg.MaxIdleConns)
+	conn.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	conn.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
 	if err := conn.Ping(); err != nil {
+		conn.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
+	return conn, nil
+}
+
+// reader returns a replica, in turn, for listings, leaderboards and
+// profiles, which may be a moment behind the primary. Anything that writes,
+// or reads state it is about to change, must use db.conn.
+func (db *DB) reader() *sql.DB {
+	if len(db.replicas) == 0 {
+		return db.conn
+	}
+	return db.replicas[int(db.next.Add(1))%len(db.replicas)]
+}
+
+// Ping checks that the primary accepts queries.
+func (db *DB) Ping(ctx context.Context) error {
+	return db.conn.PingContext(ctx)
+}
+
+// PingReplicas checks every read replica, returning the first failure.
+func (db *DB) PingReplicas(ctx context.Context) error {
+	for i, replica := range db.replicas {
+		if err := replica.PingContext(ctx); err != nil {
+			return fmt.Errorf("replica %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
 
-	return &DB{conn: conn}, nil
+// HasReplicas reports whether reads are spread over read replicas.
+func (db *DB) HasReplicas() bool {
+	return len(db.replicas) > 0
 }
 
 func (db *DB) Close() error {
-	return db.conn.Close()
+	for _, replica := range db.replicas {
+		if err := replica.Close(); err != nil {
+			log.Printf("Error closing database replica: %v", err)
+		}
+	}
+	err := db.conn.Close()
+	if db.cleanup != nil {
+		db.cleanup()
+	}
+	return err
 }
 
 // User operations
 func (db *DB) CreateUser(user *models.User) error {
-	query := `
-		INSERT INTO users (id, email, username, password_hash, created_at, updated_at, is_active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)`
-
 	now := time.Now()
 	user.CreatedAt = now
 	user.UpdatedAt = now
+	if user.Role == "" {
+		user.Role = models.UserRolePlayer
+	}
+	if user.ProfileVisibility == "" {
+		user.ProfileVisibility = models.ProfileVisibilityPublic
+	}
 
-	_, err := db.conn.Exec(query, user.ID, user.Email, user.Username, user.Password, user.CreatedAt, user.UpdatedAt, user.IsActive)
-	return err
+	return db.queries.CreateUser(context.Background(), queries.CreateUserParams{
+		ID:                user.ID,
+		Email:             user.Email,
+		Username:          user.Username,
+		PasswordHash:      user.Password,
+		CreatedAt:         user.CreatedAt,
+		UpdatedAt:         user.UpdatedAt,
+		IsActive:          user.IsActive,
+		Role:              user.Role,
+		AvatarUrl:         user.AvatarURL,
+		ProfileVisibility: user.ProfileVisibility,
+	})
 }
 
 func (db *DB) GetUser(id uuid.UUID) (*models.User, error) {
-	query := `
-		SELECT id, email, username, password_hash, created_at, updated_at, is_active
-		FROM users WHERE id = $1`
+	return userFromRow(db.queries.GetUser(context.Background(), id))
+}
 
-	user := &models.User{}
-	err := db.conn.QueryRow(query, id).Scan(
-		&user.ID, &user.Email, &user.Username, &user.Password,
-		&user.CreatedAt, &user.UpdatedAt, &user.IsActive,
-	)
+func (db *DB) GetUserByEmail(email string) (*models.User, error) {
+	return userFromRow(db.queries.GetUserByEmail(context.Background(), email))
+}
+
+func (db *DB) UpdateUser(user *models.User) error {
+	user.UpdatedAt = time.Now()
+	return db.queries.UpdateUser(context.Background(), queries.UpdateUserParams{
+		ID:                user.ID,
+		Email:             user.Email,
+		Username:          user.Username,
+		PasswordHash:      user.Password,
+		UpdatedAt:         user.UpdatedAt,
+		IsActive:          user.IsActive,
+		Role:              user.Role,
+		AvatarUrl:         user.AvatarURL,
+		ProfileVisibility: user.ProfileVisibility,
+	})
+}
+
+// UpdatePassword replaces a user's password hash.
+func (db *DB) UpdatePassword(userID uuid.UUID, passwordHash string) error {
+	return db.queries.UpdatePassword(context.Background(), queries.UpdatePasswordParams{ID: userID, PasswordHash: passwordHash})
+}
+
+// UpdateEmail changes a user's email. It fails with a unique violation if
+// another account already uses it.
+func (db *DB) UpdateEmail(userID uuid.UUID, email string) error {
+	return db.queries.UpdateEmail(context.Background(), queries.UpdateEmailParams{ID: userID, Email: email})
+}
+
+// UpdateUsername changes a user's username unless they last changed it
+// after changedBefore, reporting whether it was changed. It fails with a
+// unique violation if the username is taken.
+func (db *DB) UpdateUsername(userID uuid.UUID, username string, changedBefore time.Time) (bool, error) {
+	rows, err := db.queries.UpdateUsername(context.Background(), queries.UpdateUsernameParams{
+		ID:            userID,
+		Username:      username,
+		ChangedBefore: changedBefore,
+	})
+	return rows > 0, err
+}
+
+// SoftDeleteUser deletes an account: the user row is scrubbed of personal
+// data and kept for the games it played, while its linked logins, login
+// history, friendships and blocks are removed. It reports false if the user
+// was already deleted.
+func (db *DB) SoftDeleteUser(userID uuid.UUID) (bool, error) {
+	ctx := context.Background()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back account deletion: %v", err)
+		}
+	}()
+
+	rows, err := db.queries.WithTx(tx).SoftDeleteUser(ctx, userID)
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	for _, query := range []string{
+		`DELETE FROM user_identities WHERE user_id = $1`,
+		`DELETE FROM login_events WHERE user_id = $1`,
+		`DELETE FROM trusted_devices WHERE user_id = $1`,
+		`DELETE FROM friendships WHERE user_id = $1 OR friend_id = $1`,
+		`DELETE FROM user_blocks WHERE blocker_id = $1 OR blocked_id = $1`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return false, err
+		}
+	}
 
+	return true, tx.Commit()
+}
+
+// likeEscaper escapes the LIKE wildcards in a search prefix.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchUsers finds active users whose username starts with or resembles
+// the query, ignoring case. Users blocked by or blocking the viewer are
+// left out.
+func (db *DB) SearchUsers(query string, viewerID uuid.UUID, limit int) ([]*models.UserSummary, error) {
+	query = strings.ToLower(query)
+	rows, err := queries.New(db.reader()).SearchUsers(context.Background(), queries.SearchUsersParams{
+		Prefix:     likeEscaper.Replace(query),
+		Query:      query,
+		ViewerID:   viewerID,
+		MaxResults: int32(limit),
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	users := make([]*models.UserSummary, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, &models.UserSummary{UserID: row.ID, Username: row.Username, AvatarURL: row.AvatarUrl})
+	}
+	return users, nil
+}
+
+// displayName selects the username of the users row aliased as alias, or
+// the placeholder if the user deleted their account.
+func displayName(alias string) string {
+	return "CASE WHEN " + alias + ".deleted_at IS NULL THEN " + alias + ".username ELSE '" + models.DeletedUsername + "' END"
+}
+
+// IsUniqueViolation reports whether err is a write rejected by a unique
+// constraint.
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" || isSQLiteUniqueViolation(err)
+}
+
+func userFromRow(row queries.User, err error) (*models.User, error) {
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{
+		ID:                row.ID,
+		Email:             row.Email,
+		Username:          row.Username,
+		Password:          row.PasswordHash,
+		CreatedAt:         row.CreatedAt,
+		UpdatedAt:         row.UpdatedAt,
+		IsActive:          row.IsActive,
+		Role:              row.Role,
+		AvatarURL:         row.AvatarUrl,
+		ProfileVisibility: row.ProfileVisibility,
+		UsernameChangedAt: row.UsernameChangedAt,
+		DeletedAt:         row.DeletedAt,
+	}
+	if user.Deleted() {
+		user.Username = models.DeletedUsername
+	}
 	return user, nil
 }
 
-func (db *DB) GetUserByEmail(email string) (*models.User, error) {
+// User identity operations
+
+// GetUserByIdentity returns the user a provider account is linked to.
+func (db *DB) GetUserByIdentity(provider, subject string) (*models.User, error) {
+	return userFromRow(db.queries.GetUserByIdentity(context.Background(), queries.GetUserByIdentityParams{Provider: provider, Subject: subject}))
+}
+
+// CreateIdentity links a provider account to an existing user. It fails
+// with a unique violation if the account is already linked.
+func (db *DB) CreateIdentity(provider, subject string, userID uuid.UUID, email string) error {
 	query := `
-		SELECT id, email, username, password_hash, created_at, updated_at, is_active
-		FROM users WHERE email = $1`
+		INSERT INTO user_identities (provider, subject, user_id, email)
+		VALUES ($1, $2, $3, $4)`
 
-	user := &models.User{}
-	err := db.conn.QueryRow(query, email).Scan(
-		&user.ID, &user.Email, &user.Username, &user.Password,
-		&user.CreatedAt, &user.UpdatedAt, &user.IsActive,
-	)
+	_, err := db.conn.Exec(query, provider, subject, userID, email)
+	return err
+}
+
+// CreateUserWithIdentity creates a user linked to a provider account in a
+// single statement, so a failed link leaves no user behind.
+func (db *DB) CreateUserWithIdentity(user *models.User, provider, subject string) error {
+	query := `
+		WITH new_user AS (
+			INSERT INTO users (id, email, username, password_hash, created_at, updated_at, is_active, role, avatar_url, profile_visibility)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id, email
+		)
+		INSERT INTO user_identities (provider, subject, user_id, email)
+		SELECT $11, $12, id, email FROM new_user`
+
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	if user.Role == "" {
+		user.Role = models.UserRolePlayer
+	}
+	if user.ProfileVisibility == "" {
+		user.ProfileVisibility = models.ProfileVisibilityPublic
+	}
+
+	_, err := db.conn.Exec(query, user.ID, user.Email, user.Username, user.Password, user.CreatedAt, user.UpdatedAt, user.IsActive, user.Role, user.AvatarURL, user.ProfileVisibility, provider, subject)
+	return err
+}
+
+// Login history operations
+
+func (db *DB) CreateLoginEvent(event *models.LoginEvent) error {
+	query := `
+		INSERT INTO login_events (id, user_id, method, ip, user_agent, device_hash, country, suspicious, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	event.CreatedAt = time.Now()
+	_, err := db.conn.Exec(query, event.ID, event.UserID, event.Method, event.IP, event.UserAgent,
+		event.DeviceHash, event.Country, event.Suspicious, event.CreatedAt)
+	return err
+}
 
+// GetLoginEvents returns a user's most recent logins, newest first.
+func (db *DB) GetLoginEvents(userID uuid.UUID, limit int) ([]*models.LoginEvent, error) {
+	query := `
+		SELECT ` + loginEventColumns + ` FROM login_events
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	rows, err := db.conn.Query(query, userID, limit)
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
 
-	return user, nil
+	var events []*models.LoginEvent
+	for rows.Next() {
+		event, err := scanLoginEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
 }
 
-func (db *DB) UpdateUser(user *models.User) error {
+// GetLastLoginEvent returns a user's latest login, or nil if they have
+// none.
+func (db *DB) GetLastLoginEvent(userID uuid.UUID) (*models.LoginEvent, error) {
+	query := `SELECT ` + loginEventColumns + ` FROM login_events WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1`
+
+	event, err := scanLoginEvent(db.conn.QueryRow(query, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return event, err
+}
+
+// GetDeviceTrust reports whether the device is trusted for the user, and
+// whether the user has any trusted devices at all.
+func (db *DB) GetDeviceTrust(userID uuid.UUID, deviceHash string) (trusted, hasAny bool, err error) {
 	query := `
-		UPDATE users SET email = $2, username = $3, password_hash = $4, updated_at = $5, is_active = $6
-		WHERE id = $1`
+		SELECT COALESCE(BOOL_OR(device_hash = $2), false), COUNT(*) > 0
+		FROM trusted_devices WHERE user_id = $1`
 
-	user.UpdatedAt = time.Now()
-	_, err := db.conn.Exec(query, user.ID, user.Email, user.Username, user.Password, user.UpdatedAt, user.IsActive)
+	err = db.conn.QueryRow(query, userID, deviceHash).Scan(&trusted, &hasAny)
+	return trusted, hasAny, err
+}
+
+// TrustDevice marks a device as trusted for the user, or refreshes when it
+// was last used.
+func (db *DB) TrustDevice(userID uuid.UUID, deviceHash string) error {
+	query := `
+		INSERT INTO trusted_devices (user_id, device_hash, created_at, last_used_at)
+		VALUES ($1, $2, NOW(), NOW())
+		ON CONFLICT (user_id, device_hash) DO UPDATE SET last_used_at = NOW()`
+
+	_, err := db.conn.Exec(query, userID, deviceHash)
 	return err
 }
 
+const loginEventColumns = `id, user_id, method, ip, user_agent, device_hash, country, suspicious, created_at`
+
+func scanLoginEvent(row rowScanner) (*models.LoginEvent, error) {
+	event := &models.LoginEvent{}
+	err := row.Scan(
+		&event.ID, &event.UserID, &event.Method, &event.IP, &event.UserAgent,
+		&event.DeviceHash, &event.Country, &event.Suspicious, &event.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
 // User stats operations
 func (db *DB) GetUserStats(userID uuid.UUID) (*models.UserStats, error) {
 	query := `
-		SELECT user_id, games_played, games_won, games_lost, rating, updated_at
+		SELECT user_id, games_played, games_won, games_lost, rating, rating_deviation, rated_games, tier, division,
+			series_played, series_won, updated_at
 		FROM user_stats WHERE user_id = $1`
 
 	stats := &models.UserStats{}
 	err := db.conn.QueryRow(query, userID).Scan(
 		&stats.UserID, &stats.GamesPlayed, &stats.GamesWon, &stats.GamesLost,
-		&stats.Rating, &stats.UpdatedAt,
+		&stats.Rating, &stats.RatingDeviation, &stats.RatedGames, &stats.Tier, &stats.Division,
+		&stats.SeriesPlayed, &stats.SeriesWon, &stats.UpdatedAt,
 	)
 
 	if err != nil {
 		return nil, err
 	}
 
+	stats.Provisional = models.IsProvisional(stats.RatedGames)
 	return stats, nil
 }
 
@@ -131,125 +475,282 @@ func (db *DB) UpdateUserStats(stats *models.UserStats) error {
 	return err
 }
 
-// Game operations
-func (db *DB) CreateGame(game *models.Game) error {
-	query := `
-		INSERT INTO games (id, game_type, status, player1_id, player2_id, winner_id, current_turn, game_state, created_at, updated_at, started_at, ended_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+// AddGameResult counts a finished game toward the user's record.
+func (db *DB) AddGameResult(userID uuid.UUID, result models.GameResult) error {
+	won, lost := 0, 0
+	switch result {
+	case models.GameResultWin:
+		won = 1
+	case models.GameResultLoss:
+		lost = 1
+	}
 
-	now := time.Now()
-	game.CreatedAt = now
-	game.UpdatedAt = now
+	query := `
+		INSERT INTO user_stats (user_id, games_played, games_won, games_lost)
+		VALUES ($1, 1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			games_played = user_stats.games_played + 1,
+			games_won = user_stats.games_won + EXCLUDED.games_won,
+			games_lost = user_stats.games_lost + EXCLUDED.games_lost`
 
-	_, err := db.conn.Exec(query, game.ID, game.Type, game.Status, game.Player1ID, game.Player2ID, game.WinnerID, game.CurrentTurn, game.GameState, game.CreatedAt, game.UpdatedAt, game.StartedAt, game.EndedAt)
+	_, err := db.conn.Exec(query, userID, won, lost)
 	return err
 }
 
-func (db *DB) GetGame(id uuid.UUID) (*models.Game, error) {
+// AddSeriesResult counts a finished series toward the user's record.
+func (db *DB) AddSeriesResult(userID uuid.UUID, won bool) error {
 	query := `
-		SELECT id, game_type, status, player1_id, player2_id, winner_id, current_turn, game_state, created_at, updated_at, started_at, ended_at
-		FROM games WHERE id = $1`
+		INSERT INTO user_stats (user_id, series_played, series_won)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET
+			series_played = user_stats.series_played + 1,
+			series_won = user_stats.series_won + EXCLUDED.series_won`
 
-	game := &models.Game{}
-	err := db.conn.QueryRow(query, id).Scan(
-		&game.ID, &game.Type, &game.Status, &game.Player1ID, &game.Player2ID,
-		&game.WinnerID, &game.CurrentTurn, &game.GameState, &game.CreatedAt,
-		&game.UpdatedAt, &game.StartedAt, &game.EndedAt,
-	)
+	seriesWon := 0
+	if won {
+		seriesWon = 1
+	}
+	_, err := db.conn.Exec(query, userID, seriesWon)
+	return err
+}
+
+// GetPlayerStats returns the records of the given users as the viewer may
+// see them, applying the same visibility and block rules as public
+// profiles. Inactive users and users blocked either way are left out.
+func (db *DB) GetPlayerStats(viewerID uuid.UUID, userIDs []uuid.UUID) ([]*models.PlayerStats, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		ids[i] = userID.String()
+	}
+
+	query := `
+		SELECT u.id, u.username, u.avatar_url,
+			COALESCE(s.rating, 1000), COALESCE(s.games_played, 0), COALESCE(s.games_won, 0), COALESCE(s.games_lost, 0),
+			u.id = $2 OR u.profile_visibility = 'public' OR (u.profile_visibility = 'friends' AND EXISTS (
+				SELECT 1 FROM friendships f
+				WHERE f.status = 'accepted'
+				AND ((f.user_id = u.id AND f.friend_id = $2) OR (f.user_id = $2 AND f.friend_id = u.id))
+			))
+		FROM users u
+		LEFT JOIN user_stats s ON s.user_id = u.id
+		WHERE u.id = ANY($1::uuid[]) AND u.is_active = true
+		AND NOT EXISTS (
+			SELECT 1 FROM user_blocks
+			WHERE (blocker_id = $2 AND blocked_id = u.id) OR (blocker_id = u.id AND blocked_id = $2)
+		)`
 
+	rows, err := db.reader().Query(query, pq.Array(ids), viewerID)
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
 
-	return game, nil
-}
+	var players []*models.PlayerStats
+	for rows.Next() {
+		player := &models.PlayerStats{}
+		var rating, played, won, lost int
+		var visible bool
+		err := rows.Scan(&player.UserID, &player.Username, &player.AvatarURL, &rating, &played, &won, &lost, &visible)
+		if err != nil {
+			return nil, err
+		}
 
-func (db *DB) UpdateGame(game *models.Game) error {
-	query := `
-		UPDATE games SET game_type = $2, status = $3, player1_id = $4, player2_id = $5, winner_id = $6,
-		current_turn = $7, game_state = $8, updated_at = $9, started_at = $10, ended_at = $11
-		WHERE id = $1`
+		if visible {
+			player.Rating, player.GamesPlayed, player.GamesWon, player.GamesLost = &rating, &played, &won, &lost
+		} else {
+			player.Restricted = true
+		}
+		players = append(players, player)
+	}
 
-	game.UpdatedAt = time.Now()
-	_, err := db.conn.Exec(query, game.ID, game.Type, game.Status, game.Player1ID, game.Player2ID, game.WinnerID, game.CurrentTurn, game.GameState, game.UpdatedAt, game.StartedAt, game.EndedAt)
-	return err
+	return players, rows.Err()
 }
 
-func (db *DB) GetGames(status, gameType string, limit, offset int) ([]*models.Game, error) {
-	query := `
-		SELECT id, game_type, status, player1_id, player2_id, winner_id, current_turn, game_state, created_at, updated_at, started_at, ended_at
-		FROM games`
+// Rating operations
 
-	args := []interface{}{}
-	conditions := []string{}
-	argIndex := 1
+// UpdateRatings rates the players of a finished game together, recording
+// each change in the rating history. rate is given the players' ratings in
+// the order of userIDs and returns their new ratings.
+func (db *DB) UpdateRatings(game *models.Game, system models.RatingSystem, userIDs []uuid.UUID, rate func(ratings []models.PlayerRating) []models.PlayerRating) ([]*models.RatingChange, error) {
+	ctx := context.Background()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back rating update: %v", err)
+		}
+	}()
 
-	if status != "" {
-		conditions = append(conditions, fmt.Sprintf("status = $%d", argIndex))
-		args = append(args, status)
-		argIndex++
+	for _, userID := range userIDs {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO user_stats (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING`, userID); err != nil {
+			return nil, err
+		}
 	}
 
-	if gameType != "" {
-		conditions = append(conditions, fmt.Sprintf("game_type = $%d", argIndex))
-		args = append(args, gameType)
-		argIndex++
+	// Rows are locked in a fixed order so concurrent games cannot deadlock
+	rows, err := tx.QueryContext(ctx, `
+		SELECT user_id, rating, rating_deviation, rating_volatility, rated_games, tier, division FROM user_stats
+		WHERE user_id = ANY($1::uuid[]) ORDER BY user_id FOR UPDATE`, pq.Array(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[uuid.UUID]models.PlayerRating, len(userIDs))
+	for rows.Next() {
+		var userID uuid.UUID
+		var rating models.PlayerRating
+		err := rows.Scan(&userID, &rating.Rating, &rating.Deviation, &rating.Volatility,
+			&rating.RatedGames, &rating.Tier.Tier, &rating.Tier.Division)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		current[userID] = rating
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	ratings := make([]models.PlayerRating, len(userIDs))
+	for i, userID := range userIDs {
+		ratings[i] = current[userID]
 	}
+	updated := rate(ratings)
 
-	if len(conditions) > 0 {
-		query += " WHERE " + conditions[0]
-		for i := 1; i < len(conditions); i++ {
-			query += " AND " + conditions[i]
+	now := time.Now()
+	changes := make([]*models.RatingChange, len(userIDs))
+	for i, userID := range userIDs {
+		change := &models.RatingChange{
+			ID:        uuid.New(),
+			UserID:    userID,
+			GameID:    &game.ID,
+			GameType:  game.Type,
+			System:    system,
+			OldRating: ratings[i].Rating,
+			NewRating: updated[i].Rating,
+			Delta:     updated[i].Rating - ratings[i].Rating,
+			Deviation: &updated[i].Deviation,
+			CreatedAt: now,
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE user_stats SET rating = $2, rating_deviation = $3, rating_volatility = $4,
+				rated_games = $5, tier = $6, division = $7, last_rated_at = $8, updated_at = $8
+			WHERE user_id = $1`, userID, updated[i].Rating, updated[i].Deviation, updated[i].Volatility,
+			updated[i].RatedGames, updated[i].Tier.Tier, updated[i].Tier.Division, now)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO rating_history (id, user_id, game_id, game_type, system, old_rating, new_rating, delta,
+				deviation, old_deviation, old_volatility, old_tier, old_division, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+			change.ID, change.UserID, change.GameID, change.GameType, change.System,
+			change.OldRating, change.NewRating, change.Delta,
+			change.Deviation, ratings[i].Deviation, ratings[i].Volatility,
+			ratings[i].Tier.Tier, ratings[i].Tier.Division, change.CreatedAt)
+		if err != nil {
+			return nil, err
 		}
+		changes[i] = change
 	}
 
-	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, limit, offset)
+	return changes, tx.Commit()
+}
 
-	rows, err := db.conn.Query(query, args...)
+// DecayRatings applies decay to up to limit active players who have not
+// played a rated game since inactiveSince and whose rating has not decayed
+// since decayedBefore, recording each change in their rating history under
+// system with no game. It returns how many players it looked at; fewer
+// than limit means none are left.
+func (db *DB) DecayRatings(system models.RatingSystem, inactiveSince, decayedBefore time.Time, limit int, decay func(rating models.PlayerRating) models.PlayerRating) (int, error) {
+	ctx := context.Background()
+	tx, err := db.conn.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 	defer func() {
-		if err := rows.Close(); err != nil {
-			log.Printf("Error closing rows: %v", err)
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back rating decay: %v", err)
 		}
 	}()
 
-	var games []*models.Game
+	rows, err := tx.QueryContext(ctx, `
+		SELECT user_id, rating, rating_deviation, rating_volatility, rated_games, tier, division FROM user_stats
+		WHERE last_rated_at < $1 AND (rating_decayed_at IS NULL OR rating_decayed_at < $2)
+			AND user_id IN (SELECT id FROM users WHERE is_active = true)
+		ORDER BY user_id
+		LIMIT $3
+		FOR UPDATE`, inactiveSince, decayedBefore, limit)
+	if err != nil {
+		return 0, err
+	}
+	var userIDs []uuid.UUID
+	var ratings []models.PlayerRating
 	for rows.Next() {
-		game := &models.Game{}
-		err := rows.Scan(
-			&game.ID, &game.Type, &game.Status, &game.Player1ID, &game.Player2ID,
-			&game.WinnerID, &game.CurrentTurn, &game.GameState, &game.CreatedAt,
-			&game.UpdatedAt, &game.StartedAt, &game.EndedAt,
-		)
+		var userID uuid.UUID
+		var rating models.PlayerRating
+		err := rows.Scan(&userID, &rating.Rating, &rating.Deviation, &rating.Volatility,
+			&rating.RatedGames, &rating.Tier.Tier, &rating.Tier.Division)
 		if err != nil {
-			return nil, err
+			rows.Close()
+			return 0, err
 		}
-		games = append(games, game)
+		userIDs = append(userIDs, userID)
+		ratings = append(ratings, rating)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
 	}
 
-	return games, nil
-}
-
-// Move operations
-func (db *DB) CreateMove(move *models.Move) error {
-	query := `
-		INSERT INTO moves (id, game_id, player_id, move_data, created_at, is_valid)
-		VALUES ($1, $2, $3, $4, $5, $6)`
+	now := time.Now()
+	for i, userID := range userIDs {
+		old, decayed := ratings[i], decay(ratings[i])
+		_, err := tx.ExecContext(ctx, `
+			UPDATE user_stats SET rating = $2, rating_deviation = $3, tier = $4, division = $5,
+				rating_decayed_at = $6, updated_at = $6
+			WHERE user_id = $1`, userID, decayed.Rating, decayed.Deviation, decayed.Tier.Tier, decayed.Tier.Division, now)
+		if err != nil {
+			return 0, err
+		}
+		if decayed.Rating == old.Rating && decayed.Deviation == old.Deviation {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO rating_history (id, user_id, system, old_rating, new_rating, delta,
+				deviation, old_deviation, old_volatility, old_tier, old_division, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+			uuid.New(), userID, system, old.Rating, decayed.Rating, decayed.Rating-old.Rating,
+			decayed.Deviation, old.Deviation, old.Volatility, old.Tier.Tier, old.Tier.Division, now)
+		if err != nil {
+			return 0, err
+		}
+	}
 
-	move.CreatedAt = time.Now()
-	_, err := db.conn.Exec(query, move.ID, move.GameID, move.PlayerID, move.MoveData, move.CreatedAt, move.IsValid)
-	return err
+	return len(userIDs), tx.Commit()
 }
 
-func (db *DB) GetGameMoves(gameID uuid.UUID) ([]*models.Move, error) {
+// GetRatingHistory returns the user's latest rating changes, oldest first,
+// optionally only those from games of one type or made since a time.
+func (db *DB) GetRatingHistory(userID uuid.UUID, gameType string, since time.Time, limit int) ([]*models.RatingChange, error) {
 	query := `
-		SELECT id, game_id, player_id, move_data, created_at, is_valid
-		FROM moves WHERE game_id = $1 ORDER BY created_at ASC`
+		SELECT ` + ratingChangeColumns + ` FROM (
+			SELECT ` + ratingChangeColumns + ` FROM rating_history
+			WHERE user_id = $1 AND ($2 = '' OR game_type = $2) AND created_at >= $3
+			ORDER BY created_at DESC
+			LIMIT $4
+		) latest
+		ORDER BY created_at`
 
-	rows, err := db.conn.Query(query, gameID)
+	rows, err := db.reader().Query(query, userID, gameType, since, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -259,15 +760,2909 @@ func (db *DB) GetGameMoves(gameID uuid.UUID) ([]*models.Move, error) {
 		}
 	}()
 
-	var moves []*models.Move
+	var changes []*models.RatingChange
 	for rows.Next() {
-		move := &models.Move{}
-		err := rows.Scan(&move.ID, &move.GameID, &move.PlayerID, &move.MoveData, &move.CreatedAt, &move.IsValid)
+		change := &models.RatingChange{}
+		err := rows.Scan(&change.ID, &change.UserID, &change.GameID, &change.GameType, &change.System,
+			&change.OldRating, &change.NewRating, &change.Delta, &change.Deviation, &change.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
-		moves = append(moves, move)
+		changes = append(changes, change)
 	}
 
-	return moves, nil
+	return changes, rows.Err()
+}
+
+const ratingChangeColumns = `id, user_id, game_id, game_type, system, old_rating, new_rating, delta, deviation, created_at`
+
+// Game operations
+func (db *DB) CreateGame(game *models.Game) error {
+	now := time.Now()
+	game.CreatedAt = now
+	game.UpdatedAt = now
+	if game.Visibility == "" {
+		game.Visibility = models.GameVisibilityPublic
+	}
+
+	ctx := context.Background()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back game creation: %v", err)
+		}
+	}()
+
+	q := db.queries.WithTx(tx)
+	err = q.CreateGame(ctx, queries.CreateGameParams{
+		ID:           game.ID,
+		GameType:     game.Type,
+		Status:       game.Status,
+		Player1ID:    game.Player1ID,
+		Player2ID:    game.Player2ID,
+		WinnerID:     game.WinnerID,
+		CurrentTurn:  game.CurrentTurn,
+		GameState:    game.GameState,
+		CreatedAt:    game.CreatedAt,
+		UpdatedAt:    game.UpdatedAt,
+		StartedAt:    game.StartedAt,
+		EndedAt:      game.EndedAt,
+		IsPrivate:    game.Private,
+		Settings:     game.Settings,
+		StarterID:    game.StarterID,
+		InitialState: game.InitialState,
+		MoveDeadline: game.MoveDeadline,
+		SeriesID:     game.SeriesID,
+		SeriesGame:   seriesGame(game),
+		IsRated:      game.Settings.Rated,
+		Visibility:   game.Visibility,
+	})
+	if err != nil {
+		return err
+	}
+	if err := seatPlayers(ctx, q, game.ID, game.Seats()); err != nil {
+		return err
+	}
+	for _, tag := range game.Tags {
+		if err := q.TagGame(ctx, queries.TagGameParams{GameID: game.ID, Tag: tag}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetGame returns the game with its seats.
+func (db *DB) GetGame(id uuid.UUID) (*models.Game, error) {
+	game, err := gameFromRow(db.queries.GetGame(context.Background(), id))
+	if err != nil {
+		return nil, err
+	}
+	if game.Players, err = db.GetGamePlayers(id); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+func (db *DB) UpdateGame(game *models.Game) error {
+	game.UpdatedAt = time.Now()
+
+	ctx := context.Background()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back game update: %v", err)
+		}
+	}()
+
+	q := db.queries.WithTx(tx)
+	if err := q.UpdateGame(ctx, updateGameParams(game)); err != nil {
+		return err
+	}
+	// Games loaded without their seats leave them as they are
+	if game.Players != nil {
+		if err := seatPlayers(ctx, q, game.ID, game.Players); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// seatPlayers records the game's seats in game_players, freeing any seats
+// after them.
+func seatPlayers(ctx context.Context, q *queries.Queries, gameID uuid.UUID, players []models.GamePlayer) error {
+	// Freed first, so a player moving up a seat does not hold two
+	if err := q.ReleaseGameSeats(ctx, queries.ReleaseGameSeatsParams{GameID: gameID, Seats: int16(len(players))}); err != nil {
+		return err
+	}
+
+	for _, player := range players {
+		params := queries.SeatGamePlayerParams{
+			GameID: gameID,
+			UserID: player.UserID,
+			Seat:   int16(player.Seat),
+			Color:  sql.NullString{String: player.Color, Valid: player.Color != ""},
+		}
+		if player.Team != nil {
+			params.Team = sql.NullInt16{Int16: int16(*player.Team), Valid: true}
+		}
+		if err := q.SeatGamePlayer(ctx, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClaimSeat records the last of the game's seats, just taken with
+// SeatPlayer. It reports false if the game is no longer waiting for players
+// or someone else took the seat first; nothing is written then.
+func (db *DB) ClaimSeat(game *models.Game) (bool, error) {
+	seat := game.Players[len(game.Players)-1]
+	game.UpdatedAt = time.Now()
+
+	ctx := context.Background()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back seat claim: %v", err)
+		}
+	}()
+
+	q := db.queries.WithTx(tx)
+	rows, err := q.HoldWaitingGame(ctx, queries.HoldWaitingGameParams{
+		ID:        game.ID,
+		Player2ID: game.Player2ID,
+		Waiting:   models.GameStatusWaiting,
+	})
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	rows, err = q.ClaimGameSeat(ctx, queries.ClaimGameSeatParams{
+		GameID: game.ID,
+		UserID: seat.UserID,
+		Seat:   int16(seat.Seat),
+	})
+	if err != nil || rows == 0 {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// GetGamePlayers returns the game's seats in order.
+func (db *DB) GetGamePlayers(gameID uuid.UUID) ([]models.GamePlayer, error) {
+	rows, err := db.queries.ListGamePlayers(context.Background(), gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	players := make([]models.GamePlayer, len(rows))
+	for i, row := range rows {
+		players[i] = models.GamePlayer{
+			UserID: row.UserID,
+			Seat:   int(row.Seat),
+			Color:  row.Color.String,
+		}
+		if row.Team.Valid {
+			team := int(row.Team.Int16)
+			players[i].Team = &team
+		}
+	}
+	return players, nil
+}
+
+func updateGameParams(game *models.Game) queries.UpdateGameParams {
+	return queries.UpdateGameParams{
+		ID:              game.ID,
+		GameType:        game.Type,
+		Status:          game.Status,
+		Player1ID:       game.Player1ID,
+		Player2ID:       game.Player2ID,
+		WinnerID:        game.WinnerID,
+		CurrentTurn:     game.CurrentTurn,
+		GameState:       game.GameState,
+		UpdatedAt:       game.UpdatedAt,
+		StartedAt:       game.StartedAt,
+		EndedAt:         game.EndedAt,
+		StarterID:       game.StarterID,
+		InitialState:    game.InitialState,
+		DurationSeconds: durationSeconds(game),
+		MoveDeadline:    game.MoveDeadline,
+		SeriesID:        game.SeriesID,
+		SeriesGame:      seriesGame(game),
+		EndReason:       endReason(game),
+	}
+}
+
+// endReason is the stored end reason, null until the game ends.
+func endReason(game *models.Game) sql.NullString {
+	return sql.NullString{String: string(game.EndReason), Valid: game.EndReason != ""}
+}
+
+// seriesGame is the stored game number, null for games outside a series.
+func seriesGame(game *models.Game) sql.NullInt32 {
+	return sql.NullInt32{Int32: int32(game.SeriesGame), Valid: game.SeriesID != nil}
+}
+
+// GetGames returns the games listed to the viewer that match the filter,
+// with their tags.
+func (db *DB) GetGames(viewerID uuid.UUID, filter models.GameFilter, limit, offset int) ([]*models.Game, error) {
+	games, err := gamesFromRows(queries.New(db.reader()).ListPublicGames(context.Background(), queries.ListPublicGamesParams{
+		ViewerID:  viewerID,
+		Status:    sql.NullString{String: string(filter.Status), Valid: filter.Status != ""},
+		GameType:  sql.NullString{String: string(filter.Type), Valid: filter.Type != ""},
+		Rated:     sql.NullBool{Bool: filter.Rated != nil && *filter.Rated, Valid: filter.Rated != nil},
+		Tag:       sql.NullString{String: string(filter.Tag), Valid: filter.Tag != ""},
+		RowLimit:  int32(limit),
+		RowOffset: int32(offset),
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(games))
+	for i, game := range games {
+		ids[i] = game.ID
+	}
+	tags, err := db.GetGameTags(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, game := range games {
+		game.Tags = tags[game.ID]
+	}
+	return games, nil
+}
+
+// GetGameTags returns the tags of each of the games that has any.
+func (db *DB) GetGameTags(gameIDs []uuid.UUID) (map[uuid.UUID][]models.GameTag, error) {
+	tags := make(map[uuid.UUID][]models.GameTag)
+	if len(gameIDs) == 0 {
+		return tags, nil
+	}
+
+	rows, err := queries.New(db.reader()).ListGameTags(context.Background(), gameIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		tags[row.GameID] = append(tags[row.GameID], row.Tag)
+	}
+	return tags, nil
+}
+
+// SetGameTags replaces the game's tags. It reports false if the game does
+// not exist.
+func (db *DB) SetGameTags(gameID uuid.UUID, tags []models.GameTag) (bool, error) {
+	ctx := context.Background()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back game tags: %v", err)
+		}
+	}()
+
+	q := db.queries.WithTx(tx)
+	rows, err := q.TouchGame(ctx, gameID)
+	if err != nil || rows == 0 {
+		return false, err
+	}
+	if err := q.ClearGameTags(ctx, gameID); err != nil {
+		return false, err
+	}
+	for _, tag := range tags {
+		if err := q.TagGame(ctx, queries.TagGameParams{GameID: gameID, Tag: tag}); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
+
+// SetGameVisibility changes who can find and watch the game. It reports
+// false if the game does not exist.
+func (db *DB) SetGameVisibility(gameID uuid.UUID, visibility models.GameVisibility) (bool, error) {
+	rows, err := db.queries.SetGameVisibility(context.Background(), queries.SetGameVisibilityParams{
+		ID:         gameID,
+		Visibility: visibility,
+	})
+	return rows > 0, err
+}
+
+// CanWatchGame reports whether the user may watch the game: its players
+// always may, others only if it is not private and, for friends-only games,
+// they are a friend of one of its players.
+func (db *DB) CanWatchGame(game *models.Game, userID uuid.UUID) (bool, error) {
+	if game.HasPlayer(userID) {
+		return true, nil
+	}
+
+	if game.Private {
+		return false, nil
+	}
+	if game.Visibility != models.GameVisibilityFriends {
+		return true, nil
+	}
+	for _, playerID := range game.PlayerIDs() {
+		friends, err := db.AreFriends(playerID, userID)
+		if err != nil || friends {
+			return friends, err
+		}
+	}
+	return false, nil
+}
+
+// GetTimedOutGames returns in-progress games whose current turn started
+// before the cutoff, leaving out paused games.
+func (db *DB) GetTimedOutGames(cutoff time.Time) ([]*models.Game, error) {
+	return gamesFromRows(db.queries.ListTimedOutGames(context.Background(), queries.ListTimedOutGamesParams{
+		Status: models.GameStatusInProgress,
+		Cutoff: cutoff,
+	}))
+}
+
+// GetOverdueGames returns correspondence games whose move deadline has
+// passed, leaving out paused games.
+func (db *DB) GetOverdueGames(now time.Time) ([]*models.Game, error) {
+	return gamesFromRows(db.queries.ListOverdueGames(context.Background(), queries.ListOverdueGamesParams{
+		Status: models.GameStatusInProgress,
+		Now:    now,
+	}))
+}
+
+// GetAwaitingTurnGames returns in-progress, unpaused games whose player to
+// move may be due a reminder: live games whose turn started before
+// startedBefore and correspondence games whose deadline falls before
+// deadlineBefore.
+func (db *DB) GetAwaitingTurnGames(startedBefore, deadlineBefore time.Time) ([]*models.Game, error) {
+	return gamesFromRows(db.queries.ListAwaitingTurnGames(context.Background(), queries.ListAwaitingTurnGamesParams{
+		Status:         models.GameStatusInProgress,
+		StartedBefore:  startedBefore,
+		DeadlineBefore: deadlineBefore,
+	}))
+}
+
+// ClaimTurnReminder records the turn's reminder as sent, reporting false if
+// it already was, so each is sent once however many instances try. The
+// game's reminders for earlier turns are dropped.
+func (db *DB) ClaimTurnReminder(gameID uuid.UUID, turn, reminder int) (bool, error) {
+	ctx := context.Background()
+	rows, err := db.queries.ClaimTurnReminder(ctx, queries.ClaimTurnReminderParams{
+		GameID:   gameID,
+		Turn:     int32(turn),
+		Reminder: int32(reminder),
+		SentAt:   time.Now(),
+	})
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	if err := db.queries.DeleteTurnRemindersBefore(ctx, queries.DeleteTurnRemindersBeforeParams{
+		GameID: gameID,
+		Turn:   int32(turn),
+	}); err != nil {
+		log.Printf("Error deleting old turn reminders of game %s: %v", gameID, err)
+	}
+	return true, nil
+}
+
+// GetInProgressGames returns in-progress games that started before the
+// cutoff, leaving out paused and correspondence games.
+func (db *DB) GetInProgressGames(startedBefore time.Time) ([]*models.Game, error) {
+	return gamesFromRows(db.queries.ListGamesStartedBefore(context.Background(), queries.ListGamesStartedBeforeParams{
+		Status:        models.GameStatusInProgress,
+		StartedBefore: startedBefore,
+	}))
+}
+
+// CancelGame abandons a game that is still waiting for players. It reports
+// false unless the game is waiting and was created by creatorID.
+func (db *DB) CancelGame(gameID, creatorID uuid.UUID) (bool, error) {
+	rows, err := db.queries.CancelGame(context.Background(), queries.CancelGameParams{
+		ID:        gameID,
+		CreatorID: creatorID,
+		Abandoned: models.GameStatusAbandoned,
+		Waiting:   models.GameStatusWaiting,
+	})
+	return rows > 0, err
+}
+
+// EndGame stores the outcome of a game that ended outside of a move. It
+// reports false if the game was no longer in progress.
+func (db *DB) EndGame(game *models.Game) (bool, error) {
+	rows, err := db.queries.EndGame(context.Background(), queries.EndGameParams{
+		ID:              game.ID,
+		Status:          game.Status,
+		WinnerID:        game.WinnerID,
+		EndedAt:         game.EndedAt,
+		InProgress:      models.GameStatusInProgress,
+		DurationSeconds: durationSeconds(game),
+		EndReason:       endReason(game),
+	})
+	return rows > 0, err
+}
+
+// OverturnGameResult replaces the result of a completed game, previous,
+// with game's, taking the old result back out of both players' stats and
+// ratings. It returns false if the game is no longer completed.
+func (db *DB) OverturnGameResult(previous, game *models.Game) (bool, error) {
+	ctx := context.Background()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back result overturn: %v", err)
+		}
+	}()
+
+	rows, err := db.queries.WithTx(tx).OverturnGameResult(ctx, queries.OverturnGameResultParams{
+		ID:        game.ID,
+		Status:    game.Status,
+		WinnerID:  game.WinnerID,
+		EndReason: endReason(game),
+		Completed: models.GameStatusCompleted,
+	})
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	// Casual games were never counted
+	if previous.Settings.Rated && previous.Player2ID != nil {
+		for _, playerID := range []uuid.UUID{previous.Player1ID, *previous.Player2ID} {
+			won, lost := 0, 0
+			if previous.WinnerID != nil {
+				won, lost = 0, 1
+				if *previous.WinnerID == playerID {
+					won, lost = 1, 0
+				}
+			}
+			_, err := tx.ExecContext(ctx, `
+				UPDATE user_stats SET
+					games_played = GREATEST(games_played - 1, 0),
+					games_won = GREATEST(games_won - $2, 0),
+					games_lost = GREATEST(games_lost - $3, 0)
+				WHERE user_id = $1`, playerID, won, lost)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE user_stats SET rating = rating - h.delta,
+				rating_deviation = COALESCE(h.old_deviation, rating_deviation),
+				rating_volatility = COALESCE(h.old_volatility, rating_volatility),
+				rated_games = GREATEST(rated_games - 1, 0),
+				tier = COALESCE(h.old_tier, tier),
+				division = COALESCE(h.old_division, division),
+				updated_at = $2
+			FROM rating_history h
+			WHERE h.game_id = $1 AND h.user_id = user_stats.user_id`, game.ID, time.Now())
+		if err != nil {
+			return false, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM rating_history WHERE game_id = $1`, game.ID); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+// RecordGameSpectators stores how many connections watch each game and
+// raises its peak.
+func (db *DB) RecordGameSpectators(counts map[uuid.UUID]int) error {
+	for gameID, count := range counts {
+		err := db.queries.RecordGameSpectators(context.Background(), queries.RecordGameSpectatorsParams{
+			ID:         gameID,
+			Spectators: int32(count),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RequestPauseChange records a player's request to pause the game or, when
+// paused is set, to resume it. It returns false unless the game is in
+// progress, paused as given by its players rather than an admin, and has no
+// request pending.
+func (db *DB) RequestPauseChange(gameID, playerID uuid.UUID, paused bool) (bool, error) {
+	rows, err := db.queries.RequestPauseChange(context.Background(), queries.RequestPauseChangeParams{
+		ID:          gameID,
+		RequestedBy: &playerID,
+		InProgress:  models.GameStatusInProgress,
+		Paused:      paused,
+	})
+	return rows > 0, err
+}
+
+// CancelPauseRequest withdraws or declines a pending pause or resume
+// request. It returns false if there was none.
+func (db *DB) CancelPauseRequest(gameID uuid.UUID) (bool, error) {
+	rows, err := db.queries.CancelPauseRequest(context.Background(), queries.CancelPauseRequestParams{
+		ID:         gameID,
+		InProgress: models.GameStatusInProgress,
+	})
+	return rows > 0, err
+}
+
+// AcceptPause pauses the game at the request of requestedBy. It returns
+// false unless that player's pause request is still pending.
+func (db *DB) AcceptPause(gameID, requestedBy uuid.UUID) (bool, error) {
+	now := time.Now()
+	rows, err := db.queries.AcceptPause(context.Background(), queries.AcceptPauseParams{
+		ID:          gameID,
+		PausedAt:    &now,
+		RequestedBy: &requestedBy,
+		InProgress:  models.GameStatusInProgress,
+	})
+	return rows > 0, err
+}
+
+// ForcePause pauses an in-progress game for an admin. It returns false if
+// the game is not in progress or an admin already paused it.
+func (db *DB) ForcePause(gameID, adminID uuid.UUID) (bool, error) {
+	now := time.Now()
+	rows, err := db.queries.ForcePause(context.Background(), queries.ForcePauseParams{
+		ID:         gameID,
+		PausedAt:   &now,
+		PausedBy:   &adminID,
+		InProgress: models.GameStatusInProgress,
+	})
+	return rows > 0, err
+}
+
+// ResumeGame ends the game's pause and moves the turn clock and any move
+// deadline on by its length, so the player to move has the time left when
+// it was paused. A nil requestedBy resumes for an admin; otherwise that
+// player's resume request must still be pending. It returns false if the
+// game was resumed or paused again in the meantime.
+func (db *DB) ResumeGame(game *models.Game, requestedBy *uuid.UUID) (bool, error) {
+	if game.PausedAt == nil || game.StartedAt == nil {
+		return false, nil
+	}
+
+	turnStartedAt := game.StartedAt
+	if game.TurnStartedAt != nil {
+		turnStartedAt = game.TurnStartedAt
+	}
+	now := time.Now()
+	shifted := now.Add(-game.PausedAt.Sub(*turnStartedAt))
+	var deadline *time.Time
+	if game.MoveDeadline != nil {
+		moved := game.MoveDeadline.Add(now.Sub(*game.PausedAt))
+		deadline = &moved
+	}
+
+	rows, err := db.queries.ResumeGame(context.Background(), queries.ResumeGameParams{
+		ID:            game.ID,
+		TurnStartedAt: &shifted,
+		MoveDeadline:  deadline,
+		PausedAt:      game.PausedAt,
+		RequestedBy:   requestedBy,
+		InProgress:    models.GameStatusInProgress,
+	})
+	return rows > 0, err
+}
+
+// durationSeconds is the stored duration of the game, null until it ends.
+func durationSeconds(game *models.Game) sql.NullInt32 {
+	if game.EndedAt == nil || game.StartedAt == nil {
+		game.DurationSeconds = nil
+		return sql.NullInt32{}
+	}
+	seconds := int(game.Duration().Seconds())
+	game.DurationSeconds = &seconds
+	return sql.NullInt32{Int32: int32(seconds), Valid: true}
+}
+
+// GetLiveGames returns in-progress games listed to the viewer, newest first,
+// leaving out games with a player the viewer has blocked or been blocked
+// by. Both players' ratings must fall within minRating and maxRating; zero
+// means no bound. Popular lists the most watched games first rather than
+// the newest. An empty tag matches every game.
+func (db *DB) GetLiveGames(viewerID uuid.UUID, gameType string, tag models.GameTag, minRating, maxRating int, popular bool, limit, offset int) ([]*models.LiveGame, error) {
+	query := `
+		SELECT g.id, g.game_type, g.started_at, g.move_count, g.last_move_at,
+			u1.id, ` + displayName("u1") + `, u1.avatar_url, COALESCE(s1.rating, 1000),
+			u2.id, ` + displayName("u2") + `, u2.avatar_url, COALESCE(s2.rating, 1000)
+		FROM games g
+		JOIN users u1 ON u1.id = g.player1_id
+		JOIN users u2 ON u2.id = g.player2_id
+		LEFT JOIN user_stats s1 ON s1.user_id = g.player1_id
+		LEFT JOIN user_stats s2 ON s2.user_id = g.player2_id
+		WHERE g.status = $1 AND g.is_private = false
+		AND (g.visibility = 'public' OR (g.visibility = 'friends' AND EXISTS (
+			SELECT 1 FROM game_players p
+			WHERE p.game_id = g.id AND (p.user_id = $2 OR EXISTS (
+				SELECT 1 FROM friendships f
+				WHERE f.status = 'accepted'
+				AND ((f.user_id = $2 AND f.friend_id = p.user_id)
+				OR (f.friend_id = $2 AND f.user_id = p.user_id))
+			))
+		)))
+		AND NOT EXISTS (
+			SELECT 1 FROM game_players p
+			JOIN user_blocks b ON (b.blocker_id = $2 AND b.blocked_id = p.user_id)
+				OR (b.blocked_id = $2 AND b.blocker_id = p.user_id)
+			WHERE p.game_id = g.id
+		)`
+
+	args := []interface{}{models.GameStatusInProgress, viewerID}
+	argIndex := 3
+
+	if gameType != "" {
+		query += fmt.Sprintf(" AND g.game_type = $%d", argIndex)
+		args = append(args, gameType)
+		argIndex++
+	}
+
+	if tag != "" {
+		query += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM game_tags t WHERE t.game_id = g.id AND t.tag = $%d)", argIndex)
+		args = append(args, tag)
+		argIndex++
+	}
+
+	if minRating > 0 {
+		query += fmt.Sprintf(" AND LEAST(COALESCE(s1.rating, 1000), COALESCE(s2.rating, 1000)) >= $%d", argIndex)
+		args = append(args, minRating)
+		argIndex++
+	}
+
+	if maxRating > 0 {
+		query += fmt.Sprintf(" AND GREATEST(COALESCE(s1.rating, 1000), COALESCE(s2.rating, 1000)) <= $%d", argIndex)
+		args = append(args, maxRating)
+		argIndex++
+	}
+
+	if popular {
+		query += " ORDER BY g.spectators DESC, g.started_at DESC"
+	} else {
+		query += " ORDER BY g.started_at DESC"
+	}
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, limit, offset)
+
+	rows, err := db.reader().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var games []*models.LiveGame
+	for rows.Next() {
+		game := &models.LiveGame{Players: make([]models.LivePlayer, 2)}
+		err := rows.Scan(&game.ID, &game.GameType, &game.StartedAt, &game.MoveCount, &game.LastMoveAt,
+			&game.Players[0].UserID, &game.Players[0].Username, &game.Players[0].AvatarURL, &game.Players[0].Rating,
+			&game.Players[1].UserID, &game.Players[1].Username, &game.Players[1].AvatarURL, &game.Players[1].Rating,
+		)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, game)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(games))
+	for i, game := range games {
+		ids[i] = game.ID
+	}
+	tags, err := db.GetGameTags(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, game := range games {
+		game.Tags = tags[game.ID]
+	}
+	return games, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func gameFromRow(row queries.Game, err error) (*models.Game, error) {
+	if err != nil {
+		return nil, err
+	}
+	// The column is authoritative: games from before it have rated unset
+	// in their settings
+	settings := row.Settings
+	settings.Rated = row.IsRated
+	return &models.Game{
+		ID:               row.ID,
+		Type:             row.GameType,
+		Status:           row.Status,
+		Player1ID:        row.Player1ID,
+		Player2ID:        row.Player2ID,
+		WinnerID:         row.WinnerID,
+		CurrentTurn:      row.CurrentTurn,
+		GameState:        row.GameState,
+		CreatedAt:        row.CreatedAt,
+		UpdatedAt:        row.UpdatedAt,
+		StartedAt:        row.StartedAt,
+		EndedAt:          row.EndedAt,
+		Private:          row.IsPrivate,
+		Visibility:       row.Visibility,
+		Settings:         settings,
+		StarterID:        row.StarterID,
+		InitialState:     row.InitialState,
+		MoveCount:        int(row.MoveCount),
+		LastMoveAt:       row.LastMoveAt,
+		DurationSeconds:  intFromNull(row.DurationSeconds),
+		Spectators:       int(row.Spectators),
+		PeakSpectators:   int(row.PeakSpectators),
+		PausedAt:         row.PausedAt,
+		PausedBy:         row.PausedBy,
+		PausedByAdmin:    row.PausedBy != nil,
+		PauseRequestedBy: row.PauseRequestedBy,
+		TurnStartedAt:    row.TurnStartedAt,
+		MoveDeadline:     row.MoveDeadline,
+		SeriesID:         row.SeriesID,
+		SeriesGame:       int(row.SeriesGame.Int32),
+		EndReason:        models.EndReason(row.EndReason.String),
+	}, nil
+}
+
+func intFromNull(value sql.NullInt32) *int {
+	if !value.Valid {
+		return nil
+	}
+	n := int(value.Int32)
+	return &n
+}
+
+func gamesFromRows(rows []queries.Game, err error) ([]*models.Game, error) {
+	if err != nil {
+		return nil, err
+	}
+	var games []*models.Game
+	for _, row := range rows {
+		game, _ := gameFromRow(row, nil)
+		games = append(games, game)
+	}
+	return games, nil
+}
+
+// GetLastStarter returns who moved first in the most recent game of a type
+// between two players, or nil if they have not played one.
+func (db *DB) GetLastStarter(gameType models.GameType, playerA, playerB uuid.UUID) (*uuid.UUID, error) {
+	query := `
+		SELECT starter_id FROM games
+		WHERE game_type = $1 AND starter_id IS NOT NULL
+		AND ((player1_id = $2 AND player2_id = $3) OR (player1_id = $3 AND player2_id = $2))
+		ORDER BY started_at DESC LIMIT 1`
+
+	var starterID uuid.UUID
+	err := db.conn.QueryRow(query, gameType, playerA, playerB).Scan(&starterID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &starterID, nil
+}
+
+// GetGameTypeStats returns the user's record in each game type they have
+// completed a game of, with win rate, streaks and average duration.
+func (db *DB) GetGameTypeStats(userID uuid.UUID) ([]*models.GameTypeStats, error) {
+	stats, _, err := db.queryGameTypeStats(userID, nil)
+	return stats, err
+}
+
+// GetHeadToHead returns the user's record against an opponent, in total
+// and per game type.
+func (db *DB) GetHeadToHead(userID, opponentID uuid.UUID) (*models.HeadToHead, error) {
+	stats, lastPlayedAt, err := db.queryGameTypeStats(userID, &opponentID)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.HeadToHead{OpponentID: opponentID, LastPlayedAt: lastPlayedAt, ByGameType: stats}
+	for _, entry := range stats {
+		record.GamesPlayed += entry.GamesPlayed
+		record.GamesWon += entry.GamesWon
+		record.GamesLost += entry.GamesLost
+		record.GamesDrawn += entry.GamesDrawn
+	}
+	if record.GamesPlayed > 0 {
+		record.WinRate = float64(record.GamesWon) / float64(record.GamesPlayed)
+	}
+	if record.ByGameType == nil {
+		record.ByGameType = []*models.GameTypeStats{}
+	}
+	return record, nil
+}
+
+// queryGameTypeStats aggregates the user's completed rated games per game type,
+// against one opponent if opponentID is set, also returning when the latest
+// of them ended. Win streaks are runs of consecutive wins in the order the
+// games ended; a draw ends a streak like a loss.
+func (db *DB) queryGameTypeStats(userID uuid.UUID, opponentID *uuid.UUID) ([]*models.GameTypeStats, *time.Time, error) {
+	query := `
+		WITH results AS (
+			SELECT game_type, winner_id, duration_seconds, ended_at, COALESCE(winner_id = $1, false) AS won
+			FROM games
+			WHERE status = 'completed' AND is_rated AND (player1_id = $1 OR player2_id = $1)
+			AND ($2::uuid IS NULL OR player1_id = $2 OR player2_id = $2)
+		),
+		runs AS (
+			SELECT game_type, won,
+				ROW_NUMBER() OVER (PARTITION BY game_type ORDER BY ended_at)
+				- ROW_NUMBER() OVER (PARTITION BY game_type, won ORDER BY ended_at) AS run
+			FROM results
+		),
+		best AS (
+			SELECT game_type, MAX(length) AS streak
+			FROM (SELECT game_type, COUNT(*) AS length FROM runs WHERE won GROUP BY game_type, run) streaks
+			GROUP BY game_type
+		),
+		last_non_win AS (
+			SELECT game_type, MAX(ended_at) AS ended_at FROM results WHERE NOT won GROUP BY game_type
+		)
+		SELECT r.game_type, COUNT(*),
+			COUNT(*) FILTER (WHERE r.winner_id = $1),
+			COUNT(*) FILTER (WHERE r.winner_id <> $1),
+			COUNT(*) FILTER (WHERE r.winner_id IS NULL),
+			COUNT(*) FILTER (WHERE r.won AND r.ended_at > COALESCE(l.ended_at, '-infinity'::timestamp)),
+			COALESCE(MAX(b.streak), 0),
+			COALESCE(AVG(r.duration_seconds), 0)::float8,
+			MAX(r.ended_at)
+		FROM results r
+		LEFT JOIN best b ON b.game_type = r.game_type
+		LEFT JOIN last_non_win l ON l.game_type = r.game_type
+		GROUP BY r.game_type
+		ORDER BY r.game_type`
+
+	rows, err := db.reader().Query(query, userID, opponentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var stats []*models.GameTypeStats
+	var lastEndedAt *time.Time
+	for rows.Next() {
+		entry := &models.GameTypeStats{}
+		var averageDuration float64
+		var endedAt *time.Time
+		err := rows.Scan(&entry.GameType, &entry.GamesPlayed, &entry.GamesWon, &entry.GamesLost, &entry.GamesDrawn,
+			&entry.CurrentWinStreak, &entry.BestWinStreak, &averageDuration, &endedAt)
+		if err != nil {
+			return nil, nil, err
+		}
+		entry.WinRate = float64(entry.GamesWon) / float64(entry.GamesPlayed)
+		entry.AverageDurationSeconds = int(averageDuration)
+		if endedAt != nil && (lastEndedAt == nil || endedAt.After(*lastEndedAt)) {
+			lastEndedAt = endedAt
+		}
+		stats = append(stats, entry)
+	}
+
+	return stats, lastEndedAt, rows.Err()
+}
+
+// opponentJoin joins the user's seat me to their opponent o: the first
+// other seat, which is the only other one in two-player games.
+const opponentJoin = `
+		LEFT JOIN game_players op ON op.game_id = g.id AND op.seat = CASE WHEN me.seat = 1 THEN 2 ELSE 1 END
+		LEFT JOIN users o ON o.id = op.user_id`
+
+// GetRecentGames returns the user's latest completed games, leaving out
+// private ones.
+func (db *DB) GetRecentGames(userID uuid.UUID, limit int) ([]*models.GameSummary, error) {
+	query := `
+		SELECT g.id, g.game_type, g.winner_id, g.end_reason, g.ended_at, o.id, COALESCE(` + displayName("o") + `, '')
+		FROM games g
+		JOIN game_players me ON me.game_id = g.id AND me.user_id = $1
+		` + opponentJoin + `
+		WHERE g.status = 'completed' AND g.is_private = false
+		ORDER BY g.ended_at DESC NULLS LAST
+		LIMIT $2`
+
+	rows, err := db.reader().Query(query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var games []*models.GameSummary
+	for rows.Next() {
+		game := &models.GameSummary{}
+		var winnerID *uuid.UUID
+		var endReason sql.NullString
+		err := rows.Scan(&game.ID, &game.GameType, &winnerID, &endReason, &game.EndedAt, &game.OpponentID, &game.OpponentUsername)
+		if err != nil {
+			return nil, err
+		}
+		game.EndReason = models.EndReason(endReason.String)
+
+		switch {
+		case winnerID == nil:
+			game.Result = models.GameResultDraw
+		case *winnerID == userID:
+			game.Result = models.GameResultWin
+		default:
+			game.Result = models.GameResultLoss
+		}
+		games = append(games, game)
+	}
+
+	return games, rows.Err()
+}
+
+// GetUserGames returns the user's games in the given statuses, and with the
+// tag unless it is empty. Games where it is their turn come first, then the
+// most recently updated or ended.
+func (db *DB) GetUserGames(userID uuid.UUID, statuses []models.GameStatus, tag models.GameTag, limit int) ([]*models.UserGame, error) {
+	query := `
+		SELECT g.id, g.game_type, g.status, g.is_private, g.current_turn, g.winner_id, g.end_reason,
+			g.created_at, g.updated_at, g.started_at, g.ended_at, g.move_count, g.last_move_at, g.duration_seconds, o.id, ` + displayName("o") + `, o.avatar_url
+		FROM games g
+		JOIN game_players me ON me.game_id = g.id AND me.user_id = $1
+		` + opponentJoin + `
+		WHERE g.status = ANY($2)
+		AND ($4 = '' OR EXISTS (SELECT 1 FROM game_tags t WHERE t.game_id = g.id AND t.tag = $4))
+		ORDER BY g.current_turn IS NOT DISTINCT FROM $1 DESC, COALESCE(g.ended_at, g.updated_at) DESC
+		LIMIT $3`
+
+	names := make([]string, len(statuses))
+	for i, status := range statuses {
+		names[i] = string(status)
+	}
+
+	rows, err := db.conn.Query(query, userID, pq.Array(names), limit, tag)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var games []*models.UserGame
+	for rows.Next() {
+		game := &models.UserGame{}
+		var opponentID *uuid.UUID
+		var opponentUsername, opponentAvatar, endReason sql.NullString
+		err := rows.Scan(&game.ID, &game.GameType, &game.Status, &game.Private, &game.CurrentTurn, &game.WinnerID, &endReason,
+			&game.CreatedAt, &game.UpdatedAt, &game.StartedAt, &game.EndedAt, &game.MoveCount, &game.LastMoveAt, &game.DurationSeconds,
+			&opponentID, &opponentUsername, &opponentAvatar)
+		if err != nil {
+			return nil, err
+		}
+
+		game.EndReason = models.EndReason(endReason.String)
+
+		if opponentID != nil {
+			game.Opponent = &models.GameOpponent{
+				UserID:    *opponentID,
+				Username:  opponentUsername.String,
+				AvatarURL: opponentAvatar.String,
+			}
+		}
+		game.YourTurn = game.Status == models.GameStatusInProgress && game.CurrentTurn != nil && *game.CurrentTurn == userID
+		if game.Status == models.GameStatusCompleted {
+			switch {
+			case game.WinnerID == nil:
+				game.Result = models.GameResultDraw
+			case *game.WinnerID == userID:
+				game.Result = models.GameResultWin
+			default:
+				game.Result = models.GameResultLoss
+			}
+		}
+		games = append(games, game)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(games))
+	for i, game := range games {
+		ids[i] = game.ID
+	}
+	tags, err := db.GetGameTags(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, game := range games {
+		game.Tags = tags[game.ID]
+	}
+	return games, nil
+}
+
+// Move operations
+
+// ErrGameChanged is returned by RecordMove when the game ended or another
+// move was recorded since it was read.
+var ErrGameChanged = errors.New("game changed")
+
+// RecordMove stores the game as updated by a move along with the move's
+// history entry, counting it towards the game's moves. The game's
+// MoveCount must be as read, before the move.
+func (db *DB) RecordMove(game *models.Game, move *models.Move) error {
+	if move.Kind == "" {
+		move.Kind = models.MoveKindMove
+	}
+	now := time.Now()
+	game.UpdatedAt = now
+	move.CreatedAt = now
+
+	ctx := context.Background()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back move: %v", err)
+		}
+	}()
+
+	// The next move's deadline starts now, if moves have one
+	game.MoveDeadline = nil
+	if game.Status == models.GameStatusInProgress {
+		game.MoveDeadline = game.Settings.MoveDeadline(now)
+	}
+
+	// Counting the move first locks the game row, so a racing move or
+	// timeout waits and then finds the game changed
+	q := db.queries.WithTx(tx)
+	count, err := q.CountMove(ctx, queries.CountMoveParams{
+		ID:         game.ID,
+		MovedAt:    &now,
+		MoveCount:  int32(game.MoveCount),
+		InProgress: models.GameStatusInProgress,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrGameChanged
+	}
+	if err != nil {
+		return err
+	}
+	if err := q.UpdateGame(ctx, updateGameParams(game)); err != nil {
+		return err
+	}
+	err = q.CreateMove(ctx, queries.CreateMoveParams{
+		ID:        move.ID,
+		GameID:    move.GameID,
+		PlayerID:  move.PlayerID,
+		MoveData:  move.MoveData,
+		CreatedAt: move.CreatedAt,
+		IsValid:   move.IsValid,
+		Kind:      move.Kind,
+	})
+	if err != nil {
+		return err
+	}
+	if db.snapshotInterval > 0 && int(count)%db.snapshotInterval == 0 {
+		err = q.CreateGameSnapshot(ctx, queries.CreateGameSnapshotParams{
+			GameID:     game.ID,
+			MoveNumber: count,
+			State:      game.GameState,
+			CreatedAt:  now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	game.MoveCount = int(count)
+	game.LastMoveAt = &now
+	return nil
+}
+
+func (db *DB) GetGameMoves(gameID uuid.UUID) ([]*models.Move, error) {
+	rows, err := db.queries.ListGameMoves(context.Background(), gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	var moves []*models.Move
+	for _, row := range rows {
+		moves = append(moves, &models.Move{
+			ID:        row.ID,
+			GameID:    row.GameID,
+			PlayerID:  row.PlayerID,
+			MoveData:  row.MoveData,
+			CreatedAt: row.CreatedAt,
+			IsValid:   row.IsValid,
+			Kind:      row.Kind,
+		})
+	}
+	return moves, nil
+}
+
+// SetSnapshotInterval makes RecordMove snapshot a game's state every given
+// number of moves. It must be called before moves are recorded.
+func (db *DB) SetSnapshotInterval(moves int) {
+	db.snapshotInterval = moves
+}
+
+// GetGameSnapshot returns the latest snapshot of a game taken no later than
+// the given move, or sql.ErrNoRows if there is none.
+func (db *DB) GetGameSnapshot(gameID uuid.UUID, atOrBefore int) (*models.GameSnapshot, error) {
+	row, err := db.queries.GetGameSnapshot(context.Background(), queries.GetGameSnapshotParams{
+		GameID:     gameID,
+		MoveNumber: int32(atOrBefore),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshotFromRow(row), nil
+}
+
+// GetGameSnapshots returns a game's snapshots, earliest first.
+func (db *DB) GetGameSnapshots(gameID uuid.UUID) ([]*models.GameSnapshot, error) {
+	rows, err := db.queries.ListGameSnapshots(context.Background(), gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []*models.GameSnapshot
+	for _, row := range rows {
+		snapshots = append(snapshots, snapshotFromRow(row))
+	}
+	return snapshots, nil
+}
+
+func snapshotFromRow(row queries.GameSnapshot) *models.GameSnapshot {
+	return &models.GameSnapshot{
+		GameID:     row.GameID,
+		MoveNumber: int(row.MoveNumber),
+		State:      row.State,
+		CreatedAt:  row.CreatedAt,
+	}
+}
+
+// Series operations
+
+func (db *DB) CreateSeries(series *models.Series) error {
+	series.CreatedAt = time.Now()
+	series.Status = models.SeriesStatusInProgress
+	return db.queries.CreateSeries(context.Background(), queries.CreateSeriesParams{
+		ID:        series.ID,
+		GameType:  series.Type,
+		Player1ID: series.Player1ID,
+		Player2ID: series.Player2ID,
+		BestOf:    int32(series.BestOf),
+		Settings:  series.Settings,
+		Status:    series.Status,
+		CreatedAt: series.CreatedAt,
+	})
+}
+
+// GetSeries returns the series with its games in order.
+func (db *DB) GetSeries(id uuid.UUID) (*models.Series, error) {
+	ctx := context.Background()
+	row, err := db.queries.GetSeries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	series := seriesFromRow(row)
+
+	series.Games, err = gamesFromRows(db.queries.ListSeriesGames(ctx, &id))
+	if err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// RecordSeriesGame adds a finished game of the series to its score and
+// returns the updated series. It returns sql.ErrNoRows if the series has
+// ended or the game was already counted.
+func (db *DB) RecordSeriesGame(game *models.Game) (*models.Series, error) {
+	params := queries.RecordSeriesGameParams{
+		ID:          *game.SeriesID,
+		InProgress:  models.SeriesStatusInProgress,
+		GamesPlayed: int32(game.SeriesGame - 1),
+	}
+	switch {
+	case game.WinnerID == nil:
+		params.Draws = 1
+	case *game.WinnerID == game.Creator():
+		params.Player1Wins = 1
+	default:
+		params.Player2Wins = 1
+	}
+
+	row, err := db.queries.RecordSeriesGame(context.Background(), params)
+	if err != nil {
+		return nil, err
+	}
+	return seriesFromRow(row), nil
+}
+
+// EndSeries stores the series' final status and winner. It returns false
+// if the series had already ended.
+func (db *DB) EndSeries(series *models.Series) (bool, error) {
+	rows, err := db.queries.EndSeries(context.Background(), queries.EndSeriesParams{
+		ID:         series.ID,
+		Status:     series.Status,
+		WinnerID:   series.WinnerID,
+		EndedAt:    series.EndedAt,
+		InProgress: models.SeriesStatusInProgress,
+	})
+	return rows > 0, err
+}
+
+func seriesFromRow(row queries.Series) *models.Series {
+	return &models.Series{
+		ID:          row.ID,
+		Type:        row.GameType,
+		Player1ID:   row.Player1ID,
+		Player2ID:   row.Player2ID,
+		BestOf:      int(row.BestOf),
+		Settings:    row.Settings,
+		Status:      row.Status,
+		GamesPlayed: int(row.GamesPlayed),
+		Player1Wins: int(row.Player1Wins),
+		Player2Wins: int(row.Player2Wins),
+		Draws:       int(row.Draws),
+		WinnerID:    row.WinnerID,
+		CreatedAt:   row.CreatedAt,
+		EndedAt:     row.EndedAt,
+	}
+}
+
+// Bot operations
+
+// GetBots returns the chess bots, one per level.
+func (db *DB) GetBots() ([]*models.Bot, error) {
+	rows, err := db.queries.ListBots(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	bots := make([]*models.Bot, len(rows))
+	for i, row := range rows {
+		bots[i] = &models.Bot{Level: row.Level, UserID: row.UserID, Username: row.Username}
+	}
+	return bots, nil
+}
+
+// GetBotTurnGames returns in-progress, unpaused games in which a bot is to
+// move.
+func (db *DB) GetBotTurnGames() ([]*models.Game, error) {
+	return gamesFromRows(db.queries.ListBotTurnGames(context.Background(), models.GameStatusInProgress))
+}
+
+// ClaimBotMove claims the bot's move of the turn, reporting false if
+// another instance claimed it within timeout, so each is made once however
+// many instances try. A claim left by an instance that failed to move is
+// taken over once it is older than timeout. The game's claims of earlier
+// turns are dropped.
+func (db *DB) ClaimBotMove(gameID uuid.UUID, turn int, timeout time.Duration) (bool, error) {
+	ctx := context.Background()
+	now := time.Now()
+	rows, err := db.queries.ClaimBotMove(ctx, queries.ClaimBotMoveParams{
+		GameID:      gameID,
+		Turn:        int32(turn),
+		MovedAt:     now,
+		StaleBefore: now.Add(-timeout),
+	})
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	if err := db.queries.DeleteBotMovesBefore(ctx, queries.DeleteBotMovesBeforeParams{
+		GameID: gameID,
+		Turn:   int32(turn),
+	}); err != nil {
+		log.Printf("Error deleting old bot moves of game %s: %v", gameID, err)
+	}
+	return true, nil
+}
+
+// ReleaseBotMove drops the claim on the bot's move of the turn, so it is
+// tried again.
+func (db *DB) ReleaseBotMove(gameID uuid.UUID, turn int) error {
+	return db.queries.ReleaseBotMove(context.Background(), queries.ReleaseBotMoveParams{
+		GameID: gameID,
+		Turn:   int32(turn),
+	})
+}
+
+// Game type operations
+
+// GetDisabledGameTypes returns the game types turned off for new games.
+func (db *DB) GetDisabledGameTypes() ([]models.GameType, error) {
+	rows, err := db.conn.Query(`SELECT game_type FROM disabled_game_types`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []models.GameType
+	for rows.Next() {
+		var gameType models.GameType
+		if err := rows.Scan(&gameType); err != nil {
+			return nil, err
+		}
+		types = append(types, gameType)
+	}
+	return types, rows.Err()
+}
+
+// SetGameTypeEnabled turns a game type on or off for new games.
+func (db *DB) SetGameTypeEnabled(gameType models.GameType, enabled bool) error {
+	if enabled {
+		_, err := db.conn.Exec(`DELETE FROM disabled_game_types WHERE game_type = $1`, gameType)
+		return err
+	}
+	_, err := db.conn.Exec(`INSERT INTO disabled_game_types (game_type) VALUES ($1) ON CONFLICT (game_type) DO NOTHING`, gameType)
+	return err
+}
+
+// Friendship operations
+
+// CreateFriendRequest records a pending request from userID to friendID. It
+// returns false if the pair already has a friendship or pending request.
+func (db *DB) CreateFriendRequest(userID, friendID uuid.UUID) (bool, error) {
+	query := `
+		INSERT INTO friendships (user_id, friend_id, status, created_at)
+		VALUES ($1, $2, 'pending', $3)
+		ON CONFLICT DO NOTHING`
+
+	result, err := db.conn.Exec(query, userID, friendID, time.Now())
+	if err != nil {
+		return false, err
+	}
+	created, err := result.RowsAffected()
+	return created == 1, err
+}
+
+// GetFriendship returns the friendship between two users, whichever of them
+// sent the request.
+func (db *DB) GetFriendship(userID, otherUserID uuid.UUID) (*models.Friendship, error) {
+	query := `
+		SELECT user_id, friend_id, status, created_at FROM friendships
+		WHERE (user_id = $1 AND friend_id = $2) OR (user_id = $2 AND friend_id = $1)`
+
+	friendship := &models.Friendship{}
+	err := db.conn.QueryRow(query, userID, otherUserID).Scan(
+		&friendship.UserID, &friendship.FriendID, &friendship.Status, &friendship.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return friendship, nil
+}
+
+// AcceptFriendRequest accepts the pending request from requesterID to
+// userID. It returns false if there is no such request.
+func (db *DB) AcceptFriendRequest(requesterID, userID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE friendships SET status = 'accepted', created_at = $3
+		WHERE user_id = $1 AND friend_id = $2 AND status = 'pending'`
+
+	result, err := db.conn.Exec(query, requesterID, userID, time.Now())
+	if err != nil {
+		return false, err
+	}
+	accepted, err := result.RowsAffected()
+	return accepted == 1, err
+}
+
+// DeleteFriendRequest removes the pending request from requesterID to
+// userID. It returns false if there is no such request.
+func (db *DB) DeleteFriendRequest(requesterID, userID uuid.UUID) (bool, error) {
+	query := `DELETE FROM friendships WHERE user_id = $1 AND friend_id = $2 AND status = 'pending'`
+
+	result, err := db.conn.Exec(query, requesterID, userID)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted == 1, err
+}
+
+// DeleteFriendship removes the friendship or pending request between two
+// users. It returns false if there was none.
+func (db *DB) DeleteFriendship(userID, otherUserID uuid.UUID) (bool, error) {
+	query := `
+		DELETE FROM friendships
+		WHERE (user_id = $1 AND friend_id = $2) OR (user_id = $2 AND friend_id = $1)`
+
+	result, err := db.conn.Exec(query, userID, otherUserID)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+func (db *DB) AreFriends(userID, otherUserID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM friendships
+			WHERE status = 'accepted'
+			AND ((user_id = $1 AND friend_id = $2) OR (user_id = $2 AND friend_id = $1))
+		)`
+
+	var friends bool
+	err := db.conn.QueryRow(query, userID, otherUserID).Scan(&friends)
+	return friends, err
+}
+
+// GetFriends returns the user's accepted friends by username.
+func (db *DB) GetFriends(userID uuid.UUID) ([]*models.Friend, error) {
+	query := `
+		SELECT u.id, u.username, u.avatar_url, f.created_at
+		FROM friendships f
+		JOIN users u ON u.id = CASE WHEN f.user_id = $1 THEN f.friend_id ELSE f.user_id END
+		WHERE f.status = 'accepted' AND (f.user_id = $1 OR f.friend_id = $1)
+		ORDER BY u.username`
+
+	return db.queryFriends(query, userID)
+}
+
+// GetFriendRequests returns the pending requests sent to the user, newest
+// first.
+func (db *DB) GetFriendRequests(userID uuid.UUID) ([]*models.Friend, error) {
+	query := `
+		SELECT u.id, u.username, u.avatar_url, f.created_at
+		FROM friendships f
+		JOIN users u ON u.id = f.user_id
+		WHERE f.status = 'pending' AND f.friend_id = $1
+		ORDER BY f.created_at DESC`
+
+	return db.queryFriends(query, userID)
+}
+
+func (db *DB) queryFriends(query string, args ...interface{}) ([]*models.Friend, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var friends []*models.Friend
+	for rows.Next() {
+		friend := &models.Friend{}
+		if err := rows.Scan(&friend.UserID, &friend.Username, &friend.AvatarURL, &friend.Since); err != nil {
+			return nil, err
+		}
+		friends = append(friends, friend)
+	}
+
+	return friends, rows.Err()
+}
+
+// Block operations
+func (db *DB) IsBlocked(userID, otherUserID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_blocks
+			WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+		)`
+
+	var blocked bool
+	err := db.conn.QueryRow(query, userID, otherUserID).Scan(&blocked)
+	return blocked, err
+}
+
+// GetBlockRelations returns every user the given user has blocked or been
+// blocked by.
+func (db *DB) GetBlockRelations(userID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT blocked_id FROM user_blocks WHERE blocker_id = $1
+		UNION
+		SELECT blocker_id FROM user_blocks WHERE blocked_id = $1`
+
+	rows, err := db.conn.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var otherID uuid.UUID
+		if err := rows.Scan(&otherID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, otherID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+// BlockUser records that blockerID blocked blockedID. Blocking twice is a
+// no-op.
+func (db *DB) BlockUser(blockerID, blockedID uuid.UUID) error {
+	query := `
+		INSERT INTO user_blocks (blocker_id, blocked_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING`
+
+	_, err := db.conn.Exec(query, blockerID, blockedID, time.Now())
+	return err
+}
+
+// UnblockUser lifts blockerID's block on blockedID. It returns false if
+// there was none.
+func (db *DB) UnblockUser(blockerID, blockedID uuid.UUID) (bool, error) {
+	query := `DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`
+
+	result, err := db.conn.Exec(query, blockerID, blockedID)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted == 1, err
+}
+
+// Report operations
+func (db *DB) CreateReport(report *models.Report) error {
+	query := `
+		INSERT INTO reports (id, reporter_id, reported_id, reason, details, game_id, chat_message_id, status, context, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	report.CreatedAt = time.Now()
+	if report.Status == "" {
+		report.Status = models.ReportStatusOpen
+	}
+
+	_, err := db.conn.Exec(query, report.ID, report.ReporterID, report.ReportedID, report.Reason, report.Details,
+		report.GameID, report.ChatMessageID, report.Status, report.Context, report.CreatedAt)
+	return err
+}
+
+func (db *DB) GetReport(id uuid.UUID) (*models.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE id = $1`
+
+	return scanReport(db.conn.QueryRow(query, id))
+}
+
+// GetReports returns reports with the given status, oldest first.
+func (db *DB) GetReports(status models.ReportStatus, limit, offset int) ([]*models.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE status = $1 ORDER BY created_at LIMIT $2 OFFSET $3`
+
+	rows, err := db.conn.Query(query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var reports []*models.Report
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+// ResolveReport closes an open report. It returns false if the report is
+// not open.
+func (db *DB) ResolveReport(report *models.Report) (bool, error) {
+	query := `
+		UPDATE reports SET status = $2, resolved_at = $3, resolved_by = $4, resolution_note = $5
+		WHERE id = $1 AND status = 'open'`
+
+	now := time.Now()
+	report.ResolvedAt = &now
+	result, err := db.conn.Exec(query, report.ID, report.Status, report.ResolvedAt, report.ResolvedBy, report.ResolutionNote)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	return updated == 1, err
+}
+
+const reportColumns = `id, reporter_id, reported_id, reason, details, game_id, chat_message_id, status, context, created_at, resolved_at, resolved_by, resolution_note`
+
+func scanReport(row rowScanner) (*models.Report, error) {
+	report := &models.Report{}
+	err := row.Scan(
+		&report.ID, &report.ReporterID, &report.ReportedID, &report.Reason, &report.Details,
+		&report.GameID, &report.ChatMessageID, &report.Status, &report.Context, &report.CreatedAt,
+		&report.ResolvedAt, &report.ResolvedBy, &report.ResolutionNote,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Saved game filter operations
+
+// CountSavedGameFilters returns how many game filters the user has saved.
+func (db *DB) CountSavedGameFilters(userID uuid.UUID) (int, error) {
+	count, err := db.queries.CountSavedGameFilters(context.Background(), userID)
+	return int(count), err
+}
+
+// CreateSavedGameFilter saves a game filter for the user. It returns false
+// if they already have a filter of that name.
+func (db *DB) CreateSavedGameFilter(userID uuid.UUID, filter *models.SavedGameFilter) (bool, error) {
+	filter.CreatedAt = time.Now()
+	rows, err := db.queries.CreateSavedGameFilter(context.Background(), queries.CreateSavedGameFilterParams{
+		ID:        filter.ID,
+		UserID:    userID,
+		Name:      filter.Name,
+		Filter:    filter.Filter,
+		CreatedAt: filter.CreatedAt,
+	})
+	return rows > 0, err
+}
+
+// GetSavedGameFilters returns the user's saved game filters by name.
+func (db *DB) GetSavedGameFilters(userID uuid.UUID) ([]*models.SavedGameFilter, error) {
+	rows, err := db.queries.ListSavedGameFilters(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := make([]*models.SavedGameFilter, len(rows))
+	for i, row := range rows {
+		filters[i] = savedGameFilterFromRow(row)
+	}
+	return filters, nil
+}
+
+// GetSavedGameFilter returns one of the user's saved game filters.
+func (db *DB) GetSavedGameFilter(userID, filterID uuid.UUID) (*models.SavedGameFilter, error) {
+	row, err := db.queries.GetSavedGameFilter(context.Background(), queries.GetSavedGameFilterParams{ID: filterID, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	return savedGameFilterFromRow(row), nil
+}
+
+// DeleteSavedGameFilter removes one of the user's saved game filters. It
+// returns false if they have no such filter.
+func (db *DB) DeleteSavedGameFilter(userID, filterID uuid.UUID) (bool, error) {
+	rows, err := db.queries.DeleteSavedGameFilter(context.Background(), queries.DeleteSavedGameFilterParams{ID: filterID, UserID: userID})
+	return rows > 0, err
+}
+
+func savedGameFilterFromRow(row queries.SavedGameFilter) *models.SavedGameFilter {
+	return &models.SavedGameFilter{
+		ID:        row.ID,
+		Name:      row.Name,
+		Filter:    row.Filter,
+		CreatedAt: row.CreatedAt,
+	}
+}
+
+// Adjudication operations
+
+// CreateAdjudication files a request to settle a game. It returns false if
+// the game already has an open one.
+func (db *DB) CreateAdjudication(adjudication *models.Adjudication) (bool, error) {
+	query := `
+		INSERT INTO game_adjudications (id, game_id, requester_id, reason, details, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT DO NOTHING`
+
+	adjudication.Status = models.AdjudicationStatusOpen
+	adjudication.CreatedAt = time.Now()
+
+	result, err := db.conn.Exec(query, adjudication.ID, adjudication.GameID, adjudication.RequesterID,
+		adjudication.Reason, adjudication.Details, adjudication.Status, adjudication.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	created, err := result.RowsAffected()
+	return created == 1, err
+}
+
+func (db *DB) GetAdjudication(id uuid.UUID) (*models.Adjudication, error) {
+	query := `SELECT ` + adjudicationColumns + ` FROM game_adjudications WHERE id = $1`
+
+	return scanAdjudication(db.conn.QueryRow(query, id))
+}
+
+// GetAdjudications returns requests with the given status, oldest first.
+func (db *DB) GetAdjudications(status models.AdjudicationStatus, limit, offset int) ([]*models.Adjudication, error) {
+	query := `SELECT ` + adjudicationColumns + ` FROM game_adjudications WHERE status = $1 ORDER BY created_at LIMIT $2 OFFSET $3`
+
+	rows, err := db.conn.Query(query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var adjudications []*models.Adjudication
+	for rows.Next() {
+		adjudication, err := scanAdjudication(rows)
+		if err != nil {
+			return nil, err
+		}
+		adjudications = append(adjudications, adjudication)
+	}
+
+	return adjudications, rows.Err()
+}
+
+// ResolveAdjudication closes an open request with the moderator's
+// decision. It returns false if the request is not open.
+func (db *DB) ResolveAdjudication(adjudication *models.Adjudication) (bool, error) {
+	query := `
+		UPDATE game_adjudications
+		SET status = $2, decision = $3, winner_id = $4, resolved_at = $5, resolved_by = $6, resolution_note = $7
+		WHERE id = $1 AND status = 'open'`
+
+	now := time.Now()
+	adjudication.ResolvedAt = &now
+	result, err := db.conn.Exec(query, adjudication.ID, adjudication.Status, adjudication.Decision, adjudication.WinnerID,
+		adjudication.ResolvedAt, adjudication.ResolvedBy, adjudication.ResolutionNote)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	return updated == 1, err
+}
+
+const adjudicationColumns = `id, game_id, requester_id, reason, details, status, decision, winner_id, created_at, resolved_at, resolved_by, resolution_note`
+
+func scanAdjudication(row rowScanner) (*models.Adjudication, error) {
+	adjudication := &models.Adjudication{}
+	err := row.Scan(
+		&adjudication.ID, &adjudication.GameID, &adjudication.RequesterID, &adjudication.Reason, &adjudication.Details,
+		&adjudication.Status, &adjudication.Decision, &adjudication.WinnerID, &adjudication.CreatedAt,
+		&adjudication.ResolvedAt, &adjudication.ResolvedBy, &adjudication.ResolutionNote,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return adjudication, nil
+}
+
+// Leaderboard operations
+
+// leaderboardQuery reads the top players of a game type, and $3's own
+// entry, from the ranked leaderboard_ratings view.
+const leaderboardQuery = `
+	SELECT l.user_id, l.username, l.rating, l.games_played, l.games_won, l.rank,
+		COALESCE(s.tier, 'unranked'), COALESCE(s.division, 0), COALESCE(s.rated_games, 0)
+	FROM leaderboard_ratings l
+	LEFT JOIN user_stats s ON s.user_id = l.user_id
+	WHERE l.game_type = $1 AND (l.position <= $2 OR l.user_id = $3)
+	ORDER BY l.position`
+
+// friendsLeaderboardQuery ranks $3 and their friends among themselves.
+const friendsLeaderboardQuery = `
+	WITH ranked AS (
+		SELECT user_id, username, rating, games_played, games_won,
+			RANK() OVER (ORDER BY rating DESC) AS rank,
+			ROW_NUMBER() OVER (ORDER BY rating DESC, user_id) AS position
+		FROM leaderboard_ratings
+		WHERE game_type = $1 AND (user_id = $3 OR user_id IN (
+			SELECT friend_id FROM friendships WHERE user_id = $3 AND status = 'accepted'
+			UNION
+			SELECT user_id FROM friendships WHERE friend_id = $3 AND status = 'accepted'
+		))
+	)
+	SELECT r.user_id, r.username, r.rating, r.games_played, r.games_won, r.rank,
+		COALESCE(s.tier, 'unranked'), COALESCE(s.division, 0), COALESCE(s.rated_games, 0)
+	FROM ranked r
+	LEFT JOIN user_stats s ON s.user_id = r.user_id
+	WHERE r.position <= $2 OR r.user_id = $3
+	ORDER BY r.position`
+
+// leaderboardRatingsQuery ranks everyone who has finished a game of each
+// type by rating. It is the leaderboard_ratings view's definition (see
+// migration 00016), which SQLite keeps as a table.
+const leaderboardRatingsQuery = `
+	SELECT p.game_type, u.id, u.username,
+		COALESCE(s.rating, 1000), COALESCE(s.games_played, 0), COALESCE(s.games_won, 0),
+		RANK() OVER (PARTITION BY p.game_type ORDER BY COALESCE(s.rating, 1000) DESC),
+		ROW_NUMBER() OVER (PARTITION BY p.game_type ORDER BY COALESCE(s.rating, 1000) DESC, u.id)
+	FROM (
+		SELECT game_type, player1_id AS user_id FROM games WHERE status = 'completed' AND is_rated
+		UNION
+		SELECT game_type, player2_id FROM games WHERE status = 'completed' AND is_rated AND player2_id IS NOT NULL
+	) p
+	JOIN users u ON u.id = p.user_id
+	LEFT JOIN user_stats s ON s.user_id = u.id
+	WHERE u.is_active = true`
+
+// leaderboardRefreshLock is the advisory lock held while refreshing the
+// leaderboards, so servers do not refresh at the same time.
+const leaderboardRefreshLock = 0x6c6472 // "ldr"
+
+// GetLeaderboard returns the top players of a game type by rating, followed
+// by userID's own entry if they are outside the top. With friendsOnly the
+// ranking only covers userID and their friends. Rankings are as of the last
+// RefreshLeaderboards.
+func (db *DB) GetLeaderboard(gameType models.GameType, limit int, userID uuid.UUID, friendsOnly bool) ([]*models.LeaderboardEntry, error) {
+	query := leaderboardQuery
+	if friendsOnly {
+		query = friendsLeaderboardQuery
+	}
+
+	rows, err := db.reader().Query(query, gameType, limit, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var entries []*models.LeaderboardEntry
+	for rows.Next() {
+		entry := &models.LeaderboardEntry{}
+		var ratedGames int
+		err := rows.Scan(&entry.UserID, &entry.Username, &entry.Rating, &entry.GamesPlayed, &entry.GamesWon, &entry.Rank,
+			&entry.Tier, &entry.Division, &ratedGames)
+		if err != nil {
+			return nil, err
+		}
+		entry.Provisional = models.IsProvisional(ratedGames)
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// RefreshLeaderboards re-ranks the rating leaderboards. It reports false
+// without refreshing if another server is already doing so.
+func (db *DB) RefreshLeaderboards(ctx context.Context) (bool, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back leaderboard refresh: %v", err)
+		}
+	}()
+
+	if db.driver == DriverSQLite {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM leaderboard_ratings`); err != nil {
+			return false, err
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO leaderboard_ratings `+leaderboardRatingsQuery); err != nil {
+			return false, err
+		}
+		return true, tx.Commit()
+	}
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, leaderboardRefreshLock).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+	if _, err := tx.ExecContext(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY leaderboard_ratings`); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// GetLeaderboardUsers returns unranked leaderboard details for the given
+// users, keyed by user ID.
+func (db *DB) GetLeaderboardUsers(userIDs []uuid.UUID) (map[uuid.UUID]*models.LeaderboardEntry, error) {
+	entries := make(map[uuid.UUID]*models.LeaderboardEntry, len(userIDs))
+	if len(userIDs) == 0 {
+		return entries, nil
+	}
+
+	ids := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		ids[i] = userID.String()
+	}
+
+	query := `
+		SELECT u.id, u.username, COALESCE(s.rating, 1000), COALESCE(s.games_played, 0), COALESCE(s.games_won, 0),
+			COALESCE(s.tier, 'unranked'), COALESCE(s.division, 0), COALESCE(s.rated_games, 0)
+		FROM users u
+		LEFT JOIN user_stats s ON s.user_id = u.id
+		WHERE u.id = ANY($1::uuid[]) AND u.deleted_at IS NULL`
+
+	rows, err := db.reader().Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	for rows.Next() {
+		entry := &models.LeaderboardEntry{}
+		var ratedGames int
+		err := rows.Scan(&entry.UserID, &entry.Username, &entry.Rating, &entry.GamesPlayed, &entry.GamesWon,
+			&entry.Tier, &entry.Division, &ratedGames)
+		if err != nil {
+			return nil, err
+		}
+		entry.Provisional = models.IsProvisional(ratedGames)
+		entries[entry.UserID] = entry
+	}
+
+	return entries, rows.Err()
+}
+
+// Season operations
+
+// CreateSeason schedules a season. It returns false if the season would
+// overlap another.
+func (db *DB) CreateSeason(season *models.Season) (bool, error) {
+	ctx := context.Background()
+	overlapping, err := db.queries.CountOverlappingSeasons(ctx, queries.CountOverlappingSeasonsParams{
+		StartsAt: season.StartsAt,
+		EndsAt:   season.EndsAt,
+	})
+	if err != nil || overlapping > 0 {
+		return false, err
+	}
+
+	season.CreatedAt = time.Now()
+	err = db.queries.CreateSeason(ctx, queries.CreateSeasonParams{
+		ID:        season.ID,
+		Name:      season.Name,
+		StartsAt:  season.StartsAt,
+		EndsAt:    season.EndsAt,
+		CreatedAt: season.CreatedAt,
+	})
+	if err != nil {
+		return false, err
+	}
+	season.Status = season.StatusAt(time.Now())
+	return true, nil
+}
+
+func (db *DB) GetSeason(seasonID uuid.UUID) (*models.Season, error) {
+	row, err := db.queries.GetSeason(context.Background(), seasonID)
+	if err != nil {
+		return nil, err
+	}
+	return seasonFromRow(row), nil
+}
+
+// GetSeasons returns seasons, latest first.
+func (db *DB) GetSeasons(limit, offset int) ([]*models.Season, error) {
+	rows, err := db.queries.ListSeasons(context.Background(), queries.ListSeasonsParams{
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+	seasons := make([]*models.Season, len(rows))
+	for i, row := range rows {
+		seasons[i] = seasonFromRow(row)
+	}
+	return seasons, nil
+}
+
+// GetSeasonAt returns the season running at t, or sql.ErrNoRows between
+// seasons.
+func (db *DB) GetSeasonAt(t time.Time) (*models.Season, error) {
+	row, err := db.queries.GetSeasonAt(context.Background(), t)
+	if err != nil {
+		return nil, err
+	}
+	return seasonFromRow(row), nil
+}
+
+// DeleteSeason removes a season that has not started yet. It returns false
+// if there is no such season.
+func (db *DB) DeleteSeason(seasonID uuid.UUID) (bool, error) {
+	rows, err := db.queries.DeleteSeason(context.Background(), queries.DeleteSeasonParams{
+		ID:  seasonID,
+		Now: time.Now(),
+	})
+	return rows > 0, err
+}
+
+// GetUnfinalizedSeasons returns seasons that ended by now without their
+// rewards granted, oldest first.
+func (db *DB) GetUnfinalizedSeasons(now time.Time) ([]*models.Season, error) {
+	rows, err := db.queries.ListUnfinalizedSeasons(context.Background(), now)
+	if err != nil {
+		return nil, err
+	}
+	seasons := make([]*models.Season, len(rows))
+	for i, row := range rows {
+		seasons[i] = seasonFromRow(row)
+	}
+	return seasons, nil
+}
+
+// UpdateSeasonRatings rates the players of a finished game in the season,
+// as UpdateRatings does for their overall ratings. A player's first game
+// of the season starts them from their rating in their last season of the
+// game type, moved toward 1000 by resetFactor (0 resets fully, 1 keeps
+// it), or at 1000 if they have none. Draws have no winner.
+func (db *DB) UpdateSeasonRatings(season *models.Season, game *models.Game, resetFactor float64, rate func(rating1, rating2 int) (int, int)) error {
+	ctx := context.Background()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back season rating update: %v", err)
+		}
+	}()
+
+	userIDs := game.PlayerIDs()
+	for _, userID := range userIDs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO season_ratings (season_id, user_id, game_type, rating)
+			VALUES ($1, $2, $3, COALESCE((
+				SELECT ROUND(1000 + (r.rating - 1000) * $4::float8) FROM season_ratings r
+				JOIN seasons s ON s.id = r.season_id
+				WHERE r.user_id = $2 AND r.game_type = $3 AND r.games_played > 0 AND s.ends_at <= $5
+				ORDER BY s.ends_at DESC
+				LIMIT 1
+			), 1000))
+			ON CONFLICT (season_id, game_type, user_id) DO NOTHING`,
+			season.ID, userID, game.Type, resetFactor, season.StartsAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Rows are locked in a fixed order so concurrent games cannot deadlock
+	rows, err := tx.QueryContext(ctx, `
+		SELECT user_id, rating FROM season_ratings
+		WHERE season_id = $1 AND game_type = $2 AND user_id = ANY($3::uuid[])
+		ORDER BY user_id FOR UPDATE`, season.ID, game.Type, pq.Array(userIDs))
+	if err != nil {
+		return err
+	}
+	current := make(map[uuid.UUID]int, len(userIDs))
+	for rows.Next() {
+		var userID uuid.UUID
+		var rating int
+		if err := rows.Scan(&userID, &rating); err != nil {
+			rows.Close()
+			return err
+		}
+		current[userID] = rating
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	rating1, rating2 := rate(current[userIDs[0]], current[userIDs[1]])
+	now := time.Now()
+	for i, userID := range userIDs {
+		rating := rating1
+		if i == 1 {
+			rating = rating2
+		}
+		won, lost := 0, 0
+		if game.WinnerID != nil {
+			won, lost = 0, 1
+			if *game.WinnerID == userID {
+				won, lost = 1, 0
+			}
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE season_ratings SET rating = $4, games_played = games_played + 1,
+				games_won = games_won + $5, games_lost = games_lost + $6, updated_at = $7
+			WHERE season_id = $1 AND game_type = $2 AND user_id = $3`,
+			season.ID, game.Type, userID, rating, won, lost, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetSeasonLeaderboard ranks the players who played a game type in the
+// season by their season rating, returning the top limit followed by
+// userID's own entry if they are outside the top. Tiers are the players'
+// current ones.
+func (db *DB) GetSeasonLeaderboard(seasonID uuid.UUID, gameType models.GameType, limit int, userID uuid.UUID) ([]*models.LeaderboardEntry, error) {
+	query := `
+		WITH ranked AS (
+			SELECT r.user_id, u.username, r.rating, r.games_played, r.games_won,
+				RANK() OVER (ORDER BY r.rating DESC) AS rank,
+				ROW_NUMBER() OVER (ORDER BY r.rating DESC, r.user_id) AS position
+			FROM season_ratings r
+			JOIN users u ON u.id = r.user_id
+			WHERE r.season_id = $1 AND r.game_type = $2 AND r.games_played > 0 AND u.is_active = true
+		)
+		SELECT r.user_id, r.username, r.rating, r.games_played, r.games_won, r.rank,
+			COALESCE(s.tier, 'unranked'), COALESCE(s.division, 0)
+		FROM ranked r
+		LEFT JOIN user_stats s ON s.user_id = r.user_id
+		WHERE r.position <= $3 OR r.user_id = $4
+		ORDER BY r.position`
+
+	rows, err := db.reader().Query(query, seasonID, gameType, limit, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var entries []*models.LeaderboardEntry
+	for rows.Next() {
+		entry := &models.LeaderboardEntry{}
+		err := rows.Scan(&entry.UserID, &entry.Username, &entry.Rating, &entry.GamesPlayed, &entry.GamesWon, &entry.Rank,
+			&entry.Tier, &entry.Division)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// FinalizeSeason grants an ended season's rewards and returns them: for each
+// game type, players with at least minGames games are ranked by season
+// rating, the first becoming champion, then top_10 and top_100, and
+// everyone else a participant. It returns false if the season was already
+// finalized, granting nothing.
+func (db *DB) FinalizeSeason(season *models.Season, minGames int) ([]*models.SeasonRewardGrant, bool, error) {
+	ctx := context.Background()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back season finalization: %v", err)
+		}
+	}()
+
+	now := time.Now()
+	finalized, err := db.queries.WithTx(tx).FinalizeSeason(ctx, queries.FinalizeSeasonParams{
+		ID:          season.ID,
+		FinalizedAt: &now,
+	})
+	if err != nil || finalized == 0 {
+		return nil, false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO season_rewards (season_id, user_id, game_type, reward, rank, rating, granted_at)
+		SELECT season_id, user_id, game_type,
+			CASE WHEN rank = 1 THEN 'champion' WHEN rank <= 10 THEN 'top_10'
+				WHEN rank <= 100 THEN 'top_100' ELSE 'participant' END,
+			rank, rating, $3
+		FROM (
+			SELECT r.season_id, r.user_id, r.game_type, r.rating,
+				RANK() OVER (PARTITION BY r.game_type ORDER BY r.rating DESC) AS rank
+			FROM season_ratings r
+			JOIN users u ON u.id = r.user_id
+			WHERE r.season_id = $1 AND r.games_played >= $2 AND u.is_active = true
+		) ranked`, season.ID, minGames, now)
+	if err != nil {
+		return nil, false, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT user_id, game_type, reward, rank, rating FROM season_rewards
+		WHERE season_id = $1 ORDER BY game_type, rank`, season.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	var grants []*models.SeasonRewardGrant
+	for rows.Next() {
+		grant := &models.SeasonRewardGrant{SeasonID: season.ID, SeasonName: season.Name, GrantedAt: now}
+		if err := rows.Scan(&grant.UserID, &grant.GameType, &grant.Reward, &grant.Rank, &grant.Rating); err != nil {
+			rows.Close()
+			return nil, false, err
+		}
+		grants = append(grants, grant)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, false, err
+	}
+
+	season.FinalizedAt = &now
+	return grants, true, tx.Commit()
+}
+
+// GetUserSeasonRewards returns every season reward the user was granted,
+// latest first.
+func (db *DB) GetUserSeasonRewards(userID uuid.UUID) ([]*models.SeasonRewardGrant, error) {
+	rows, err := db.queries.ListUserSeasonRewards(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+	grants := make([]*models.SeasonRewardGrant, len(rows))
+	for i, row := range rows {
+		grants[i] = &models.SeasonRewardGrant{
+			SeasonID:   row.SeasonID,
+			SeasonName: row.SeasonName,
+			UserID:     row.UserID,
+			GameType:   row.GameType,
+			Reward:     row.Reward,
+			Rank:       int(row.Rank),
+			Rating:     int(row.Rating),
+			GrantedAt:  row.GrantedAt,
+		}
+	}
+	return grants, nil
+}
+
+func seasonFromRow(row queries.Season) *models.Season {
+	season := &models.Season{
+		ID:          row.ID,
+		Name:        row.Name,
+		StartsAt:    row.StartsAt,
+		EndsAt:      row.EndsAt,
+		FinalizedAt: row.FinalizedAt,
+		CreatedAt:   row.CreatedAt,
+	}
+	season.Status = season.StatusAt(time.Now())
+	return season
+}
+
+// Tournament operations
+
+func (db *DB) CreateTournament(tournament *models.Tournament) error {
+	tournament.CreatedAt = time.Now()
+	err := db.queries.CreateTournament(context.Background(), queries.CreateTournamentParams{
+		ID:           tournament.ID,
+		Name:         tournament.Name,
+		GameType:     tournament.GameType,
+		TimeControl:  tournament.TimeControl,
+		StartsAt:     tournament.StartsAt,
+		EndsAt:       tournament.EndsAt,
+		Rounds:       int32(tournament.Rounds),
+		RoundMinutes: int32(tournament.RoundMinutes),
+		Capacity:     int32(tournament.Capacity),
+		MinRating:    nullInt32(tournament.MinRating),
+		MaxRating:    nullInt32(tournament.MaxRating),
+		CreatedBy:    tournament.CreatedBy,
+		CreatedAt:    tournament.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	tournament.Status = tournament.StatusAt(time.Now())
+	return nil
+}
+
+func (db *DB) GetTournament(tournamentID uuid.UUID) (*models.Tournament, error) {
+	row, err := db.queries.GetTournament(context.Background(), tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	return tournamentFromRow(row.Tournament, row.Registrations), nil
+}
+
+// GetUpcomingTournaments returns tournaments open for registration, soonest
+// first. An empty game type matches every type.
+func (db *DB) GetUpcomingTournaments(gameType models.GameType, limit, offset int) ([]*models.Tournament, error) {
+	rows, err := db.queries.ListUpcomingTournaments(context.Background(), queries.ListUpcomingTournamentsParams{
+		Now:      time.Now(),
+		GameType: string(gameType),
+		Lim:      int32(limit),
+		Off:      int32(offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+	tournaments := make([]*models.Tournament, len(rows))
+	for i, row := range rows {
+		tournaments[i] = tournamentFromRow(row.Tournament, row.Registrations)
+	}
+	return tournaments, nil
+}
+
+// GetTournamentsWithRoundsDue returns tournaments with a round that has not
+// started by now but starts before before.
+func (db *DB) GetTournamentsWithRoundsDue(now, before time.Time) ([]*models.Tournament, error) {
+	rows, err := db.queries.ListTournamentsWithRoundsDue(context.Background(), queries.ListTournamentsWithRoundsDueParams{
+		Now:    now,
+		Before: before,
+	})
+	if err != nil {
+		return nil, err
+	}
+	tournaments := make([]*models.Tournament, 0, len(rows))
+	for _, row := range rows {
+		tournament := tournamentFromRow(row.Tournament, row.Registrations)
+		if round, ok := tournament.NextRound(now); ok && tournament.RoundStartsAt(round).Before(before) {
+			tournaments = append(tournaments, tournament)
+		}
+	}
+	return tournaments, nil
+}
+
+// CancelTournament cancels a tournament that has not ended. It returns false
+// if there is no such tournament.
+func (db *DB) CancelTournament(tournament *models.Tournament) (bool, error) {
+	now := time.Now()
+	rows, err := db.queries.CancelTournament(context.Background(), queries.CancelTournamentParams{
+		ID:          tournament.ID,
+		CancelledAt: &now,
+	})
+	if err != nil || rows == 0 {
+		return false, err
+	}
+	tournament.CancelledAt = &now
+	tournament.Status = models.TournamentStatusCancelled
+	return true, nil
+}
+
+// RegisterForTournament registers the user for a tournament that has not
+// started. It returns false if the tournament has started, been cancelled
+// or is full. Registering again is a no-op.
+func (db *DB) RegisterForTournament(tournamentID, userID uuid.UUID) (bool, error) {
+	ctx := context.Background()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back tournament registration: %v", err)
+		}
+	}()
+
+	// Locking the tournament keeps concurrent registrations under capacity
+	var capacity int
+	var startsAt time.Time
+	var cancelledAt *time.Time
+	err = tx.QueryRowContext(ctx, `SELECT capacity, starts_at, cancelled_at FROM tournaments WHERE id = $1 FOR UPDATE`,
+		tournamentID).Scan(&capacity, &startsAt, &cancelledAt)
+	if err != nil {
+		return false, err
+	}
+	if cancelledAt != nil || !startsAt.After(time.Now()) {
+		return false, nil
+	}
+
+	q := db.queries.WithTx(tx)
+	registered, err := q.IsRegisteredForTournament(ctx, queries.IsRegisteredForTournamentParams{
+		TournamentID: tournamentID,
+		UserID:       userID,
+	})
+	if err != nil || registered {
+		return registered, err
+	}
+
+	var registrations int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM tournament_registrations WHERE tournament_id = $1`,
+		tournamentID).Scan(&registrations)
+	if err != nil {
+		return false, err
+	}
+	if registrations >= capacity {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO tournament_registrations (tournament_id, user_id, registered_at) VALUES ($1, $2, $3)`,
+		tournamentID, userID, time.Now())
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// WithdrawFromTournament removes the user's registration for a tournament
+// that has not started. It returns false if they were not registered or it
+// has started.
+func (db *DB) WithdrawFromTournament(tournamentID, userID uuid.UUID) (bool, error) {
+	rows, err := db.queries.DeleteTournamentRegistration(context.Background(), queries.DeleteTournamentRegistrationParams{
+		TournamentID: tournamentID,
+		UserID:       userID,
+		Now:          time.Now(),
+	})
+	return rows > 0, err
+}
+
+func (db *DB) IsRegisteredForTournament(tournamentID, userID uuid.UUID) (bool, error) {
+	return db.queries.IsRegisteredForTournament(context.Background(), queries.IsRegisteredForTournamentParams{
+		TournamentID: tournamentID,
+		UserID:       userID,
+	})
+}
+
+// GetTournamentRegistrants returns the players registered for a tournament,
+// in the order they registered.
+func (db *DB) GetTournamentRegistrants(tournamentID uuid.UUID) ([]uuid.UUID, error) {
+	return db.queries.ListTournamentRegistrants(context.Background(), tournamentID)
+}
+
+// GetUserTournaments returns the tournaments the user is registered for that
+// have not ended or been cancelled, soonest first.
+func (db *DB) GetUserTournaments(userID uuid.UUID) ([]*models.Tournament, error) {
+	rows, err := db.queries.ListUserTournaments(context.Background(), queries.ListUserTournamentsParams{
+		UserID: userID,
+		Now:    time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	tournaments := make([]*models.Tournament, len(rows))
+	for i, row := range rows {
+		tournaments[i] = tournamentFromRow(row.Tournament, row.Registrations)
+	}
+	return tournaments, nil
+}
+
+// ClaimTournamentReminder records that a round's reminder is being sent. It
+// returns false if it already was.
+func (db *DB) ClaimTournamentReminder(tournamentID uuid.UUID, round int) (bool, error) {
+	rows, err := db.queries.ClaimTournamentReminder(context.Background(), queries.ClaimTournamentReminderParams{
+		TournamentID: tournamentID,
+		Round:        int32(round),
+	})
+	return rows > 0, err
+}
+
+func tournamentFromRow(row queries.Tournament, registrations int64) *models.Tournament {
+	tournament := &models.Tournament{
+		ID:            row.ID,
+		Name:          row.Name,
+		GameType:      row.GameType,
+		TimeControl:   row.TimeControl,
+		StartsAt:      row.StartsAt,
+		EndsAt:        row.EndsAt,
+		Rounds:        int(row.Rounds),
+		RoundMinutes:  int(row.RoundMinutes),
+		Capacity:      int(row.Capacity),
+		MinRating:     intPtr(row.MinRating),
+		MaxRating:     intPtr(row.MaxRating),
+		CreatedBy:     row.CreatedBy,
+		CancelledAt:   row.CancelledAt,
+		CreatedAt:     row.CreatedAt,
+		Registrations: int(registrations),
+	}
+	tournament.Status = tournament.StatusAt(time.Now())
+	return tournament
+}
+
+func nullInt32(value *int) sql.NullInt32 {
+	if value == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: int32(*value), Valid: true}
+}
+
+func intPtr(value sql.NullInt32) *int {
+	if !value.Valid {
+		return nil
+	}
+	v := int(value.Int32)
+	return &v
+}
+
+// Direct message operations
+func (db *DB) CreateDirectMessage(message *models.DirectMessage) error {
+	query := `
+		INSERT INTO direct_messages (id, sender_id, recipient_id, body, created_at)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	message.CreatedAt = time.Now()
+	_, err := db.conn.Exec(query, message.ID, message.SenderID, message.RecipientID, message.Body, message.CreatedAt)
+	return err
+}
+
+// GetDirectMessagesBetween returns the latest direct messages two users
+// exchanged, oldest first.
+func (db *DB) GetDirectMessagesBetween(userID, otherUserID uuid.UUID, limit int) ([]*models.DirectMessage, error) {
+	query := `
+		SELECT id, sender_id, recipient_id, body, created_at FROM (
+			SELECT id, sender_id, recipient_id, body, created_at FROM direct_messages
+			WHERE (sender_id = $1 AND recipient_id = $2) OR (sender_id = $2 AND recipient_id = $1)
+			ORDER BY created_at DESC
+			LIMIT $3
+		) latest
+		ORDER BY created_at`
+
+	rows, err := db.conn.Query(query, userID, otherUserID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var messages []*models.DirectMessage
+	for rows.Next() {
+		message := &models.DirectMessage{}
+		if err := rows.Scan(&message.ID, &message.SenderID, &message.RecipientID, &message.Body, &message.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+// Chat message operations
+func (db *DB) CreateChatMessage(message *models.ChatMessage) error {
+	query := `
+		INSERT INTO chat_messages (id, room_id, sender_id, body, created_at)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := db.conn.Exec(query, message.ID, message.RoomID, message.SenderID, message.Body, message.CreatedAt)
+	return err
+}
+
+func (db *DB) GetChatMessage(id uuid.UUID) (*models.ChatMessage, error) {
+	query := `SELECT ` + chatMessageColumns + ` FROM chat_messages WHERE id = $1`
+
+	return scanChatMessage(db.conn.QueryRow(query, id))
+}
+
+// GetChatMessages returns room chat for review, newest first, including
+// deleted messages. An empty room ID or nil sender matches any.
+func (db *DB) GetChatMessages(roomID string, senderID uuid.UUID, limit, offset int) ([]*models.ChatMessage, error) {
+	query := `SELECT ` + chatMessageColumns + ` FROM chat_messages WHERE true`
+
+	var args []interface{}
+	argIndex := 1
+
+	if roomID != "" {
+		query += fmt.Sprintf(" AND room_id = $%d", argIndex)
+		args = append(args, roomID)
+		argIndex++
+	}
+
+	if senderID != uuid.Nil {
+		query += fmt.Sprintf(" AND sender_id = $%d", argIndex)
+		args = append(args, senderID)
+		argIndex++
+	}
+
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, limit, offset)
+
+	return db.queryChatMessages(query, args...)
+}
+
+// GetRoomChatBefore returns up to limit of a room's messages sent no later
+// than before, oldest first, including deleted ones.
+func (db *DB) GetRoomChatBefore(roomID string, before time.Time, limit int) ([]*models.ChatMessage, error) {
+	query := `
+		SELECT ` + chatMessageColumns + ` FROM (
+			SELECT ` + chatMessageColumns + ` FROM chat_messages
+			WHERE room_id = $1 AND created_at <= $2
+			ORDER BY created_at DESC
+			LIMIT $3
+		) latest
+		ORDER BY created_at`
+
+	return db.queryChatMessages(query, roomID, before, limit)
+}
+
+// GetRoomChat returns a room's chat as one of its members sees it, newest
+// first: deleted messages and messages between the viewer and users on
+// either side of a block with them are left out.
+func (db *DB) GetRoomChat(roomID string, viewerID uuid.UUID, limit, offset int) ([]*models.ChatMessage, error) {
+	query := `
+		SELECT ` + chatMessageColumns + ` FROM chat_messages m
+		WHERE room_id = $1 AND deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM user_blocks b
+				WHERE (b.blocker_id = $2 AND b.blocked_id = m.sender_id)
+					OR (b.blocker_id = m.sender_id AND b.blocked_id = $2)
+			)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	return db.queryChatMessages(query, roomID, viewerID, limit, offset)
+}
+
+// DeleteChatMessage hides a chat message. It returns false if the message
+// does not exist or is already deleted.
+func (db *DB) DeleteChatMessage(id, deletedBy uuid.UUID) (bool, error) {
+	query := `UPDATE chat_messages SET deleted_at = NOW(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := db.conn.Exec(query, id, deletedBy)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted == 1, err
+}
+
+func (db *DB) queryChatMessages(query string, args ...interface{}) ([]*models.ChatMessage, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var messages []*models.ChatMessage
+	for rows.Next() {
+		message, err := scanChatMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+const chatMessageColumns = `id, room_id, sender_id, body, created_at, deleted_at, deleted_by`
+
+func scanChatMessage(row rowScanner) (*models.ChatMessage, error) {
+	message := &models.ChatMessage{}
+	err := row.Scan(
+		&message.ID, &message.RoomID, &message.SenderID, &message.Body,
+		&message.CreatedAt, &message.DeletedAt, &message.DeletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// Mute operations
+
+// SetMute mutes a user, replacing any mute they already have.
+func (db *DB) SetMute(mute *models.Mute) error {
+	query := `
+		INSERT INTO user_mutes (user_id, muted_until, reason, muted_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET
+			muted_until = EXCLUDED.muted_until, reason = EXCLUDED.reason,
+			muted_by = EXCLUDED.muted_by, created_at = EXCLUDED.created_at`
+
+	mute.CreatedAt = time.Now()
+	_, err := db.conn.Exec(query, mute.UserID, mute.MutedUntil, mute.Reason, mute.MutedBy, mute.CreatedAt)
+	return err
+}
+
+// DeleteMute lifts a user's mute. It returns false if they were not muted.
+func (db *DB) DeleteMute(userID uuid.UUID) (bool, error) {
+	result, err := db.conn.Exec(`DELETE FROM user_mutes WHERE user_id = $1 AND muted_until > NOW()`, userID)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted == 1, err
+}
+
+// GetMutedUntil returns when a user's mute ends, or the zero time if they
+// are not muted.
+func (db *DB) GetMutedUntil(userID uuid.UUID) (time.Time, error) {
+	var until time.Time
+	err := db.conn.QueryRow(`SELECT muted_until FROM user_mutes WHERE user_id = $1 AND muted_until > NOW()`, userID).Scan(&until)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	return until, err
+}
+
+// Audit log operations
+
+// CreateAuditEntry appends to the audit log, which cannot be changed
+// afterwards.
+func (db *DB) CreateAuditEntry(entry *models.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (id, actor_id, action, target_type, target_id, reason, before, after, ip, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	entry.CreatedAt = time.Now()
+	_, err := db.conn.Exec(query, entry.ID, entry.ActorID, entry.Action, entry.TargetType, entry.TargetID, entry.Reason,
+		nullJSON(entry.Before), nullJSON(entry.After), entry.IP, entry.RequestID, entry.CreatedAt)
+	return err
+}
+
+// GetAuditEntries returns audit log entries matching the filter, newest
+// first.
+func (db *DB) GetAuditEntries(filter models.AuditFilter, limit, offset int) ([]*models.AuditEntry, error) {
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE true`
+
+	var args []interface{}
+	argIndex := 1
+
+	if filter.ActorID != uuid.Nil {
+		query += fmt.Sprintf(" AND actor_id = $%d", argIndex)
+		args = append(args, filter.ActorID)
+		argIndex++
+	}
+
+	if filter.Action != "" {
+		query += fmt.Sprintf(" AND action = $%d", argIndex)
+		args = append(args, filter.Action)
+		argIndex++
+	}
+
+	if filter.TargetType != "" {
+		query += fmt.Sprintf(" AND target_type = $%d", argIndex)
+		args = append(args, filter.TargetType)
+		argIndex++
+	}
+
+	if filter.TargetID != "" {
+		query += fmt.Sprintf(" AND target_id = $%d", argIndex)
+		args = append(args, filter.TargetID)
+		argIndex++
+	}
+
+	if !filter.Since.IsZero() {
+		query += fmt.Sprintf(" AND created_at >= $%d", argIndex)
+		args = append(args, filter.Since)
+		argIndex++
+	}
+
+	if !filter.Until.IsZero() {
+		query += fmt.Sprintf(" AND created_at < $%d", argIndex)
+		args = append(args, filter.Until)
+		argIndex++
+	}
+
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, limit, offset)
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		entry := &models.AuditEntry{}
+		err := rows.Scan(
+			&entry.ID, &entry.ActorID, &entry.Action, &entry.TargetType, &entry.TargetID, &entry.Reason,
+			(*[]byte)(&entry.Before), (*[]byte)(&entry.After), &entry.IP, &entry.RequestID, &entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+const auditColumns = `id, actor_id, action, target_type, target_id, reason, before, after, ip, request_id, created_at`
+
+// nullJSON stores an empty snapshot as NULL.
+func nullJSON(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return []byte(data)
+}
+
+// Retention operations
+
+// retentionBatchSize bounds each delete so retention never locks many rows
+// at once.
+const retentionBatchSize = 1000
+
+// deleteInBatches runs a delete that takes the batch size as its last
+// parameter until a batch comes up short, and returns how many rows it
+// deleted.
+func (db *DB) deleteInBatches(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	args = append(args, retentionBatchSize)
+	var total int64
+	for {
+		result, err := db.conn.ExecContext(ctx, query, args...)
+		if err != nil {
+			return total, err
+		}
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+		if deleted < retentionBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// DeleteStaleWaitingGames deletes waiting games created before the cutoff
+// that nobody joined.
+func (db *DB) DeleteStaleWaitingGames(ctx context.Context, before time.Time) (int64, error) {
+	return db.deleteInBatches(ctx, `
+		DELETE FROM games WHERE id IN (
+			SELECT id FROM games
+			WHERE status = 'waiting' AND player2_id IS NULL AND created_at < $1
+			LIMIT $2
+		)`, before)
+}
+
+// DeleteOldChatMessages deletes room chat sent before the cutoff, keeping
+// messages an open report points at.
+func (db *DB) DeleteOldChatMessages(ctx context.Context, before time.Time) (int64, error) {
+	return db.deleteInBatches(ctx, `
+		DELETE FROM chat_messages WHERE id IN (
+			SELECT m.id FROM chat_messages m
+			WHERE m.created_at < $1 AND NOT EXISTS (
+				SELECT 1 FROM reports r WHERE r.chat_message_id = m.id AND r.status = 'open'
+			)
+			LIMIT $2
+		)`, before)
+}
+
+// DeleteOldDirectMessages deletes direct messages sent before the cutoff.
+func (db *DB) DeleteOldDirectMessages(ctx context.Context, before time.Time) (int64, error) {
+	return db.deleteInBatches(ctx, `
+		DELETE FROM direct_messages WHERE id IN (
+			SELECT id FROM direct_messages WHERE created_at < $1 LIMIT $2
+		)`, before)
+}
+
+// DeleteOldLoginEvents deletes login history recorded before the cutoff.
+func (db *DB) DeleteOldLoginEvents(ctx context.Context, before time.Time) (int64, error) {
+	return db.deleteInBatches(ctx, `
+		DELETE FROM login_events WHERE id IN (
+			SELECT id FROM login_events WHERE created_at < $1 LIMIT $2
+		)`, before)
+}
+
+// DeleteStaleTrustedDevices forgets devices not used since the cutoff, so
+// their next login is verified again.
+func (db *DB) DeleteStaleTrustedDevices(ctx context.Context, before time.Time) (int64, error) {
+	return db.deleteInBatches(ctx, `
+		DELETE FROM trusted_devices WHERE (user_id, device_hash) IN (
+			SELECT user_id, device_hash FROM trusted_devices WHERE last_used_at < $1 LIMIT $2
+		)`, before)
 }
@@ -0,0 +1,19 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// registerPoolMetrics publishes the connection pool's stats as go_sql_*
+// metrics labelled with the database name: open, in use and idle
+// connections, how often and how long queries waited for one, and how many
+// were closed by the idle and lifetime limits.
+func registerPoolMetrics(conn *sql.DB, name string) {
+	if err := prometheus.Register(collectors.NewDBStatsCollector(conn, name)); err != nil {
+		log.Printf("Error registering database pool metrics: %v", err)
+	}
+}
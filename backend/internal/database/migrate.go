@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// migrations holds the versioned schema, applied in order by file prefix.
+// Add a new file for every schema change; never edit an applied one.
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// sqliteMigrations is the same schema for SQLite databases.
+//
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrations embed.FS
+
+// Migrations applies and rolls back the embedded schema migrations.
+type Migrations struct {
+	provider *goose.Provider
+}
+
+// Migrations returns the migrator for this database. Migrations hold a
+// Postgres advisory lock while running, so servers starting together apply
+// each one once.
+func (db *DB) Migrations() (*Migrations, error) {
+	if db.driver == DriverSQLite {
+		fsys, err := fs.Sub(sqliteMigrations, "migrations/sqlite")
+		if err != nil {
+			return nil, err
+		}
+		provider, err := goose.NewProvider(goose.DialectSQLite3, db.conn, fsys)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load migrations: %w", err)
+		}
+		return &Migrations{provider: provider}, nil
+	}
+
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration lock: %w", err)
+	}
+	provider, err := goose.NewProvider(goose.DialectPostgres, db.conn, fsys, goose.WithSessionLocker(locker))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return &Migrations{provider: provider}, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrations) Up(ctx context.Context) error {
+	results, err := m.provider.Up(ctx)
+	logMigrationResults(results)
+	return err
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrations) Down(ctx context.Context) error {
+	result, err := m.provider.Down(ctx)
+	if result != nil {
+		logMigrationResults([]*goose.MigrationResult{result})
+	}
+	return err
+}
+
+// Status lists every migration and whether it has been applied.
+func (m *Migrations) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	return m.provider.Status(ctx)
+}
+
+// Version returns the version of the latest applied migration, or 0 if
+// none has been.
+func (m *Migrations) Version(ctx context.Context) (int64, error) {
+	return m.provider.GetDBVersion(ctx)
+}
+
+func logMigrationResults(results []*goose.MigrationResult) {
+	for _, result := range results {
+		if result.Error != nil {
+			continue
+		}
+		log.Printf("Migration %s %s (%s)", result.Direction, result.Source.Path, result.Duration.Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,231 @@
+-- Baseline schema for Vibe Arcade. It is idempotent so databases created
+-- before versioned migrations can adopt it.
+
+-- +goose Up
+
+-- Users table
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    username VARCHAR(50) UNIQUE NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    role VARCHAR(20) NOT NULL DEFAULT 'player' CHECK (role IN ('player', 'admin')),
+    avatar_url VARCHAR(500) NOT NULL DEFAULT '',
+    profile_visibility VARCHAR(20) NOT NULL DEFAULT 'public' CHECK (profile_visibility IN ('public', 'friends', 'private')),
+    username_changed_at TIMESTAMP
+);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_url VARCHAR(500) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS profile_visibility VARCHAR(20) NOT NULL DEFAULT 'public' CHECK (profile_visibility IN ('public', 'friends', 'private'));
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username_changed_at TIMESTAMP;
+
+-- User stats table
+CREATE TABLE IF NOT EXISTS user_stats (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    games_played INTEGER NOT NULL DEFAULT 0,
+    games_won INTEGER NOT NULL DEFAULT 0,
+    games_lost INTEGER NOT NULL DEFAULT 0,
+    rating INTEGER NOT NULL DEFAULT 1000,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Games table
+CREATE TABLE IF NOT EXISTS games (
+    id UUID PRIMARY KEY,
+    game_type VARCHAR(20) NOT NULL CHECK (game_type IN ('dominoes', 'chess')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('waiting', 'in_progress', 'completed', 'abandoned')),
+    player1_id UUID NOT NULL REFERENCES users(id),
+    player2_id UUID REFERENCES users(id),
+    winner_id UUID REFERENCES users(id),
+    current_turn UUID REFERENCES users(id),
+    game_state JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP,
+    ended_at TIMESTAMP,
+    is_private BOOLEAN NOT NULL DEFAULT false,
+    settings JSONB NOT NULL DEFAULT '{}',
+    starter_id UUID REFERENCES users(id),
+    initial_state JSONB
+);
+
+ALTER TABLE games ADD COLUMN IF NOT EXISTS is_private BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE games ADD COLUMN IF NOT EXISTS starter_id UUID REFERENCES users(id);
+ALTER TABLE games ADD COLUMN IF NOT EXISTS initial_state JSONB;
+
+-- Moves table
+CREATE TABLE IF NOT EXISTS moves (
+    id UUID PRIMARY KEY,
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    player_id UUID NOT NULL REFERENCES users(id),
+    move_data JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    is_valid BOOLEAN NOT NULL DEFAULT true,
+    kind VARCHAR(20) NOT NULL DEFAULT 'move' CHECK (kind IN ('move', 'timeout'))
+);
+
+ALTER TABLE moves ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'move' CHECK (kind IN ('move', 'timeout'));
+
+-- User blocks table
+CREATE TABLE IF NOT EXISTS user_blocks (
+    blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blocker_id, blocked_id)
+);
+
+-- Friendships table; user_id sent the request
+CREATE TABLE IF NOT EXISTS friendships (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    friend_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, friend_id),
+    CHECK (user_id <> friend_id)
+);
+
+-- Reports table; context holds the chat and game at the time of the report
+CREATE TABLE IF NOT EXISTS reports (
+    id UUID PRIMARY KEY,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reported_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('harassment', 'cheating', 'spam', 'offensive_name', 'other')),
+    details TEXT NOT NULL DEFAULT '',
+    game_id UUID REFERENCES games(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    context JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP,
+    resolved_by UUID REFERENCES users(id),
+    resolution_note TEXT NOT NULL DEFAULT ''
+);
+
+-- Direct messages table
+CREATE TABLE IF NOT EXISTS direct_messages (
+    id UUID PRIMARY KEY,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Room chat; deleted messages are kept for moderation
+CREATE TABLE IF NOT EXISTS chat_messages (
+    id UUID PRIMARY KEY,
+    room_id VARCHAR(100) NOT NULL,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+    deleted_by UUID REFERENCES users(id)
+);
+
+-- One mute per user; muting again replaces it
+CREATE TABLE IF NOT EXISTS user_mutes (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    muted_until TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    muted_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Social login accounts, keyed by the provider's stable subject ID
+CREATE TABLE IF NOT EXISTS user_identities (
+    provider VARCHAR(20) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (provider, subject)
+);
+
+-- Sign-ins, for spotting logins from new devices or improbable locations
+CREATE TABLE IF NOT EXISTS login_events (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    method VARCHAR(20) NOT NULL,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    device_hash VARCHAR(64) NOT NULL,
+    country VARCHAR(2) NOT NULL DEFAULT '',
+    suspicious BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Devices a user has signed in from without needing verification
+CREATE TABLE IF NOT EXISTS trusted_devices (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, device_hash)
+);
+
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS chat_message_id UUID REFERENCES chat_messages(id) ON DELETE SET NULL;
+
+-- Indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_users_username ON users(username);
+CREATE INDEX IF NOT EXISTS idx_games_status ON games(status);
+CREATE INDEX IF NOT EXISTS idx_games_type ON games(game_type);
+CREATE INDEX IF NOT EXISTS idx_games_player1 ON games(player1_id);
+CREATE INDEX IF NOT EXISTS idx_games_player2 ON games(player2_id);
+CREATE INDEX IF NOT EXISTS idx_games_created_at ON games(created_at);
+CREATE INDEX IF NOT EXISTS idx_moves_game_id ON moves(game_id);
+CREATE INDEX IF NOT EXISTS idx_moves_player_id ON moves(player_id);
+CREATE INDEX IF NOT EXISTS idx_moves_created_at ON moves(created_at);
+CREATE INDEX IF NOT EXISTS idx_user_blocks_blocked ON user_blocks(blocked_id);
+CREATE INDEX IF NOT EXISTS idx_friendships_friend ON friendships(friend_id);
+-- One friendship per pair, whichever way the request was sent
+CREATE UNIQUE INDEX IF NOT EXISTS idx_friendships_pair ON friendships(LEAST(user_id, friend_id), GREATEST(user_id, friend_id));
+CREATE INDEX IF NOT EXISTS idx_reports_status ON reports(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_direct_messages_recipient ON direct_messages(recipient_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_direct_messages_sender ON direct_messages(sender_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_chat_messages_room ON chat_messages(room_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_chat_messages_sender ON chat_messages(sender_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user ON user_identities(user_id);
+CREATE INDEX IF NOT EXISTS idx_login_events_user ON login_events(user_id, created_at);
+
+-- Function to update updated_at timestamp
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+-- +goose StatementEnd
+
+-- Triggers to automatically update updated_at
+DROP TRIGGER IF EXISTS update_users_updated_at ON users;
+CREATE TRIGGER update_users_updated_at BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_user_stats_updated_at ON user_stats;
+CREATE TRIGGER update_user_stats_updated_at BEFORE UPDATE ON user_stats
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_games_updated_at ON games;
+CREATE TRIGGER update_games_updated_at BEFORE UPDATE ON games
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose Down
+DROP TABLE IF EXISTS trusted_devices;
+DROP TABLE IF EXISTS login_events;
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS user_mutes;
+DROP TABLE IF EXISTS reports;
+DROP TABLE IF EXISTS chat_messages;
+DROP TABLE IF EXISTS direct_messages;
+DROP TABLE IF EXISTS friendships;
+DROP TABLE IF EXISTS user_blocks;
+DROP TABLE IF EXISTS moves;
+DROP TABLE IF EXISTS games;
+DROP TABLE IF EXISTS user_stats;
+DROP TABLE IF EXISTS users;
+DROP FUNCTION IF EXISTS update_updated_at_column();
@@ -0,0 +1,8 @@
+-- Deleted accounts keep their row, scrubbed of personal data, so their
+-- games still have both players.
+
+-- +goose Up
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
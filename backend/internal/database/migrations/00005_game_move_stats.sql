@@ -0,0 +1,23 @@
+-- Move counts and timings kept on the game, so listings and the turn
+-- timeout sweep need not read the moves table.
+
+-- +goose Up
+ALTER TABLE games ADD COLUMN IF NOT EXISTS move_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS last_move_at TIMESTAMP;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS duration_seconds INTEGER;
+
+UPDATE games g SET move_count = m.move_count, last_move_at = m.last_move_at
+FROM (SELECT game_id, COUNT(*) AS move_count, MAX(created_at) AS last_move_at FROM moves GROUP BY game_id) m
+WHERE m.game_id = g.id;
+
+UPDATE games SET duration_seconds = EXTRACT(EPOCH FROM ended_at - started_at)::integer
+WHERE started_at IS NOT NULL AND ended_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_games_turn_started ON games ((COALESCE(last_move_at, started_at)))
+    WHERE status = 'in_progress';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_games_turn_started;
+ALTER TABLE games DROP COLUMN IF EXISTS duration_seconds;
+ALTER TABLE games DROP COLUMN IF EXISTS last_move_at;
+ALTER TABLE games DROP COLUMN IF EXISTS move_count;
@@ -0,0 +1,19 @@
+-- Every change to a user's rating, for rating graphs.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS rating_history (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    game_id UUID REFERENCES games(id) ON DELETE SET NULL,
+    game_type VARCHAR(50) NOT NULL DEFAULT '',
+    system VARCHAR(20) NOT NULL,
+    old_rating INTEGER NOT NULL,
+    new_rating INTEGER NOT NULL,
+    delta INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rating_history_user ON rating_history(user_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS rating_history;
@@ -0,0 +1,33 @@
+-- Announce game row changes on the game_changed channel so every instance
+-- can refresh what it holds for the game, whichever instance wrote it.
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_game_changed() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND (OLD.status, OLD.player2_id, OLD.current_turn, OLD.winner_id, OLD.move_count)
+        IS NOT DISTINCT FROM (NEW.status, NEW.player2_id, NEW.current_turn, NEW.winner_id, NEW.move_count) THEN
+        RETURN NULL;
+    END IF;
+    PERFORM pg_notify('game_changed', json_build_object(
+        'id', NEW.id,
+        'status', NEW.status,
+        'player1_id', NEW.player1_id,
+        'player2_id', NEW.player2_id,
+        'current_turn', NEW.current_turn,
+        'winner_id', NEW.winner_id,
+        'move_count', NEW.move_count
+    )::text);
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS games_notify_change ON games;
+CREATE TRIGGER games_notify_change
+    AFTER INSERT OR UPDATE ON games
+    FOR EACH ROW EXECUTE FUNCTION notify_game_changed();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS games_notify_change ON games;
+DROP FUNCTION IF EXISTS notify_game_changed();
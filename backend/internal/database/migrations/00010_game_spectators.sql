@@ -0,0 +1,14 @@
+-- Spectators recorded on the game, so popular games can be found without
+-- asking every server.
+
+-- +goose Up
+ALTER TABLE games ADD COLUMN IF NOT EXISTS spectators INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS peak_spectators INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_games_live_spectators ON games(spectators DESC)
+    WHERE status = 'in_progress';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_games_live_spectators;
+ALTER TABLE games DROP COLUMN IF EXISTS peak_spectators;
+ALTER TABLE games DROP COLUMN IF EXISTS spectators;
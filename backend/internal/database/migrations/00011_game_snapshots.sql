@@ -0,0 +1,14 @@
+-- The move log is the source of truth for a game's state. Snapshots of the
+-- state every few moves let it be rebuilt without replaying from the start.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS game_snapshots (
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    move_number INTEGER NOT NULL,
+    state JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, move_number)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS game_snapshots;
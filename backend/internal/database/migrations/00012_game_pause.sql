@@ -0,0 +1,25 @@
+-- Paused games: the turn clock stops while paused_at is set, and is moved
+-- on by the length of the pause when the game resumes. turn_started_at is
+-- when the current turn's clock started, shifted by any pauses.
+
+-- +goose Up
+ALTER TABLE games ADD COLUMN IF NOT EXISTS paused_at TIMESTAMP;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS paused_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS pause_requested_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS turn_started_at TIMESTAMP;
+
+UPDATE games SET turn_started_at = last_move_at WHERE status = 'in_progress';
+
+DROP INDEX IF EXISTS idx_games_turn_started;
+CREATE INDEX IF NOT EXISTS idx_games_turn_started ON games ((COALESCE(turn_started_at, started_at)))
+    WHERE status = 'in_progress' AND paused_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_games_turn_started;
+CREATE INDEX IF NOT EXISTS idx_games_turn_started ON games ((COALESCE(last_move_at, started_at)))
+    WHERE status = 'in_progress';
+
+ALTER TABLE games DROP COLUMN IF EXISTS turn_started_at;
+ALTER TABLE games DROP COLUMN IF EXISTS pause_requested_by;
+ALTER TABLE games DROP COLUMN IF EXISTS paused_by;
+ALTER TABLE games DROP COLUMN IF EXISTS paused_at;
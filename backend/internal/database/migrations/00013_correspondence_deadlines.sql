@@ -0,0 +1,17 @@
+-- Correspondence games: each move must be made by move_deadline, days
+-- rather than minutes away, which a scheduled job enforces in place of the
+-- live turn timer. deadline_reminded is set once the player to move has
+-- been reminded, and cleared by the next move.
+
+-- +goose Up
+ALTER TABLE games ADD COLUMN IF NOT EXISTS move_deadline TIMESTAMP;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS deadline_reminded BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_games_move_deadline ON games(move_deadline)
+    WHERE status = 'in_progress' AND paused_at IS NULL AND move_deadline IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_games_move_deadline;
+
+ALTER TABLE games DROP COLUMN IF EXISTS deadline_reminded;
+ALTER TABLE games DROP COLUMN IF EXISTS move_deadline;
@@ -0,0 +1,11 @@
+-- How each finished game ended. Games that finished before this was
+-- recorded have none.
+
+-- +goose Up
+ALTER TABLE games ADD COLUMN IF NOT EXISTS end_reason VARCHAR(20)
+    CHECK (end_reason IN ('checkmate', 'resignation', 'timeout', 'abandonment', 'agreement', 'blocked', 'domino', 'cancelled'));
+
+UPDATE games SET end_reason = 'cancelled' WHERE status = 'abandoned' AND started_at IS NULL;
+
+-- +goose Down
+ALTER TABLE games DROP COLUMN IF EXISTS end_reason;
@@ -0,0 +1,48 @@
+-- Casual games leave stats, ratings and leaderboards alone. Games played
+-- before this all counted, so they are rated.
+
+-- +goose Up
+ALTER TABLE games ADD COLUMN IF NOT EXISTS is_rated BOOLEAN NOT NULL DEFAULT true;
+
+DROP MATERIALIZED VIEW IF EXISTS leaderboard_ratings;
+CREATE MATERIALIZED VIEW leaderboard_ratings AS
+SELECT p.game_type, u.id AS user_id, u.username,
+    COALESCE(s.rating, 1000) AS rating,
+    COALESCE(s.games_played, 0) AS games_played,
+    COALESCE(s.games_won, 0) AS games_won,
+    RANK() OVER (PARTITION BY p.game_type ORDER BY COALESCE(s.rating, 1000) DESC) AS rank,
+    ROW_NUMBER() OVER (PARTITION BY p.game_type ORDER BY COALESCE(s.rating, 1000) DESC, u.id) AS position
+FROM (
+    SELECT game_type, player1_id AS user_id FROM games WHERE status = 'completed' AND is_rated
+    UNION
+    SELECT game_type, player2_id FROM games WHERE status = 'completed' AND is_rated AND player2_id IS NOT NULL
+) p
+JOIN users u ON u.id = p.user_id
+LEFT JOIN user_stats s ON s.user_id = u.id
+WHERE u.is_active = true;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_leaderboard_ratings_user ON leaderboard_ratings(game_type, user_id);
+CREATE INDEX IF NOT EXISTS idx_leaderboard_ratings_position ON leaderboard_ratings(game_type, position);
+
+-- +goose Down
+DROP MATERIALIZED VIEW IF EXISTS leaderboard_ratings;
+CREATE MATERIALIZED VIEW leaderboard_ratings AS
+SELECT p.game_type, u.id AS user_id, u.username,
+    COALESCE(s.rating, 1000) AS rating,
+    COALESCE(s.games_played, 0) AS games_played,
+    COALESCE(s.games_won, 0) AS games_won,
+    RANK() OVER (PARTITION BY p.game_type ORDER BY COALESCE(s.rating, 1000) DESC) AS rank,
+    ROW_NUMBER() OVER (PARTITION BY p.game_type ORDER BY COALESCE(s.rating, 1000) DESC, u.id) AS position
+FROM (
+    SELECT game_type, player1_id AS user_id FROM games WHERE status = 'completed'
+    UNION
+    SELECT game_type, player2_id FROM games WHERE status = 'completed' AND player2_id IS NOT NULL
+) p
+JOIN users u ON u.id = p.user_id
+LEFT JOIN user_stats s ON s.user_id = u.id
+WHERE u.is_active = true;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_leaderboard_ratings_user ON leaderboard_ratings(game_type, user_id);
+CREATE INDEX IF NOT EXISTS idx_leaderboard_ratings_position ON leaderboard_ratings(game_type, position);
+
+ALTER TABLE games DROP COLUMN IF EXISTS is_rated;
@@ -0,0 +1,9 @@
+-- Who can find and watch a game: everyone, anyone with its link, or the
+-- players' friends. Private games stay hidden whatever this says.
+
+-- +goose Up
+ALTER TABLE games ADD COLUMN IF NOT EXISTS visibility VARCHAR(16) NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'friends'));
+
+-- +goose Down
+ALTER TABLE games DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,36 @@
+-- Seats of each game's players, numbered from 1 in the order they sat
+-- down, with the color they play and their team where the game has them.
+-- The player1_id and player2_id columns stay as a copy of seats 1 and 2
+-- for the two-player engines.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS game_players (
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id),
+    seat SMALLINT NOT NULL CHECK (seat > 0),
+    color VARCHAR(16),
+    team SMALLINT,
+    PRIMARY KEY (game_id, seat),
+    UNIQUE (game_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_game_players_user ON game_players(user_id);
+
+INSERT INTO game_players (game_id, user_id, seat, color)
+SELECT id, player1_id, 1,
+    CASE WHEN game_type = 'chess' AND game_state->>'white_player' IS NOT NULL THEN
+        CASE WHEN game_state->>'white_player' = player1_id::text THEN 'white' ELSE 'black' END
+    END
+FROM games
+ON CONFLICT DO NOTHING;
+
+INSERT INTO game_players (game_id, user_id, seat, color)
+SELECT id, player2_id, 2,
+    CASE WHEN game_type = 'chess' AND game_state->>'white_player' IS NOT NULL THEN
+        CASE WHEN game_state->>'white_player' = player2_id::text THEN 'white' ELSE 'black' END
+    END
+FROM games WHERE player2_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS game_players;
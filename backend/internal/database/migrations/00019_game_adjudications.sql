@@ -0,0 +1,36 @@
+-- Players' requests for a moderator to settle a stuck or disputed game,
+-- and the moderator's decision: a winner, a draw, or voiding the game.
+-- Games can end by adjudication.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS game_adjudications (
+    id UUID PRIMARY KEY,
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    requester_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('stuck', 'abuse', 'wrong_result', 'other')),
+    details TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    decision VARCHAR(10) CHECK (decision IN ('winner', 'draw', 'void')),
+    winner_id UUID REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP,
+    resolved_by UUID REFERENCES users(id),
+    resolution_note TEXT NOT NULL DEFAULT ''
+);
+
+-- A game has at most one open request
+CREATE UNIQUE INDEX IF NOT EXISTS idx_game_adjudications_open ON game_adjudications(game_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_game_adjudications_status ON game_adjudications(status, created_at);
+
+ALTER TABLE games DROP CONSTRAINT IF EXISTS games_end_reason_check;
+ALTER TABLE games ADD CONSTRAINT games_end_reason_check
+    CHECK (end_reason IN ('checkmate', 'resignation', 'timeout', 'abandonment', 'agreement', 'blocked', 'domino', 'cancelled', 'adjudication'));
+
+-- +goose Down
+UPDATE games SET end_reason = NULL WHERE end_reason = 'adjudication';
+
+ALTER TABLE games DROP CONSTRAINT IF EXISTS games_end_reason_check;
+ALTER TABLE games ADD CONSTRAINT games_end_reason_check
+    CHECK (end_reason IN ('checkmate', 'resignation', 'timeout', 'abandonment', 'agreement', 'blocked', 'domino', 'cancelled'));
+
+DROP TABLE IF EXISTS game_adjudications;
@@ -0,0 +1,30 @@
+-- Turn reminders: one row per reminder sent, so each is sent once per game
+-- and turn however many instances run the scheduler. turn is the game's
+-- move_count when the turn began; reminder 0 is the correspondence deadline
+-- reminder and N the Nth reminder of a slow turn. Replaces
+-- games.deadline_reminded.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS turn_reminders (
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    turn INTEGER NOT NULL,
+    reminder INTEGER NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, turn, reminder)
+);
+
+INSERT INTO turn_reminders (game_id, turn, reminder)
+SELECT id, move_count, 0 FROM games WHERE deadline_reminded;
+
+ALTER TABLE games DROP COLUMN IF EXISTS deadline_reminded;
+
+-- +goose Down
+ALTER TABLE games ADD COLUMN IF NOT EXISTS deadline_reminded BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE games SET deadline_reminded = TRUE
+WHERE EXISTS (
+    SELECT 1 FROM turn_reminders r
+    WHERE r.game_id = games.id AND r.turn = games.move_count AND r.reminder = 0
+);
+
+DROP TABLE IF EXISTS turn_reminders;
@@ -0,0 +1,21 @@
+-- Glicko-2 ratings: alongside the rating, each player's rating deviation
+-- (how unsure the rating is) and volatility (how erratic their results
+-- are). Elo leaves both alone. Rating history records the deviation after
+-- each change, and the deviation and volatility before it so overturned
+-- results can be reverted.
+
+-- +goose Up
+ALTER TABLE user_stats ADD COLUMN IF NOT EXISTS rating_deviation DOUBLE PRECISION NOT NULL DEFAULT 350;
+ALTER TABLE user_stats ADD COLUMN IF NOT EXISTS rating_volatility DOUBLE PRECISION NOT NULL DEFAULT 0.06;
+
+ALTER TABLE rating_history ADD COLUMN IF NOT EXISTS deviation DOUBLE PRECISION;
+ALTER TABLE rating_history ADD COLUMN IF NOT EXISTS old_deviation DOUBLE PRECISION;
+ALTER TABLE rating_history ADD COLUMN IF NOT EXISTS old_volatility DOUBLE PRECISION;
+
+-- +goose Down
+ALTER TABLE rating_history DROP COLUMN IF EXISTS old_volatility;
+ALTER TABLE rating_history DROP COLUMN IF EXISTS old_deviation;
+ALTER TABLE rating_history DROP COLUMN IF EXISTS deviation;
+
+ALTER TABLE user_stats DROP COLUMN IF EXISTS rating_volatility;
+ALTER TABLE user_stats DROP COLUMN IF EXISTS rating_deviation;
@@ -0,0 +1,45 @@
+-- Scheduled tournaments players register for. A tournament's rounds start
+-- round_minutes apart from starts_at; each round's reminder is claimed in
+-- tournament_reminders so it is sent once.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tournaments (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    game_type VARCHAR(20) NOT NULL,
+    time_control VARCHAR(20) NOT NULL DEFAULT '',
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    rounds INTEGER NOT NULL CHECK (rounds > 0),
+    round_minutes INTEGER NOT NULL CHECK (round_minutes > 0),
+    capacity INTEGER NOT NULL CHECK (capacity >= 2),
+    min_rating INTEGER,
+    max_rating INTEGER,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournaments_starts_at ON tournaments(starts_at);
+
+CREATE TABLE IF NOT EXISTS tournament_registrations (
+    tournament_id UUID NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    registered_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tournament_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_registrations_user ON tournament_registrations(user_id);
+
+CREATE TABLE IF NOT EXISTS tournament_reminders (
+    tournament_id UUID NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round INTEGER NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tournament_id, round)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS tournament_reminders;
+DROP TABLE IF EXISTS tournament_registrations;
+DROP TABLE IF EXISTS tournaments;
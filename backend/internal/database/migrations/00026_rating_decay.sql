@@ -0,0 +1,18 @@
+-- Rating decay for inactive players: when each player last played a rated
+-- game, and when their rating last decayed, so it decays once per period.
+
+-- +goose Up
+ALTER TABLE user_stats ADD COLUMN IF NOT EXISTS last_rated_at TIMESTAMP;
+ALTER TABLE user_stats ADD COLUMN IF NOT EXISTS rating_decayed_at TIMESTAMP;
+
+UPDATE user_stats SET last_rated_at = (
+    SELECT MAX(h.created_at) FROM rating_history h WHERE h.user_id = user_stats.user_id AND h.game_id IS NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_stats_last_rated_at ON user_stats(last_rated_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_stats_last_rated_at;
+
+ALTER TABLE user_stats DROP COLUMN IF EXISTS rating_decayed_at;
+ALTER TABLE user_stats DROP COLUMN IF EXISTS last_rated_at;
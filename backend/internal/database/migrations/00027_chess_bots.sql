@@ -0,0 +1,37 @@
+-- Chess bots: an account for each difficulty level, seated in games against
+-- players who want an opponent now, and one row per bot move, so each is
+-- made once however many instances run the bots. turn is the game's
+-- move_count when the bot's turn began. Bot usernames are longer than
+-- chosen ones can be, so they cannot clash.
+
+-- +goose Up
+INSERT INTO users (id, email, username, password_hash)
+VALUES
+    ('00000000-0000-4000-8000-00000000b001', 'easy@bots.vibeboard.invalid', 'Vibeboard Chess Bot (Easy)', ''),
+    ('00000000-0000-4000-8000-00000000b002', 'medium@bots.vibeboard.invalid', 'Vibeboard Chess Bot (Medium)', ''),
+    ('00000000-0000-4000-8000-00000000b003', 'hard@bots.vibeboard.invalid', 'Vibeboard Chess Bot (Hard)', '')
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS bots (
+    level VARCHAR(20) PRIMARY KEY,
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO bots (level, user_id)
+VALUES
+    ('easy', '00000000-0000-4000-8000-00000000b001'),
+    ('medium', '00000000-0000-4000-8000-00000000b002'),
+    ('hard', '00000000-0000-4000-8000-00000000b003')
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS bot_moves (
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    turn INTEGER NOT NULL,
+    moved_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, turn)
+);
+
+-- +goose Down
+-- Bot accounts stay for the games they played
+DROP TABLE IF EXISTS bot_moves;
+DROP TABLE IF EXISTS bots;
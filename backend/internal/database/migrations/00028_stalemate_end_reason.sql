@@ -0,0 +1,13 @@
+-- Games can end in a stalemate, when a chess bot has no move.
+
+-- +goose Up
+ALTER TABLE games DROP CONSTRAINT IF EXISTS games_end_reason_check;
+ALTER TABLE games ADD CONSTRAINT games_end_reason_check
+    CHECK (end_reason IN ('checkmate', 'resignation', 'timeout', 'abandonment', 'agreement', 'blocked', 'domino', 'cancelled', 'adjudication', 'stalemate'));
+
+-- +goose Down
+UPDATE games SET end_reason = NULL WHERE end_reason = 'stalemate';
+
+ALTER TABLE games DROP CONSTRAINT IF EXISTS games_end_reason_check;
+ALTER TABLE games ADD CONSTRAINT games_end_reason_check
+    CHECK (end_reason IN ('checkmate', 'resignation', 'timeout', 'abandonment', 'agreement', 'blocked', 'domino', 'cancelled', 'adjudication'));
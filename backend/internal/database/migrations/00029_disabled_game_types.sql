@@ -0,0 +1,11 @@
+-- Game types an admin has turned off for new games. Kept in the database
+-- so every instance agrees.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS disabled_game_types (
+    game_type VARCHAR(20) PRIMARY KEY,
+    disabled_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS disabled_game_types;
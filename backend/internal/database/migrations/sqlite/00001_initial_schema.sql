@@ -0,0 +1,253 @@
+-- The Postgres schema, through migration 00007, for SQLite databases used
+-- in local development and tests. Postgres-only features are left out:
+-- trigram and pattern indexes, and game change notifications. Add a
+-- migration here alongside each Postgres one.
+
+-- +goose Up
+CREATE TABLE users (
+    id UUID PRIMARY KEY,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    username VARCHAR(50) UNIQUE NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    role VARCHAR(20) NOT NULL DEFAULT 'player' CHECK (role IN ('player', 'admin')),
+    avatar_url VARCHAR(500) NOT NULL DEFAULT '',
+    profile_visibility VARCHAR(20) NOT NULL DEFAULT 'public' CHECK (profile_visibility IN ('public', 'friends', 'private')),
+    username_changed_at TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE TABLE user_stats (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    games_played INTEGER NOT NULL DEFAULT 0,
+    games_won INTEGER NOT NULL DEFAULT 0,
+    games_lost INTEGER NOT NULL DEFAULT 0,
+    rating INTEGER NOT NULL DEFAULT 1000,
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE games (
+    id UUID PRIMARY KEY,
+    game_type VARCHAR(20) NOT NULL CHECK (game_type IN ('dominoes', 'chess')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('waiting', 'in_progress', 'completed', 'abandoned')),
+    player1_id UUID NOT NULL REFERENCES users(id),
+    player2_id UUID REFERENCES users(id),
+    winner_id UUID REFERENCES users(id),
+    current_turn UUID REFERENCES users(id),
+    game_state JSON NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    started_at TIMESTAMP,
+    ended_at TIMESTAMP,
+    is_private BOOLEAN NOT NULL DEFAULT false,
+    settings JSON NOT NULL DEFAULT '{}',
+    starter_id UUID REFERENCES users(id),
+    initial_state JSON,
+    move_count INTEGER NOT NULL DEFAULT 0,
+    last_move_at TIMESTAMP,
+    duration_seconds INTEGER
+);
+
+CREATE TABLE moves (
+    id UUID PRIMARY KEY,
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    player_id UUID NOT NULL REFERENCES users(id),
+    move_data JSON NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    is_valid BOOLEAN NOT NULL DEFAULT true,
+    kind VARCHAR(20) NOT NULL DEFAULT 'move' CHECK (kind IN ('move', 'timeout'))
+);
+
+CREATE TABLE user_blocks (
+    blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (blocker_id, blocked_id)
+);
+
+CREATE TABLE friendships (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    friend_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted')),
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (user_id, friend_id),
+    CHECK (user_id <> friend_id)
+);
+
+CREATE TABLE chat_messages (
+    id UUID PRIMARY KEY,
+    room_id VARCHAR(100) NOT NULL,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    deleted_at TIMESTAMP,
+    deleted_by UUID REFERENCES users(id)
+);
+
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reported_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('harassment', 'cheating', 'spam', 'offensive_name', 'other')),
+    details TEXT NOT NULL DEFAULT '',
+    game_id UUID REFERENCES games(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    context JSON NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    resolved_at TIMESTAMP,
+    resolved_by UUID REFERENCES users(id),
+    resolution_note TEXT NOT NULL DEFAULT '',
+    chat_message_id UUID REFERENCES chat_messages(id) ON DELETE SET NULL
+);
+
+CREATE TABLE direct_messages (
+    id UUID PRIMARY KEY,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE user_mutes (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    muted_until TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    muted_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE user_identities (
+    provider VARCHAR(20) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE TABLE login_events (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    method VARCHAR(20) NOT NULL,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    device_hash VARCHAR(64) NOT NULL,
+    country VARCHAR(2) NOT NULL DEFAULT '',
+    suspicious BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE trusted_devices (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    last_used_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (user_id, device_hash)
+);
+
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY,
+    actor_id UUID NOT NULL REFERENCES users(id),
+    action VARCHAR(50) NOT NULL,
+    target_type VARCHAR(20) NOT NULL,
+    target_id VARCHAR(100) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    before JSON,
+    after JSON,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE rating_history (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    game_id UUID REFERENCES games(id) ON DELETE SET NULL,
+    game_type VARCHAR(50) NOT NULL DEFAULT '',
+    system VARCHAR(20) NOT NULL,
+    old_rating INTEGER NOT NULL,
+    new_rating INTEGER NOT NULL,
+    delta INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX idx_users_email ON users(email);
+CREATE INDEX idx_users_username ON users(username);
+CREATE INDEX idx_users_username_lower ON users(lower(username)) WHERE deleted_at IS NULL;
+CREATE INDEX idx_games_status ON games(status);
+CREATE INDEX idx_games_type ON games(game_type);
+CREATE INDEX idx_games_player1 ON games(player1_id);
+CREATE INDEX idx_games_player2 ON games(player2_id);
+CREATE INDEX idx_games_created_at ON games(created_at);
+CREATE INDEX idx_games_turn_started ON games(COALESCE(last_move_at, started_at)) WHERE status = 'in_progress';
+CREATE INDEX idx_moves_game_id ON moves(game_id);
+CREATE INDEX idx_moves_player_id ON moves(player_id);
+CREATE INDEX idx_moves_created_at ON moves(created_at);
+CREATE INDEX idx_user_blocks_blocked ON user_blocks(blocked_id);
+CREATE INDEX idx_friendships_friend ON friendships(friend_id);
+CREATE UNIQUE INDEX idx_friendships_pair ON friendships(MIN(user_id, friend_id), MAX(user_id, friend_id));
+CREATE INDEX idx_reports_status ON reports(status, created_at);
+CREATE INDEX idx_direct_messages_recipient ON direct_messages(recipient_id, created_at);
+CREATE INDEX idx_direct_messages_sender ON direct_messages(sender_id, created_at);
+CREATE INDEX idx_chat_messages_room ON chat_messages(room_id, created_at);
+CREATE INDEX idx_chat_messages_sender ON chat_messages(sender_id, created_at);
+CREATE INDEX idx_user_identities_user ON user_identities(user_id);
+CREATE INDEX idx_login_events_user ON login_events(user_id, created_at);
+CREATE INDEX idx_audit_log_created ON audit_log(created_at);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor_id, created_at);
+CREATE INDEX idx_audit_log_target ON audit_log(target_type, target_id, created_at);
+CREATE INDEX idx_rating_history_user ON rating_history(user_id, created_at);
+
+-- +goose StatementBegin
+CREATE TRIGGER update_users_updated_at AFTER UPDATE ON users FOR EACH ROW
+BEGIN
+    UPDATE users SET updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now') WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER update_user_stats_updated_at AFTER UPDATE ON user_stats FOR EACH ROW
+BEGIN
+    UPDATE user_stats SET updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now') WHERE user_id = NEW.user_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER update_games_updated_at AFTER UPDATE ON games FOR EACH ROW
+BEGIN
+    UPDATE games SET updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now') WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS rating_history;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS trusted_devices;
+DROP TABLE IF EXISTS login_events;
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS user_mutes;
+DROP TABLE IF EXISTS reports;
+DROP TABLE IF EXISTS chat_messages;
+DROP TABLE IF EXISTS direct_messages;
+DROP TABLE IF EXISTS friendships;
+DROP TABLE IF EXISTS user_blocks;
+DROP TABLE IF EXISTS moves;
+DROP TABLE IF EXISTS games;
+DROP TABLE IF EXISTS user_stats;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,20 @@
+-- SQLite has no materialized views, so the ranked leaderboards are a table
+-- the server rebuilds; see DB.RefreshLeaderboards.
+
+-- +goose Up
+CREATE TABLE leaderboard_ratings (
+    game_type VARCHAR(20) NOT NULL,
+    user_id UUID NOT NULL,
+    username VARCHAR(50) NOT NULL,
+    rating INTEGER NOT NULL,
+    games_played INTEGER NOT NULL,
+    games_won INTEGER NOT NULL,
+    rank INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (game_type, user_id)
+);
+
+CREATE INDEX idx_leaderboard_ratings_position ON leaderboard_ratings(game_type, position);
+
+-- +goose Down
+DROP TABLE IF EXISTS leaderboard_ratings;
@@ -0,0 +1,15 @@
+-- Indexes for the retention janitor, which deletes rows by age.
+
+-- +goose Up
+CREATE INDEX IF NOT EXISTS idx_chat_messages_created_at ON chat_messages(created_at);
+CREATE INDEX IF NOT EXISTS idx_direct_messages_created_at ON direct_messages(created_at);
+CREATE INDEX IF NOT EXISTS idx_login_events_created_at ON login_events(created_at);
+CREATE INDEX IF NOT EXISTS idx_trusted_devices_last_used ON trusted_devices(last_used_at);
+CREATE INDEX IF NOT EXISTS idx_reports_chat_message ON reports(chat_message_id) WHERE chat_message_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_reports_chat_message;
+DROP INDEX IF EXISTS idx_trusted_devices_last_used;
+DROP INDEX IF EXISTS idx_login_events_created_at;
+DROP INDEX IF EXISTS idx_direct_messages_created_at;
+DROP INDEX IF EXISTS idx_chat_messages_created_at;
@@ -0,0 +1,42 @@
+-- Best-of-N series: games between the same two players linked into a
+-- match, each game numbered by series_game. The series keeps its running
+-- score, and players' user_stats count the series they played and won.
+
+-- +goose Up
+CREATE TABLE series (
+    id UUID PRIMARY KEY,
+    game_type VARCHAR(20) NOT NULL,
+    player1_id UUID NOT NULL REFERENCES users(id),
+    player2_id UUID NOT NULL REFERENCES users(id),
+    best_of INTEGER NOT NULL CHECK (best_of > 1),
+    settings JSON NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'completed', 'abandoned')),
+    games_played INTEGER NOT NULL DEFAULT 0,
+    player1_wins INTEGER NOT NULL DEFAULT 0,
+    player2_wins INTEGER NOT NULL DEFAULT 0,
+    draws INTEGER NOT NULL DEFAULT 0,
+    winner_id UUID REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    ended_at TIMESTAMP
+);
+
+CREATE INDEX idx_series_player1 ON series(player1_id, created_at DESC);
+CREATE INDEX idx_series_player2 ON series(player2_id, created_at DESC);
+
+ALTER TABLE games ADD COLUMN series_id UUID REFERENCES series(id) ON DELETE SET NULL;
+ALTER TABLE games ADD COLUMN series_game INTEGER;
+
+CREATE INDEX idx_games_series ON games(series_id, series_game) WHERE series_id IS NOT NULL;
+
+ALTER TABLE user_stats ADD COLUMN series_played INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_stats ADD COLUMN series_won INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE user_stats DROP COLUMN series_won;
+ALTER TABLE user_stats DROP COLUMN series_played;
+
+DROP INDEX IF EXISTS idx_games_series;
+ALTER TABLE games DROP COLUMN series_game;
+ALTER TABLE games DROP COLUMN series_id;
+
+DROP TABLE IF EXISTS series;
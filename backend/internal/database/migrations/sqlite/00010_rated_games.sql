@@ -0,0 +1,8 @@
+-- Casual games leave stats, ratings and leaderboards alone. Games played
+-- before this all counted, so they are rated.
+
+-- +goose Up
+ALTER TABLE games ADD COLUMN is_rated BOOLEAN NOT NULL DEFAULT true;
+
+-- +goose Down
+ALTER TABLE games DROP COLUMN is_rated;
@@ -0,0 +1,38 @@
+-- Players' requests for a moderator to settle a stuck or disputed game,
+-- and the moderator's decision: a winner, a draw, or voiding the game.
+-- Games can end by adjudication. SQLite cannot change a column's CHECK,
+-- so end_reason is replaced by a copy with the new one.
+
+-- +goose Up
+CREATE TABLE game_adjudications (
+    id UUID PRIMARY KEY,
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    requester_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('stuck', 'abuse', 'wrong_result', 'other')),
+    details TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    decision VARCHAR(10) CHECK (decision IN ('winner', 'draw', 'void')),
+    winner_id UUID REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    resolved_at TIMESTAMP,
+    resolved_by UUID REFERENCES users(id),
+    resolution_note TEXT NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX idx_game_adjudications_open ON game_adjudications(game_id) WHERE status = 'open';
+CREATE INDEX idx_game_adjudications_status ON game_adjudications(status, created_at);
+
+ALTER TABLE games RENAME COLUMN end_reason TO end_reason_old;
+ALTER TABLE games ADD COLUMN end_reason VARCHAR(20)
+    CHECK (end_reason IN ('checkmate', 'resignation', 'timeout', 'abandonment', 'agreement', 'blocked', 'domino', 'cancelled', 'adjudication'));
+UPDATE games SET end_reason = end_reason_old;
+ALTER TABLE games DROP COLUMN end_reason_old;
+
+-- +goose Down
+ALTER TABLE games RENAME COLUMN end_reason TO end_reason_old;
+ALTER TABLE games ADD COLUMN end_reason VARCHAR(20)
+    CHECK (end_reason IN ('checkmate', 'resignation', 'timeout', 'abandonment', 'agreement', 'blocked', 'domino', 'cancelled'));
+UPDATE games SET end_reason = end_reason_old WHERE end_reason_old <> 'adjudication';
+ALTER TABLE games DROP COLUMN end_reason_old;
+
+DROP TABLE IF EXISTS game_adjudications;
@@ -0,0 +1,24 @@
+-- Tags on games, such as tournament or study games, for filtering game
+-- listings, and the listing filters users save for their game browser.
+
+-- +goose Up
+CREATE TABLE game_tags (
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    tag VARCHAR(20) NOT NULL CHECK (tag IN ('tournament', 'friendly', 'study')),
+    PRIMARY KEY (game_id, tag)
+);
+
+CREATE INDEX idx_game_tags_tag ON game_tags(tag, game_id);
+
+CREATE TABLE saved_game_filters (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    filter JSON NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    UNIQUE (user_id, name)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS saved_game_filters;
+DROP TABLE IF EXISTS game_tags;
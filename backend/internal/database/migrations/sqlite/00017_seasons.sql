@@ -0,0 +1,48 @@
+-- Competitive seasons. Each season keeps its own rating per player and game
+-- type, starting from a soft reset of the player's rating in their last
+-- season, and grants rewards by final rank when it ends (finalized_at).
+
+-- +goose Up
+CREATE TABLE seasons (
+    id UUID PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    finalized_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_seasons_starts_at ON seasons(starts_at);
+
+CREATE TABLE season_ratings (
+    season_id UUID NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    game_type VARCHAR(20) NOT NULL,
+    rating INTEGER NOT NULL,
+    games_played INTEGER NOT NULL DEFAULT 0,
+    games_won INTEGER NOT NULL DEFAULT 0,
+    games_lost INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (season_id, game_type, user_id)
+);
+
+CREATE INDEX idx_season_ratings_user ON season_ratings(user_id, game_type);
+
+CREATE TABLE season_rewards (
+    season_id UUID NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    game_type VARCHAR(20) NOT NULL,
+    reward VARCHAR(20) NOT NULL CHECK (reward IN ('champion', 'top_10', 'top_100', 'participant')),
+    rank INTEGER NOT NULL,
+    rating INTEGER NOT NULL,
+    granted_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (season_id, game_type, user_id)
+);
+
+CREATE INDEX idx_season_rewards_user ON season_rewards(user_id, granted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS season_rewards;
+DROP TABLE IF EXISTS season_ratings;
+DROP TABLE IF EXISTS seasons;
@@ -0,0 +1,42 @@
+-- Ranked tiers: each player's count of rated games, and the tier and
+-- division those games have placed them in ('unranked' until their
+-- placement games are done). Rating history records the tier before each
+-- change so overturned results can be reverted. Existing players are
+-- placed by their rating.
+
+-- +goose Up
+ALTER TABLE user_stats ADD COLUMN rated_games INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_stats ADD COLUMN tier VARCHAR(20) NOT NULL DEFAULT 'unranked';
+ALTER TABLE user_stats ADD COLUMN division INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE rating_history ADD COLUMN old_tier VARCHAR(20);
+ALTER TABLE rating_history ADD COLUMN old_division INTEGER;
+
+UPDATE user_stats SET rated_games = (
+    SELECT COUNT(*) FROM rating_history h WHERE h.user_id = user_stats.user_id AND h.game_id IS NOT NULL
+);
+
+UPDATE user_stats SET
+    tier = CASE
+        WHEN rating >= 1900 THEN 'grandmaster'
+        WHEN rating >= 1700 THEN 'master'
+        WHEN rating >= 1500 THEN 'diamond'
+        WHEN rating >= 1300 THEN 'platinum'
+        WHEN rating >= 1100 THEN 'gold'
+        WHEN rating >= 900 THEN 'silver'
+        ELSE 'bronze'
+    END,
+    division = CASE
+        WHEN rating >= 1700 THEN 0
+        WHEN rating < 750 THEN 4
+        ELSE 4 - ((rating - 700) % 200) / 50
+    END
+WHERE rated_games >= 5;
+
+-- +goose Down
+ALTER TABLE rating_history DROP COLUMN old_division;
+ALTER TABLE rating_history DROP COLUMN old_tier;
+
+ALTER TABLE user_stats DROP COLUMN division;
+ALTER TABLE user_stats DROP COLUMN tier;
+ALTER TABLE user_stats DROP COLUMN rated_games;
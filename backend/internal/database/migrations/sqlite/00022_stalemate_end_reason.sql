@@ -0,0 +1,17 @@
+-- Games can end in a stalemate, when a chess bot has no move. SQLite
+-- cannot change a column's CHECK, so end_reason is replaced by a copy with
+-- the new one.
+
+-- +goose Up
+ALTER TABLE games RENAME COLUMN end_reason TO end_reason_old;
+ALTER TABLE games ADD COLUMN end_reason VARCHAR(20)
+    CHECK (end_reason IN ('checkmate', 'resignation', 'timeout', 'abandonment', 'agreement', 'blocked', 'domino', 'cancelled', 'adjudication', 'stalemate'));
+UPDATE games SET end_reason = end_reason_old;
+ALTER TABLE games DROP COLUMN end_reason_old;
+
+-- +goose Down
+ALTER TABLE games RENAME COLUMN end_reason TO end_reason_old;
+ALTER TABLE games ADD COLUMN end_reason VARCHAR(20)
+    CHECK (end_reason IN ('checkmate', 'resignation', 'timeout', 'abandonment', 'agreement', 'blocked', 'domino', 'cancelled', 'adjudication'));
+UPDATE games SET end_reason = end_reason_old WHERE end_reason_old <> 'stalemate';
+ALTER TABLE games DROP COLUMN end_reason_old;
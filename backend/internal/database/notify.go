@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// gameChangeChannel is notified by the games table trigger; see migration
+// 00007_game_change_notify.
+const gameChangeChannel = "game_changed"
+
+// listenerPingInterval bounds how long a silently dropped listener
+// connection goes unnoticed.
+const listenerPingInterval = time.Minute
+
+// ListenGameChanges calls handle with every game change the database
+// announces until ctx is done. It holds its own connection and reconnects
+// when it is lost; handle then gets nil, since changes may have been missed.
+func (db *DB) ListenGameChanges(ctx context.Context, handle func(*models.GameChange)) error {
+	if db.driver != DriverPostgres {
+		return errors.New("game change events need Postgres")
+	}
+
+	listener := pq.NewListener(db.dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Game change listener: %v", err)
+		}
+	})
+	defer func() {
+		if err := listener.Close(); err != nil {
+			log.Printf("Error closing game change listener: %v", err)
+		}
+	}()
+
+	if err := listener.Listen(gameChangeChannel); err != nil {
+		return fmt.Errorf("failed to listen for game changes: %w", err)
+	}
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification := <-listener.Notify:
+			if notification == nil {
+				handle(nil)
+				continue
+			}
+			var change models.GameChange
+			if err := json.Unmarshal([]byte(notification.Extra), &change); err != nil {
+				log.Printf("Error decoding game change: %v", err)
+				continue
+			}
+			handle(&change)
+		case <-ticker.C:
+			go func() {
+				if err := listener.Ping(); err != nil {
+					log.Printf("Game change listener ping failed: %v", err)
+				}
+			}()
+		}
+	}
+}
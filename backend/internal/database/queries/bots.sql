@@ -0,0 +1,23 @@
+-- name: ListBots :many
+SELECT b.level, b.user_id, u.username FROM bots b
+JOIN users u ON u.id = b.user_id
+ORDER BY b.user_id;
+
+-- name: ListBotTurnGames :many
+SELECT g.* FROM games g
+JOIN bots b ON b.user_id = g.current_turn
+WHERE g.status = @status AND g.paused_at IS NULL;
+
+-- Claims a bot's move of the turn, affecting no rows if another claim
+-- was made after stale_before.
+-- name: ClaimBotMove :execrows
+INSERT INTO bot_moves (game_id, turn, moved_at)
+VALUES (@game_id, @turn, @moved_at)
+ON CONFLICT (game_id, turn) DO UPDATE SET moved_at = excluded.moved_at
+WHERE bot_moves.moved_at < @stale_before::timestamp;
+
+-- name: ReleaseBotMove :exec
+DELETE FROM bot_moves WHERE game_id = @game_id AND turn = @turn;
+
+-- name: DeleteBotMovesBefore :exec
+DELETE FROM bot_moves WHERE game_id = @game_id AND turn < @turn;
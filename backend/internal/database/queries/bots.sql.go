@@ -0,0 +1,167 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: bots.sql
+
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+const claimBotMove = `-- name: ClaimBotMove :execrows
+INSERT INTO bot_moves (game_id, turn, moved_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (game_id, turn) DO UPDATE SET moved_at = excluded.moved_at
+WHERE bot_moves.moved_at < $4::timestamp
+`
+
+type ClaimBotMoveParams struct {
+	GameID      uuid.UUID
+	Turn        int32
+	MovedAt     time.Time
+	StaleBefore time.Time
+}
+
+// Claims a bot's move of the turn, affecting no rows if another claim
+// was made after stale_before.
+func (q *Queries) ClaimBotMove(ctx context.Context, arg ClaimBotMoveParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimBotMove,
+		arg.GameID,
+		arg.Turn,
+		arg.MovedAt,
+		arg.StaleBefore,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteBotMovesBefore = `-- name: DeleteBotMovesBefore :exec
+DELETE FROM bot_moves WHERE game_id = $1 AND turn < $2
+`
+
+type DeleteBotMovesBeforeParams struct {
+	GameID uuid.UUID
+	Turn   int32
+}
+
+func (q *Queries) DeleteBotMovesBefore(ctx context.Context, arg DeleteBotMovesBeforeParams) error {
+	_, err := q.db.ExecContext(ctx, deleteBotMovesBefore, arg.GameID, arg.Turn)
+	return err
+}
+
+const listBotTurnGames = `-- name: ListBotTurnGames :many
+SELECT g.id, g.game_type, g.status, g.player1_id, g.player2_id, g.winner_id, g.current_turn, g.game_state, g.created_at, g.updated_at, g.started_at, g.ended_at, g.is_private, g.settings, g.starter_id, g.initial_state, g.move_count, g.last_move_at, g.duration_seconds, g.spectators, g.peak_spectators, g.paused_at, g.paused_by, g.pause_requested_by, g.turn_started_at, g.move_deadline, g.series_id, g.series_game, g.end_reason, g.is_rated, g.visibility FROM games g
+JOIN bots b ON b.user_id = g.current_turn
+WHERE g.status = $1 AND g.paused_at IS NULL
+`
+
+func (q *Queries) ListBotTurnGames(ctx context.Context, status models.GameStatus) ([]Game, error) {
+	rows, err := q.db.QueryContext(ctx, listBotTurnGames, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Game
+	for rows.Next() {
+		var i Game
+		if err := rows.Scan(
+			&i.ID,
+			&i.GameType,
+			&i.Status,
+			&i.Player1ID,
+			&i.Player2ID,
+			&i.WinnerID,
+			&i.CurrentTurn,
+			&i.GameState,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.IsPrivate,
+			&i.Settings,
+			&i.StarterID,
+			&i.InitialState,
+			&i.MoveCount,
+			&i.LastMoveAt,
+			&i.DurationSeconds,
+			&i.Spectators,
+			&i.PeakSpectators,
+			&i.PausedAt,
+			&i.PausedBy,
+			&i.PauseRequestedBy,
+			&i.TurnStartedAt,
+			&i.MoveDeadline,
+			&i.SeriesID,
+			&i.SeriesGame,
+			&i.EndReason,
+			&i.IsRated,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBots = `-- name: ListBots :many
+SELECT b.level, b.user_id, u.username FROM bots b
+JOIN users u ON u.id = b.user_id
+ORDER BY b.user_id
+`
+
+type ListBotsRow struct {
+	Level    models.BotLevel
+	UserID   uuid.UUID
+	Username string
+}
+
+func (q *Queries) ListBots(ctx context.Context) ([]ListBotsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBots)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBotsRow
+	for rows.Next() {
+		var i ListBotsRow
+		if err := rows.Scan(&i.Level, &i.UserID, &i.Username); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseBotMove = `-- name: ReleaseBotMove :exec
+DELETE FROM bot_moves WHERE game_id = $1 AND turn = $2
+`
+
+type ReleaseBotMoveParams struct {
+	GameID uuid.UUID
+	Turn   int32
+}
+
+func (q *Queries) ReleaseBotMove(ctx context.Context, arg ReleaseBotMoveParams) error {
+	_, err := q.db.ExecContext(ctx, releaseBotMove, arg.GameID, arg.Turn)
+	return err
+}
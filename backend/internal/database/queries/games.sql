@@ -0,0 +1,188 @@
+-- name: CreateGame :exec
+INSERT INTO games (id, game_type, status, player1_id, player2_id, winner_id, current_turn, game_state, created_at, updated_at, started_at, ended_at, is_private, settings, starter_id, initial_state, move_deadline, series_id, series_game, is_rated, visibility)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21);
+
+-- name: GetGame :one
+SELECT * FROM games WHERE id = $1;
+
+-- name: UpdateGame :exec
+UPDATE games SET game_type = $2, status = $3, player1_id = $4, player2_id = $5, winner_id = $6,
+    current_turn = $7, game_state = $8, updated_at = $9, started_at = $10, ended_at = $11, starter_id = $12,
+    initial_state = $13, duration_seconds = $14, move_deadline = $15, series_id = $16, series_game = $17,
+    end_reason = $18
+WHERE id = $1;
+
+-- A move's history entry is the game's last move; timeouts count too. It
+-- also starts the next turn's clock. Only a game still in progress at the
+-- move count the move was made against is counted, so a move or timeout
+-- racing another returns no row.
+-- name: CountMove :one
+UPDATE games SET move_count = move_count + 1, last_move_at = @moved_at, turn_started_at = @moved_at
+WHERE id = @id AND move_count = @move_count AND status = @in_progress
+RETURNING move_count;
+
+-- name: RecordGameSpectators :exec
+UPDATE games SET spectators = @spectators, peak_spectators = GREATEST(peak_spectators, @spectators)
+WHERE id = @id;
+
+-- Private games are only reachable through their join code. A null status
+-- or game type matches any.
+-- Friends-only games are listed to their players and the players' friends.
+-- name: ListPublicGames :many
+SELECT * FROM games
+WHERE is_private = false
+    AND (visibility = 'public' OR (visibility = 'friends' AND EXISTS (
+        SELECT 1 FROM game_players p
+        WHERE p.game_id = games.id AND (p.user_id = @viewer_id OR EXISTS (
+            SELECT 1 FROM friendships f
+            WHERE f.status = 'accepted'
+                AND ((f.user_id = @viewer_id AND f.friend_id = p.user_id)
+                    OR (f.friend_id = @viewer_id AND f.user_id = p.user_id))
+        ))
+    )))
+    AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status')::text)
+    AND (sqlc.narg('game_type')::text IS NULL OR game_type = sqlc.narg('game_type')::text)
+    AND (sqlc.narg('rated')::boolean IS NULL OR is_rated = sqlc.narg('rated')::boolean)
+    AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+        SELECT 1 FROM game_tags t WHERE t.game_id = games.id AND t.tag = sqlc.narg('tag')::text
+    ))
+ORDER BY created_at DESC
+LIMIT @row_limit OFFSET @row_offset;
+
+-- The current turn's clock started with the last move, or with the game if
+-- no move has been made, and stops while the game is paused. Correspondence
+-- games run on their move deadlines instead.
+-- name: ListTimedOutGames :many
+SELECT * FROM games
+WHERE status = @status AND current_turn IS NOT NULL AND paused_at IS NULL AND move_deadline IS NULL
+    AND COALESCE(turn_started_at, started_at) < @cutoff::timestamp
+ORDER BY COALESCE(turn_started_at, started_at) ASC;
+
+-- Paused and correspondence games are left out, as their players may be
+-- away.
+-- name: ListGamesStartedBefore :many
+SELECT * FROM games
+WHERE status = @status AND paused_at IS NULL AND move_deadline IS NULL AND started_at < @started_before::timestamp
+ORDER BY started_at ASC;
+
+-- A player asks to pause a running game or to resume one the players
+-- paused; paused says which.
+-- name: RequestPauseChange :execrows
+UPDATE games SET pause_requested_by = @requested_by, updated_at = NOW()
+WHERE id = @id AND status = @in_progress AND pause_requested_by IS NULL AND paused_by IS NULL
+    AND (paused_at IS NOT NULL) = @paused::boolean;
+
+-- name: CancelPauseRequest :execrows
+UPDATE games SET pause_requested_by = NULL, updated_at = NOW()
+WHERE id = @id AND status = @in_progress AND pause_requested_by IS NOT NULL;
+
+-- The other player agrees to a requested pause.
+-- name: AcceptPause :execrows
+UPDATE games SET paused_at = @paused_at, pause_requested_by = NULL, updated_at = NOW()
+WHERE id = @id AND status = @in_progress AND paused_at IS NULL AND pause_requested_by = @requested_by;
+
+-- An admin pauses a game, taking over a pause the players agreed to.
+-- name: ForcePause :execrows
+UPDATE games SET paused_at = COALESCE(paused_at, @paused_at), paused_by = @paused_by,
+    pause_requested_by = NULL, updated_at = NOW()
+WHERE id = @id AND status = @in_progress AND paused_by IS NULL;
+
+-- Resumes the pause that started at paused_at. A requester is the player
+-- whose resume request the other agreed to; admins resume without one.
+-- name: ResumeGame :execrows
+UPDATE games SET paused_at = NULL, paused_by = NULL, pause_requested_by = NULL,
+    turn_started_at = @turn_started_at, move_deadline = sqlc.narg('move_deadline'), updated_at = NOW()
+WHERE id = @id AND status = @in_progress AND paused_at = @paused_at
+    AND (sqlc.narg('requested_by')::uuid IS NULL OR pause_requested_by = sqlc.narg('requested_by')::uuid);
+
+-- Correspondence games whose player to move has missed the deadline.
+-- name: ListOverdueGames :many
+SELECT * FROM games
+WHERE status = @status AND current_turn IS NOT NULL AND paused_at IS NULL
+    AND move_deadline < @now::timestamp
+ORDER BY move_deadline ASC;
+
+-- Games whose player to move may be due a reminder: live games whose turn
+-- started before started_before, and correspondence games whose deadline
+-- falls before deadline_before.
+-- name: ListAwaitingTurnGames :many
+SELECT * FROM games
+WHERE status = @status AND current_turn IS NOT NULL AND paused_at IS NULL
+    AND ((move_deadline IS NULL AND COALESCE(turn_started_at, started_at) < @started_before::timestamp)
+        OR move_deadline < @deadline_before::timestamp);
+
+-- Records a turn's reminder as sent, affecting no rows if it already was.
+-- name: ClaimTurnReminder :execrows
+INSERT INTO turn_reminders (game_id, turn, reminder, sent_at)
+VALUES (@game_id, @turn, @reminder, @sent_at)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteTurnRemindersBefore :exec
+DELETE FROM turn_reminders WHERE game_id = @game_id AND turn < @turn;
+
+-- name: ListSeriesGames :many
+SELECT * FROM games WHERE series_id = $1 ORDER BY series_game ASC;
+
+-- name: CancelGame :execrows
+UPDATE games SET status = @abandoned, ended_at = NOW(), end_reason = 'cancelled'
+WHERE id = @id AND player1_id = @creator_id AND status = @waiting;
+
+-- name: EndGame :execrows
+UPDATE games
+SET status = @status, winner_id = @winner_id, current_turn = NULL, ended_at = @ended_at,
+    duration_seconds = @duration_seconds, end_reason = @end_reason
+WHERE id = @id AND status = @in_progress;
+
+-- Changes the result of a completed game.
+-- name: OverturnGameResult :execrows
+UPDATE games
+SET status = @status, winner_id = @winner_id, end_reason = @end_reason, updated_at = NOW()
+WHERE id = @id AND status = @completed;
+
+-- name: SetGameVisibility :execrows
+UPDATE games SET visibility = @visibility, updated_at = NOW() WHERE id = @id;
+
+-- Seats a player. A player who keeps their seat keeps its color and team
+-- unless new ones are given.
+-- name: SeatGamePlayer :exec
+INSERT INTO game_players (game_id, user_id, seat, color, team)
+VALUES (@game_id, @user_id, @seat, @color, @team)
+ON CONFLICT (game_id, seat) DO UPDATE SET
+    user_id = excluded.user_id,
+    color = CASE WHEN game_players.user_id = excluded.user_id THEN COALESCE(excluded.color, game_players.color) ELSE excluded.color END,
+    team = CASE WHEN game_players.user_id = excluded.user_id THEN COALESCE(excluded.team, game_players.team) ELSE excluded.team END;
+
+-- Holds a waiting game's row while a player takes a seat, so the game
+-- cannot be cancelled or started underneath them. A game no longer waiting
+-- returns no row.
+-- name: HoldWaitingGame :execrows
+UPDATE games SET player2_id = @player2_id, updated_at = NOW()
+WHERE id = @id AND status = @waiting;
+
+-- A seat already taken, or a player already seated, inserts no row.
+-- name: ClaimGameSeat :execrows
+INSERT INTO game_players (game_id, user_id, seat)
+VALUES (@game_id, @user_id, @seat)
+ON CONFLICT DO NOTHING;
+
+-- name: ReleaseGameSeats :exec
+DELETE FROM game_players WHERE game_id = @game_id AND seat > @seats;
+
+-- name: ListGamePlayers :many
+SELECT * FROM game_players WHERE game_id = $1 ORDER BY seat;
+
+-- Moves updated_at on, so clients polling the game see the change.
+-- name: TouchGame :execrows
+UPDATE games SET updated_at = NOW() WHERE id = @id;
+
+-- name: TagGame :exec
+INSERT INTO game_tags (game_id, tag) VALUES (@game_id, @tag)
+ON CONFLICT DO NOTHING;
+
+-- name: ClearGameTags :exec
+DELETE FROM game_tags WHERE game_id = @game_id;
+
+-- name: ListGameTags :many
+SELECT game_id, tag FROM game_tags
+WHERE game_id = ANY(@game_ids::uuid[])
+ORDER BY game_id, tag;
@@ -0,0 +1,1110 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: games.sql
+
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+const acceptPause = `-- name: AcceptPause :execrows
+UPDATE games SET paused_at = $1, pause_requested_by = NULL, updated_at = NOW()
+WHERE id = $2 AND status = $3 AND paused_at IS NULL AND pause_requested_by = $4
+`
+
+type AcceptPauseParams struct {
+	PausedAt    *time.Time
+	ID          uuid.UUID
+	InProgress  models.GameStatus
+	RequestedBy *uuid.UUID
+}
+
+// The other player agrees to a requested pause.
+func (q *Queries) AcceptPause(ctx context.Context, arg AcceptPauseParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, acceptPause,
+		arg.PausedAt,
+		arg.ID,
+		arg.InProgress,
+		arg.RequestedBy,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const cancelGame = `-- name: CancelGame :execrows
+UPDATE games SET status = $1, ended_at = NOW(), end_reason = 'cancelled'
+WHERE id = $2 AND player1_id = $3 AND status = $4
+`
+
+type CancelGameParams struct {
+	Abandoned models.GameStatus
+	ID        uuid.UUID
+	CreatorID uuid.UUID
+	Waiting   models.GameStatus
+}
+
+func (q *Queries) CancelGame(ctx context.Context, arg CancelGameParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelGame,
+		arg.Abandoned,
+		arg.ID,
+		arg.CreatorID,
+		arg.Waiting,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const cancelPauseRequest = `-- name: CancelPauseRequest :execrows
+UPDATE games SET pause_requested_by = NULL, updated_at = NOW()
+WHERE id = $1 AND status = $2 AND pause_requested_by IS NOT NULL
+`
+
+type CancelPauseRequestParams struct {
+	ID         uuid.UUID
+	InProgress models.GameStatus
+}
+
+func (q *Queries) CancelPauseRequest(ctx context.Context, arg CancelPauseRequestParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelPauseRequest, arg.ID, arg.InProgress)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const claimGameSeat = `-- name: ClaimGameSeat :execrows
+INSERT INTO game_players (game_id, user_id, seat)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING
+`
+
+type ClaimGameSeatParams struct {
+	GameID uuid.UUID
+	UserID uuid.UUID
+	Seat   int16
+}
+
+// A seat already taken, or a player already seated, inserts no row.
+func (q *Queries) ClaimGameSeat(ctx context.Context, arg ClaimGameSeatParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimGameSeat, arg.GameID, arg.UserID, arg.Seat)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const claimTurnReminder = `-- name: ClaimTurnReminder :execrows
+INSERT INTO turn_reminders (game_id, turn, reminder, sent_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING
+`
+
+type ClaimTurnReminderParams struct {
+	GameID   uuid.UUID
+	Turn     int32
+	Reminder int32
+	SentAt   time.Time
+}
+
+// Records a turn's reminder as sent, affecting no rows if it already was.
+func (q *Queries) ClaimTurnReminder(ctx context.Context, arg ClaimTurnReminderParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimTurnReminder,
+		arg.GameID,
+		arg.Turn,
+		arg.Reminder,
+		arg.SentAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const clearGameTags = `-- name: ClearGameTags :exec
+DELETE FROM game_tags WHERE game_id = $1
+`
+
+func (q *Queries) ClearGameTags(ctx context.Context, gameID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, clearGameTags, gameID)
+	return err
+}
+
+const countMove = `-- name: CountMove :one
+UPDATE games SET move_count = move_count + 1, last_move_at = $1, turn_started_at = $1
+WHERE id = $2 AND move_count = $3 AND status = $4
+RETURNING move_count
+`
+
+type CountMoveParams struct {
+	MovedAt    *time.Time
+	ID         uuid.UUID
+	MoveCount  int32
+	InProgress models.GameStatus
+}
+
+// A move's history entry is the game's last move; timeouts count too. It
+// also starts the next turn's clock. Only a game still in progress at the
+// move count the move was made against is counted, so a move or timeout
+// racing another returns no row.
+func (q *Queries) CountMove(ctx context.Context, arg CountMoveParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, countMove,
+		arg.MovedAt,
+		arg.ID,
+		arg.MoveCount,
+		arg.InProgress,
+	)
+	var move_count int32
+	err := row.Scan(&move_count)
+	return move_count, err
+}
+
+const createGame = `-- name: CreateGame :exec
+INSERT INTO games (id, game_type, status, player1_id, player2_id, winner_id, current_turn, game_state, created_at, updated_at, started_at, ended_at, is_private, settings, starter_id, initial_state, move_deadline, series_id, series_game, is_rated, visibility)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+`
+
+type CreateGameParams struct {
+	ID           uuid.UUID
+	GameType     models.GameType
+	Status       models.GameStatus
+	Player1ID    uuid.UUID
+	Player2ID    *uuid.UUID
+	WinnerID     *uuid.UUID
+	CurrentTurn  *uuid.UUID
+	GameState    json.RawMessage
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	StartedAt    *time.Time
+	EndedAt      *time.Time
+	IsPrivate    bool
+	Settings     models.GameSettings
+	StarterID    *uuid.UUID
+	InitialState json.RawMessage
+	MoveDeadline *time.Time
+	SeriesID     *uuid.UUID
+	SeriesGame   sql.NullInt32
+	IsRated      bool
+	Visibility   models.GameVisibility
+}
+
+func (q *Queries) CreateGame(ctx context.Context, arg CreateGameParams) error {
+	_, err := q.db.ExecContext(ctx, createGame,
+		arg.ID,
+		arg.GameType,
+		arg.Status,
+		arg.Player1ID,
+		arg.Player2ID,
+		arg.WinnerID,
+		arg.CurrentTurn,
+		arg.GameState,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.StartedAt,
+		arg.EndedAt,
+		arg.IsPrivate,
+		arg.Settings,
+		arg.StarterID,
+		arg.InitialState,
+		arg.MoveDeadline,
+		arg.SeriesID,
+		arg.SeriesGame,
+		arg.IsRated,
+		arg.Visibility,
+	)
+	return err
+}
+
+const deleteTurnRemindersBefore = `-- name: DeleteTurnRemindersBefore :exec
+DELETE FROM turn_reminders WHERE game_id = $1 AND turn < $2
+`
+
+type DeleteTurnRemindersBeforeParams struct {
+	GameID uuid.UUID
+	Turn   int32
+}
+
+func (q *Queries) DeleteTurnRemindersBefore(ctx context.Context, arg DeleteTurnRemindersBeforeParams) error {
+	_, err := q.db.ExecContext(ctx, deleteTurnRemindersBefore, arg.GameID, arg.Turn)
+	return err
+}
+
+const endGame = `-- name: EndGame :execrows
+UPDATE games
+SET status = $1, winner_id = $2, current_turn = NULL, ended_at = $3,
+    duration_seconds = $4, end_reason = $5
+WHERE id = $6 AND status = $7
+`
+
+type EndGameParams struct {
+	Status          models.GameStatus
+	WinnerID        *uuid.UUID
+	EndedAt         *time.Time
+	DurationSeconds sql.NullInt32
+	EndReason       sql.NullString
+	ID              uuid.UUID
+	InProgress      models.GameStatus
+}
+
+func (q *Queries) EndGame(ctx context.Context, arg EndGameParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, endGame,
+		arg.Status,
+		arg.WinnerID,
+		arg.EndedAt,
+		arg.DurationSeconds,
+		arg.EndReason,
+		arg.ID,
+		arg.InProgress,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const forcePause = `-- name: ForcePause :execrows
+UPDATE games SET paused_at = COALESCE(paused_at, $1), paused_by = $2,
+    pause_requested_by = NULL, updated_at = NOW()
+WHERE id = $3 AND status = $4 AND paused_by IS NULL
+`
+
+type ForcePauseParams struct {
+	PausedAt   *time.Time
+	PausedBy   *uuid.UUID
+	ID         uuid.UUID
+	InProgress models.GameStatus
+}
+
+// An admin pauses a game, taking over a pause the players agreed to.
+func (q *Queries) ForcePause(ctx context.Context, arg ForcePauseParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, forcePause,
+		arg.PausedAt,
+		arg.PausedBy,
+		arg.ID,
+		arg.InProgress,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getGame = `-- name: GetGame :one
+SELECT id, game_type, status, player1_id, player2_id, winner_id, current_turn, game_state, created_at, updated_at, started_at, ended_at, is_private, settings, starter_id, initial_state, move_count, last_move_at, duration_seconds, spectators, peak_spectators, paused_at, paused_by, pause_requested_by, turn_started_at, move_deadline, series_id, series_game, end_reason, is_rated, visibility FROM games WHERE id = $1
+`
+
+func (q *Queries) GetGame(ctx context.Context, id uuid.UUID) (Game, error) {
+	row := q.db.QueryRowContext(ctx, getGame, id)
+	var i Game
+	err := row.Scan(
+		&i.ID,
+		&i.GameType,
+		&i.Status,
+		&i.Player1ID,
+		&i.Player2ID,
+		&i.WinnerID,
+		&i.CurrentTurn,
+		&i.GameState,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.IsPrivate,
+		&i.Settings,
+		&i.StarterID,
+		&i.InitialState,
+		&i.MoveCount,
+		&i.LastMoveAt,
+		&i.DurationSeconds,
+		&i.Spectators,
+		&i.PeakSpectators,
+		&i.PausedAt,
+		&i.PausedBy,
+		&i.PauseRequestedBy,
+		&i.TurnStartedAt,
+		&i.MoveDeadline,
+		&i.SeriesID,
+		&i.SeriesGame,
+		&i.EndReason,
+		&i.IsRated,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const holdWaitingGame = `-- name: HoldWaitingGame :execrows
+UPDATE games SET player2_id = $1, updated_at = NOW()
+WHERE id = $2 AND status = $3
+`
+
+type HoldWaitingGameParams struct {
+	Player2ID *uuid.UUID
+	ID        uuid.UUID
+	Waiting   models.GameStatus
+}
+
+// Holds a waiting game's row while a player takes a seat, so the game
+// cannot be cancelled or started underneath them. A game no longer waiting
+// returns no row.
+func (q *Queries) HoldWaitingGame(ctx context.Context, arg HoldWaitingGameParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, holdWaitingGame, arg.Player2ID, arg.ID, arg.Waiting)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listAwaitingTurnGames = `-- name: ListAwaitingTurnGames :many
+SELECT id, game_type, status, player1_id, player2_id, winner_id, current_turn, game_state, created_at, updated_at, started_at, ended_at, is_private, settings, starter_id, initial_state, move_count, last_move_at, duration_seconds, spectators, peak_spectators, paused_at, paused_by, pause_requested_by, turn_started_at, move_deadline, series_id, series_game, end_reason, is_rated, visibility FROM games
+WHERE status = $1 AND current_turn IS NOT NULL AND paused_at IS NULL
+    AND ((move_deadline IS NULL AND COALESCE(turn_started_at, started_at) < $2::timestamp)
+        OR move_deadline < $3::timestamp)
+`
+
+type ListAwaitingTurnGamesParams struct {
+	Status         models.GameStatus
+	StartedBefore  time.Time
+	DeadlineBefore time.Time
+}
+
+// Games whose player to move may be due a reminder: live games whose turn
+// started before started_before, and correspondence games whose deadline
+// falls before deadline_before.
+func (q *Queries) ListAwaitingTurnGames(ctx context.Context, arg ListAwaitingTurnGamesParams) ([]Game, error) {
+	rows, err := q.db.QueryContext(ctx, listAwaitingTurnGames, arg.Status, arg.StartedBefore, arg.DeadlineBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Game
+	for rows.Next() {
+		var i Game
+		if err := rows.Scan(
+			&i.ID,
+			&i.GameType,
+			&i.Status,
+			&i.Player1ID,
+			&i.Player2ID,
+			&i.WinnerID,
+			&i.CurrentTurn,
+			&i.GameState,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.IsPrivate,
+			&i.Settings,
+			&i.StarterID,
+			&i.InitialState,
+			&i.MoveCount,
+			&i.LastMoveAt,
+			&i.DurationSeconds,
+			&i.Spectators,
+			&i.PeakSpectators,
+			&i.PausedAt,
+			&i.PausedBy,
+			&i.PauseRequestedBy,
+			&i.TurnStartedAt,
+			&i.MoveDeadline,
+			&i.SeriesID,
+			&i.SeriesGame,
+			&i.EndReason,
+			&i.IsRated,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGamePlayers = `-- name: ListGamePlayers :many
+SELECT game_id, user_id, seat, color, team FROM game_players WHERE game_id = $1 ORDER BY seat
+`
+
+func (q *Queries) ListGamePlayers(ctx context.Context, gameID uuid.UUID) ([]GamePlayer, error) {
+	rows, err := q.db.QueryContext(ctx, listGamePlayers, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GamePlayer
+	for rows.Next() {
+		var i GamePlayer
+		if err := rows.Scan(
+			&i.GameID,
+			&i.UserID,
+			&i.Seat,
+			&i.Color,
+			&i.Team,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGameTags = `-- name: ListGameTags :many
+SELECT game_id, tag FROM game_tags
+WHERE game_id = ANY($1::uuid[])
+ORDER BY game_id, tag
+`
+
+func (q *Queries) ListGameTags(ctx context.Context, gameIds []uuid.UUID) ([]GameTag, error) {
+	rows, err := q.db.QueryContext(ctx, listGameTags, pq.Array(gameIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GameTag
+	for rows.Next() {
+		var i GameTag
+		if err := rows.Scan(&i.GameID, &i.Tag); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGamesStartedBefore = `-- name: ListGamesStartedBefore :many
+SELECT id, game_type, status, player1_id, player2_id, winner_id, current_turn, game_state, created_at, updated_at, started_at, ended_at, is_private, settings, starter_id, initial_state, move_count, last_move_at, duration_seconds, spectators, peak_spectators, paused_at, paused_by, pause_requested_by, turn_started_at, move_deadline, series_id, series_game, end_reason, is_rated, visibility FROM games
+WHERE status = $1 AND paused_at IS NULL AND move_deadline IS NULL AND started_at < $2::timestamp
+ORDER BY started_at ASC
+`
+
+type ListGamesStartedBeforeParams struct {
+	Status        models.GameStatus
+	StartedBefore time.Time
+}
+
+// Paused and correspondence games are left out, as their players may be
+// away.
+func (q *Queries) ListGamesStartedBefore(ctx context.Context, arg ListGamesStartedBeforeParams) ([]Game, error) {
+	rows, err := q.db.QueryContext(ctx, listGamesStartedBefore, arg.Status, arg.StartedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Game
+	for rows.Next() {
+		var i Game
+		if err := rows.Scan(
+			&i.ID,
+			&i.GameType,
+			&i.Status,
+			&i.Player1ID,
+			&i.Player2ID,
+			&i.WinnerID,
+			&i.CurrentTurn,
+			&i.GameState,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.IsPrivate,
+			&i.Settings,
+			&i.StarterID,
+			&i.InitialState,
+			&i.MoveCount,
+			&i.LastMoveAt,
+			&i.DurationSeconds,
+			&i.Spectators,
+			&i.PeakSpectators,
+			&i.PausedAt,
+			&i.PausedBy,
+			&i.PauseRequestedBy,
+			&i.TurnStartedAt,
+			&i.MoveDeadline,
+			&i.SeriesID,
+			&i.SeriesGame,
+			&i.EndReason,
+			&i.IsRated,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOverdueGames = `-- name: ListOverdueGames :many
+SELECT id, game_type, status, player1_id, player2_id, winner_id, current_turn, game_state, created_at, updated_at, started_at, ended_at, is_private, settings, starter_id, initial_state, move_count, last_move_at, duration_seconds, spectators, peak_spectators, paused_at, paused_by, pause_requested_by, turn_started_at, move_deadline, series_id, series_game, end_reason, is_rated, visibility FROM games
+WHERE status = $1 AND current_turn IS NOT NULL AND paused_at IS NULL
+    AND move_deadline < $2::timestamp
+ORDER BY move_deadline ASC
+`
+
+type ListOverdueGamesParams struct {
+	Status models.GameStatus
+	Now    time.Time
+}
+
+// Correspondence games whose player to move has missed the deadline.
+func (q *Queries) ListOverdueGames(ctx context.Context, arg ListOverdueGamesParams) ([]Game, error) {
+	rows, err := q.db.QueryContext(ctx, listOverdueGames, arg.Status, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Game
+	for rows.Next() {
+		var i Game
+		if err := rows.Scan(
+			&i.ID,
+			&i.GameType,
+			&i.Status,
+			&i.Player1ID,
+			&i.Player2ID,
+			&i.WinnerID,
+			&i.CurrentTurn,
+			&i.GameState,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.IsPrivate,
+			&i.Settings,
+			&i.StarterID,
+			&i.InitialState,
+			&i.MoveCount,
+			&i.LastMoveAt,
+			&i.DurationSeconds,
+			&i.Spectators,
+			&i.PeakSpectators,
+			&i.PausedAt,
+			&i.PausedBy,
+			&i.PauseRequestedBy,
+			&i.TurnStartedAt,
+			&i.MoveDeadline,
+			&i.SeriesID,
+			&i.SeriesGame,
+			&i.EndReason,
+			&i.IsRated,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPublicGames = `-- name: ListPublicGames :many
+SELECT id, game_type, status, player1_id, player2_id, winner_id, current_turn, game_state, created_at, updated_at, started_at, ended_at, is_private, settings, starter_id, initial_state, move_count, last_move_at, duration_seconds, spectators, peak_spectators, paused_at, paused_by, pause_requested_by, turn_started_at, move_deadline, series_id, series_game, end_reason, is_rated, visibility FROM games
+WHERE is_private = false
+    AND (visibility = 'public' OR (visibility = 'friends' AND EXISTS (
+        SELECT 1 FROM game_players p
+        WHERE p.game_id = games.id AND (p.user_id = $1 OR EXISTS (
+            SELECT 1 FROM friendships f
+            WHERE f.status = 'accepted'
+                AND ((f.user_id = $1 AND f.friend_id = p.user_id)
+                    OR (f.friend_id = $1 AND f.user_id = p.user_id))
+        ))
+    )))
+    AND ($2::text IS NULL OR status = $2::text)
+    AND ($3::text IS NULL OR game_type = $3::text)
+    AND ($4::boolean IS NULL OR is_rated = $4::boolean)
+    AND ($5::text IS NULL OR EXISTS (
+        SELECT 1 FROM game_tags t WHERE t.game_id = games.id AND t.tag = $5::text
+    ))
+ORDER BY created_at DESC
+LIMIT $7 OFFSET $6
+`
+
+type ListPublicGamesParams struct {
+	ViewerID  uuid.UUID
+	Status    sql.NullString
+	GameType  sql.NullString
+	Rated     sql.NullBool
+	Tag       sql.NullString
+	RowOffset int32
+	RowLimit  int32
+}
+
+// Private games are only reachable through their join code. A null status
+// or game type matches any.
+// Friends-only games are listed to their players and the players' friends.
+func (q *Queries) ListPublicGames(ctx context.Context, arg ListPublicGamesParams) ([]Game, error) {
+	rows, err := q.db.QueryContext(ctx, listPublicGames,
+		arg.ViewerID,
+		arg.Status,
+		arg.GameType,
+		arg.Rated,
+		arg.Tag,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Game
+	for rows.Next() {
+		var i Game
+		if err := rows.Scan(
+			&i.ID,
+			&i.GameType,
+			&i.Status,
+			&i.Player1ID,
+			&i.Player2ID,
+			&i.WinnerID,
+			&i.CurrentTurn,
+			&i.GameState,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.IsPrivate,
+			&i.Settings,
+			&i.StarterID,
+			&i.InitialState,
+			&i.MoveCount,
+			&i.LastMoveAt,
+			&i.DurationSeconds,
+			&i.Spectators,
+			&i.PeakSpectators,
+			&i.PausedAt,
+			&i.PausedBy,
+			&i.PauseRequestedBy,
+			&i.TurnStartedAt,
+			&i.MoveDeadline,
+			&i.SeriesID,
+			&i.SeriesGame,
+			&i.EndReason,
+			&i.IsRated,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeriesGames = `-- name: ListSeriesGames :many
+SELECT id, game_type, status, player1_id, player2_id, winner_id, current_turn, game_state, created_at, updated_at, started_at, ended_at, is_private, settings, starter_id, initial_state, move_count, last_move_at, duration_seconds, spectators, peak_spectators, paused_at, paused_by, pause_requested_by, turn_started_at, move_deadline, series_id, series_game, end_reason, is_rated, visibility FROM games WHERE series_id = $1 ORDER BY series_game ASC
+`
+
+func (q *Queries) ListSeriesGames(ctx context.Context, seriesID *uuid.UUID) ([]Game, error) {
+	rows, err := q.db.QueryContext(ctx, listSeriesGames, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Game
+	for rows.Next() {
+		var i Game
+		if err := rows.Scan(
+			&i.ID,
+			&i.GameType,
+			&i.Status,
+			&i.Player1ID,
+			&i.Player2ID,
+			&i.WinnerID,
+			&i.CurrentTurn,
+			&i.GameState,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.IsPrivate,
+			&i.Settings,
+			&i.StarterID,
+			&i.InitialState,
+			&i.MoveCount,
+			&i.LastMoveAt,
+			&i.DurationSeconds,
+			&i.Spectators,
+			&i.PeakSpectators,
+			&i.PausedAt,
+			&i.PausedBy,
+			&i.PauseRequestedBy,
+			&i.TurnStartedAt,
+			&i.MoveDeadline,
+			&i.SeriesID,
+			&i.SeriesGame,
+			&i.EndReason,
+			&i.IsRated,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTimedOutGames = `-- name: ListTimedOutGames :many
+SELECT id, game_type, status, player1_id, player2_id, winner_id, current_turn, game_state, created_at, updated_at, started_at, ended_at, is_private, settings, starter_id, initial_state, move_count, last_move_at, duration_seconds, spectators, peak_spectators, paused_at, paused_by, pause_requested_by, turn_started_at, move_deadline, series_id, series_game, end_reason, is_rated, visibility FROM games
+WHERE status = $1 AND current_turn IS NOT NULL AND paused_at IS NULL AND move_deadline IS NULL
+    AND COALESCE(turn_started_at, started_at) < $2::timestamp
+ORDER BY COALESCE(turn_started_at, started_at) ASC
+`
+
+type ListTimedOutGamesParams struct {
+	Status models.GameStatus
+	Cutoff time.Time
+}
+
+// The current turn's clock started with the last move, or with the game if
+// no move has been made, and stops while the game is paused. Correspondence
+// games run on their move deadlines instead.
+func (q *Queries) ListTimedOutGames(ctx context.Context, arg ListTimedOutGamesParams) ([]Game, error) {
+	rows, err := q.db.QueryContext(ctx, listTimedOutGames, arg.Status, arg.Cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Game
+	for rows.Next() {
+		var i Game
+		if err := rows.Scan(
+			&i.ID,
+			&i.GameType,
+			&i.Status,
+			&i.Player1ID,
+			&i.Player2ID,
+			&i.WinnerID,
+			&i.CurrentTurn,
+			&i.GameState,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.IsPrivate,
+			&i.Settings,
+			&i.StarterID,
+			&i.InitialState,
+			&i.MoveCount,
+			&i.LastMoveAt,
+			&i.DurationSeconds,
+			&i.Spectators,
+			&i.PeakSpectators,
+			&i.PausedAt,
+			&i.PausedBy,
+			&i.PauseRequestedBy,
+			&i.TurnStartedAt,
+			&i.MoveDeadline,
+			&i.SeriesID,
+			&i.SeriesGame,
+			&i.EndReason,
+			&i.IsRated,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const overturnGameResult = `-- name: OverturnGameResult :execrows
+UPDATE games
+SET status = $1, winner_id = $2, end_reason = $3, updated_at = NOW()
+WHERE id = $4 AND status = $5
+`
+
+type OverturnGameResultParams struct {
+	Status    models.GameStatus
+	WinnerID  *uuid.UUID
+	EndReason sql.NullString
+	ID        uuid.UUID
+	Completed models.GameStatus
+}
+
+// Changes the result of a completed game.
+func (q *Queries) OverturnGameResult(ctx context.Context, arg OverturnGameResultParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, overturnGameResult,
+		arg.Status,
+		arg.WinnerID,
+		arg.EndReason,
+		arg.ID,
+		arg.Completed,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const recordGameSpectators = `-- name: RecordGameSpectators :exec
+UPDATE games SET spectators = $1, peak_spectators = GREATEST(peak_spectators, $1)
+WHERE id = $2
+`
+
+type RecordGameSpectatorsParams struct {
+	Spectators int32
+	ID         uuid.UUID
+}
+
+func (q *Queries) RecordGameSpectators(ctx context.Context, arg RecordGameSpectatorsParams) error {
+	_, err := q.db.ExecContext(ctx, recordGameSpectators, arg.Spectators, arg.ID)
+	return err
+}
+
+const releaseGameSeats = `-- name: ReleaseGameSeats :exec
+DELETE FROM game_players WHERE game_id = $1 AND seat > $2
+`
+
+type ReleaseGameSeatsParams struct {
+	GameID uuid.UUID
+	Seats  int16
+}
+
+func (q *Queries) ReleaseGameSeats(ctx context.Context, arg ReleaseGameSeatsParams) error {
+	_, err := q.db.ExecContext(ctx, releaseGameSeats, arg.GameID, arg.Seats)
+	return err
+}
+
+const requestPauseChange = `-- name: RequestPauseChange :execrows
+UPDATE games SET pause_requested_by = $1, updated_at = NOW()
+WHERE id = $2 AND status = $3 AND pause_requested_by IS NULL AND paused_by IS NULL
+    AND (paused_at IS NOT NULL) = $4::boolean
+`
+
+type RequestPauseChangeParams struct {
+	RequestedBy *uuid.UUID
+	ID          uuid.UUID
+	InProgress  models.GameStatus
+	Paused      bool
+}
+
+// A player asks to pause a running game or to resume one the players
+// paused; paused says which.
+func (q *Queries) RequestPauseChange(ctx context.Context, arg RequestPauseChangeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, requestPauseChange,
+		arg.RequestedBy,
+		arg.ID,
+		arg.InProgress,
+		arg.Paused,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const resumeGame = `-- name: ResumeGame :execrows
+UPDATE games SET paused_at = NULL, paused_by = NULL, pause_requested_by = NULL,
+    turn_started_at = $1, move_deadline = $2, updated_at = NOW()
+WHERE id = $3 AND status = $4 AND paused_at = $5
+    AND ($6::uuid IS NULL OR pause_requested_by = $6::uuid)
+`
+
+type ResumeGameParams struct {
+	TurnStartedAt *time.Time
+	MoveDeadline  *time.Time
+	ID            uuid.UUID
+	InProgress    models.GameStatus
+	PausedAt      *time.Time
+	RequestedBy   *uuid.UUID
+}
+
+// Resumes the pause that started at paused_at. A requester is the player
+// whose resume request the other agreed to; admins resume without one.
+func (q *Queries) ResumeGame(ctx context.Context, arg ResumeGameParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, resumeGame,
+		arg.TurnStartedAt,
+		arg.MoveDeadline,
+		arg.ID,
+		arg.InProgress,
+		arg.PausedAt,
+		arg.RequestedBy,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const seatGamePlayer = `-- name: SeatGamePlayer :exec
+INSERT INTO game_players (game_id, user_id, seat, color, team)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (game_id, seat) DO UPDATE SET
+    user_id = excluded.user_id,
+    color = CASE WHEN game_players.user_id = excluded.user_id THEN COALESCE(excluded.color, game_players.color) ELSE excluded.color END,
+    team = CASE WHEN game_players.user_id = excluded.user_id THEN COALESCE(excluded.team, game_players.team) ELSE excluded.team END
+`
+
+type SeatGamePlayerParams struct {
+	GameID uuid.UUID
+	UserID uuid.UUID
+	Seat   int16
+	Color  sql.NullString
+	Team   sql.NullInt16
+}
+
+// Seats a player. A player who keeps their seat keeps its color and team
+// unless new ones are given.
+func (q *Queries) SeatGamePlayer(ctx context.Context, arg SeatGamePlayerParams) error {
+	_, err := q.db.ExecContext(ctx, seatGamePlayer,
+		arg.GameID,
+		arg.UserID,
+		arg.Seat,
+		arg.Color,
+		arg.Team,
+	)
+	return err
+}
+
+const setGameVisibility = `-- name: SetGameVisibility :execrows
+UPDATE games SET visibility = $1, updated_at = NOW() WHERE id = $2
+`
+
+type SetGameVisibilityParams struct {
+	Visibility models.GameVisibility
+	ID         uuid.UUID
+}
+
+func (q *Queries) SetGameVisibility(ctx context.Context, arg SetGameVisibilityParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setGameVisibility, arg.Visibility, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const tagGame = `-- name: TagGame :exec
+INSERT INTO game_tags (game_id, tag) VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type TagGameParams struct {
+	GameID uuid.UUID
+	Tag    models.GameTag
+}
+
+func (q *Queries) TagGame(ctx context.Context, arg TagGameParams) error {
+	_, err := q.db.ExecContext(ctx, tagGame, arg.GameID, arg.Tag)
+	return err
+}
+
+const touchGame = `-- name: TouchGame :execrows
+UPDATE games SET updated_at = NOW() WHERE id = $1
+`
+
+// Moves updated_at on, so clients polling the game see the change.
+func (q *Queries) TouchGame(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, touchGame, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateGame = `-- name: UpdateGame :exec
+UPDATE games SET game_type = $2, status = $3, player1_id = $4, player2_id = $5, winner_id = $6,
+    current_turn = $7, game_state = $8, updated_at = $9, started_at = $10, ended_at = $11, starter_id = $12,
+    initial_state = $13, duration_seconds = $14, move_deadline = $15, series_id = $16, series_game = $17,
+    end_reason = $18
+WHERE id = $1
+`
+
+type UpdateGameParams struct {
+	ID              uuid.UUID
+	GameType        models.GameType
+	Status          models.GameStatus
+	Player1ID       uuid.UUID
+	Player2ID       *uuid.UUID
+	WinnerID        *uuid.UUID
+	CurrentTurn     *uuid.UUID
+	GameState       json.RawMessage
+	UpdatedAt       time.Time
+	StartedAt       *time.Time
+	EndedAt         *time.Time
+	StarterID       *uuid.UUID
+	InitialState    json.RawMessage
+	DurationSeconds sql.NullInt32
+	MoveDeadline    *time.Time
+	SeriesID        *uuid.UUID
+	SeriesGame      sql.NullInt32
+	EndReason       sql.NullString
+}
+
+func (q *Queries) UpdateGame(ctx context.Context, arg UpdateGameParams) error {
+	_, err := q.db.ExecContext(ctx, updateGame,
+		arg.ID,
+		arg.GameType,
+		arg.Status,
+		arg.Player1ID,
+		arg.Player2ID,
+		arg.WinnerID,
+		arg.CurrentTurn,
+		arg.GameState,
+		arg.UpdatedAt,
+		arg.StartedAt,
+		arg.EndedAt,
+		arg.StarterID,
+		arg.InitialState,
+		arg.DurationSeconds,
+		arg.MoveDeadline,
+		arg.SeriesID,
+		arg.SeriesGame,
+		arg.EndReason,
+	)
+	return err
+}
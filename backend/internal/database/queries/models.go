@@ -0,0 +1,357 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package queries
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+type AuditLog struct {
+	ID         uuid.UUID
+	ActorID    uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   string
+	Reason     string
+	Before     json.RawMessage
+	After      json.RawMessage
+	Ip         string
+	RequestID  string
+	CreatedAt  time.Time
+}
+
+type Bot struct {
+	Level  models.BotLevel
+	UserID uuid.UUID
+}
+
+type BotMove struct {
+	GameID  uuid.UUID
+	Turn    int32
+	MovedAt time.Time
+}
+
+type ChatMessage struct {
+	ID        uuid.UUID
+	RoomID    string
+	SenderID  uuid.UUID
+	Body      string
+	CreatedAt time.Time
+	DeletedAt *time.Time
+	DeletedBy *uuid.UUID
+}
+
+type DirectMessage struct {
+	ID          uuid.UUID
+	SenderID    uuid.UUID
+	RecipientID uuid.UUID
+	Body        string
+	CreatedAt   time.Time
+}
+
+type DisabledGameType struct {
+	GameType   string
+	DisabledAt time.Time
+}
+
+type Friendship struct {
+	UserID    uuid.UUID
+	FriendID  uuid.UUID
+	Status    string
+	CreatedAt time.Time
+}
+
+type Game struct {
+	ID               uuid.UUID
+	GameType         models.GameType
+	Status           models.GameStatus
+	Player1ID        uuid.UUID
+	Player2ID        *uuid.UUID
+	WinnerID         *uuid.UUID
+	CurrentTurn      *uuid.UUID
+	GameState        json.RawMessage
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	StartedAt        *time.Time
+	EndedAt          *time.Time
+	IsPrivate        bool
+	Settings         models.GameSettings
+	StarterID        *uuid.UUID
+	InitialState     json.RawMessage
+	MoveCount        int32
+	LastMoveAt       *time.Time
+	DurationSeconds  sql.NullInt32
+	Spectators       int32
+	PeakSpectators   int32
+	PausedAt         *time.Time
+	PausedBy         *uuid.UUID
+	PauseRequestedBy *uuid.UUID
+	TurnStartedAt    *time.Time
+	MoveDeadline     *time.Time
+	SeriesID         *uuid.UUID
+	SeriesGame       sql.NullInt32
+	EndReason        sql.NullString
+	IsRated          bool
+	Visibility       models.GameVisibility
+}
+
+type GameAdjudication struct {
+	ID             uuid.UUID
+	GameID         uuid.UUID
+	RequesterID    uuid.UUID
+	Reason         string
+	Details        string
+	Status         string
+	Decision       sql.NullString
+	WinnerID       *uuid.UUID
+	CreatedAt      time.Time
+	ResolvedAt     *time.Time
+	ResolvedBy     *uuid.UUID
+	ResolutionNote string
+}
+
+type GamePlayer struct {
+	GameID uuid.UUID
+	UserID uuid.UUID
+	Seat   int16
+	Color  sql.NullString
+	Team   sql.NullInt16
+}
+
+type GameSnapshot struct {
+	GameID     uuid.UUID
+	MoveNumber int32
+	State      json.RawMessage
+	CreatedAt  time.Time
+}
+
+type GameTag struct {
+	GameID uuid.UUID
+	Tag    models.GameTag
+}
+
+type LeaderboardRating struct {
+	GameType    string
+	UserID      uuid.UUID
+	Username    string
+	Rating      int32
+	GamesPlayed int32
+	GamesWon    int32
+	Rank        int64
+	Position    int64
+}
+
+type LoginEvent struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Method     string
+	Ip         string
+	UserAgent  string
+	DeviceHash string
+	Country    string
+	Suspicious bool
+	CreatedAt  time.Time
+}
+
+type Move struct {
+	ID        uuid.UUID
+	GameID    uuid.UUID
+	PlayerID  uuid.UUID
+	MoveData  json.RawMessage
+	CreatedAt time.Time
+	IsValid   bool
+	Kind      models.MoveKind
+}
+
+type RatingHistory struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	GameID        *uuid.UUID
+	GameType      string
+	System        string
+	OldRating     int32
+	NewRating     int32
+	Delta         int32
+	CreatedAt     time.Time
+	Deviation     sql.NullFloat64
+	OldDeviation  sql.NullFloat64
+	OldVolatility sql.NullFloat64
+	OldTier       sql.NullString
+	OldDivision   sql.NullInt32
+}
+
+type Report struct {
+	ID             uuid.UUID
+	ReporterID     uuid.UUID
+	ReportedID     uuid.UUID
+	Reason         string
+	Details        string
+	GameID         *uuid.UUID
+	Status         string
+	Context        json.RawMessage
+	CreatedAt      time.Time
+	ResolvedAt     *time.Time
+	ResolvedBy     *uuid.UUID
+	ResolutionNote string
+	ChatMessageID  *uuid.UUID
+}
+
+type SavedGameFilter struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Name      string
+	Filter    models.GameFilter
+	CreatedAt time.Time
+}
+
+type Season struct {
+	ID          uuid.UUID
+	Name        string
+	StartsAt    time.Time
+	EndsAt      time.Time
+	FinalizedAt *time.Time
+	CreatedAt   time.Time
+}
+
+type SeasonRating struct {
+	SeasonID    uuid.UUID
+	UserID      uuid.UUID
+	GameType    models.GameType
+	Rating      int32
+	GamesPlayed int32
+	GamesWon    int32
+	GamesLost   int32
+	UpdatedAt   time.Time
+}
+
+type SeasonReward struct {
+	SeasonID  uuid.UUID
+	UserID    uuid.UUID
+	GameType  models.GameType
+	Reward    models.SeasonReward
+	Rank      int32
+	Rating    int32
+	GrantedAt time.Time
+}
+
+type Series struct {
+	ID          uuid.UUID
+	GameType    models.GameType
+	Player1ID   uuid.UUID
+	Player2ID   uuid.UUID
+	BestOf      int32
+	Settings    models.GameSettings
+	Status      models.SeriesStatus
+	GamesPlayed int32
+	Player1Wins int32
+	Player2Wins int32
+	Draws       int32
+	WinnerID    *uuid.UUID
+	CreatedAt   time.Time
+	EndedAt     *time.Time
+}
+
+type Tournament struct {
+	ID           uuid.UUID
+	Name         string
+	GameType     models.GameType
+	TimeControl  string
+	StartsAt     time.Time
+	EndsAt       time.Time
+	Rounds       int32
+	RoundMinutes int32
+	Capacity     int32
+	MinRating    sql.NullInt32
+	MaxRating    sql.NullInt32
+	CreatedBy    *uuid.UUID
+	CancelledAt  *time.Time
+	CreatedAt    time.Time
+}
+
+type TournamentRegistration struct {
+	TournamentID uuid.UUID
+	UserID       uuid.UUID
+	RegisteredAt time.Time
+}
+
+type TournamentReminder struct {
+	TournamentID uuid.UUID
+	Round        int32
+	SentAt       time.Time
+}
+
+type TrustedDevice struct {
+	UserID     uuid.UUID
+	DeviceHash string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+type TurnReminder struct {
+	GameID   uuid.UUID
+	Turn     int32
+	Reminder int32
+	SentAt   time.Time
+}
+
+type User struct {
+	ID                uuid.UUID
+	Email             string
+	Username          string
+	PasswordHash      string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	IsActive          bool
+	Role              models.UserRole
+	AvatarUrl         string
+	ProfileVisibility models.ProfileVisibility
+	UsernameChangedAt *time.Time
+	DeletedAt         *time.Time
+}
+
+type UserBlock struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+	CreatedAt time.Time
+}
+
+type UserIdentity struct {
+	Provider  string
+	Subject   string
+	UserID    uuid.UUID
+	Email     string
+	CreatedAt time.Time
+}
+
+type UserMute struct {
+	UserID     uuid.UUID
+	MutedUntil time.Time
+	Reason     string
+	MutedBy    uuid.UUID
+	CreatedAt  time.Time
+}
+
+type UserStat struct {
+	UserID           uuid.UUID
+	GamesPlayed      int32
+	GamesWon         int32
+	GamesLost        int32
+	Rating           int32
+	UpdatedAt        time.Time
+	SeriesPlayed     int32
+	SeriesWon        int32
+	RatingDeviation  float64
+	RatingVolatility float64
+	RatedGames       int32
+	Tier             string
+	Division         int32
+	LastRatedAt      *time.Time
+	RatingDecayedAt  *time.Time
+}
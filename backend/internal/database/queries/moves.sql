@@ -0,0 +1,21 @@
+-- name: CreateMove :exec
+INSERT INTO moves (id, game_id, player_id, move_data, created_at, is_valid, kind)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListGameMoves :many
+SELECT * FROM moves WHERE game_id = $1 ORDER BY created_at ASC;
+
+-- name: CreateGameSnapshot :exec
+INSERT INTO game_snapshots (game_id, move_number, state, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (game_id, move_number) DO NOTHING;
+
+-- The latest snapshot taken no later than the given move.
+-- name: GetGameSnapshot :one
+SELECT * FROM game_snapshots
+WHERE game_id = @game_id AND move_number <= @move_number
+ORDER BY move_number DESC
+LIMIT 1;
+
+-- name: ListGameSnapshots :many
+SELECT * FROM game_snapshots WHERE game_id = $1 ORDER BY move_number ASC;
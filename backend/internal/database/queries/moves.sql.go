@@ -0,0 +1,158 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: moves.sql
+
+package queries
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+const createGameSnapshot = `-- name: CreateGameSnapshot :exec
+INSERT INTO game_snapshots (game_id, move_number, state, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (game_id, move_number) DO NOTHING
+`
+
+type CreateGameSnapshotParams struct {
+	GameID     uuid.UUID
+	MoveNumber int32
+	State      json.RawMessage
+	CreatedAt  time.Time
+}
+
+func (q *Queries) CreateGameSnapshot(ctx context.Context, arg CreateGameSnapshotParams) error {
+	_, err := q.db.ExecContext(ctx, createGameSnapshot,
+		arg.GameID,
+		arg.MoveNumber,
+		arg.State,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const createMove = `-- name: CreateMove :exec
+INSERT INTO moves (id, game_id, player_id, move_data, created_at, is_valid, kind)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type CreateMoveParams struct {
+	ID        uuid.UUID
+	GameID    uuid.UUID
+	PlayerID  uuid.UUID
+	MoveData  json.RawMessage
+	CreatedAt time.Time
+	IsValid   bool
+	Kind      models.MoveKind
+}
+
+func (q *Queries) CreateMove(ctx context.Context, arg CreateMoveParams) error {
+	_, err := q.db.ExecContext(ctx, createMove,
+		arg.ID,
+		arg.GameID,
+		arg.PlayerID,
+		arg.MoveData,
+		arg.CreatedAt,
+		arg.IsValid,
+		arg.Kind,
+	)
+	return err
+}
+
+const getGameSnapshot = `-- name: GetGameSnapshot :one
+SELECT game_id, move_number, state, created_at FROM game_snapshots
+WHERE game_id = $1 AND move_number <= $2
+ORDER BY move_number DESC
+LIMIT 1
+`
+
+type GetGameSnapshotParams struct {
+	GameID     uuid.UUID
+	MoveNumber int32
+}
+
+// The latest snapshot taken no later than the given move.
+func (q *Queries) GetGameSnapshot(ctx context.Context, arg GetGameSnapshotParams) (GameSnapshot, error) {
+	row := q.db.QueryRowContext(ctx, getGameSnapshot, arg.GameID, arg.MoveNumber)
+	var i GameSnapshot
+	err := row.Scan(
+		&i.GameID,
+		&i.MoveNumber,
+		&i.State,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listGameMoves = `-- name: ListGameMoves :many
+SELECT id, game_id, player_id, move_data, created_at, is_valid, kind FROM moves WHERE game_id = $1 ORDER BY created_at ASC
+`
+
+func (q *Queries) ListGameMoves(ctx context.Context, gameID uuid.UUID) ([]Move, error) {
+	rows, err := q.db.QueryContext(ctx, listGameMoves, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Move
+	for rows.Next() {
+		var i Move
+		if err := rows.Scan(
+			&i.ID,
+			&i.GameID,
+			&i.PlayerID,
+			&i.MoveData,
+			&i.CreatedAt,
+			&i.IsValid,
+			&i.Kind,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGameSnapshots = `-- name: ListGameSnapshots :many
+SELECT game_id, move_number, state, created_at FROM game_snapshots WHERE game_id = $1 ORDER BY move_number ASC
+`
+
+func (q *Queries) ListGameSnapshots(ctx context.Context, gameID uuid.UUID) ([]GameSnapshot, error) {
+	rows, err := q.db.QueryContext(ctx, listGameSnapshots, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GameSnapshot
+	for rows.Next() {
+		var i GameSnapshot
+		if err := rows.Scan(
+			&i.GameID,
+			&i.MoveNumber,
+			&i.State,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
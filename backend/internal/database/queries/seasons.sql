@@ -0,0 +1,34 @@
+-- name: CreateSeason :exec
+INSERT INTO seasons (id, name, starts_at, ends_at, created_at)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetSeason :one
+SELECT * FROM seasons WHERE id = $1;
+
+-- name: ListSeasons :many
+SELECT * FROM seasons ORDER BY starts_at DESC LIMIT $1 OFFSET $2;
+
+-- The season running at a time. Seasons never overlap.
+-- name: GetSeasonAt :one
+SELECT * FROM seasons WHERE starts_at <= @at::timestamp AND ends_at > @at::timestamp;
+
+-- name: CountOverlappingSeasons :one
+SELECT COUNT(*) FROM seasons WHERE starts_at < @ends_at::timestamp AND ends_at > @starts_at::timestamp;
+
+-- Only seasons that have not started yet can be deleted.
+-- name: DeleteSeason :execrows
+DELETE FROM seasons WHERE id = @id AND starts_at > @now::timestamp;
+
+-- Seasons that have ended but not yet granted their rewards, oldest first.
+-- name: ListUnfinalizedSeasons :many
+SELECT * FROM seasons WHERE finalized_at IS NULL AND ends_at <= @now::timestamp ORDER BY ends_at;
+
+-- name: FinalizeSeason :execrows
+UPDATE seasons SET finalized_at = @finalized_at WHERE id = @id AND finalized_at IS NULL;
+
+-- name: ListUserSeasonRewards :many
+SELECT r.season_id, s.name AS season_name, r.user_id, r.game_type, r.reward, r.rank, r.rating, r.granted_at
+FROM season_rewards r
+JOIN seasons s ON s.id = r.season_id
+WHERE r.user_id = $1
+ORDER BY r.granted_at DESC, r.game_type;
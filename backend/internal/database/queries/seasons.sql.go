@@ -0,0 +1,251 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: seasons.sql
+
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+const countOverlappingSeasons = `-- name: CountOverlappingSeasons :one
+SELECT COUNT(*) FROM seasons WHERE starts_at < $1::timestamp AND ends_at > $2::timestamp
+`
+
+type CountOverlappingSeasonsParams struct {
+	EndsAt   time.Time
+	StartsAt time.Time
+}
+
+func (q *Queries) CountOverlappingSeasons(ctx context.Context, arg CountOverlappingSeasonsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOverlappingSeasons, arg.EndsAt, arg.StartsAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createSeason = `-- name: CreateSeason :exec
+INSERT INTO seasons (id, name, starts_at, ends_at, created_at)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateSeasonParams struct {
+	ID        uuid.UUID
+	Name      string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateSeason(ctx context.Context, arg CreateSeasonParams) error {
+	_, err := q.db.ExecContext(ctx, createSeason,
+		arg.ID,
+		arg.Name,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const deleteSeason = `-- name: DeleteSeason :execrows
+DELETE FROM seasons WHERE id = $1 AND starts_at > $2::timestamp
+`
+
+type DeleteSeasonParams struct {
+	ID  uuid.UUID
+	Now time.Time
+}
+
+// Only seasons that have not started yet can be deleted.
+func (q *Queries) DeleteSeason(ctx context.Context, arg DeleteSeasonParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSeason, arg.ID, arg.Now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const finalizeSeason = `-- name: FinalizeSeason :execrows
+UPDATE seasons SET finalized_at = $1 WHERE id = $2 AND finalized_at IS NULL
+`
+
+type FinalizeSeasonParams struct {
+	FinalizedAt *time.Time
+	ID          uuid.UUID
+}
+
+func (q *Queries) FinalizeSeason(ctx context.Context, arg FinalizeSeasonParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, finalizeSeason, arg.FinalizedAt, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getSeason = `-- name: GetSeason :one
+SELECT id, name, starts_at, ends_at, finalized_at, created_at FROM seasons WHERE id = $1
+`
+
+func (q *Queries) GetSeason(ctx context.Context, id uuid.UUID) (Season, error) {
+	row := q.db.QueryRowContext(ctx, getSeason, id)
+	var i Season
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.FinalizedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSeasonAt = `-- name: GetSeasonAt :one
+SELECT id, name, starts_at, ends_at, finalized_at, created_at FROM seasons WHERE starts_at <= $1::timestamp AND ends_at > $1::timestamp
+`
+
+// The season running at a time. Seasons never overlap.
+func (q *Queries) GetSeasonAt(ctx context.Context, at time.Time) (Season, error) {
+	row := q.db.QueryRowContext(ctx, getSeasonAt, at)
+	var i Season
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.FinalizedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listSeasons = `-- name: ListSeasons :many
+SELECT id, name, starts_at, ends_at, finalized_at, created_at FROM seasons ORDER BY starts_at DESC LIMIT $1 OFFSET $2
+`
+
+type ListSeasonsParams struct {
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) ListSeasons(ctx context.Context, arg ListSeasonsParams) ([]Season, error) {
+	rows, err := q.db.QueryContext(ctx, listSeasons, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Season
+	for rows.Next() {
+		var i Season
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.FinalizedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnfinalizedSeasons = `-- name: ListUnfinalizedSeasons :many
+SELECT id, name, starts_at, ends_at, finalized_at, created_at FROM seasons WHERE finalized_at IS NULL AND ends_at <= $1::timestamp ORDER BY ends_at
+`
+
+// Seasons that have ended but not yet granted their rewards, oldest first.
+func (q *Queries) ListUnfinalizedSeasons(ctx context.Context, now time.Time) ([]Season, error) {
+	rows, err := q.db.QueryContext(ctx, listUnfinalizedSeasons, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Season
+	for rows.Next() {
+		var i Season
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.FinalizedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserSeasonRewards = `-- name: ListUserSeasonRewards :many
+SELECT r.season_id, s.name AS season_name, r.user_id, r.game_type, r.reward, r.rank, r.rating, r.granted_at
+FROM season_rewards r
+JOIN seasons s ON s.id = r.season_id
+WHERE r.user_id = $1
+ORDER BY r.granted_at DESC, r.game_type
+`
+
+type ListUserSeasonRewardsRow struct {
+	SeasonID   uuid.UUID
+	SeasonName string
+	UserID     uuid.UUID
+	GameType   models.GameType
+	Reward     models.SeasonReward
+	Rank       int32
+	Rating     int32
+	GrantedAt  time.Time
+}
+
+func (q *Queries) ListUserSeasonRewards(ctx context.Context, userID uuid.UUID) ([]ListUserSeasonRewardsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserSeasonRewards, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserSeasonRewardsRow
+	for rows.Next() {
+		var i ListUserSeasonRewardsRow
+		if err := rows.Scan(
+			&i.SeasonID,
+			&i.SeasonName,
+			&i.UserID,
+			&i.GameType,
+			&i.Reward,
+			&i.Rank,
+			&i.Rating,
+			&i.GrantedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
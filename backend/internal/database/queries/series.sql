@@ -0,0 +1,19 @@
+-- name: CreateSeries :exec
+INSERT INTO series (id, game_type, player1_id, player2_id, best_of, settings, status, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: GetSeries :one
+SELECT * FROM series WHERE id = $1;
+
+-- Adds a finished game to the score. Game numbers must arrive in order, so
+-- each game counts once.
+-- name: RecordSeriesGame :one
+UPDATE series
+SET games_played = games_played + 1, player1_wins = player1_wins + @player1_wins,
+    player2_wins = player2_wins + @player2_wins, draws = draws + @draws
+WHERE id = @id AND status = @in_progress AND games_played = @games_played
+RETURNING *;
+
+-- name: EndSeries :execrows
+UPDATE series SET status = @status, winner_id = @winner_id, ended_at = @ended_at
+WHERE id = @id AND status = @in_progress;
@@ -0,0 +1,145 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: series.sql
+
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+const createSeries = `-- name: CreateSeries :exec
+INSERT INTO series (id, game_type, player1_id, player2_id, best_of, settings, status, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateSeriesParams struct {
+	ID        uuid.UUID
+	GameType  models.GameType
+	Player1ID uuid.UUID
+	Player2ID uuid.UUID
+	BestOf    int32
+	Settings  models.GameSettings
+	Status    models.SeriesStatus
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateSeries(ctx context.Context, arg CreateSeriesParams) error {
+	_, err := q.db.ExecContext(ctx, createSeries,
+		arg.ID,
+		arg.GameType,
+		arg.Player1ID,
+		arg.Player2ID,
+		arg.BestOf,
+		arg.Settings,
+		arg.Status,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const endSeries = `-- name: EndSeries :execrows
+UPDATE series SET status = $1, winner_id = $2, ended_at = $3
+WHERE id = $4 AND status = $5
+`
+
+type EndSeriesParams struct {
+	Status     models.SeriesStatus
+	WinnerID   *uuid.UUID
+	EndedAt    *time.Time
+	ID         uuid.UUID
+	InProgress models.SeriesStatus
+}
+
+func (q *Queries) EndSeries(ctx context.Context, arg EndSeriesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, endSeries,
+		arg.Status,
+		arg.WinnerID,
+		arg.EndedAt,
+		arg.ID,
+		arg.InProgress,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getSeries = `-- name: GetSeries :one
+SELECT id, game_type, player1_id, player2_id, best_of, settings, status, games_played, player1_wins, player2_wins, draws, winner_id, created_at, ended_at FROM series WHERE id = $1
+`
+
+func (q *Queries) GetSeries(ctx context.Context, id uuid.UUID) (Series, error) {
+	row := q.db.QueryRowContext(ctx, getSeries, id)
+	var i Series
+	err := row.Scan(
+		&i.ID,
+		&i.GameType,
+		&i.Player1ID,
+		&i.Player2ID,
+		&i.BestOf,
+		&i.Settings,
+		&i.Status,
+		&i.GamesPlayed,
+		&i.Player1Wins,
+		&i.Player2Wins,
+		&i.Draws,
+		&i.WinnerID,
+		&i.CreatedAt,
+		&i.EndedAt,
+	)
+	return i, err
+}
+
+const recordSeriesGame = `-- name: RecordSeriesGame :one
+UPDATE series
+SET games_played = games_played + 1, player1_wins = player1_wins + $1,
+    player2_wins = player2_wins + $2, draws = draws + $3
+WHERE id = $4 AND status = $5 AND games_played = $6
+RETURNING id, game_type, player1_id, player2_id, best_of, settings, status, games_played, player1_wins, player2_wins, draws, winner_id, created_at, ended_at
+`
+
+type RecordSeriesGameParams struct {
+	Player1Wins int32
+	Player2Wins int32
+	Draws       int32
+	ID          uuid.UUID
+	InProgress  models.SeriesStatus
+	GamesPlayed int32
+}
+
+// Adds a finished game to the score. Game numbers must arrive in order, so
+// each game counts once.
+func (q *Queries) RecordSeriesGame(ctx context.Context, arg RecordSeriesGameParams) (Series, error) {
+	row := q.db.QueryRowContext(ctx, recordSeriesGame,
+		arg.Player1Wins,
+		arg.Player2Wins,
+		arg.Draws,
+		arg.ID,
+		arg.InProgress,
+		arg.GamesPlayed,
+	)
+	var i Series
+	err := row.Scan(
+		&i.ID,
+		&i.GameType,
+		&i.Player1ID,
+		&i.Player2ID,
+		&i.BestOf,
+		&i.Settings,
+		&i.Status,
+		&i.GamesPlayed,
+		&i.Player1Wins,
+		&i.Player2Wins,
+		&i.Draws,
+		&i.WinnerID,
+		&i.CreatedAt,
+		&i.EndedAt,
+	)
+	return i, err
+}
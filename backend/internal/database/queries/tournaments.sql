@@ -0,0 +1,53 @@
+-- name: CreateTournament :exec
+INSERT INTO tournaments (id, name, game_type, time_control, starts_at, ends_at, rounds, round_minutes, capacity, min_rating, max_rating, created_by, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
+
+-- name: GetTournament :one
+SELECT sqlc.embed(t), (SELECT COUNT(*) FROM tournament_registrations r WHERE r.tournament_id = t.id) AS registrations
+FROM tournaments t WHERE t.id = $1;
+
+-- Tournaments open for registration, soonest first. An empty game type
+-- matches every type.
+-- name: ListUpcomingTournaments :many
+SELECT sqlc.embed(t), (SELECT COUNT(*) FROM tournament_registrations r WHERE r.tournament_id = t.id) AS registrations
+FROM tournaments t
+WHERE t.cancelled_at IS NULL AND t.starts_at > @now::timestamp
+  AND (@game_type::text = '' OR t.game_type = @game_type::text)
+ORDER BY t.starts_at
+LIMIT @lim OFFSET @off;
+
+-- Tournaments that have not been cancelled with a round starting before
+-- @before that has not started by @now.
+-- name: ListTournamentsWithRoundsDue :many
+SELECT sqlc.embed(t), (SELECT COUNT(*) FROM tournament_registrations r WHERE r.tournament_id = t.id) AS registrations
+FROM tournaments t
+WHERE t.cancelled_at IS NULL AND t.starts_at <= @before::timestamp AND t.ends_at > @now::timestamp;
+
+-- name: CancelTournament :execrows
+UPDATE tournaments SET cancelled_at = @cancelled_at
+WHERE id = @id AND cancelled_at IS NULL AND ends_at > @cancelled_at::timestamp;
+
+-- name: IsRegisteredForTournament :one
+SELECT EXISTS(SELECT 1 FROM tournament_registrations WHERE tournament_id = $1 AND user_id = $2);
+
+-- Registrations can only be withdrawn before the tournament starts.
+-- name: DeleteTournamentRegistration :execrows
+DELETE FROM tournament_registrations
+WHERE tournament_id = @tournament_id AND user_id = @user_id
+  AND EXISTS (SELECT 1 FROM tournaments t WHERE t.id = @tournament_id AND t.starts_at > @now::timestamp);
+
+-- name: ListTournamentRegistrants :many
+SELECT user_id FROM tournament_registrations WHERE tournament_id = $1 ORDER BY registered_at;
+
+-- Tournaments the user is registered for that have not ended, soonest
+-- first.
+-- name: ListUserTournaments :many
+SELECT sqlc.embed(t), (SELECT COUNT(*) FROM tournament_registrations c WHERE c.tournament_id = t.id) AS registrations
+FROM tournaments t
+JOIN tournament_registrations r ON r.tournament_id = t.id
+WHERE r.user_id = @user_id AND t.cancelled_at IS NULL AND t.ends_at > @now::timestamp
+ORDER BY t.starts_at;
+
+-- name: ClaimTournamentReminder :execrows
+INSERT INTO tournament_reminders (tournament_id, round) VALUES ($1, $2)
+ON CONFLICT (tournament_id, round) DO NOTHING;
@@ -0,0 +1,371 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: tournaments.sql
+
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+const cancelTournament = `-- name: CancelTournament :execrows
+UPDATE tournaments SET cancelled_at = $1
+WHERE id = $2 AND cancelled_at IS NULL AND ends_at > $1::timestamp
+`
+
+type CancelTournamentParams struct {
+	CancelledAt *time.Time
+	ID          uuid.UUID
+}
+
+func (q *Queries) CancelTournament(ctx context.Context, arg CancelTournamentParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelTournament, arg.CancelledAt, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const claimTournamentReminder = `-- name: ClaimTournamentReminder :execrows
+INSERT INTO tournament_reminders (tournament_id, round) VALUES ($1, $2)
+ON CONFLICT (tournament_id, round) DO NOTHING
+`
+
+type ClaimTournamentReminderParams struct {
+	TournamentID uuid.UUID
+	Round        int32
+}
+
+func (q *Queries) ClaimTournamentReminder(ctx context.Context, arg ClaimTournamentReminderParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimTournamentReminder, arg.TournamentID, arg.Round)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createTournament = `-- name: CreateTournament :exec
+INSERT INTO tournaments (id, name, game_type, time_control, starts_at, ends_at, rounds, round_minutes, capacity, min_rating, max_rating, created_by, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+`
+
+type CreateTournamentParams struct {
+	ID           uuid.UUID
+	Name         string
+	GameType     models.GameType
+	TimeControl  string
+	StartsAt     time.Time
+	EndsAt       time.Time
+	Rounds       int32
+	RoundMinutes int32
+	Capacity     int32
+	MinRating    sql.NullInt32
+	MaxRating    sql.NullInt32
+	CreatedBy    *uuid.UUID
+	CreatedAt    time.Time
+}
+
+func (q *Queries) CreateTournament(ctx context.Context, arg CreateTournamentParams) error {
+	_, err := q.db.ExecContext(ctx, createTournament,
+		arg.ID,
+		arg.Name,
+		arg.GameType,
+		arg.TimeControl,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Rounds,
+		arg.RoundMinutes,
+		arg.Capacity,
+		arg.MinRating,
+		arg.MaxRating,
+		arg.CreatedBy,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const deleteTournamentRegistration = `-- name: DeleteTournamentRegistration :execrows
+DELETE FROM tournament_registrations
+WHERE tournament_id = $1 AND user_id = $2
+  AND EXISTS (SELECT 1 FROM tournaments t WHERE t.id = $1 AND t.starts_at > $3::timestamp)
+`
+
+type DeleteTournamentRegistrationParams struct {
+	TournamentID uuid.UUID
+	UserID       uuid.UUID
+	Now          time.Time
+}
+
+// Registrations can only be withdrawn before the tournament starts.
+func (q *Queries) DeleteTournamentRegistration(ctx context.Context, arg DeleteTournamentRegistrationParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteTournamentRegistration, arg.TournamentID, arg.UserID, arg.Now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getTournament = `-- name: GetTournament :one
+SELECT t.id, t.name, t.game_type, t.time_control, t.starts_at, t.ends_at, t.rounds, t.round_minutes, t.capacity, t.min_rating, t.max_rating, t.created_by, t.cancelled_at, t.created_at, (SELECT COUNT(*) FROM tournament_registrations r WHERE r.tournament_id = t.id) AS registrations
+FROM tournaments t WHERE t.id = $1
+`
+
+type GetTournamentRow struct {
+	Tournament    Tournament
+	Registrations int64
+}
+
+func (q *Queries) GetTournament(ctx context.Context, id uuid.UUID) (GetTournamentRow, error) {
+	row := q.db.QueryRowContext(ctx, getTournament, id)
+	var i GetTournamentRow
+	err := row.Scan(
+		&i.Tournament.ID,
+		&i.Tournament.Name,
+		&i.Tournament.GameType,
+		&i.Tournament.TimeControl,
+		&i.Tournament.StartsAt,
+		&i.Tournament.EndsAt,
+		&i.Tournament.Rounds,
+		&i.Tournament.RoundMinutes,
+		&i.Tournament.Capacity,
+		&i.Tournament.MinRating,
+		&i.Tournament.MaxRating,
+		&i.Tournament.CreatedBy,
+		&i.Tournament.CancelledAt,
+		&i.Tournament.CreatedAt,
+		&i.Registrations,
+	)
+	return i, err
+}
+
+const isRegisteredForTournament = `-- name: IsRegisteredForTournament :one
+SELECT EXISTS(SELECT 1 FROM tournament_registrations WHERE tournament_id = $1 AND user_id = $2)
+`
+
+type IsRegisteredForTournamentParams struct {
+	TournamentID uuid.UUID
+	UserID       uuid.UUID
+}
+
+func (q *Queries) IsRegisteredForTournament(ctx context.Context, arg IsRegisteredForTournamentParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isRegisteredForTournament, arg.TournamentID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listTournamentRegistrants = `-- name: ListTournamentRegistrants :many
+SELECT user_id FROM tournament_registrations WHERE tournament_id = $1 ORDER BY registered_at
+`
+
+func (q *Queries) ListTournamentRegistrants(ctx context.Context, tournamentID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, listTournamentRegistrants, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTournamentsWithRoundsDue = `-- name: ListTournamentsWithRoundsDue :many
+SELECT t.id, t.name, t.game_type, t.time_control, t.starts_at, t.ends_at, t.rounds, t.round_minutes, t.capacity, t.min_rating, t.max_rating, t.created_by, t.cancelled_at, t.created_at, (SELECT COUNT(*) FROM tournament_registrations r WHERE r.tournament_id = t.id) AS registrations
+FROM tournaments t
+WHERE t.cancelled_at IS NULL AND t.starts_at <= $1::timestamp AND t.ends_at > $2::timestamp
+`
+
+type ListTournamentsWithRoundsDueParams struct {
+	Before time.Time
+	Now    time.Time
+}
+
+type ListTournamentsWithRoundsDueRow struct {
+	Tournament    Tournament
+	Registrations int64
+}
+
+// Tournaments that have not been cancelled with a round starting before
+// @before that has not started by @now.
+func (q *Queries) ListTournamentsWithRoundsDue(ctx context.Context, arg ListTournamentsWithRoundsDueParams) ([]ListTournamentsWithRoundsDueRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTournamentsWithRoundsDue, arg.Before, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTournamentsWithRoundsDueRow
+	for rows.Next() {
+		var i ListTournamentsWithRoundsDueRow
+		if err := rows.Scan(
+			&i.Tournament.ID,
+			&i.Tournament.Name,
+			&i.Tournament.GameType,
+			&i.Tournament.TimeControl,
+			&i.Tournament.StartsAt,
+			&i.Tournament.EndsAt,
+			&i.Tournament.Rounds,
+			&i.Tournament.RoundMinutes,
+			&i.Tournament.Capacity,
+			&i.Tournament.MinRating,
+			&i.Tournament.MaxRating,
+			&i.Tournament.CreatedBy,
+			&i.Tournament.CancelledAt,
+			&i.Tournament.CreatedAt,
+			&i.Registrations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUpcomingTournaments = `-- name: ListUpcomingTournaments :many
+SELECT t.id, t.name, t.game_type, t.time_control, t.starts_at, t.ends_at, t.rounds, t.round_minutes, t.capacity, t.min_rating, t.max_rating, t.created_by, t.cancelled_at, t.created_at, (SELECT COUNT(*) FROM tournament_registrations r WHERE r.tournament_id = t.id) AS registrations
+FROM tournaments t
+WHERE t.cancelled_at IS NULL AND t.starts_at > $1::timestamp
+  AND ($2::text = '' OR t.game_type = $2::text)
+ORDER BY t.starts_at
+LIMIT $4 OFFSET $3
+`
+
+type ListUpcomingTournamentsParams struct {
+	Now      time.Time
+	GameType string
+	Off      int32
+	Lim      int32
+}
+
+type ListUpcomingTournamentsRow struct {
+	Tournament    Tournament
+	Registrations int64
+}
+
+// Tournaments open for registration, soonest first. An empty game type
+// matches every type.
+func (q *Queries) ListUpcomingTournaments(ctx context.Context, arg ListUpcomingTournamentsParams) ([]ListUpcomingTournamentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUpcomingTournaments,
+		arg.Now,
+		arg.GameType,
+		arg.Off,
+		arg.Lim,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUpcomingTournamentsRow
+	for rows.Next() {
+		var i ListUpcomingTournamentsRow
+		if err := rows.Scan(
+			&i.Tournament.ID,
+			&i.Tournament.Name,
+			&i.Tournament.GameType,
+			&i.Tournament.TimeControl,
+			&i.Tournament.StartsAt,
+			&i.Tournament.EndsAt,
+			&i.Tournament.Rounds,
+			&i.Tournament.RoundMinutes,
+			&i.Tournament.Capacity,
+			&i.Tournament.MinRating,
+			&i.Tournament.MaxRating,
+			&i.Tournament.CreatedBy,
+			&i.Tournament.CancelledAt,
+			&i.Tournament.CreatedAt,
+			&i.Registrations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserTournaments = `-- name: ListUserTournaments :many
+SELECT t.id, t.name, t.game_type, t.time_control, t.starts_at, t.ends_at, t.rounds, t.round_minutes, t.capacity, t.min_rating, t.max_rating, t.created_by, t.cancelled_at, t.created_at, (SELECT COUNT(*) FROM tournament_registrations c WHERE c.tournament_id = t.id) AS registrations
+FROM tournaments t
+JOIN tournament_registrations r ON r.tournament_id = t.id
+WHERE r.user_id = $1 AND t.cancelled_at IS NULL AND t.ends_at > $2::timestamp
+ORDER BY t.starts_at
+`
+
+type ListUserTournamentsParams struct {
+	UserID uuid.UUID
+	Now    time.Time
+}
+
+type ListUserTournamentsRow struct {
+	Tournament    Tournament
+	Registrations int64
+}
+
+// Tournaments the user is registered for that have not ended, soonest
+// first.
+func (q *Queries) ListUserTournaments(ctx context.Context, arg ListUserTournamentsParams) ([]ListUserTournamentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserTournaments, arg.UserID, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserTournamentsRow
+	for rows.Next() {
+		var i ListUserTournamentsRow
+		if err := rows.Scan(
+			&i.Tournament.ID,
+			&i.Tournament.Name,
+			&i.Tournament.GameType,
+			&i.Tournament.TimeControl,
+			&i.Tournament.StartsAt,
+			&i.Tournament.EndsAt,
+			&i.Tournament.Rounds,
+			&i.Tournament.RoundMinutes,
+			&i.Tournament.Capacity,
+			&i.Tournament.MinRating,
+			&i.Tournament.MaxRating,
+			&i.Tournament.CreatedBy,
+			&i.Tournament.CancelledAt,
+			&i.Tournament.CreatedAt,
+			&i.Registrations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
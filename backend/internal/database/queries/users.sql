@@ -0,0 +1,69 @@
+-- name: CreateUser :exec
+INSERT INTO users (id, email, username, password_hash, created_at, updated_at, is_active, role, avatar_url, profile_visibility)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: GetUser :one
+SELECT * FROM users WHERE id = $1;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users WHERE email = $1;
+
+-- name: GetUserByIdentity :one
+SELECT u.* FROM users u
+JOIN user_identities i ON i.user_id = u.id
+WHERE i.provider = $1 AND i.subject = $2;
+
+-- name: UpdateUser :exec
+UPDATE users SET email = $2, username = $3, password_hash = $4, updated_at = $5, is_active = $6, role = $7,
+    avatar_url = $8, profile_visibility = $9
+WHERE id = $1;
+
+-- name: UpdatePassword :exec
+UPDATE users SET password_hash = $2 WHERE id = $1;
+
+-- name: UpdateEmail :exec
+UPDATE users SET email = $2 WHERE id = $1;
+
+-- name: UpdateUsername :execrows
+UPDATE users SET username = @username, username_changed_at = NOW()
+WHERE id = @id AND (username_changed_at IS NULL OR username_changed_at < @changed_before::timestamp);
+
+-- The row stays for the games the user played, with its email and username
+-- freed. Generated usernames are longer than chosen ones can be.
+-- name: SoftDeleteUser :execrows
+UPDATE users SET deleted_at = NOW(), is_active = false,
+    email = 'deleted-' || id::text || '@deleted.invalid',
+    username = 'deleted-' || LEFT(REPLACE(id::text, '-', ''), 16),
+    password_hash = '', avatar_url = '', profile_visibility = 'private'
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- Prefix matches come first, then fuzzy matches by similarity. The prefix
+-- is the lowercased query with LIKE wildcards escaped.
+-- name: SearchUsers :many
+SELECT u.id, u.username, u.avatar_url
+FROM users u
+WHERE u.deleted_at IS NULL AND u.is_active = true
+AND (lower(u.username) LIKE @prefix::text || '%' OR lower(u.username) % @query::text)
+AND NOT EXISTS (
+    SELECT 1 FROM user_blocks
+    WHERE (blocker_id = @viewer_id AND blocked_id = u.id) OR (blocker_id = u.id AND blocked_id = @viewer_id)
+)
+ORDER BY lower(u.username) LIKE @prefix::text || '%' DESC, similarity(lower(u.username), @query::text) DESC, u.username
+LIMIT @max_results;
+
+-- name: CountSavedGameFilters :one
+SELECT COUNT(*) FROM saved_game_filters WHERE user_id = @user_id;
+
+-- name: CreateSavedGameFilter :execrows
+INSERT INTO saved_game_filters (id, user_id, name, filter, created_at)
+VALUES (@id, @user_id, @name, @filter, @created_at)
+ON CONFLICT (user_id, name) DO NOTHING;
+
+-- name: ListSavedGameFilters :many
+SELECT * FROM saved_game_filters WHERE user_id = @user_id ORDER BY name;
+
+-- name: GetSavedGameFilter :one
+SELECT * FROM saved_game_filters WHERE id = @id AND user_id = @user_id;
+
+-- name: DeleteSavedGameFilter :execrows
+DELETE FROM saved_game_filters WHERE id = @id AND user_id = @user_id;
@@ -0,0 +1,392 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: users.sql
+
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+const countSavedGameFilters = `-- name: CountSavedGameFilters :one
+SELECT COUNT(*) FROM saved_game_filters WHERE user_id = $1
+`
+
+func (q *Queries) CountSavedGameFilters(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSavedGameFilters, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createSavedGameFilter = `-- name: CreateSavedGameFilter :execrows
+INSERT INTO saved_game_filters (id, user_id, name, filter, created_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, name) DO NOTHING
+`
+
+type CreateSavedGameFilterParams struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Name      string
+	Filter    models.GameFilter
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateSavedGameFilter(ctx context.Context, arg CreateSavedGameFilterParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createSavedGameFilter,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Filter,
+		arg.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createUser = `-- name: CreateUser :exec
+INSERT INTO users (id, email, username, password_hash, created_at, updated_at, is_active, role, avatar_url, profile_visibility)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+`
+
+type CreateUserParams struct {
+	ID                uuid.UUID
+	Email             string
+	Username          string
+	PasswordHash      string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	IsActive          bool
+	Role              models.UserRole
+	AvatarUrl         string
+	ProfileVisibility models.ProfileVisibility
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) error {
+	_, err := q.db.ExecContext(ctx, createUser,
+		arg.ID,
+		arg.Email,
+		arg.Username,
+		arg.PasswordHash,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.IsActive,
+		arg.Role,
+		arg.AvatarUrl,
+		arg.ProfileVisibility,
+	)
+	return err
+}
+
+const deleteSavedGameFilter = `-- name: DeleteSavedGameFilter :execrows
+DELETE FROM saved_game_filters WHERE id = $1 AND user_id = $2
+`
+
+type DeleteSavedGameFilterParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteSavedGameFilter(ctx context.Context, arg DeleteSavedGameFilterParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSavedGameFilter, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getSavedGameFilter = `-- name: GetSavedGameFilter :one
+SELECT id, user_id, name, filter, created_at FROM saved_game_filters WHERE id = $1 AND user_id = $2
+`
+
+type GetSavedGameFilterParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetSavedGameFilter(ctx context.Context, arg GetSavedGameFilterParams) (SavedGameFilter, error) {
+	row := q.db.QueryRowContext(ctx, getSavedGameFilter, arg.ID, arg.UserID)
+	var i SavedGameFilter
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Filter,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT id, email, username, password_hash, created_at, updated_at, is_active, role, avatar_url, profile_visibility, username_changed_at, deleted_at FROM users WHERE id = $1
+`
+
+func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsActive,
+		&i.Role,
+		&i.AvatarUrl,
+		&i.ProfileVisibility,
+		&i.UsernameChangedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, email, username, password_hash, created_at, updated_at, is_active, role, avatar_url, profile_visibility, username_changed_at, deleted_at FROM users WHERE email = $1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsActive,
+		&i.Role,
+		&i.AvatarUrl,
+		&i.ProfileVisibility,
+		&i.UsernameChangedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getUserByIdentity = `-- name: GetUserByIdentity :one
+SELECT u.id, u.email, u.username, u.password_hash, u.created_at, u.updated_at, u.is_active, u.role, u.avatar_url, u.profile_visibility, u.username_changed_at, u.deleted_at FROM users u
+JOIN user_identities i ON i.user_id = u.id
+WHERE i.provider = $1 AND i.subject = $2
+`
+
+type GetUserByIdentityParams struct {
+	Provider string
+	Subject  string
+}
+
+func (q *Queries) GetUserByIdentity(ctx context.Context, arg GetUserByIdentityParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByIdentity, arg.Provider, arg.Subject)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsActive,
+		&i.Role,
+		&i.AvatarUrl,
+		&i.ProfileVisibility,
+		&i.UsernameChangedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const listSavedGameFilters = `-- name: ListSavedGameFilters :many
+SELECT id, user_id, name, filter, created_at FROM saved_game_filters WHERE user_id = $1 ORDER BY name
+`
+
+func (q *Queries) ListSavedGameFilters(ctx context.Context, userID uuid.UUID) ([]SavedGameFilter, error) {
+	rows, err := q.db.QueryContext(ctx, listSavedGameFilters, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SavedGameFilter
+	for rows.Next() {
+		var i SavedGameFilter
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Filter,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchUsers = `-- name: SearchUsers :many
+SELECT u.id, u.username, u.avatar_url
+FROM users u
+WHERE u.deleted_at IS NULL AND u.is_active = true
+AND (lower(u.username) LIKE $1::text || '%' OR lower(u.username) % $2::text)
+AND NOT EXISTS (
+    SELECT 1 FROM user_blocks
+    WHERE (blocker_id = $3 AND blocked_id = u.id) OR (blocker_id = u.id AND blocked_id = $3)
+)
+ORDER BY lower(u.username) LIKE $1::text || '%' DESC, similarity(lower(u.username), $2::text) DESC, u.username
+LIMIT $4
+`
+
+type SearchUsersParams struct {
+	Prefix     string
+	Query      string
+	ViewerID   uuid.UUID
+	MaxResults int32
+}
+
+type SearchUsersRow struct {
+	ID        uuid.UUID
+	Username  string
+	AvatarUrl string
+}
+
+// Prefix matches come first, then fuzzy matches by similarity. The prefix
+// is the lowercased query with LIKE wildcards escaped.
+func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]SearchUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchUsers,
+		arg.Prefix,
+		arg.Query,
+		arg.ViewerID,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchUsersRow
+	for rows.Next() {
+		var i SearchUsersRow
+		if err := rows.Scan(&i.ID, &i.Username, &i.AvatarUrl); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteUser = `-- name: SoftDeleteUser :execrows
+UPDATE users SET deleted_at = NOW(), is_active = false,
+    email = 'deleted-' || id::text || '@deleted.invalid',
+    username = 'deleted-' || LEFT(REPLACE(id::text, '-', ''), 16),
+    password_hash = '', avatar_url = '', profile_visibility = 'private'
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+// The row stays for the games the user played, with its email and username
+// freed. Generated usernames are longer than chosen ones can be.
+func (q *Queries) SoftDeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, softDeleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateEmail = `-- name: UpdateEmail :exec
+UPDATE users SET email = $2 WHERE id = $1
+`
+
+type UpdateEmailParams struct {
+	ID    uuid.UUID
+	Email string
+}
+
+func (q *Queries) UpdateEmail(ctx context.Context, arg UpdateEmailParams) error {
+	_, err := q.db.ExecContext(ctx, updateEmail, arg.ID, arg.Email)
+	return err
+}
+
+const updatePassword = `-- name: UpdatePassword :exec
+UPDATE users SET password_hash = $2 WHERE id = $1
+`
+
+type UpdatePasswordParams struct {
+	ID           uuid.UUID
+	PasswordHash string
+}
+
+func (q *Queries) UpdatePassword(ctx context.Context, arg UpdatePasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updatePassword, arg.ID, arg.PasswordHash)
+	return err
+}
+
+const updateUser = `-- name: UpdateUser :exec
+UPDATE users SET email = $2, username = $3, password_hash = $4, updated_at = $5, is_active = $6, role = $7,
+    avatar_url = $8, profile_visibility = $9
+WHERE id = $1
+`
+
+type UpdateUserParams struct {
+	ID                uuid.UUID
+	Email             string
+	Username          string
+	PasswordHash      string
+	UpdatedAt         time.Time
+	IsActive          bool
+	Role              models.UserRole
+	AvatarUrl         string
+	ProfileVisibility models.ProfileVisibility
+}
+
+func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
+	_, err := q.db.ExecContext(ctx, updateUser,
+		arg.ID,
+		arg.Email,
+		arg.Username,
+		arg.PasswordHash,
+		arg.UpdatedAt,
+		arg.IsActive,
+		arg.Role,
+		arg.AvatarUrl,
+		arg.ProfileVisibility,
+	)
+	return err
+}
+
+const updateUsername = `-- name: UpdateUsername :execrows
+UPDATE users SET username = $1, username_changed_at = NOW()
+WHERE id = $2 AND (username_changed_at IS NULL OR username_changed_at < $3::timestamp)
+`
+
+type UpdateUsernameParams struct {
+	Username      string
+	ID            uuid.UUID
+	ChangedBefore time.Time
+}
+
+func (q *Queries) UpdateUsername(ctx context.Context, arg UpdateUsernameParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUsername, arg.Username, arg.ID, arg.ChangedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,348 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// sqliteDriverName is the database/sql driver that runs the Postgres SQL in
+// this package against SQLite; see rewriteForSQLite.
+const sqliteDriverName = "vibeboard-sqlite"
+
+// sqliteMemory is the SQLite path for a throwaway database.
+const sqliteMemory = ":memory:"
+
+// sqliteTimeFormat is how times are stored, so they compare as text.
+const sqliteTimeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+func init() {
+	// Functions registered below live on the SQLite package's own driver,
+	// so wrap that one rather than a new instance
+	pool, err := sql.Open("sqlite", "")
+	if err != nil {
+		panic(err)
+	}
+	sql.Register(sqliteDriverName, &sqliteDriver{base: pool.Driver()})
+	pool.Close()
+
+	sqlite.MustRegisterScalarFunction("now", 0, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		return time.Now().UTC().Format(sqliteTimeFormat), nil
+	})
+	sqlite.MustRegisterDeterministicScalarFunction("similarity", 2, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		a, _ := args[0].(string)
+		b, _ := args[1].(string)
+		return trigramSimilarity(a, b), nil
+	})
+	sqlite.MustRegisterDeterministicScalarFunction("pg_left", 2, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		s, _ := args[0].(string)
+		n, _ := args[1].(int64)
+		if runes := []rune(s); int64(len(runes)) > n {
+			return string(runes[:n]), nil
+		}
+		return s, nil
+	})
+	sqlite.MustRegisterDeterministicScalarFunction("pg_array", 1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		s, _ := args[0].(string)
+		return pgArrayToJSON(s)
+	})
+}
+
+// openSQLite opens the SQLite database at path, creating it if needed. The
+// returned cleanup removes a throwaway database once the pool is closed.
+func openSQLite(path string, cfg *config.DatabaseConfig) (*sql.DB, func(), error) {
+	cleanup := func() {}
+	if path == sqliteMemory {
+		// Each connection to :memory: would get its own database, so a
+		// temporary file stands in for it
+		dir, err := os.MkdirTemp("", "vibeboard-sqlite-")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+		path = filepath.Join(dir, "vibeboard.db")
+		cleanup = func() { os.RemoveAll(dir) }
+	}
+
+	// Immediate transactions wait for the write lock up front instead of
+	// failing when a read tries to become a write
+	dsn := "file:" + path + "?_time_format=sqlite&_txlock=immediate" +
+		"&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+	conn, err := openPool(sqliteDriverName, dsn, cfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return conn, cleanup, nil
+}
+
+// isSQLiteUniqueViolation reports whether err is a SQLite unique or primary
+// key violation.
+func isSQLiteUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code()
+	return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+var (
+	sqliteCast       = regexp.MustCompile(`::\w+(\[\])?`)
+	sqliteParam      = regexp.MustCompile(`\$(\d+)`)
+	sqliteAny        = regexp.MustCompile(`=\s*ANY\((\?\d+)\)`)
+	sqliteForUpdate  = regexp.MustCompile(`\s+FOR UPDATE\b`)
+	sqliteGreatest   = regexp.MustCompile(`\bGREATEST\(`)
+	sqliteLeast      = regexp.MustCompile(`\bLEAST\(`)
+	sqliteBoolOr     = regexp.MustCompile(`\bBOOL_OR\(`)
+	sqliteLeft       = regexp.MustCompile(`\bLEFT\(`)
+	sqliteTrigramOp  = regexp.MustCompile(`(lower\([\w.]+\)) % (\?\d+)`)
+	sqliteRewritten  sync.Map // query -> rewritten query
+	sqliteTimeString = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}`)
+)
+
+// rewriteForSQLite translates the Postgres dialect used in this package to
+// SQLite. It only covers the constructs the package uses; queries should
+// stay within them.
+func rewriteForSQLite(query string) string {
+	if rewritten, ok := sqliteRewritten.Load(query); ok {
+		return rewritten.(string)
+	}
+
+	rewritten := sqliteCast.ReplaceAllString(query, "")
+	rewritten = sqliteParam.ReplaceAllString(rewritten, "?$1")
+	rewritten = sqliteAny.ReplaceAllString(rewritten, "IN (SELECT value FROM json_each(pg_array($1)))")
+	rewritten = sqliteForUpdate.ReplaceAllString(rewritten, "")
+	rewritten = sqliteGreatest.ReplaceAllString(rewritten, "MAX(")
+	rewritten = sqliteLeast.ReplaceAllString(rewritten, "MIN(")
+	rewritten = sqliteBoolOr.ReplaceAllString(rewritten, "MAX(")
+	rewritten = sqliteLeft.ReplaceAllString(rewritten, "pg_left(")
+	rewritten = sqliteTrigramOp.ReplaceAllString(rewritten, "similarity($1, $2) >= 0.3")
+
+	sqliteRewritten.Store(query, rewritten)
+	return rewritten
+}
+
+// pgArrayToJSON converts a Postgres array literal, as pq.Array writes it,
+// to a JSON array for json_each.
+func pgArrayToJSON(literal string) (string, error) {
+	literal = strings.TrimSpace(literal)
+	if len(literal) < 2 || literal[0] != '{' || literal[len(literal)-1] != '}' {
+		return "", fmt.Errorf("malformed array literal %q", literal)
+	}
+	body := literal[1 : len(literal)-1]
+
+	elems := []any{}
+	for i := 0; i < len(body); {
+		if body[i] == '"' {
+			var elem strings.Builder
+			for i++; i < len(body) && body[i] != '"'; i++ {
+				if body[i] == '\\' && i+1 < len(body) {
+					i++
+				}
+				elem.WriteByte(body[i])
+			}
+			i++ // closing quote
+			elems = append(elems, elem.String())
+		} else {
+			end := strings.IndexByte(body[i:], ',')
+			if end < 0 {
+				end = len(body) - i
+			}
+			elem := strings.TrimSpace(body[i : i+end])
+			i += end
+			if elem == "NULL" {
+				elems = append(elems, nil)
+			} else {
+				elems = append(elems, elem)
+			}
+		}
+		if i < len(body) && body[i] == ',' {
+			i++
+		}
+	}
+
+	data, err := json.Marshal(elems)
+	return string(data), err
+}
+
+// trigramSimilarity approximates pg_trgm's similarity: the share of
+// trigrams of the padded words that both strings have.
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for trigram := range ta {
+		if tb[trigram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(strings.ToLower(s)) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// sqliteDriver wraps the SQLite driver to rewrite queries and normalize
+// values on their way in and out.
+type sqliteDriver struct {
+	base driver.Driver
+}
+
+type sqliteInnerConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.QueryerContext
+	driver.ExecerContext
+	driver.Pinger
+}
+
+func (d *sqliteDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.base.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	inner, ok := conn.(sqliteInnerConn)
+	if !ok {
+		conn.Close()
+		return nil, errors.New("sqlite driver does not support contexts")
+	}
+	return &sqliteConn{inner: inner}, nil
+}
+
+type sqliteConn struct {
+	inner sqliteInnerConn
+}
+
+func (c *sqliteConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqliteConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.inner.PrepareContext(ctx, rewriteForSQLite(query))
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteStmt{Stmt: stmt}, nil
+}
+
+func (c *sqliteConn) Close() error {
+	return c.inner.Close()
+}
+
+func (c *sqliteConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *sqliteConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.inner.BeginTx(ctx, opts)
+}
+
+func (c *sqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.inner.QueryContext(ctx, rewriteForSQLite(query), args)
+	if err != nil {
+		return nil, err
+	}
+	return newSQLiteRows(rows), nil
+}
+
+func (c *sqliteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.inner.ExecContext(ctx, rewriteForSQLite(query), args)
+}
+
+func (c *sqliteConn) Ping(ctx context.Context) error {
+	return c.inner.Ping(ctx)
+}
+
+// CheckNamedValue stores times in UTC so they compare as text.
+func (c *sqliteConn) CheckNamedValue(nv *driver.NamedValue) error {
+	value, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	if err != nil {
+		return err
+	}
+	if t, ok := value.(time.Time); ok {
+		value = t.UTC()
+	}
+	nv.Value = value
+	return nil
+}
+
+type sqliteStmt struct {
+	driver.Stmt
+}
+
+func (s *sqliteStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows, err := s.Stmt.Query(args)
+	if err != nil {
+		return nil, err
+	}
+	return newSQLiteRows(rows), nil
+}
+
+// sqliteRows parses times in computed columns. SQLite only returns times
+// for columns declared as timestamps, so MAX(created_at) would otherwise
+// come back as text.
+type sqliteRows struct {
+	driver.Rows
+	computed []bool
+}
+
+func newSQLiteRows(rows driver.Rows) driver.Rows {
+	typed, ok := rows.(driver.RowsColumnTypeDatabaseTypeName)
+	if !ok {
+		return rows
+	}
+	columns := rows.Columns()
+	computed := make([]bool, len(columns))
+	for i := range columns {
+		computed[i] = typed.ColumnTypeDatabaseTypeName(i) == ""
+	}
+	return &sqliteRows{Rows: rows, computed: computed}
+}
+
+func (r *sqliteRows) Next(dest []driver.Value) error {
+	if err := r.Rows.Next(dest); err != nil {
+		return err
+	}
+	for i, value := range dest {
+		s, ok := value.(string)
+		if !ok || !r.computed[i] || !sqliteTimeString.MatchString(s) {
+			continue
+		}
+		if t, err := time.Parse(sqliteTimeFormat, s); err == nil {
+			dest[i] = t
+		} else if t, err := time.Parse(time.DateTime, s); err == nil {
+			dest[i] = t
+		}
+	}
+	return nil
+}
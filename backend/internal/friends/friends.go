@@ -0,0 +1,210 @@
+package friends
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+var (
+	ErrCannotFriendSelf      = errors.New("cannot_friend_self")
+	ErrFriendBlocked         = errors.New("friend_blocked")
+	ErrAlreadyFriends        = errors.New("already_friends")
+	ErrFriendRequestPending  = errors.New("friend_request_pending")
+	ErrFriendRequestNotFound = errors.New("friend_request_not_found")
+	ErrFriendNotFound        = errors.New("friend_not_found")
+)
+
+// Event names sent through the Notifier.
+const (
+	EventFriendRequest  = "friend_request"
+	EventFriendAccepted = "friend_accepted"
+)
+
+// presenceWindow is how recently a friend must have been seen on a
+// connection to be shown online. It covers the hub's refresh interval.
+const presenceWindow = 90 * time.Second
+
+// Notifier delivers friend events to a user's connections.
+type Notifier interface {
+	NotifyUser(userID uuid.UUID, event string, data interface{}) error
+}
+
+// PresenceChecker reports whether a user has a live connection.
+type PresenceChecker interface {
+	IsConnected(userID uuid.UUID, within time.Duration) (bool, error)
+}
+
+// FriendEventData identifies the other user in a friend event.
+type FriendEventData struct {
+	Friend *models.Friend `json:"friend"`
+}
+
+type Service struct {
+	db       *database.DB
+	notifier Notifier
+	presence PresenceChecker
+}
+
+func NewService(db *database.DB) *Service {
+	return &Service{db: db}
+}
+
+// SetNotifier must be called before requests are handled. Without it users
+// are not told when requests arrive or are accepted.
+func (s *Service) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
+// SetPresenceChecker must be called before requests are handled. Without it
+// every friend is listed as offline.
+func (s *Service) SetPresenceChecker(presence PresenceChecker) {
+	s.presence = presence
+}
+
+// SendRequest asks friendID to be userID's friend. If friendID already asked
+// userID, their request is accepted instead.
+func (s *Service) SendRequest(userID, friendID uuid.UUID) (models.FriendshipStatus, error) {
+	if userID == friendID {
+		return "", ErrCannotFriendSelf
+	}
+
+	blocked, err := s.db.IsBlocked(userID, friendID)
+	if err != nil {
+		return "", fmt.Errorf("failed to check block list: %w", err)
+	}
+	if blocked {
+		return "", ErrFriendBlocked
+	}
+
+	existing, err := s.db.GetFriendship(userID, friendID)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return "", fmt.Errorf("failed to get friendship: %w", err)
+	case existing.Status == models.FriendshipStatusAccepted:
+		return "", ErrAlreadyFriends
+	case existing.UserID == friendID:
+		if err := s.Accept(userID, friendID); err != nil {
+			return "", err
+		}
+		return models.FriendshipStatusAccepted, nil
+	default:
+		return "", ErrFriendRequestPending
+	}
+
+	created, err := s.db.CreateFriendRequest(userID, friendID)
+	if err != nil {
+		return "", fmt.Errorf("failed to create friend request: %w", err)
+	}
+	if !created {
+		return "", ErrFriendRequestPending
+	}
+
+	s.notify(friendID, EventFriendRequest, userID)
+	return models.FriendshipStatusPending, nil
+}
+
+// Accept accepts the pending request requesterID sent to userID.
+func (s *Service) Accept(userID, requesterID uuid.UUID) error {
+	accepted, err := s.db.AcceptFriendRequest(requesterID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to accept friend request: %w", err)
+	}
+	if !accepted {
+		return ErrFriendRequestNotFound
+	}
+
+	s.notify(requesterID, EventFriendAccepted, userID)
+	return nil
+}
+
+// Decline discards the pending request requesterID sent to userID. The
+// requester is not told.
+func (s *Service) Decline(userID, requesterID uuid.UUID) error {
+	deleted, err := s.db.DeleteFriendRequest(requesterID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to decline friend request: %w", err)
+	}
+	if !deleted {
+		return ErrFriendRequestNotFound
+	}
+	return nil
+}
+
+// Remove ends a friendship, or withdraws a request either user sent.
+func (s *Service) Remove(userID, friendID uuid.UUID) error {
+	deleted, err := s.db.DeleteFriendship(userID, friendID)
+	if err != nil {
+		return fmt.Errorf("failed to remove friend: %w", err)
+	}
+	if !deleted {
+		return ErrFriendNotFound
+	}
+	return nil
+}
+
+// List returns the user's friends with whether each is online.
+func (s *Service) List(userID uuid.UUID) ([]*models.Friend, error) {
+	friends, err := s.db.GetFriends(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get friends: %w", err)
+	}
+
+	if s.presence != nil {
+		for _, friend := range friends {
+			online, err := s.presence.IsConnected(friend.UserID, presenceWindow)
+			if err != nil {
+				log.Printf("Error checking presence for %s: %v", friend.UserID, err)
+				continue
+			}
+			friend.Online = online
+		}
+	}
+
+	if friends == nil {
+		friends = []*models.Friend{}
+	}
+	return friends, nil
+}
+
+// Requests returns the pending requests sent to the user.
+func (s *Service) Requests(userID uuid.UUID) ([]*models.Friend, error) {
+	requests, err := s.db.GetFriendRequests(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get friend requests: %w", err)
+	}
+	if requests == nil {
+		requests = []*models.Friend{}
+	}
+	return requests, nil
+}
+
+// notify tells userID about an event involving otherID.
+func (s *Service) notify(userID uuid.UUID, event string, otherID uuid.UUID) {
+	if s.notifier == nil {
+		return
+	}
+
+	other, err := s.db.GetUser(otherID)
+	if err != nil {
+		log.Printf("Error getting user %s for %s: %v", otherID, event, err)
+		return
+	}
+
+	data := FriendEventData{Friend: &models.Friend{
+		UserID:    other.ID,
+		Username:  other.Username,
+		AvatarURL: other.AvatarURL,
+		Since:     time.Now(),
+	}}
+	if err := s.notifier.NotifyUser(userID, event, data); err != nil {
+		log.Printf("Error notifying user %s of %s: %v", userID, event, err)
+	}
+}
@@ -0,0 +1,161 @@
+package game
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// Reasons a game ended without a move.
+const (
+	// EndReasonForfeit means the other player left and the game was
+	// awarded to the one still connected
+	EndReasonForfeit = "forfeit"
+	// EndReasonAbandoned means both players left
+	EndReasonAbandoned = "abandoned"
+	// EndReasonCancelled means the creator cancelled the game before it
+	// started
+	EndReasonCancelled = "cancelled"
+	// EndReasonResigned means a player resigned
+	EndReasonResigned = "resigned"
+	// EndReasonAdjudicated means a moderator settled the game
+	EndReasonAdjudicated = "adjudicated"
+	// EndReasonStalemated means the player to move had no move, drawing
+	// the game
+	EndReasonStalemated = "stalemated"
+)
+
+const abandonmentInterval = 15 * time.Second
+
+// PresenceChecker reports whether a user still has a live connection.
+type PresenceChecker interface {
+	IsConnected(userID uuid.UUID, within time.Duration) (bool, error)
+}
+
+// GameNotifier tells a game's room that the game ended without a move.
+type GameNotifier interface {
+	NotifyGameEnded(game *models.Game, reason string)
+}
+
+// AbandonmentService ends in-progress games that players have left, so
+// they are not stuck in progress forever. A player has left when none of
+// their connections has been seen for the timeout. Games are forfeited to
+// a player who is still connected and abandoned when both have left.
+type AbandonmentService struct {
+	db       *database.DB
+	timeout  time.Duration
+	presence PresenceChecker
+	notifier GameNotifier
+	results  ResultRecorder
+}
+
+func NewAbandonmentService(db *database.DB, timeout time.Duration) *AbandonmentService {
+	return &AbandonmentService{
+		db:      db,
+		timeout: timeout,
+	}
+}
+
+// SetPresenceChecker must be called before Start. Without it no game is
+// ever considered left.
+func (s *AbandonmentService) SetPresenceChecker(presence PresenceChecker) {
+	s.presence = presence
+}
+
+// SetNotifier must be called before Start. Without it rooms are not told
+// when their game ends.
+func (s *AbandonmentService) SetNotifier(notifier GameNotifier) {
+	s.notifier = notifier
+}
+
+// SetResultRecorder must be called before Start.
+func (s *AbandonmentService) SetResultRecorder(results ResultRecorder) {
+	s.results = results
+}
+
+func (s *AbandonmentService) Start() {
+	if s.timeout <= 0 || s.presence == nil {
+		log.Println("Abandonment detection disabled")
+		return
+	}
+
+	log.Printf("Starting abandonment detection (timeout: %s)...", s.timeout)
+
+	ticker := time.NewTicker(abandonmentInterval)
+	go func() {
+		for range ticker.C {
+			s.processAbandoned()
+		}
+	}()
+}
+
+func (s *AbandonmentService) processAbandoned() {
+	// Players get the timeout to connect once their game starts
+	games, err := s.db.GetInProgressGames(time.Now().Add(-s.timeout))
+	if err != nil {
+		log.Printf("Error getting in-progress games: %v", err)
+		return
+	}
+
+	for _, game := range games {
+		if err := s.checkGame(game); err != nil {
+			log.Printf("Error checking game %s for abandonment: %v", game.ID, err)
+		}
+	}
+}
+
+func (s *AbandonmentService) checkGame(game *models.Game) error {
+	players := game.PlayerIDs()
+	if len(players) < 2 {
+		return nil
+	}
+
+	// Presence errors leave the game alone rather than forfeit it
+	var present []uuid.UUID
+	for _, playerID := range players {
+		connected, err := s.presence.IsConnected(playerID, s.timeout)
+		if err != nil {
+			return err
+		}
+		if connected {
+			present = append(present, playerID)
+		}
+	}
+
+	reason := EndReasonForfeit
+	switch len(present) {
+	case len(players):
+		return nil
+	case 1:
+		game.Status = models.GameStatusCompleted
+		game.WinnerID = &present[0]
+	case 0:
+		reason = EndReasonAbandoned
+		game.Status = models.GameStatusAbandoned
+		game.WinnerID = nil
+	default:
+		// The players still present play on
+		return nil
+	}
+
+	now := time.Now()
+	game.CurrentTurn = nil
+	game.EndedAt = &now
+	game.EndReason = models.EndReasonAbandonment
+
+	// Another instance, or a final move, may have ended it first
+	ended, err := s.db.EndGame(game)
+	if err != nil || !ended {
+		return err
+	}
+
+	log.Printf("Game %s ended by %s", game.ID, reason)
+	recordResult(s.results, game)
+	if s.notifier != nil {
+		s.notifier.NotifyGameEnded(game, reason)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package game
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// ErrGameNotAdjudicable is returned for games that have not started or
+// were abandoned, which have no result to settle.
+var ErrGameNotAdjudicable = errors.New("game_not_adjudicable")
+
+// Adjudicate settles a game as a moderator decided: winnerID wins, or it is
+// drawn, or voided as abandoned. An in-progress game, paused or not, ends.
+// A completed game has its result overturned, with the old one taken back
+// out of the players' stats and ratings before the new one is counted.
+func (s *MoveService) Adjudicate(gameID uuid.UUID, decision models.AdjudicationDecision, winnerID *uuid.UUID) (*models.Game, error) {
+	lock := &s.locks[int(gameID[0])%moveLockStripes]
+	lock.Lock()
+	defer lock.Unlock()
+
+	game, err := s.db.GetGame(gameID)
+	if err == sql.ErrNoRows {
+		return nil, ErrGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if game.Status != models.GameStatusInProgress && game.Status != models.GameStatusCompleted {
+		return nil, ErrGameNotAdjudicable
+	}
+	if !game.IsFull() {
+		return nil, ErrGameNotAdjudicable
+	}
+	if winnerID != nil && !game.HasPlayer(*winnerID) {
+		return nil, ErrNotInGame
+	}
+
+	previous := *game
+	game.WinnerID = nil
+	game.EndReason = models.EndReasonAdjudication
+	game.CurrentTurn = nil
+	switch decision {
+	case models.AdjudicationDecisionWinner:
+		game.Status = models.GameStatusCompleted
+		game.WinnerID = winnerID
+	case models.AdjudicationDecisionDraw:
+		game.Status = models.GameStatusCompleted
+	default:
+		game.Status = models.GameStatusAbandoned
+	}
+
+	if previous.Status == models.GameStatusInProgress {
+		now := time.Now()
+		game.EndedAt = &now
+		ended, err := s.db.EndGame(game)
+		if err != nil {
+			return nil, err
+		}
+		if !ended {
+			return nil, ErrGameNotAdjudicable
+		}
+		recordResult(s.results, game)
+		return game, nil
+	}
+
+	overturned, err := s.db.OverturnGameResult(&previous, game)
+	if err != nil {
+		return nil, err
+	}
+	if !overturned {
+		return nil, ErrGameNotAdjudicable
+	}
+	// A voided game counts for nobody, and its series already counted it
+	if game.Status == models.GameStatusCompleted {
+		recordResult(s.results, game)
+	}
+	return game, nil
+}
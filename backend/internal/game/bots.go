@@ -0,0 +1,234 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// ErrUnknownBot is returned for a bot level that has no bot.
+var ErrUnknownBot = errors.New("unknown_bot")
+
+const botMoveInterval = time.Second
+
+// botMoveTimeout is how long a bot's move may take before another instance
+// takes it over.
+const botMoveTimeout = 30 * time.Second
+
+// MoveNotifier tells a game's room about moves made outside it, and that
+// the game ended without a move.
+type MoveNotifier interface {
+	GameNotifier
+	NotifyGameMove(game *models.Game, playerID uuid.UUID, move json.RawMessage)
+}
+
+// BotService seats chess bots in casual games against players who want an
+// opponent now, and makes the bots' moves. Each move is claimed in the
+// database before it is made, so it is made once across instances.
+type BotService struct {
+	db       *database.DB
+	registry *EngineRegistry
+	moves    *MoveService
+	starters *StarterSelector
+	notifier MoveNotifier
+
+	mutex sync.RWMutex
+	bots  []*models.Bot
+}
+
+func NewBotService(db *database.DB, registry *EngineRegistry, moves *MoveService, starters *StarterSelector) *BotService {
+	return &BotService{
+		db:       db,
+		registry: registry,
+		moves:    moves,
+		starters: starters,
+	}
+}
+
+// SetNotifier must be called before Start. Without it rooms only see the
+// bots' moves once they reload the game.
+func (s *BotService) SetNotifier(notifier MoveNotifier) {
+	s.notifier = notifier
+}
+
+// Start loads the bots and begins making their moves.
+func (s *BotService) Start() {
+	bots, err := s.db.GetBots()
+	if err != nil {
+		log.Printf("Error loading bots: %v", err)
+	}
+	if len(bots) == 0 {
+		log.Println("Chess bots disabled")
+		return
+	}
+
+	s.mutex.Lock()
+	s.bots = bots
+	s.mutex.Unlock()
+
+	log.Printf("Starting chess bots (%d levels)...", len(bots))
+
+	ticker := time.NewTicker(botMoveInterval)
+	go func() {
+		for range ticker.C {
+			s.makeMoves()
+		}
+	}()
+}
+
+// Bots returns the bots players may play against, weakest first.
+func (s *BotService) Bots() []*models.Bot {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.bots
+}
+
+func (s *BotService) botAt(level models.BotLevel) *models.Bot {
+	for _, bot := range s.Bots() {
+		if bot.Level == level {
+			return bot
+		}
+	}
+	return nil
+}
+
+func (s *BotService) botFor(userID uuid.UUID) *models.Bot {
+	for _, bot := range s.Bots() {
+		if bot.UserID == userID {
+			return bot
+		}
+	}
+	return nil
+}
+
+// NewGame starts a casual chess game between the player and the bot of the
+// level. Who moves first follows the starter policy.
+func (s *BotService) NewGame(playerID uuid.UUID, level models.BotLevel, settings models.GameSettings) (*models.Game, error) {
+	bot := s.botAt(level)
+	if bot == nil {
+		return nil, ErrUnknownBot
+	}
+
+	engine, err := s.registry.GetEngine(models.GameTypeChess)
+	if err != nil {
+		return nil, err
+	}
+	settings.Rated = false
+	if err := ApplySettings(engine, &settings); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	game := &models.Game{
+		ID:           uuid.New(),
+		Type:         models.GameTypeChess,
+		Status:       models.GameStatusInProgress,
+		StartedAt:    &now,
+		Settings:     settings,
+		MoveDeadline: settings.MoveDeadline(now),
+	}
+	game.SetPlayers(playerID, bot.UserID)
+	if err := s.starters.InitializeGame(engine, game); err != nil {
+		return nil, fmt.Errorf("failed to initialize game state: %w", err)
+	}
+
+	if err := s.db.CreateGame(game); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+// Presence reports bots as always connected, and other players as presence
+// does, so games are not forfeited to a player for their bot's absence.
+func (s *BotService) Presence(presence PresenceChecker) PresenceChecker {
+	return botPresence{bots: s, presence: presence}
+}
+
+type botPresence struct {
+	bots     *BotService
+	presence PresenceChecker
+}
+
+func (p botPresence) IsConnected(userID uuid.UUID, within time.Duration) (bool, error) {
+	if p.bots.botFor(userID) != nil {
+		return true, nil
+	}
+	return p.presence.IsConnected(userID, within)
+}
+
+func (s *BotService) makeMoves() {
+	games, err := s.db.GetBotTurnGames()
+	if err != nil {
+		log.Printf("Error getting games awaiting a bot's move: %v", err)
+		return
+	}
+
+	for _, game := range games {
+		if err := s.makeMove(game); err != nil {
+			log.Printf("Error making bot move in game %s: %v", game.ID, err)
+		}
+	}
+}
+
+func (s *BotService) makeMove(game *models.Game) error {
+	bot := s.botFor(*game.CurrentTurn)
+	if bot == nil {
+		return nil
+	}
+	player, ok := ChessBotFor(bot.Level)
+	if !ok || game.Type != models.GameTypeChess {
+		return fmt.Errorf("bot %s cannot play %s", bot.Level, game.Type)
+	}
+
+	claimed, err := s.db.ClaimBotMove(game.ID, game.MoveCount, botMoveTimeout)
+	if err != nil || !claimed {
+		return err
+	}
+
+	if err := s.play(bot, player, game); err != nil {
+		// Leave the turn to be tried again
+		if releaseErr := s.db.ReleaseBotMove(game.ID, game.MoveCount); releaseErr != nil {
+			log.Printf("Error releasing bot move in game %s: %v", game.ID, releaseErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// play makes the bot's move in the game. A bot with no move draws the game.
+func (s *BotService) play(bot *models.Bot, player ChessBot, game *models.Game) error {
+	move, err := player.Move(game.GameState, bot.UserID)
+	if errors.Is(err, ErrNoMoves) {
+		updated, err := s.moves.Stalemate(game.ID, bot.UserID)
+		if err != nil {
+			return err
+		}
+		if s.notifier != nil {
+			s.notifier.NotifyGameEnded(updated, EndReasonStalemated)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	updated, err := s.moves.ProcessMove(game.ID, bot.UserID, move)
+	if errors.Is(err, ErrGameChanged) || errors.Is(err, ErrGameNotInProgress) {
+		// The turn timed out or the game ended meanwhile
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if s.notifier != nil {
+		s.notifier.NotifyGameMove(updated, bot.UserID, move)
+	}
+	return nil
+}
@@ -27,6 +27,7 @@ type ChessGameState struct {
 	BlackPlayer uuid.UUID         `json:"black_player"`
 	GameEnded   bool              `json:"game_ended"`
 	Winner      *uuid.UUID        `json:"winner,omitempty"`
+	EndReason   models.EndReason  `json:"end_reason,omitempty"`
 	Check       bool              `json:"check"`
 	Checkmate   bool              `json:"checkmate"`
 	Stalemate   bool              `json:"stalemate"`
@@ -57,9 +58,28 @@ func (e *ChessEngine) GetGameType() models.GameType {
 	return models.GameTypeChess
 }
 
-func (e *ChessEngine) Initialize() (json.RawMessage, error) {
+// SettingsSchema offers standard chess on the standard board.
+func (e *ChessEngine) SettingsSchema() SettingsSchema {
+	return SettingsSchema{Variants: []string{"standard"}, BoardSizes: []int{8}}
+}
+
+func (e *ChessEngine) Initialize(players []uuid.UUID, options GameOptions) (json.RawMessage, error) {
+	if len(players) != 2 {
+		return nil, errors.New("chess needs exactly two players")
+	}
+
+	// The starter plays white
+	white, black := players[0], players[1]
+	if options.Starter == black {
+		white, black = black, white
+	}
+
 	gameState := ChessGameState{
 		CurrentTurn:          "white",
+		Player1ID:            players[0],
+		Player2ID:            players[1],
+		WhitePlayer:          white,
+		BlackPlayer:          black,
 		GameEnded:            false,
 		WhiteKingSideCastle:  true,
 		WhiteQueenSideCastle: true,
@@ -133,6 +153,15 @@ func (e *ChessEngine) ApplyMove(gameState json.RawMessage, move json.RawMessage,
 	return json.RawMessage(stateBytes), err
 }
 
+// PlayerColors returns who plays white and who plays black.
+func (e *ChessEngine) PlayerColors(gameState json.RawMessage) (map[uuid.UUID]string, error) {
+	var state ChessGameState
+	if err := json.Unmarshal(gameState, &state); err != nil {
+		return nil, err
+	}
+	return map[uuid.UUID]string{state.WhitePlayer: "white", state.BlackPlayer: "black"}, nil
+}
+
 func (e *ChessEngine) GetGameStatus(gameState json.RawMessage) GameStatusInfo {
 	var state ChessGameState
 	if err := json.Unmarshal(gameState, &state); err != nil {
@@ -153,6 +182,7 @@ func (e *ChessEngine) GetGameStatus(gameState json.RawMessage) GameStatusInfo {
 		Winner:     state.Winner,
 		NextPlayer: nextPlayer,
 		IsDraw:     state.GameEnded && state.Winner == nil,
+		EndReason:  state.EndReason,
 	}
 }
 
@@ -184,6 +214,34 @@ func (e *ChessEngine) GetPossibleMoves(gameState json.RawMessage, playerID uuid.
 	return possibleMoves, nil
 }
 
+// OnTimeout forfeits the game to the opponent of the player who ran out of time.
+func (e *ChessEngine) OnTimeout(gameState json.RawMessage, playerID uuid.UUID) (json.RawMessage, error) {
+	var state ChessGameState
+	if err := json.Unmarshal(gameState, &state); err != nil {
+		return nil, err
+	}
+
+	if state.GameEnded {
+		return nil, errors.New("game has already ended")
+	}
+
+	playerColor := e.getPlayerColor(state, playerID)
+	if playerColor != state.CurrentTurn {
+		return nil, errors.New("not player's turn")
+	}
+
+	state.GameEnded = true
+	state.EndReason = models.EndReasonTimeout
+	if playerColor == "white" {
+		state.Winner = &state.BlackPlayer
+	} else {
+		state.Winner = &state.WhitePlayer
+	}
+
+	stateBytes, err := json.Marshal(state)
+	return json.RawMessage(stateBytes), err
+}
+
 // Helper functions
 func (e *ChessEngine) setupInitialBoard(state *ChessGameState) {
 	// Initialize empty board
@@ -440,9 +498,11 @@ func (e *ChessEngine) updateGameStatus(state *ChessGameState) {
 	if !whiteKingExists {
 		state.GameEnded = true
 		state.Winner = &state.BlackPlayer
+		state.EndReason = models.EndReasonCheckmate
 	} else if !blackKingExists {
 		state.GameEnded = true
 		state.Winner = &state.WhitePlayer
+		state.EndReason = models.EndReasonCheckmate
 	}
 }
 
@@ -0,0 +1,38 @@
+package game_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/game"
+	"github.com/szaher/vibeboard/backend/internal/game/enginetest"
+)
+
+func TestChessEngine(t *testing.T) {
+	engine := game.NewChessEngine()
+	enginetest.Run(t, enginetest.Suite{
+		Engine: engine,
+		NewGame: func() (json.RawMessage, []uuid.UUID, error) {
+			players := []uuid.UUID{uuid.New(), uuid.New()}
+			state, err := engine.Initialize(players, game.GameOptions{Starter: players[0]})
+			return state, players, err
+		},
+		InvalidMoves: []json.RawMessage{
+			// From an empty square
+			json.RawMessage(`{"from":{"row":4,"col":4},"to":{"row":3,"col":4}}`),
+			// An opponent's pawn
+			json.RawMessage(`{"from":{"row":1,"col":4},"to":{"row":2,"col":4}}`),
+			// A pawn three squares forward
+			json.RawMessage(`{"from":{"row":6,"col":4},"to":{"row":3,"col":4}}`),
+			// A rook through its own pawn
+			json.RawMessage(`{"from":{"row":7,"col":0},"to":{"row":4,"col":0}}`),
+			// A knight onto its own pawn
+			json.RawMessage(`{"from":{"row":7,"col":1},"to":{"row":6,"col":3}}`),
+			// Off the board
+			json.RawMessage(`{"from":{"row":6,"col":4},"to":{"row":8,"col":4}}`),
+			// Castling with pieces in the way
+			json.RawMessage(`{"from":{"row":7,"col":4},"to":{"row":7,"col":6},"castling":"king_side"}`),
+		},
+	})
+}
@@ -0,0 +1,199 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// ErrNoMoves is returned when a bot has no move to make.
+var ErrNoMoves = errors.New("no moves")
+
+// chessPieceValues are in centipawns. Kings are not counted: the game ends
+// when one is taken, which the search scores as chessMateScore.
+var chessPieceValues = map[string]int{
+	"pawn":   100,
+	"knight": 320,
+	"bishop": 330,
+	"rook":   500,
+	"queen":  900,
+}
+
+const chessMateScore = 100000
+
+// ChessBot picks chess moves by searching Depth plies ahead with alpha-beta
+// pruning. Positions are scored by material plus, scaled by Placement
+// percent, a bonus for pieces near the center. Up to Noise centipawns of
+// randomness is added to each candidate move's score, so weaker bots err
+// and no bot always plays the same game.
+type ChessBot struct {
+	Depth     int
+	Placement int
+	Noise     int
+}
+
+// chessBots are the bots of each level.
+var chessBots = map[models.BotLevel]ChessBot{
+	models.BotLevelEasy:   {Depth: 1, Placement: 0, Noise: 250},
+	models.BotLevelMedium: {Depth: 2, Placement: 100, Noise: 60},
+	models.BotLevelHard:   {Depth: 3, Placement: 100, Noise: 10},
+}
+
+// ChessBotFor returns the bot that plays at level.
+func ChessBotFor(level models.BotLevel) (ChessBot, bool) {
+	bot, ok := chessBots[level]
+	return bot, ok
+}
+
+// Move returns the bot's move as playerID in the chess game's state.
+func (b ChessBot) Move(gameState json.RawMessage, playerID uuid.UUID) (json.RawMessage, error) {
+	var state ChessGameState
+	if err := json.Unmarshal(gameState, &state); err != nil {
+		return nil, err
+	}
+
+	e := &ChessEngine{}
+	color := e.getPlayerColor(state, playerID)
+	if state.GameEnded || color != state.CurrentTurn {
+		return nil, errors.New("not player's turn")
+	}
+
+	moves := b.orderedMoves(e, state)
+	if len(moves) == 0 {
+		return nil, ErrNoMoves
+	}
+
+	best, bestScore := moves[0], math.MinInt
+	for _, move := range moves {
+		score := -b.search(e, b.play(e, state, move), b.Depth-1, -math.MaxInt, math.MaxInt)
+		if b.Noise > 0 {
+			score += rand.Intn(2*b.Noise+1) - b.Noise
+		}
+		if score > bestScore {
+			best, bestScore = move, score
+		}
+	}
+	return json.Marshal(best)
+}
+
+// search returns the score of the state for the side to move, searching
+// depth plies ahead.
+func (b ChessBot) search(e *ChessEngine, state ChessGameState, depth, alpha, beta int) int {
+	if state.GameEnded {
+		if state.Winner == nil {
+			return 0
+		}
+		// The side to move lost; sooner losses, with more depth left, are worse
+		return -chessMateScore - depth
+	}
+	if depth <= 0 {
+		return b.evaluate(state)
+	}
+
+	moves := b.orderedMoves(e, state)
+	if len(moves) == 0 {
+		return 0
+	}
+
+	for _, move := range moves {
+		score := -b.search(e, b.play(e, state, move), depth-1, -beta, -alpha)
+		if score >= beta {
+			return beta
+		}
+		if score > alpha {
+			alpha = score
+		}
+	}
+	return alpha
+}
+
+// orderedMoves returns the legal moves of the side to move, captures of the
+// most valuable pieces first, so the search prunes more.
+func (b ChessBot) orderedMoves(e *ChessEngine, state ChessGameState) []ChessMove {
+	var moves []ChessMove
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			piece := state.Board[row][col]
+			if piece == nil || piece.Color != state.CurrentTurn {
+				continue
+			}
+			for _, move := range e.generatePieceMoves(state, ChessPosition{Row: row, Col: col}) {
+				if e.validateChessMove(state, move, state.CurrentTurn) == nil {
+					moves = append(moves, move)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(moves, func(i, j int) bool {
+		return capturedValue(state, moves[i]) > capturedValue(state, moves[j])
+	})
+	return moves
+}
+
+func capturedValue(state ChessGameState, move ChessMove) int {
+	captured := state.Board[move.To.Row][move.To.Col]
+	if captured == nil {
+		return 0
+	}
+	if captured.Type == "king" {
+		return chessMateScore
+	}
+	return chessPieceValues[captured.Type]
+}
+
+// play returns the state after the side to move makes the move, leaving
+// state itself as it was.
+func (b ChessBot) play(e *ChessEngine, state ChessGameState, move ChessMove) ChessGameState {
+	// Promotion changes the piece in place, so pieces are copied
+	for row := range state.Board {
+		for col, piece := range state.Board[row] {
+			if piece != nil {
+				copied := *piece
+				state.Board[row][col] = &copied
+			}
+		}
+	}
+
+	e.applyChessMove(&state, move, state.CurrentTurn)
+	if state.CurrentTurn == "white" {
+		state.CurrentTurn = "black"
+	} else {
+		state.CurrentTurn = "white"
+	}
+	state.MoveCount++
+	e.updateGameStatus(&state)
+	return state
+}
+
+// evaluate scores the state for the side to move.
+func (b ChessBot) evaluate(state ChessGameState) int {
+	score := 0
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			piece := state.Board[row][col]
+			if piece == nil || piece.Type == "king" {
+				continue
+			}
+			value := chessPieceValues[piece.Type] + b.Placement*centrality(row, col)/100
+			if piece.Color == state.CurrentTurn {
+				score += value
+			} else {
+				score -= value
+			}
+		}
+	}
+	return score
+}
+
+// centrality is a bonus of up to 30 centipawns for standing near the center
+// of the board.
+func centrality(row, col int) int {
+	// Distances are doubled to stay whole: 1 in the center, 7 on the edge
+	return 30 - 5*(abs(2*row-7)+abs(2*col-7)-2)/2
+}
@@ -0,0 +1,170 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+var (
+	testWhite = uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	testBlack = uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+)
+
+// chessPosition returns a state with white to move and the given pieces,
+// keyed by square as "color piece".
+func chessPosition(t *testing.T, pieces map[ChessPosition]string) json.RawMessage {
+	t.Helper()
+	state := ChessGameState{
+		CurrentTurn: "white",
+		Player1ID:   testWhite,
+		Player2ID:   testBlack,
+		WhitePlayer: testWhite,
+		BlackPlayer: testBlack,
+	}
+	for pos, piece := range pieces {
+		var p ChessPiece
+		for i := range piece {
+			if piece[i] == ' ' {
+				p = ChessPiece{Color: piece[:i], Type: piece[i+1:]}
+			}
+		}
+		state.Board[pos.Row][pos.Col] = &p
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestChessBotMoves(t *testing.T) {
+	tests := []struct {
+		name   string
+		levels []models.BotLevel
+		pieces map[ChessPosition]string
+		// check reports what is wrong with the bot's move, if anything
+		check func(t *testing.T, state json.RawMessage, move ChessMove)
+	}{
+		{
+			name:   "takes a free queen",
+			levels: []models.BotLevel{models.BotLevelEasy, models.BotLevelMedium, models.BotLevelHard},
+			pieces: map[ChessPosition]string{
+				{Row: 7, Col: 7}: "white king",
+				{Row: 4, Col: 0}: "white rook",
+				{Row: 4, Col: 6}: "black queen",
+				{Row: 0, Col: 3}: "black king",
+			},
+			check: func(t *testing.T, _ json.RawMessage, move ChessMove) {
+				if move.From != (ChessPosition{Row: 4, Col: 0}) || move.To != (ChessPosition{Row: 4, Col: 6}) {
+					t.Errorf("move = %+v, want the rook to take the queen", move)
+				}
+			},
+		},
+		{
+			// Taking the queen leaves the king to the rook on its file
+			name:   "avoids a one-move king capture",
+			levels: []models.BotLevel{models.BotLevelMedium, models.BotLevelHard},
+			pieces: map[ChessPosition]string{
+				{Row: 7, Col: 4}: "white king",
+				{Row: 5, Col: 7}: "white rook",
+				{Row: 5, Col: 0}: "black queen",
+				{Row: 0, Col: 4}: "black rook",
+				{Row: 2, Col: 2}: "black king",
+			},
+			check: func(t *testing.T, state json.RawMessage, move ChessMove) {
+				engine := NewChessEngine()
+				data, _ := json.Marshal(move)
+				after, err := engine.ApplyMove(state, data, testWhite)
+				if err != nil {
+					t.Fatal(err)
+				}
+				replies, err := engine.GetPossibleMoves(after, testBlack)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var next ChessGameState
+				if err := json.Unmarshal(after, &next); err != nil {
+					t.Fatal(err)
+				}
+				for _, reply := range replies {
+					var r ChessMove
+					if err := json.Unmarshal(reply, &r); err != nil {
+						t.Fatal(err)
+					}
+					if p := next.Board[r.To.Row][r.To.Col]; p != nil && p.Type == "king" && p.Color == "white" {
+						t.Errorf("after %+v black takes the king with %+v", move, r)
+					}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, level := range tt.levels {
+			t.Run(tt.name+"/"+string(level), func(t *testing.T) {
+				bot, ok := ChessBotFor(level)
+				if !ok {
+					t.Fatalf("no bot at level %s", level)
+				}
+				state := chessPosition(t, tt.pieces)
+
+				data, err := bot.Move(state, testWhite)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var move ChessMove
+				if err := json.Unmarshal(data, &move); err != nil {
+					t.Fatal(err)
+				}
+				tt.check(t, state, move)
+			})
+		}
+	}
+}
+
+func TestChessBotPlaysValidMoves(t *testing.T) {
+	engine := NewChessEngine()
+	for _, level := range []models.BotLevel{models.BotLevelEasy, models.BotLevelMedium, models.BotLevelHard} {
+		t.Run(string(level), func(t *testing.T) {
+			bot, _ := ChessBotFor(level)
+			state, err := engine.Initialize([]uuid.UUID{testWhite, testBlack}, GameOptions{Starter: testWhite})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for ply := 0; ply < 30; ply++ {
+				status := engine.GetGameStatus(state)
+				if status.IsGameOver {
+					return
+				}
+				player := *status.NextPlayer
+
+				move, err := bot.Move(state, player)
+				if err != nil {
+					t.Fatalf("ply %d: %v", ply, err)
+				}
+				if err := engine.ValidateMove(state, move, player); err != nil {
+					t.Fatalf("ply %d: move %s is invalid: %v", ply, move, err)
+				}
+				if state, err = engine.ApplyMove(state, move, player); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestChessBotWithoutMoves(t *testing.T) {
+	bot, _ := ChessBotFor(models.BotLevelHard)
+	state := chessPosition(t, map[ChessPosition]string{
+		{Row: 0, Col: 0}: "black king",
+	})
+
+	if _, err := bot.Move(state, testWhite); err != ErrNoMoves {
+		t.Errorf("err = %v, want ErrNoMoves", err)
+	}
+}
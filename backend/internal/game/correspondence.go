@@ -0,0 +1,55 @@
+package game
+
+import (
+	"log"
+	"time"
+
+	"github.com/szaher/vibeboard/backend/internal/database"
+)
+
+// CorrespondenceService enforces the move deadlines of correspondence
+// games, which run for days and are not on the live turn timer. A player
+// who misses a deadline times out as on the turn timer.
+type CorrespondenceService struct {
+	db       *database.DB
+	moves    *MoveService
+	interval time.Duration
+}
+
+func NewCorrespondenceService(db *database.DB, moves *MoveService, interval time.Duration) *CorrespondenceService {
+	return &CorrespondenceService{
+		db:       db,
+		moves:    moves,
+		interval: interval,
+	}
+}
+
+func (s *CorrespondenceService) Start() {
+	if s.interval <= 0 {
+		log.Println("Correspondence deadlines disabled")
+		return
+	}
+
+	log.Printf("Starting correspondence deadlines (interval: %s)...", s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	go func() {
+		for range ticker.C {
+			s.processDeadlines()
+		}
+	}()
+}
+
+func (s *CorrespondenceService) processDeadlines() {
+	games, err := s.db.GetOverdueGames(time.Now())
+	if err != nil {
+		log.Printf("Error getting overdue games: %v", err)
+		return
+	}
+
+	for _, game := range games {
+		if err := s.moves.ExpireTurn(game); err != nil {
+			log.Printf("Error applying move deadline to game %s: %v", game.ID, err)
+		}
+	}
+}
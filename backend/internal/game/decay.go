@@ -0,0 +1,123 @@
+package game
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+// Rating decay modes
+const (
+	RatingDecayNone      = "none"
+	RatingDecayRating    = "rating"
+	RatingDecayDeviation = "deviation"
+)
+
+const (
+	ratingDecayCheckInterval = time.Hour
+	ratingDecayBatchSize     = 500
+)
+
+// RatingsRefresher re-ranks the rating leaderboards after ratings change
+// outside games.
+type RatingsRefresher interface {
+	RatingsChanged()
+}
+
+// RatingDecayService decays the ratings of players who have not played a
+// rated game for a while, once every interval while they stay away. In
+// rating mode their rating drops toward the floor, moving them down tiers
+// as it goes; in deviation mode their rating deviation grows as Glicko-2
+// does between rating periods, so their next results move it further.
+type RatingDecayService struct {
+	db          *database.DB
+	system      models.RatingSystem
+	cfg         *config.RatingDecayConfig
+	leaderboard RatingsRefresher
+}
+
+// NewRatingDecayService records decay in rating history under system, the
+// deployment's rating system.
+func NewRatingDecayService(db *database.DB, system string, cfg *config.RatingDecayConfig) *RatingDecayService {
+	return &RatingDecayService{
+		db:     db,
+		system: ratingSystemOrElo(system).Name(),
+		cfg:    cfg,
+	}
+}
+
+// SetLeaderboard must be called before Start. Without it leaderboards only
+// show decayed ratings after their next scheduled refresh.
+func (s *RatingDecayService) SetLeaderboard(leaderboard RatingsRefresher) {
+	s.leaderboard = leaderboard
+}
+
+func (s *RatingDecayService) Start() {
+	switch s.cfg.Mode {
+	case RatingDecayRating, RatingDecayDeviation:
+	case RatingDecayNone, "":
+		log.Println("Rating decay disabled")
+		return
+	default:
+		log.Printf("Unknown rating decay mode %q, rating decay disabled", s.cfg.Mode)
+		return
+	}
+	if s.cfg.After <= 0 || s.cfg.Interval <= 0 {
+		log.Println("Rating decay disabled")
+		return
+	}
+
+	log.Printf("Starting rating decay (mode: %s, after: %s, interval: %s)...", s.cfg.Mode, s.cfg.After, s.cfg.Interval)
+
+	ticker := time.NewTicker(ratingDecayCheckInterval)
+	go func() {
+		for range ticker.C {
+			s.decayRatings()
+		}
+	}()
+}
+
+func (s *RatingDecayService) decayRatings() {
+	now := time.Now()
+	decayed := 0
+	for {
+		n, err := s.db.DecayRatings(s.system, now.Add(-s.cfg.After), now.Add(-s.cfg.Interval), ratingDecayBatchSize, s.decay)
+		if err != nil {
+			log.Printf("Error decaying ratings: %v", err)
+			break
+		}
+		decayed += n
+		if n < ratingDecayBatchSize {
+			break
+		}
+	}
+
+	if decayed == 0 {
+		return
+	}
+	log.Printf("Decayed the ratings of %d inactive players", decayed)
+	if s.leaderboard != nil {
+		s.leaderboard.RatingsChanged()
+	}
+}
+
+// decay returns a player's rating after one interval of decay.
+func (s *RatingDecayService) decay(rating models.PlayerRating) models.PlayerRating {
+	switch s.cfg.Mode {
+	case RatingDecayRating:
+		if rating.Rating > s.cfg.Floor {
+			rating.Rating = max(rating.Rating-s.cfg.Points, s.cfg.Floor)
+			rating.Tier = NextTier(rating.Tier, rating.Rating, rating.RatedGames)
+		}
+	case RatingDecayDeviation:
+		rating = withGlicko2Defaults(rating)
+		phi := rating.Deviation / glicko2Scale
+		grown := math.Sqrt(phi*phi+rating.Volatility*rating.Volatility) * glicko2Scale
+		rating.Deviation = math.Min(grown, models.DefaultRatingDeviation)
+	}
+	return rating
+}
@@ -24,6 +24,7 @@ type DominoGameState struct {
 	Player2ID   uuid.UUID                  `json:"player2_id"`
 	GameEnded   bool                       `json:"game_ended"`
 	Winner      *uuid.UUID                 `json:"winner,omitempty"`
+	EndReason   models.EndReason           `json:"end_reason,omitempty"`
 }
 
 type DominoMove struct {
@@ -32,6 +33,16 @@ type DominoMove struct {
 	Pass bool       `json:"pass"` // true if player passes turn
 }
 
+// Dominoes set sizes, by highest double
+const (
+	DoubleSix  = 6
+	DoubleNine = 9
+)
+
+// DominoVariantBlock is the block game: players pass rather than draw from
+// the bone yard when they can't play.
+const DominoVariantBlock = "block"
+
 type DominoEngine struct{}
 
 func NewDominoEngine() *DominoEngine {
@@ -42,8 +53,21 @@ func (e *DominoEngine) GetGameType() models.GameType {
 	return models.GameTypeDominoes
 }
 
-func (e *DominoEngine) Initialize() (json.RawMessage, error) {
-	tiles := e.generateDominoSet()
+// SettingsSchema offers the block game with double-six or double-nine sets.
+func (e *DominoEngine) SettingsSchema() SettingsSchema {
+	return SettingsSchema{Variants: []string{DominoVariantBlock}, BoardSizes: []int{DoubleSix, DoubleNine}}
+}
+
+func (e *DominoEngine) Initialize(players []uuid.UUID, options GameOptions) (json.RawMessage, error) {
+	if len(players) != 2 {
+		return nil, errors.New("dominoes needs exactly two players")
+	}
+
+	highest := options.Settings.BoardSize
+	if highest == 0 {
+		highest = DoubleSix
+	}
+	tiles := e.generateDominoSet(highest)
 
 	shuffledTiles := make([]DominoTile, len(tiles))
 	copy(shuffledTiles, tiles)
@@ -55,6 +79,8 @@ func (e *DominoEngine) Initialize() (json.RawMessage, error) {
 		PlayerHands: make(map[uuid.UUID][]DominoTile),
 		Board:       []DominoTile{},
 		BoneYard:    shuffledTiles[14:], // Remaining tiles after dealing
+		Player1ID:   players[0],
+		Player2ID:   players[1],
 		GameEnded:   false,
 	}
 
@@ -64,8 +90,12 @@ func (e *DominoEngine) Initialize() (json.RawMessage, error) {
 		gameState.PlayerHands[gameState.Player2ID] = append(gameState.PlayerHands[gameState.Player2ID], shuffledTiles[i+7])
 	}
 
-	// Player with highest double starts, or highest tile value
-	starter := e.determineStartingPlayer(gameState)
+	// Without a chosen starter, the player with the highest double starts,
+	// or highest tile value
+	starter := options.Starter
+	if starter != gameState.Player1ID && starter != gameState.Player2ID {
+		starter = e.determineStartingPlayer(gameState)
+	}
 	gameState.CurrentTurn = starter
 
 	stateBytes, err := json.Marshal(gameState)
@@ -143,6 +173,7 @@ func (e *DominoEngine) ApplyMove(gameState json.RawMessage, move json.RawMessage
 		// Check if both players passed (game blocked)
 		if !e.canPlayerPlay(state, state.CurrentTurn) {
 			state.GameEnded = true
+			state.EndReason = models.EndReasonBlocked
 			winner := e.determineWinnerByScore(state)
 			state.Winner = winner
 		}
@@ -167,6 +198,7 @@ func (e *DominoEngine) ApplyMove(gameState json.RawMessage, move json.RawMessage
 		// Check if player won (no tiles left)
 		if len(state.PlayerHands[playerID]) == 0 {
 			state.GameEnded = true
+			state.EndReason = models.EndReasonDomino
 			state.Winner = &playerID
 		} else {
 			// Switch turns
@@ -184,11 +216,17 @@ func (e *DominoEngine) GetGameStatus(gameState json.RawMessage) GameStatusInfo {
 		return GameStatusInfo{}
 	}
 
+	var nextPlayer *uuid.UUID
+	if !state.GameEnded {
+		nextPlayer = &state.CurrentTurn
+	}
+
 	return GameStatusInfo{
 		IsGameOver: state.GameEnded,
 		Winner:     state.Winner,
-		NextPlayer: &state.CurrentTurn,
+		NextPlayer: nextPlayer,
 		IsDraw:     state.GameEnded && state.Winner == nil,
+		EndReason:  state.EndReason,
 	}
 }
 
@@ -237,11 +275,34 @@ func (e *DominoEngine) GetPossibleMoves(gameState json.RawMessage, playerID uuid
 	return possibleMoves, nil
 }
 
+// OnTimeout passes the turn on behalf of the player who ran out of time.
+func (e *DominoEngine) OnTimeout(gameState json.RawMessage, playerID uuid.UUID) (json.RawMessage, error) {
+	var state DominoGameState
+	if err := json.Unmarshal(gameState, &state); err != nil {
+		return nil, err
+	}
+
+	if state.GameEnded {
+		return nil, errors.New("game has already ended")
+	}
+
+	if state.CurrentTurn != playerID {
+		return nil, errors.New("not player's turn")
+	}
+
+	passMove, err := json.Marshal(DominoMove{Pass: true})
+	if err != nil {
+		return nil, err
+	}
+
+	return e.ApplyMove(gameState, passMove, playerID)
+}
+
 // Helper functions
-func (e *DominoEngine) generateDominoSet() []DominoTile {
+func (e *DominoEngine) generateDominoSet(highest int) []DominoTile {
 	var tiles []DominoTile
-	for i := 0; i <= 6; i++ {
-		for j := i; j <= 6; j++ {
+	for i := 0; i <= highest; i++ {
+		for j := i; j <= highest; j++ {
 			tiles = append(tiles, DominoTile{Left: i, Right: j})
 		}
 	}
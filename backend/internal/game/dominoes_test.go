@@ -0,0 +1,29 @@
+package game_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/game"
+	"github.com/szaher/vibeboard/backend/internal/game/enginetest"
+)
+
+func TestDominoEngine(t *testing.T) {
+	engine := game.NewDominoEngine()
+	enginetest.Run(t, enginetest.Suite{
+		Engine: engine,
+		NewGame: func() (json.RawMessage, []uuid.UUID, error) {
+			players := []uuid.UUID{uuid.New(), uuid.New()}
+			state, err := engine.Initialize(players, game.GameOptions{Starter: players[0]})
+			return state, players, err
+		},
+		InvalidMoves: []json.RawMessage{
+			// Passing while any tile may start the board
+			json.RawMessage(`{"pass":true}`),
+			// Tiles outside a double-six set, so in no hand
+			json.RawMessage(`{"tile":{"left":7,"right":7},"side":"left"}`),
+			json.RawMessage(`{"tile":{"left":-1,"right":0},"side":"right"}`),
+		},
+	})
+}
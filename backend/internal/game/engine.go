@@ -3,42 +3,99 @@ package game
 import (
 	"encoding/json"
 	"fmt"
+	"log"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/szaher/vibeboard/backend/internal/models"
 )
 
 type GameEngine interface {
-	Initialize() (json.RawMessage, error)
+	// Initialize seats the players, in seat order, and returns the opening
+	// state.
+	Initialize(players []uuid.UUID, options GameOptions) (json.RawMessage, error)
 	ValidateMove(gameState json.RawMessage, move json.RawMessage, playerID uuid.UUID) error
 	ApplyMove(gameState json.RawMessage, move json.RawMessage, playerID uuid.UUID) (json.RawMessage, error)
 	GetGameStatus(gameState json.RawMessage) GameStatusInfo
 	GetPossibleMoves(gameState json.RawMessage, playerID uuid.UUID) ([]json.RawMessage, error)
+	// OnTimeout is called when playerID's turn clock expires and returns
+	// the state after the game's timeout rule has been applied.
+	OnTimeout(gameState json.RawMessage, playerID uuid.UUID) (json.RawMessage, error)
 	GetGameType() models.GameType
 }
 
+// ColoredEngine is implemented by engines whose players play a color, such
+// as chess.
+type ColoredEngine interface {
+	// PlayerColors returns each player's color in the state
+	PlayerColors(gameState json.RawMessage) (map[uuid.UUID]string, error)
+}
+
+// GameOptions are the choices made for a game before it starts.
+type GameOptions struct {
+	// Starter moves first. When unset the engine's own rules decide.
+	Starter uuid.UUID
+	// Settings are the game's rules, already checked by ApplySettings.
+	// Unset settings take the engine's defaults.
+	Settings models.GameSettings
+}
+
 type GameStatusInfo struct {
 	IsGameOver bool
 	Winner     *uuid.UUID
 	NextPlayer *uuid.UUID
 	IsDraw     bool
+	// EndReason is how the game ended, once it is over
+	EndReason models.EndReason
+}
+
+// GameTypeStore keeps which game types are disabled, so every instance
+// agrees.
+type GameTypeStore interface {
+	GetDisabledGameTypes() ([]models.GameType, error)
+	SetGameTypeEnabled(gameType models.GameType, enabled bool) error
 }
 
+// GameTypeNotifier tells the other instances that a game type was enabled
+// or disabled, so they reload them.
+type GameTypeNotifier interface {
+	GameTypesChanged()
+}
+
+// disabledTypesRefresh is how long disabled game types are cached before
+// they are read from the store again, in case a change notice was missed.
+const disabledTypesRefresh = 30 * time.Second
+
 type EngineRegistry struct {
-	engines map[models.GameType]GameEngine
+	engines  map[models.GameType]GameEngine
+	disabled map[models.GameType]bool
+	// store is optional; without it disabled types are only kept in memory
+	store    GameTypeStore
+	loadedAt time.Time
+	notifier GameTypeNotifier
+	mutex    sync.RWMutex
 }
 
 func NewEngineRegistry() *EngineRegistry {
 	return &EngineRegistry{
-		engines: make(map[models.GameType]GameEngine),
+		engines:  make(map[models.GameType]GameEngine),
+		disabled: make(map[models.GameType]bool),
 	}
 }
 
 func (r *EngineRegistry) Register(gameType models.GameType, engine GameEngine) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 	r.engines[gameType] = engine
 }
 
+// GetEngine returns the engine for a game type even when the type is
+// disabled, so games that are already running can finish.
 func (r *EngineRegistry) GetEngine(gameType models.GameType) (GameEngine, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
 	engine, exists := r.engines[gameType]
 	if !exists {
 		return nil, fmt.Errorf("game engine not found for type: %s", gameType)
@@ -47,6 +104,9 @@ func (r *EngineRegistry) GetEngine(gameType models.GameType) (GameEngine, error)
 }
 
 func (r *EngineRegistry) GetSupportedTypes() []models.GameType {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
 	types := make([]models.GameType, 0, len(r.engines))
 	for gameType := range r.engines {
 		types = append(types, gameType)
@@ -54,4 +114,104 @@ func (r *EngineRegistry) GetSupportedTypes() []models.GameType {
 	return types
 }
 
+// SetStore must be called before the registry is used. Without it game
+// types are enabled and disabled on this instance only.
+func (r *EngineRegistry) SetStore(store GameTypeStore) {
+	r.store = store
+}
+
+// SetNotifier must be called before game types are enabled or disabled.
+// Without it other instances only see changes once they reload.
+func (r *EngineRegistry) SetNotifier(notifier GameTypeNotifier) {
+	r.notifier = notifier
+}
+
+// Reload reads the disabled game types from the store.
+func (r *EngineRegistry) Reload() error {
+	if r.store == nil {
+		return nil
+	}
+	types, err := r.store.GetDisabledGameTypes()
+	if err != nil {
+		return err
+	}
+
+	disabled := make(map[models.GameType]bool, len(types))
+	for _, gameType := range types {
+		disabled[gameType] = true
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.disabled = disabled
+	r.loadedAt = time.Now()
+	return nil
+}
+
+// refresh reloads the disabled game types once they are stale, keeping the
+// cached ones if the store cannot be read.
+func (r *EngineRegistry) refresh() {
+	r.mutex.RLock()
+	stale := r.store != nil && time.Since(r.loadedAt) > disabledTypesRefresh
+	r.mutex.RUnlock()
+
+	if stale {
+		if err := r.Reload(); err != nil {
+			log.Printf("Error loading disabled game types: %v", err)
+		}
+	}
+}
+
+// GetEnabledTypes returns the registered game types that accept new games.
+func (r *EngineRegistry) GetEnabledTypes() []models.GameType {
+	r.refresh()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	types := make([]models.GameType, 0, len(r.engines))
+	for gameType := range r.engines {
+		if !r.disabled[gameType] {
+			types = append(types, gameType)
+		}
+	}
+	return types
+}
+
+func (r *EngineRegistry) IsEnabled(gameType models.GameType) bool {
+	r.refresh()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	_, exists := r.engines[gameType]
+	return exists && !r.disabled[gameType]
+}
+
+// SetEnabled enables or disables new games of a type on every instance.
+func (r *EngineRegistry) SetEnabled(gameType models.GameType, enabled bool) error {
+	if _, err := r.GetEngine(gameType); err != nil {
+		return err
+	}
+
+	if r.store != nil {
+		if err := r.store.SetGameTypeEnabled(gameType, enabled); err != nil {
+			return fmt.Errorf("failed to store game type: %w", err)
+		}
+	}
+
+	r.mutex.Lock()
+	if enabled {
+		delete(r.disabled, gameType)
+	} else {
+		r.disabled[gameType] = true
+	}
+	r.mutex.Unlock()
+
+	if r.notifier != nil {
+		r.notifier.GameTypesChanged()
+	}
+	return nil
+}
+
 var GlobalRegistry = NewEngineRegistry()
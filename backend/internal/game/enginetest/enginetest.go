@@ -0,0 +1,316 @@
+// Package enginetest provides a conformance suite that any game.GameEngine
+// implementation can run from its own tests.
+package enginetest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/game"
+)
+
+// Suite describes the engine under test. NewGame must return a freshly
+// initialized state with players seated, along with those players in seat
+// order.
+type Suite struct {
+	Engine       game.GameEngine
+	NewGame      func() (json.RawMessage, []uuid.UUID, error)
+	InvalidMoves []json.RawMessage
+	MaxMoves     int
+}
+
+const defaultMaxMoves = 500
+
+func Run(t *testing.T, s Suite) {
+	t.Helper()
+
+	if s.Engine == nil {
+		t.Fatal("enginetest: Suite.Engine is nil")
+	}
+	if s.NewGame == nil {
+		t.Fatal("enginetest: Suite.NewGame is nil")
+	}
+	if s.MaxMoves <= 0 {
+		s.MaxMoves = defaultMaxMoves
+	}
+
+	t.Run("GameType", s.testGameType)
+	t.Run("Initialize", s.testInitialize)
+	t.Run("SerializationRoundTrip", s.testSerializationRoundTrip)
+	t.Run("RejectsInvalidMoves", s.testRejectsInvalidMoves)
+	t.Run("RejectsOutOfTurnMoves", s.testRejectsOutOfTurnMoves)
+	t.Run("TurnAlternation", s.testTurnAlternation)
+	t.Run("TerminalState", s.testTerminalState)
+}
+
+func (s Suite) newGame(t *testing.T) (json.RawMessage, []uuid.UUID) {
+	t.Helper()
+
+	state, players, err := s.NewGame()
+	if err != nil {
+		t.Fatalf("NewGame failed: %v", err)
+	}
+	if len(players) < 2 {
+		t.Fatalf("NewGame returned %d players, want at least 2", len(players))
+	}
+	return state, players
+}
+
+func (s Suite) testGameType(t *testing.T) {
+	if s.Engine.GetGameType() == "" {
+		t.Fatal("GetGameType returned an empty game type")
+	}
+}
+
+func (s Suite) testInitialize(t *testing.T) {
+	players := []uuid.UUID{uuid.New(), uuid.New()}
+
+	// Either seat may be chosen to start
+	for _, starter := range players {
+		state, err := s.Engine.Initialize(players, game.GameOptions{Starter: starter})
+		if err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		if !json.Valid(state) {
+			t.Fatalf("Initialize returned invalid JSON: %s", state)
+		}
+
+		status := s.Engine.GetGameStatus(state)
+		if status.IsGameOver {
+			t.Fatal("freshly initialized game reports game over")
+		}
+		if status.NextPlayer == nil || *status.NextPlayer != starter {
+			t.Fatalf("next player is %v, want starter %s", status.NextPlayer, starter)
+		}
+	}
+}
+
+func (s Suite) testSerializationRoundTrip(t *testing.T) {
+	state, players := s.newGame(t)
+
+	var decoded interface{}
+	if err := json.Unmarshal(state, &decoded); err != nil {
+		t.Fatalf("state is not valid JSON: %v", err)
+	}
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("failed to re-encode state: %v", err)
+	}
+
+	assertSameStatus(t, s.Engine.GetGameStatus(state), s.Engine.GetGameStatus(encoded))
+
+	for _, playerID := range players {
+		before, err := s.Engine.GetPossibleMoves(state, playerID)
+		if err != nil {
+			t.Fatalf("GetPossibleMoves failed: %v", err)
+		}
+		after, err := s.Engine.GetPossibleMoves(encoded, playerID)
+		if err != nil {
+			t.Fatalf("GetPossibleMoves failed after round trip: %v", err)
+		}
+		if len(before) != len(after) {
+			t.Fatalf("player %s has %d moves before round trip, %d after", playerID, len(before), len(after))
+		}
+	}
+
+	// Applying a move must also produce a state that survives a round trip.
+	next, _, ok := s.applyFirstMove(t, state)
+	if !ok {
+		return
+	}
+	if err := json.Unmarshal(next, &decoded); err != nil {
+		t.Fatalf("state after move is not valid JSON: %v", err)
+	}
+	encoded, err = json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("failed to re-encode state after move: %v", err)
+	}
+	assertSameStatus(t, s.Engine.GetGameStatus(next), s.Engine.GetGameStatus(encoded))
+}
+
+func (s Suite) testRejectsInvalidMoves(t *testing.T) {
+	state, _ := s.newGame(t)
+
+	status := s.Engine.GetGameStatus(state)
+	if status.NextPlayer == nil {
+		t.Fatal("new game has no next player")
+	}
+	playerID := *status.NextPlayer
+
+	invalid := append([]json.RawMessage{
+		json.RawMessage(`not json`),
+		json.RawMessage(`[]`),
+	}, s.InvalidMoves...)
+
+	for _, move := range invalid {
+		if err := s.Engine.ValidateMove(state, move, playerID); err == nil {
+			t.Errorf("ValidateMove accepted invalid move %s", move)
+		}
+	}
+}
+
+func (s Suite) testRejectsOutOfTurnMoves(t *testing.T) {
+	state, players := s.newGame(t)
+
+	status := s.Engine.GetGameStatus(state)
+	if status.NextPlayer == nil {
+		t.Fatal("new game has no next player")
+	}
+	current := *status.NextPlayer
+
+	moves, err := s.Engine.GetPossibleMoves(state, current)
+	if err != nil {
+		t.Fatalf("GetPossibleMoves failed: %v", err)
+	}
+
+	for _, playerID := range players {
+		if playerID == current {
+			continue
+		}
+		for _, move := range moves {
+			if err := s.Engine.ValidateMove(state, move, playerID); err == nil {
+				t.Errorf("player %s was allowed to move out of turn: %s", playerID, move)
+			}
+		}
+	}
+}
+
+func (s Suite) testTurnAlternation(t *testing.T) {
+	state, players := s.newGame(t)
+
+	for i := 0; i < s.MaxMoves; i++ {
+		status := s.Engine.GetGameStatus(state)
+		if status.IsGameOver {
+			return
+		}
+		if status.NextPlayer == nil {
+			t.Fatalf("move %d: game in progress has no next player", i)
+		}
+		if !containsPlayer(players, *status.NextPlayer) {
+			t.Fatalf("move %d: next player %s is not seated in the game", i, *status.NextPlayer)
+		}
+
+		next, mover, ok := s.applyFirstMove(t, state)
+		if !ok {
+			t.Fatalf("move %d: player %s has no legal moves in an unfinished game", i, *status.NextPlayer)
+		}
+
+		nextStatus := s.Engine.GetGameStatus(next)
+		if !nextStatus.IsGameOver && nextStatus.NextPlayer != nil && *nextStatus.NextPlayer == mover {
+			t.Fatalf("move %d: player %s moved twice in a row", i, mover)
+		}
+		state = next
+	}
+}
+
+func (s Suite) testTerminalState(t *testing.T) {
+	state, players := s.newGame(t)
+
+	for i := 0; i < s.MaxMoves; i++ {
+		status := s.Engine.GetGameStatus(state)
+		assertConsistentStatus(t, status, players)
+		if status.IsGameOver {
+			for _, playerID := range players {
+				moves, _ := s.Engine.GetPossibleMoves(state, playerID)
+				for _, move := range moves {
+					if err := s.Engine.ValidateMove(state, move, playerID); err == nil {
+						t.Errorf("finished game accepted move %s from %s", move, playerID)
+					}
+				}
+			}
+			return
+		}
+
+		next, _, ok := s.applyFirstMove(t, state)
+		if !ok {
+			t.Fatalf("move %d: no legal moves in an unfinished game", i)
+		}
+		state = next
+	}
+
+	t.Logf("game did not finish within %d moves", s.MaxMoves)
+}
+
+// applyFirstMove plays the first valid move offered to the next player.
+func (s Suite) applyFirstMove(t *testing.T, state json.RawMessage) (json.RawMessage, uuid.UUID, bool) {
+	t.Helper()
+
+	status := s.Engine.GetGameStatus(state)
+	if status.IsGameOver || status.NextPlayer == nil {
+		return nil, uuid.Nil, false
+	}
+	playerID := *status.NextPlayer
+
+	moves, err := s.Engine.GetPossibleMoves(state, playerID)
+	if err != nil {
+		t.Fatalf("GetPossibleMoves failed: %v", err)
+	}
+
+	for _, move := range moves {
+		if err := s.Engine.ValidateMove(state, move, playerID); err != nil {
+			continue
+		}
+		next, err := s.Engine.ApplyMove(state, move, playerID)
+		if err != nil {
+			t.Fatalf("ApplyMove rejected a validated move %s: %v", move, err)
+		}
+		if !json.Valid(next) {
+			t.Fatalf("ApplyMove returned invalid JSON: %s", next)
+		}
+		return next, playerID, true
+	}
+
+	return nil, uuid.Nil, false
+}
+
+func assertConsistentStatus(t *testing.T, status game.GameStatusInfo, players []uuid.UUID) {
+	t.Helper()
+
+	if status.IsDraw && !status.IsGameOver {
+		t.Fatal("status reports a draw for an unfinished game")
+	}
+	if status.Winner != nil {
+		if !status.IsGameOver {
+			t.Fatal("status reports a winner for an unfinished game")
+		}
+		if status.IsDraw {
+			t.Fatal("status reports both a winner and a draw")
+		}
+		if !containsPlayer(players, *status.Winner) {
+			t.Fatalf("winner %s is not seated in the game", *status.Winner)
+		}
+	}
+	if status.IsGameOver && status.NextPlayer != nil {
+		t.Fatal("finished game still reports a next player")
+	}
+	if status.IsGameOver != (status.EndReason != "") {
+		t.Fatalf("game over is %v but end reason is %q", status.IsGameOver, status.EndReason)
+	}
+}
+
+func assertSameStatus(t *testing.T, want, got game.GameStatusInfo) {
+	t.Helper()
+
+	if want.IsGameOver != got.IsGameOver || want.IsDraw != got.IsDraw ||
+		want.EndReason != got.EndReason ||
+		!sameID(want.Winner, got.Winner) || !sameID(want.NextPlayer, got.NextPlayer) {
+		t.Fatalf("status changed across round trip: want %+v, got %+v", want, got)
+	}
+}
+
+func sameID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func containsPlayer(players []uuid.UUID, playerID uuid.UUID) bool {
+	for _, p := range players {
+		if p == playerID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"math"
+
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// Glicko-2 works on its own scale, centered on glicko2Center
+const (
+	glicko2Scale  = 173.7178
+	glicko2Center = 1500
+	// glicko2Epsilon is when the volatility iteration has converged
+	glicko2Epsilon = 0.000001
+	// defaultGlicko2Tau keeps volatility from changing too quickly
+	defaultGlicko2Tau = 0.5
+)
+
+// Glicko2 rates each game as its own rating period. Unlike Elo it tracks how
+// sure it is of each rating: ratings of players with few or old results
+// move quickly, and settle as they play.
+type Glicko2 struct {
+	Tau float64
+}
+
+func NewGlicko2() Glicko2 {
+	return Glicko2{Tau: defaultGlicko2Tau}
+}
+
+func (Glicko2) Name() models.RatingSystem { return models.RatingSystemGlicko2 }
+
+func (g Glicko2) Rate(player1, player2 models.PlayerRating, score float64) (models.PlayerRating, models.PlayerRating) {
+	return g.rate(player1, player2, score), g.rate(player2, player1, 1-score)
+}
+
+// rate returns the player's rating after scoring score against opponent.
+func (g Glicko2) rate(player, opponent models.PlayerRating, score float64) models.PlayerRating {
+	player, opponent = withGlicko2Defaults(player), withGlicko2Defaults(opponent)
+
+	mu := float64(player.Rating-glicko2Center) / glicko2Scale
+	phi := player.Deviation / glicko2Scale
+	opponentMu := float64(opponent.Rating-glicko2Center) / glicko2Scale
+	opponentPhi := opponent.Deviation / glicko2Scale
+
+	impact := 1 / math.Sqrt(1+3*opponentPhi*opponentPhi/(math.Pi*math.Pi))
+	expected := 1 / (1 + math.Exp(-impact*(mu-opponentMu)))
+	variance := 1 / (impact * impact * expected * (1 - expected))
+	delta := variance * impact * (score - expected)
+
+	volatility := g.volatility(phi, player.Volatility, variance, delta)
+
+	phiStar := math.Sqrt(phi*phi + volatility*volatility)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/variance)
+	newMu := mu + newPhi*newPhi*impact*(score-expected)
+
+	return models.PlayerRating{
+		Rating:     int(math.Round(newMu*glicko2Scale + glicko2Center)),
+		Deviation:  math.Min(newPhi*glicko2Scale, models.DefaultRatingDeviation),
+		Volatility: volatility,
+	}
+}
+
+// volatility finds the player's new volatility with the Illinois algorithm,
+// as in step 5 of Glickman's description of Glicko-2.
+func (g Glicko2) volatility(phi, sigma, variance, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + variance + ex
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(g.Tau*g.Tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+variance {
+		B = math.Log(delta*delta - phi*phi - variance)
+	} else {
+		k := 1.0
+		for f(a-k*g.Tau) < 0 {
+			k++
+		}
+		B = a - k*g.Tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > glicko2Epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	return math.Exp(A / 2)
+}
+
+// withGlicko2Defaults gives players rated before deviations were tracked
+// those of a new player.
+func withGlicko2Defaults(rating models.PlayerRating) models.PlayerRating {
+	if rating.Deviation <= 0 {
+		rating.Deviation = models.DefaultRatingDeviation
+	}
+	if rating.Volatility <= 0 {
+		rating.Volatility = models.DefaultRatingVolatility
+	}
+	return rating
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// LifecycleService moves games that are waiting in a lobby into play.
+type LifecycleService struct {
+	db       *database.DB
+	registry *EngineRegistry
+	starters *StarterSelector
+	series   *SeriesService
+}
+
+func NewLifecycleService(db *database.DB, registry *EngineRegistry, starters *StarterSelector) *LifecycleService {
+	return &LifecycleService{
+		db:       db,
+		registry: registry,
+		starters: starters,
+	}
+}
+
+// SetSeries must be called before games are started. Without it games
+// asking for a series are played as single games.
+func (s *LifecycleService) SetSeries(series *SeriesService) {
+	s.series = series
+}
+
+// StartGame initializes the game state once every seat is taken.
+func (s *LifecycleService) StartGame(gameID uuid.UUID) (*models.Game, error) {
+	game, err := s.getGame(gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	if game.Status != models.GameStatusWaiting {
+		return nil, ErrGameNotWaiting
+	}
+	if !game.IsFull() {
+		return nil, ErrGameNotFull
+	}
+
+	engine, err := s.registry.GetEngine(game.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.starters.InitializeGame(engine, game); err != nil {
+		return nil, err
+	}
+
+	if s.series != nil {
+		if err := s.series.Begin(game); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	game.Status = models.GameStatusInProgress
+	game.StartedAt = &now
+	game.MoveDeadline = game.Settings.MoveDeadline(now)
+
+	if err := s.db.UpdateGame(game); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+// ReleaseSeat removes a player from a game that has not started. Other
+// players' seats are simply freed; if the creator leaves, the game is
+// abandoned.
+func (s *LifecycleService) ReleaseSeat(gameID, playerID uuid.UUID) (*models.Game, error) {
+	game, err := s.getGame(gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	if game.Status != models.GameStatusWaiting {
+		return nil, ErrGameNotWaiting
+	}
+
+	switch {
+	case game.Creator() == playerID:
+		now := time.Now()
+		game.Status = models.GameStatusAbandoned
+		game.EndedAt = &now
+	case !game.ReleaseSeat(playerID):
+		return nil, ErrNotInGame
+	}
+
+	if err := s.db.UpdateGame(game); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+func (s *LifecycleService) getGame(gameID uuid.UUID) (*models.Game, error) {
+	game, err := s.db.GetGame(gameID)
+	if err == sql.ErrNoRows {
+		return nil, ErrGameNotFound
+	}
+	return game, err
+}
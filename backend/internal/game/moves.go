@@ -0,0 +1,270 @@
+package game
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+var (
+	ErrGameNotFound      = errors.New("game_not_found")
+	ErrGameNotInProgress = errors.New("game_not_in_progress")
+	ErrGamePaused        = errors.New("game_paused")
+	ErrGameNotWaiting    = errors.New("game_not_waiting")
+	ErrGameNotFull       = errors.New("game_not_full")
+	ErrNotInGame         = errors.New("not_in_game")
+	ErrInvalidMove       = errors.New("invalid_move")
+	// ErrGameChanged is returned when another move or a timeout was
+	// recorded while the move was being made
+	ErrGameChanged = errors.New("game_changed")
+)
+
+// moveLockStripes bounds the number of mutexes used to serialize moves; games
+// hashing to the same stripe simply wait on each other.
+const moveLockStripes = 64
+
+// MoveService validates moves against the game engine and persists the
+// resulting state, making the server the authority on every game.
+type MoveService struct {
+	db       *database.DB
+	registry *EngineRegistry
+	results  ResultRecorder
+	locks    [moveLockStripes]sync.Mutex
+}
+
+// ResultRecorder is told about every game that finishes, whether completed
+// or abandoned.
+type ResultRecorder interface {
+	RecordResult(game *models.Game)
+}
+
+func NewMoveService(db *database.DB, registry *EngineRegistry) *MoveService {
+	return &MoveService{
+		db:       db,
+		registry: registry,
+	}
+}
+
+// SetResultRecorder must be called before moves are processed.
+func (s *MoveService) SetResultRecorder(results ResultRecorder) {
+	s.results = results
+}
+
+// ProcessMove applies a player's move and returns the updated game. Errors
+// other than the Err* values above are internal failures.
+func (s *MoveService) ProcessMove(gameID, playerID uuid.UUID, move json.RawMessage) (*models.Game, error) {
+	lock := &s.locks[int(gameID[0])%moveLockStripes]
+	lock.Lock()
+	defer lock.Unlock()
+
+	game, err := s.db.GetGame(gameID)
+	if err == sql.ErrNoRows {
+		return nil, ErrGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if game.Status != models.GameStatusInProgress {
+		return nil, ErrGameNotInProgress
+	}
+	if game.PausedAt != nil {
+		return nil, ErrGamePaused
+	}
+	if !game.HasPlayer(playerID) {
+		return nil, ErrNotInGame
+	}
+
+	engine, err := s.registry.GetEngine(game.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := engine.ValidateMove(game.GameState, move, playerID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMove, err)
+	}
+
+	newState, err := engine.ApplyMove(game.GameState, move, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMove, err)
+	}
+
+	game.GameState = newState
+	applyStatus(game, engine.GetGameStatus(newState))
+
+	err = s.db.RecordMove(game, &models.Move{
+		ID:       uuid.New(),
+		GameID:   game.ID,
+		PlayerID: playerID,
+		MoveData: move,
+		IsValid:  true,
+	})
+	if errors.Is(err, database.ErrGameChanged) {
+		return nil, ErrGameChanged
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	recordResult(s.results, game)
+	return game, nil
+}
+
+// Resign ends the game in the opponent's favour. Players may resign while
+// the game is paused.
+func (s *MoveService) Resign(gameID, playerID uuid.UUID) (*models.Game, error) {
+	lock := &s.locks[int(gameID[0])%moveLockStripes]
+	lock.Lock()
+	defer lock.Unlock()
+
+	game, err := s.db.GetGame(gameID)
+	if err == sql.ErrNoRows {
+		return nil, ErrGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if game.Status != models.GameStatusInProgress {
+		return nil, ErrGameNotInProgress
+	}
+	// Resigning hands the game to the one opponent
+	opponents := game.Opponents(playerID)
+	if !game.HasPlayer(playerID) || len(opponents) != 1 {
+		return nil, ErrNotInGame
+	}
+
+	winner := opponents[0]
+	now := time.Now()
+	game.Status = models.GameStatusCompleted
+	game.WinnerID = &winner
+	game.EndReason = models.EndReasonResignation
+	game.CurrentTurn = nil
+	game.EndedAt = &now
+
+	// A timeout or abandonment check may have ended it first
+	ended, err := s.db.EndGame(game)
+	if err != nil {
+		return nil, err
+	}
+	if !ended {
+		return nil, ErrGameNotInProgress
+	}
+
+	recordResult(s.results, game)
+	return game, nil
+}
+
+// Stalemate ends the game in a draw when it is playerID's move and the
+// engine offers them none.
+func (s *MoveService) Stalemate(gameID, playerID uuid.UUID) (*models.Game, error) {
+	lock := &s.locks[int(gameID[0])%moveLockStripes]
+	lock.Lock()
+	defer lock.Unlock()
+
+	game, err := s.db.GetGame(gameID)
+	if err == sql.ErrNoRows {
+		return nil, ErrGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if game.Status != models.GameStatusInProgress {
+		return nil, ErrGameNotInProgress
+	}
+	if game.CurrentTurn == nil || *game.CurrentTurn != playerID {
+		return nil, ErrNotInGame
+	}
+
+	engine, err := s.registry.GetEngine(game.Type)
+	if err != nil {
+		return nil, err
+	}
+	moves, err := engine.GetPossibleMoves(game.GameState, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(moves) > 0 {
+		return nil, fmt.Errorf("%w: player has moves", ErrInvalidMove)
+	}
+
+	now := time.Now()
+	game.Status = models.GameStatusCompleted
+	game.WinnerID = nil
+	game.EndReason = models.EndReasonStalemate
+	game.CurrentTurn = nil
+	game.EndedAt = &now
+
+	ended, err := s.db.EndGame(game)
+	if err != nil {
+		return nil, err
+	}
+	if !ended {
+		return nil, ErrGameNotInProgress
+	}
+
+	recordResult(s.results, game)
+	return game, nil
+}
+
+// ExpireTurn applies the engine's timeout rule to the player whose turn ran
+// out in the game as listed, and records it in the move log. Nothing
+// happens if the game has moved on since, so each turn times out once
+// however many instances try.
+func (s *MoveService) ExpireTurn(listed *models.Game) error {
+	lock := &s.locks[int(listed.ID[0])%moveLockStripes]
+	lock.Lock()
+	defer lock.Unlock()
+
+	game, err := s.db.GetGame(listed.ID)
+	if err != nil {
+		return err
+	}
+	if game.Status != models.GameStatusInProgress || game.PausedAt != nil || game.MoveCount != listed.MoveCount ||
+		game.CurrentTurn == nil || listed.CurrentTurn == nil || *game.CurrentTurn != *listed.CurrentTurn {
+		return nil
+	}
+
+	engine, err := s.registry.GetEngine(game.Type)
+	if err != nil {
+		return err
+	}
+
+	playerID := *game.CurrentTurn
+	newState, err := engine.OnTimeout(game.GameState, playerID)
+	if err != nil {
+		return err
+	}
+
+	game.GameState = newState
+	applyStatus(game, engine.GetGameStatus(newState))
+
+	// Replays apply the same rule when they reach this entry
+	err = s.db.RecordMove(game, &models.Move{
+		ID:       uuid.New(),
+		GameID:   game.ID,
+		PlayerID: playerID,
+		MoveData: json.RawMessage(`{}`),
+		IsValid:  true,
+		Kind:     models.MoveKindTimeout,
+	})
+	if errors.Is(err, database.ErrGameChanged) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Turn timed out for player %s in game %s", playerID, game.ID)
+	recordResult(s.results, game)
+	return nil
+}
@@ -0,0 +1,156 @@
+package game
+
+import (
+	"log"
+	"math"
+	"strings"
+
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// eloKFactor is the most one game can move an established rating. A
+// provisional rating moves up to eloProvisionalKFactor, while an established
+// player facing a provisional one risks only eloProvisionalOpponentKFactor.
+const (
+	eloKFactor                    = 32
+	eloProvisionalKFactor         = 64
+	eloProvisionalOpponentKFactor = 16
+)
+
+// RatingSystem rates the players of a two-player game.
+type RatingSystem interface {
+	Name() models.RatingSystem
+	// Rate returns both players' ratings after a game in which the first
+	// scored score: 1 for a win, 0.5 for a draw and 0 for a loss.
+	Rate(player1, player2 models.PlayerRating, score float64) (models.PlayerRating, models.PlayerRating)
+}
+
+// NewRatingSystem returns the rating system with the given name.
+func NewRatingSystem(name models.RatingSystem) (RatingSystem, bool) {
+	switch name {
+	case models.RatingSystemElo:
+		return Elo{}, true
+	case models.RatingSystemGlicko2:
+		return NewGlicko2(), true
+	}
+	return nil, false
+}
+
+// RatingRecorder updates both players' ratings after a completed rated
+// game, with the rating system chosen for its game type, and moves them
+// between tiers.
+type RatingRecorder struct {
+	db        *database.DB
+	system    RatingSystem
+	gameTypes map[models.GameType]RatingSystem
+}
+
+// NewRatingRecorder rates games with the named system, except game types
+// given their own as "type:system" overrides. Unknown systems fall back to
+// Elo.
+func NewRatingRecorder(db *database.DB, system string, overrides []string) *RatingRecorder {
+	r := &RatingRecorder{
+		db:        db,
+		system:    ratingSystemOrElo(system),
+		gameTypes: make(map[models.GameType]RatingSystem),
+	}
+
+	for _, override := range overrides {
+		gameType, name, ok := strings.Cut(override, ":")
+		if !ok {
+			log.Printf("Ignoring rating system override %q, expected type:system", override)
+			continue
+		}
+		r.gameTypes[models.GameType(gameType)] = ratingSystemOrElo(name)
+	}
+	return r
+}
+
+func ratingSystemOrElo(name string) RatingSystem {
+	system, ok := NewRatingSystem(models.RatingSystem(name))
+	if !ok {
+		log.Printf("Unknown rating system %q, using %s", name, models.RatingSystemElo)
+		return Elo{}
+	}
+	return system
+}
+
+// SystemFor returns the rating system games of the type are rated with.
+func (r *RatingRecorder) SystemFor(gameType models.GameType) RatingSystem {
+	if system, ok := r.gameTypes[gameType]; ok {
+		return system
+	}
+	return r.system
+}
+
+func (r *RatingRecorder) RecordResult(game *models.Game) {
+	// Ratings are for head-to-head games
+	players := game.PlayerIDs()
+	if game.Status != models.GameStatusCompleted || !game.Settings.Rated || len(players) != 2 {
+		return
+	}
+
+	score := 0.5
+	if game.WinnerID != nil {
+		score = 0
+		if *game.WinnerID == players[0] {
+			score = 1
+		}
+	}
+
+	system := r.SystemFor(game.Type)
+	_, err := r.db.UpdateRatings(game, system.Name(), players, func(ratings []models.PlayerRating) []models.PlayerRating {
+		for i := range ratings {
+			ratings[i].Provisional = models.IsProvisional(ratings[i].RatedGames)
+		}
+		player1, player2 := system.Rate(ratings[0], ratings[1], score)
+		updated := []models.PlayerRating{player1, player2}
+		for i := range updated {
+			updated[i].RatedGames = ratings[i].RatedGames + 1
+			updated[i].Tier = NextTier(ratings[i].Tier, updated[i].Rating, updated[i].RatedGames)
+		}
+		return updated
+	})
+	if err != nil {
+		log.Printf("Error updating ratings for game %s: %v", game.ID, err)
+	}
+}
+
+// Elo moves ratings by up to eloKFactor a game. Between established
+// players, what one gains the other loses; provisional ratings move
+// further, and move their opponents' less.
+type Elo struct{}
+
+func (Elo) Name() models.RatingSystem { return models.RatingSystemElo }
+
+func (Elo) Rate(player1, player2 models.PlayerRating, score float64) (models.PlayerRating, models.PlayerRating) {
+	ratings := eloRatings(player1.Rating, player2.Rating, score,
+		eloKFactorFor(player1, player2), eloKFactorFor(player2, player1))
+	player1.Rating, player2.Rating = ratings[0], ratings[1]
+	return player1, player2
+}
+
+// eloKFactorFor returns how far one game against opponent can move
+// player's rating.
+func eloKFactorFor(player, opponent models.PlayerRating) float64 {
+	switch {
+	case player.Provisional:
+		return eloProvisionalKFactor
+	case opponent.Provisional:
+		return eloProvisionalOpponentKFactor
+	}
+	return eloKFactor
+}
+
+// eloRatings returns both players' ratings after a game in which the first
+// scored score: 1 for a win, 0.5 for a draw and 0 for a loss. Each rating
+// moves by up to its K-factor, so with equal K-factors what one player
+// gains the other loses.
+func eloRatings(rating1, rating2 int, score, k1, k2 float64) []int {
+	expected := 1 / (1 + math.Pow(10, float64(rating2-rating1)/400))
+	return []int{
+		rating1 + int(math.Round(k1*(score-expected))),
+		rating2 - int(math.Round(k2*(score-expected))),
+	}
+}
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// EventTurnReminder is sent to the player to move when their turn drags on
+// or, in correspondence games, their move deadline approaches.
+const EventTurnReminder = "turn_reminder"
+
+const turnReminderInterval = 15 * time.Second
+
+// deadlineReminder numbers the reminder of a correspondence move deadline;
+// the reminders of a slow live turn are numbered from 1.
+const deadlineReminder = 0
+
+// UserNotifier delivers an event to a user's connections.
+type UserNotifier interface {
+	NotifyUser(userID uuid.UUID, event string, data interface{}) error
+}
+
+// TurnReminder is the data of a turn_reminder event
+type TurnReminder struct {
+	GameID        uuid.UUID       `json:"game_id"`
+	GameType      models.GameType `json:"game_type"`
+	TurnStartedAt time.Time       `json:"turn_started_at"`
+	// MoveDeadline is set in correspondence games
+	MoveDeadline *time.Time `json:"move_deadline,omitempty"`
+}
+
+// TurnReminderService reminds players it is their move. In live games the
+// player to move is reminded every interval into their turn, up to limit
+// times; in correspondence games, once when their deadline is
+// beforeDeadline away. Each reminder is claimed in the database before it
+// is sent, so it goes out once per game and turn across instances.
+type TurnReminderService struct {
+	db             *database.DB
+	interval       time.Duration
+	limit          int
+	beforeDeadline time.Duration
+	notifier       UserNotifier
+}
+
+func NewTurnReminderService(db *database.DB, interval time.Duration, limit int, beforeDeadline time.Duration) *TurnReminderService {
+	return &TurnReminderService{
+		db:             db,
+		interval:       interval,
+		limit:          limit,
+		beforeDeadline: beforeDeadline,
+	}
+}
+
+// SetNotifier must be called before Start. Without it no reminders are
+// sent.
+func (s *TurnReminderService) SetNotifier(notifier UserNotifier) {
+	s.notifier = notifier
+}
+
+func (s *TurnReminderService) Start() {
+	if !s.remindsLive() && s.beforeDeadline <= 0 || s.notifier == nil {
+		log.Println("Turn reminders disabled")
+		return
+	}
+
+	log.Printf("Starting turn reminders (interval: %s, limit: %d, before deadline: %s)...",
+		s.interval, s.limit, s.beforeDeadline)
+
+	ticker := time.NewTicker(turnReminderInterval)
+	go func() {
+		for range ticker.C {
+			s.sendReminders()
+		}
+	}()
+}
+
+func (s *TurnReminderService) remindsLive() bool {
+	return s.interval > 0 && s.limit > 0
+}
+
+func (s *TurnReminderService) sendReminders() {
+	// The zero time matches no game, leaving that kind of reminder off
+	now := time.Now()
+	var startedBefore, deadlineBefore time.Time
+	if s.remindsLive() {
+		startedBefore = now.Add(-s.interval)
+	}
+	if s.beforeDeadline > 0 {
+		deadlineBefore = now.Add(s.beforeDeadline)
+	}
+
+	games, err := s.db.GetAwaitingTurnGames(startedBefore, deadlineBefore)
+	if err != nil {
+		log.Printf("Error getting games awaiting a move: %v", err)
+		return
+	}
+
+	for _, game := range games {
+		turnStartedAt := game.TurnStartedAt
+		if turnStartedAt == nil {
+			turnStartedAt = game.StartedAt
+		}
+		if turnStartedAt == nil {
+			continue
+		}
+
+		reminder := deadlineReminder
+		if game.MoveDeadline == nil {
+			// Only the latest reminder due is sent if earlier ones were missed
+			reminder = int(now.Sub(*turnStartedAt) / s.interval)
+			if reminder > s.limit {
+				reminder = s.limit
+			}
+		}
+
+		claimed, err := s.db.ClaimTurnReminder(game.ID, game.MoveCount, reminder)
+		if err != nil {
+			log.Printf("Error claiming turn reminder for game %s: %v", game.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		data := TurnReminder{
+			GameID:        game.ID,
+			GameType:      game.Type,
+			TurnStartedAt: *turnStartedAt,
+			MoveDeadline:  game.MoveDeadline,
+		}
+		if err := s.notifier.NotifyUser(*game.CurrentTurn, EventTurnReminder, data); err != nil {
+			log.Printf("Error reminding %s of game %s: %v", *game.CurrentTurn, game.ID, err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package game
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+var (
+	// ErrReplayUnavailable is returned for games started before their
+	// opening state was stored.
+	ErrReplayUnavailable = errors.New("replay_unavailable")
+	// ErrRebuildEndsGame is returned when a rebuilt state would end the
+	// game, which needs its result recorded rather than a silent rewrite.
+	ErrRebuildEndsGame = errors.New("rebuild_ends_game")
+)
+
+// Divergence is the first point where replaying a game's move log disagrees
+// with what was stored, which points at an engine change or bug.
+type Divergence struct {
+	MoveNumber int
+	Reason     string
+}
+
+func (d *Divergence) Error() string {
+	return fmt.Sprintf("diverged at move %d: %s", d.MoveNumber, d.Reason)
+}
+
+// Replay rebuilds a game's state after the first upTo entries of its move
+// log. Timeout entries apply the engine's timeout rule, as the turn timer
+// did.
+func Replay(engine GameEngine, game *models.Game, moves []*models.Move, upTo int) (json.RawMessage, error) {
+	if len(game.InitialState) == 0 {
+		return nil, ErrReplayUnavailable
+	}
+	return ReplayFrom(engine, openingSnapshot(game), moves, upTo)
+}
+
+// ReplayFrom is Replay starting from a snapshot taken at or before upTo
+// rather than the opening state.
+func ReplayFrom(engine GameEngine, snapshot *models.GameSnapshot, moves []*models.Move, upTo int) (json.RawMessage, error) {
+	if upTo < snapshot.MoveNumber || upTo > len(moves) {
+		return nil, fmt.Errorf("move %d is out of range", upTo)
+	}
+
+	state := snapshot.State
+	for i := snapshot.MoveNumber; i < upTo; i++ {
+		var err error
+		state, err = applyLogged(engine, state, moves[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay move %d: %w", i+1, err)
+		}
+	}
+	return state, nil
+}
+
+// Verify replays a game's whole move log from its opening state, checking
+// every player move against the engine and the state against each snapshot
+// and the stored state. It returns a *Divergence for the first mismatch.
+func Verify(engine GameEngine, game *models.Game, moves []*models.Move, snapshots []*models.GameSnapshot) error {
+	if len(game.InitialState) == 0 {
+		return ErrReplayUnavailable
+	}
+
+	expected := make(map[int]json.RawMessage, len(snapshots))
+	for _, snapshot := range snapshots {
+		expected[snapshot.MoveNumber] = snapshot.State
+	}
+
+	state := game.InitialState
+	for i, move := range moves {
+		if move.Kind != models.MoveKindTimeout {
+			if err := engine.ValidateMove(state, move.MoveData, move.PlayerID); err != nil {
+				return &Divergence{MoveNumber: i + 1, Reason: "move is no longer valid: " + err.Error()}
+			}
+		}
+
+		var err error
+		state, err = applyLogged(engine, state, move)
+		if err != nil {
+			return &Divergence{MoveNumber: i + 1, Reason: "move no longer applies: " + err.Error()}
+		}
+
+		if snapshot, ok := expected[i+1]; ok {
+			same, err := sameState(state, snapshot)
+			if err != nil {
+				return err
+			}
+			if !same {
+				return &Divergence{MoveNumber: i + 1, Reason: "state differs from the snapshot"}
+			}
+		}
+	}
+
+	same, err := sameState(state, game.GameState)
+	if err != nil {
+		return err
+	}
+	if !same {
+		return &Divergence{MoveNumber: len(moves), Reason: "state differs from the stored state"}
+	}
+	return nil
+}
+
+// Rebuild recomputes an in-progress game's state from its latest snapshot
+// and the moves since, as after a crash or an engine fix, and stores it if
+// it differs. It reports whether the stored state was rewritten.
+func Rebuild(db *database.DB, registry *EngineRegistry, gameID uuid.UUID) (bool, error) {
+	game, err := db.GetGame(gameID)
+	if err == sql.ErrNoRows {
+		return false, ErrGameNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	if game.Status != models.GameStatusInProgress {
+		return false, ErrGameNotInProgress
+	}
+
+	engine, err := registry.GetEngine(game.Type)
+	if err != nil {
+		return false, err
+	}
+
+	moves, err := db.GetGameMoves(gameID)
+	if err != nil {
+		return false, err
+	}
+	moves = validMoves(moves)
+
+	snapshot, err := db.GetGameSnapshot(gameID, len(moves))
+	if errors.Is(err, sql.ErrNoRows) {
+		if len(game.InitialState) == 0 {
+			return false, ErrReplayUnavailable
+		}
+		snapshot = openingSnapshot(game)
+	} else if err != nil {
+		return false, err
+	}
+
+	state, err := ReplayFrom(engine, snapshot, moves, len(moves))
+	if err != nil {
+		return false, err
+	}
+	same, err := sameState(state, game.GameState)
+	if err != nil || same {
+		return false, err
+	}
+
+	status := engine.GetGameStatus(state)
+	if status.IsGameOver {
+		return false, ErrRebuildEndsGame
+	}
+	game.GameState = state
+	game.CurrentTurn = status.NextPlayer
+	if err := db.UpdateGame(game); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// validMoves leaves out moves the server rejected, which never changed the
+// state.
+func validMoves(moves []*models.Move) []*models.Move {
+	valid := make([]*models.Move, 0, len(moves))
+	for _, move := range moves {
+		if move.IsValid {
+			valid = append(valid, move)
+		}
+	}
+	return valid
+}
+
+func openingSnapshot(game *models.Game) *models.GameSnapshot {
+	return &models.GameSnapshot{GameID: game.ID, State: game.InitialState, CreatedAt: game.CreatedAt}
+}
+
+func applyLogged(engine GameEngine, state json.RawMessage, move *models.Move) (json.RawMessage, error) {
+	if move.Kind == models.MoveKindTimeout {
+		return engine.OnTimeout(state, move.PlayerID)
+	}
+	return engine.ApplyMove(state, move.MoveData, move.PlayerID)
+}
+
+// sameState compares states as JSON values, since the database may store
+// them with keys reordered.
+func sameState(a, b json.RawMessage) (bool, error) {
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(va, vb), nil
+}
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// EventSeasonReward is sent to players granted a reward when a season ends.
+const EventSeasonReward = "season_reward"
+
+// seasonRewardMinGames is how many games of a type a player must finish in a
+// season to be ranked for its rewards.
+const seasonRewardMinGames = 10
+
+// SeasonService rates completed rated games in the season they ended in, and
+// grants each season's rewards once it is over. Season ratings are Elo
+// whatever system overall ratings use, and are soft reset at each season's
+// start by resetFactor.
+type SeasonService struct {
+	db          *database.DB
+	interval    time.Duration
+	resetFactor float64
+	notifier    UserNotifier
+}
+
+func NewSeasonService(db *database.DB, interval time.Duration, resetFactor float64) *SeasonService {
+	return &SeasonService{
+		db:          db,
+		interval:    interval,
+		resetFactor: resetFactor,
+	}
+}
+
+// SetNotifier must be called before Start. Without it rewards are granted
+// silently.
+func (s *SeasonService) SetNotifier(notifier UserNotifier) {
+	s.notifier = notifier
+}
+
+func (s *SeasonService) Start() {
+	if s.interval <= 0 {
+		log.Println("Season finalization disabled")
+		return
+	}
+
+	log.Printf("Starting season finalization (interval: %s, reset factor: %.2f)...", s.interval, s.resetFactor)
+
+	ticker := time.NewTicker(s.interval)
+	go func() {
+		for range ticker.C {
+			s.finalizeSeasons()
+		}
+	}()
+}
+
+func (s *SeasonService) RecordResult(game *models.Game) {
+	players := game.PlayerIDs()
+	if game.Status != models.GameStatusCompleted || !game.Settings.Rated || len(players) != 2 {
+		return
+	}
+
+	endedAt := time.Now()
+	if game.EndedAt != nil {
+		endedAt = *game.EndedAt
+	}
+	season, err := s.db.GetSeasonAt(endedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting season for game %s: %v", game.ID, err)
+		return
+	}
+
+	score := 0.5
+	if game.WinnerID != nil {
+		score = 0
+		if *game.WinnerID == players[0] {
+			score = 1
+		}
+	}
+
+	err = s.db.UpdateSeasonRatings(season, game, s.resetFactor, func(rating1, rating2 int) (int, int) {
+		ratings := eloRatings(rating1, rating2, score, eloKFactor, eloKFactor)
+		return ratings[0], ratings[1]
+	})
+	if err != nil {
+		log.Printf("Error updating season ratings for game %s: %v", game.ID, err)
+	}
+}
+
+func (s *SeasonService) finalizeSeasons() {
+	seasons, err := s.db.GetUnfinalizedSeasons(time.Now())
+	if err != nil {
+		log.Printf("Error getting ended seasons: %v", err)
+		return
+	}
+
+	for _, season := range seasons {
+		grants, finalized, err := s.db.FinalizeSeason(season, seasonRewardMinGames)
+		if err != nil {
+			log.Printf("Error finalizing season %s: %v", season.ID, err)
+			continue
+		}
+		if !finalized {
+			// Another instance finalized it first
+			continue
+		}
+
+		log.Printf("Finalized season %q, granting %d rewards", season.Name, len(grants))
+		if s.notifier == nil {
+			continue
+		}
+		for _, grant := range grants {
+			if err := s.notifier.NotifyUser(grant.UserID, EventSeasonReward, grant); err != nil {
+				log.Printf("Error notifying %s of season reward: %v", grant.UserID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package game
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// Events sent through the UserNotifier to both players of a series.
+const (
+	EventSeriesNextGame = "series_next_game"
+	EventSeriesEnded    = "series_ended"
+)
+
+// SeriesUpdate is the data of series_next_game and series_ended events.
+// GameID and StarterID are the next game's, and unset once it ends.
+type SeriesUpdate struct {
+	Series    *models.Series `json:"series"`
+	GameID    *uuid.UUID     `json:"game_id,omitempty"`
+	StarterID *uuid.UUID     `json:"starter_id,omitempty"`
+}
+
+// SeriesService plays best-of-N series. A game whose settings ask for a
+// series starts one; as each game finishes, the series' score is updated
+// and the next game is started with the other player moving first, until
+// the series is decided.
+type SeriesService struct {
+	db       *database.DB
+	registry *EngineRegistry
+	notifier UserNotifier
+}
+
+func NewSeriesService(db *database.DB, registry *EngineRegistry) *SeriesService {
+	return &SeriesService{
+		db:       db,
+		registry: registry,
+	}
+}
+
+// SetNotifier must be called before games are played. Without it players
+// are not told when the next game starts.
+func (s *SeriesService) SetNotifier(notifier UserNotifier) {
+	s.notifier = notifier
+}
+
+// Begin starts a series with the game as its first game if the game's
+// settings ask for one. It is called as the game starts, before it is
+// saved. Series are played between two players.
+func (s *SeriesService) Begin(game *models.Game) error {
+	players := game.PlayerIDs()
+	if game.Settings.BestOf <= 1 || game.SeriesID != nil || len(players) != 2 {
+		return nil
+	}
+
+	series := &models.Series{
+		ID:        uuid.New(),
+		Type:      game.Type,
+		Player1ID: players[0],
+		Player2ID: players[1],
+		BestOf:    game.Settings.BestOf,
+		Settings:  game.Settings,
+	}
+	if err := s.db.CreateSeries(series); err != nil {
+		return err
+	}
+
+	game.SeriesID = &series.ID
+	game.SeriesGame = 1
+	return nil
+}
+
+// RecordResult counts a finished game toward its series, then starts the
+// next game or ends the series.
+func (s *SeriesService) RecordResult(game *models.Game) {
+	if game.SeriesID == nil {
+		return
+	}
+	if err := s.recordGame(game); err != nil {
+		log.Printf("Error recording game %s in series %s: %v", game.ID, *game.SeriesID, err)
+	}
+}
+
+func (s *SeriesService) recordGame(game *models.Game) error {
+	// Nobody is left to play the rest of the series
+	if game.Status == models.GameStatusAbandoned {
+		series, err := s.db.GetSeries(*game.SeriesID)
+		if err != nil {
+			return err
+		}
+		series.Games = nil
+		return s.end(series, models.SeriesStatusAbandoned)
+	}
+
+	series, err := s.db.RecordSeriesGame(game)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Already counted, or the series ended
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if series.Decided() {
+		return s.end(series, models.SeriesStatusCompleted)
+	}
+
+	next, err := s.nextGame(series, game)
+	if err != nil {
+		return err
+	}
+	log.Printf("Series %s: game %d of %d started as %s", series.ID, next.SeriesGame, series.BestOf, next.ID)
+	s.notify(series, EventSeriesNextGame, SeriesUpdate{Series: series, GameID: &next.ID, StarterID: next.StarterID})
+	return nil
+}
+
+// nextGame starts the series' next game, with whoever did not move first
+// in the previous one moving first.
+func (s *SeriesService) nextGame(series *models.Series, previous *models.Game) (*models.Game, error) {
+	engine, err := s.registry.GetEngine(series.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	game := &models.Game{
+		ID:           uuid.New(),
+		Type:         series.Type,
+		Status:       models.GameStatusInProgress,
+		Private:      previous.Private,
+		Visibility:   previous.Visibility,
+		Settings:     series.Settings,
+		StartedAt:    &now,
+		MoveDeadline: series.Settings.MoveDeadline(now),
+		SeriesID:     &series.ID,
+		SeriesGame:   previous.SeriesGame + 1,
+	}
+	game.SetPlayers(series.Player1ID, series.Player2ID)
+
+	starter := series.Player1ID
+	if previous.StarterID != nil && *previous.StarterID == series.Player1ID {
+		starter = series.Player2ID
+	}
+	if err := initializeGame(engine, game, starter); err != nil {
+		return nil, err
+	}
+
+	tags, err := s.db.GetGameTags([]uuid.UUID{previous.ID})
+	if err != nil {
+		return nil, err
+	}
+	game.Tags = tags[previous.ID]
+
+	if err := s.db.CreateGame(game); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+func (s *SeriesService) end(series *models.Series, status models.SeriesStatus) error {
+	now := time.Now()
+	series.Status = status
+	series.EndedAt = &now
+	if status == models.SeriesStatusCompleted {
+		series.WinnerID = series.Leader()
+	}
+
+	// Another instance may have ended it first
+	ended, err := s.db.EndSeries(series)
+	if err != nil || !ended {
+		return err
+	}
+
+	if status == models.SeriesStatusCompleted && series.Settings.Rated {
+		for _, playerID := range []uuid.UUID{series.Player1ID, series.Player2ID} {
+			won := series.WinnerID != nil && *series.WinnerID == playerID
+			if err := s.db.AddSeriesResult(playerID, won); err != nil {
+				log.Printf("Error recording series %s for %s: %v", series.ID, playerID, err)
+			}
+		}
+	}
+
+	log.Printf("Series %s %s", series.ID, status)
+	s.notify(series, EventSeriesEnded, SeriesUpdate{Series: series})
+	return nil
+}
+
+func (s *SeriesService) notify(series *models.Series, event string, update SeriesUpdate) {
+	if s.notifier == nil {
+		return
+	}
+	for _, playerID := range []uuid.UUID{series.Player1ID, series.Player2ID} {
+		if err := s.notifier.NotifyUser(playerID, event, update); err != nil {
+			log.Printf("Error notifying user %s of %s: %v", playerID, event, err)
+		}
+	}
+}
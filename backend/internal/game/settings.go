@@ -0,0 +1,104 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// ErrInvalidSettings is returned for settings a game type doesn't offer.
+var ErrInvalidSettings = errors.New("invalid game settings")
+
+// Limits of live "minutes+increment" time controls
+const (
+	maxTimeControlMinutes   = 180
+	maxTimeControlIncrement = 60
+)
+
+// SettingsSchema lists the settings a game type offers. The first entry of
+// each list is the default.
+type SettingsSchema struct {
+	Variants []string `json:"variants"`
+	// BoardSizes are the board, or for tile games set, sizes on offer
+	BoardSizes []int `json:"board_sizes"`
+}
+
+// ConfigurableEngine is implemented by engines that offer settings to choose
+// from. Other engines only accept the default settings.
+type ConfigurableEngine interface {
+	SettingsSchema() SettingsSchema
+}
+
+// SchemaFor returns the settings the engine offers.
+func SchemaFor(engine GameEngine) SettingsSchema {
+	if configurable, ok := engine.(ConfigurableEngine); ok {
+		return configurable.SettingsSchema()
+	}
+	return SettingsSchema{}
+}
+
+// ApplySettings checks settings against the engine's schema and fills in
+// its defaults, so stored settings always name the rules in effect.
+func ApplySettings(engine GameEngine, settings *models.GameSettings) error {
+	schema := SchemaFor(engine)
+
+	switch {
+	case settings.Variant == "" && len(schema.Variants) > 0:
+		settings.Variant = schema.Variants[0]
+	case settings.Variant != "" && !schema.OffersVariant(settings.Variant):
+		return fmt.Errorf("%w: %s has no %q variant", ErrInvalidSettings, engine.GetGameType(), settings.Variant)
+	}
+
+	switch {
+	case settings.BoardSize == 0 && len(schema.BoardSizes) > 0:
+		settings.BoardSize = schema.BoardSizes[0]
+	case settings.BoardSize != 0 && !containsInt(schema.BoardSizes, settings.BoardSize):
+		return fmt.Errorf("%w: %s has no board size %d", ErrInvalidSettings, engine.GetGameType(), settings.BoardSize)
+	}
+
+	if !ValidTimeControl(settings.TimeControl) {
+		return fmt.Errorf("%w: unknown time control %q", ErrInvalidSettings, settings.TimeControl)
+	}
+	return nil
+}
+
+// OffersVariant reports whether variant is one of the schema's variants.
+func (s SettingsSchema) OffersVariant(variant string) bool {
+	for _, offered := range s.Variants {
+		if offered == variant {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidTimeControl reports whether timeControl is unset, correspondence or
+// a live "minutes+increment" control such as "5+0".
+func ValidTimeControl(timeControl string) bool {
+	if timeControl == "" || timeControl == models.TimeControlCorrespondence {
+		return true
+	}
+
+	minutes, increment, ok := strings.Cut(timeControl, "+")
+	if !ok {
+		return false
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 1 || m > maxTimeControlMinutes {
+		return false
+	}
+	i, err := strconv.Atoi(increment)
+	return err == nil && i >= 0 && i <= maxTimeControlIncrement
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
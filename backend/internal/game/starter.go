@@ -0,0 +1,128 @@
+package game
+
+import (
+	"errors"
+	"log"
+	"math/rand"
+
+	"github.com/google/uuid"
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// Starter policies decide who moves first.
+const (
+	StarterPolicyRandom = "random"
+	// StarterPolicyAlternate gives the first move to whoever did not have it
+	// in the pair's last game of the same type
+	StarterPolicyAlternate = "alternate"
+	// StarterPolicyRating gives the first move to the lower-rated player
+	StarterPolicyRating = "rating"
+
+	defaultRating = 1000
+)
+
+// StarterSelector picks who moves first and sets up new games accordingly.
+type StarterSelector struct {
+	db     *database.DB
+	policy string
+}
+
+func NewStarterSelector(db *database.DB, policy string) *StarterSelector {
+	switch policy {
+	case StarterPolicyRandom, StarterPolicyAlternate, StarterPolicyRating:
+	default:
+		log.Printf("Unknown starter policy %q, using %s", policy, StarterPolicyRandom)
+		policy = StarterPolicyRandom
+	}
+
+	return &StarterSelector{
+		db:     db,
+		policy: policy,
+	}
+}
+
+// InitializeGame seats the game's players in the engine, picks the starter
+// and records the opening state on the game.
+func (s *StarterSelector) InitializeGame(engine GameEngine, game *models.Game) error {
+	if !game.IsFull() {
+		return ErrGameNotFull
+	}
+	players := game.PlayerIDs()
+	return initializeGame(engine, game, s.SelectStarter(game.Type, players[0], players[1]))
+}
+
+// initializeGame seats the game's players in the engine, in seat order,
+// with the given starter and records the opening state and the players'
+// colors on the game.
+func initializeGame(engine GameEngine, game *models.Game, starter uuid.UUID) error {
+	initialState, err := engine.Initialize(game.PlayerIDs(), GameOptions{Starter: starter, Settings: game.Settings})
+	if err != nil {
+		return err
+	}
+
+	// Engines may apply their own rules, so trust the state over the choice
+	status := engine.GetGameStatus(initialState)
+	if status.NextPlayer == nil {
+		return errors.New("initialized game has no next player")
+	}
+	firstPlayer := *status.NextPlayer
+
+	var colors map[uuid.UUID]string
+	if colored, ok := engine.(ColoredEngine); ok {
+		if colors, err = colored.PlayerColors(initialState); err != nil {
+			return err
+		}
+	}
+
+	game.GameState = initialState
+	game.InitialState = initialState
+	game.CurrentTurn = &firstPlayer
+	game.StarterID = &firstPlayer
+	game.Players = game.Seats()
+	for i := range game.Players {
+		game.Players[i].Color = colors[game.Players[i].UserID]
+	}
+	return nil
+}
+
+// SelectStarter returns the player who should move first. Lookup failures
+// fall back to a random choice.
+func (s *StarterSelector) SelectStarter(gameType models.GameType, player1, player2 uuid.UUID) uuid.UUID {
+	switch s.policy {
+	case StarterPolicyAlternate:
+		last, err := s.db.GetLastStarter(gameType, player1, player2)
+		if err != nil {
+			log.Printf("Error getting last starter for %s and %s: %v", player1, player2, err)
+			break
+		}
+		if last != nil {
+			if *last == player1 {
+				return player2
+			}
+			return player1
+		}
+
+	case StarterPolicyRating:
+		rating1, rating2 := s.rating(player1), s.rating(player2)
+		if rating1 < rating2 {
+			return player1
+		}
+		if rating2 < rating1 {
+			return player2
+		}
+	}
+
+	if rand.Intn(2) == 0 {
+		return player1
+	}
+	return player2
+}
+
+func (s *StarterSelector) rating(userID uuid.UUID) int {
+	stats, err := s.db.GetUserStats(userID)
+	if err != nil {
+		return defaultRating
+	}
+	return stats.Rating
+}
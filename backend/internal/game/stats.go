@@ -0,0 +1,47 @@
+package game
+
+import (
+	"log"
+
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// ResultRecorders tells each recorder about a finished game in turn.
+type ResultRecorders []ResultRecorder
+
+func (r ResultRecorders) RecordResult(game *models.Game) {
+	for _, results := range r {
+		results.RecordResult(game)
+	}
+}
+
+// StatsRecorder counts finished rated games toward both players' user_stats.
+type StatsRecorder struct {
+	db *database.DB
+}
+
+func NewStatsRecorder(db *database.DB) *StatsRecorder {
+	return &StatsRecorder{db: db}
+}
+
+func (s *StatsRecorder) RecordResult(game *models.Game) {
+	players := game.PlayerIDs()
+	if game.Status != models.GameStatusCompleted || !game.Settings.Rated || len(players) < 2 {
+		return
+	}
+
+	for _, playerID := range players {
+		result := models.GameResultDraw
+		if game.WinnerID != nil {
+			result = models.GameResultLoss
+			if *game.WinnerID == playerID {
+				result = models.GameResultWin
+			}
+		}
+
+		if err := s.db.AddGameResult(playerID, result); err != nil {
+			log.Printf("Error recording result of game %s for %s: %v", game.ID, playerID, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package game
+
+import "github.com/szaher/vibeboard/backend/internal/models"
+
+// PlacementGames is how many rated games a new player plays unranked before
+// being placed in a tier by their rating.
+const PlacementGames = 5
+
+const (
+	divisionsPerTier = 4
+	divisionWidth    = 50
+	// bronzeDivisionFloor is where bronze's divisions are counted from, as
+	// bronze has no floor; its lowest division takes every rating below
+	bronzeDivisionFloor = 700
+	// demotionBuffer is how far below their tier's floor a player may fall
+	// before being demoted
+	demotionBuffer = 50
+)
+
+// tiers lists each tier with the rating it starts at, lowest first. Tiers
+// below master have divisions.
+var tiers = []struct {
+	tier  models.Tier
+	floor int
+}{
+	{models.TierBronze, 0},
+	{models.TierSilver, 900},
+	{models.TierGold, 1100},
+	{models.TierPlatinum, 1300},
+	{models.TierDiamond, 1500},
+	{models.TierMaster, 1700},
+	{models.TierGrandmaster, 1900},
+}
+
+// TierFor returns the tier and division rating falls in.
+func TierFor(rating int) models.RankedTier {
+	i := len(tiers) - 1
+	for i > 0 && rating < tiers[i].floor {
+		i--
+	}
+	tier := tiers[i]
+	if tier.floor >= tierFloor(models.TierMaster) {
+		return models.RankedTier{Tier: tier.tier}
+	}
+
+	floor := tier.floor
+	if tier.tier == models.TierBronze {
+		floor = bronzeDivisionFloor
+	}
+	steps := (rating - floor) / divisionWidth
+	if steps < 0 {
+		steps = 0
+	}
+	if steps > divisionsPerTier-1 {
+		steps = divisionsPerTier - 1
+	}
+	return models.RankedTier{Tier: tier.tier, Division: divisionsPerTier - steps}
+}
+
+// NextTier returns a player's tier after a rated game leaves them at rating
+// with ratedGames played. Players are unranked until their placement games
+// are done, and promoted as soon as their rating reaches a tier, but only
+// demoted once it falls demotionBuffer below their tier; until then they
+// hold its lowest division.
+func NextTier(current models.RankedTier, rating, ratedGames int) models.RankedTier {
+	if ratedGames < PlacementGames {
+		return models.RankedTier{Tier: models.TierUnranked}
+	}
+
+	placed := TierFor(rating)
+	if current.Tier == models.TierUnranked || current.Tier == "" || tierIndex(placed.Tier) >= tierIndex(current.Tier) {
+		return placed
+	}
+	if rating >= tierFloor(current.Tier)-demotionBuffer {
+		held := models.RankedTier{Tier: current.Tier}
+		if tierIndex(current.Tier) < tierIndex(models.TierMaster) {
+			held.Division = divisionsPerTier
+		}
+		return held
+	}
+	return placed
+}
+
+// PlacementGamesLeft returns how many more rated games an unranked player
+// with ratedGames played has until they are placed.
+func PlacementGamesLeft(tier models.RankedTier, ratedGames int) int {
+	if tier.Tier != models.TierUnranked || ratedGames >= PlacementGames {
+		return 0
+	}
+	return PlacementGames - ratedGames
+}
+
+func tierIndex(tier models.Tier) int {
+	for i, t := range tiers {
+		if t.tier == tier {
+			return i
+		}
+	}
+	return -1
+}
+
+func tierFloor(tier models.Tier) int {
+	return tiers[tierIndex(tier)].floor
+}
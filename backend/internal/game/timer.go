@@ -0,0 +1,75 @@
+package game
+
+import (
+	"log"
+	"time"
+
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// TurnTimerService times out players whose live turn ran past the
+// timeout, through the MoveService so timeouts and moves never interleave.
+type TurnTimerService struct {
+	db          *database.DB
+	moves       *MoveService
+	turnTimeout time.Duration
+}
+
+const turnTimerInterval = 5 * time.Second
+
+func NewTurnTimerService(db *database.DB, moves *MoveService, turnTimeout time.Duration) *TurnTimerService {
+	return &TurnTimerService{
+		db:          db,
+		moves:       moves,
+		turnTimeout: turnTimeout,
+	}
+}
+
+func (s *TurnTimerService) Start() {
+	if s.turnTimeout <= 0 {
+		log.Println("Turn timer disabled")
+		return
+	}
+
+	log.Printf("Starting turn timer service (timeout: %s)...", s.turnTimeout)
+
+	ticker := time.NewTicker(turnTimerInterval)
+	go func() {
+		for range ticker.C {
+			s.processTimeouts()
+		}
+	}()
+}
+
+func (s *TurnTimerService) processTimeouts() {
+	games, err := s.db.GetTimedOutGames(time.Now().Add(-s.turnTimeout))
+	if err != nil {
+		log.Printf("Error getting timed out games: %v", err)
+		return
+	}
+
+	for _, game := range games {
+		if err := s.moves.ExpireTurn(game); err != nil {
+			log.Printf("Error applying turn timeout to game %s: %v", game.ID, err)
+		}
+	}
+}
+
+// applyStatus copies the engine's view of the game onto the stored record.
+func applyStatus(game *models.Game, status GameStatusInfo) {
+	game.CurrentTurn = status.NextPlayer
+	if status.IsGameOver {
+		now := time.Now()
+		game.Status = models.GameStatusCompleted
+		game.WinnerID = status.Winner
+		game.EndReason = status.EndReason
+		game.EndedAt = &now
+	}
+}
+
+func recordResult(results ResultRecorder, game *models.Game) {
+	if results != nil && (game.Status == models.GameStatusCompleted || game.Status == models.GameStatusAbandoned) {
+		results.RecordResult(game)
+	}
+}
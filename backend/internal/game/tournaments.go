@@ -0,0 +1,100 @@
+package game
+
+import (
+	"log"
+	"time"
+
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// Event names sent to a tournament's registered players
+const (
+	// EventTournamentRound is sent when a round is about to start
+	EventTournamentRound     = "tournament_round"
+	EventTournamentCancelled = "tournament_cancelled"
+)
+
+const tournamentReminderInterval = 30 * time.Second
+
+// TournamentReminderService reminds registered players of each tournament
+// round beforeRound before it starts. Each reminder is claimed in the
+// database before it is sent, so it goes out once across instances.
+type TournamentReminderService struct {
+	db          *database.DB
+	beforeRound time.Duration
+	notifier    UserNotifier
+}
+
+func NewTournamentReminderService(db *database.DB, beforeRound time.Duration) *TournamentReminderService {
+	return &TournamentReminderService{
+		db:          db,
+		beforeRound: beforeRound,
+	}
+}
+
+// SetNotifier must be called before Start. Without it no reminders are
+// sent.
+func (s *TournamentReminderService) SetNotifier(notifier UserNotifier) {
+	s.notifier = notifier
+}
+
+func (s *TournamentReminderService) Start() {
+	if s.beforeRound <= 0 || s.notifier == nil {
+		log.Println("Tournament reminders disabled")
+		return
+	}
+
+	log.Printf("Starting tournament reminders (before round: %s)...", s.beforeRound)
+
+	ticker := time.NewTicker(tournamentReminderInterval)
+	go func() {
+		for range ticker.C {
+			s.sendReminders()
+		}
+	}()
+}
+
+func (s *TournamentReminderService) sendReminders() {
+	now := time.Now()
+	tournaments, err := s.db.GetTournamentsWithRoundsDue(now, now.Add(s.beforeRound))
+	if err != nil {
+		log.Printf("Error getting tournaments with rounds due: %v", err)
+		return
+	}
+
+	for _, tournament := range tournaments {
+		round, ok := tournament.NextRound(now)
+		if !ok {
+			continue
+		}
+
+		claimed, err := s.db.ClaimTournamentReminder(tournament.ID, round)
+		if err != nil {
+			log.Printf("Error claiming reminder for round %d of tournament %s: %v", round, tournament.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		players, err := s.db.GetTournamentRegistrants(tournament.ID)
+		if err != nil {
+			log.Printf("Error getting players of tournament %s: %v", tournament.ID, err)
+			continue
+		}
+
+		data := models.TournamentRound{
+			TournamentID:   tournament.ID,
+			TournamentName: tournament.Name,
+			GameType:       tournament.GameType,
+			Round:          round,
+			StartsAt:       tournament.RoundStartsAt(round),
+		}
+		for _, playerID := range players {
+			if err := s.notifier.NotifyUser(playerID, EventTournamentRound, data); err != nil {
+				log.Printf("Error reminding %s of tournament %s: %v", playerID, tournament.ID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+// Leaderboard views.
+const (
+	// ViewGlobal ranks everyone who has finished a game of the type by rating
+	ViewGlobal = "global"
+	// ViewWeekly ranks players by games won this ISO week
+	ViewWeekly = "weekly"
+	// ViewFriends ranks the player and their friends by rating
+	ViewFriends = "friends"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 100
+
+	weeklyKey = "leaderboard:%s:weekly:%s" // game type, ISO week
+	// weeklyTTL keeps a few past weeks around after they end
+	weeklyTTL = 5 * 7 * 24 * time.Hour
+
+	// refreshTimeout bounds a refresh of the rating views
+	refreshTimeout = 2 * time.Minute
+)
+
+var ErrInvalidView = errors.New("invalid_view")
+
+type Leaderboard struct {
+	GameType models.GameType            `json:"game_type"`
+	View     string                     `json:"view"`
+	Week     string                     `json:"week,omitempty"`
+	Entries  []*models.LeaderboardEntry `json:"entries"`
+	// Me is the requesting player's entry, even when they are outside the
+	// top entries. It is unset if they are not ranked.
+	Me *models.LeaderboardEntry `json:"me"`
+}
+
+// Service ranks players per game type. Rating views read rankings the
+// database keeps, refreshed after games complete and on a schedule; weekly
+// views are kept in Redis sorted sets updated as games finish.
+type Service struct {
+	db          *database.DB
+	redisClient *redis.Client
+	cfg         *config.LeaderboardConfig
+	// refresh holds a pending request to re-rank the rating views
+	refresh chan struct{}
+}
+
+func NewService(db *database.DB, redisClient *redis.Client, cfg *config.LeaderboardConfig) *Service {
+	return &Service{
+		db:          db,
+		redisClient: redisClient,
+		cfg:         cfg,
+		refresh:     make(chan struct{}, 1),
+	}
+}
+
+// Start refreshes the rating views once, then whenever results come in and
+// every RefreshInterval.
+func (s *Service) Start() {
+	log.Printf("Starting leaderboard refresh (interval: %s)...", s.cfg.RefreshInterval)
+	s.requestRefresh()
+
+	var scheduled <-chan time.Time
+	if s.cfg.RefreshInterval > 0 {
+		scheduled = time.NewTicker(s.cfg.RefreshInterval).C
+	}
+	go func() {
+		for {
+			select {
+			case <-scheduled:
+			case <-s.refresh:
+				// Fold in results arriving shortly after
+				time.Sleep(s.cfg.RefreshDelay)
+				select {
+				case <-s.refresh:
+				default:
+				}
+			}
+			s.refreshRatings()
+		}
+	}()
+}
+
+// requestRefresh asks for the rating views to be re-ranked soon.
+func (s *Service) requestRefresh() {
+	select {
+	case s.refresh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Service) refreshRatings() {
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+
+	started := time.Now()
+	refreshed, err := s.db.RefreshLeaderboards(ctx)
+	if err != nil {
+		log.Printf("Error refreshing leaderboards: %v", err)
+		return
+	}
+	if refreshed {
+		log.Printf("Refreshed leaderboards in %s", time.Since(started).Round(time.Millisecond))
+	}
+}
+
+// RecordResult schedules a refresh of the rating views for a completed rated
+// game and counts its win toward the weekly leaderboard.
+func (s *Service) RecordResult(game *models.Game) {
+	if game.Status != models.GameStatusCompleted || !game.Settings.Rated {
+		return
+	}
+	s.requestRefresh()
+	if game.WinnerID == nil {
+		return
+	}
+
+	ctx := context.Background()
+	key := fmt.Sprintf(weeklyKey, game.Type, isoWeek(time.Now()))
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.ZIncrBy(ctx, key, 1, game.WinnerID.String())
+	pipe.Expire(ctx, key, weeklyTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Error recording result of game %s on leaderboard: %v", game.ID, err)
+	}
+}
+
+// RatingsChanged schedules a refresh of the rating views after ratings
+// change outside games, such as by decay.
+func (s *Service) RatingsChanged() {
+	s.requestRefresh()
+}
+
+// RemoveUser takes a deleted user off the leaderboards: the rating views
+// from their next refresh, and the weekly ones, including past weeks still
+// kept, right away.
+func (s *Service) RemoveUser(userID uuid.UUID) error {
+	s.requestRefresh()
+
+	ctx := context.Background()
+	iter := s.redisClient.Scan(ctx, 0, fmt.Sprintf(weeklyKey, "*", "*"), 100).Iterator()
+	for iter.Next(ctx) {
+		if err := s.redisClient.ZRem(ctx, iter.Val(), userID.String()).Err(); err != nil {
+			return fmt.Errorf("failed to remove from weekly leaderboard: %w", err)
+		}
+	}
+	return iter.Err()
+}
+
+// Get returns the top limit entries of a view along with the player's own.
+func (s *Service) Get(gameType models.GameType, view string, userID uuid.UUID, limit int) (*Leaderboard, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	board := &Leaderboard{
+		GameType: gameType,
+		View:     view,
+		Entries:  []*models.LeaderboardEntry{},
+	}
+
+	var err error
+	switch view {
+	case ViewGlobal, ViewFriends:
+		var entries []*models.LeaderboardEntry
+		entries, err = s.db.GetLeaderboard(gameType, limit, userID, view == ViewFriends)
+		// The player's own entry is appended when they are outside the top
+		if len(entries) > limit {
+			board.Me = entries[limit]
+			entries = entries[:limit]
+		}
+		board.Entries = append(board.Entries, entries...)
+	case ViewWeekly:
+		board.Week = isoWeek(time.Now())
+		err = s.getWeekly(board, userID, limit)
+	default:
+		return nil, ErrInvalidView
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if board.Me == nil {
+		for _, entry := range board.Entries {
+			if entry.UserID == userID {
+				board.Me = entry
+			}
+		}
+	}
+	return board, nil
+}
+
+func (s *Service) getWeekly(board *Leaderboard, userID uuid.UUID, limit int) error {
+	ctx := context.Background()
+	key := fmt.Sprintf(weeklyKey, board.GameType, board.Week)
+
+	top, err := s.redisClient.ZRevRangeWithScores(ctx, key, 0, int64(limit-1)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get weekly leaderboard: %w", err)
+	}
+
+	type ranked struct {
+		userID uuid.UUID
+		rank   int
+		wins   int
+	}
+	standings := make([]ranked, 0, len(top)+1)
+	inTop := false
+	for i, z := range top {
+		memberID, err := uuid.Parse(z.Member)
+		if err != nil {
+			continue
+		}
+		inTop = inTop || memberID == userID
+		standings = append(standings, ranked{userID: memberID, rank: i + 1, wins: int(z.Score)})
+	}
+
+	if !inTop {
+		rank, err := s.redisClient.ZRevRank(ctx, key, userID.String()).Result()
+		if err != nil && err != redis.Nil {
+			return fmt.Errorf("failed to get weekly rank: %w", err)
+		}
+		if err == nil {
+			wins, err := s.redisClient.ZScore(ctx, key, userID.String()).Result()
+			if err != nil {
+				return fmt.Errorf("failed to get weekly wins: %w", err)
+			}
+			standings = append(standings, ranked{userID: userID, rank: int(rank) + 1, wins: int(wins)})
+		}
+	}
+
+	userIDs := make([]uuid.UUID, len(standings))
+	for i, standing := range standings {
+		userIDs[i] = standing.userID
+	}
+	users, err := s.db.GetLeaderboardUsers(userIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get leaderboard users: %w", err)
+	}
+
+	for i, standing := range standings {
+		entry, ok := users[standing.userID]
+		if !ok {
+			continue // deleted since they won
+		}
+		entry.Rank = standing.rank
+		entry.WeeklyWins = standing.wins
+		if i < len(top) {
+			board.Entries = append(board.Entries, entry)
+		} else {
+			board.Me = entry
+		}
+	}
+	return nil
+}
+
+func isoWeek(t time.Time) string {
+	year, week := t.UTC().ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
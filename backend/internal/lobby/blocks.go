@@ -0,0 +1,79 @@
+package lobby
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	matchmakingBlocksKey = "matchmaking:blocks:%s" // user ID
+	// blockCacheTTL bounds how long a new block can take to affect matching
+	blockCacheTTL = 5 * time.Minute
+	// blockCacheLoaded marks a cached set as loaded, so users who block no
+	// one are not looked up again
+	blockCacheLoaded = "loaded"
+)
+
+// blockedUsers returns the users that userID must not be matched with: those
+// they blocked and those who blocked them. Results are cached in Redis.
+func (m *MatchmakingService) blockedUsers(userID string) (map[string]bool, error) {
+	ctx := context.Background()
+	key := fmt.Sprintf(matchmakingBlocksKey, userID)
+
+	members, err := m.redisClient.SMembers(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(members) == 0 {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return nil, err
+		}
+		blocked, err := m.db.GetBlockRelations(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load block list: %w", err)
+		}
+
+		members = []string{blockCacheLoaded}
+		for _, blockedID := range blocked {
+			members = append(members, blockedID.String())
+		}
+
+		args := make([]interface{}, len(members))
+		for i, member := range members {
+			args[i] = member
+		}
+		pipe := m.redisClient.TxPipeline()
+		pipe.SAdd(ctx, key, args...)
+		pipe.Expire(ctx, key, blockCacheTTL)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	blocked := make(map[string]bool, len(members))
+	for _, member := range members {
+		if member != blockCacheLoaded {
+			blocked[member] = true
+		}
+	}
+	return blocked, nil
+}
+
+// RefreshBlocks drops the cached block lists of two users after one blocked
+// or unblocked the other, so matching sees the change right away.
+func (m *MatchmakingService) RefreshBlocks(userID, otherID uuid.UUID) {
+	ctx := context.Background()
+	err := m.redisClient.Del(ctx,
+		fmt.Sprintf(matchmakingBlocksKey, userID),
+		fmt.Sprintf(matchmakingBlocksKey, otherID),
+	).Err()
+	if err != nil {
+		log.Printf("Error refreshing block lists of %s and %s: %v", userID, otherID, err)
+	}
+}
@@ -0,0 +1,256 @@
+package lobby
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+var (
+	ErrChallengeNotFound   = errors.New("challenge_not_found")
+	ErrCannotChallengeSelf = errors.New("cannot_challenge_self")
+	ErrChallengeBlocked    = errors.New("challenge_blocked")
+	ErrChallengePending    = errors.New("challenge_pending")
+)
+
+// Challenge is an invitation from one player to another to start a game.
+type Challenge struct {
+	ID           uuid.UUID           `json:"id"`
+	ChallengerID uuid.UUID           `json:"challenger_id"`
+	TargetID     uuid.UUID           `json:"target_id"`
+	GameType     models.GameType     `json:"game_type"`
+	Settings     models.GameSettings `json:"settings"`
+	CreatedAt    time.Time           `json:"created_at"`
+	ExpiresAt    time.Time           `json:"expires_at"`
+}
+
+// ChallengeEventData reports how a challenge was resolved. GameID is set
+// once it is accepted.
+type ChallengeEventData struct {
+	Challenge *Challenge `json:"challenge"`
+	GameID    *uuid.UUID `json:"game_id,omitempty"`
+}
+
+const (
+	challengeKey         = "matchmaking:challenge:%s"           // challenge ID
+	challengePairKey     = "matchmaking:challenge:pair:%s:%s"   // challenger ID, target ID
+	challengeIncomingKey = "matchmaking:challenges:incoming:%s" // target ID
+	// challengeExpiryKey orders pending challenges by expiry for the sweep
+	challengeExpiryKey = "matchmaking:challenges"
+	challengeTTL       = time.Minute
+)
+
+// CreateChallenge sends a challenge to another player. A player can have one
+// pending challenge to the same opponent at a time.
+func (m *MatchmakingService) CreateChallenge(challengerID, targetID uuid.UUID, gameType models.GameType, settings models.GameSettings) (*Challenge, error) {
+	if challengerID == targetID {
+		return nil, ErrCannotChallengeSelf
+	}
+	if !m.registry.IsEnabled(gameType) {
+		return nil, ErrGameTypeDisabled
+	}
+
+	blocked, err := m.db.IsBlocked(challengerID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check block list: %w", err)
+	}
+	if blocked {
+		return nil, ErrChallengeBlocked
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	challenge := &Challenge{
+		ID:           uuid.New(),
+		ChallengerID: challengerID,
+		TargetID:     targetID,
+		GameType:     gameType,
+		Settings:     settings,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(challengeTTL),
+	}
+
+	pairKey := fmt.Sprintf(challengePairKey, challengerID, targetID)
+	created, err := m.redisClient.SetNX(ctx, pairKey, challenge.ID.String(), challengeTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create challenge: %w", err)
+	}
+	if !created {
+		return nil, ErrChallengePending
+	}
+
+	challengeData, err := json.Marshal(challenge)
+	if err != nil {
+		m.redisClient.Del(ctx, pairKey)
+		return nil, fmt.Errorf("failed to marshal challenge: %w", err)
+	}
+
+	// The record outlives its expiry so the sweep can still report it
+	_, err = m.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, fmt.Sprintf(challengeKey, challenge.ID), challengeData, challengeTTL+requestExpiryGrace)
+		pipe.ZAdd(ctx, challengeExpiryKey, redis.Z{
+			Score:  float64(challenge.ExpiresAt.Unix()),
+			Member: challenge.ID.String(),
+		})
+		pipe.SAdd(ctx, fmt.Sprintf(challengeIncomingKey, targetID), challenge.ID.String())
+		return nil
+	})
+	if err != nil {
+		m.redisClient.Del(ctx, pairKey)
+		return nil, fmt.Errorf("failed to store challenge: %w", err)
+	}
+
+	m.notify(targetID, EventChallenge, challenge)
+
+	log.Printf("User %s challenged %s to %s", challengerID, targetID, gameType)
+	return challenge, nil
+}
+
+// AcceptChallenge creates the game for a challenge sent to the user.
+func (m *MatchmakingService) AcceptChallenge(userID, challengeID uuid.UUID) (*models.Game, error) {
+	challenge, err := m.claimChallenge(userID, challengeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The challenger takes the first seat, as in a lobby game they created
+	game, err := m.createMatch(challenge.GameType, challenge.Settings,
+		&MatchmakingRequest{UserID: challenge.ChallengerID, GameType: challenge.GameType},
+		&MatchmakingRequest{UserID: challenge.TargetID, GameType: challenge.GameType},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create challenge game: %w", err)
+	}
+
+	m.notify(challenge.ChallengerID, EventChallengeAccepted, ChallengeEventData{
+		Challenge: challenge,
+		GameID:    &game.ID,
+	})
+	return game, nil
+}
+
+func (m *MatchmakingService) DeclineChallenge(userID, challengeID uuid.UUID) error {
+	challenge, err := m.claimChallenge(userID, challengeID)
+	if err != nil {
+		return err
+	}
+
+	m.notify(challenge.ChallengerID, EventChallengeDeclined, ChallengeEventData{Challenge: challenge})
+	return nil
+}
+
+// GetIncomingChallenges returns the pending challenges sent to the user.
+func (m *MatchmakingService) GetIncomingChallenges(userID uuid.UUID) ([]*Challenge, error) {
+	ctx := context.Background()
+	incomingKey := fmt.Sprintf(challengeIncomingKey, userID)
+
+	challengeIDs, err := m.redisClient.SMembers(ctx, incomingKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get challenges: %w", err)
+	}
+
+	challenges := []*Challenge{}
+	for _, challengeID := range challengeIDs {
+		challenge, err := m.getChallenge(challengeID)
+		if err != nil || time.Now().After(challenge.ExpiresAt) {
+			continue
+		}
+		challenges = append(challenges, challenge)
+	}
+	return challenges, nil
+}
+
+// claimChallenge resolves a pending challenge sent to the user. Removing it
+// from the expiry set is atomic, so a challenge is accepted, declined or
+// expired exactly once.
+func (m *MatchmakingService) claimChallenge(userID, challengeID uuid.UUID) (*Challenge, error) {
+	ctx := context.Background()
+
+	challenge, err := m.getChallenge(challengeID.String())
+	if err != nil {
+		return nil, err
+	}
+	if challenge.TargetID != userID || time.Now().After(challenge.ExpiresAt) {
+		return nil, ErrChallengeNotFound
+	}
+
+	removed, err := m.redisClient.ZRem(ctx, challengeExpiryKey, challengeID.String()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve challenge: %w", err)
+	}
+	if removed == 0 {
+		return nil, ErrChallengeNotFound
+	}
+
+	m.deleteChallenge(challenge)
+	return challenge, nil
+}
+
+// expireChallenges tells challengers about challenges that timed out.
+func (m *MatchmakingService) expireChallenges() {
+	ctx := context.Background()
+
+	challengeIDs, err := m.redisClient.ZRangeByScore(ctx, challengeExpiryKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().Unix(), 10),
+	}).Result()
+	if err != nil {
+		log.Printf("Error getting expired challenges: %v", err)
+		return
+	}
+
+	for _, challengeID := range challengeIDs {
+		removed, err := m.redisClient.ZRem(ctx, challengeExpiryKey, challengeID).Result()
+		if err != nil || removed == 0 {
+			continue // resolved by someone else
+		}
+
+		challenge, err := m.getChallenge(challengeID)
+		if err != nil {
+			continue
+		}
+		m.deleteChallenge(challenge)
+
+		event := ChallengeEventData{Challenge: challenge}
+		m.notify(challenge.ChallengerID, EventChallengeExpired, event)
+		m.notify(challenge.TargetID, EventChallengeExpired, event)
+	}
+}
+
+func (m *MatchmakingService) getChallenge(challengeID string) (*Challenge, error) {
+	ctx := context.Background()
+
+	challengeData, err := m.redisClient.Get(ctx, fmt.Sprintf(challengeKey, challengeID)).Result()
+	if err == redis.Nil {
+		return nil, ErrChallengeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get challenge: %w", err)
+	}
+
+	var challenge Challenge
+	if err := json.Unmarshal([]byte(challengeData), &challenge); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal challenge: %w", err)
+	}
+	return &challenge, nil
+}
+
+func (m *MatchmakingService) deleteChallenge(challenge *Challenge) {
+	ctx := context.Background()
+
+	pipe := m.redisClient.TxPipeline()
+	pipe.Del(ctx, fmt.Sprintf(challengeKey, challenge.ID))
+	pipe.Del(ctx, fmt.Sprintf(challengePairKey, challenge.ChallengerID, challenge.TargetID))
+	pipe.SRem(ctx, fmt.Sprintf(challengeIncomingKey, challenge.TargetID), challenge.ID.String())
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Error deleting challenge %s: %v", challenge.ID, err)
+	}
+}
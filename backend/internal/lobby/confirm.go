@@ -0,0 +1,280 @@
+package lobby
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+var ErrMatchConfirmationNotFound = errors.New("match_confirmation_not_found")
+
+// MatchConfirmation is a pairing from the queue waiting for both players to
+// accept before its game is created.
+type MatchConfirmation struct {
+	ID       uuid.UUID             `json:"id"`
+	GameType models.GameType       `json:"game_type"`
+	Settings models.GameSettings   `json:"settings"`
+	Players  []*MatchmakingRequest `json:"players"`
+	// ExpiresAt is when players who have not accepted are dropped
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// MatchConfirmData asks a player to accept a match.
+type MatchConfirmData struct {
+	ConfirmationID uuid.UUID           `json:"confirmation_id"`
+	GameType       models.GameType     `json:"game_type"`
+	Settings       models.GameSettings `json:"settings"`
+	ExpiresAt      time.Time           `json:"expires_at"`
+}
+
+// MatchCancelledData tells a player a proposed match fell through, and
+// whether they are back in the queue.
+type MatchCancelledData struct {
+	ConfirmationID uuid.UUID `json:"confirmation_id"`
+	Requeued       bool      `json:"requeued"`
+}
+
+const (
+	matchConfirmKey         = "matchmaking:confirm:%s"          // confirmation ID
+	matchConfirmAcceptedKey = "matchmaking:confirm:%s:accepted" // confirmation ID
+	// matchConfirmExpiryKey orders pending confirmations by expiry for the
+	// sweep
+	matchConfirmExpiryKey = "matchmaking:confirms"
+)
+
+// proposeMatch asks both players to accept a pairing. Their requests have
+// already been claimed, so they are out of the queue until it resolves.
+func (m *MatchmakingService) proposeMatch(gameType models.GameType, settings models.GameSettings, player1, player2 *MatchmakingRequest) error {
+	ctx := context.Background()
+	timeout := m.cfg.MatchConfirmTimeout
+
+	confirmation := &MatchConfirmation{
+		ID:        uuid.New(),
+		GameType:  gameType,
+		Settings:  settings,
+		Players:   []*MatchmakingRequest{player1, player2},
+		ExpiresAt: time.Now().Add(timeout),
+	}
+
+	confirmationData, err := json.Marshal(confirmation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal match confirmation: %w", err)
+	}
+
+	// The record outlives its expiry so the sweep can still requeue players
+	_, err = m.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, fmt.Sprintf(matchConfirmKey, confirmation.ID), confirmationData, timeout+requestExpiryGrace)
+		pipe.ZAdd(ctx, matchConfirmExpiryKey, redis.Z{
+			Score:  float64(confirmation.ExpiresAt.Unix()),
+			Member: confirmation.ID.String(),
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store match confirmation: %w", err)
+	}
+
+	event := MatchConfirmData{
+		ConfirmationID: confirmation.ID,
+		GameType:       gameType,
+		Settings:       settings,
+		ExpiresAt:      confirmation.ExpiresAt,
+	}
+	for _, player := range confirmation.Players {
+		m.notify(player.UserID, EventMatchConfirm, event)
+	}
+	return nil
+}
+
+// AcceptMatch records a player's acceptance. The game is created once every
+// player has accepted.
+func (m *MatchmakingService) AcceptMatch(userID, confirmationID uuid.UUID) error {
+	ctx := context.Background()
+
+	confirmation, err := m.getPendingConfirmation(userID, confirmationID)
+	if err != nil {
+		return err
+	}
+
+	acceptedKey := fmt.Sprintf(matchConfirmAcceptedKey, confirmationID)
+	var accepted *redis.IntCmd
+	_, err = m.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, acceptedKey, userID.String())
+		pipe.Expire(ctx, acceptedKey, m.cfg.MatchConfirmTimeout+requestExpiryGrace)
+		accepted = pipe.SCard(ctx, acceptedKey)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to accept match: %w", err)
+	}
+	if accepted.Val() < int64(len(confirmation.Players)) {
+		return nil
+	}
+
+	claimed, err := m.claimConfirmation(confirmation)
+	if err != nil || !claimed {
+		return err
+	}
+
+	player1, player2 := confirmation.Players[0], confirmation.Players[1]
+	if _, err := m.createMatch(confirmation.GameType, confirmation.Settings, player1, player2); err != nil {
+		log.Printf("Failed to create confirmed match: %v", err)
+		m.requeueAfterFailedMatch(player1, player2)
+		return nil
+	}
+	m.recordMatch(confirmation.GameType, player1, player2)
+	return nil
+}
+
+// DeclineMatch takes the player out of matchmaking. Their opponent goes back
+// to the queue.
+func (m *MatchmakingService) DeclineMatch(userID, confirmationID uuid.UUID) error {
+	confirmation, err := m.getPendingConfirmation(userID, confirmationID)
+	if err != nil {
+		return err
+	}
+
+	claimed, err := m.claimConfirmation(confirmation)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrMatchConfirmationNotFound
+	}
+
+	m.cancelConfirmation(confirmation, func(playerID uuid.UUID) bool {
+		return playerID != userID
+	})
+	return nil
+}
+
+// expireConfirmations requeues players who accepted a match their opponent
+// never answered, and drops those who did not answer.
+func (m *MatchmakingService) expireConfirmations() {
+	ctx := context.Background()
+
+	confirmationIDs, err := m.redisClient.ZRangeByScore(ctx, matchConfirmExpiryKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().Unix(), 10),
+	}).Result()
+	if err != nil {
+		log.Printf("Error getting expired match confirmations: %v", err)
+		return
+	}
+
+	for _, confirmationID := range confirmationIDs {
+		confirmation, err := m.getConfirmation(confirmationID)
+		if err != nil {
+			m.redisClient.ZRem(ctx, matchConfirmExpiryKey, confirmationID)
+			continue
+		}
+
+		accepted, err := m.redisClient.SMembers(ctx, fmt.Sprintf(matchConfirmAcceptedKey, confirmationID)).Result()
+		if err != nil {
+			log.Printf("Error getting match acceptances: %v", err)
+			continue
+		}
+
+		claimed, err := m.claimConfirmation(confirmation)
+		if err != nil || !claimed {
+			continue // resolved by someone else
+		}
+
+		acceptedBy := make(map[uuid.UUID]bool, len(accepted))
+		for _, id := range accepted {
+			if playerID, err := uuid.Parse(id); err == nil {
+				acceptedBy[playerID] = true
+			}
+		}
+		m.cancelConfirmation(confirmation, func(playerID uuid.UUID) bool {
+			return acceptedBy[playerID]
+		})
+	}
+}
+
+// cancelConfirmation requeues the players for whom requeue returns true and
+// tells everyone the match is off. The pair is backed off either way.
+func (m *MatchmakingService) cancelConfirmation(confirmation *MatchConfirmation, requeue func(uuid.UUID) bool) {
+	player1, player2 := confirmation.Players[0], confirmation.Players[1]
+	m.backOffPair(player1.UserID, player2.UserID)
+
+	for _, player := range confirmation.Players {
+		requeued := requeue(player.UserID)
+		m.notify(player.UserID, EventMatchCancelled, MatchCancelledData{
+			ConfirmationID: confirmation.ID,
+			Requeued:       requeued,
+		})
+		if requeued {
+			m.requeue(player)
+		} else {
+			m.recordAbandoned(player, abandonReasonDeclined)
+		}
+	}
+}
+
+func (m *MatchmakingService) getPendingConfirmation(userID, confirmationID uuid.UUID) (*MatchConfirmation, error) {
+	confirmation, err := m.getConfirmation(confirmationID.String())
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(confirmation.ExpiresAt) {
+		return nil, ErrMatchConfirmationNotFound
+	}
+	for _, player := range confirmation.Players {
+		if player.UserID == userID {
+			return confirmation, nil
+		}
+	}
+	return nil, ErrMatchConfirmationNotFound
+}
+
+func (m *MatchmakingService) getConfirmation(confirmationID string) (*MatchConfirmation, error) {
+	ctx := context.Background()
+
+	confirmationData, err := m.redisClient.Get(ctx, fmt.Sprintf(matchConfirmKey, confirmationID)).Result()
+	if err == redis.Nil {
+		return nil, ErrMatchConfirmationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get match confirmation: %w", err)
+	}
+
+	var confirmation MatchConfirmation
+	if err := json.Unmarshal([]byte(confirmationData), &confirmation); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal match confirmation: %w", err)
+	}
+	if len(confirmation.Players) != 2 {
+		return nil, ErrMatchConfirmationNotFound
+	}
+	return &confirmation, nil
+}
+
+// claimConfirmation resolves a confirmation. Removing it from the expiry set
+// is atomic, so a match is created, declined or expired exactly once.
+func (m *MatchmakingService) claimConfirmation(confirmation *MatchConfirmation) (bool, error) {
+	ctx := context.Background()
+
+	removed, err := m.redisClient.ZRem(ctx, matchConfirmExpiryKey, confirmation.ID.String()).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve match confirmation: %w", err)
+	}
+	if removed == 0 {
+		return false, nil
+	}
+
+	pipe := m.redisClient.TxPipeline()
+	pipe.Del(ctx, fmt.Sprintf(matchConfirmKey, confirmation.ID))
+	pipe.Del(ctx, fmt.Sprintf(matchConfirmAcceptedKey, confirmation.ID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Error deleting match confirmation %s: %v", confirmation.ID, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,163 @@
+package lobby
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+var (
+	ErrGameInviteNotFound = errors.New("game_invite_not_found")
+	ErrCannotInviteSelf   = errors.New("cannot_invite_self")
+)
+
+// GameInvite asks a player to take the open seat of a waiting game.
+type GameInvite struct {
+	ID        uuid.UUID       `json:"id"`
+	GameID    uuid.UUID       `json:"game_id"`
+	GameType  models.GameType `json:"game_type"`
+	InviterID uuid.UUID       `json:"inviter_id"`
+	InviteeID uuid.UUID       `json:"invitee_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+const (
+	gameInviteKey         = "lobby:invite:%s"           // invite ID
+	gameInviteIncomingKey = "lobby:invites:incoming:%s" // invitee ID
+	gameInviteTTL         = 15 * time.Minute
+)
+
+// SetNotifier must be called before invites are sent. Without it players are
+// not told about invites or their answers.
+func (s *PrivateLobbyService) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
+// CreateInvite invites a player to the game. The caller checks that the
+// inviter may invite to it and that its seat is open.
+func (s *PrivateLobbyService) CreateInvite(game *models.Game, inviterID, inviteeID uuid.UUID) (*GameInvite, error) {
+	if inviterID == inviteeID {
+		return nil, ErrCannotInviteSelf
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	invite := &GameInvite{
+		ID:        uuid.New(),
+		GameID:    game.ID,
+		GameType:  game.Type,
+		InviterID: inviterID,
+		InviteeID: inviteeID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(gameInviteTTL),
+	}
+
+	inviteData, err := json.Marshal(invite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal invite: %w", err)
+	}
+
+	incomingKey := fmt.Sprintf(gameInviteIncomingKey, inviteeID)
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, fmt.Sprintf(gameInviteKey, invite.ID), inviteData, gameInviteTTL)
+		pipe.SAdd(ctx, incomingKey, invite.ID.String())
+		pipe.Expire(ctx, incomingKey, gameInviteTTL)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store invite: %w", err)
+	}
+
+	s.notify(inviteeID, EventGameInvite, invite)
+	return invite, nil
+}
+
+// GetInvites returns the pending invites sent to the user.
+func (s *PrivateLobbyService) GetInvites(userID uuid.UUID) ([]*GameInvite, error) {
+	ctx := context.Background()
+	incomingKey := fmt.Sprintf(gameInviteIncomingKey, userID)
+
+	inviteIDs, err := s.redisClient.SMembers(ctx, incomingKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invites: %w", err)
+	}
+
+	invites := []*GameInvite{}
+	for _, inviteID := range inviteIDs {
+		inviteData, err := s.redisClient.Get(ctx, fmt.Sprintf(gameInviteKey, inviteID)).Result()
+		if err == redis.Nil {
+			s.redisClient.SRem(ctx, incomingKey, inviteID)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get invite: %w", err)
+		}
+
+		var invite GameInvite
+		if err := json.Unmarshal([]byte(inviteData), &invite); err != nil {
+			continue
+		}
+		invites = append(invites, &invite)
+	}
+	return invites, nil
+}
+
+// ClaimInvite resolves a pending invite sent to the user. Deleting it is
+// atomic, so an invite is answered exactly once.
+func (s *PrivateLobbyService) ClaimInvite(userID, inviteID uuid.UUID) (*GameInvite, error) {
+	ctx := context.Background()
+	key := fmt.Sprintf(gameInviteKey, inviteID)
+
+	inviteData, err := s.redisClient.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return nil, ErrGameInviteNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+
+	var invite GameInvite
+	if err := json.Unmarshal([]byte(inviteData), &invite); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal invite: %w", err)
+	}
+	if invite.InviteeID != userID {
+		return nil, ErrGameInviteNotFound
+	}
+
+	deleted, err := s.redisClient.Del(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve invite: %w", err)
+	}
+	if deleted == 0 {
+		return nil, ErrGameInviteNotFound
+	}
+	s.redisClient.SRem(ctx, fmt.Sprintf(gameInviteIncomingKey, userID), inviteID.String())
+
+	return &invite, nil
+}
+
+// AnswerInvite tells the inviter whether their invite was accepted.
+func (s *PrivateLobbyService) AnswerInvite(invite *GameInvite, accepted bool) {
+	event := EventGameInviteDeclined
+	if accepted {
+		event = EventGameInviteAccepted
+	}
+	s.notify(invite.InviterID, event, invite)
+}
+
+func (s *PrivateLobbyService) notify(userID uuid.UUID, event string, data interface{}) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.NotifyUser(userID, event, data); err != nil {
+		log.Printf("Error notifying user %s of %s: %v", userID, event, err)
+	}
+}
@@ -3,8 +3,11 @@ package lobby
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,40 +15,85 @@ import (
 	"github.com/szaher/vibeboard/backend/internal/database"
 	"github.com/szaher/vibeboard/backend/internal/game"
 	"github.com/szaher/vibeboard/backend/internal/models"
+	"github.com/szaher/vibeboard/backend/pkg/config"
 )
 
+var ErrAlreadyQueued = errors.New("already_in_queue")
+
 type MatchmakingService struct {
 	db          *database.DB
 	redisClient *redis.Client
 	registry    *game.EngineRegistry
+	starters    *game.StarterSelector
+	cfg         *config.LobbyConfig
+	notifier    Notifier
+	presence    PresenceChecker
+	series      *game.SeriesService
+}
+
+// SetSeries must be called before Start. Without it games asking for a
+// series are played as single games.
+func (m *MatchmakingService) SetSeries(series *game.SeriesService) {
+	m.series = series
 }
 
 type MatchmakingRequest struct {
 	UserID   uuid.UUID       `json:"user_id"`
-	GameType models.GameType `json:"game_type"`
-	Rating   int             `json:"rating"`
-	JoinedAt time.Time       `json:"joined_at"`
+	GameType models.GameType `json:"game_type,omitempty"`
+	// GameTypes is set for quick play, where any of several game types will do
+	GameTypes   []models.GameType `json:"game_types,omitempty"`
+	Rating      int               `json:"rating"`
+	Preferences MatchPreferences  `json:"preferences"`
+	JoinedAt    time.Time         `json:"joined_at"`
+}
+
+// QueueEventData describes a player's place in matchmaking.
+type QueueEventData struct {
+	GameTypes   []models.GameType `json:"game_types"`
+	Tolerance   int               `json:"tolerance,omitempty"`
+	WaitSeconds int               `json:"wait_seconds"`
 }
 
 type MatchResult struct {
-	GameID    uuid.UUID       `json:"game_id"`
-	Player1ID uuid.UUID       `json:"player1_id"`
-	Player2ID uuid.UUID       `json:"player2_id"`
-	GameType  models.GameType `json:"game_type"`
+	GameID    uuid.UUID           `json:"game_id"`
+	Player1ID uuid.UUID           `json:"player1_id"`
+	Player2ID uuid.UUID           `json:"player2_id"`
+	GameType  models.GameType     `json:"game_type"`
+	Settings  models.GameSettings `json:"settings"`
+	StarterID uuid.UUID           `json:"starter_id"`
+	// Player1Tier and Player2Tier are the players' ranked tiers
+	Player1Tier models.RankedTier `json:"player1_tier"`
+	Player2Tier models.RankedTier `json:"player2_tier"`
 }
 
 const (
-	matchmakingQueueKey = "matchmaking:queue:%s" // game type
-	matchmakingTimeout  = 5 * time.Minute
-	ratingTolerance     = 100 // Initial rating tolerance
-	maxRatingTolerance  = 500 // Maximum rating tolerance after waiting
+	matchmakingQueueKey   = "matchmaking:queue:%s:%d" // game type, rating bucket
+	matchmakingBucketsKey = "matchmaking:buckets:%s"  // game type
+	matchmakingRequestKey = "matchmaking:request:%s"  // user ID
+	// matchmakingToleranceKey holds the tolerance last reported to the user
+	matchmakingToleranceKey = "matchmaking:tolerance:%s" // user ID
+	matchmakingTimeout      = 5 * time.Minute
+	// requestExpiryGrace keeps expired requests around until cleanup has
+	// told the player
+	requestExpiryGrace = time.Minute
+	// matchmakingBackoffKey stops a pair whose match fell through from being
+	// paired again straight away
+	matchmakingBackoffKey = "matchmaking:backoff:%s:%s" // lower user ID, higher user ID
+	rematchBackoff        = 30 * time.Second
+	ratingTolerance       = 100 // Initial rating tolerance
+	maxRatingTolerance    = 500 // Maximum rating tolerance after waiting
+	ratingBucketSize      = 100 // Width of the rating band each queue covers
+	// toleranceIncreasePerMinute widens the tolerance the longer a player waits
+	toleranceIncreasePerMinute = 20
 )
 
-func NewMatchmakingService(db *database.DB, redisClient *redis.Client, registry *game.EngineRegistry) *MatchmakingService {
+func NewMatchmakingService(db *database.DB, redisClient *redis.Client, registry *game.EngineRegistry, starters *game.StarterSelector, cfg *config.LobbyConfig) *MatchmakingService {
 	return &MatchmakingService{
 		db:          db,
 		redisClient: redisClient,
 		registry:    registry,
+		starters:    starters,
+		cfg:         cfg,
 	}
 }
 
@@ -57,6 +105,8 @@ func (m *MatchmakingService) Start() {
 	go func() {
 		for range ticker.C {
 			m.processMatchmaking()
+			m.expireChallenges()
+			m.expireConfirmations()
 		}
 	}()
 
@@ -69,21 +119,69 @@ func (m *MatchmakingService) Start() {
 	}()
 }
 
-func (m *MatchmakingService) JoinQueue(userID uuid.UUID, gameType models.GameType, rating int) error {
-	ctx := context.Background()
-	queueKey := fmt.Sprintf(matchmakingQueueKey, gameType)
+func (m *MatchmakingService) JoinQueue(userID uuid.UUID, gameType models.GameType, rating int, preferences MatchPreferences) error {
+	request := &MatchmakingRequest{
+		UserID:      userID,
+		GameType:    gameType,
+		Rating:      rating,
+		Preferences: preferences,
+		JoinedAt:    time.Now(),
+	}
+	if err := m.joinQueues(request); err != nil {
+		return err
+	}
+	m.recordJoin(request)
+	return nil
+}
 
-	// Check if user is already in queue
-	exists, err := m.redisClient.ZScore(ctx, queueKey, userID.String()).Result()
-	if err == nil && exists != 0 {
-		return fmt.Errorf("user already in matchmaking queue")
+// JoinQuickPlay queues the user for several game types at once. The first
+// match found in any of them wins and the other entries are withdrawn.
+func (m *MatchmakingService) JoinQuickPlay(userID uuid.UUID, gameTypes []models.GameType, rating int, preferences MatchPreferences) error {
+	unique := make([]models.GameType, 0, len(gameTypes))
+	seen := make(map[models.GameType]bool)
+	for _, gameType := range gameTypes {
+		if !seen[gameType] {
+			seen[gameType] = true
+			unique = append(unique, gameType)
+		}
 	}
+	gameTypes = unique
 
-	request := MatchmakingRequest{
-		UserID:   userID,
-		GameType: gameType,
-		Rating:   rating,
-		JoinedAt: time.Now(),
+	if len(gameTypes) == 1 {
+		return m.JoinQueue(userID, gameTypes[0], rating, preferences)
+	}
+
+	request := &MatchmakingRequest{
+		UserID:      userID,
+		GameTypes:   gameTypes,
+		Rating:      rating,
+		Preferences: preferences,
+		JoinedAt:    time.Now(),
+	}
+	if err := m.joinQueues(request); err != nil {
+		return err
+	}
+	m.recordJoin(request)
+	return nil
+}
+
+func (m *MatchmakingService) joinQueues(request *MatchmakingRequest) error {
+	gameTypes := request.queuedTypes()
+	if len(gameTypes) == 0 {
+		return fmt.Errorf("no game type requested")
+	}
+	for _, gameType := range gameTypes {
+		if !m.registry.IsEnabled(gameType) {
+			return fmt.Errorf("game type %s is not available", gameType)
+		}
+	}
+
+	ctx := context.Background()
+	userID := request.UserID
+
+	// A user has a single request, so they can only wait in one queue
+	if _, err := m.getMatchmakingRequest(userID.String()); err == nil {
+		return ErrAlreadyQueued
 	}
 
 	requestData, err := json.Marshal(request)
@@ -91,51 +189,88 @@ func (m *MatchmakingService) JoinQueue(userID uuid.UUID, gameType models.GameTyp
 		return fmt.Errorf("failed to marshal matchmaking request: %w", err)
 	}
 
-	// Add to sorted set with score as timestamp (for FIFO processing)
-	score := float64(time.Now().Unix())
-	err = m.redisClient.ZAdd(ctx, queueKey, redis.Z{
-		Score:  score,
-		Member: userID.String(),
-	}).Err()
+	// Store request details before queueing so matching never sees an
+	// entry without them
+	requestKey := fmt.Sprintf(matchmakingRequestKey, userID)
+	err = m.redisClient.Set(ctx, requestKey, requestData, matchmakingTimeout+requestExpiryGrace).Err()
 	if err != nil {
-		return fmt.Errorf("failed to add to matchmaking queue: %w", err)
+		return fmt.Errorf("failed to store matchmaking request: %w", err)
 	}
 
-	// Store request details
-	requestKey := fmt.Sprintf("matchmaking:request:%s", userID)
-	err = m.redisClient.Set(ctx, requestKey, requestData, matchmakingTimeout).Err()
+	// Add to each game type's rating bucket with score as timestamp (for FIFO processing)
+	bucket := ratingBucket(request.Rating)
+	score := float64(request.JoinedAt.Unix())
+	_, err = m.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, gameType := range gameTypes {
+			pipe.ZAdd(ctx, queueKeyFor(gameType, bucket), redis.Z{
+				Score:  score,
+				Member: userID.String(),
+			})
+			pipe.SAdd(ctx, fmt.Sprintf(matchmakingBucketsKey, gameType), bucket)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to store matchmaking request: %w", err)
+		m.redisClient.Del(ctx, requestKey)
+		return fmt.Errorf("failed to add to matchmaking queue: %w", err)
 	}
 
-	log.Printf("User %s joined matchmaking queue for %s", userID, gameType)
+	tolerance := m.calculateRatingTolerance(time.Since(request.JoinedAt))
+	m.redisClient.Set(ctx, fmt.Sprintf(matchmakingToleranceKey, userID), tolerance, matchmakingTimeout+requestExpiryGrace)
+	m.notify(userID, EventQueueJoined, QueueEventData{
+		GameTypes:   gameTypes,
+		Tolerance:   tolerance,
+		WaitSeconds: int(time.Since(request.JoinedAt).Seconds()),
+	})
+
+	log.Printf("User %s joined matchmaking queue for %v", userID, gameTypes)
 	return nil
 }
 
 func (m *MatchmakingService) LeaveQueue(userID uuid.UUID, gameType models.GameType) error {
-	ctx := context.Background()
-	queueKey := fmt.Sprintf(matchmakingQueueKey, gameType)
-
-	// Remove from queue
-	err := m.redisClient.ZRem(ctx, queueKey, userID.String()).Err()
+	request, err := m.getMatchmakingRequest(userID.String())
+	if err == redis.Nil {
+		return nil
+	}
 	if err != nil {
+		return fmt.Errorf("failed to get matchmaking request: %w", err)
+	}
+	if !request.queuedFor(gameType) {
+		return nil
+	}
+
+	// Quick play entries are withdrawn from every game type together
+	if err := m.removeRequest(request); err != nil {
 		return fmt.Errorf("failed to remove from matchmaking queue: %w", err)
 	}
+	m.recordAbandoned(request, abandonReasonCancelled)
 
-	// Remove request details
-	requestKey := fmt.Sprintf("matchmaking:request:%s", userID)
-	err = m.redisClient.Del(ctx, requestKey).Err()
+	log.Printf("User %s left matchmaking queue for %s", userID, gameType)
+	return nil
+}
+
+// CancelQueue withdraws the user's request from every queue it is in.
+func (m *MatchmakingService) CancelQueue(userID uuid.UUID) error {
+	request, err := m.getMatchmakingRequest(userID.String())
+	if err == redis.Nil {
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to remove matchmaking request: %w", err)
+		return fmt.Errorf("failed to get matchmaking request: %w", err)
 	}
 
-	log.Printf("User %s left matchmaking queue for %s", userID, gameType)
+	if err := m.removeRequest(request); err != nil {
+		return fmt.Errorf("failed to remove from matchmaking queue: %w", err)
+	}
+	m.recordAbandoned(request, abandonReasonCancelled)
+
+	log.Printf("User %s left matchmaking queue for %v", userID, request.queuedTypes())
 	return nil
 }
 
-func (m *MatchmakingService) GetQueueStatus(userID uuid.UUID, gameType models.GameType) (*MatchmakingRequest, error) {
+func (m *MatchmakingService) GetQueueStatus(userID uuid.UUID) (*MatchmakingRequest, error) {
 	ctx := context.Background()
-	requestKey := fmt.Sprintf("matchmaking:request:%s", userID)
+	requestKey := fmt.Sprintf(matchmakingRequestKey, userID)
 
 	requestData, err := m.redisClient.Get(ctx, requestKey).Result()
 	if err == redis.Nil {
@@ -155,115 +290,331 @@ func (m *MatchmakingService) GetQueueStatus(userID uuid.UUID, gameType models.Ga
 }
 
 func (m *MatchmakingService) processMatchmaking() {
-	ctx := context.Background()
-
-	// Process each game type
-	for _, gameType := range m.registry.GetSupportedTypes() {
-		queueKey := fmt.Sprintf(matchmakingQueueKey, gameType)
-
-		// Get all users in queue (sorted by join time)
-		userIDs, err := m.redisClient.ZRange(ctx, queueKey, 0, -1).Result()
+	// Process each enabled game type
+	for _, gameType := range m.registry.GetEnabledTypes() {
+		queue, err := m.loadQueue(gameType)
 		if err != nil {
 			log.Printf("Error getting matchmaking queue for %s: %v", gameType, err)
 			continue
 		}
 
-		if len(userIDs) < 2 {
+		matchmakingQueueSize.WithLabelValues(string(gameType)).Set(float64(queue.size))
+
+		if queue.size < 2 {
 			continue // Need at least 2 players
 		}
 
 		// Try to match players
-		m.matchPlayers(gameType, userIDs)
+		m.matchPlayers(gameType, queue)
 	}
 }
 
-func (m *MatchmakingService) matchPlayers(gameType models.GameType, userIDs []string) {
+// bucketedQueue is a snapshot of a game type's queues, keyed by rating
+// bucket, each in join order.
+type bucketedQueue struct {
+	buckets map[int][]string
+	size    int
+}
+
+func (m *MatchmakingService) loadQueue(gameType models.GameType) (*bucketedQueue, error) {
 	ctx := context.Background()
 
-	for i := 0; i < len(userIDs)-1; i++ {
-		player1ID := userIDs[i]
-		player1Request, err := m.getMatchmakingRequest(player1ID)
+	buckets, err := m.queueBuckets(gameType)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := &bucketedQueue{buckets: make(map[int][]string, len(buckets))}
+	for _, bucket := range buckets {
+		userIDs, err := m.redisClient.ZRange(ctx, queueKeyFor(gameType, bucket), 0, -1).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(userIDs) > 0 {
+			queue.buckets[bucket] = userIDs
+			queue.size += len(userIDs)
+		}
+	}
+	return queue, nil
+}
+
+func (m *MatchmakingService) queueBuckets(gameType models.GameType) ([]int, error) {
+	ctx := context.Background()
+
+	members, err := m.redisClient.SMembers(ctx, fmt.Sprintf(matchmakingBucketsKey, gameType)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]int, 0, len(members))
+	for _, member := range members {
+		bucket, err := strconv.Atoi(member)
 		if err != nil {
 			continue
 		}
+		buckets = append(buckets, bucket)
+	}
+	sort.Ints(buckets)
+	return buckets, nil
+}
 
-		// Calculate current rating tolerance based on wait time
-		waitTime := time.Since(player1Request.JoinedAt)
-		tolerance := m.calculateRatingTolerance(waitTime)
+// matchPlayers pairs each waiting player with the longest-waiting opponent
+// in the nearest rating buckets. The search widens to further buckets as
+// the player's tolerance grows with wait time.
+func (m *MatchmakingService) matchPlayers(gameType models.GameType, queue *bucketedQueue) {
+	requests := make(map[string]*MatchmakingRequest)
+	getRequest := func(userID string) *MatchmakingRequest {
+		if request, ok := requests[userID]; ok {
+			return request
+		}
+		request, err := m.getMatchmakingRequest(userID)
+		if err != nil {
+			request = nil
+		}
+		requests[userID] = request
+		return request
+	}
 
-		// Find a suitable opponent
-		for j := i + 1; j < len(userIDs); j++ {
-			player2ID := userIDs[j]
-			player2Request, err := m.getMatchmakingRequest(player2ID)
+	blocks := make(map[string]map[string]bool)
+	backoffs := make(map[string]bool)
+	avoidPair := func(userID, otherID string) bool {
+		blocked, ok := blocks[userID]
+		if !ok {
+			var err error
+			blocked, err = m.blockedUsers(userID)
 			if err != nil {
+				// Fail closed: better to wait than to face a blocked player
+				log.Printf("Error getting block list for %s: %v", userID, err)
+				return true
+			}
+			blocks[userID] = blocked
+		}
+		if blocked[otherID] {
+			return true
+		}
+
+		key := backoffKey(userID, otherID)
+		backedOff, ok := backoffs[key]
+		if !ok {
+			backedOff = m.redisClient.Exists(context.Background(), key).Val() > 0
+			backoffs[key] = backedOff
+		}
+		return backedOff
+	}
+
+	buckets := make([]int, 0, len(queue.buckets))
+	for bucket := range queue.buckets {
+		buckets = append(buckets, bucket)
+	}
+	sort.Ints(buckets)
+
+	matched := make(map[string]bool)
+	reported := make(map[string]bool)
+	for _, bucket := range buckets {
+		for _, player1ID := range queue.buckets[bucket] {
+			if matched[player1ID] {
+				continue
+			}
+			player1Request := getRequest(player1ID)
+			if player1Request == nil {
 				continue
 			}
 
-			// Check if ratings are within tolerance
-			ratingDiff := abs(player1Request.Rating - player2Request.Rating)
-			if ratingDiff <= tolerance {
-				// Create match
-				err := m.createMatch(player1Request, player2Request)
-				if err != nil {
-					log.Printf("Failed to create match: %v", err)
-					continue
+			// Calculate current rating tolerance based on wait time
+			waitTime := time.Since(player1Request.JoinedAt)
+			tolerance := m.calculateRatingTolerance(waitTime)
+
+			// Find a suitable opponent, nearest buckets first
+			player2Request := m.findOpponent(queue, bucket, player1Request, tolerance, matched, getRequest, avoidPair)
+			if player2Request == nil {
+				// Quick play players sit in several queues; only report once
+				if !reported[player1ID] {
+					reported[player1ID] = true
+					m.reportSearching(player1Request, tolerance)
+				}
+				continue
+			}
+			settings, _ := negotiateSettings(player1Request.Preferences, player2Request.Preferences)
+
+			// Players who closed the app since queueing would leave a dead
+			// game; drop them and try again next tick
+			if !m.isConnected(player1Request.UserID) {
+				matched[player1ID] = true
+				m.dropDisconnected(player1Request)
+				continue
+			}
+			if !m.isConnected(player2Request.UserID) {
+				matched[player2Request.UserID.String()] = true
+				m.dropDisconnected(player2Request)
+				continue
+			}
+
+			// Take both players out of every queue they are in. This fails
+			// if either was already matched, e.g. for another game type
+			matched[player1ID] = true
+			matched[player2Request.UserID.String()] = true
+			claimed, err := m.claimRequests(player1Request, player2Request)
+			if err != nil {
+				log.Printf("Failed to claim matchmaking requests: %v", err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+
+			if m.cfg.MatchConfirmTimeout > 0 {
+				if err := m.proposeMatch(gameType, settings, player1Request, player2Request); err != nil {
+					log.Printf("Failed to propose match: %v", err)
+					m.requeueAfterFailedMatch(player1Request, player2Request)
 				}
+				continue
+			}
 
-				// Remove both players from queue
-				queueKey := fmt.Sprintf(matchmakingQueueKey, gameType)
-				m.redisClient.ZRem(ctx, queueKey, player1ID, player2ID)
+			// Create match
+			if _, err := m.createMatch(gameType, settings, player1Request, player2Request); err != nil {
+				log.Printf("Failed to create match: %v", err)
+				m.requeueAfterFailedMatch(player1Request, player2Request)
+				continue
+			}
+			m.recordMatch(gameType, player1Request, player2Request)
 
-				// Remove request details
-				m.redisClient.Del(ctx, fmt.Sprintf("matchmaking:request:%s", player1ID))
-				m.redisClient.Del(ctx, fmt.Sprintf("matchmaking:request:%s", player2ID))
+			log.Printf("Created match between %s and %s for %s", player1ID, player2Request.UserID, gameType)
+		}
+	}
+}
 
-				log.Printf("Created match between %s and %s for %s", player1ID, player2ID, gameType)
-				return
+// reportSearching tells a waiting player when their rating tolerance has
+// widened since the last update.
+func (m *MatchmakingService) reportSearching(request *MatchmakingRequest, tolerance int) {
+	ctx := context.Background()
+	key := fmt.Sprintf(matchmakingToleranceKey, request.UserID)
+
+	// Swap atomically so only one instance reports each change
+	previous, err := m.redisClient.SetArgs(ctx, key, tolerance, redis.SetArgs{
+		Get: true,
+		TTL: matchmakingTimeout + requestExpiryGrace,
+	}).Result()
+	if err != nil && err != redis.Nil {
+		return
+	}
+	if previous == strconv.Itoa(tolerance) {
+		return
+	}
+
+	m.notify(request.UserID, EventSearching, QueueEventData{
+		GameTypes:   request.queuedTypes(),
+		Tolerance:   tolerance,
+		WaitSeconds: int(time.Since(request.JoinedAt).Seconds()),
+	})
+}
+
+func (m *MatchmakingService) findOpponent(queue *bucketedQueue, bucket int, player *MatchmakingRequest, tolerance int, matched map[string]bool, getRequest func(string) *MatchmakingRequest, avoidPair func(string, string) bool) *MatchmakingRequest {
+	playerID := player.UserID.String()
+	reach := (tolerance + ratingBucketSize - 1) / ratingBucketSize
+
+	for distance := 0; distance <= reach; distance++ {
+		var best *MatchmakingRequest
+		for _, candidateBucket := range []int{bucket - distance, bucket + distance} {
+			for _, candidateID := range queue.buckets[candidateBucket] {
+				if candidateID == playerID || matched[candidateID] {
+					continue
+				}
+				candidate := getRequest(candidateID)
+				if candidate == nil || abs(player.Rating-candidate.Rating) > tolerance {
+					continue
+				}
+				if _, ok := negotiateSettings(player.Preferences, candidate.Preferences); !ok {
+					continue
+				}
+				// Block relations and backoffs are symmetric, so checking
+				// one side is enough
+				if avoidPair(playerID, candidateID) {
+					continue
+				}
+				// Buckets are in join order, so the first fit is the
+				// longest-waiting one in this bucket
+				if best == nil || candidate.JoinedAt.Before(best.JoinedAt) {
+					best = candidate
+				}
+				break
 			}
+			if distance == 0 {
+				break
+			}
+		}
+		if best != nil {
+			return best
 		}
 	}
+	return nil
 }
 
-func (m *MatchmakingService) createMatch(player1, player2 *MatchmakingRequest) error {
-	// Get game engine
-	engine, err := m.registry.GetEngine(player1.GameType)
+// tierOf returns the player's ranked tier, unranked if it can't be read.
+func (m *MatchmakingService) tierOf(userID uuid.UUID) models.RankedTier {
+	stats, err := m.db.GetUserStats(userID)
 	if err != nil {
-		return fmt.Errorf("failed to get game engine: %w", err)
+		return models.RankedTier{Tier: models.TierUnranked}
 	}
+	return stats.RankedTier
+}
 
-	// Initialize game state
-	initialState, err := engine.Initialize()
+func (m *MatchmakingService) createMatch(gameType models.GameType, settings models.GameSettings, player1, player2 *MatchmakingRequest) (*models.Game, error) {
+	// Get game engine
+	engine, err := m.registry.GetEngine(gameType)
 	if err != nil {
-		return fmt.Errorf("failed to initialize game state: %w", err)
+		return nil, fmt.Errorf("failed to get game engine: %w", err)
+	}
+	if err := game.ApplySettings(engine, &settings); err != nil {
+		return nil, err
 	}
 
 	// Create game record
+	now := time.Now()
 	game := &models.Game{
-		ID:          uuid.New(),
-		Type:        player1.GameType,
-		Status:      models.GameStatusInProgress,
-		Player1ID:   player1.UserID,
-		Player2ID:   &player2.UserID,
-		CurrentTurn: &player1.UserID, // Player 1 starts
-		GameState:   initialState,
-		StartedAt:   &[]time.Time{time.Now()}[0],
+		ID:           uuid.New(),
+		Type:         gameType,
+		Status:       models.GameStatusInProgress,
+		StartedAt:    &now,
+		Settings:     settings,
+		MoveDeadline: settings.MoveDeadline(now),
+	}
+	game.SetPlayers(player1.UserID, player2.UserID)
+
+	// Initialize game state
+	if err := m.starters.InitializeGame(engine, game); err != nil {
+		return nil, fmt.Errorf("failed to initialize game state: %w", err)
+	}
+	if m.series != nil {
+		if err := m.series.Begin(game); err != nil {
+			return nil, fmt.Errorf("failed to start series: %w", err)
+		}
 	}
 
 	// Save game to database
 	err = m.db.CreateGame(game)
 	if err != nil {
-		return fmt.Errorf("failed to create game: %w", err)
+		return nil, fmt.Errorf("failed to create game: %w", err)
 	}
 
-	// TODO: Notify players via WebSocket that match was found
-	// This would involve sending a message to both players with game details
+	result := MatchResult{
+		GameID:      game.ID,
+		Player1ID:   player1.UserID,
+		Player2ID:   player2.UserID,
+		GameType:    game.Type,
+		Settings:    game.Settings,
+		StarterID:   *game.StarterID,
+		Player1Tier: m.tierOf(player1.UserID),
+		Player2Tier: m.tierOf(player2.UserID),
+	}
+	m.notify(player1.UserID, EventMatchFound, result)
+	m.notify(player2.UserID, EventMatchFound, result)
 
-	return nil
+	return game, nil
 }
 
 func (m *MatchmakingService) getMatchmakingRequest(userIDStr string) (*MatchmakingRequest, error) {
 	ctx := context.Background()
-	requestKey := fmt.Sprintf("matchmaking:request:%s", userIDStr)
+	requestKey := fmt.Sprintf(matchmakingRequestKey, userIDStr)
 
 	requestData, err := m.redisClient.Get(ctx, requestKey).Result()
 	if err != nil {
@@ -281,7 +632,7 @@ func (m *MatchmakingService) getMatchmakingRequest(userIDStr string) (*Matchmaki
 
 func (m *MatchmakingService) calculateRatingTolerance(waitTime time.Duration) int {
 	// Start with base tolerance and increase over time
-	tolerance := ratingTolerance + int(waitTime.Minutes())*20
+	tolerance := ratingTolerance + int(waitTime.Minutes())*toleranceIncreasePerMinute
 
 	if tolerance > maxRatingTolerance {
 		tolerance = maxRatingTolerance
@@ -290,36 +641,181 @@ func (m *MatchmakingService) calculateRatingTolerance(waitTime time.Duration) in
 	return tolerance
 }
 
+// claimScript atomically removes matched requests from every queue they
+// wait in. KEYS holds the request keys followed by queue keys; ARGV[1] is the
+// number of request keys and the rest are the queue members to remove. It
+// returns 0 without changes if any request is gone.
+var claimScript = redis.NewScript(`
+local n = tonumber(ARGV[1])
+for i = 1, n do
+	if redis.call('EXISTS', KEYS[i]) == 0 then
+		return 0
+	end
+end
+for i = 1, n do
+	redis.call('DEL', KEYS[i])
+end
+for i = n + 1, #KEYS do
+	redis.call('ZREM', KEYS[i], ARGV[i - n + 1])
+end
+return 1
+`)
+
+func (m *MatchmakingService) claimRequests(requests ...*MatchmakingRequest) (bool, error) {
+	ctx := context.Background()
+
+	keys := make([]string, 0, len(requests))
+	args := []interface{}{len(requests)}
+	for _, request := range requests {
+		keys = append(keys, fmt.Sprintf(matchmakingRequestKey, request.UserID))
+	}
+	for _, request := range requests {
+		for _, gameType := range request.queuedTypes() {
+			keys = append(keys, queueKeyFor(gameType, ratingBucket(request.Rating)))
+			args = append(args, request.UserID.String())
+		}
+	}
+
+	claimed, err := claimScript.Run(ctx, m.redisClient, keys, args...).Int()
+	if err != nil {
+		return false, err
+	}
+	return claimed == 1, nil
+}
+
+// requeue puts a claimed request back, keeping its place in the queues.
+func (m *MatchmakingService) requeue(request *MatchmakingRequest) {
+	if err := m.joinQueues(request); err != nil {
+		log.Printf("Failed to requeue %s: %v", request.UserID, err)
+	}
+}
+
+// requeueAfterFailedMatch puts both players of a match that fell through
+// back in their queues, keeping their place but not their opponent.
+func (m *MatchmakingService) requeueAfterFailedMatch(player1, player2 *MatchmakingRequest) {
+	m.backOffPair(player1.UserID, player2.UserID)
+	m.requeue(player1)
+	m.requeue(player2)
+}
+
+// backOffPair keeps two players from being paired again for a while, so a
+// match that failed is not immediately retried.
+func (m *MatchmakingService) backOffPair(userID, otherID uuid.UUID) {
+	key := backoffKey(userID.String(), otherID.String())
+	if err := m.redisClient.Set(context.Background(), key, "1", rematchBackoff).Err(); err != nil {
+		log.Printf("Failed to set rematch backoff: %v", err)
+	}
+}
+
+func backoffKey(userID, otherID string) string {
+	if otherID < userID {
+		userID, otherID = otherID, userID
+	}
+	return fmt.Sprintf(matchmakingBackoffKey, userID, otherID)
+}
+
+// removeRequest takes a request out of its queues and deletes its details.
+func (m *MatchmakingService) removeRequest(request *MatchmakingRequest) error {
+	ctx := context.Background()
+	userID := request.UserID.String()
+
+	_, err := m.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, gameType := range request.queuedTypes() {
+			pipe.ZRem(ctx, queueKeyFor(gameType, ratingBucket(request.Rating)), userID)
+		}
+		pipe.Del(ctx, fmt.Sprintf(matchmakingRequestKey, userID))
+		return nil
+	})
+	return err
+}
+
 func (m *MatchmakingService) cleanupExpiredRequests() {
 	ctx := context.Background()
 
 	for _, gameType := range m.registry.GetSupportedTypes() {
-		queueKey := fmt.Sprintf(matchmakingQueueKey, gameType)
-
-		// Get all users in queue
-		userIDs, err := m.redisClient.ZRange(ctx, queueKey, 0, -1).Result()
+		buckets, err := m.queueBuckets(gameType)
 		if err != nil {
 			continue
 		}
 
-		expiredUsers := []string{}
-		for _, userID := range userIDs {
-			request, err := m.getMatchmakingRequest(userID)
-			if err != nil || time.Since(request.JoinedAt) > matchmakingTimeout {
-				expiredUsers = append(expiredUsers, userID)
+		expired := 0
+		for _, bucket := range buckets {
+			queueKey := queueKeyFor(gameType, bucket)
+
+			// Get all users in the bucket
+			userIDs, err := m.redisClient.ZRange(ctx, queueKey, 0, -1).Result()
+			if err != nil {
+				continue
+			}
+
+			orphanedUsers := []string{}
+			expiredRequests := []*MatchmakingRequest{}
+			for _, userID := range userIDs {
+				request, err := m.getMatchmakingRequest(userID)
+				switch {
+				case err != nil || !request.queuedFor(gameType):
+					// Orphaned entry; leave any request for another queue alone
+					orphanedUsers = append(orphanedUsers, userID)
+				case time.Since(request.JoinedAt) > matchmakingTimeout:
+					expiredRequests = append(expiredRequests, request)
+				}
+			}
+
+			if len(orphanedUsers) > 0 {
+				m.redisClient.ZRem(ctx, queueKey, orphanedUsers)
+				expired += len(orphanedUsers)
 			}
-		}
 
-		// Remove expired users
-		if len(expiredUsers) > 0 {
-			m.redisClient.ZRem(ctx, queueKey, expiredUsers)
-			for _, userID := range expiredUsers {
-				requestKey := fmt.Sprintf("matchmaking:request:%s", userID)
-				m.redisClient.Del(ctx, requestKey)
+			// Claiming withdraws quick play entries from every queue and
+			// ensures only one instance tells the player
+			for _, request := range expiredRequests {
+				claimed, err := m.claimRequests(request)
+				if err != nil || !claimed {
+					continue
+				}
+				m.recordAbandoned(request, abandonReasonExpired)
+				m.notify(request.UserID, EventQueueExpired, QueueEventData{
+					GameTypes:   request.queuedTypes(),
+					WaitSeconds: int(time.Since(request.JoinedAt).Seconds()),
+				})
+				expired++
 			}
-			log.Printf("Cleaned up %d expired matchmaking requests for %s", len(expiredUsers), gameType)
 		}
+
+		if expired > 0 {
+			log.Printf("Cleaned up %d expired matchmaking requests for %s", expired, gameType)
+		}
+	}
+}
+
+func (r *MatchmakingRequest) queuedTypes() []models.GameType {
+	if len(r.GameTypes) > 0 {
+		return r.GameTypes
+	}
+	if r.GameType != "" {
+		return []models.GameType{r.GameType}
+	}
+	return nil
+}
+
+func (r *MatchmakingRequest) queuedFor(gameType models.GameType) bool {
+	for _, queued := range r.queuedTypes() {
+		if queued == gameType {
+			return true
+		}
+	}
+	return false
+}
+
+func ratingBucket(rating int) int {
+	if rating < 0 {
+		return 0
 	}
+	return rating / ratingBucketSize
+}
+
+func queueKeyFor(gameType models.GameType, bucket int) string {
+	return fmt.Sprintf(matchmakingQueueKey, gameType, bucket)
 }
 
 func abs(x int) int {
@@ -0,0 +1,49 @@
+package lobby
+
+import (
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// Event names sent through the Notifier.
+const (
+	EventPartyInvite  = "party_invite"
+	EventPartyUpdate  = "party_update"
+	EventMatchFound   = "match_found"
+	EventQueueJoined  = "queue_joined"
+	EventSearching    = "searching"
+	EventQueueExpired = "queue_expired"
+
+	EventMatchConfirm   = "match_confirm"
+	EventMatchCancelled = "match_cancelled"
+
+	EventChallenge         = "challenge"
+	EventChallengeAccepted = "challenge_accepted"
+	EventChallengeDeclined = "challenge_declined"
+	EventChallengeExpired  = "challenge_expired"
+
+	EventGameInvite         = "game_invite"
+	EventGameInviteAccepted = "game_invite_accepted"
+	EventGameInviteDeclined = "game_invite_declined"
+)
+
+// Notifier delivers matchmaking and lobby events to a user's connections.
+type Notifier interface {
+	NotifyUser(userID uuid.UUID, event string, data interface{}) error
+}
+
+// SetNotifier must be called before Start. Without it players are not told
+// about invites or matches.
+func (m *MatchmakingService) SetNotifier(notifier Notifier) {
+	m.notifier = notifier
+}
+
+func (m *MatchmakingService) notify(userID uuid.UUID, event string, data interface{}) {
+	if m.notifier == nil {
+		return
+	}
+	if err := m.notifier.NotifyUser(userID, event, data); err != nil {
+		log.Printf("Error notifying user %s of %s: %v", userID, event, err)
+	}
+}
@@ -0,0 +1,423 @@
+package lobby
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+var (
+	ErrPartyNotFound      = errors.New("party_not_found")
+	ErrNotPartyLeader     = errors.New("not_party_leader")
+	ErrPartyFull          = errors.New("party_full")
+	ErrPartyIncomplete    = errors.New("party_incomplete")
+	ErrAlreadyInParty     = errors.New("already_in_party")
+	ErrAlreadyInvited     = errors.New("already_invited")
+	ErrInviteNotFound     = errors.New("party_invite_not_found")
+	ErrInvalidPartyInvite = errors.New("invalid_party_invite")
+	ErrPartyInviteBlocked = errors.New("party_invite_blocked")
+	ErrGameTypeDisabled   = errors.New("game_type_disabled")
+)
+
+// Party is a group of friends that is seated in the same game. Every game
+// type has two seats, so a full party plays against itself.
+type Party struct {
+	ID        uuid.UUID     `json:"id"`
+	LeaderID  uuid.UUID     `json:"leader_id"`
+	Members   []uuid.UUID   `json:"members"`
+	Invites   []PartyInvite `json:"invites"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type PartyInvite struct {
+	UserID    uuid.UUID `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type PartyInviteData struct {
+	PartyID   uuid.UUID `json:"party_id"`
+	LeaderID  uuid.UUID `json:"leader_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+const (
+	partyKey        = "matchmaking:party:%s"        // party ID
+	partyMemberKey  = "matchmaking:party:member:%s" // user ID
+	partyTTL        = time.Hour
+	partyInviteTTL  = 2 * time.Minute
+	seatsPerGame    = 2
+	maxPartyRetries = 5
+)
+
+// InviteToParty invites another user to the inviter's party, creating the
+// party with the inviter as leader if needed.
+func (m *MatchmakingService) InviteToParty(userID, inviteeID uuid.UUID) error {
+	if userID == inviteeID {
+		return ErrInvalidPartyInvite
+	}
+
+	blocked, err := m.db.IsBlocked(userID, inviteeID)
+	if err != nil {
+		return fmt.Errorf("failed to check block list: %w", err)
+	}
+	if blocked {
+		return ErrPartyInviteBlocked
+	}
+
+	partyID, err := m.partyIDFor(userID)
+	if errors.Is(err, ErrPartyNotFound) {
+		partyID, err = m.createParty(userID)
+	}
+	if err != nil {
+		return err
+	}
+
+	invite := PartyInvite{UserID: inviteeID, ExpiresAt: time.Now().Add(partyInviteTTL)}
+	party, err := m.updateParty(partyID, func(party *Party) error {
+		if party.LeaderID != userID {
+			return ErrNotPartyLeader
+		}
+		if party.hasMember(inviteeID) || party.hasInvite(inviteeID) {
+			return ErrAlreadyInvited
+		}
+		if len(party.Members)+len(party.Invites) >= seatsPerGame {
+			return ErrPartyFull
+		}
+		party.Invites = append(party.Invites, invite)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	m.notify(inviteeID, EventPartyInvite, PartyInviteData{
+		PartyID:   party.ID,
+		LeaderID:  party.LeaderID,
+		ExpiresAt: invite.ExpiresAt,
+	})
+	m.notifyParty(party)
+	return nil
+}
+
+func (m *MatchmakingService) AcceptPartyInvite(userID, partyID uuid.UUID) error {
+	ctx := context.Background()
+
+	// Claim the membership first so a user cannot join two parties at once
+	memberKey := fmt.Sprintf(partyMemberKey, userID)
+	claimed, err := m.redisClient.SetNX(ctx, memberKey, partyID.String(), partyTTL).Result()
+	if err != nil {
+		return fmt.Errorf("failed to join party: %w", err)
+	}
+	if !claimed {
+		return ErrAlreadyInParty
+	}
+
+	party, err := m.updateParty(partyID, func(party *Party) error {
+		if !party.hasInvite(userID) {
+			return ErrInviteNotFound
+		}
+		party.removeInvite(userID)
+		party.Members = append(party.Members, userID)
+		return nil
+	})
+	if err != nil {
+		m.redisClient.Del(ctx, memberKey)
+		return err
+	}
+
+	m.notifyParty(party)
+	return nil
+}
+
+func (m *MatchmakingService) DeclinePartyInvite(userID, partyID uuid.UUID) error {
+	party, err := m.updateParty(partyID, func(party *Party) error {
+		if !party.hasInvite(userID) {
+			return ErrInviteNotFound
+		}
+		party.removeInvite(userID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	m.notifyParty(party)
+	return nil
+}
+
+// LeaveParty removes the user from their party. The next member becomes
+// leader if the leader leaves, and the party is disbanded once empty.
+func (m *MatchmakingService) LeaveParty(userID uuid.UUID) error {
+	ctx := context.Background()
+
+	partyID, err := m.partyIDFor(userID)
+	if err != nil {
+		return err
+	}
+
+	party, err := m.updateParty(partyID, func(party *Party) error {
+		party.removeMember(userID)
+		if len(party.Members) > 0 && party.LeaderID == userID {
+			party.LeaderID = party.Members[0]
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrPartyNotFound) {
+		return err
+	}
+	m.redisClient.Del(ctx, fmt.Sprintf(partyMemberKey, userID))
+
+	if party != nil {
+		if len(party.Members) == 0 {
+			m.redisClient.Del(ctx, fmt.Sprintf(partyKey, partyID))
+			return nil
+		}
+		m.notifyParty(party)
+	}
+	return nil
+}
+
+func (m *MatchmakingService) GetParty(userID uuid.UUID) (*Party, error) {
+	partyID, err := m.partyIDFor(userID)
+	if err != nil {
+		return nil, err
+	}
+	return m.getParty(partyID)
+}
+
+// QueueParty matches the leader's party. A party fills every seat of a game,
+// so its members are seated against each other straight away instead of
+// waiting in the rating queue.
+func (m *MatchmakingService) QueueParty(userID uuid.UUID, gameType models.GameType) error {
+	if !m.registry.IsEnabled(gameType) {
+		return ErrGameTypeDisabled
+	}
+
+	party, err := m.GetParty(userID)
+	if err != nil {
+		return err
+	}
+	if party.LeaderID != userID {
+		return ErrNotPartyLeader
+	}
+	if len(party.Members) < seatsPerGame {
+		return ErrPartyIncomplete
+	}
+
+	requests := make([]*MatchmakingRequest, 0, len(party.Members))
+	for _, memberID := range party.Members {
+		request := &MatchmakingRequest{
+			UserID:   memberID,
+			GameType: gameType,
+			JoinedAt: time.Now(),
+		}
+		if stats, err := m.db.GetUserStats(memberID); err == nil {
+			request.Rating = stats.Rating
+		}
+		requests = append(requests, request)
+
+		// Members who were also searching on their own are matched now
+		m.removeFromQueues(memberID)
+	}
+
+	// Games between friends don't affect ratings
+	settings := models.GameSettings{Rated: false}
+	if _, err := m.createMatch(gameType, settings, requests[0], requests[1]); err != nil {
+		return fmt.Errorf("failed to create party match: %w", err)
+	}
+
+	log.Printf("Created party match for party %s (%s)", party.ID, gameType)
+	return nil
+}
+
+func (m *MatchmakingService) createParty(leaderID uuid.UUID) (uuid.UUID, error) {
+	ctx := context.Background()
+
+	party := &Party{
+		ID:        uuid.New(),
+		LeaderID:  leaderID,
+		Members:   []uuid.UUID{leaderID},
+		CreatedAt: time.Now(),
+	}
+
+	memberKey := fmt.Sprintf(partyMemberKey, leaderID)
+	claimed, err := m.redisClient.SetNX(ctx, memberKey, party.ID.String(), partyTTL).Result()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create party: %w", err)
+	}
+	if !claimed {
+		return uuid.Nil, ErrAlreadyInParty
+	}
+
+	partyData, err := json.Marshal(party)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to marshal party: %w", err)
+	}
+	if err := m.redisClient.Set(ctx, fmt.Sprintf(partyKey, party.ID), partyData, partyTTL).Err(); err != nil {
+		m.redisClient.Del(ctx, memberKey)
+		return uuid.Nil, fmt.Errorf("failed to store party: %w", err)
+	}
+
+	return party.ID, nil
+}
+
+func (m *MatchmakingService) partyIDFor(userID uuid.UUID) (uuid.UUID, error) {
+	ctx := context.Background()
+
+	partyIDStr, err := m.redisClient.Get(ctx, fmt.Sprintf(partyMemberKey, userID)).Result()
+	if err == redis.Nil {
+		return uuid.Nil, ErrPartyNotFound
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get party: %w", err)
+	}
+
+	return uuid.Parse(partyIDStr)
+}
+
+func (m *MatchmakingService) getParty(partyID uuid.UUID) (*Party, error) {
+	ctx := context.Background()
+
+	partyData, err := m.redisClient.Get(ctx, fmt.Sprintf(partyKey, partyID)).Result()
+	if err == redis.Nil {
+		return nil, ErrPartyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get party: %w", err)
+	}
+
+	var party Party
+	if err := json.Unmarshal([]byte(partyData), &party); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal party: %w", err)
+	}
+	party.pruneInvites(time.Now())
+
+	return &party, nil
+}
+
+// updateParty applies fn to the stored party in a transaction, retrying if
+// another instance changed the party concurrently.
+func (m *MatchmakingService) updateParty(partyID uuid.UUID, fn func(party *Party) error) (*Party, error) {
+	ctx := context.Background()
+	key := fmt.Sprintf(partyKey, partyID)
+
+	var party *Party
+	txf := func(tx *redis.Tx) error {
+		partyData, err := tx.Get(ctx, key).Result()
+		if err == redis.Nil {
+			return ErrPartyNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		party = &Party{}
+		if err := json.Unmarshal([]byte(partyData), party); err != nil {
+			return err
+		}
+		party.pruneInvites(time.Now())
+
+		if err := fn(party); err != nil {
+			return err
+		}
+
+		updated, err := json.Marshal(party)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, updated, partyTTL)
+			for _, memberID := range party.Members {
+				pipe.Expire(ctx, fmt.Sprintf(partyMemberKey, memberID), partyTTL)
+			}
+			return nil
+		})
+		return err
+	}
+
+	for i := 0; i < maxPartyRetries; i++ {
+		err := m.redisClient.Watch(ctx, txf, key)
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return party, nil
+	}
+
+	return nil, fmt.Errorf("failed to update party %s: too many concurrent updates", partyID)
+}
+
+// removeFromQueues drops any solo matchmaking entries the user has.
+func (m *MatchmakingService) removeFromQueues(userID uuid.UUID) {
+	request, err := m.getMatchmakingRequest(userID.String())
+	if err != nil {
+		return
+	}
+	if err := m.removeRequest(request); err != nil {
+		log.Printf("Error removing %s from matchmaking queue: %v", userID, err)
+	}
+}
+
+func (m *MatchmakingService) notifyParty(party *Party) {
+	for _, memberID := range party.Members {
+		m.notify(memberID, EventPartyUpdate, party)
+	}
+}
+
+func (p *Party) hasMember(userID uuid.UUID) bool {
+	for _, memberID := range p.Members {
+		if memberID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Party) hasInvite(userID uuid.UUID) bool {
+	for _, invite := range p.Invites {
+		if invite.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Party) removeMember(userID uuid.UUID) {
+	members := p.Members[:0]
+	for _, memberID := range p.Members {
+		if memberID != userID {
+			members = append(members, memberID)
+		}
+	}
+	p.Members = members
+}
+
+func (p *Party) removeInvite(userID uuid.UUID) {
+	invites := p.Invites[:0]
+	for _, invite := range p.Invites {
+		if invite.UserID != userID {
+			invites = append(invites, invite)
+		}
+	}
+	p.Invites = invites
+}
+
+func (p *Party) pruneInvites(now time.Time) {
+	invites := p.Invites[:0]
+	for _, invite := range p.Invites {
+		if now.Before(invite.ExpiresAt) {
+			invites = append(invites, invite)
+		}
+	}
+	p.Invites = invites
+}
@@ -0,0 +1,65 @@
+package lobby
+
+import "github.com/szaher/vibeboard/backend/internal/models"
+
+// MatchPreferences lists what a player will accept. Empty lists accept
+// anything, and a nil Rated accepts both rated and casual games.
+type MatchPreferences struct {
+	TimeControls []string `json:"time_controls,omitempty"`
+	Variants     []string `json:"variants,omitempty"`
+	Rated        *bool    `json:"rated,omitempty"`
+}
+
+// negotiateSettings returns the settings two players both accept, preferring
+// the first player's order. It reports false if their preferences conflict.
+func negotiateSettings(a, b MatchPreferences) (models.GameSettings, bool) {
+	timeControl, ok := negotiateOption(a.TimeControls, b.TimeControls)
+	if !ok {
+		return models.GameSettings{}, false
+	}
+
+	variant, ok := negotiateOption(a.Variants, b.Variants)
+	if !ok {
+		return models.GameSettings{}, false
+	}
+
+	// Matchmade games are rated unless someone asked for casual
+	rated := true
+	switch {
+	case a.Rated != nil && b.Rated != nil:
+		if *a.Rated != *b.Rated {
+			return models.GameSettings{}, false
+		}
+		rated = *a.Rated
+	case a.Rated != nil:
+		rated = *a.Rated
+	case b.Rated != nil:
+		rated = *b.Rated
+	}
+
+	return models.GameSettings{
+		Variant:     variant,
+		TimeControl: timeControl,
+		Rated:       rated,
+	}, true
+}
+
+func negotiateOption(a, b []string) (string, bool) {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return "", true
+	case len(a) == 0:
+		return b[0], true
+	case len(b) == 0:
+		return a[0], true
+	}
+
+	for _, option := range a {
+		for _, other := range b {
+			if option == other {
+				return option, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,52 @@
+package lobby
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// presenceWindow is how recently a queued player must have been seen on a
+// connection to be matched. It covers the hub's ping and refresh intervals.
+const presenceWindow = 90 * time.Second
+
+// PresenceChecker reports whether a user still has a live connection.
+type PresenceChecker interface {
+	IsConnected(userID uuid.UUID, within time.Duration) (bool, error)
+}
+
+// SetPresenceChecker must be called before Start. Without it queued players
+// are assumed to be connected.
+func (m *MatchmakingService) SetPresenceChecker(presence PresenceChecker) {
+	m.presence = presence
+}
+
+func (m *MatchmakingService) isConnected(userID uuid.UUID) bool {
+	if m.presence == nil {
+		return true
+	}
+
+	connected, err := m.presence.IsConnected(userID, presenceWindow)
+	if err != nil {
+		// Fail open rather than stall matchmaking while Redis is unhealthy
+		log.Printf("Error checking presence for %s: %v", userID, err)
+		return true
+	}
+	return connected
+}
+
+// dropDisconnected withdraws a request whose player is no longer connected.
+func (m *MatchmakingService) dropDisconnected(request *MatchmakingRequest) {
+	claimed, err := m.claimRequests(request)
+	if err != nil || !claimed {
+		return
+	}
+	m.recordAbandoned(request, abandonReasonDisconnected)
+
+	m.notify(request.UserID, EventQueueExpired, QueueEventData{
+		GameTypes:   request.queuedTypes(),
+		WaitSeconds: int(time.Since(request.JoinedAt).Seconds()),
+	})
+	log.Printf("Dropped disconnected user %s from matchmaking", request.UserID)
+}
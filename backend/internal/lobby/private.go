@@ -0,0 +1,155 @@
+package lobby
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+var (
+	ErrInvalidJoinCode     = errors.New("invalid_join_code")
+	ErrJoinCodeRateLimited = errors.New("join_code_rate_limited")
+)
+
+// JoinCode lets a friend join a private game without matchmaking.
+type JoinCode struct {
+	Code      string    `json:"join_code"`
+	GameID    uuid.UUID `json:"game_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PrivateLobbyService issues and redeems join codes for private games, and
+// invites to waiting games.
+type PrivateLobbyService struct {
+	redisClient *redis.Client
+	cfg         *config.LobbyConfig
+	notifier    Notifier
+}
+
+const (
+	joinCodeKey      = "lobby:code:%s"         // code
+	joinCodeGameKey  = "lobby:code:game:%s"    // game ID
+	joinCodeLimitKey = "lobby:ratelimit:%s:%s" // action, user ID
+	// Ambiguous characters (0/O, 1/I/L) are left out so codes can be read
+	// aloud
+	joinCodeAlphabet    = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	joinCodeLength      = 6
+	maxJoinCodeAttempts = 5
+)
+
+func NewPrivateLobbyService(redisClient *redis.Client, cfg *config.LobbyConfig) *PrivateLobbyService {
+	return &PrivateLobbyService{
+		redisClient: redisClient,
+		cfg:         cfg,
+	}
+}
+
+// CreateJoinCode issues a new code for a game, replacing any previous one.
+func (s *PrivateLobbyService) CreateJoinCode(userID, gameID uuid.UUID) (*JoinCode, error) {
+	ctx := context.Background()
+
+	if err := s.checkRateLimit(ctx, "create", userID, s.cfg.JoinCodesPerHour, time.Hour); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < maxJoinCodeAttempts; i++ {
+		code, err := generateJoinCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate join code: %w", err)
+		}
+
+		created, err := s.redisClient.SetNX(ctx, fmt.Sprintf(joinCodeKey, code), gameID.String(), s.cfg.JoinCodeTTL).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to store join code: %w", err)
+		}
+		if !created {
+			continue // collision with a live code
+		}
+
+		s.RevokeJoinCode(gameID)
+		s.redisClient.Set(ctx, fmt.Sprintf(joinCodeGameKey, gameID), code, s.cfg.JoinCodeTTL)
+
+		return &JoinCode{
+			Code:      code,
+			GameID:    gameID,
+			ExpiresAt: time.Now().Add(s.cfg.JoinCodeTTL),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("failed to generate a unique join code")
+}
+
+// ResolveJoinCode returns the game a code belongs to. Lookups are rate
+// limited per user so codes cannot be guessed.
+func (s *PrivateLobbyService) ResolveJoinCode(userID uuid.UUID, code string) (uuid.UUID, error) {
+	ctx := context.Background()
+
+	if err := s.checkRateLimit(ctx, "redeem", userID, s.cfg.JoinCodeAttempts, time.Minute); err != nil {
+		return uuid.Nil, err
+	}
+
+	code = strings.ToUpper(strings.TrimSpace(code))
+	gameIDStr, err := s.redisClient.Get(ctx, fmt.Sprintf(joinCodeKey, code)).Result()
+	if err == redis.Nil {
+		return uuid.Nil, ErrInvalidJoinCode
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to look up join code: %w", err)
+	}
+
+	return uuid.Parse(gameIDStr)
+}
+
+// RevokeJoinCode invalidates a game's current code, e.g. once its seat is
+// taken.
+func (s *PrivateLobbyService) RevokeJoinCode(gameID uuid.UUID) {
+	ctx := context.Background()
+	gameKey := fmt.Sprintf(joinCodeGameKey, gameID)
+
+	code, err := s.redisClient.GetDel(ctx, gameKey).Result()
+	if err != nil {
+		return
+	}
+	s.redisClient.Del(ctx, fmt.Sprintf(joinCodeKey, code))
+}
+
+func (s *PrivateLobbyService) checkRateLimit(ctx context.Context, action string, userID uuid.UUID, limit int, window time.Duration) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf(joinCodeLimitKey, action, userID)
+	count, err := s.redisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	if count == 1 {
+		s.redisClient.Expire(ctx, key, window)
+	}
+	if count > int64(limit) {
+		return ErrJoinCodeRateLimited
+	}
+	return nil
+}
+
+func generateJoinCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(joinCodeAlphabet)))
+
+	var code strings.Builder
+	for i := 0; i < joinCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		code.WriteByte(joinCodeAlphabet[n.Int64()])
+	}
+	return code.String(), nil
+}
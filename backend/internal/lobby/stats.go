@@ -0,0 +1,243 @@
+package lobby
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// Reasons a player left matchmaking without a game.
+const (
+	abandonReasonCancelled = "cancelled"
+	abandonReasonExpired   = "expired"
+	abandonReasonDeclined  = "declined"
+	// abandonReasonDisconnected counts players dropped at match time
+	// because they had no live connection
+	abandonReasonDisconnected = "disconnected"
+)
+
+var (
+	matchmakingQueueSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "vibearcade",
+		Subsystem: "matchmaking",
+		Name:      "queue_size",
+		Help:      "Players waiting in the matchmaking queue, by game type.",
+	}, []string{"game_type"})
+
+	matchmakingJoins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "vibearcade",
+		Subsystem: "matchmaking",
+		Name:      "joins_total",
+		Help:      "Players who joined the matchmaking queue, by game type.",
+	}, []string{"game_type"})
+
+	matchmakingMatches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "vibearcade",
+		Subsystem: "matchmaking",
+		Name:      "matches_total",
+		Help:      "Games created from the matchmaking queue, by game type.",
+	}, []string{"game_type"})
+
+	matchmakingAbandoned = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "vibearcade",
+		Subsystem: "matchmaking",
+		Name:      "abandoned_total",
+		Help:      "Players who left the queue without a game, by game type and reason.",
+	}, []string{"game_type", "reason"})
+
+	matchmakingWaitSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "vibearcade",
+		Subsystem: "matchmaking",
+		Name:      "wait_seconds",
+		Help:      "Time matched players spent in the queue, by game type.",
+		Buckets:   []float64{1, 5, 10, 20, 30, 60, 120, 180, 300},
+	}, []string{"game_type"})
+
+	matchmakingRatingDifference = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "vibearcade",
+		Subsystem: "matchmaking",
+		Name:      "rating_difference",
+		Help:      "Rating difference between matched players, by game type.",
+		Buckets:   prometheus.LinearBuckets(0, 50, 11),
+	}, []string{"game_type"})
+)
+
+// MatchmakingStats summarizes a game type's queue since stats were first
+// recorded. Quick play requests count toward each of their game types.
+type MatchmakingStats struct {
+	GameType                models.GameType  `json:"game_type"`
+	QueueSize               int64            `json:"queue_size"`
+	Joins                   int64            `json:"joins"`
+	Matches                 int64            `json:"matches"`
+	AverageWaitSeconds      float64          `json:"average_wait_seconds"`
+	AverageRatingDifference float64          `json:"average_rating_difference"`
+	Abandoned               map[string]int64 `json:"abandoned"`
+	AbandonmentRate         float64          `json:"abandonment_rate"`
+}
+
+// TolerancePolicy describes how the accepted rating difference grows while
+// a player waits.
+type TolerancePolicy struct {
+	Initial           int `json:"initial"`
+	Max               int `json:"max"`
+	IncreasePerMinute int `json:"increase_per_minute"`
+	BucketSize        int `json:"bucket_size"`
+}
+
+const (
+	// matchmakingStatsKey totals the recorded events in a hash, so every
+	// instance contributes to the same stats
+	matchmakingStatsKey = "matchmaking:stats:%s" // game type
+
+	statsJoins            = "joins"
+	statsMatches          = "matches"
+	statsWaitSeconds      = "wait_seconds"
+	statsRatingDifference = "rating_difference"
+	statsAbandonedPrefix  = "abandoned:"
+)
+
+var abandonReasons = []string{abandonReasonCancelled, abandonReasonExpired, abandonReasonDeclined, abandonReasonDisconnected}
+
+// GetStats returns queue stats for every supported game type.
+func (m *MatchmakingService) GetStats() ([]MatchmakingStats, error) {
+	ctx := context.Background()
+
+	gameTypes := m.registry.GetSupportedTypes()
+	sort.Slice(gameTypes, func(i, j int) bool { return gameTypes[i] < gameTypes[j] })
+
+	stats := make([]MatchmakingStats, 0, len(gameTypes))
+	for _, gameType := range gameTypes {
+		fields, err := m.redisClient.HGetAll(ctx, fmt.Sprintf(matchmakingStatsKey, gameType)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get matchmaking stats: %w", err)
+		}
+		queueSize, err := m.queueSize(gameType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get queue size: %w", err)
+		}
+
+		entry := MatchmakingStats{
+			GameType:  gameType,
+			QueueSize: queueSize,
+			Joins:     parseStat(fields[statsJoins]),
+			Matches:   parseStat(fields[statsMatches]),
+			Abandoned: make(map[string]int64, len(abandonReasons)),
+		}
+
+		// Each match has two players, each with their own wait
+		if entry.Matches > 0 {
+			entry.AverageWaitSeconds = parseFloatStat(fields[statsWaitSeconds]) / float64(2*entry.Matches)
+			entry.AverageRatingDifference = parseFloatStat(fields[statsRatingDifference]) / float64(entry.Matches)
+		}
+
+		var abandoned int64
+		for _, reason := range abandonReasons {
+			count := parseStat(fields[statsAbandonedPrefix+reason])
+			entry.Abandoned[reason] = count
+			abandoned += count
+		}
+		if entry.Joins > 0 {
+			entry.AbandonmentRate = float64(abandoned) / float64(entry.Joins)
+		}
+
+		stats = append(stats, entry)
+	}
+	return stats, nil
+}
+
+// GetTolerancePolicy returns the rating tolerance settings the stats should
+// be read against.
+func (m *MatchmakingService) GetTolerancePolicy() TolerancePolicy {
+	return TolerancePolicy{
+		Initial:           ratingTolerance,
+		Max:               maxRatingTolerance,
+		IncreasePerMinute: toleranceIncreasePerMinute,
+		BucketSize:        ratingBucketSize,
+	}
+}
+
+// recordJoin counts a player entering the queue. Requeued players are not
+// counted again.
+func (m *MatchmakingService) recordJoin(request *MatchmakingRequest) {
+	for _, gameType := range request.queuedTypes() {
+		matchmakingJoins.WithLabelValues(string(gameType)).Inc()
+		m.incrementStat(gameType, statsJoins, 1)
+	}
+}
+
+// recordMatch counts a game created from the queue.
+func (m *MatchmakingService) recordMatch(gameType models.GameType, player1, player2 *MatchmakingRequest) {
+	label := string(gameType)
+	matchmakingMatches.WithLabelValues(label).Inc()
+	m.incrementStat(gameType, statsMatches, 1)
+
+	var waited float64
+	for _, player := range []*MatchmakingRequest{player1, player2} {
+		wait := time.Since(player.JoinedAt).Seconds()
+		matchmakingWaitSeconds.WithLabelValues(label).Observe(wait)
+		waited += wait
+	}
+	m.incrementStat(gameType, statsWaitSeconds, waited)
+
+	difference := float64(abs(player1.Rating - player2.Rating))
+	matchmakingRatingDifference.WithLabelValues(label).Observe(difference)
+	m.incrementStat(gameType, statsRatingDifference, difference)
+}
+
+// recordAbandoned counts a player leaving the queue without a game.
+func (m *MatchmakingService) recordAbandoned(request *MatchmakingRequest, reason string) {
+	for _, gameType := range request.queuedTypes() {
+		matchmakingAbandoned.WithLabelValues(string(gameType), reason).Inc()
+		m.incrementStat(gameType, statsAbandonedPrefix+reason, 1)
+	}
+}
+
+func (m *MatchmakingService) incrementStat(gameType models.GameType, field string, value float64) {
+	key := fmt.Sprintf(matchmakingStatsKey, gameType)
+	if err := m.redisClient.HIncrByFloat(context.Background(), key, field, value).Err(); err != nil {
+		log.Printf("Error recording matchmaking stat %s for %s: %v", field, gameType, err)
+	}
+}
+
+func (m *MatchmakingService) queueSize(gameType models.GameType) (int64, error) {
+	ctx := context.Background()
+
+	buckets, err := m.queueBuckets(gameType)
+	if err != nil {
+		return 0, err
+	}
+
+	cmds := make([]*redis.IntCmd, 0, len(buckets))
+	_, err = m.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, bucket := range buckets {
+			cmds = append(cmds, pipe.ZCard(ctx, queueKeyFor(gameType, bucket)))
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, cmd := range cmds {
+		size += cmd.Val()
+	}
+	return size, nil
+}
+
+func parseStat(value string) int64 {
+	return int64(parseFloatStat(value))
+}
+
+func parseFloatStat(value string) float64 {
+	parsed, _ := strconv.ParseFloat(value, 64)
+	return parsed
+}
@@ -0,0 +1,64 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strings"
+
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+// Mailer sends plain-text email.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NewMailer sends through SMTP when a host is configured and only logs
+// messages otherwise, for development.
+func NewMailer(cfg *config.MailConfig) Mailer {
+	if cfg.SMTPHost == "" {
+		return LogMailer{}
+	}
+	return &SMTPMailer{cfg: cfg}
+}
+
+// LogMailer writes messages to the log instead of sending them.
+type LogMailer struct{}
+
+func (LogMailer) Send(to, subject, body string) error {
+	log.Printf("Mail to %s: %s\n%s", to, subject, body)
+	return nil
+}
+
+type SMTPMailer struct {
+	cfg *config.MailConfig
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return fmt.Errorf("invalid mail header")
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.SMTPHost)
+	}
+
+	message := "From: " + m.cfg.From + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body
+	from, err := netmail.ParseAddress(m.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+	addr := net.JoinHostPort(m.cfg.SMTPHost, m.cfg.SMTPPort)
+	if err := smtp.SendMail(addr, auth, from.Address, []string{to}, []byte(message)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AdjudicationReason string
+
+const (
+	// AdjudicationReasonStuck is for games that cannot go on, such as when
+	// the opponent stopped moving without leaving
+	AdjudicationReasonStuck       AdjudicationReason = "stuck"
+	AdjudicationReasonAbuse       AdjudicationReason = "abuse"
+	AdjudicationReasonWrongResult AdjudicationReason = "wrong_result"
+	AdjudicationReasonOther       AdjudicationReason = "other"
+)
+
+type AdjudicationStatus string
+
+const (
+	AdjudicationStatusOpen      AdjudicationStatus = "open"
+	AdjudicationStatusResolved  AdjudicationStatus = "resolved"
+	AdjudicationStatusDismissed AdjudicationStatus = "dismissed"
+)
+
+// AdjudicationDecision is how a moderator settled a game.
+type AdjudicationDecision string
+
+const (
+	AdjudicationDecisionWinner AdjudicationDecision = "winner"
+	AdjudicationDecisionDraw   AdjudicationDecision = "draw"
+	// AdjudicationDecisionVoid abandons the game, so it counts for nobody
+	AdjudicationDecisionVoid AdjudicationDecision = "void"
+)
+
+// Adjudication is a player's request for a moderator to settle a stuck or
+// disputed game.
+type Adjudication struct {
+	ID          uuid.UUID          `json:"id" db:"id"`
+	GameID      uuid.UUID          `json:"game_id" db:"game_id"`
+	RequesterID uuid.UUID          `json:"requester_id" db:"requester_id"`
+	Reason      AdjudicationReason `json:"reason" db:"reason"`
+	Details     string             `json:"details" db:"details"`
+	Status      AdjudicationStatus `json:"status" db:"status"`
+	// Decision and WinnerID are set once the request is resolved
+	Decision       *AdjudicationDecision `json:"decision,omitempty" db:"decision"`
+	WinnerID       *uuid.UUID            `json:"winner_id,omitempty" db:"winner_id"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+	ResolvedAt     *time.Time            `json:"resolved_at,omitempty" db:"resolved_at"`
+	ResolvedBy     *uuid.UUID            `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolutionNote string                `json:"resolution_note,omitempty" db:"resolution_note"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditAction string
+
+const (
+	AuditActionGameTypeEnable    AuditAction = "game_type.set_enabled"
+	AuditActionClientDisconnect  AuditAction = "client.disconnect"
+	AuditActionAnnouncement      AuditAction = "announcement.send"
+	AuditActionReportResolve     AuditAction = "report.resolve"
+	AuditActionChatMessageDelete AuditAction = "chat_message.delete"
+	AuditActionUserMute          AuditAction = "user.mute"
+	AuditActionUserUnmute        AuditAction = "user.unmute"
+	AuditActionGamePause         AuditAction = "game.pause"
+	AuditActionGameResume        AuditAction = "game.resume"
+	AuditActionAdjudicate        AuditAction = "adjudication.resolve"
+	AuditActionSeasonCreate      AuditAction = "season.create"
+	AuditActionSeasonDelete      AuditAction = "season.delete"
+	AuditActionTournamentCreate  AuditAction = "tournament.create"
+	AuditActionTournamentCancel  AuditAction = "tournament.cancel"
+)
+
+// AuditTarget is the kind of thing an audited action was taken on.
+type AuditTarget string
+
+const (
+	AuditTargetUser         AuditTarget = "user"
+	AuditTargetGame         AuditTarget = "game"
+	AuditTargetGameType     AuditTarget = "game_type"
+	AuditTargetClient       AuditTarget = "client"
+	AuditTargetReport       AuditTarget = "report"
+	AuditTargetChatMessage  AuditTarget = "chat_message"
+	AuditTargetAnnouncement AuditTarget = "announcement"
+	AuditTargetAdjudication AuditTarget = "adjudication"
+	AuditTargetSeason       AuditTarget = "season"
+	AuditTargetTournament   AuditTarget = "tournament"
+)
+
+// AuditEntry records a privileged action by an admin. Before and After
+// are snapshots of the target, either of which is empty when the action
+// created or removed it.
+type AuditEntry struct {
+	ID         uuid.UUID       `json:"id" db:"id"`
+	ActorID    uuid.UUID       `json:"actor_id" db:"actor_id"`
+	Action     AuditAction     `json:"action" db:"action"`
+	TargetType AuditTarget     `json:"target_type" db:"target_type"`
+	TargetID   string          `json:"target_id" db:"target_id"`
+	Reason     string          `json:"reason,omitempty" db:"reason"`
+	Before     json.RawMessage `json:"before,omitempty" db:"before"`
+	After      json.RawMessage `json:"after,omitempty" db:"after"`
+	IP         string          `json:"ip" db:"ip"`
+	RequestID  string          `json:"request_id,omitempty" db:"request_id"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditFilter narrows an audit log query. Zero fields match everything.
+type AuditFilter struct {
+	ActorID    uuid.UUID
+	Action     AuditAction
+	TargetType AuditTarget
+	TargetID   string
+	Since      time.Time
+	Until      time.Time
+}
@@ -0,0 +1,20 @@
+package models
+
+import "github.com/google/uuid"
+
+// BotLevel is how strongly a bot plays.
+type BotLevel string
+
+const (
+	BotLevelEasy   BotLevel = "easy"
+	BotLevelMedium BotLevel = "medium"
+	BotLevelHard   BotLevel = "hard"
+)
+
+// Bot is a computer chess player. Each has an account of its own, which is
+// seated in its games.
+type Bot struct {
+	Level    BotLevel  `json:"level"`
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GameFilter narrows a game listing. Empty fields match every game.
+type GameFilter struct {
+	Status GameStatus `json:"status,omitempty"`
+	Type   GameType   `json:"type,omitempty"`
+	// Rated lists only rated games if true, only casual ones if false
+	Rated *bool   `json:"rated,omitempty"`
+	Tag   GameTag `json:"tag,omitempty"`
+}
+
+func (f GameFilter) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
+
+func (f *GameFilter) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*f = GameFilter{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into GameFilter", src)
+	}
+	return json.Unmarshal(data, f)
+}
+
+// SavedGameFilter is a game listing filter a user named and kept, so their
+// game browser can offer it again.
+type SavedGameFilter struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Filter    GameFilter `json:"filter"`
+	CreatedAt time.Time  `json:"created_at"`
+}
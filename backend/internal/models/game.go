@@ -14,6 +14,12 @@ const (
 	GameTypeChess    GameType = "chess"
 )
 
+// Seats is how many players a game of the type seats. It starts once they
+// are all seated.
+func (t GameType) Seats() int {
+	return 2
+}
+
 type GameStatus string
 
 const (
@@ -23,6 +29,57 @@ const (
 	GameStatusAbandoned  GameStatus = "abandoned"
 )
 
+// EndReason is how a finished game ended.
+type EndReason string
+
+const (
+	// EndReasonCheckmate means a chess king was taken
+	EndReasonCheckmate   EndReason = "checkmate"
+	EndReasonResignation EndReason = "resignation"
+	// EndReasonTimeout means a player ran out of time on the turn timer or
+	// missed a correspondence deadline
+	EndReasonTimeout EndReason = "timeout"
+	// EndReasonAbandonment means one or both players left the game
+	EndReasonAbandonment EndReason = "abandonment"
+	// EndReasonAgreement is for draws agreed by the players
+	EndReasonAgreement EndReason = "agreement"
+	// EndReasonBlocked means neither dominoes player could play, so the
+	// lower hand won
+	EndReasonBlocked EndReason = "blocked"
+	// EndReasonDomino means a dominoes player played their last tile
+	EndReasonDomino EndReason = "domino"
+	// EndReasonCancelled means the creator called the game off before it
+	// started
+	EndReasonCancelled EndReason = "cancelled"
+	// EndReasonAdjudication means a moderator settled the game
+	EndReasonAdjudication EndReason = "adjudication"
+	// EndReasonStalemate is for draws where the player to move had no move
+	EndReasonStalemate EndReason = "stalemate"
+)
+
+// GameVisibility decides who can find a game in listings and watch it.
+type GameVisibility string
+
+const (
+	// GameVisibilityPublic games are listed and open to spectators
+	GameVisibilityPublic GameVisibility = "public"
+	// GameVisibilityUnlisted games are left out of listings but anyone
+	// with the game's ID may watch
+	GameVisibilityUnlisted GameVisibility = "unlisted"
+	// GameVisibilityFriends games are listed to, and may be watched by,
+	// the players' friends
+	GameVisibilityFriends GameVisibility = "friends"
+)
+
+// GameTag marks what a game is for, so listings can be filtered by it.
+type GameTag string
+
+const (
+	GameTagTournament GameTag = "tournament"
+	GameTagFriendly   GameTag = "friendly"
+	GameTagStudy      GameTag = "study"
+)
+
 type Game struct {
 	ID          uuid.UUID       `json:"id" db:"id"`
 	Type        GameType        `json:"type" db:"game_type"`
@@ -36,6 +93,183 @@ type Game struct {
 	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
 	StartedAt   *time.Time      `json:"started_at,omitempty" db:"started_at"`
 	EndedAt     *time.Time      `json:"ended_at,omitempty" db:"ended_at"`
+	// Private games are unlisted and can only be joined with a join code
+	Private bool `json:"private" db:"is_private"`
+	// Visibility applies to games that are not private, which are always
+	// hidden
+	Visibility GameVisibility `json:"visibility" db:"visibility"`
+	Settings   GameSettings   `json:"settings" db:"settings"`
+	// StarterID is the player who moved first, set once the game starts
+	StarterID *uuid.UUID `json:"starter_id,omitempty" db:"starter_id"`
+	// InitialState is the opening state, kept so the game can be replayed
+	InitialState json.RawMessage `json:"-" db:"initial_state"`
+	// MoveCount counts the game's move history, timeouts included
+	MoveCount  int        `json:"move_count" db:"move_count"`
+	LastMoveAt *time.Time `json:"last_move_at,omitempty" db:"last_move_at"`
+	// DurationSeconds is the time from start to end, set once the game ends
+	DurationSeconds *int `json:"duration_seconds,omitempty" db:"duration_seconds"`
+	// Spectators is how many connections on all servers were watching when
+	// last recorded, and PeakSpectators the most at once
+	Spectators     int `json:"spectators" db:"spectators"`
+	PeakSpectators int `json:"peak_spectators" db:"peak_spectators"`
+	// PausedAt is set while the game is paused, which stops the turn clock
+	// and blocks moves. PausedBy is the admin who forced the pause; only an
+	// admin can resume it
+	PausedAt      *time.Time `json:"paused_at,omitempty" db:"paused_at"`
+	PausedBy      *uuid.UUID `json:"-" db:"paused_by"`
+	PausedByAdmin bool       `json:"paused_by_admin"`
+	// PauseRequestedBy is the player waiting for the other to agree to
+	// pause or, while paused, to resume
+	PauseRequestedBy *uuid.UUID `json:"pause_requested_by,omitempty" db:"pause_requested_by"`
+	// TurnStartedAt is when the current turn's clock started, moved on by
+	// any pauses; unset until the first move
+	TurnStartedAt *time.Time `json:"turn_started_at,omitempty" db:"turn_started_at"`
+	// MoveDeadline is when the player to move in a correspondence game
+	// runs out of time
+	MoveDeadline *time.Time `json:"move_deadline,omitempty" db:"move_deadline"`
+	// SeriesID links the game to the series it is game number SeriesGame
+	// of, counting from 1
+	SeriesID   *uuid.UUID `json:"series_id,omitempty" db:"series_id"`
+	SeriesGame int        `json:"series_game,omitempty" db:"series_game"`
+	// EndReason is set once the game has ended
+	EndReason EndReason `json:"end_reason,omitempty" db:"end_reason"`
+	// Players are the game's seats from game_players, which decide who
+	// plays it. They are loaded with single games, not listings; Player1ID
+	// and Player2ID are derived from seats 1 and 2, and are only set
+	// through the seat methods below.
+	Players []GamePlayer `json:"players,omitempty"`
+	// Tags are loaded for game listings and single-game responses
+	Tags []GameTag `json:"tags,omitempty"`
+}
+
+// GamePlayer is a player's seat in a game.
+type GamePlayer struct {
+	UserID uuid.UUID `json:"user_id"`
+	// Seat numbers the players from 1 in the order they sat down
+	Seat int `json:"seat"`
+	// Color is the side the player plays, such as white in chess
+	Color string `json:"color,omitempty"`
+	Team  *int   `json:"team,omitempty"`
+}
+
+// Seats returns the game's seats in order. Games loaded without their seats
+// fall back to seats 1 and 2 from the player columns.
+func (g *Game) Seats() []GamePlayer {
+	if g.Players != nil {
+		return g.Players
+	}
+	seats := []GamePlayer{{UserID: g.Player1ID, Seat: 1}}
+	if g.Player2ID != nil {
+		seats = append(seats, GamePlayer{UserID: *g.Player2ID, Seat: 2})
+	}
+	return seats
+}
+
+// PlayerIDs returns the seated players in seat order.
+func (g *Game) PlayerIDs() []uuid.UUID {
+	seats := g.Seats()
+	ids := make([]uuid.UUID, len(seats))
+	for i, seat := range seats {
+		ids[i] = seat.UserID
+	}
+	return ids
+}
+
+// Creator returns the player in seat 1, who created the game or, for games
+// that were made for their players, was seated first.
+func (g *Game) Creator() uuid.UUID {
+	return g.Seats()[0].UserID
+}
+
+// HasPlayer reports whether the user holds a seat in the game.
+func (g *Game) HasPlayer(userID uuid.UUID) bool {
+	for _, seat := range g.Seats() {
+		if seat.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFull reports whether every seat of the game is taken.
+func (g *Game) IsFull() bool {
+	return len(g.Seats()) >= g.Type.Seats()
+}
+
+// Opponents returns the other seated players in seat order.
+func (g *Game) Opponents(userID uuid.UUID) []uuid.UUID {
+	var opponents []uuid.UUID
+	for _, id := range g.PlayerIDs() {
+		if id != userID {
+			opponents = append(opponents, id)
+		}
+	}
+	return opponents
+}
+
+// SetPlayers seats the players in order.
+func (g *Game) SetPlayers(playerIDs ...uuid.UUID) {
+	g.Players = make([]GamePlayer, len(playerIDs))
+	for i, id := range playerIDs {
+		g.Players[i] = GamePlayer{UserID: id, Seat: i + 1}
+	}
+	g.derivePlayerColumns()
+}
+
+// SeatPlayer seats the user in the next seat.
+func (g *Game) SeatPlayer(userID uuid.UUID) {
+	seats := g.Seats()
+	g.Players = append(seats, GamePlayer{UserID: userID, Seat: len(seats) + 1})
+	g.derivePlayerColumns()
+}
+
+// ReleaseSeat frees the user's seat, moving the players after them up a
+// seat, and reports whether they had one.
+func (g *Game) ReleaseSeat(userID uuid.UUID) bool {
+	seats := make([]GamePlayer, 0, len(g.Seats()))
+	for _, seat := range g.Seats() {
+		if seat.UserID != userID {
+			seat.Seat = len(seats) + 1
+			seats = append(seats, seat)
+		}
+	}
+	if len(seats) == len(g.Seats()) {
+		return false
+	}
+	g.Players = seats
+	g.derivePlayerColumns()
+	return true
+}
+
+func (g *Game) derivePlayerColumns() {
+	g.Player1ID, g.Player2ID = uuid.Nil, nil
+	if len(g.Players) > 0 {
+		g.Player1ID = g.Players[0].UserID
+	}
+	if len(g.Players) > 1 {
+		player2 := g.Players[1].UserID
+		g.Player2ID = &player2
+	}
+}
+
+// Duration is how long the game lasted, or zero if it has not ended.
+func (g *Game) Duration() time.Duration {
+	if g.StartedAt == nil || g.EndedAt == nil {
+		return 0
+	}
+	return g.EndedAt.Sub(*g.StartedAt)
+}
+
+// GameChange is what the database announces when a game's status, seats,
+// turn or move count changes, whichever instance wrote it.
+type GameChange struct {
+	GameID      uuid.UUID  `json:"id"`
+	Status      GameStatus `json:"status"`
+	Player1ID   uuid.UUID  `json:"player1_id"`
+	Player2ID   *uuid.UUID `json:"player2_id,omitempty"`
+	CurrentTurn *uuid.UUID `json:"current_turn,omitempty"`
+	WinnerID    *uuid.UUID `json:"winner_id,omitempty"`
+	MoveCount   int        `json:"move_count"`
 }
 
 type Move struct {
@@ -45,6 +279,94 @@ type Move struct {
 	MoveData  json.RawMessage `json:"move_data" db:"move_data"`
 	CreatedAt time.Time       `json:"created_at" db:"created_at"`
 	IsValid   bool            `json:"is_valid" db:"is_valid"`
+	Kind      MoveKind        `json:"kind" db:"kind"`
+}
+
+// GameSnapshot is a game's state after its first MoveNumber moves.
+type GameSnapshot struct {
+	GameID     uuid.UUID       `json:"game_id" db:"game_id"`
+	MoveNumber int             `json:"move_number" db:"move_number"`
+	State      json.RawMessage `json:"state" db:"state"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// MoveKind tells player moves apart from turns the server resolved.
+type MoveKind string
+
+const (
+	MoveKindMove MoveKind = "move"
+	// MoveKindTimeout records the engine's timeout rule being applied to
+	// the player's turn
+	MoveKindTimeout MoveKind = "timeout"
+)
+
+// GameSummary is a completed game from one player's point of view.
+type GameSummary struct {
+	ID               uuid.UUID  `json:"id"`
+	GameType         GameType   `json:"game_type"`
+	OpponentID       *uuid.UUID `json:"opponent_id,omitempty"`
+	OpponentUsername string     `json:"opponent_username,omitempty"`
+	Result           GameResult `json:"result"`
+	EndReason        EndReason  `json:"end_reason,omitempty"`
+	EndedAt          *time.Time `json:"ended_at,omitempty"`
+}
+
+type GameResult string
+
+const (
+	GameResultWin  GameResult = "win"
+	GameResultLoss GameResult = "loss"
+	GameResultDraw GameResult = "draw"
+)
+
+// UserGame is one of a player's games from their point of view.
+type UserGame struct {
+	ID       uuid.UUID  `json:"id"`
+	GameType GameType   `json:"game_type"`
+	Status   GameStatus `json:"status"`
+	Private  bool       `json:"private"`
+	// Opponent is unset while the game waits for a second player
+	Opponent    *GameOpponent `json:"opponent"`
+	CurrentTurn *uuid.UUID    `json:"current_turn,omitempty"`
+	YourTurn    bool          `json:"your_turn"`
+	WinnerID    *uuid.UUID    `json:"winner_id,omitempty"`
+	// Result is set on completed games
+	Result    GameResult `json:"result,omitempty"`
+	EndReason EndReason  `json:"end_reason,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	MoveCount int        `json:"move_count"`
+	// LastMoveAt is when the current turn began, if a move has been made
+	LastMoveAt      *time.Time `json:"last_move_at,omitempty"`
+	DurationSeconds *int       `json:"duration_seconds,omitempty"`
+	Tags            []GameTag  `json:"tags,omitempty"`
+}
+
+type GameOpponent struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	AvatarURL string    `json:"avatar_url"`
+}
+
+// LiveGame is an in-progress public game as listed for spectators.
+type LiveGame struct {
+	ID         uuid.UUID    `json:"id"`
+	GameType   GameType     `json:"game_type"`
+	StartedAt  *time.Time   `json:"started_at,omitempty"`
+	MoveCount  int          `json:"move_count"`
+	LastMoveAt *time.Time   `json:"last_move_at,omitempty"`
+	Players    []LivePlayer `json:"players"`
+	Spectators int          `json:"spectators"`
+	Tags       []GameTag    `json:"tags,omitempty"`
+}
+
+type LivePlayer struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	AvatarURL string    `json:"avatar_url"`
+	Rating    int       `json:"rating"`
 }
 
 type GameRoom struct {
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DirectMessage struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	SenderID    uuid.UUID `json:"sender_id" db:"sender_id"`
+	RecipientID uuid.UUID `json:"recipient_id" db:"recipient_id"`
+	Body        string    `json:"body" db:"body"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// ChatMessage is a message sent to a room's chat. Deleted messages are kept
+// for moderation but no longer shown.
+type ChatMessage struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	RoomID    string     `json:"room_id" db:"room_id"`
+	SenderID  uuid.UUID  `json:"sender_id" db:"sender_id"`
+	Body      string     `json:"body" db:"body"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy *uuid.UUID `json:"deleted_by,omitempty" db:"deleted_by"`
+}
+
+// Mute stops a user from chatting until it expires.
+type Mute struct {
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	MutedUntil time.Time `json:"muted_until" db:"muted_until"`
+	Reason     string    `json:"reason" db:"reason"`
+	MutedBy    uuid.UUID `json:"muted_by" db:"muted_by"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
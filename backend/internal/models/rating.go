@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RatingSystem string
+
+const (
+	RatingSystemElo     RatingSystem = "elo"
+	RatingSystemGlicko2 RatingSystem = "glicko2"
+)
+
+// ProvisionalGames is how many rated games a player's rating is provisional
+// for: it moves faster, and moves their opponents' less.
+const ProvisionalGames = 10
+
+// IsProvisional reports whether a rating from ratedGames games is
+// provisional.
+func IsProvisional(ratedGames int) bool {
+	return ratedGames < ProvisionalGames
+}
+
+// A new player's Glicko-2 deviation and volatility
+const (
+	DefaultRatingDeviation  = 350
+	DefaultRatingVolatility = 0.06
+)
+
+// PlayerRating is a player's rating along with how sure Glicko-2 is of it:
+// Deviation is the rating's uncertainty and Volatility how erratic the
+// player's results are. Elo leaves both alone. RatedGames and Tier are
+// kept by the rating recorder, not the rating system, which sets
+// Provisional for players with fewer than ProvisionalGames rated games.
+type PlayerRating struct {
+	Rating      int
+	Deviation   float64
+	Volatility  float64
+	RatedGames  int
+	Tier        RankedTier
+	Provisional bool
+}
+
+// RatingChange is one change to a user's rating, usually from a game.
+type RatingChange struct {
+	ID        uuid.UUID    `json:"id" db:"id"`
+	UserID    uuid.UUID    `json:"user_id" db:"user_id"`
+	GameID    *uuid.UUID   `json:"game_id,omitempty" db:"game_id"`
+	GameType  GameType     `json:"game_type,omitempty" db:"game_type"`
+	System    RatingSystem `json:"system" db:"system"`
+	OldRating int          `json:"old_rating" db:"old_rating"`
+	NewRating int          `json:"new_rating" db:"new_rating"`
+	Delta     int          `json:"delta" db:"delta"`
+	// Deviation is the rating deviation after the change, unset for changes
+	// made before deviations were recorded
+	Deviation *float64  `json:"deviation,omitempty" db:"deviation"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReportReason string
+
+const (
+	ReportReasonHarassment    ReportReason = "harassment"
+	ReportReasonCheating      ReportReason = "cheating"
+	ReportReasonSpam          ReportReason = "spam"
+	ReportReasonOffensiveName ReportReason = "offensive_name"
+	ReportReasonOther         ReportReason = "other"
+)
+
+type ReportStatus string
+
+const (
+	ReportStatusOpen      ReportStatus = "open"
+	ReportStatusResolved  ReportStatus = "resolved"
+	ReportStatusDismissed ReportStatus = "dismissed"
+)
+
+// Report is a player's complaint about another player, awaiting review by
+// an admin.
+type Report struct {
+	ID         uuid.UUID    `json:"id" db:"id"`
+	ReporterID uuid.UUID    `json:"reporter_id" db:"reporter_id"`
+	ReportedID uuid.UUID    `json:"reported_id" db:"reported_id"`
+	Reason     ReportReason `json:"reason" db:"reason"`
+	Details    string       `json:"details" db:"details"`
+	GameID     *uuid.UUID   `json:"game_id,omitempty" db:"game_id"`
+	// ChatMessageID is set when a specific room chat message is reported
+	ChatMessageID *uuid.UUID   `json:"chat_message_id,omitempty" db:"chat_message_id"`
+	Status        ReportStatus `json:"status" db:"status"`
+	// Context is captured when the report is made, so later deletions do
+	// not hide what was reported
+	Context        ReportContext `json:"context" db:"context"`
+	CreatedAt      time.Time     `json:"created_at" db:"created_at"`
+	ResolvedAt     *time.Time    `json:"resolved_at,omitempty" db:"resolved_at"`
+	ResolvedBy     *uuid.UUID    `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolutionNote string        `json:"resolution_note,omitempty" db:"resolution_note"`
+}
+
+// ReportContext is what the two players said to each other and, for reports
+// about a game, the game itself. Reports of a chat message carry the room's
+// chat leading up to it.
+type ReportContext struct {
+	DirectMessages []*DirectMessage  `json:"direct_messages,omitempty"`
+	RoomChat       []json.RawMessage `json:"room_chat,omitempty"`
+	ChatMessages   []*ChatMessage    `json:"chat_messages,omitempty"`
+	Game           *Game             `json:"game,omitempty"`
+}
+
+func (c ReportContext) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func (c *ReportContext) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*c = ReportContext{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ReportContext", src)
+	}
+	return json.Unmarshal(data, c)
+}
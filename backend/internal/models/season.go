@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SeasonStatus string
+
+const (
+	SeasonStatusScheduled SeasonStatus = "scheduled"
+	SeasonStatusActive    SeasonStatus = "active"
+	SeasonStatusEnded     SeasonStatus = "ended"
+)
+
+// Season is a competitive season, with ratings and leaderboards of its own
+// from StartsAt until EndsAt. Rewards are granted once it is finalized.
+type Season struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	StartsAt    time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt      time.Time  `json:"ends_at" db:"ends_at"`
+	FinalizedAt *time.Time `json:"finalized_at,omitempty" db:"finalized_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	// Status is as of when the season was read
+	Status SeasonStatus `json:"status"`
+}
+
+// StatusAt returns whether the season has started or ended at t.
+func (s *Season) StatusAt(t time.Time) SeasonStatus {
+	switch {
+	case t.Before(s.StartsAt):
+		return SeasonStatusScheduled
+	case t.Before(s.EndsAt):
+		return SeasonStatusActive
+	}
+	return SeasonStatusEnded
+}
+
+// SeasonReward is granted by a player's final rank in a season.
+type SeasonReward string
+
+const (
+	SeasonRewardChampion    SeasonReward = "champion"
+	SeasonRewardTop10       SeasonReward = "top_10"
+	SeasonRewardTop100      SeasonReward = "top_100"
+	SeasonRewardParticipant SeasonReward = "participant"
+)
+
+// SeasonRewardGrant is a reward a player earned in a season's leaderboard
+// of one game type.
+type SeasonRewardGrant struct {
+	SeasonID   uuid.UUID    `json:"season_id" db:"season_id"`
+	SeasonName string       `json:"season_name"`
+	UserID     uuid.UUID    `json:"user_id" db:"user_id"`
+	GameType   GameType     `json:"game_type" db:"game_type"`
+	Reward     SeasonReward `json:"reward" db:"reward"`
+	Rank       int          `json:"rank" db:"rank"`
+	Rating     int          `json:"rating" db:"rating"`
+	GrantedAt  time.Time    `json:"granted_at" db:"granted_at"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SeriesStatus string
+
+const (
+	SeriesStatusInProgress SeriesStatus = "in_progress"
+	SeriesStatusCompleted  SeriesStatus = "completed"
+	// SeriesStatusAbandoned means a game of the series was abandoned by
+	// both players, so no further games were played
+	SeriesStatusAbandoned SeriesStatus = "abandoned"
+)
+
+// MaxBestOf is the longest series that can be played
+const MaxBestOf = 9
+
+// Series is a best-of-N match between two players, made of games of the
+// same type and settings played one after another. It is won by the first
+// player to win a majority of BestOf games, or after BestOf games by
+// whoever won more; an even score is a drawn series.
+type Series struct {
+	ID          uuid.UUID    `json:"id" db:"id"`
+	Type        GameType     `json:"game_type" db:"game_type"`
+	Player1ID   uuid.UUID    `json:"player1_id" db:"player1_id"`
+	Player2ID   uuid.UUID    `json:"player2_id" db:"player2_id"`
+	BestOf      int          `json:"best_of" db:"best_of"`
+	Settings    GameSettings `json:"settings" db:"settings"`
+	Status      SeriesStatus `json:"status" db:"status"`
+	GamesPlayed int          `json:"games_played" db:"games_played"`
+	Player1Wins int          `json:"player1_wins" db:"player1_wins"`
+	Player2Wins int          `json:"player2_wins" db:"player2_wins"`
+	Draws       int          `json:"draws" db:"draws"`
+	// WinnerID is unset until the series completes, and on a drawn series
+	WinnerID  *uuid.UUID `json:"winner_id,omitempty" db:"winner_id"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty" db:"ended_at"`
+	Games     []*Game    `json:"games,omitempty"`
+}
+
+// Decided reports whether the series is over: a player has won a
+// majority of its games or every game has been played.
+func (s *Series) Decided() bool {
+	majority := s.BestOf/2 + 1
+	return s.Player1Wins >= majority || s.Player2Wins >= majority || s.GamesPlayed >= s.BestOf
+}
+
+// Leader is the player with more wins, or nil on an even score.
+func (s *Series) Leader() *uuid.UUID {
+	switch {
+	case s.Player1Wins > s.Player2Wins:
+		return &s.Player1ID
+	case s.Player2Wins > s.Player1Wins:
+		return &s.Player2ID
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TimeControlCorrespondence is the time control of long-form games, where
+// each move may take days and is due by a deadline rather than on a live
+// clock.
+const TimeControlCorrespondence = "correspondence"
+
+// Days per move allowed in correspondence games
+const (
+	DefaultDaysPerMove = 3
+	MaxDaysPerMove     = 14
+)
+
+// GameSettings are the rules a game is played under.
+type GameSettings struct {
+	Variant string `json:"variant,omitempty"`
+	// BoardSize is the size of the board, or for tile games of the set
+	BoardSize   int    `json:"board_size,omitempty"`
+	TimeControl string `json:"time_control,omitempty"`
+	Rated       bool   `json:"rated"`
+	// DaysPerMove is how long each move may take in correspondence games,
+	// DefaultDaysPerMove if unset
+	DaysPerMove int `json:"days_per_move,omitempty"`
+	// BestOf makes the game the first of a series of that many games
+	BestOf int `json:"best_of,omitempty"`
+}
+
+// MoveDeadline is when the next move is due if the turn starts at from, or
+// nil if moves are on the live turn timer rather than a deadline.
+func (s GameSettings) MoveDeadline(from time.Time) *time.Time {
+	if s.TimeControl != TimeControlCorrespondence {
+		return nil
+	}
+	days := s.DaysPerMove
+	if days <= 0 {
+		days = DefaultDaysPerMove
+	}
+	deadline := from.Add(time.Duration(days) * 24 * time.Hour)
+	return &deadline
+}
+
+func (s GameSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *GameSettings) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*s = GameSettings{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into GameSettings", src)
+	}
+	return json.Unmarshal(data, s)
+}
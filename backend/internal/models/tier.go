@@ -0,0 +1,23 @@
+package models
+
+// Tier is a named band of ratings players are placed in once they have
+// finished their placement games.
+type Tier string
+
+const (
+	TierUnranked    Tier = "unranked"
+	TierBronze      Tier = "bronze"
+	TierSilver      Tier = "silver"
+	TierGold        Tier = "gold"
+	TierPlatinum    Tier = "platinum"
+	TierDiamond     Tier = "diamond"
+	TierMaster      Tier = "master"
+	TierGrandmaster Tier = "grandmaster"
+)
+
+// RankedTier is a player's tier and, below master, their division in it:
+// 4 is the lowest and 1 the highest.
+type RankedTier struct {
+	Tier     Tier `json:"tier"`
+	Division int  `json:"division,omitempty"`
+}
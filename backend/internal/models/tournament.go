@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TournamentStatus string
+
+const (
+	TournamentStatusScheduled  TournamentStatus = "scheduled"
+	TournamentStatusInProgress TournamentStatus = "in_progress"
+	TournamentStatusFinished   TournamentStatus = "finished"
+	TournamentStatusCancelled  TournamentStatus = "cancelled"
+)
+
+// Tournament is a scheduled event players register for before it starts.
+// Its rounds start RoundMinutes apart from StartsAt. Players whose rating
+// is outside MinRating and MaxRating, where set, may not register.
+type Tournament struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	Name         string     `json:"name" db:"name"`
+	GameType     GameType   `json:"game_type" db:"game_type"`
+	TimeControl  string     `json:"time_control,omitempty" db:"time_control"`
+	StartsAt     time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt       time.Time  `json:"ends_at" db:"ends_at"`
+	Rounds       int        `json:"rounds" db:"rounds"`
+	RoundMinutes int        `json:"round_minutes" db:"round_minutes"`
+	Capacity     int        `json:"capacity" db:"capacity"`
+	MinRating    *int       `json:"min_rating,omitempty" db:"min_rating"`
+	MaxRating    *int       `json:"max_rating,omitempty" db:"max_rating"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	// Registrations is how many players have registered
+	Registrations int `json:"registrations"`
+	// Status is as of when the tournament was read
+	Status TournamentStatus `json:"status"`
+}
+
+// StatusAt returns whether the tournament has started or ended at t.
+func (t *Tournament) StatusAt(at time.Time) TournamentStatus {
+	switch {
+	case t.CancelledAt != nil:
+		return TournamentStatusCancelled
+	case at.Before(t.StartsAt):
+		return TournamentStatusScheduled
+	case at.Before(t.EndsAt):
+		return TournamentStatusInProgress
+	}
+	return TournamentStatusFinished
+}
+
+// RoundStartsAt returns when round, numbered from 1, starts.
+func (t *Tournament) RoundStartsAt(round int) time.Time {
+	return t.StartsAt.Add(time.Duration((round-1)*t.RoundMinutes) * time.Minute)
+}
+
+// NextRound returns the first round starting after at, or false if every
+// round has started.
+func (t *Tournament) NextRound(at time.Time) (int, bool) {
+	for round := 1; round <= t.Rounds; round++ {
+		if t.RoundStartsAt(round).After(at) {
+			return round, true
+		}
+	}
+	return 0, false
+}
+
+// AcceptsRating reports whether a player rated rating is within the
+// tournament's rating band.
+func (t *Tournament) AcceptsRating(rating int) bool {
+	return (t.MinRating == nil || rating >= *t.MinRating) && (t.MaxRating == nil || rating <= *t.MaxRating)
+}
+
+// TournamentRound is a round of a tournament a player is registered for,
+// as listed in their schedule and sent in round reminders.
+type TournamentRound struct {
+	TournamentID   uuid.UUID `json:"tournament_id"`
+	TournamentName string    `json:"tournament_name"`
+	GameType       GameType  `json:"game_type"`
+	Round          int       `json:"round"`
+	StartsAt       time.Time `json:"starts_at"`
+}
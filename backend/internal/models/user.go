@@ -6,6 +6,13 @@ import (
 	"github.com/google/uuid"
 )
 
+type UserRole string
+
+const (
+	UserRolePlayer UserRole = "player"
+	UserRoleAdmin  UserRole = "admin"
+)
+
 type User struct {
 	ID        uuid.UUID `json:"id" db:"id"`
 	Email     string    `json:"email" db:"email"`
@@ -14,6 +21,56 @@ type User struct {
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 	IsActive  bool      `json:"is_active" db:"is_active"`
+	Role      UserRole  `json:"role" db:"role"`
+	AvatarURL string    `json:"avatar_url" db:"avatar_url"`
+	// ProfileVisibility controls who sees stats and recent games on the
+	// public profile
+	ProfileVisibility ProfileVisibility `json:"profile_visibility" db:"profile_visibility"`
+	// UsernameChangedAt is when the username was last changed, which starts
+	// the cooldown before it may change again
+	UsernameChangedAt *time.Time `json:"username_changed_at,omitempty" db:"username_changed_at"`
+	// DeletedAt is set when the user deleted their account. The row stays,
+	// scrubbed of personal data, so their past games still render
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// DeletedUsername stands in for a deleted user's name in games and chat.
+const DeletedUsername = "Deleted player"
+
+// Deleted reports whether the user deleted their account. Deleted users
+// are also inactive.
+func (u *User) Deleted() bool {
+	return u.DeletedAt != nil
+}
+
+type ProfileVisibility string
+
+const (
+	ProfileVisibilityPublic  ProfileVisibility = "public"
+	ProfileVisibilityFriends ProfileVisibility = "friends"
+	ProfileVisibilityPrivate ProfileVisibility = "private"
+)
+
+// UserSummary is a user as listed in search results.
+type UserSummary struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	AvatarURL string    `json:"avatar_url"`
+}
+
+// LeaderboardEntry is a player's standing on a leaderboard.
+type LeaderboardEntry struct {
+	Rank        int       `json:"rank"`
+	UserID      uuid.UUID `json:"user_id"`
+	Username    string    `json:"username"`
+	Rating      int       `json:"rating"`
+	GamesPlayed int       `json:"games_played"`
+	GamesWon    int       `json:"games_won"`
+	// WeeklyWins is set on weekly leaderboards, which rank by it
+	WeeklyWins int `json:"weekly_wins,omitempty"`
+	RankedTier
+	// Provisional is set while the player's rating is provisional
+	Provisional bool `json:"provisional,omitempty"`
 }
 
 type UserStats struct {
@@ -22,5 +79,105 @@ type UserStats struct {
 	GamesWon    int       `json:"games_won" db:"games_won"`
 	GamesLost   int       `json:"games_lost" db:"games_lost"`
 	Rating      int       `json:"rating" db:"rating"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	// RatingDeviation is how unsure the rating is; see PlayerRating
+	RatingDeviation float64 `json:"rating_deviation" db:"rating_deviation"`
+	// RatedGames counts the player's rated games, the first of which are
+	// placement games played unranked
+	RatedGames int `json:"rated_games" db:"rated_games"`
+	RankedTier
+	// Provisional is set for the player's first ProvisionalGames rated
+	// games, while their rating settles
+	Provisional bool `json:"provisional"`
+	// PlacementGamesLeft is how many more rated games place an unranked
+	// player in a tier
+	PlacementGamesLeft int `json:"placement_games_left,omitempty"`
+	// SeriesPlayed counts finished best-of-N series, and SeriesWon those
+	// the user won
+	SeriesPlayed int       `json:"series_played" db:"series_played"`
+	SeriesWon    int       `json:"series_won" db:"series_won"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PlayerStats is a player's overall record as another player may see it.
+// The record is left out when Restricted is set.
+type PlayerStats struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Username    string    `json:"username"`
+	AvatarURL   string    `json:"avatar_url"`
+	Restricted  bool      `json:"restricted"`
+	Rating      *int      `json:"rating,omitempty"`
+	GamesPlayed *int      `json:"games_played,omitempty"`
+	GamesWon    *int      `json:"games_won,omitempty"`
+	GamesLost   *int      `json:"games_lost,omitempty"`
+}
+
+// GameTypeStats is a player's record in one game type, counted from their
+// completed games.
+type GameTypeStats struct {
+	GameType    GameType `json:"game_type"`
+	GamesPlayed int      `json:"games_played"`
+	GamesWon    int      `json:"games_won"`
+	GamesLost   int      `json:"games_lost"`
+	GamesDrawn  int      `json:"games_drawn"`
+	// WinRate is GamesWon over GamesPlayed, from 0 to 1
+	WinRate float64 `json:"win_rate"`
+	// CurrentWinStreak counts wins since the latest loss or draw
+	CurrentWinStreak int `json:"current_win_streak"`
+	BestWinStreak    int `json:"best_win_streak"`
+	// AverageDurationSeconds is the mean time from start to end
+	AverageDurationSeconds int `json:"average_duration_seconds"`
+}
+
+// HeadToHead is a player's record against one opponent.
+type HeadToHead struct {
+	OpponentID   uuid.UUID        `json:"opponent_id"`
+	GamesPlayed  int              `json:"games_played"`
+	GamesWon     int              `json:"games_won"`
+	GamesLost    int              `json:"games_lost"`
+	GamesDrawn   int              `json:"games_drawn"`
+	WinRate      float64          `json:"win_rate"`
+	LastPlayedAt *time.Time       `json:"last_played_at,omitempty"`
+	ByGameType   []*GameTypeStats `json:"by_game_type"`
+}
+
+type FriendshipStatus string
+
+const (
+	FriendshipStatusPending  FriendshipStatus = "pending"
+	FriendshipStatusAccepted FriendshipStatus = "accepted"
+)
+
+// Friendship links two users. UserID sent the request.
+type Friendship struct {
+	UserID    uuid.UUID        `json:"user_id" db:"user_id"`
+	FriendID  uuid.UUID        `json:"friend_id" db:"friend_id"`
+	Status    FriendshipStatus `json:"status" db:"status"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+}
+
+// Friend is another user as shown in a friends list or friend request.
+type Friend struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	AvatarURL string    `json:"avatar_url"`
+	Since     time.Time `json:"since"`
+	// Online is only set on friends lists
+	Online bool `json:"online"`
+}
+
+// LoginEvent is one sign-in to an account.
+type LoginEvent struct {
+	ID     uuid.UUID `json:"id" db:"id"`
+	UserID uuid.UUID `json:"user_id" db:"user_id"`
+	// Method is password, magic_link or the OAuth provider
+	Method    string `json:"method" db:"method"`
+	IP        string `json:"ip" db:"ip"`
+	UserAgent string `json:"user_agent" db:"user_agent"`
+	// DeviceHash identifies the device without storing its raw ID
+	DeviceHash string `json:"-" db:"device_hash"`
+	Country    string `json:"country,omitempty" db:"country"`
+	// Suspicious is set for logins from a new device or an improbable
+	// location
+	Suspicious bool      `json:"suspicious" db:"suspicious"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 }
@@ -0,0 +1,180 @@
+// Package password enforces the password policy, including checks against
+// passwords exposed in known breaches.
+package password
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+// MaxLength is the most bcrypt will hash.
+const MaxLength = 72
+
+// common is the offline list of breached passwords, used always and as the
+// only breach check when the breach API is unreachable.
+//
+//go:embed common.txt
+var common string
+
+// Violation is one way a password breaks the policy.
+type Violation struct {
+	Code    string
+	Message string
+}
+
+// Policy checks candidate passwords.
+type Policy struct {
+	cfg     *config.PasswordConfig
+	client  *http.Client
+	offline map[string]bool
+}
+
+// NewPolicy loads the offline breach list, adding BreachListFile if set.
+func NewPolicy(cfg *config.PasswordConfig) (*Policy, error) {
+	p := &Policy{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: cfg.BreachTimeout},
+		offline: make(map[string]bool),
+	}
+
+	p.addOffline(bufio.NewScanner(strings.NewReader(common)))
+	if cfg.BreachListFile != "" {
+		f, err := os.Open(cfg.BreachListFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open breached password list: %w", err)
+		}
+		defer f.Close()
+		p.addOffline(bufio.NewScanner(f))
+	}
+
+	return p, nil
+}
+
+func (p *Policy) addOffline(scanner *bufio.Scanner) {
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			p.offline[strings.ToLower(line)] = true
+		}
+	}
+}
+
+// Check returns every way the password breaks the policy for an account
+// with the given username and email, or nil if it is acceptable.
+func (p *Policy) Check(ctx context.Context, password, username, email string) []Violation {
+	var violations []Violation
+
+	if len([]rune(password)) < p.cfg.MinLength {
+		violations = append(violations, Violation{"too_short", fmt.Sprintf("Must be at least %d characters", p.cfg.MinLength)})
+	}
+	if len(password) > MaxLength {
+		violations = append(violations, Violation{"too_long", fmt.Sprintf("Must be at most %d bytes", MaxLength)})
+	}
+	if classes := characterClasses(password); classes < p.cfg.MinCharacterClasses {
+		violations = append(violations, Violation{"too_simple", fmt.Sprintf("Must mix at least %d of lowercase letters, uppercase letters, digits and symbols", p.cfg.MinCharacterClasses)})
+	}
+	if containsIdentity(password, username, email) {
+		violations = append(violations, Violation{"contains_identity", "Must not contain your username or email"})
+	}
+	// Only passwords that pass everything else are worth a network call
+	if violations == nil && p.breached(ctx, password) {
+		violations = append(violations, Violation{"breached", "This password has appeared in a data breach; choose another"})
+	}
+
+	return violations
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+	return classes
+}
+
+func containsIdentity(password, username, email string) bool {
+	password = strings.ToLower(password)
+	local, _, _ := strings.Cut(email, "@")
+	for _, identity := range []string{username, local} {
+		if len(identity) >= 3 && strings.Contains(password, strings.ToLower(identity)) {
+			return true
+		}
+	}
+	return false
+}
+
+// breached checks the offline list and then, if enabled, the k-anonymity
+// range API, which is only sent the first five characters of the
+// password's SHA-1. If the API fails, the offline result stands.
+func (p *Policy) breached(ctx context.Context, password string) bool {
+	if p.offline[strings.ToLower(password)] {
+		return true
+	}
+	if p.cfg.BreachAPIURL == "" {
+		return false
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.BreachTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.BreachAPIURL+prefix, nil)
+	if err != nil {
+		log.Printf("Error building breach check request: %v", err)
+		return false
+	}
+	// Padding hides the real size of the response from observers
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		log.Printf("Breach check unavailable, using offline list: %v", err)
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Breach check unavailable, using offline list: status %d", resp.StatusCode)
+		return false
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, count, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		// Padding entries have a count of 0
+		if candidate == suffix && count != "0" {
+			return true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("Error reading breach check response: %v", err)
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+const (
+	userKey = "ratelimit:user:%s" // user ID
+	ipKey   = "ratelimit:ip:%s"   // client IP
+)
+
+// slidingWindow keeps one sorted-set entry per request scored by its time
+// in milliseconds, so the quota covers exactly the trailing window on every
+// instance. Rejected requests are not recorded. Returns whether the request
+// was allowed, the requests counted in the window and the milliseconds until
+// the oldest of them leaves it.
+var slidingWindow = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`)
+
+// Result describes a quota after a request was counted against it.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Window    time.Duration
+	// Reset is how long until the oldest counted request leaves the
+	// window and frees a slot
+	Reset time.Duration
+}
+
+// Limiter enforces the API's per-user and per-IP quotas across instances.
+type Limiter struct {
+	redisClient *redis.Client
+	cfg         *config.APIConfig
+}
+
+func NewLimiter(redisClient *redis.Client, cfg *config.APIConfig) *Limiter {
+	return &Limiter{redisClient: redisClient, cfg: cfg}
+}
+
+// AllowUser counts a request by an authenticated user. A zero Limit in
+// the result means the quota is disabled.
+func (l *Limiter) AllowUser(ctx context.Context, userID uuid.UUID) (Result, error) {
+	return l.allow(ctx, fmt.Sprintf(userKey, userID), l.cfg.UserRateLimit)
+}
+
+// AllowIP counts an unauthenticated request from an IP.
+func (l *Limiter) AllowIP(ctx context.Context, ip string) (Result, error) {
+	return l.allow(ctx, fmt.Sprintf(ipKey, ip), l.cfg.IPRateLimit)
+}
+
+func (l *Limiter) allow(ctx context.Context, key string, limit int) (Result, error) {
+	window := l.cfg.RateLimitWindow
+	if limit <= 0 || window <= 0 {
+		return Result{Allowed: true}, nil
+	}
+
+	now := time.Now().UnixMilli()
+	values, err := slidingWindow.Run(ctx, l.redisClient, []string{key},
+		now, window.Milliseconds(), limit, fmt.Sprintf("%d-%s", now, uuid.NewString())).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	return Result{
+		Allowed:   values[0] == 1,
+		Limit:     limit,
+		Remaining: limit - int(values[1]),
+		Window:    window,
+		Reset:     time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,120 @@
+package retention
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+// Kinds of data the janitor deletes, as metric labels.
+const (
+	kindWaitingGames   = "waiting_games"
+	kindChatMessages   = "chat_messages"
+	kindDirectMessages = "direct_messages"
+	kindLoginEvents    = "login_events"
+	kindTrustedDevices = "trusted_devices"
+)
+
+// taskTimeout bounds each kind's deletes in one run.
+const taskTimeout = 5 * time.Minute
+
+var (
+	retentionDeleted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "vibearcade",
+		Subsystem: "retention",
+		Name:      "deleted_total",
+		Help:      "Rows deleted past their retention period, by kind.",
+	}, []string{"kind"})
+
+	retentionErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "vibearcade",
+		Subsystem: "retention",
+		Name:      "errors_total",
+		Help:      "Retention runs that failed to delete a kind of data, by kind.",
+	}, []string{"kind"})
+
+	retentionLastRun = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "vibearcade",
+		Subsystem: "retention",
+		Name:      "last_run_timestamp_seconds",
+		Help:      "When the retention janitor last finished a run.",
+	})
+)
+
+// Janitor periodically deletes data past its retention period: waiting
+// games nobody joined, old chat and direct messages, login history and
+// devices that have not been used in a while. Redis-held auth artifacts
+// expire on their own and are not its concern.
+type Janitor struct {
+	db  *database.DB
+	cfg *config.RetentionConfig
+}
+
+func NewJanitor(db *database.DB, cfg *config.RetentionConfig) *Janitor {
+	return &Janitor{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+func (j *Janitor) Start() {
+	if j.cfg.Interval <= 0 {
+		log.Println("Data retention disabled")
+		return
+	}
+
+	log.Printf("Starting data retention (interval: %s)...", j.cfg.Interval)
+
+	ticker := time.NewTicker(j.cfg.Interval)
+	go func() {
+		j.run()
+		for range ticker.C {
+			j.run()
+		}
+	}()
+}
+
+type task struct {
+	kind   string
+	age    time.Duration
+	delete func(ctx context.Context, before time.Time) (int64, error)
+}
+
+func (j *Janitor) run() {
+	tasks := []task{
+		{kindWaitingGames, j.cfg.WaitingGames, j.db.DeleteStaleWaitingGames},
+		{kindChatMessages, j.cfg.ChatMessages, j.db.DeleteOldChatMessages},
+		{kindDirectMessages, j.cfg.DirectMessages, j.db.DeleteOldDirectMessages},
+		{kindLoginEvents, j.cfg.LoginEvents, j.db.DeleteOldLoginEvents},
+		{kindTrustedDevices, j.cfg.TrustedDevices, j.db.DeleteStaleTrustedDevices},
+	}
+
+	now := time.Now()
+	for _, t := range tasks {
+		if t.age <= 0 {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), taskTimeout)
+		deleted, err := t.delete(ctx, now.Add(-t.age))
+		cancel()
+
+		// Batches deleted before a failure still count
+		retentionDeleted.WithLabelValues(t.kind).Add(float64(deleted))
+		if err != nil {
+			retentionErrors.WithLabelValues(t.kind).Inc()
+			log.Printf("Error deleting old %s: %v", strings.ReplaceAll(t.kind, "_", " "), err)
+			continue
+		}
+		if deleted > 0 {
+			log.Printf("Deleted %d old %s", deleted, strings.ReplaceAll(t.kind, "_", " "))
+		}
+	}
+	retentionLastRun.SetToCurrentTime()
+}
@@ -0,0 +1,292 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	closeKicked = 4003
+	errorKicked = "kicked"
+)
+
+// MessageTypeGameTypesChanged tells the other instances that a game type
+// was enabled or disabled. It only travels over the backplane.
+const MessageTypeGameTypesChanged MessageType = "game_types_changed"
+
+// GameTypeReloader reloads which game types are enabled.
+type GameTypeReloader interface {
+	Reload() error
+}
+
+type AnnouncementLevel string
+
+const (
+	AnnouncementLevelInfo     AnnouncementLevel = "info"
+	AnnouncementLevelWarning  AnnouncementLevel = "warning"
+	AnnouncementLevelCritical AnnouncementLevel = "critical"
+)
+
+type AnnouncementData struct {
+	Message string            `json:"message"`
+	Level   AnnouncementLevel `json:"level"`
+}
+
+type RoomInfo struct {
+	ID         string       `json:"id"`
+	Locked     bool         `json:"locked"`
+	Seq        uint64       `json:"seq"`
+	Players    []ClientInfo `json:"players"`
+	Spectators []ClientInfo `json:"spectators"`
+}
+
+type ClientInfo struct {
+	ID          uuid.UUID         `json:"id"`
+	UserID      uuid.UUID         `json:"user_id"`
+	Rooms       []string          `json:"rooms"`
+	ConnectedAt time.Time         `json:"connected_at"`
+	LastSeen    time.Time         `json:"last_seen"`
+	RTTMs       int64             `json:"rtt_ms,omitempty"`
+	Quality     ConnectionQuality `json:"quality,omitempty"`
+	QueueDepth  int               `json:"queue_depth"`
+	Protocol    string            `json:"protocol,omitempty"`
+}
+
+type kickData struct {
+	ClientID uuid.UUID `json:"client_id"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// ListRooms describes the rooms with members on this instance.
+func (h *Hub) ListRooms() []RoomInfo {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	rooms := make([]RoomInfo, 0, len(h.rooms))
+	for _, room := range h.rooms {
+		room.mutex.RLock()
+		info := RoomInfo{
+			ID:         room.ID,
+			Locked:     room.locked,
+			Seq:        room.seq,
+			Players:    []ClientInfo{},
+			Spectators: []ClientInfo{},
+		}
+		for clientID, client := range room.Clients {
+			if room.Spectators[clientID] {
+				info.Spectators = append(info.Spectators, client.info())
+			} else {
+				info.Players = append(info.Players, client.info())
+			}
+		}
+		room.mutex.RUnlock()
+		rooms = append(rooms, info)
+	}
+
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].ID < rooms[j].ID })
+	return rooms
+}
+
+// ListClients describes the connections on this instance, optionally only
+// those of one user.
+func (h *Hub) ListClients(userID uuid.UUID) []ClientInfo {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	clients := make([]ClientInfo, 0, len(h.clients))
+	for _, client := range h.clients {
+		if userID == uuid.Nil || client.UserID == userID {
+			clients = append(clients, client.info())
+		}
+	}
+
+	sort.Slice(clients, func(i, j int) bool { return clients[i].ConnectedAt.Before(clients[j].ConnectedAt) })
+	return clients
+}
+
+// RoomChat returns the room's buffered chat messages sent by any of the
+// given users, oldest first. Only rooms with members on this instance have
+// a buffer.
+func (h *Hub) RoomChat(roomID string, senders ...uuid.UUID) []json.RawMessage {
+	h.mutex.RLock()
+	room, exists := h.rooms[roomID]
+	h.mutex.RUnlock()
+	if !exists {
+		return nil
+	}
+
+	var chat []json.RawMessage
+	for _, m := range room.recentMessages(roomHistorySize, MessageTypeChatMessage) {
+		for _, sender := range senders {
+			if m.sender == sender {
+				chat = append(chat, json.RawMessage(m.message))
+				break
+			}
+		}
+	}
+	return chat
+}
+
+func (h *Hub) GetClient(clientID uuid.UUID) (ClientInfo, bool) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	client, exists := h.clients[clientID]
+	if !exists {
+		return ClientInfo{}, false
+	}
+	return client.info(), true
+}
+
+// DisconnectClient closes a connection. It reports whether the client was
+// connected to this instance; otherwise the request is forwarded to the
+// other instances.
+func (h *Hub) DisconnectClient(clientID uuid.UUID, reason string) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.kickClient(clientID, reason) {
+		return true
+	}
+
+	data, err := json.Marshal(kickData{ClientID: clientID, Reason: reason})
+	if err != nil {
+		log.Printf("Error marshaling kick: %v", err)
+		return false
+	}
+	h.publish(BackplaneMessage{
+		Type:    MessageTypeKick,
+		Message: data,
+	})
+	return false
+}
+
+func (h *Hub) deliverKick(msg BackplaneMessage) {
+	var data kickData
+	if err := json.Unmarshal(msg.Message, &data); err != nil {
+		log.Printf("Error unmarshaling kick: %v", err)
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.kickClient(data.ClientID, data.Reason)
+}
+
+// kickClient tells a client why it is being removed and closes it. The
+// caller must hold the hub lock.
+func (h *Hub) kickClient(clientID uuid.UUID, reason string) bool {
+	client, exists := h.clients[clientID]
+	if !exists {
+		return false
+	}
+
+	data, err := json.Marshal(ErrorData{Error: errorKicked, Message: reason})
+	if err == nil {
+		if notice, err := json.Marshal(Message{
+			Type:      MessageTypeError,
+			PlayerID:  client.UserID,
+			Data:      data,
+			Timestamp: time.Now(),
+		}); err == nil {
+			client.enqueue(MessageTypeError, notice)
+		}
+	}
+
+	log.Printf("Kicking client %s (User: %s): %s", client.ID, client.UserID, reason)
+	client.mutex.Lock()
+	client.closeFrame = websocket.FormatCloseMessage(closeKicked, errorKicked)
+	client.mutex.Unlock()
+	h.removeClient(client)
+	return true
+}
+
+// Announce sends a system announcement to every connection on every
+// instance.
+func (h *Hub) Announce(text string, level AnnouncementLevel) error {
+	data, err := json.Marshal(AnnouncementData{Message: text, Level: level})
+	if err != nil {
+		return err
+	}
+
+	messageBytes, err := json.Marshal(Message{
+		Type:      MessageTypeAnnouncement,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	h.deliverToAll(MessageTypeAnnouncement, messageBytes)
+	h.publish(BackplaneMessage{
+		Type:    MessageTypeAnnouncement,
+		Message: messageBytes,
+	})
+	return nil
+}
+
+// deliverToAll sends a message to every local connection. The caller must
+// hold the hub lock.
+func (h *Hub) deliverToAll(msgType MessageType, messageBytes []byte) {
+	for _, client := range h.clients {
+		client.enqueue(msgType, messageBytes)
+	}
+}
+
+func (c *Client) info() ClientInfo {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	rooms := make([]string, 0, len(c.Rooms))
+	for roomID := range c.Rooms {
+		rooms = append(rooms, roomID)
+	}
+	sort.Strings(rooms)
+
+	protocol := c.transport
+	if c.Conn != nil && c.Conn.Subprotocol() != "" {
+		protocol = c.Conn.Subprotocol()
+	}
+
+	return ClientInfo{
+		ID:          c.ID,
+		UserID:      c.UserID,
+		Rooms:       rooms,
+		ConnectedAt: c.connectedAt,
+		LastSeen:    c.LastSeen,
+		RTTMs:       c.rtt.Milliseconds(),
+		Quality:     c.quality,
+		QueueDepth:  c.send.len(),
+		Protocol:    protocol,
+	}
+}
+
+// SetGameTypes must be called before Run. Without it game types enabled or
+// disabled on other instances are only seen once the registry refreshes.
+func (h *Hub) SetGameTypes(gameTypes GameTypeReloader) {
+	h.gameTypes = gameTypes
+}
+
+// GameTypesChanged tells the other instances to reload the enabled game
+// types.
+func (h *Hub) GameTypesChanged() {
+	h.publish(BackplaneMessage{Type: MessageTypeGameTypesChanged})
+}
+
+func (h *Hub) deliverGameTypesChanged() {
+	if h.gameTypes == nil {
+		return
+	}
+	if err := h.gameTypes.Reload(); err != nil {
+		log.Printf("Error reloading game types: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/szaher/vibeboard/backend/internal/auth"
+)
+
+// closeTokenExpired is sent when a connection's credentials expire. Codes in
+// the 4000-4999 range are reserved for applications.
+const closeTokenExpired = 4001
+
+// spectatorMessageTypes are the messages a connection made with a scoped
+// token may send. Rooms it joins are joined as a spectator.
+var spectatorMessageTypes = map[MessageType]bool{
+	MessageTypeJoinRoom:  true,
+	MessageTypeLeaveRoom: true,
+	MessageTypeAck:       true,
+	MessageTypeResend:    true,
+	MessageTypeHeartbeat: true,
+}
+
+type TokenValidator interface {
+	ValidateToken(tokenString string) (*auth.Claims, error)
+}
+
+type AuthRefreshData struct {
+	Token string `json:"token"`
+}
+
+type AuthRefreshedData struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SetTokenValidator must be called before Run. Without it auth_refresh
+// messages are rejected, but connections still expire.
+func (h *Hub) SetTokenValidator(validator TokenValidator) {
+	h.tokens = validator
+}
+
+func (c *Client) handleAuthRefresh(message Message) {
+	var data AuthRefreshData
+	if err := json.Unmarshal(message.Data, &data); err != nil || data.Token == "" || c.Hub.tokens == nil {
+		c.replyError(message, "invalid_token", "")
+		return
+	}
+
+	claims, err := c.Hub.tokens.ValidateToken(data.Token)
+	if err != nil || claims.ExpiresAt == nil {
+		c.replyError(message, "invalid_token", "")
+		return
+	}
+	if claims.UserID != c.UserID {
+		c.replyError(message, "token_user_mismatch", "")
+		return
+	}
+
+	c.mutex.Lock()
+	c.expiresAt = claims.ExpiresAt.Time
+	c.mutex.Unlock()
+
+	payload, err := json.Marshal(AuthRefreshedData{ExpiresAt: claims.ExpiresAt.Time})
+	if err != nil {
+		log.Printf("Error marshaling auth refresh: %v", err)
+		return
+	}
+
+	if err := c.Hub.SendToClient(c.ID, Message{
+		Type:      MessageTypeAuthRefreshed,
+		PlayerID:  c.UserID,
+		Data:      payload,
+		Timestamp: time.Now(),
+	}); err != nil {
+		log.Printf("Error sending auth refresh: %v", err)
+	}
+}
+
+// expireClients disconnects clients whose token has expired. It must be
+// called from Run.
+func (h *Hub) expireClients() {
+	now := time.Now()
+	closeFrame := websocket.FormatCloseMessage(closeTokenExpired, "token_expired")
+
+	h.mutex.RLock()
+	var expired []*Client
+	for _, client := range h.clients {
+		client.mutex.RLock()
+		if !client.expiresAt.IsZero() && now.After(client.expiresAt) {
+			expired = append(expired, client)
+		}
+		client.mutex.RUnlock()
+	}
+	h.mutex.RUnlock()
+
+	for _, client := range expired {
+		log.Printf("Disconnecting client %s (User: %s): token expired", client.ID, client.UserID)
+		client.mutex.Lock()
+		client.closeFrame = closeFrame
+		client.mutex.Unlock()
+		h.unregisterClient(client)
+	}
+}
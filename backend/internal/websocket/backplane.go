@@ -0,0 +1,111 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Backplane relays room broadcasts and user-addressed messages between hub
+// instances so that clients connected to different servers still receive
+// each other's messages.
+type Backplane interface {
+	Publish(msg BackplaneMessage) error
+	Subscribe(deliver func(msg BackplaneMessage))
+	// NextSequence returns the next room sequence number shared by all instances.
+	NextSequence(roomID string) (uint64, error)
+}
+
+// BackplaneMessage is addressed either to a room (RoomID) or to every
+// connection of a user (UserID).
+type BackplaneMessage struct {
+	RoomID  string          `json:"room_id,omitempty"`
+	UserID  uuid.UUID       `json:"user_id,omitempty"`
+	Seq     uint64          `json:"seq,omitempty"`
+	Type    MessageType     `json:"type"`
+	Message json.RawMessage `json:"message"`
+	// SenderID is the user a room message came from, if any
+	SenderID uuid.UUID `json:"sender_id,omitempty"`
+}
+
+type backplaneEnvelope struct {
+	Origin uuid.UUID `json:"origin"`
+	BackplaneMessage
+}
+
+const (
+	backplaneChannel = "websocket:broadcast"
+	roomSequenceKey  = "websocket:room:%s:seq" // room ID
+	roomSequenceTTL  = 24 * time.Hour
+)
+
+type RedisBackplane struct {
+	redisClient *redis.Client
+	instanceID  uuid.UUID
+}
+
+func NewRedisBackplane(redisClient *redis.Client) *RedisBackplane {
+	return &RedisBackplane{
+		redisClient: redisClient,
+		instanceID:  uuid.New(),
+	}
+}
+
+func (b *RedisBackplane) Publish(msg BackplaneMessage) error {
+	payload, err := json.Marshal(backplaneEnvelope{
+		Origin:           b.instanceID,
+		BackplaneMessage: msg,
+	})
+	if err != nil {
+		return err
+	}
+
+	return b.redisClient.Publish(context.Background(), backplaneChannel, payload).Err()
+}
+
+// Subscribe blocks, delivering messages published by other instances.
+func (b *RedisBackplane) Subscribe(deliver func(msg BackplaneMessage)) {
+	pubsub := b.redisClient.Subscribe(context.Background(), backplaneChannel)
+	defer func() {
+		if err := pubsub.Close(); err != nil {
+			log.Printf("Error closing backplane subscription: %v", err)
+		}
+	}()
+
+	log.Printf("Hub backplane subscribed (instance: %s)", b.instanceID)
+
+	for msg := range pubsub.Channel() {
+		var envelope backplaneEnvelope
+		if err := json.Unmarshal([]byte(msg.Payload), &envelope); err != nil {
+			log.Printf("Error unmarshaling backplane message: %v", err)
+			continue
+		}
+
+		if envelope.Origin == b.instanceID {
+			continue
+		}
+
+		deliver(envelope.BackplaneMessage)
+	}
+}
+
+func (b *RedisBackplane) NextSequence(roomID string) (uint64, error) {
+	ctx := context.Background()
+	key := fmt.Sprintf(roomSequenceKey, roomID)
+
+	seq, err := b.redisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment room sequence: %w", err)
+	}
+
+	if err := b.redisClient.Expire(ctx, key, roomSequenceTTL).Err(); err != nil {
+		log.Printf("Error setting room sequence TTL: %v", err)
+	}
+
+	return uint64(seq), nil
+}
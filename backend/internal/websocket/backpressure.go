@@ -0,0 +1,143 @@
+package websocket
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// sendQueueSize is the soft limit on a client's outbound queue. Past it,
+	// droppable messages make room for new ones.
+	sendQueueSize = 256
+	// sendQueueHardLimit bounds the queue even for messages that are never
+	// dropped voluntarily.
+	sendQueueHardLimit = 2 * sendQueueSize
+)
+
+// droppableMessages are shed first when a client falls behind. Clients can
+// recover from losing them: chat gaps are visible through seq and can be
+// resent, and the rest are superseded by the next update.
+var droppableMessages = map[MessageType]bool{
+	MessageTypeChatMessage:       true,
+	MessageTypeSpectatorCount:    true,
+	MessageTypeConnectionQuality: true,
+	MessageTypeHeartbeat:         true,
+	MessageTypeSearching:         true,
+}
+
+type outboundMessage struct {
+	msgType MessageType
+	data    []byte
+}
+
+// sendQueue is a client's outbound buffer. Unlike a channel it can shed
+// old messages and be closed safely while other goroutines are pushing.
+type sendQueue struct {
+	mutex        sync.Mutex
+	messages     []outboundMessage
+	ready        chan struct{}
+	closed       bool
+	stalledSince time.Time
+}
+
+func newSendQueue() *sendQueue {
+	return &sendQueue{ready: make(chan struct{}, 1)}
+}
+
+// push queues a message. dropped reports whether this or an older message
+// was shed, and stalled for how long the queue has been full.
+func (q *sendQueue) push(msgType MessageType, data []byte) (queued, dropped bool, stalled time.Duration) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return false, false, 0
+	}
+
+	if len(q.messages) >= sendQueueSize {
+		if q.stalledSince.IsZero() {
+			q.stalledSince = time.Now()
+		}
+		stalled = time.Since(q.stalledSince)
+
+		if q.dropOldest() {
+			dropped = true
+		} else if droppableMessages[msgType] || len(q.messages) >= sendQueueHardLimit {
+			return false, true, stalled
+		}
+	}
+
+	q.messages = append(q.messages, outboundMessage{msgType: msgType, data: data})
+	q.signal()
+	return true, dropped, stalled
+}
+
+// dropOldest removes the oldest droppable message. The caller must hold the
+// queue lock.
+func (q *sendQueue) dropOldest() bool {
+	for i, m := range q.messages {
+		if droppableMessages[m.msgType] {
+			q.messages = append(q.messages[:i], q.messages[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// pop takes everything queued so far.
+func (q *sendQueue) pop() ([]outboundMessage, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	messages := q.messages
+	q.messages = nil
+	q.stalledSince = time.Time{}
+	return messages, q.closed
+}
+
+func (q *sendQueue) len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.messages)
+}
+
+func (q *sendQueue) close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.closed = true
+	q.signal()
+}
+
+func (q *sendQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// enqueue queues a message for the client without blocking. Clients whose
+// queue stays full for longer than the stall timeout are evicted.
+func (c *Client) enqueue(msgType MessageType, data []byte) bool {
+	queued, dropped, stalled := c.send.push(msgType, data)
+	if dropped {
+		hubMessagesDropped.WithLabelValues(dropReasonSendBufferFull).Inc()
+	}
+	if stalled > c.Hub.cfg.StallTimeout {
+		c.evict()
+	}
+	return queued
+}
+
+// evict disconnects a client that cannot keep up. Callers often hold the hub
+// lock, so the removal goes through Run.
+func (c *Client) evict() {
+	c.evictOnce.Do(func() {
+		log.Printf("Evicting client %s (User: %s): send queue stalled", c.ID, c.UserID)
+		hubClientsEvicted.Inc()
+		go func() {
+			c.Hub.unregister <- c
+		}()
+	})
+}
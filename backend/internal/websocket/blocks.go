@@ -0,0 +1,112 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// BlockStore loads the users a user must not exchange chat with.
+type BlockStore interface {
+	GetBlockRelations(userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type blockUpdateData struct {
+	UserID  uuid.UUID `json:"user_id"`
+	OtherID uuid.UUID `json:"other_id"`
+	Blocked bool      `json:"blocked"`
+}
+
+// SetBlockStore must be called before Run.
+func (h *Hub) SetBlockStore(store BlockStore) {
+	h.blocks = store
+}
+
+// UpdateBlock applies a block or unblock between two users to their
+// connections on every instance.
+func (h *Hub) UpdateBlock(userID, otherID uuid.UUID, blocked bool) {
+	data := blockUpdateData{UserID: userID, OtherID: otherID, Blocked: blocked}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	h.applyBlockUpdate(data)
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshaling block update: %v", err)
+		return
+	}
+	h.publish(BackplaneMessage{
+		Type:    MessageTypeBlockUpdate,
+		Message: payload,
+	})
+}
+
+func (h *Hub) deliverBlockUpdate(msg BackplaneMessage) {
+	var data blockUpdateData
+	if err := json.Unmarshal(msg.Message, &data); err != nil {
+		log.Printf("Error unmarshaling block update: %v", err)
+		return
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	h.applyBlockUpdate(data)
+}
+
+// applyBlockUpdate updates the block lists of both users' local
+// connections. The caller must hold the hub lock.
+func (h *Hub) applyBlockUpdate(data blockUpdateData) {
+	for _, client := range h.clients {
+		switch client.UserID {
+		case data.UserID:
+			client.setBlocked(data.OtherID, data.Blocked)
+		case data.OtherID:
+			client.setBlocked(data.UserID, data.Blocked)
+		}
+	}
+}
+
+// loadBlocks fills in a new connection's block list. Chat may reach the
+// client before it finishes.
+func (h *Hub) loadBlocks(client *Client) {
+	if h.blocks == nil {
+		return
+	}
+
+	userIDs, err := h.blocks.GetBlockRelations(client.UserID)
+	if err != nil {
+		log.Printf("Error loading block list for user %s: %v", client.UserID, err)
+		return
+	}
+	for _, userID := range userIDs {
+		client.setBlocked(userID, true)
+	}
+}
+
+func (c *Client) setBlocked(userID uuid.UUID, blocked bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !blocked {
+		delete(c.blocked, userID)
+		return
+	}
+	if c.blocked == nil {
+		c.blocked = make(map[uuid.UUID]bool)
+	}
+	c.blocked[userID] = true
+}
+
+// hides reports whether a room message must not be delivered to the client
+// because of a block between its sender and the client's user.
+func (c *Client) hides(msgType MessageType, sender uuid.UUID) bool {
+	if msgType != MessageTypeChatMessage || sender == uuid.Nil {
+		return false
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.blocked[sender]
+}
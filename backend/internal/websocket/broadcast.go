@@ -0,0 +1,55 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type broadcastOptions struct {
+	excludeClient uuid.UUID
+}
+
+type BroadcastOption func(*broadcastOptions)
+
+// ExcludeSender skips the connection that sent the message. The sender gets
+// an ack carrying the message's seq instead, so it sees no gap in the room
+// sequence.
+func ExcludeSender(clientID uuid.UUID) BroadcastOption {
+	return func(o *broadcastOptions) {
+		o.excludeClient = clientID
+	}
+}
+
+// ackSender confirms a broadcast to a sender excluded from it. The caller
+// must hold the hub lock.
+func (h *Hub) ackSender(clientID uuid.UUID, roomID string, seq uint64, requestID string) {
+	client, exists := h.clients[clientID]
+	if !exists {
+		return
+	}
+
+	data, err := json.Marshal(AckData{Seq: seq})
+	if err != nil {
+		log.Printf("Error marshaling ack: %v", err)
+		return
+	}
+
+	messageBytes, err := json.Marshal(Message{
+		Type:      MessageTypeAck,
+		RoomID:    roomID,
+		PlayerID:  client.UserID,
+		Data:      data,
+		Seq:       seq,
+		RequestID: requestID,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Error marshaling ack: %v", err)
+		return
+	}
+
+	client.enqueue(MessageTypeAck, messageBytes)
+}
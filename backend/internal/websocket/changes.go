@@ -0,0 +1,101 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// MessageTypeGameChanged tells a room that its game row changed. It is a
+// hint rather than a room message: it is not sequenced or replayed, and
+// clients that already saw the change in a game_update can ignore it.
+const MessageTypeGameChanged MessageType = "game_changed"
+
+// GameChanged refreshes a room held by this instance after its game changed,
+// possibly on another instance, and tells the room's local members. A nil
+// change means changes may have been missed, so every room is refreshed.
+func (h *Hub) GameChanged(change *models.GameChange) {
+	if change == nil {
+		h.mutex.RLock()
+		roomIDs := make([]string, 0, len(h.rooms))
+		for roomID := range h.rooms {
+			roomIDs = append(roomIDs, roomID)
+		}
+		h.mutex.RUnlock()
+
+		for _, roomID := range roomIDs {
+			h.refreshRoomLimits(roomID)
+		}
+		return
+	}
+
+	roomID := change.GameID.String()
+	if !h.refreshRoomLimits(roomID) {
+		return
+	}
+
+	data, err := json.Marshal(change)
+	if err != nil {
+		log.Printf("Error marshaling game change: %v", err)
+		return
+	}
+	messageBytes, err := json.Marshal(Message{
+		Type:      MessageTypeGameChanged,
+		RoomID:    roomID,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	if room, exists := h.rooms[roomID]; exists {
+		h.deliverToRoom(room, MessageTypeGameChanged, uuid.Nil, messageBytes, uuid.Nil)
+	}
+}
+
+// refreshRoomLimits resolves a room's limits again, so seats taken or freed
+// on other instances are reflected here. It reports whether this instance
+// holds the room.
+func (h *Hub) refreshRoomLimits(roomID string) bool {
+	h.mutex.RLock()
+	_, exists := h.rooms[roomID]
+	h.mutex.RUnlock()
+	if !exists {
+		return false
+	}
+	if h.roomLimits == nil {
+		return true
+	}
+
+	// Resolve limits before taking the hub lock since it may hit the database
+	limits := h.roomLimits(roomID)
+
+	h.mutex.Lock()
+	room, exists := h.rooms[roomID]
+	if !exists {
+		h.mutex.Unlock()
+		return false
+	}
+
+	room.mutex.Lock()
+	room.allowedPlayers = make(map[uuid.UUID]bool, len(limits.Players))
+	room.applyLimits(limits)
+	room.mutex.Unlock()
+
+	// Players who lost their seat elsewhere leave the room here too
+	evicted := h.evictUnseated(room)
+	h.mutex.Unlock()
+
+	for _, client := range evicted {
+		client.sendError(roomID, ErrNotSeated.Error())
+	}
+	return true
+}
@@ -0,0 +1,234 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+const maxChatMessageLength = 400
+
+const (
+	// MessageTypeChatDeleted tells a room to hide a chat message
+	MessageTypeChatDeleted MessageType = "chat_deleted"
+	// MessageTypeMuteUpdate is only sent over the backplane, to apply a
+	// mute to a user's connections on other instances
+	MessageTypeMuteUpdate MessageType = "mute_update"
+)
+
+// ChatStore persists room chat and loads mutes.
+type ChatStore interface {
+	CreateChatMessage(message *models.ChatMessage) error
+	GetMutedUntil(userID uuid.UUID) (time.Time, error)
+}
+
+type ChatMessageData struct {
+	ID      uuid.UUID `json:"id,omitempty"`
+	Message string    `json:"message"`
+}
+
+type ChatDeletedData struct {
+	ID uuid.UUID `json:"id"`
+}
+
+type muteUpdateData struct {
+	UserID uuid.UUID `json:"user_id"`
+	Until  time.Time `json:"until"`
+}
+
+// SetChatStore must be called before Run.
+func (h *Hub) SetChatStore(store ChatStore) {
+	h.chat = store
+}
+
+func (c *Client) handleChatMessage(message Message) {
+	// Spectators watch read-only
+	if c.Hub.isSpectator(c.ID, message.RoomID) {
+		c.replyError(message, "spectators_cannot_chat", "")
+		return
+	}
+	if until := c.mutedUntil(); !until.IsZero() {
+		c.replyError(message, "muted", "Muted until "+until.UTC().Format(time.RFC3339))
+		return
+	}
+
+	var data ChatMessageData
+	if err := json.Unmarshal(message.Data, &data); err != nil {
+		c.replyError(message, "invalid_chat_message", "")
+		return
+	}
+	data.Message = strings.TrimSpace(data.Message)
+	if data.Message == "" || len(data.Message) > maxChatMessageLength {
+		c.replyError(message, "invalid_chat_message", "")
+		return
+	}
+
+	data.ID = uuid.New()
+	now := time.Now()
+
+	if store := c.Hub.chat; store != nil && c.Hub.cfg.PersistRoomChat {
+		if err := store.CreateChatMessage(&models.ChatMessage{
+			ID:        data.ID,
+			RoomID:    message.RoomID,
+			SenderID:  c.UserID,
+			Body:      data.Message,
+			CreatedAt: now,
+		}); err != nil {
+			log.Printf("Error saving chat message: %v", err)
+			c.replyError(message, "chat_message_failed", "")
+			return
+		}
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshaling chat message: %v", err)
+		return
+	}
+	message.Data = payload
+	message.Timestamp = now
+
+	c.Hub.BroadcastToRoom(message.RoomID, message, ExcludeSender(c.ID))
+}
+
+// DeleteChatMessage drops a chat message from the room's history on every
+// instance and tells the room to hide it.
+func (h *Hub) DeleteChatMessage(roomID string, messageID uuid.UUID) {
+	payload, err := json.Marshal(ChatDeletedData{ID: messageID})
+	if err != nil {
+		log.Printf("Error marshaling chat deletion: %v", err)
+		return
+	}
+
+	h.mutex.RLock()
+	if room, exists := h.rooms[roomID]; exists {
+		room.forgetChatMessage(messageID)
+	}
+	h.mutex.RUnlock()
+
+	h.BroadcastToRoom(roomID, Message{
+		Type:      MessageTypeChatDeleted,
+		RoomID:    roomID,
+		Data:      payload,
+		Timestamp: time.Now(),
+	})
+}
+
+// forgetRemoteChatMessage applies a chat deletion broadcast by another
+// instance to the room's history.
+func (r *Room) forgetRemoteChatMessage(messageBytes []byte) {
+	var message Message
+	if err := json.Unmarshal(messageBytes, &message); err != nil {
+		return
+	}
+	var data ChatDeletedData
+	if err := json.Unmarshal(message.Data, &data); err != nil {
+		return
+	}
+	r.forgetChatMessage(data.ID)
+}
+
+func (r *Room) forgetChatMessage(messageID uuid.UUID) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	history := r.history[:0]
+	for _, m := range r.history {
+		if m.msgType == MessageTypeChatMessage && chatMessageID(m.message) == messageID {
+			continue
+		}
+		history = append(history, m)
+	}
+	r.history = history
+}
+
+func chatMessageID(messageBytes []byte) uuid.UUID {
+	var message Message
+	if err := json.Unmarshal(messageBytes, &message); err != nil {
+		return uuid.Nil
+	}
+	var data ChatMessageData
+	if err := json.Unmarshal(message.Data, &data); err != nil {
+		return uuid.Nil
+	}
+	return data.ID
+}
+
+// UpdateMute applies a mute, or with a zero until an unmute, to the user's
+// connections on every instance.
+func (h *Hub) UpdateMute(userID uuid.UUID, until time.Time) {
+	data := muteUpdateData{UserID: userID, Until: until}
+
+	h.mutex.RLock()
+	h.applyMuteUpdate(data)
+	h.mutex.RUnlock()
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshaling mute update: %v", err)
+		return
+	}
+	h.publish(BackplaneMessage{
+		Type:    MessageTypeMuteUpdate,
+		Message: payload,
+	})
+}
+
+func (h *Hub) deliverMuteUpdate(msg BackplaneMessage) {
+	var data muteUpdateData
+	if err := json.Unmarshal(msg.Message, &data); err != nil {
+		log.Printf("Error unmarshaling mute update: %v", err)
+		return
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	h.applyMuteUpdate(data)
+}
+
+// applyMuteUpdate sets the mute on the user's local connections. The
+// caller must hold the hub lock.
+func (h *Hub) applyMuteUpdate(data muteUpdateData) {
+	for _, client := range h.clients {
+		if client.UserID == data.UserID {
+			client.setMutedUntil(data.Until)
+		}
+	}
+}
+
+// loadMute applies a new connection's user's current mute, if any.
+func (h *Hub) loadMute(client *Client) {
+	if h.chat == nil {
+		return
+	}
+
+	until, err := h.chat.GetMutedUntil(client.UserID)
+	if err != nil {
+		log.Printf("Error loading mute for user %s: %v", client.UserID, err)
+		return
+	}
+	client.setMutedUntil(until)
+}
+
+func (c *Client) setMutedUntil(until time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.muted = until
+}
+
+// mutedUntil returns when the client's mute ends, or the zero time if it
+// is not muted.
+func (c *Client) mutedUntil() time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if time.Now().Before(c.muted) {
+		return c.muted
+	}
+	return time.Time{}
+}
@@ -0,0 +1,56 @@
+package websocket
+
+import (
+	"encoding/json"
+
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	SubprotocolJSON    = "vibe.json"
+	SubprotocolMsgPack = "vibe.msgpack"
+)
+
+// Codec translates between the hub's internal JSON message encoding and the
+// encoding a client negotiated through the WebSocket subprotocol.
+type Codec interface {
+	FrameType() int
+	Encode(jsonMessage []byte) ([]byte, error)
+	Decode(frame []byte) ([]byte, error)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) FrameType() int { return websocket.TextMessage }
+
+func (jsonCodec) Encode(jsonMessage []byte) ([]byte, error) { return jsonMessage, nil }
+
+func (jsonCodec) Decode(frame []byte) ([]byte, error) { return frame, nil }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) FrameType() int { return websocket.BinaryMessage }
+
+func (msgpackCodec) Encode(jsonMessage []byte) ([]byte, error) {
+	var value interface{}
+	if err := json.Unmarshal(jsonMessage, &value); err != nil {
+		return nil, err
+	}
+	return msgpack.Marshal(value)
+}
+
+func (msgpackCodec) Decode(frame []byte) ([]byte, error) {
+	var value interface{}
+	if err := msgpack.Unmarshal(frame, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+func codecForSubprotocol(subprotocol string) Codec {
+	if subprotocol == SubprotocolMsgPack {
+		return msgpackCodec{}
+	}
+	return jsonCodec{}
+}
@@ -0,0 +1,58 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/lobby"
+)
+
+// MatchConfirmer resolves matches that players must accept before they
+// start.
+type MatchConfirmer interface {
+	AcceptMatch(userID, confirmationID uuid.UUID) error
+	DeclineMatch(userID, confirmationID uuid.UUID) error
+}
+
+type MatchResponseData struct {
+	ConfirmationID uuid.UUID `json:"confirmation_id"`
+}
+
+// SetMatchConfirmer must be called before Run. Without it match_accept and
+// match_decline are rejected.
+func (h *Hub) SetMatchConfirmer(matches MatchConfirmer) {
+	h.matches = matches
+}
+
+func (c *Client) handleMatchConfirm(message Message) {
+	matches := c.Hub.matches
+	if matches == nil {
+		c.replyError(message, "matchmaking_unavailable", "")
+		return
+	}
+
+	var data MatchResponseData
+	if err := json.Unmarshal(message.Data, &data); err != nil {
+		c.replyError(message, errorInvalidData, "")
+		return
+	}
+
+	var err error
+	if message.Type == MessageTypeMatchAccept {
+		err = matches.AcceptMatch(c.UserID, data.ConfirmationID)
+	} else {
+		err = matches.DeclineMatch(c.UserID, data.ConfirmationID)
+	}
+
+	if err != nil {
+		if errors.Is(err, lobby.ErrMatchConfirmationNotFound) {
+			c.replyError(message, err.Error(), "")
+			return
+		}
+		log.Printf("Error handling %s for user %s: %v", message.Type, c.UserID, err)
+		c.replyError(message, "match_confirm_failed", "")
+	}
+}
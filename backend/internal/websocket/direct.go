@@ -0,0 +1,98 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+const maxDirectMessageLength = 400
+
+// DirectMessageStore enforces block lists and optionally persists direct
+// messages.
+type DirectMessageStore interface {
+	IsBlocked(userID, otherUserID uuid.UUID) (bool, error)
+	CreateDirectMessage(message *models.DirectMessage) error
+}
+
+type DirectMessageData struct {
+	ID          uuid.UUID `json:"id,omitempty"`
+	RecipientID uuid.UUID `json:"recipient_id"`
+	Body        string    `json:"body"`
+}
+
+// SetDirectMessageStore must be called before Run.
+func (h *Hub) SetDirectMessageStore(store DirectMessageStore) {
+	h.directMessages = store
+}
+
+func (c *Client) handleDirectMessage(message Message) {
+	var data DirectMessageData
+	if err := json.Unmarshal(message.Data, &data); err != nil {
+		c.replyError(message, "invalid_direct_message", "")
+		return
+	}
+
+	if until := c.mutedUntil(); !until.IsZero() {
+		c.replyError(message, "muted", "Muted until "+until.UTC().Format(time.RFC3339))
+		return
+	}
+
+	data.Body = strings.TrimSpace(data.Body)
+	if data.RecipientID == uuid.Nil || data.RecipientID == c.UserID ||
+		data.Body == "" || len(data.Body) > maxDirectMessageLength {
+		c.replyError(message, "invalid_direct_message", "")
+		return
+	}
+
+	store := c.Hub.directMessages
+	if store != nil {
+		blocked, err := store.IsBlocked(c.UserID, data.RecipientID)
+		if err != nil {
+			log.Printf("Error checking block list: %v", err)
+			c.replyError(message, "direct_message_failed", "")
+			return
+		}
+		if blocked {
+			c.replyError(message, "direct_message_blocked", "")
+			return
+		}
+	}
+
+	data.ID = uuid.New()
+	now := time.Now()
+
+	if store != nil && c.Hub.cfg.PersistDirectMessages {
+		if err := store.CreateDirectMessage(&models.DirectMessage{
+			ID:          data.ID,
+			SenderID:    c.UserID,
+			RecipientID: data.RecipientID,
+			Body:        data.Body,
+			CreatedAt:   now,
+		}); err != nil {
+			log.Printf("Error saving direct message: %v", err)
+			c.replyError(message, "direct_message_failed", "")
+			return
+		}
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshaling direct message: %v", err)
+		return
+	}
+
+	if err := c.Hub.SendToUser(data.RecipientID, Message{
+		Type:      MessageTypeDirectMessage,
+		PlayerID:  c.UserID,
+		Data:      payload,
+		Timestamp: now,
+	}); err != nil {
+		log.Printf("Error sending direct message: %v", err)
+	}
+}
@@ -11,50 +11,147 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+	"github.com/szaher/vibeboard/backend/pkg/config"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow connections from any origin
-	},
-}
+// maxMessageSize bounds incoming frames; it must fit a JWT for auth_refresh
+// and a full-length direct message.
+const maxMessageSize = 2048
 
 type MessageType string
 
 const (
-	MessageTypeJoinRoom     MessageType = "join_room"
-	MessageTypeLeaveRoom    MessageType = "leave_room"
-	MessageTypeGameMove     MessageType = "game_move"
-	MessageTypeGameUpdate   MessageType = "game_update"
-	MessageTypeChatMessage  MessageType = "chat_message"
-	MessageTypePlayerJoined MessageType = "player_joined"
-	MessageTypePlayerLeft   MessageType = "player_left"
-	MessageTypeError        MessageType = "error"
-	MessageTypeHeartbeat    MessageType = "heartbeat"
+	MessageTypeJoinRoom           MessageType = "join_room"
+	MessageTypeLeaveRoom          MessageType = "leave_room"
+	MessageTypeGameMove           MessageType = "game_move"
+	MessageTypeResign             MessageType = "resign"
+	MessageTypeGameUpdate         MessageType = "game_update"
+	MessageTypeChatMessage        MessageType = "chat_message"
+	MessageTypePlayerJoined       MessageType = "player_joined"
+	MessageTypePlayerLeft         MessageType = "player_left"
+	MessageTypeError              MessageType = "error"
+	MessageTypeHeartbeat          MessageType = "heartbeat"
+	MessageTypeAck                MessageType = "ack"
+	MessageTypeResend             MessageType = "resend"
+	MessageTypeSpectatorCount     MessageType = "spectator_count"
+	MessageTypeDirectMessage      MessageType = "direct_message"
+	MessageTypeSessionResumed     MessageType = "session_resumed"
+	MessageTypeAuthRefresh        MessageType = "auth_refresh"
+	MessageTypeAuthRefreshed      MessageType = "auth_refreshed"
+	MessageTypeConnectionQuality  MessageType = "connection_quality"
+	MessageTypeReady              MessageType = "ready"
+	MessageTypeUnready            MessageType = "unready"
+	MessageTypeReadyCheck         MessageType = "ready_check"
+	MessageTypeStart              MessageType = "start"
+	MessageTypeSessionReplaced    MessageType = "session_replaced"
+	MessageTypeKick               MessageType = "kick"
+	MessageTypeBlockUpdate        MessageType = "block_update"
+	MessageTypeAnnouncement       MessageType = "announcement"
+	MessageTypePartyInvite        MessageType = "party_invite"
+	MessageTypePartyAccept        MessageType = "party_accept"
+	MessageTypePartyDecline       MessageType = "party_decline"
+	MessageTypePartyLeave         MessageType = "party_leave"
+	MessageTypePartyQueue         MessageType = "party_queue"
+	MessageTypePartyUpdate        MessageType = "party_update"
+	MessageTypeMatchFound         MessageType = "match_found"
+	MessageTypeQueueJoined        MessageType = "queue_joined"
+	MessageTypeSearching          MessageType = "searching"
+	MessageTypeQueueExpired       MessageType = "queue_expired"
+	MessageTypeMatchConfirm       MessageType = "match_confirm"
+	MessageTypeMatchAccept        MessageType = "match_accept"
+	MessageTypeMatchDecline       MessageType = "match_decline"
+	MessageTypeMatchCancelled     MessageType = "match_cancelled"
+	MessageTypeChallenge          MessageType = "challenge"
+	MessageTypeChallengeAccepted  MessageType = "challenge_accepted"
+	MessageTypeChallengeDeclined  MessageType = "challenge_declined"
+	MessageTypeChallengeExpired   MessageType = "challenge_expired"
+	MessageTypeFriendRequest      MessageType = "friend_request"
+	MessageTypeFriendAccepted     MessageType = "friend_accepted"
+	MessageTypeGameInvite         MessageType = "game_invite"
+	MessageTypeGameInviteAccepted MessageType = "game_invite_accepted"
+	MessageTypeGameInviteDeclined MessageType = "game_invite_declined"
+)
+
+type RoomRole string
+
+const (
+	RoomRolePlayer    RoomRole = "player"
+	RoomRoleSpectator RoomRole = "spectator"
 )
 
+type JoinRoomData struct {
+	Role RoomRole `json:"role,omitempty"`
+}
+
+type SpectatorCountData struct {
+	Spectators int `json:"spectators"`
+}
+
+type ErrorData struct {
+	Error       string      `json:"error"`
+	Message     string      `json:"message,omitempty"`
+	RequestType MessageType `json:"request_type,omitempty"`
+}
+
 type Message struct {
-	Type      MessageType     `json:"type"`
-	RoomID    string          `json:"room_id,omitempty"`
-	PlayerID  uuid.UUID       `json:"player_id"`
-	Data      json.RawMessage `json:"data,omitempty"`
-	Timestamp time.Time       `json:"timestamp"`
+	Type     MessageType     `json:"type"`
+	RoomID   string          `json:"room_id,omitempty"`
+	PlayerID uuid.UUID       `json:"player_id"`
+	Data     json.RawMessage `json:"data,omitempty"`
+	Seq      uint64          `json:"seq,omitempty"`
+	// RequestID is chosen by the client and echoed on replies to the message
+	RequestID string    `json:"request_id,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 type Client struct {
-	ID       uuid.UUID
-	UserID   uuid.UUID
-	Hub      *Hub
-	Conn     *websocket.Conn
-	Send     chan []byte
-	Rooms    map[string]bool
-	LastSeen time.Time
-	mutex    sync.RWMutex
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Hub         *Hub
+	Conn        *websocket.Conn
+	transport   string
+	send        *sendQueue
+	Rooms       map[string]bool
+	LastSeen    time.Time
+	acks        map[string]uint64
+	limiter     *messageLimiter
+	codec       Codec
+	connectedAt time.Time
+	rtt         time.Duration
+	quality     ConnectionQuality
+	// expiresAt is when the client's token expires; zero means never
+	expiresAt time.Time
+	// spectateOnly is set for connections made with a scoped token, which
+	// may only watch rooms
+	spectateOnly bool
+	// closeFrame, when set, is sent instead of an empty close frame
+	closeFrame []byte
+	// blocked holds the users this client's user blocked or was blocked by;
+	// chat between them is not delivered
+	blocked map[uuid.UUID]bool
+	// muted is when the user's mute ends; chat is refused until then
+	muted     time.Time
+	evictOnce sync.Once
+	mutex     sync.RWMutex
 }
 
 type Room struct {
-	ID      string
-	Clients map[uuid.UUID]*Client
+	ID             string
+	Clients        map[uuid.UUID]*Client
+	Spectators     map[uuid.UUID]bool
+	seq            uint64
+	history        []sequencedMessage
+	maxPlayers     int
+	maxSpectators  int
+	locked         bool
+	allowedPlayers map[uuid.UUID]bool
+	// seatsOnly restricts players to allowedPlayers even while unlocked
+	seatsOnly bool
+	// started is set once the room's game is under way; ready checks end
+	started bool
 	mutex   sync.RWMutex
 }
 
@@ -64,22 +161,91 @@ type Hub struct {
 	register   chan *Client
 	unregister chan *Client
 	broadcast  chan []byte
-	mutex      sync.RWMutex
+	backplane  Backplane
+	outbound   chan BackplaneMessage
+	cfg        *config.HubConfig
+	roomLimits RoomLimitsFunc
+	// spectatorAccess is optional; without it anyone may spectate
+	spectatorAccess SpectatorAccessFunc
+	upgrader        websocket.Upgrader
+	// directMessages is optional; without it block lists are not enforced
+	directMessages DirectMessageStore
+	// blocks is optional; without it chat is not filtered by block lists
+	blocks BlockStore
+	// chat is optional; without it room chat is not persisted and mutes
+	// only come from UpdateMute
+	chat        ChatStore
+	resumeStore ResumeStore
+	presence    PresenceStore
+	spectators  SpectatorStore
+	// spectatorRecorder is optional; without it spectator counts are not
+	// recorded on games
+	spectatorRecorder SpectatorRecorder
+	// spectatorsChanged holds rooms whose spectators changed since the last
+	// flush, and spectatorsSettled those from the flush before; see
+	// flushSpectators
+	spectatorsChanged map[string]bool
+	spectatorsSettled map[string]bool
+	tokens            TokenValidator
+	tickets           TicketStore
+	moves             MoveProcessor
+	lifecycle         GameLifecycle
+	readies           ReadyStore
+	parties           PartyCoordinator
+	matches           MatchConfirmer
+	// gameTypes is optional; see SetGameTypes
+	gameTypes    GameTypeReloader
+	shuttingDown bool
+	writers      sync.WaitGroup
+	mutex        sync.RWMutex
 }
 
-func NewHub() *Hub {
+func NewHub(cfg *config.HubConfig) *Hub {
 	return &Hub{
-		clients:    make(map[uuid.UUID]*Client),
-		rooms:      make(map[string]*Room),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		broadcast:  make(chan []byte, 256),
+		cfg:               cfg,
+		clients:           make(map[uuid.UUID]*Client),
+		rooms:             make(map[string]*Room),
+		spectatorsChanged: make(map[string]bool),
+		spectatorsSettled: make(map[string]bool),
+		register:          make(chan *Client),
+		unregister:        make(chan *Client),
+		broadcast:         make(chan []byte, 256),
+		outbound:          make(chan BackplaneMessage, 256),
+		upgrader: websocket.Upgrader{
+			CheckOrigin:       checkOrigin(cfg),
+			Subprotocols:      []string{SubprotocolMsgPack, SubprotocolJSON},
+			EnableCompression: cfg.Compression,
+		},
 	}
 }
 
+// SetBackplane must be called before Run.
+func (h *Hub) SetBackplane(backplane Backplane) {
+	h.backplane = backplane
+}
+
+// SetRoomLimits must be called before Run.
+func (h *Hub) SetRoomLimits(roomLimits RoomLimitsFunc) {
+	h.roomLimits = roomLimits
+}
+
+// SetSpectatorAccess must be called before Run.
+func (h *Hub) SetSpectatorAccess(access SpectatorAccessFunc) {
+	h.spectatorAccess = access
+}
+
 func (h *Hub) Run() {
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
+	spectatorTicker := time.NewTicker(spectatorFlushInterval)
+	defer spectatorTicker.Stop()
+	readyTicker := time.NewTicker(readyCheckInterval)
+	defer readyTicker.Stop()
+
+	if h.backplane != nil {
+		go h.backplane.Subscribe(h.deliverRemote)
+		go h.publishOutbound()
+	}
 
 	for {
 		select {
@@ -94,6 +260,15 @@ func (h *Hub) Run() {
 
 		case <-ticker.C:
 			h.cleanupInactiveClients()
+			h.expireClients()
+			h.refreshPresence()
+			h.refreshSpectators()
+
+		case <-spectatorTicker.C:
+			go h.flushSpectators()
+
+		case <-readyTicker.C:
+			go h.expireReadyChecks()
 		}
 	}
 }
@@ -103,13 +278,33 @@ func (h *Hub) registerClient(client *Client) {
 	defer h.mutex.Unlock()
 
 	h.clients[client.ID] = client
+	h.updateGauges()
+	h.touchPresence(client)
 	log.Printf("Client %s connected (User: %s)", client.ID, client.UserID)
+
+	if h.cfg.SessionPolicy == SessionPolicySingle {
+		h.replaceSessions(client.UserID, client.connectedAt)
+		h.publishSessionReplaced(client)
+	} else {
+		h.joinSiblingRooms(client)
+	}
+
+	// Runs once the hub lock is released
+	go h.resumeSession(client)
+	go h.loadBlocks(client)
+	go h.loadMute(client)
 }
 
 func (h *Hub) unregisterClient(client *Client) {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 
+	h.removeClient(client)
+}
+
+// removeClient drops a client from the hub and closes its send channel. The
+// caller must hold the hub lock.
+func (h *Hub) removeClient(client *Client) {
 	if _, ok := h.clients[client.ID]; ok {
 		// Remove client from all rooms
 		for roomID := range client.Rooms {
@@ -117,7 +312,9 @@ func (h *Hub) unregisterClient(client *Client) {
 		}
 
 		delete(h.clients, client.ID)
-		close(client.Send)
+		client.send.close()
+		h.updateGauges()
+		h.removePresence(client)
 		log.Printf("Client %s disconnected (User: %s)", client.ID, client.UserID)
 	}
 }
@@ -127,16 +324,27 @@ func (h *Hub) broadcastMessage(message []byte) {
 	defer h.mutex.RUnlock()
 
 	for _, client := range h.clients {
-		select {
-		case client.Send <- message:
-		default:
-			close(client.Send)
-			delete(h.clients, client.ID)
-		}
+		client.enqueue("", message)
 	}
 }
 
-func (h *Hub) JoinRoom(clientID uuid.UUID, roomID string) error {
+func (h *Hub) JoinRoom(clientID uuid.UUID, roomID string, role RoomRole) error {
+	// Resolve limits and access before taking the hub lock since they may
+	// hit the database
+	h.mutex.RLock()
+	_, roomExists := h.rooms[roomID]
+	joining, clientExists := h.clients[clientID]
+	h.mutex.RUnlock()
+
+	if role == RoomRoleSpectator && clientExists && h.spectatorAccess != nil && !h.spectatorAccess(roomID, joining.UserID) {
+		return ErrSpectatingNotAllowed
+	}
+
+	var limits RoomLimits
+	if !roomExists && h.roomLimits != nil {
+		limits = h.roomLimits(roomID)
+	}
+
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 
@@ -148,27 +356,60 @@ func (h *Hub) JoinRoom(clientID uuid.UUID, roomID string) error {
 	room, exists := h.rooms[roomID]
 	if !exists {
 		room = &Room{
-			ID:      roomID,
-			Clients: make(map[uuid.UUID]*Client),
+			ID:             roomID,
+			Clients:        make(map[uuid.UUID]*Client),
+			Spectators:     make(map[uuid.UUID]bool),
+			allowedPlayers: make(map[uuid.UUID]bool),
 		}
+		room.applyLimits(limits)
 		h.rooms[roomID] = room
+		h.updateGauges()
 	}
 
 	room.mutex.Lock()
+	if err := room.admit(client, role); err != nil {
+		isEmpty := len(room.Clients) == 0
+		room.mutex.Unlock()
+		if isEmpty {
+			delete(h.rooms, roomID)
+			h.updateGauges()
+		}
+		return err
+	}
 	room.Clients[clientID] = client
+	if role == RoomRoleSpectator {
+		room.Spectators[clientID] = true
+	} else {
+		delete(room.Spectators, clientID)
+	}
 	room.mutex.Unlock()
 
 	client.mutex.Lock()
 	client.Rooms[roomID] = true
 	client.mutex.Unlock()
 
+	if h.cfg.SessionPolicy != SessionPolicySingle {
+		h.addSiblings(client, room, role)
+	}
+
+	// Give late joiners the recent conversation and game state
+	h.sendRoomHistory(client, room)
+	h.sendPlayerQualities(client, room)
+
 	// Notify other clients in the room
-	h.broadcastToRoom(roomID, Message{
-		Type:      MessageTypePlayerJoined,
-		RoomID:    roomID,
-		PlayerID:  client.UserID,
-		Timestamp: time.Now(),
-	})
+	if role == RoomRoleSpectator {
+		h.touchSpectator(roomID, clientID)
+		h.spectatorsChanged[roomID] = true
+	} else {
+		// Runs once the hub lock is released
+		go h.BroadcastToRoom(roomID, Message{
+			Type:      MessageTypePlayerJoined,
+			RoomID:    roomID,
+			PlayerID:  client.UserID,
+			Data:      client.presenceData(),
+			Timestamp: time.Now(),
+		})
+	}
 
 	return nil
 }
@@ -182,7 +423,17 @@ func (h *Hub) LeaveRoom(clientID uuid.UUID, roomID string) error {
 		return fmt.Errorf("client not found")
 	}
 
-	h.removeClientFromRoom(client, roomID)
+	if h.cfg.SessionPolicy == SessionPolicySingle {
+		h.removeClientFromRoom(client, roomID)
+		return nil
+	}
+
+	// Room membership is shared by all of the user's devices
+	for _, other := range h.clients {
+		if other.UserID == client.UserID {
+			h.removeClientFromRoom(other, roomID)
+		}
+	}
 	return nil
 }
 
@@ -193,59 +444,248 @@ func (h *Hub) removeClientFromRoom(client *Client, roomID string) {
 	}
 
 	room.mutex.Lock()
+	_, wasMember := room.Clients[client.ID]
 	delete(room.Clients, client.ID)
+	wasSpectator := room.Spectators[client.ID]
+	delete(room.Spectators, client.ID)
 	isEmpty := len(room.Clients) == 0
+	// The player is still present if another of their devices is in the room
+	stillPlaying := false
+	for id, other := range room.Clients {
+		if other.UserID == client.UserID && !room.Spectators[id] {
+			stillPlaying = true
+		}
+	}
 	room.mutex.Unlock()
 
 	client.mutex.Lock()
 	delete(client.Rooms, roomID)
 	client.mutex.Unlock()
 
+	if !wasMember {
+		return
+	}
+
 	// Notify other clients in the room
-	h.broadcastToRoom(roomID, Message{
-		Type:      MessageTypePlayerLeft,
-		RoomID:    roomID,
-		PlayerID:  client.UserID,
-		Timestamp: time.Now(),
-	})
+	if wasSpectator {
+		h.removeSpectator(roomID, client.ID)
+		h.spectatorsChanged[roomID] = true
+	} else if !stillPlaying {
+		// Runs once the hub lock is released
+		go h.BroadcastToRoom(roomID, Message{
+			Type:      MessageTypePlayerLeft,
+			RoomID:    roomID,
+			PlayerID:  client.UserID,
+			Timestamp: time.Now(),
+		})
+	}
 
 	// Remove room if empty
 	if isEmpty {
 		delete(h.rooms, roomID)
+		h.updateGauges()
 	}
 }
 
-func (h *Hub) BroadcastToRoom(roomID string, message Message) {
+func (h *Hub) isSpectator(clientID uuid.UUID, roomID string) bool {
 	h.mutex.RLock()
 	defer h.mutex.RUnlock()
-	h.broadcastToRoom(roomID, message)
-}
 
-func (h *Hub) broadcastToRoom(roomID string, message Message) {
 	room, exists := h.rooms[roomID]
 	if !exists {
+		return false
+	}
+
+	room.mutex.RLock()
+	defer room.mutex.RUnlock()
+	return room.Spectators[clientID]
+}
+
+// BroadcastToRoom sends a message to the room's members on every instance.
+// It must not be called with the hub lock held. A message whose shared
+// sequence number cannot be allocated is dropped rather than numbered from
+// a local counter that other instances do not share.
+func (h *Hub) BroadcastToRoom(roomID string, message Message, opts ...BroadcastOption) {
+	seq, err := h.sharedSequence(roomID)
+	if err != nil {
+		hubMessagesDropped.WithLabelValues(dropReasonSequenceUnavailable).Inc()
+		log.Printf("Error getting sequence for room %s, dropping %s message: %v", roomID, message.Type, err)
 		return
 	}
 
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	h.broadcastToRoom(roomID, message, seq, opts...)
+}
+
+// broadcastToRoom sends a message numbered seq, or the room's next local
+// sequence number if seq is 0. The caller must hold the hub lock.
+func (h *Hub) broadcastToRoom(roomID string, message Message, seq uint64, opts ...BroadcastOption) {
+	var options broadcastOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	room := h.rooms[roomID]
+	if seq == 0 && room != nil {
+		seq = room.nextSequence()
+	}
+	message.Seq = seq
+
+	// The request ID only means something to the sender
+	requestID := message.RequestID
+	message.RequestID = ""
+
 	messageBytes, err := json.Marshal(message)
 	if err != nil {
 		log.Printf("Error marshaling message: %v", err)
 		return
 	}
 
+	// Members of the room may also be connected to other instances
+	h.publish(BackplaneMessage{
+		RoomID:   roomID,
+		Seq:      message.Seq,
+		Type:     message.Type,
+		Message:  messageBytes,
+		SenderID: message.PlayerID,
+	})
+
+	if room == nil {
+		return
+	}
+
+	room.record(message.Seq, message.Type, message.PlayerID, messageBytes)
+	h.deliverToRoom(room, message.Type, message.PlayerID, messageBytes, options.excludeClient)
+
+	if options.excludeClient != uuid.Nil {
+		h.ackSender(options.excludeClient, roomID, message.Seq, requestID)
+	}
+}
+
+// deliverToRoom sends a message from sender to the room's local members,
+// except the given client if it is set.
+func (h *Hub) deliverToRoom(room *Room, msgType MessageType, sender uuid.UUID, messageBytes []byte, exclude uuid.UUID) {
+	timer := prometheus.NewTimer(hubBroadcastDuration)
+	defer timer.ObserveDuration()
+
 	room.mutex.RLock()
 	defer room.mutex.RUnlock()
 
-	for _, client := range room.Clients {
-		select {
-		case client.Send <- messageBytes:
-		default:
-			close(client.Send)
-			delete(room.Clients, client.ID)
+	for clientID, client := range room.Clients {
+		if clientID != exclude && !client.hides(msgType, sender) {
+			client.enqueue(msgType, messageBytes)
 		}
 	}
 }
 
+// publish queues a message for other instances without blocking the
+// caller, which usually holds the hub lock.
+func (h *Hub) publish(msg BackplaneMessage) {
+	if h.backplane == nil {
+		return
+	}
+
+	select {
+	case h.outbound <- msg:
+		hubBackplaneQueueDepth.Set(float64(len(h.outbound)))
+	default:
+		hubMessagesDropped.WithLabelValues(dropReasonBackplaneQueueFull).Inc()
+		log.Printf("Backplane queue full, dropping %s message", msg.Type)
+	}
+}
+
+func (h *Hub) publishOutbound() {
+	for msg := range h.outbound {
+		hubBackplaneQueueDepth.Set(float64(len(h.outbound)))
+		if err := h.backplane.Publish(msg); err != nil {
+			log.Printf("Error publishing to backplane: %v", err)
+		}
+	}
+}
+
+func (h *Hub) deliverRemote(msg BackplaneMessage) {
+	switch msg.Type {
+	case MessageTypeSessionReplaced:
+		h.deliverSessionReplaced(msg)
+		return
+	case MessageTypeKick:
+		h.deliverKick(msg)
+		return
+	case MessageTypeBlockUpdate:
+		h.deliverBlockUpdate(msg)
+		return
+	case MessageTypeMuteUpdate:
+		h.deliverMuteUpdate(msg)
+		return
+	case MessageTypeSpectate:
+		h.deliverSpectate(msg)
+		return
+	case MessageTypeGameTypesChanged:
+		h.deliverGameTypesChanged()
+		return
+	case MessageTypeUnseat:
+		h.deliverUnseat(msg)
+		return
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	if msg.Type == MessageTypeAnnouncement {
+		h.deliverToAll(msg.Type, msg.Message)
+		return
+	}
+
+	if msg.UserID != uuid.Nil {
+		h.deliverToUser(msg.UserID, msg.Type, msg.Message)
+		return
+	}
+
+	room, exists := h.rooms[msg.RoomID]
+	if !exists {
+		return
+	}
+
+	if msg.Type == MessageTypeChatDeleted {
+		room.forgetRemoteChatMessage(msg.Message)
+	}
+	room.record(msg.Seq, msg.Type, msg.SenderID, msg.Message)
+	h.deliverToRoom(room, msg.Type, msg.SenderID, msg.Message, uuid.Nil)
+}
+
+// deliverToUser sends a message to every local connection of a user. The
+// caller must hold the hub lock.
+func (h *Hub) deliverToUser(userID uuid.UUID, msgType MessageType, messageBytes []byte) int {
+	delivered := 0
+	for _, client := range h.clients {
+		if client.UserID == userID && client.enqueue(msgType, messageBytes) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+// SendToUser delivers a message to all of a user's connections on every
+// instance.
+func (h *Hub) SendToUser(userID uuid.UUID, message Message) error {
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	h.mutex.RLock()
+	h.deliverToUser(userID, message.Type, messageBytes)
+	h.publish(BackplaneMessage{
+		UserID:  userID,
+		Type:    message.Type,
+		Message: messageBytes,
+	})
+	h.mutex.RUnlock()
+
+	return nil
+}
+
 func (h *Hub) SendToClient(clientID uuid.UUID, message Message) error {
 	h.mutex.RLock()
 	client, exists := h.clients[clientID]
@@ -260,12 +700,10 @@ func (h *Hub) SendToClient(clientID uuid.UUID, message Message) error {
 		return err
 	}
 
-	select {
-	case client.Send <- messageBytes:
-		return nil
-	default:
-		return fmt.Errorf("client send channel is full")
+	if !client.enqueue(message.Type, messageBytes) {
+		return fmt.Errorf("client send queue is full")
 	}
+	return nil
 }
 
 func (h *Hub) GetRoomClients(roomID string) []uuid.UUID {
@@ -302,41 +740,74 @@ func (h *Hub) cleanupInactiveClients() {
 
 		if now.Sub(lastSeen) > timeout {
 			log.Printf("Cleaning up inactive client: %s", clientID)
-			h.unregister <- client
+			// Run is the only reader of unregister, so remove directly
+			h.removeClient(client)
 		}
 	}
 }
 
 func (h *Hub) HandleWebSocket(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	userID, ok := h.authenticate(c)
+	if !ok {
 		return
 	}
 
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if h.ShuttingDown() {
+		apierror.Respond(c, http.StatusServiceUnavailable, "shutting_down", "Server is shutting down")
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade failed: %v", err)
 		return
 	}
 
-	clientID := uuid.New()
-	client := &Client{
-		ID:       clientID,
-		UserID:   userID.(uuid.UUID),
-		Hub:      h,
-		Conn:     conn,
-		Send:     make(chan []byte, 256),
-		Rooms:    make(map[string]bool),
-		LastSeen: time.Now(),
+	if h.cfg.Compression {
+		if err := conn.SetCompressionLevel(h.cfg.CompressionLevel); err != nil {
+			log.Printf("Invalid compression level %d: %v", h.cfg.CompressionLevel, err)
+		}
 	}
 
+	client := h.newClient(c, userID, codecForSubprotocol(conn.Subprotocol()))
+	client.Conn = conn
+	client.transport = TransportWebSocket
+
 	client.Hub.register <- client
 
+	h.writers.Add(1)
 	go client.writePump()
 	go client.readPump()
 }
 
+func (h *Hub) newClient(c *gin.Context, userID uuid.UUID, codec Codec) *Client {
+	client := &Client{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Hub:         h,
+		send:        newSendQueue(),
+		Rooms:       make(map[string]bool),
+		LastSeen:    time.Now(),
+		connectedAt: time.Now(),
+		acks:        make(map[string]uint64),
+		limiter:     newMessageLimiter(h.cfg),
+		codec:       codec,
+	}
+	if expiresAt, ok := c.Get("tokenExpiresAt"); ok {
+		client.expiresAt = expiresAt.(time.Time)
+	}
+	client.spectateOnly = c.GetString("scope") != ""
+	return client
+}
+
+// ShuttingDown reports whether Shutdown has been called, after which the
+// hub accepts no new connections.
+func (h *Hub) ShuttingDown() bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.shuttingDown
+}
+
 func (c *Client) readPump() {
 	defer func() {
 		c.Hub.unregister <- c
@@ -345,22 +816,23 @@ func (c *Client) readPump() {
 		}
 	}()
 
-	c.Conn.SetReadLimit(512)
+	c.Conn.SetReadLimit(maxMessageSize)
 	if err := c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second)); err != nil {
 		log.Printf("Error setting read deadline: %v", err)
 	}
-	c.Conn.SetPongHandler(func(string) error {
+	c.Conn.SetPongHandler(func(payload string) error {
 		if err := c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second)); err != nil {
 			log.Printf("Error setting read deadline: %v", err)
 		}
 		c.mutex.Lock()
 		c.LastSeen = time.Now()
 		c.mutex.Unlock()
+		c.handlePong(payload)
 		return nil
 	})
 
 	for {
-		_, messageBytes, err := c.Conn.ReadMessage()
+		_, frame, err := c.Conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error: %v", err)
@@ -368,84 +840,181 @@ func (c *Client) readPump() {
 			break
 		}
 
-		c.mutex.Lock()
-		c.LastSeen = time.Now()
-		c.mutex.Unlock()
-
-		var message Message
-		if err := json.Unmarshal(messageBytes, &message); err != nil {
-			log.Printf("Error unmarshaling message: %v", err)
-			continue
+		if !c.receive(frame) {
+			return
 		}
+	}
+}
+
+// receive handles one frame from the client. It returns false if the client
+// must be disconnected.
+func (c *Client) receive(frame []byte) bool {
+	c.mutex.Lock()
+	c.LastSeen = time.Now()
+	c.mutex.Unlock()
+
+	// Malformed messages still count against the rate limit
+	var message Message
+	messageBytes, decodeErr := c.codec.Decode(frame)
+	if decodeErr == nil {
+		decodeErr = json.Unmarshal(messageBytes, &message)
+	}
 
-		message.PlayerID = c.UserID
-		message.Timestamp = time.Now()
+	hubMessagesReceived.WithLabelValues(messageTypeLabel(message.Type)).Inc()
+
+	switch c.limiter.check(message.Type) {
+	case rateLimitDrop:
+		hubMessagesDropped.WithLabelValues(dropReasonRateLimited).Inc()
+		return true
+	case rateLimitWarn:
+		hubMessagesDropped.WithLabelValues(dropReasonRateLimited).Inc()
+		c.replyError(message, "rate_limited", "")
+		return true
+	case rateLimitDisconnect:
+		log.Printf("Disconnecting client %s (User: %s) for flooding", c.ID, c.UserID)
+		c.replyError(message, "rate_limit_exceeded", "")
+		return false
+	}
+
+	if decodeErr != nil {
+		c.replyError(Message{}, errorMalformedMessage, decodeErr.Error())
+		return true
+	}
+
+	if err := validateMessage(message); err != nil {
+		c.replyError(message, err.code, err.detail)
+		return true
+	}
 
-		c.handleMessage(message)
+	if c.spectateOnly && !spectatorMessageTypes[message.Type] {
+		c.replyError(message, "insufficient_scope", "")
+		return true
 	}
+
+	message.PlayerID = c.UserID
+	message.Timestamp = time.Now()
+
+	c.handleMessage(message)
+	return true
 }
 
 func (c *Client) writePump() {
-	ticker := time.NewTicker(54 * time.Second)
+	ticker := time.NewTicker(pingPeriod)
 	defer func() {
 		ticker.Stop()
 		if err := c.Conn.Close(); err != nil {
 			log.Printf("Error closing connection: %v", err)
 		}
+		c.Hub.writers.Done()
 	}()
 
 	for {
 		select {
-		case message, ok := <-c.Send:
+		case <-c.send.ready:
+			messages, closed := c.send.pop()
 			if err := c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second)); err != nil {
 				return
 			}
-			if !ok {
-				if err := c.Conn.WriteMessage(websocket.CloseMessage, []byte{}); err != nil {
+			if err := c.writeMessages(messages); err != nil {
+				return
+			}
+			if closed {
+				c.mutex.RLock()
+				closeFrame := c.closeFrame
+				c.mutex.RUnlock()
+				if err := c.Conn.WriteMessage(websocket.CloseMessage, closeFrame); err != nil {
 					log.Printf("Error writing close message: %v", err)
 				}
 				return
 			}
 
-			w, err := c.Conn.NextWriter(websocket.TextMessage)
-			if err != nil {
+		case <-ticker.C:
+			if err := c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second)); err != nil {
 				return
 			}
-			if _, err := w.Write(message); err != nil {
+			if err := c.Conn.WriteMessage(websocket.PingMessage, pingPayload()); err != nil {
 				return
 			}
+		}
+	}
+}
 
-			n := len(c.Send)
-			for i := 0; i < n; i++ {
-				if _, err := w.Write([]byte{'\n'}); err != nil {
-					return
-				}
-				if _, err := w.Write(<-c.Send); err != nil {
-					return
-				}
-			}
+func (c *Client) writeMessages(messages []outboundMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
 
-			if err := w.Close(); err != nil {
-				return
+	// Binary frames can't be newline-batched, so send one message per frame
+	if c.codec.FrameType() == websocket.BinaryMessage {
+		for _, m := range messages {
+			if err := c.writeEncoded(m.data); err != nil {
+				return err
 			}
+		}
+		return nil
+	}
 
-		case <-ticker.C:
-			if err := c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second)); err != nil {
-				return
-			}
-			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				return
+	size := 0
+	for _, m := range messages {
+		size += len(m.data)
+	}
+	c.Conn.EnableWriteCompression(c.shouldCompress(size))
+
+	w, err := c.Conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return err
+	}
+	for i, m := range messages {
+		if i > 0 {
+			if _, err := w.Write([]byte{'\n'}); err != nil {
+				return err
 			}
 		}
+		if _, err := w.Write(m.data); err != nil {
+			return err
+		}
 	}
+	return w.Close()
+}
+
+func (c *Client) writeEncoded(message []byte) error {
+	frame, err := c.codec.Encode(message)
+	if err != nil {
+		log.Printf("Error encoding message: %v", err)
+		return nil
+	}
+	c.Conn.EnableWriteCompression(c.shouldCompress(len(frame)))
+	return c.Conn.WriteMessage(c.codec.FrameType(), frame)
+}
+
+// shouldCompress skips compression for small frames, where the deflate
+// overhead outweighs the savings. It is a no-op unless the client
+// negotiated permessage-deflate.
+func (c *Client) shouldCompress(size int) bool {
+	return c.Hub.cfg.Compression && size >= c.Hub.cfg.CompressionThreshold
 }
 
 func (c *Client) handleMessage(message Message) {
 	switch message.Type {
 	case MessageTypeJoinRoom:
 		if message.RoomID != "" {
-			if err := c.Hub.JoinRoom(c.ID, message.RoomID); err != nil {
-				log.Printf("Error joining room: %v", err)
+			var data JoinRoomData
+			if len(message.Data) > 0 {
+				if err := json.Unmarshal(message.Data, &data); err != nil {
+					log.Printf("Error unmarshaling join data: %v", err)
+					return
+				}
+			}
+			if c.spectateOnly {
+				data.Role = RoomRoleSpectator
+			}
+			if err := c.Hub.JoinRoom(c.ID, message.RoomID, data.Role); err != nil {
+				switch err {
+				case ErrRoomFull, ErrRoomLocked, ErrNotSeated, ErrSpectatorsFull, ErrSpectatingNotAllowed:
+					c.replyError(message, err.Error(), "")
+				default:
+					log.Printf("Error joining room: %v", err)
+				}
 			}
 		}
 
@@ -457,28 +1026,97 @@ func (c *Client) handleMessage(message Message) {
 		}
 
 	case MessageTypeGameMove:
-		// Forward game move to room
 		if message.RoomID != "" {
-			c.Hub.BroadcastToRoom(message.RoomID, message)
+			c.handleGameMove(message)
+		}
+
+	case MessageTypeResign:
+		if message.RoomID != "" {
+			c.handleResign(message)
 		}
 
 	case MessageTypeChatMessage:
-		// Forward chat message to room
 		if message.RoomID != "" {
-			c.Hub.BroadcastToRoom(message.RoomID, message)
+			c.handleChatMessage(message)
+		}
+
+	case MessageTypeDirectMessage:
+		c.handleDirectMessage(message)
+
+	case MessageTypeAck:
+		if message.RoomID != "" {
+			c.handleAck(message)
+		}
+
+	case MessageTypeResend:
+		if message.RoomID != "" {
+			c.handleResend(message)
+		}
+
+	case MessageTypeReady, MessageTypeUnready:
+		if message.RoomID != "" {
+			c.handleReady(message, message.Type == MessageTypeReady)
 		}
 
+	case MessageTypeAuthRefresh:
+		c.handleAuthRefresh(message)
+
+	case MessageTypePartyInvite, MessageTypePartyAccept, MessageTypePartyDecline,
+		MessageTypePartyLeave, MessageTypePartyQueue:
+		c.handleParty(message)
+
+	case MessageTypeMatchAccept, MessageTypeMatchDecline:
+		c.handleMatchConfirm(message)
+
 	case MessageTypeHeartbeat:
-		// Respond with heartbeat
+		// Respond with heartbeat, echoing the data so clients can time it
 		response := Message{
 			Type:      MessageTypeHeartbeat,
 			PlayerID:  c.UserID,
+			Data:      message.Data,
+			RequestID: message.RequestID,
 			Timestamp: time.Now(),
 		}
 		responseBytes, _ := json.Marshal(response)
-		c.Send <- responseBytes
+		c.enqueue(MessageTypeHeartbeat, responseBytes)
 
 	default:
 		log.Printf("Unknown message type: %s", message.Type)
 	}
 }
+
+func (c *Client) sendError(roomID string, errorCode string) {
+	c.sendErrorMessage(Message{
+		Type:   MessageTypeError,
+		RoomID: roomID,
+	}, ErrorData{Error: errorCode})
+}
+
+// replyError rejects a message from the client, echoing its request ID so
+// the client can tell which request failed.
+func (c *Client) replyError(request Message, errorCode string, detail string) {
+	c.sendErrorMessage(Message{
+		Type:      MessageTypeError,
+		RoomID:    request.RoomID,
+		RequestID: request.RequestID,
+	}, ErrorData{
+		Error:       errorCode,
+		Message:     detail,
+		RequestType: request.Type,
+	})
+}
+
+func (c *Client) sendErrorMessage(message Message, errorData ErrorData) {
+	data, err := json.Marshal(errorData)
+	if err != nil {
+		log.Printf("Error marshaling error message: %v", err)
+		return
+	}
+
+	message.PlayerID = c.UserID
+	message.Data = data
+	message.Timestamp = time.Now()
+	if err := c.Hub.SendToClient(c.ID, message); err != nil {
+		log.Printf("Error sending error message: %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// pingPeriod drives both keepalive and RTT sampling, so it is shorter than
+// keepalive alone would need.
+const pingPeriod = 20 * time.Second
+
+const (
+	goodRTT = 150 * time.Millisecond
+	fairRTT = 400 * time.Millisecond
+
+	// rttSmoothing weights new samples in the moving average
+	rttSmoothing = 0.3
+)
+
+type ConnectionQuality string
+
+const (
+	ConnectionQualityGood ConnectionQuality = "good"
+	ConnectionQualityFair ConnectionQuality = "fair"
+	ConnectionQualityPoor ConnectionQuality = "poor"
+)
+
+type ConnectionQualityData struct {
+	RTTMs   int64             `json:"rtt_ms"`
+	Quality ConnectionQuality `json:"quality"`
+}
+
+func qualityFor(rtt time.Duration) ConnectionQuality {
+	switch {
+	case rtt <= goodRTT:
+		return ConnectionQualityGood
+	case rtt <= fairRTT:
+		return ConnectionQualityFair
+	default:
+		return ConnectionQualityPoor
+	}
+}
+
+// pingPayload stamps pings with the send time; clients echo it in the pong.
+func pingPayload() []byte {
+	return []byte(strconv.FormatInt(time.Now().UnixNano(), 10))
+}
+
+// handlePong updates the client's RTT and tells its rooms when the connection
+// quality changes.
+func (c *Client) handlePong(payload string) {
+	sentAt, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		return
+	}
+	sample := time.Since(time.Unix(0, sentAt))
+	if sample < 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	if c.rtt == 0 {
+		c.rtt = sample
+	} else {
+		c.rtt = time.Duration(rttSmoothing*float64(sample) + (1-rttSmoothing)*float64(c.rtt))
+	}
+	quality := qualityFor(c.rtt)
+	changed := quality != c.quality
+	c.quality = quality
+
+	rooms := make([]string, 0, len(c.Rooms))
+	for roomID := range c.Rooms {
+		rooms = append(rooms, roomID)
+	}
+	c.mutex.Unlock()
+
+	if !changed {
+		return
+	}
+
+	message, ok := c.connectionQualityMessage("")
+	if !ok {
+		return
+	}
+	for _, roomID := range rooms {
+		if c.Hub.isSpectator(c.ID, roomID) {
+			continue
+		}
+		message.RoomID = roomID
+		c.Hub.BroadcastToRoom(roomID, message)
+	}
+}
+
+func (c *Client) connectionQuality() (ConnectionQualityData, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.quality == "" {
+		return ConnectionQualityData{}, false
+	}
+	return ConnectionQualityData{RTTMs: c.rtt.Milliseconds(), Quality: c.quality}, true
+}
+
+func (c *Client) connectionQualityMessage(roomID string) (Message, bool) {
+	quality, ok := c.connectionQuality()
+	if !ok {
+		return Message{}, false
+	}
+
+	data, err := json.Marshal(quality)
+	if err != nil {
+		log.Printf("Error marshaling connection quality: %v", err)
+		return Message{}, false
+	}
+
+	return Message{
+		Type:      MessageTypeConnectionQuality,
+		RoomID:    roomID,
+		PlayerID:  c.UserID,
+		Data:      data,
+		Timestamp: time.Now(),
+	}, true
+}
+
+// presenceData describes a player for player_joined; it is empty until the
+// first RTT sample arrives.
+func (c *Client) presenceData() json.RawMessage {
+	quality, ok := c.connectionQuality()
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(quality)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// sendPlayerQualities tells a client joining a room how well the players
+// already in it are connected. The hub lock must be held.
+func (h *Hub) sendPlayerQualities(client *Client, room *Room) {
+	room.mutex.RLock()
+	others := make(map[uuid.UUID]*Client, len(room.Clients))
+	for id, other := range room.Clients {
+		if id != client.ID && !room.Spectators[id] {
+			others[id] = other
+		}
+	}
+	room.mutex.RUnlock()
+
+	for _, other := range others {
+		message, ok := other.connectionQualityMessage(room.ID)
+		if !ok {
+			continue
+		}
+
+		messageBytes, err := json.Marshal(message)
+		if err != nil {
+			log.Printf("Error marshaling connection quality: %v", err)
+			continue
+		}
+
+		client.enqueue(MessageTypeConnectionQuality, messageBytes)
+	}
+}
@@ -0,0 +1,209 @@
+package websocket
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/database"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+var (
+	ErrRoomFull   = errors.New("room_full")
+	ErrRoomLocked = errors.New("room_locked")
+	// ErrNotSeated means the room's game has not seated the user, who may
+	// only spectate
+	ErrNotSeated      = errors.New("not_seated")
+	ErrSpectatorsFull = errors.New("spectators_full")
+	// ErrSpectatingNotAllowed means the room's game is private or only
+	// open to its players' friends
+	ErrSpectatingNotAllowed = errors.New("spectating_not_allowed")
+)
+
+// maxPlayersPerGame limits rooms until their game is known, which sets the
+// limit to its seats.
+const maxPlayersPerGame = 2
+
+type RoomLimits struct {
+	MaxPlayers    int // 0 means unlimited
+	MaxSpectators int // 0 means unlimited
+	Locked        bool
+	// SeatsOnly restricts players to Players before the room is locked too
+	SeatsOnly bool
+	Players   []uuid.UUID // users allowed to join a locked room as players
+	Started   bool        // the room's game is under way
+}
+
+// RoomLimitsFunc resolves the limits for a room when it is first created on
+// this instance.
+type RoomLimitsFunc func(roomID string) RoomLimits
+
+// GameRoomLimits derives room limits from the game whose ID is the room ID.
+// Only users seated in the game may join as players, and rooms for games
+// whose seats are filled are locked to them.
+func GameRoomLimits(db *database.DB, maxSpectators int) RoomLimitsFunc {
+	return func(roomID string) RoomLimits {
+		limits := RoomLimits{
+			MaxPlayers:    maxPlayersPerGame,
+			MaxSpectators: maxSpectators,
+		}
+
+		gameID, err := uuid.Parse(roomID)
+		if err != nil {
+			return limits
+		}
+
+		game, err := db.GetGame(gameID)
+		if err != nil {
+			return limits
+		}
+
+		limits.MaxPlayers = game.Type.Seats()
+		limits.SeatsOnly = true
+		limits.Players = game.PlayerIDs()
+		limits.Locked = game.Status != models.GameStatusWaiting || game.IsFull()
+		limits.Started = game.Status != models.GameStatusWaiting
+
+		return limits
+	}
+}
+
+// SpectatorAccessFunc reports whether a user may join a room as a spectator.
+type SpectatorAccessFunc func(roomID string, userID uuid.UUID) bool
+
+// GameSpectatorAccess lets users watch the game whose ID is the room ID if
+// its visibility allows them to. Rooms that are not games are open.
+func GameSpectatorAccess(db *database.DB) SpectatorAccessFunc {
+	return func(roomID string, userID uuid.UUID) bool {
+		gameID, err := uuid.Parse(roomID)
+		if err != nil {
+			return true
+		}
+
+		game, err := db.GetGame(gameID)
+		if err != nil {
+			return err == sql.ErrNoRows
+		}
+
+		allowed, err := db.CanWatchGame(game, userID)
+		if err != nil {
+			log.Printf("Error checking whether %s may watch game %s: %v", userID, gameID, err)
+			return false
+		}
+		return allowed
+	}
+}
+
+func (r *Room) applyLimits(limits RoomLimits) {
+	r.maxPlayers = limits.MaxPlayers
+	r.maxSpectators = limits.MaxSpectators
+	r.locked = limits.Locked
+	r.seatsOnly = limits.SeatsOnly
+	r.started = limits.Started
+	for _, playerID := range limits.Players {
+		r.allowedPlayers[playerID] = true
+	}
+}
+
+// admit checks whether a client may join the room in the given role. The
+// caller must hold the room lock.
+func (r *Room) admit(client *Client, role RoomRole) error {
+	if _, member := r.Clients[client.ID]; member {
+		return nil
+	}
+
+	if role == RoomRoleSpectator {
+		if r.maxSpectators > 0 && len(r.Spectators) >= r.maxSpectators {
+			return ErrSpectatorsFull
+		}
+		return nil
+	}
+
+	if r.locked && !r.allowedPlayers[client.UserID] {
+		return ErrRoomLocked
+	}
+	if r.seatsOnly && !r.allowedPlayers[client.UserID] {
+		return ErrNotSeated
+	}
+	if r.locked {
+		return nil
+	}
+
+	players := make(map[uuid.UUID]bool)
+	for clientID, c := range r.Clients {
+		if !r.Spectators[clientID] {
+			players[c.UserID] = true
+		}
+	}
+	if r.maxPlayers > 0 && !players[client.UserID] && len(players) >= r.maxPlayers {
+		return ErrRoomFull
+	}
+
+	return nil
+}
+
+// LockRoom restricts the player seats of a room to the given users, e.g.
+// once a match has started. Other players are taken out of the room;
+// spectators may still join.
+func (h *Hub) LockRoom(roomID string, players []uuid.UUID) {
+	h.mutex.Lock()
+	room, exists := h.rooms[roomID]
+	if !exists {
+		// The limits resolver locks rooms created after the match starts
+		h.mutex.Unlock()
+		return
+	}
+
+	room.mutex.Lock()
+	room.locked = true
+	room.allowedPlayers = make(map[uuid.UUID]bool, len(players))
+	for _, playerID := range players {
+		room.allowedPlayers[playerID] = true
+	}
+	room.mutex.Unlock()
+
+	evicted := h.evictUnseated(room)
+	h.mutex.Unlock()
+
+	for _, client := range evicted {
+		client.sendError(roomID, ErrNotSeated.Error())
+	}
+}
+
+// evictUnseated takes connections whose user may no longer play out of the
+// room and returns them, to be told once the hub lock is released.
+// Spectators stay. The caller must hold the hub lock.
+func (h *Hub) evictUnseated(room *Room) []*Client {
+	room.mutex.RLock()
+	var evicted []*Client
+	if room.locked || room.seatsOnly {
+		for clientID, client := range room.Clients {
+			if !room.Spectators[clientID] && !room.allowedPlayers[client.UserID] {
+				evicted = append(evicted, client)
+			}
+		}
+	}
+	room.mutex.RUnlock()
+
+	for _, client := range evicted {
+		h.removeClientFromRoom(client, room.ID)
+	}
+	return evicted
+}
+
+func (h *Hub) UnlockRoom(roomID string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	room, exists := h.rooms[roomID]
+	if !exists {
+		return
+	}
+
+	room.mutex.Lock()
+	defer room.mutex.Unlock()
+	room.locked = false
+}
@@ -0,0 +1,136 @@
+package websocket
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+// newTestHub returns a hub whose rooms take their limits from limits as it
+// stands when they are resolved.
+func newTestHub(limits *RoomLimits) *Hub {
+	hub := NewHub(&config.HubConfig{StallTimeout: time.Minute})
+	hub.SetRoomLimits(func(string) RoomLimits { return *limits })
+	return hub
+}
+
+func connectTestClient(hub *Hub, userID uuid.UUID) *Client {
+	client := &Client{
+		ID:     uuid.New(),
+		UserID: userID,
+		Hub:    hub,
+		send:   newSendQueue(),
+		Rooms:  make(map[string]bool),
+		acks:   make(map[string]uint64),
+	}
+	hub.mutex.Lock()
+	hub.clients[client.ID] = client
+	hub.mutex.Unlock()
+	return client
+}
+
+func inRoom(hub *Hub, roomID string, client *Client) bool {
+	hub.mutex.RLock()
+	defer hub.mutex.RUnlock()
+	room, exists := hub.rooms[roomID]
+	if !exists {
+		return false
+	}
+	room.mutex.RLock()
+	defer room.mutex.RUnlock()
+	_, member := room.Clients[client.ID]
+	return member
+}
+
+// received reports whether the client was sent an error with the code.
+func received(client *Client, errorCode string) bool {
+	client.send.mutex.Lock()
+	defer client.send.mutex.Unlock()
+	for _, message := range client.send.messages {
+		if message.msgType == MessageTypeError && bytes.Contains(message.data, []byte(`"`+errorCode+`"`)) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestJoinRoomRequiresSeat(t *testing.T) {
+	seated, stranger := uuid.New(), uuid.New()
+	limits := RoomLimits{MaxPlayers: 2, SeatsOnly: true, Players: []uuid.UUID{seated}}
+	hub := newTestHub(&limits)
+	roomID := uuid.NewString()
+
+	player := connectTestClient(hub, seated)
+	if err := hub.JoinRoom(player.ID, roomID, RoomRolePlayer); err != nil {
+		t.Fatalf("seated player: %v", err)
+	}
+
+	// A free seat in a waiting game is no player slot for those not in it
+	other := connectTestClient(hub, stranger)
+	if err := hub.JoinRoom(other.ID, roomID, RoomRolePlayer); err != ErrNotSeated {
+		t.Errorf("unseated player: err = %v, want %v", err, ErrNotSeated)
+	}
+	if err := hub.JoinRoom(other.ID, roomID, RoomRoleSpectator); err != nil {
+		t.Errorf("unseated spectator: %v", err)
+	}
+}
+
+func TestUnseatedPlayersAreEvicted(t *testing.T) {
+	tests := []struct {
+		name string
+		// unseat takes the second player's seat away
+		unseat func(hub *Hub, limits *RoomLimits, roomID string, players []uuid.UUID)
+	}{
+		{
+			name: "LockRoom",
+			unseat: func(hub *Hub, _ *RoomLimits, roomID string, players []uuid.UUID) {
+				hub.LockRoom(roomID, players)
+			},
+		},
+		{
+			name: "refreshRoomLimits",
+			unseat: func(hub *Hub, limits *RoomLimits, roomID string, players []uuid.UUID) {
+				limits.Locked = true
+				limits.Players = players
+				hub.refreshRoomLimits(roomID)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creator, leaver, joiner := uuid.New(), uuid.New(), uuid.New()
+			limits := RoomLimits{MaxPlayers: 2, SeatsOnly: true, Players: []uuid.UUID{creator, leaver}}
+			hub := newTestHub(&limits)
+			roomID := uuid.NewString()
+
+			kept := connectTestClient(hub, creator)
+			evicted := connectTestClient(hub, leaver)
+			watcher := connectTestClient(hub, uuid.New())
+			for _, join := range []struct {
+				client *Client
+				role   RoomRole
+			}{{kept, RoomRolePlayer}, {evicted, RoomRolePlayer}, {watcher, RoomRoleSpectator}} {
+				if err := hub.JoinRoom(join.client.ID, roomID, join.role); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tt.unseat(hub, &limits, roomID, []uuid.UUID{creator, joiner})
+
+			if !inRoom(hub, roomID, kept) || !inRoom(hub, roomID, watcher) {
+				t.Error("seated player or spectator was removed")
+			}
+			if inRoom(hub, roomID, evicted) {
+				t.Error("player without a seat is still in the room")
+			}
+			if !received(evicted, ErrNotSeated.Error()) {
+				t.Errorf("evicted player was not sent %s", ErrNotSeated)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package websocket
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	dropReasonSendBufferFull      = "send_buffer_full"
+	dropReasonBackplaneQueueFull  = "backplane_queue_full"
+	dropReasonRateLimited         = "rate_limited"
+	dropReasonSequenceUnavailable = "sequence_unavailable"
+)
+
+var (
+	hubConnectedClients = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "vibearcade",
+		Subsystem: "hub",
+		Name:      "connected_clients",
+		Help:      "Number of WebSocket connections on this instance.",
+	})
+
+	hubActiveRooms = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "vibearcade",
+		Subsystem: "hub",
+		Name:      "active_rooms",
+		Help:      "Number of rooms with at least one local member.",
+	})
+
+	hubBackplaneQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "vibearcade",
+		Subsystem: "hub",
+		Name:      "backplane_queue_depth",
+		Help:      "Messages waiting to be published to the backplane.",
+	})
+
+	hubMessagesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "vibearcade",
+		Subsystem: "hub",
+		Name:      "messages_received_total",
+		Help:      "Messages received from clients by message type.",
+	}, []string{"type"})
+
+	hubMessagesDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "vibearcade",
+		Subsystem: "hub",
+		Name:      "messages_dropped_total",
+		Help:      "Messages that could not be delivered, by reason.",
+	}, []string{"reason"})
+
+	hubClientsEvicted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "vibearcade",
+		Subsystem: "hub",
+		Name:      "clients_evicted_total",
+		Help:      "Clients disconnected because their send queue stalled.",
+	})
+
+	hubBroadcastDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "vibearcade",
+		Subsystem: "hub",
+		Name:      "broadcast_duration_seconds",
+		Help:      "Time spent fanning a room message out to local members.",
+		Buckets:   prometheus.ExponentialBuckets(0.00005, 4, 8),
+	})
+)
+
+// updateGauges refreshes the connection and room gauges. The caller must
+// hold the hub lock.
+func (h *Hub) updateGauges() {
+	hubConnectedClients.Set(float64(len(h.clients)))
+	hubActiveRooms.Set(float64(len(h.rooms)))
+}
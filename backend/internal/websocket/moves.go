@@ -0,0 +1,194 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/game"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+type MoveProcessor interface {
+	ProcessMove(gameID, playerID uuid.UUID, move json.RawMessage) (*models.Game, error)
+	Resign(gameID, playerID uuid.UUID) (*models.Game, error)
+	Adjudicate(gameID uuid.UUID, decision models.AdjudicationDecision, winnerID *uuid.UUID) (*models.Game, error)
+}
+
+// ErrMovesUnavailable is returned when no MoveProcessor is set.
+var ErrMovesUnavailable = errors.New("moves_unavailable")
+
+type GameUpdateData struct {
+	GameState   json.RawMessage   `json:"game_state"`
+	Status      models.GameStatus `json:"status"`
+	CurrentTurn *uuid.UUID        `json:"current_turn,omitempty"`
+	WinnerID    *uuid.UUID        `json:"winner_id,omitempty"`
+	Move        json.RawMessage   `json:"move"`
+	// MoveCount lets clients tell whether a game_changed is news to them
+	MoveCount int `json:"move_count"`
+	// Reason is set when the game ended without a move
+	Reason string `json:"reason,omitempty"`
+	// EndReason is how the game ended, once it has
+	EndReason models.EndReason `json:"end_reason,omitempty"`
+}
+
+// SetMoveProcessor must be called before Run. Without it game moves are
+// rejected, since relaying them unchecked would let clients forge state.
+func (h *Hub) SetMoveProcessor(processor MoveProcessor) {
+	h.moves = processor
+}
+
+func (c *Client) handleGameMove(message Message) {
+	if c.Hub.isSpectator(c.ID, message.RoomID) {
+		c.replyError(message, "spectators_cannot_move", "")
+		return
+	}
+
+	if c.Hub.moves == nil {
+		c.replyError(message, "moves_unavailable", "")
+		return
+	}
+
+	// Game rooms are keyed by game ID
+	gameID, err := uuid.Parse(message.RoomID)
+	if err != nil {
+		c.replyError(message, game.ErrGameNotFound.Error(), "")
+		return
+	}
+
+	updated, err := c.Hub.moves.ProcessMove(gameID, c.UserID, message.Data)
+	if err != nil {
+		switch {
+		case errors.Is(err, game.ErrInvalidMove):
+			c.replyError(message, game.ErrInvalidMove.Error(), err.Error())
+		case errors.Is(err, game.ErrGameNotFound), errors.Is(err, game.ErrGameNotInProgress), errors.Is(err, game.ErrGamePaused), errors.Is(err, game.ErrNotInGame), errors.Is(err, game.ErrGameChanged):
+			c.replyError(message, err.Error(), "")
+		default:
+			log.Printf("Error processing move in game %s: %v", gameID, err)
+			c.replyError(message, "move_failed", "")
+		}
+		return
+	}
+
+	c.Hub.NotifyGameMove(updated, c.UserID, message.Data)
+}
+
+// NotifyGameMove tells a game's room, on every instance, about the player's
+// move.
+func (h *Hub) NotifyGameMove(game *models.Game, playerID uuid.UUID, move json.RawMessage) {
+	data, err := json.Marshal(GameUpdateData{
+		GameState:   game.GameState,
+		Status:      game.Status,
+		CurrentTurn: game.CurrentTurn,
+		WinnerID:    game.WinnerID,
+		Move:        move,
+		MoveCount:   game.MoveCount,
+		EndReason:   game.EndReason,
+	})
+	if err != nil {
+		log.Printf("Error marshaling game update: %v", err)
+		return
+	}
+
+	roomID := game.ID.String()
+	h.BroadcastToRoom(roomID, Message{
+		Type:      MessageTypeGameUpdate,
+		RoomID:    roomID,
+		PlayerID:  playerID,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+}
+
+// handleResign ends the player's game in their opponent's favour and tells
+// the room.
+func (c *Client) handleResign(message Message) {
+	if c.Hub.isSpectator(c.ID, message.RoomID) {
+		c.replyError(message, "spectators_cannot_move", "")
+		return
+	}
+
+	if c.Hub.moves == nil {
+		c.replyError(message, "moves_unavailable", "")
+		return
+	}
+
+	gameID, err := uuid.Parse(message.RoomID)
+	if err != nil {
+		c.replyError(message, game.ErrGameNotFound.Error(), "")
+		return
+	}
+
+	updated, err := c.Hub.moves.Resign(gameID, c.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, game.ErrGameNotFound), errors.Is(err, game.ErrGameNotInProgress), errors.Is(err, game.ErrNotInGame):
+			c.replyError(message, err.Error(), "")
+		default:
+			log.Printf("Error resigning game %s: %v", gameID, err)
+			c.replyError(message, "resign_failed", "")
+		}
+		return
+	}
+
+	c.Hub.NotifyGameEnded(updated, game.EndReasonResigned)
+}
+
+// MakeMove applies a move made outside the game's room, as over the REST
+// API, and tells the room.
+func (h *Hub) MakeMove(gameID, playerID uuid.UUID, move json.RawMessage) (*models.Game, error) {
+	if h.moves == nil {
+		return nil, ErrMovesUnavailable
+	}
+
+	updated, err := h.moves.ProcessMove(gameID, playerID, move)
+	if err != nil {
+		return nil, err
+	}
+
+	h.NotifyGameMove(updated, playerID, move)
+	return updated, nil
+}
+
+// AdjudicateGame settles a game as a moderator decided and tells its room.
+func (h *Hub) AdjudicateGame(gameID uuid.UUID, decision models.AdjudicationDecision, winnerID *uuid.UUID) (*models.Game, error) {
+	if h.moves == nil {
+		return nil, ErrMovesUnavailable
+	}
+
+	updated, err := h.moves.Adjudicate(gameID, decision, winnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	h.NotifyGameEnded(updated, game.EndReasonAdjudicated)
+	return updated, nil
+}
+
+// NotifyGameEnded tells a game's room, on every instance, that the game
+// ended without a move.
+func (h *Hub) NotifyGameEnded(game *models.Game, reason string) {
+	data, err := json.Marshal(GameUpdateData{
+		GameState: game.GameState,
+		Status:    game.Status,
+		WinnerID:  game.WinnerID,
+		MoveCount: game.MoveCount,
+		Reason:    reason,
+		EndReason: game.EndReason,
+	})
+	if err != nil {
+		log.Printf("Error marshaling game update: %v", err)
+		return
+	}
+
+	roomID := game.ID.String()
+	h.BroadcastToRoom(roomID, Message{
+		Type:      MessageTypeGameUpdate,
+		RoomID:    roomID,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+}
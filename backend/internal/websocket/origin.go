@@ -0,0 +1,47 @@
+package websocket
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+// checkOrigin guards against cross-site WebSocket hijacking by only accepting
+// browser upgrades from configured origins.
+func checkOrigin(cfg *config.HubConfig) func(r *http.Request) bool {
+	if cfg.AllowAnyOrigin {
+		log.Println("WARNING: WebSocket origin check disabled (HUB_ALLOW_ANY_ORIGIN)")
+		return func(r *http.Request) bool { return true }
+	}
+
+	allowed := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.AllowedOrigins {
+		allowed[normalizeOrigin(origin)] = true
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			// Non-browser clients don't send an Origin header
+			return true
+		}
+
+		if allowed[normalizeOrigin(origin)] {
+			return true
+		}
+
+		log.Printf("Rejected WebSocket upgrade from origin %q", origin)
+		return false
+	}
+}
+
+func normalizeOrigin(origin string) string {
+	u, err := url.Parse(strings.TrimSpace(origin))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return strings.ToLower(strings.TrimSuffix(origin, "/"))
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
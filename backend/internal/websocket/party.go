@@ -0,0 +1,111 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/lobby"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// PartyCoordinator manages parties of friends that queue together.
+type PartyCoordinator interface {
+	InviteToParty(userID, inviteeID uuid.UUID) error
+	AcceptPartyInvite(userID, partyID uuid.UUID) error
+	DeclinePartyInvite(userID, partyID uuid.UUID) error
+	LeaveParty(userID uuid.UUID) error
+	QueueParty(userID uuid.UUID, gameType models.GameType) error
+}
+
+type PartyInviteRequestData struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+type PartyResponseData struct {
+	PartyID uuid.UUID `json:"party_id"`
+}
+
+type PartyQueueData struct {
+	GameType models.GameType `json:"game_type"`
+}
+
+// SetPartyCoordinator must be called before Run. Without it party messages
+// are rejected.
+func (h *Hub) SetPartyCoordinator(parties PartyCoordinator) {
+	h.parties = parties
+}
+
+// NotifyUser sends an event to every connection of a user, on any instance.
+func (h *Hub) NotifyUser(userID uuid.UUID, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return h.SendToUser(userID, Message{
+		Type:      MessageType(event),
+		PlayerID:  userID,
+		Data:      payload,
+		Timestamp: time.Now(),
+	})
+}
+
+func (c *Client) handleParty(message Message) {
+	parties := c.Hub.parties
+	if parties == nil {
+		c.replyError(message, "parties_unavailable", "")
+		return
+	}
+
+	var err error
+	switch message.Type {
+	case MessageTypePartyInvite:
+		var data PartyInviteRequestData
+		if err := json.Unmarshal(message.Data, &data); err != nil {
+			c.replyError(message, errorInvalidData, "")
+			return
+		}
+		err = parties.InviteToParty(c.UserID, data.UserID)
+
+	case MessageTypePartyAccept, MessageTypePartyDecline:
+		var data PartyResponseData
+		if err := json.Unmarshal(message.Data, &data); err != nil {
+			c.replyError(message, errorInvalidData, "")
+			return
+		}
+		if message.Type == MessageTypePartyAccept {
+			err = parties.AcceptPartyInvite(c.UserID, data.PartyID)
+		} else {
+			err = parties.DeclinePartyInvite(c.UserID, data.PartyID)
+		}
+
+	case MessageTypePartyLeave:
+		err = parties.LeaveParty(c.UserID)
+
+	case MessageTypePartyQueue:
+		var data PartyQueueData
+		if err := json.Unmarshal(message.Data, &data); err != nil {
+			c.replyError(message, errorInvalidData, "")
+			return
+		}
+		err = parties.QueueParty(c.UserID, data.GameType)
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, lobby.ErrPartyNotFound), errors.Is(err, lobby.ErrNotPartyLeader),
+			errors.Is(err, lobby.ErrPartyFull), errors.Is(err, lobby.ErrPartyIncomplete),
+			errors.Is(err, lobby.ErrAlreadyInParty), errors.Is(err, lobby.ErrAlreadyInvited),
+			errors.Is(err, lobby.ErrInviteNotFound), errors.Is(err, lobby.ErrInvalidPartyInvite),
+			errors.Is(err, lobby.ErrPartyInviteBlocked), errors.Is(err, lobby.ErrGameTypeDisabled):
+			c.replyError(message, err.Error(), "")
+		default:
+			log.Printf("Error handling %s for user %s: %v", message.Type, c.UserID, err)
+			c.replyError(message, "party_failed", "")
+		}
+	}
+}
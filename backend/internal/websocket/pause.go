@@ -0,0 +1,63 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// MessageTypePauseUpdate tells a room that its game was paused or resumed,
+// or that a player asked to pause or resume it.
+const MessageTypePauseUpdate MessageType = "pause_update"
+
+// Pause request kinds carried by PauseUpdateData
+const (
+	PauseRequestPause  = "pause"
+	PauseRequestResume = "resume"
+)
+
+// PauseUpdateData describes a game's pause state. Request names what
+// RequestedBy is waiting for the other player to agree to, if anything.
+type PauseUpdateData struct {
+	Paused        bool       `json:"paused"`
+	PausedAt      *time.Time `json:"paused_at,omitempty"`
+	PausedByAdmin bool       `json:"paused_by_admin"`
+	RequestedBy   *uuid.UUID `json:"requested_by,omitempty"`
+	Request       string     `json:"request,omitempty"`
+	TurnStartedAt *time.Time `json:"turn_started_at,omitempty"`
+}
+
+// NotifyPauseChanged tells the game's room about its current pause state.
+func (h *Hub) NotifyPauseChanged(game *models.Game) {
+	update := PauseUpdateData{
+		Paused:        game.PausedAt != nil,
+		PausedAt:      game.PausedAt,
+		PausedByAdmin: game.PausedByAdmin,
+		RequestedBy:   game.PauseRequestedBy,
+		TurnStartedAt: game.TurnStartedAt,
+	}
+	if game.PauseRequestedBy != nil {
+		update.Request = PauseRequestPause
+		if update.Paused {
+			update.Request = PauseRequestResume
+		}
+	}
+
+	data, err := json.Marshal(update)
+	if err != nil {
+		log.Printf("Error marshaling pause update: %v", err)
+		return
+	}
+
+	roomID := game.ID.String()
+	h.BroadcastToRoom(roomID, Message{
+		Type:      MessageTypePauseUpdate,
+		RoomID:    roomID,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+}
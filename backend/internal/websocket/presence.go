@@ -0,0 +1,138 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// presenceKey maps each of a user's connections to when it was last seen
+	presenceKey = "websocket:presence:%s" // user ID
+	// presenceTTL outlives several refreshes, so entries left behind by an
+	// instance that died expire on their own
+	presenceTTL = 2 * time.Minute
+)
+
+// PresenceEntry is a connection's latest sign of life.
+type PresenceEntry struct {
+	UserID   uuid.UUID
+	ClientID uuid.UUID
+	LastSeen time.Time
+}
+
+// PresenceStore shares which users are connected across instances.
+type PresenceStore interface {
+	Touch(entries []PresenceEntry) error
+	Remove(userID, clientID uuid.UUID) error
+}
+
+type RedisPresenceStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisPresenceStore(redisClient *redis.Client) *RedisPresenceStore {
+	return &RedisPresenceStore{redisClient: redisClient}
+}
+
+func (s *RedisPresenceStore) Touch(entries []PresenceEntry) error {
+	ctx := context.Background()
+
+	_, err := s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, entry := range entries {
+			key := fmt.Sprintf(presenceKey, entry.UserID)
+			pipe.HSet(ctx, key, entry.ClientID.String(), entry.LastSeen.Unix())
+			pipe.Expire(ctx, key, presenceTTL)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisPresenceStore) Remove(userID, clientID uuid.UUID) error {
+	return s.redisClient.HDel(context.Background(), fmt.Sprintf(presenceKey, userID), clientID.String()).Err()
+}
+
+// IsConnected reports whether any of the user's connections, on any
+// instance, was seen within the given window.
+func (s *RedisPresenceStore) IsConnected(userID uuid.UUID, within time.Duration) (bool, error) {
+	lastSeen, err := s.redisClient.HVals(context.Background(), fmt.Sprintf(presenceKey, userID)).Result()
+	if err != nil {
+		return false, err
+	}
+
+	cutoff := time.Now().Add(-within).Unix()
+	for _, value := range lastSeen {
+		seen, err := strconv.ParseInt(value, 10, 64)
+		if err == nil && seen >= cutoff {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetPresenceStore must be called before Run.
+func (h *Hub) SetPresenceStore(store PresenceStore) {
+	h.presence = store
+}
+
+// refreshPresence records every local connection's last activity. The hub
+// is only read-locked while taking the snapshot.
+func (h *Hub) refreshPresence() {
+	if h.presence == nil {
+		return
+	}
+
+	h.mutex.RLock()
+	entries := make([]PresenceEntry, 0, len(h.clients))
+	for _, client := range h.clients {
+		client.mutex.RLock()
+		entries = append(entries, PresenceEntry{
+			UserID:   client.UserID,
+			ClientID: client.ID,
+			LastSeen: client.LastSeen,
+		})
+		client.mutex.RUnlock()
+	}
+	h.mutex.RUnlock()
+
+	if len(entries) == 0 {
+		return
+	}
+	go func() {
+		if err := h.presence.Touch(entries); err != nil {
+			log.Printf("Error refreshing presence: %v", err)
+		}
+	}()
+}
+
+func (h *Hub) touchPresence(client *Client) {
+	if h.presence == nil {
+		return
+	}
+
+	entry := PresenceEntry{UserID: client.UserID, ClientID: client.ID, LastSeen: time.Now()}
+	go func() {
+		if err := h.presence.Touch([]PresenceEntry{entry}); err != nil {
+			log.Printf("Error recording presence for user %s: %v", entry.UserID, err)
+		}
+	}()
+}
+
+func (h *Hub) removePresence(client *Client) {
+	if h.presence == nil {
+		return
+	}
+
+	userID, clientID := client.UserID, client.ID
+	go func() {
+		if err := h.presence.Remove(userID, clientID); err != nil {
+			log.Printf("Error removing presence for user %s: %v", userID, err)
+		}
+	}()
+}
@@ -0,0 +1,112 @@
+package websocket
+
+import (
+	"sync"
+	"time"
+
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+type rateLimitAction int
+
+const (
+	rateLimitAllow rateLimitAction = iota
+	rateLimitDrop
+	rateLimitWarn
+	rateLimitDisconnect
+)
+
+const rateLimitViolationWindow = time.Minute
+
+type tokenBucket struct {
+	tokens float64
+	rate   float64
+	burst  float64
+	last   time.Time
+}
+
+func newTokenBucket(limit config.RateLimitConfig, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		tokens: float64(limit.Burst),
+		rate:   limit.Rate,
+		burst:  float64(limit.Burst),
+		last:   now,
+	}
+}
+
+func (b *tokenBucket) allow(now time.Time) bool {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// messageLimiter applies per-message-type token buckets to a single
+// connection and escalates repeated violations. Unknown types share one
+// bucket, so made-up types neither escape the limit nor grow the buckets.
+type messageLimiter struct {
+	cfg         *config.HubConfig
+	buckets     map[MessageType]*tokenBucket
+	violations  int
+	windowStart time.Time
+	mutex       sync.Mutex
+}
+
+func newMessageLimiter(cfg *config.HubConfig) *messageLimiter {
+	return &messageLimiter{
+		cfg:     cfg,
+		buckets: make(map[MessageType]*tokenBucket),
+	}
+}
+
+func (l *messageLimiter) limitFor(msgType MessageType) config.RateLimitConfig {
+	switch msgType {
+	case MessageTypeChatMessage:
+		return l.cfg.ChatRateLimit
+	case MessageTypeGameMove:
+		return l.cfg.MoveRateLimit
+	default:
+		return l.cfg.DefaultRateLimit
+	}
+}
+
+func (l *messageLimiter) check(msgType MessageType) rateLimitAction {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// Unknown types are checked before the message is validated
+	msgType = MessageType(messageTypeLabel(msgType))
+
+	now := time.Now()
+	bucket, exists := l.buckets[msgType]
+	if !exists {
+		bucket = newTokenBucket(l.limitFor(msgType), now)
+		l.buckets[msgType] = bucket
+	}
+
+	if bucket.allow(now) {
+		return rateLimitAllow
+	}
+
+	if now.Sub(l.windowStart) > rateLimitViolationWindow {
+		l.windowStart = now
+		l.violations = 0
+	}
+	l.violations++
+
+	switch {
+	case l.cfg.RateLimitDisconnectAt > 0 && l.violations >= l.cfg.RateLimitDisconnectAt:
+		return rateLimitDisconnect
+	case l.violations == l.cfg.RateLimitWarnAt:
+		return rateLimitWarn
+	default:
+		return rateLimitDrop
+	}
+}
@@ -0,0 +1,28 @@
+package websocket
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/szaher/vibeboard/backend/pkg/config"
+)
+
+func TestMessageLimiterSharesUnknownTypes(t *testing.T) {
+	limiter := newMessageLimiter(&config.HubConfig{
+		DefaultRateLimit: config.RateLimitConfig{Rate: 1, Burst: 5},
+	})
+
+	allowed := 0
+	for i := 0; i < 50; i++ {
+		if limiter.check(MessageType(fmt.Sprintf("made_up_%d", i))) == rateLimitAllow {
+			allowed++
+		}
+	}
+
+	if allowed > 5 {
+		t.Errorf("allowed %d made-up messages, want at most the burst of 5", allowed)
+	}
+	if len(limiter.buckets) != 1 {
+		t.Errorf("limiter holds %d buckets, want 1", len(limiter.buckets))
+	}
+}
@@ -0,0 +1,502 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/szaher/vibeboard/backend/internal/game"
+	"github.com/szaher/vibeboard/backend/internal/models"
+)
+
+// MessageTypeUnseat is only sent over the backplane, to take a user who lost
+// their seat out of a room on every instance.
+const MessageTypeUnseat MessageType = "unseat"
+
+const (
+	// readyCheckKey maps each seated player of a game's ready check to
+	// whether they are ready
+	readyCheckKey = "websocket:ready:%s" // game ID
+	// readyDeadlinesKey orders running ready checks by deadline, in
+	// milliseconds, for the sweep
+	readyDeadlinesKey = "websocket:ready_deadlines"
+	// readyCheckGrace keeps a check past its deadline until the sweep
+	// expires it
+	readyCheckGrace = time.Minute
+)
+
+// readyCheckInterval is how often ready checks past their deadline are
+// expired.
+const readyCheckInterval = time.Second
+
+// GameLifecycle starts lobby games once every seated player is ready.
+type GameLifecycle interface {
+	StartGame(gameID uuid.UUID) (*models.Game, error)
+	ReleaseSeat(gameID, playerID uuid.UUID) (*models.Game, error)
+}
+
+type ReadyCheckData struct {
+	Ready    []uuid.UUID `json:"ready"`
+	Waiting  []uuid.UUID `json:"waiting"`
+	Deadline *time.Time  `json:"deadline,omitempty"`
+}
+
+// ReadyCheck is a game's ready check: each seated player and whether they
+// are ready. Deadline is zero while no one is ready.
+type ReadyCheck struct {
+	Players  map[uuid.UUID]bool
+	Deadline time.Time
+}
+
+// ReadyStore keeps ready checks across instances, so players may ready up
+// on different servers and each check is resolved once.
+type ReadyStore interface {
+	// SetReady records whether the user is ready among the game's seated
+	// players. The first player to ready up starts the countdown, which
+	// stops once no one is ready. When everyone is ready the check ends, and
+	// complete is true for the one caller who should start the game.
+	SetReady(gameID uuid.UUID, players []uuid.UUID, userID uuid.UUID, ready bool, timeout time.Duration) (check *ReadyCheck, complete bool, err error)
+	// Due returns the games whose countdown has run out.
+	Due(now time.Time) ([]uuid.UUID, error)
+	// Expire ends a ready check whose countdown has run out and returns it
+	// as it stood, or nil if it is not due or was already resolved.
+	Expire(gameID uuid.UUID, now time.Time) (*ReadyCheck, error)
+}
+
+type RedisReadyStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisReadyStore(redisClient *redis.Client) *RedisReadyStore {
+	return &RedisReadyStore{redisClient: redisClient}
+}
+
+// setReadyScript records a player's readiness. KEYS are the check and the
+// deadlines; ARGV holds the game, the user, '1' or '0', the deadline and
+// TTL in milliseconds, then the seated players. Players who are no longer
+// seated are dropped from the check. It returns whether the check completed,
+// the check's players and its deadline.
+var setReadyScript = redis.NewScript(`
+local seated = {}
+for i = 6, #ARGV do
+	seated[ARGV[i]] = true
+	redis.call('HSETNX', KEYS[1], ARGV[i], '0')
+end
+redis.call('HSET', KEYS[1], ARGV[2], ARGV[3])
+
+local players = {}
+local total, ready = 0, 0
+local entries = redis.call('HGETALL', KEYS[1])
+for i = 1, #entries, 2 do
+	if seated[entries[i]] then
+		table.insert(players, entries[i])
+		table.insert(players, entries[i + 1])
+		total = total + 1
+		if entries[i + 1] == '1' then
+			ready = ready + 1
+		end
+	else
+		redis.call('HDEL', KEYS[1], entries[i])
+	end
+end
+
+if ready == 0 or ready == total then
+	redis.call('DEL', KEYS[1])
+	redis.call('ZREM', KEYS[2], ARGV[1])
+	if ready == 0 then
+		return {0, players, false}
+	end
+	return {1, players, false}
+end
+
+redis.call('PEXPIRE', KEYS[1], ARGV[5])
+redis.call('ZADD', KEYS[2], 'NX', ARGV[4], ARGV[1])
+return {0, players, redis.call('ZSCORE', KEYS[2], ARGV[1])}
+`)
+
+// expireReadyScript ends a ready check if its deadline has passed. KEYS are
+// the check and the deadlines; ARGV holds the game and the time in
+// milliseconds. It returns the check's players, or nil if it is not due.
+var expireReadyScript = redis.NewScript(`
+local deadline = redis.call('ZSCORE', KEYS[2], ARGV[1])
+if not deadline or tonumber(deadline) > tonumber(ARGV[2]) then
+	return false
+end
+redis.call('ZREM', KEYS[2], ARGV[1])
+local players = redis.call('HGETALL', KEYS[1])
+redis.call('DEL', KEYS[1])
+return players
+`)
+
+func (s *RedisReadyStore) SetReady(gameID uuid.UUID, players []uuid.UUID, userID uuid.UUID, ready bool, timeout time.Duration) (*ReadyCheck, bool, error) {
+	flag := "0"
+	if ready {
+		flag = "1"
+	}
+	args := []interface{}{
+		gameID.String(),
+		userID.String(),
+		flag,
+		time.Now().Add(timeout).UnixMilli(),
+		(timeout + readyCheckGrace).Milliseconds(),
+	}
+	for _, playerID := range players {
+		args = append(args, playerID.String())
+	}
+
+	keys := []string{fmt.Sprintf(readyCheckKey, gameID), readyDeadlinesKey}
+	result, err := setReadyScript.Run(context.Background(), s.redisClient, keys, args...).Slice()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to record ready check: %w", err)
+	}
+	if len(result) != 3 {
+		return nil, false, fmt.Errorf("unexpected ready check reply: %v", result)
+	}
+
+	entries, _ := result[1].([]interface{})
+	check := &ReadyCheck{Players: parseReadyPlayers(entries)}
+	if deadline, ok := result[2].(string); ok {
+		millis, err := strconv.ParseFloat(deadline, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid ready check deadline %q: %w", deadline, err)
+		}
+		check.Deadline = time.UnixMilli(int64(millis))
+	}
+
+	complete, _ := result[0].(int64)
+	return check, complete == 1, nil
+}
+
+func (s *RedisReadyStore) Due(now time.Time) ([]uuid.UUID, error) {
+	members, err := s.redisClient.ZRangeByScore(context.Background(), readyDeadlinesKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due ready checks: %w", err)
+	}
+
+	gameIDs := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		if gameID, err := uuid.Parse(member); err == nil {
+			gameIDs = append(gameIDs, gameID)
+		}
+	}
+	return gameIDs, nil
+}
+
+func (s *RedisReadyStore) Expire(gameID uuid.UUID, now time.Time) (*ReadyCheck, error) {
+	keys := []string{fmt.Sprintf(readyCheckKey, gameID), readyDeadlinesKey}
+	entries, err := expireReadyScript.Run(context.Background(), s.redisClient, keys, gameID.String(), now.UnixMilli()).Slice()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire ready check: %w", err)
+	}
+	return &ReadyCheck{Players: parseReadyPlayers(entries)}, nil
+}
+
+// parseReadyPlayers reads a check's players from alternating user IDs and
+// ready flags.
+func parseReadyPlayers(entries []interface{}) map[uuid.UUID]bool {
+	players := make(map[uuid.UUID]bool, len(entries)/2)
+	for i := 0; i+1 < len(entries); i += 2 {
+		id, _ := entries[i].(string)
+		flag, _ := entries[i+1].(string)
+		if playerID, err := uuid.Parse(id); err == nil {
+			players[playerID] = flag == "1"
+		}
+	}
+	return players
+}
+
+// SetGameLifecycle must be called before Run. Without it ready checks are
+// disabled.
+func (h *Hub) SetGameLifecycle(lifecycle GameLifecycle) {
+	h.lifecycle = lifecycle
+}
+
+// SetReadyStore must be called before Run. Without it ready checks are
+// disabled.
+func (h *Hub) SetReadyStore(store ReadyStore) {
+	h.readies = store
+}
+
+func (c *Client) handleReady(message Message, ready bool) {
+	h := c.Hub
+	if h.lifecycle == nil || h.readies == nil {
+		c.replyError(message, "ready_check_unavailable", "")
+		return
+	}
+
+	gameID, err := uuid.Parse(message.RoomID)
+	if err != nil {
+		c.replyError(message, "not_seated", "")
+		return
+	}
+
+	h.mutex.RLock()
+	room, exists := h.rooms[message.RoomID]
+	h.mutex.RUnlock()
+	if !exists {
+		c.replyError(message, "not_seated", "")
+		return
+	}
+
+	room.mutex.RLock()
+	// Rooms are locked to the seated players once both seats are filled
+	seated := room.locked && room.allowedPlayers[c.UserID] && !room.Spectators[c.ID]
+	started := room.started
+	players := make([]uuid.UUID, 0, len(room.allowedPlayers))
+	for playerID := range room.allowedPlayers {
+		players = append(players, playerID)
+	}
+	room.mutex.RUnlock()
+
+	if !seated {
+		c.replyError(message, "not_seated", "")
+		return
+	}
+	if started {
+		return
+	}
+
+	check, complete, err := h.readies.SetReady(gameID, players, c.UserID, ready, h.cfg.ReadyTimeout)
+	if err != nil {
+		log.Printf("Error updating ready check for game %s: %v", gameID, err)
+		c.replyError(message, "ready_check_failed", "")
+		return
+	}
+
+	h.broadcastReadyCheck(room.ID, check.data())
+
+	if complete {
+		h.startGame(room, gameID)
+	}
+}
+
+// startGame starts the game of a completed ready check. Should it fail, the
+// players ready up again.
+func (h *Hub) startGame(room *Room, gameID uuid.UUID) {
+	started, err := h.lifecycle.StartGame(gameID)
+	if errors.Is(err, game.ErrGameNotWaiting) {
+		return
+	}
+	if err != nil {
+		log.Printf("Error starting game %s: %v", gameID, err)
+		h.broadcastRoomError(room.ID, "start_failed")
+		return
+	}
+
+	room.mutex.Lock()
+	room.started = true
+	room.mutex.Unlock()
+
+	data, err := json.Marshal(GameUpdateData{
+		GameState:   started.GameState,
+		Status:      started.Status,
+		CurrentTurn: started.CurrentTurn,
+	})
+	if err != nil {
+		log.Printf("Error marshaling game start: %v", err)
+		return
+	}
+
+	h.BroadcastToRoom(room.ID, Message{
+		Type:      MessageTypeStart,
+		RoomID:    room.ID,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+}
+
+// expireReadyChecks expires the ready checks whose countdown has run out.
+// Each check is expired by one instance, which need not hold its room.
+func (h *Hub) expireReadyChecks() {
+	if h.lifecycle == nil || h.readies == nil {
+		return
+	}
+
+	now := time.Now()
+	gameIDs, err := h.readies.Due(now)
+	if err != nil {
+		log.Printf("Error getting expired ready checks: %v", err)
+		return
+	}
+
+	for _, gameID := range gameIDs {
+		check, err := h.readies.Expire(gameID, now)
+		if err != nil {
+			log.Printf("Error expiring ready check for game %s: %v", gameID, err)
+			continue
+		}
+		if check != nil {
+			h.expireReadyCheck(gameID, check)
+		}
+	}
+}
+
+// expireReadyCheck unseats the players who did not ready up in time.
+func (h *Hub) expireReadyCheck(gameID uuid.UUID, check *ReadyCheck) {
+	roomID := gameID.String()
+	remaining := ReadyCheckData{
+		Ready:   []uuid.UUID{},
+		Waiting: []uuid.UUID{},
+	}
+	released := false
+
+	for userID, ready := range check.Players {
+		if ready {
+			remaining.Waiting = append(remaining.Waiting, userID)
+			continue
+		}
+
+		updated, err := h.lifecycle.ReleaseSeat(gameID, userID)
+		if errors.Is(err, game.ErrGameNotWaiting) || errors.Is(err, game.ErrNotInGame) {
+			// The game started or the player left meanwhile
+			continue
+		}
+		if err != nil {
+			log.Printf("Error releasing seat for %s in game %s: %v", userID, gameID, err)
+			continue
+		}
+
+		log.Printf("Player %s timed out of the ready check in game %s", userID, gameID)
+		released = true
+		h.unseat(roomID, userID, "ready_timeout")
+
+		if updated.Status == models.GameStatusAbandoned {
+			data, err := json.Marshal(GameUpdateData{Status: updated.Status})
+			if err == nil {
+				h.BroadcastToRoom(roomID, Message{
+					Type:      MessageTypeGameUpdate,
+					RoomID:    roomID,
+					Data:      data,
+					Timestamp: time.Now(),
+				})
+			}
+			return
+		}
+	}
+
+	if released {
+		h.broadcastReadyCheck(roomID, remaining)
+	}
+}
+
+// unseat takes all of a user's connections, on every instance, out of a
+// room whose seat they lost, and tells them why.
+func (h *Hub) unseat(roomID string, userID uuid.UUID, reason string) {
+	data, err := json.Marshal(ErrorData{Error: reason})
+	if err != nil {
+		log.Printf("Error marshaling unseat: %v", err)
+		return
+	}
+
+	h.publish(BackplaneMessage{
+		Type:    MessageTypeUnseat,
+		RoomID:  roomID,
+		UserID:  userID,
+		Message: data,
+	})
+	h.unseatLocal(roomID, userID, reason)
+}
+
+func (h *Hub) deliverUnseat(msg BackplaneMessage) {
+	var data ErrorData
+	if err := json.Unmarshal(msg.Message, &data); err != nil {
+		log.Printf("Error unmarshaling unseat: %v", err)
+		return
+	}
+	h.unseatLocal(msg.RoomID, msg.UserID, data.Error)
+}
+
+// unseatLocal removes the user from the room on this instance and reopens
+// its seats, since the freed seat may be taken by someone else.
+func (h *Hub) unseatLocal(roomID string, userID uuid.UUID, reason string) {
+	h.removeUserFromRoom(roomID, userID, reason)
+	h.UnlockRoom(roomID)
+}
+
+// removeUserFromRoom takes all of a user's connections out of a room and
+// tells them why.
+func (h *Hub) removeUserFromRoom(roomID string, userID uuid.UUID, reason string) {
+	h.mutex.Lock()
+	var removed []*Client
+	if room, exists := h.rooms[roomID]; exists {
+		room.mutex.Lock()
+		delete(room.allowedPlayers, userID)
+		for _, client := range room.Clients {
+			if client.UserID == userID {
+				removed = append(removed, client)
+			}
+		}
+		room.mutex.Unlock()
+	}
+	for _, client := range removed {
+		h.removeClientFromRoom(client, roomID)
+	}
+	h.mutex.Unlock()
+
+	for _, client := range removed {
+		client.sendError(roomID, reason)
+	}
+}
+
+func (h *Hub) broadcastReadyCheck(roomID string, data ReadyCheckData) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshaling ready check: %v", err)
+		return
+	}
+
+	h.BroadcastToRoom(roomID, Message{
+		Type:      MessageTypeReadyCheck,
+		RoomID:    roomID,
+		Data:      payload,
+		Timestamp: time.Now(),
+	})
+}
+
+func (h *Hub) broadcastRoomError(roomID string, errorCode string) {
+	data, err := json.Marshal(ErrorData{Error: errorCode})
+	if err != nil {
+		log.Printf("Error marshaling error message: %v", err)
+		return
+	}
+
+	h.BroadcastToRoom(roomID, Message{
+		Type:      MessageTypeError,
+		RoomID:    roomID,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+}
+
+// data is the ready check as sent to the room.
+func (c *ReadyCheck) data() ReadyCheckData {
+	data := ReadyCheckData{
+		Ready:   []uuid.UUID{},
+		Waiting: []uuid.UUID{},
+	}
+	for userID, ready := range c.Players {
+		if ready {
+			data.Ready = append(data.Ready, userID)
+		} else {
+			data.Waiting = append(data.Waiting, userID)
+		}
+	}
+	if !c.Deadline.IsZero() {
+		deadline := c.Deadline
+		data.Deadline = &deadline
+	}
+	return data
+}
@@ -0,0 +1,173 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Error codes for messages rejected before they reach a handler.
+const (
+	errorMalformedMessage   = "malformed_message"
+	errorUnknownMessageType = "unknown_message_type"
+	errorMissingRoomID      = "missing_room_id"
+	errorInvalidData        = "invalid_data"
+)
+
+// messageSchema describes what a client may send for a message type. Types
+// without a schema are server-to-client only.
+type messageSchema struct {
+	room     bool
+	data     bool
+	validate func(data json.RawMessage) error
+}
+
+var messageSchemas = map[MessageType]messageSchema{
+	MessageTypeJoinRoom:      {room: true, validate: validateJoinRoom},
+	MessageTypeLeaveRoom:     {room: true},
+	MessageTypeGameMove:      {room: true, data: true},
+	MessageTypeResign:        {room: true},
+	MessageTypeChatMessage:   {room: true, data: true},
+	MessageTypeDirectMessage: {data: true, validate: validateDirectMessage},
+	MessageTypeAck:           {room: true, data: true, validate: validateAck},
+	MessageTypeResend:        {room: true, validate: validateResend},
+	MessageTypeHeartbeat:     {},
+	MessageTypeAuthRefresh:   {data: true, validate: validateAuthRefresh},
+	MessageTypeReady:         {room: true},
+	MessageTypeUnready:       {room: true},
+	MessageTypePartyInvite:   {data: true, validate: validatePartyInvite},
+	MessageTypePartyAccept:   {data: true, validate: validatePartyResponse},
+	MessageTypePartyDecline:  {data: true, validate: validatePartyResponse},
+	MessageTypePartyLeave:    {},
+	MessageTypePartyQueue:    {data: true, validate: validatePartyQueue},
+	MessageTypeMatchAccept:   {data: true, validate: validateMatchResponse},
+	MessageTypeMatchDecline:  {data: true, validate: validateMatchResponse},
+}
+
+// schemaError is a validation failure with a human-readable explanation.
+type schemaError struct {
+	code   string
+	detail string
+}
+
+// messageTypeLabel keeps arbitrary client-supplied types out of metric labels.
+func messageTypeLabel(messageType MessageType) string {
+	if _, known := messageSchemas[messageType]; !known {
+		return "unknown"
+	}
+	return string(messageType)
+}
+
+func validateMessage(message Message) *schemaError {
+	schema, known := messageSchemas[message.Type]
+	if !known {
+		return &schemaError{errorUnknownMessageType, fmt.Sprintf("unknown message type %q", message.Type)}
+	}
+	if schema.room && message.RoomID == "" {
+		return &schemaError{errorMissingRoomID, fmt.Sprintf("%s requires room_id", message.Type)}
+	}
+
+	hasData := len(message.Data) > 0 && string(message.Data) != "null"
+	if schema.data && !hasData {
+		return &schemaError{errorInvalidData, fmt.Sprintf("%s requires data", message.Type)}
+	}
+	if hasData && schema.validate != nil {
+		if err := schema.validate(message.Data); err != nil {
+			return &schemaError{errorInvalidData, err.Error()}
+		}
+	}
+	return nil
+}
+
+func validateJoinRoom(data json.RawMessage) error {
+	var join JoinRoomData
+	if err := json.Unmarshal(data, &join); err != nil {
+		return err
+	}
+	switch join.Role {
+	case "", RoomRolePlayer, RoomRoleSpectator:
+		return nil
+	}
+	return fmt.Errorf("unknown role %q", join.Role)
+}
+
+func validateDirectMessage(data json.RawMessage) error {
+	var direct DirectMessageData
+	if err := json.Unmarshal(data, &direct); err != nil {
+		return err
+	}
+	if direct.RecipientID == uuid.Nil {
+		return errors.New("recipient_id is required")
+	}
+	if direct.Body == "" {
+		return errors.New("body is required")
+	}
+	return nil
+}
+
+func validateAck(data json.RawMessage) error {
+	var ack AckData
+	return json.Unmarshal(data, &ack)
+}
+
+func validateResend(data json.RawMessage) error {
+	var resend ResendData
+	return json.Unmarshal(data, &resend)
+}
+
+func validateAuthRefresh(data json.RawMessage) error {
+	var refresh AuthRefreshData
+	if err := json.Unmarshal(data, &refresh); err != nil {
+		return err
+	}
+	if refresh.Token == "" {
+		return errors.New("token is required")
+	}
+	return nil
+}
+
+func validatePartyInvite(data json.RawMessage) error {
+	var invite PartyInviteRequestData
+	if err := json.Unmarshal(data, &invite); err != nil {
+		return err
+	}
+	if invite.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return nil
+}
+
+func validatePartyResponse(data json.RawMessage) error {
+	var response PartyResponseData
+	if err := json.Unmarshal(data, &response); err != nil {
+		return err
+	}
+	if response.PartyID == uuid.Nil {
+		return errors.New("party_id is required")
+	}
+	return nil
+}
+
+func validatePartyQueue(data json.RawMessage) error {
+	var queue PartyQueueData
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return err
+	}
+	if queue.GameType == "" {
+		return errors.New("game_type is required")
+	}
+	return nil
+}
+
+func validateMatchResponse(data json.RawMessage) error {
+	var response MatchResponseData
+	if err := json.Unmarshal(data, &response); err != nil {
+		return err
+	}
+	if response.ConfirmationID == uuid.Nil {
+		return errors.New("confirmation_id is required")
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	roomHistorySize = 100
+	joinHistorySize = 20
+)
+
+type sequencedMessage struct {
+	seq     uint64
+	msgType MessageType
+	sender  uuid.UUID
+	message []byte
+}
+
+type AckData struct {
+	Seq uint64 `json:"seq"`
+}
+
+// ResendData asks for every buffered message after FromSeq. When FromSeq is
+// omitted the client's last acknowledged sequence number is used.
+type ResendData struct {
+	FromSeq *uint64 `json:"from_seq,omitempty"`
+}
+
+type ResendUnavailableData struct {
+	Error     string `json:"error"`
+	OldestSeq uint64 `json:"oldest_seq"`
+	LatestSeq uint64 `json:"latest_seq"`
+}
+
+// sharedSequence returns the room's next sequence number from the backplane,
+// or 0 without one. It is a Redis round trip, so callers must not hold the
+// hub lock.
+func (h *Hub) sharedSequence(roomID string) (uint64, error) {
+	if h.backplane == nil {
+		return 0, nil
+	}
+	return h.backplane.NextSequence(roomID)
+}
+
+// nextSequence returns the room's next local sequence number, used when
+// there is no backplane.
+func (r *Room) nextSequence() uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.seq++
+	return r.seq
+}
+
+func (r *Room) record(seq uint64, msgType MessageType, sender uuid.UUID, message []byte) {
+	if seq == 0 {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if seq > r.seq {
+		r.seq = seq
+	}
+
+	r.history = append(r.history, sequencedMessage{seq: seq, msgType: msgType, sender: sender, message: message})
+	if len(r.history) > roomHistorySize {
+		r.history = r.history[len(r.history)-roomHistorySize:]
+	}
+}
+
+// messagesAfter returns buffered messages with a sequence number greater than
+// seq, and false if some of them have already been evicted.
+func (r *Room) messagesAfter(seq uint64) ([]sequencedMessage, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if seq >= r.seq {
+		return nil, true
+	}
+	if len(r.history) == 0 || r.history[0].seq > seq+1 {
+		return nil, false
+	}
+
+	messages := make([]sequencedMessage, 0, len(r.history))
+	for _, m := range r.history {
+		if m.seq > seq {
+			messages = append(messages, m)
+		}
+	}
+	return messages, true
+}
+
+// recentMessages returns up to limit of the newest buffered messages of the
+// given types, oldest first.
+func (r *Room) recentMessages(limit int, types ...MessageType) []sequencedMessage {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var messages []sequencedMessage
+	for i := len(r.history) - 1; i >= 0 && len(messages) < limit; i-- {
+		for _, t := range types {
+			if r.history[i].msgType == t {
+				messages = append(messages, r.history[i])
+				break
+			}
+		}
+	}
+
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages
+}
+
+func (h *Hub) sendRoomHistory(client *Client, room *Room) {
+	for _, m := range room.recentMessages(joinHistorySize, MessageTypeChatMessage, MessageTypeGameUpdate) {
+		if client.hides(m.msgType, m.sender) {
+			continue
+		}
+		if !client.enqueue(m.msgType, m.message) {
+			log.Printf("Client %s send queue full while sending room history", client.ID)
+			return
+		}
+	}
+}
+
+func (r *Room) sequenceRange() (oldest, latest uint64) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if len(r.history) > 0 {
+		oldest = r.history[0].seq
+	}
+	return oldest, r.seq
+}
+
+func (c *Client) handleAck(message Message) {
+	var data AckData
+	if err := json.Unmarshal(message.Data, &data); err != nil {
+		log.Printf("Error unmarshaling ack: %v", err)
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if data.Seq > c.acks[message.RoomID] {
+		c.acks[message.RoomID] = data.Seq
+	}
+}
+
+func (c *Client) handleResend(message Message) {
+	var data ResendData
+	if len(message.Data) > 0 {
+		if err := json.Unmarshal(message.Data, &data); err != nil {
+			log.Printf("Error unmarshaling resend request: %v", err)
+			return
+		}
+	}
+
+	c.mutex.RLock()
+	fromSeq, member := c.acks[message.RoomID], c.Rooms[message.RoomID]
+	c.mutex.RUnlock()
+
+	if data.FromSeq != nil {
+		fromSeq = *data.FromSeq
+	}
+
+	c.Hub.mutex.RLock()
+	room, exists := c.Hub.rooms[message.RoomID]
+	c.Hub.mutex.RUnlock()
+
+	if !exists || !member {
+		return
+	}
+
+	messages, ok := room.messagesAfter(fromSeq)
+	if !ok {
+		oldest, latest := room.sequenceRange()
+		data, _ := json.Marshal(ResendUnavailableData{
+			Error:     "history_unavailable",
+			OldestSeq: oldest,
+			LatestSeq: latest,
+		})
+		if err := c.Hub.SendToClient(c.ID, Message{
+			Type:      MessageTypeError,
+			RoomID:    message.RoomID,
+			PlayerID:  c.UserID,
+			Data:      data,
+			RequestID: message.RequestID,
+			Timestamp: time.Now(),
+		}); err != nil {
+			log.Printf("Error sending resend failure: %v", err)
+		}
+		return
+	}
+
+	for _, m := range messages {
+		if c.hides(m.msgType, m.sender) {
+			continue
+		}
+		if !c.enqueue(m.msgType, m.message) {
+			log.Printf("Client %s send queue full during resend", c.ID)
+			return
+		}
+	}
+}
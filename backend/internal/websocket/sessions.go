@@ -0,0 +1,165 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// Session policies decide what happens when a user opens several sockets.
+const (
+	// SessionPolicyMulti keeps every connection; room membership is shared by
+	// all of the user's devices.
+	SessionPolicyMulti = "multi"
+	// SessionPolicySingle keeps only the newest connection and closes the rest.
+	SessionPolicySingle = "single"
+)
+
+const (
+	closeLoggedInElsewhere = 4002
+	errorLoggedInElsewhere = "logged_in_elsewhere"
+)
+
+type SessionReplacedData struct {
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
+// replaceSessions closes the user's connections opened before the given time.
+// The caller must hold the hub lock.
+func (h *Hub) replaceSessions(userID uuid.UUID, connectedBefore time.Time) {
+	data, err := json.Marshal(ErrorData{Error: errorLoggedInElsewhere})
+	if err != nil {
+		log.Printf("Error marshaling session notice: %v", err)
+		return
+	}
+	notice, err := json.Marshal(Message{
+		Type:      MessageTypeError,
+		PlayerID:  userID,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Error marshaling session notice: %v", err)
+		return
+	}
+	closeFrame := websocket.FormatCloseMessage(closeLoggedInElsewhere, errorLoggedInElsewhere)
+
+	for _, client := range h.clients {
+		if client.UserID != userID || !client.connectedAt.Before(connectedBefore) {
+			continue
+		}
+
+		log.Printf("Closing client %s (User: %s): logged in elsewhere", client.ID, client.UserID)
+		client.enqueue(MessageTypeError, notice)
+		client.mutex.Lock()
+		client.closeFrame = closeFrame
+		client.mutex.Unlock()
+		h.removeClient(client)
+	}
+}
+
+// publishSessionReplaced asks other instances to close the user's older
+// connections. The caller must hold the hub lock.
+func (h *Hub) publishSessionReplaced(client *Client) {
+	data, err := json.Marshal(SessionReplacedData{ConnectedAt: client.connectedAt})
+	if err != nil {
+		log.Printf("Error marshaling session replacement: %v", err)
+		return
+	}
+
+	h.publish(BackplaneMessage{
+		UserID:  client.UserID,
+		Type:    MessageTypeSessionReplaced,
+		Message: data,
+	})
+}
+
+func (h *Hub) deliverSessionReplaced(msg BackplaneMessage) {
+	var data SessionReplacedData
+	if err := json.Unmarshal(msg.Message, &data); err != nil {
+		log.Printf("Error unmarshaling session replacement: %v", err)
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.replaceSessions(msg.UserID, data.ConnectedAt)
+}
+
+// joinSiblingRooms adds a new connection to the rooms the user's other
+// connections are in. The caller must hold the hub lock.
+func (h *Hub) joinSiblingRooms(client *Client) {
+	for _, sibling := range h.clients {
+		if sibling.ID == client.ID || sibling.UserID != client.UserID {
+			continue
+		}
+
+		sibling.mutex.RLock()
+		roomIDs := make([]string, 0, len(sibling.Rooms))
+		for roomID := range sibling.Rooms {
+			roomIDs = append(roomIDs, roomID)
+		}
+		sibling.mutex.RUnlock()
+
+		for _, roomID := range roomIDs {
+			room, exists := h.rooms[roomID]
+			if !exists {
+				continue
+			}
+
+			room.mutex.RLock()
+			_, member := room.Clients[client.ID]
+			role := RoomRolePlayer
+			if room.Spectators[sibling.ID] {
+				role = RoomRoleSpectator
+			}
+			room.mutex.RUnlock()
+
+			if !member {
+				h.addToRoom(client, room, role)
+				h.sendRoomHistory(client, room)
+			}
+		}
+	}
+}
+
+// addSiblings adds the user's other connections to a room one of them just
+// joined. The caller must hold the hub lock.
+func (h *Hub) addSiblings(client *Client, room *Room, role RoomRole) {
+	for _, sibling := range h.clients {
+		if sibling.ID == client.ID || sibling.UserID != client.UserID {
+			continue
+		}
+
+		room.mutex.RLock()
+		_, member := room.Clients[sibling.ID]
+		room.mutex.RUnlock()
+
+		if !member {
+			h.addToRoom(sibling, room, role)
+			h.sendRoomHistory(sibling, room)
+		}
+	}
+}
+
+// addToRoom records membership without admission checks. The caller must
+// hold the hub lock.
+func (h *Hub) addToRoom(client *Client, room *Room, role RoomRole) {
+	room.mutex.Lock()
+	room.Clients[client.ID] = client
+	if role == RoomRoleSpectator {
+		room.Spectators[client.ID] = true
+		h.touchSpectator(room.ID, client.ID)
+		h.spectatorsChanged[room.ID] = true
+	} else {
+		delete(room.Spectators, client.ID)
+	}
+	room.mutex.Unlock()
+
+	client.mutex.Lock()
+	client.Rooms[room.ID] = true
+	client.mutex.Unlock()
+}
@@ -0,0 +1,187 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	resumeKey       = "websocket:resume:%s" // user ID
+	resumeTTL       = 2 * time.Minute
+	reconnectReason = "reconnect"
+)
+
+type RoomMembership struct {
+	RoomID string   `json:"room_id"`
+	Role   RoomRole `json:"role"`
+}
+
+type SessionResumedData struct {
+	Rooms []RoomMembership `json:"rooms"`
+}
+
+// ResumeStore hands room memberships from an instance that is shutting down
+// to whichever instance the user reconnects to.
+type ResumeStore interface {
+	SaveRooms(userID uuid.UUID, rooms []RoomMembership) error
+	TakeRooms(userID uuid.UUID) ([]RoomMembership, error)
+}
+
+type RedisResumeStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisResumeStore(redisClient *redis.Client) *RedisResumeStore {
+	return &RedisResumeStore{redisClient: redisClient}
+}
+
+func (s *RedisResumeStore) SaveRooms(userID uuid.UUID, rooms []RoomMembership) error {
+	data, err := json.Marshal(rooms)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf(resumeKey, userID)
+	return s.redisClient.Set(context.Background(), key, data, resumeTTL).Err()
+}
+
+func (s *RedisResumeStore) TakeRooms(userID uuid.UUID) ([]RoomMembership, error) {
+	key := fmt.Sprintf(resumeKey, userID)
+	data, err := s.redisClient.GetDel(context.Background(), key).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rooms []RoomMembership
+	if err := json.Unmarshal([]byte(data), &rooms); err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+// SetResumeStore must be called before Run.
+func (h *Hub) SetResumeStore(store ResumeStore) {
+	h.resumeStore = store
+}
+
+// Shutdown saves every connected user's room memberships, asks clients to
+// reconnect elsewhere and waits for their pending messages to be flushed.
+// Room members are not told that the departing clients left, since they are
+// expected to come back on another instance.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mutex.Lock()
+	h.shuttingDown = true
+
+	sessions := make(map[uuid.UUID][]RoomMembership)
+	closeFrame := websocket.FormatCloseMessage(websocket.CloseServiceRestart, reconnectReason)
+
+	for clientID, client := range h.clients {
+		client.mutex.Lock()
+		for roomID := range client.Rooms {
+			role := RoomRolePlayer
+			if room, exists := h.rooms[roomID]; exists {
+				room.mutex.RLock()
+				if room.Spectators[clientID] {
+					role = RoomRoleSpectator
+				}
+				room.mutex.RUnlock()
+			}
+			sessions[client.UserID] = appendMembership(sessions[client.UserID], RoomMembership{RoomID: roomID, Role: role})
+		}
+		client.closeFrame = closeFrame
+		client.mutex.Unlock()
+
+		delete(h.clients, clientID)
+		client.send.close()
+	}
+
+	h.rooms = make(map[string]*Room)
+	h.updateGauges()
+	h.mutex.Unlock()
+
+	if h.resumeStore != nil {
+		for userID, rooms := range sessions {
+			if err := h.resumeStore.SaveRooms(userID, rooms); err != nil {
+				log.Printf("Error saving session for user %s: %v", userID, err)
+			}
+		}
+	}
+
+	log.Printf("Hub draining %d sessions", len(sessions))
+
+	done := make(chan struct{})
+	go func() {
+		h.writers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func appendMembership(rooms []RoomMembership, membership RoomMembership) []RoomMembership {
+	for i, existing := range rooms {
+		if existing.RoomID == membership.RoomID {
+			// Prefer the player role if any of the user's devices held it
+			if membership.Role == RoomRolePlayer {
+				rooms[i].Role = RoomRolePlayer
+			}
+			return rooms
+		}
+	}
+	return append(rooms, membership)
+}
+
+// resumeSession rejoins the rooms a user was in on an instance that shut down.
+func (h *Hub) resumeSession(client *Client) {
+	if h.resumeStore == nil {
+		return
+	}
+
+	rooms, err := h.resumeStore.TakeRooms(client.UserID)
+	if err != nil {
+		log.Printf("Error loading session for user %s: %v", client.UserID, err)
+		return
+	}
+	if len(rooms) == 0 {
+		return
+	}
+
+	resumed := make([]RoomMembership, 0, len(rooms))
+	for _, membership := range rooms {
+		if err := h.JoinRoom(client.ID, membership.RoomID, membership.Role); err != nil {
+			log.Printf("Error resuming room %s for user %s: %v", membership.RoomID, client.UserID, err)
+			continue
+		}
+		resumed = append(resumed, membership)
+	}
+
+	data, err := json.Marshal(SessionResumedData{Rooms: resumed})
+	if err != nil {
+		log.Printf("Error marshaling resumed session: %v", err)
+		return
+	}
+
+	if err := h.SendToClient(client.ID, Message{
+		Type:      MessageTypeSessionResumed,
+		PlayerID:  client.UserID,
+		Data:      data,
+		Timestamp: time.Now(),
+	}); err != nil {
+		log.Printf("Error sending resumed session: %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// MessageTypeSpectate is only sent over the backplane, to join a user's
+// connections on other instances to a room as spectators.
+const MessageTypeSpectate MessageType = "spectate"
+
+// spectatorsKey maps each spectating connection of a room to when it was
+// last seen. Entries share the presence TTL and refresh.
+const spectatorsKey = "websocket:spectators:%s" // room ID
+
+// spectatorFlushInterval is how often changed spectator counts are sent to
+// rooms and recorded on games.
+const spectatorFlushInterval = time.Second
+
+// SpectatorEntry is a spectating connection's latest sign of life.
+type SpectatorEntry struct {
+	RoomID   string
+	ClientID uuid.UUID
+	LastSeen time.Time
+}
+
+// SpectatorStore counts each room's spectators across instances.
+type SpectatorStore interface {
+	Touch(entries []SpectatorEntry) error
+	Remove(roomID string, clientID uuid.UUID) error
+	Count(roomIDs []string) (map[string]int, error)
+}
+
+// SpectatorRecorder records how many connections watch each game.
+type SpectatorRecorder interface {
+	RecordGameSpectators(counts map[uuid.UUID]int) error
+}
+
+type RedisSpectatorStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisSpectatorStore(redisClient *redis.Client) *RedisSpectatorStore {
+	return &RedisSpectatorStore{redisClient: redisClient}
+}
+
+func (s *RedisSpectatorStore) Touch(entries []SpectatorEntry) error {
+	ctx := context.Background()
+
+	_, err := s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, entry := range entries {
+			key := fmt.Sprintf(spectatorsKey, entry.RoomID)
+			pipe.HSet(ctx, key, entry.ClientID.String(), entry.LastSeen.Unix())
+			pipe.Expire(ctx, key, presenceTTL)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisSpectatorStore) Remove(roomID string, clientID uuid.UUID) error {
+	return s.redisClient.HDel(context.Background(), fmt.Sprintf(spectatorsKey, roomID), clientID.String()).Err()
+}
+
+// Count skips entries left behind by instances that stopped refreshing them.
+func (s *RedisSpectatorStore) Count(roomIDs []string) (map[string]int, error) {
+	ctx := context.Background()
+	counts := make(map[string]int, len(roomIDs))
+	if len(roomIDs) == 0 {
+		return counts, nil
+	}
+
+	cmds := make([]*redis.StringSliceCmd, len(roomIDs))
+	_, err := s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, roomID := range roomIDs {
+			cmds[i] = pipe.HVals(ctx, fmt.Sprintf(spectatorsKey, roomID))
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-presenceTTL).Unix()
+	for i, roomID := range roomIDs {
+		for _, value := range cmds[i].Val() {
+			seen, err := strconv.ParseInt(value, 10, 64)
+			if err == nil && seen >= cutoff {
+				counts[roomID]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// SetSpectatorStore must be called before Run. Without it spectator counts
+// only cover this instance.
+func (h *Hub) SetSpectatorStore(store SpectatorStore) {
+	h.spectators = store
+}
+
+// SetSpectatorRecorder must be called before Run.
+func (h *Hub) SetSpectatorRecorder(recorder SpectatorRecorder) {
+	h.spectatorRecorder = recorder
+}
+
+// SpectatorCounts returns how many connections spectate each room.
+func (h *Hub) SpectatorCounts(roomIDs []string) map[string]int {
+	if h.spectators != nil {
+		counts, err := h.spectators.Count(roomIDs)
+		if err == nil {
+			return counts
+		}
+		log.Printf("Error counting spectators: %v", err)
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	counts := make(map[string]int, len(roomIDs))
+	for _, roomID := range roomIDs {
+		if room, exists := h.rooms[roomID]; exists {
+			room.mutex.RLock()
+			counts[roomID] = len(room.Spectators)
+			room.mutex.RUnlock()
+		}
+	}
+	return counts
+}
+
+// Spectate joins every connection of the user, on every instance, to the
+// room as a spectator. The caller checks that the user may watch it.
+func (h *Hub) Spectate(userID uuid.UUID, roomID string) {
+	h.publish(BackplaneMessage{
+		Type:   MessageTypeSpectate,
+		RoomID: roomID,
+		UserID: userID,
+	})
+	h.spectateLocal(userID, roomID)
+}
+
+func (h *Hub) deliverSpectate(msg BackplaneMessage) {
+	h.spectateLocal(msg.UserID, msg.RoomID)
+}
+
+func (h *Hub) spectateLocal(userID uuid.UUID, roomID string) {
+	h.mutex.RLock()
+	var clients []*Client
+	for _, client := range h.clients {
+		if client.UserID == userID {
+			clients = append(clients, client)
+		}
+	}
+	h.mutex.RUnlock()
+
+	for _, client := range clients {
+		err := h.JoinRoom(client.ID, roomID, RoomRoleSpectator)
+		if err == ErrSpectatorsFull || err == ErrSpectatingNotAllowed {
+			client.replyError(Message{Type: MessageTypeJoinRoom, RoomID: roomID}, err.Error(), "")
+		} else if err != nil {
+			log.Printf("Error joining client %s to room %s as a spectator: %v", client.ID, roomID, err)
+		}
+	}
+}
+
+// flushSpectators tells rooms whose spectators changed how many there are
+// now on every instance, and records the counts on their games. Spectators
+// are written to the store in the background, so a room is only flushed
+// once a full interval has passed since it changed.
+func (h *Hub) flushSpectators() {
+	h.mutex.Lock()
+	settled := h.spectatorsSettled
+	h.spectatorsSettled = h.spectatorsChanged
+	h.spectatorsChanged = make(map[string]bool)
+	h.mutex.Unlock()
+
+	if len(settled) == 0 {
+		return
+	}
+
+	roomIDs := make([]string, 0, len(settled))
+	for roomID := range settled {
+		roomIDs = append(roomIDs, roomID)
+	}
+	counts := h.SpectatorCounts(roomIDs)
+
+	games := make(map[uuid.UUID]int, len(roomIDs))
+	for _, roomID := range roomIDs {
+		count := counts[roomID]
+		h.broadcastSpectatorCount(roomID, count)
+		if gameID, err := uuid.Parse(roomID); err == nil {
+			games[gameID] = count
+		}
+	}
+
+	if h.spectatorRecorder != nil && len(games) > 0 {
+		if err := h.spectatorRecorder.RecordGameSpectators(games); err != nil {
+			log.Printf("Error recording spectator counts: %v", err)
+		}
+	}
+}
+
+func (h *Hub) broadcastSpectatorCount(roomID string, count int) {
+	data, err := json.Marshal(SpectatorCountData{Spectators: count})
+	if err != nil {
+		log.Printf("Error marshaling spectator count: %v", err)
+		return
+	}
+
+	h.BroadcastToRoom(roomID, Message{
+		Type:      MessageTypeSpectatorCount,
+		RoomID:    roomID,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+}
+
+// refreshSpectators records every local spectator's last activity.
+func (h *Hub) refreshSpectators() {
+	if h.spectators == nil {
+		return
+	}
+
+	now := time.Now()
+	var entries []SpectatorEntry
+	h.mutex.RLock()
+	for roomID, room := range h.rooms {
+		room.mutex.RLock()
+		for clientID := range room.Spectators {
+			entries = append(entries, SpectatorEntry{RoomID: roomID, ClientID: clientID, LastSeen: now})
+		}
+		room.mutex.RUnlock()
+	}
+	h.mutex.RUnlock()
+
+	if len(entries) == 0 {
+		return
+	}
+	go func() {
+		if err := h.spectators.Touch(entries); err != nil {
+			log.Printf("Error refreshing spectators: %v", err)
+		}
+	}()
+}
+
+func (h *Hub) touchSpectator(roomID string, clientID uuid.UUID) {
+	if h.spectators == nil {
+		return
+	}
+
+	entry := SpectatorEntry{RoomID: roomID, ClientID: clientID, LastSeen: time.Now()}
+	go func() {
+		if err := h.spectators.Touch([]SpectatorEntry{entry}); err != nil {
+			log.Printf("Error recording spectator in room %s: %v", roomID, err)
+		}
+	}()
+}
+
+func (h *Hub) removeSpectator(roomID string, clientID uuid.UUID) {
+	if h.spectators == nil {
+		return
+	}
+
+	go func() {
+		if err := h.spectators.Remove(roomID, clientID); err != nil {
+			log.Printf("Error removing spectator from room %s: %v", roomID, err)
+		}
+	}()
+}
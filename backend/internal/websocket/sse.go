@@ -0,0 +1,178 @@
+package websocket
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+)
+
+// Transports a client can be connected over.
+const (
+	TransportWebSocket = "websocket"
+	TransportSSE       = "sse"
+)
+
+type SSEConnectedData struct {
+	ClientID uuid.UUID `json:"client_id"`
+}
+
+type SSECloseData struct {
+	Code   int    `json:"code"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// HandleSSE serves the fallback transport for networks that block
+// WebSockets. Server messages are streamed as Server-Sent Events; the first
+// event carries the client ID to use with HandleSSEMessage.
+func (h *Hub) HandleSSE(c *gin.Context) {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return
+	}
+
+	if h.ShuttingDown() {
+		apierror.Respond(c, http.StatusServiceUnavailable, "shutting_down", "Server is shutting down")
+		return
+	}
+
+	client := h.newClient(c, userID, jsonCodec{})
+	client.transport = TransportSSE
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // disable proxy buffering
+	c.Status(http.StatusOK)
+
+	h.writers.Add(1)
+	h.register <- client
+	defer func() {
+		h.unregister <- client
+		h.writers.Done()
+	}()
+
+	client.ssePump(c)
+}
+
+// HandleSSEMessage accepts a client-to-server message for an SSE client.
+// Replies, including errors, arrive on the event stream.
+func (h *Hub) HandleSSEMessage(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	clientID, err := uuid.Parse(c.Param("clientId"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid_client_id", "Invalid client ID")
+		return
+	}
+
+	h.mutex.RLock()
+	client, exists := h.clients[clientID]
+	h.mutex.RUnlock()
+	if !exists || client.transport != TransportSSE || client.UserID != userID.(uuid.UUID) {
+		apierror.Respond(c, http.StatusNotFound, "stream_not_found", "Stream not found")
+		return
+	}
+
+	frame, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxMessageSize))
+	if err != nil {
+		apierror.Respond(c, http.StatusRequestEntityTooLarge, "message_too_large", "Message too large")
+		return
+	}
+
+	if !client.receive(frame) {
+		h.unregister <- client
+		apierror.Respond(c, http.StatusTooManyRequests, apierror.CodeRateLimited, "Rate limit exceeded")
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ssePump writes queued messages to the event stream until the client goes
+// away or is closed by the hub.
+func (c *Client) ssePump(ctx *gin.Context) {
+	rc := http.NewResponseController(ctx.Writer)
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	write := func(event string) bool {
+		// Each write gets its own deadline; the server's WriteTimeout
+		// would otherwise end the stream
+		if err := rc.SetWriteDeadline(time.Now().Add(10 * time.Second)); err != nil {
+			log.Printf("Error setting SSE write deadline: %v", err)
+		}
+		if _, err := io.WriteString(ctx.Writer, event); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	connected, _ := json.Marshal(SSEConnectedData{ClientID: c.ID})
+	if !write(sseEvent("connected", connected)) {
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+
+		case <-c.send.ready:
+			messages, closed := c.send.pop()
+			for _, m := range messages {
+				if !write(sseEvent("", m.data)) {
+					return
+				}
+			}
+			if closed {
+				c.mutex.RLock()
+				closeFrame := c.closeFrame
+				c.mutex.RUnlock()
+				data, _ := json.Marshal(closeData(closeFrame))
+				write(sseEvent("close", data))
+				return
+			}
+
+		case <-ticker.C:
+			if !write(": ping\n\n") {
+				return
+			}
+			// SSE has no pongs, so a successful write is the only sign of life
+			c.mutex.Lock()
+			c.LastSeen = time.Now()
+			c.mutex.Unlock()
+		}
+	}
+}
+
+func sseEvent(event string, data []byte) string {
+	if event == "" {
+		return fmt.Sprintf("data: %s\n\n", data)
+	}
+	return fmt.Sprintf("event: %s\ndata: %s\n\n", event, data)
+}
+
+// closeData decodes a WebSocket close frame so SSE clients get the same code
+// and reason.
+func closeData(closeFrame []byte) SSECloseData {
+	if len(closeFrame) < 2 {
+		return SSECloseData{Code: 1000}
+	}
+	return SSECloseData{
+		Code:   int(binary.BigEndian.Uint16(closeFrame)),
+		Reason: string(closeFrame[2:]),
+	}
+}
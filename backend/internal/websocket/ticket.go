@@ -0,0 +1,167 @@
+package websocket
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/szaher/vibeboard/backend/internal/apierror"
+)
+
+// ticketKey holds a ticket by the SHA-256 of its value, so tickets cannot
+// be lifted from a Redis dump.
+const ticketKey = "websocket:ticket:%s" // ticket hash
+
+// Ticket is what a connection ticket stands in for: the user who asked for
+// it and their access token's scope and expiry.
+type Ticket struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Scope     string    `json:"scope,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// TicketStore issues one-time tickets that authenticate a WebSocket or SSE
+// connection without putting the access token in its URL.
+type TicketStore interface {
+	Issue(ticket Ticket) (string, error)
+	// Redeem consumes a ticket, returning nil if it is unknown, expired or
+	// already used.
+	Redeem(value string) (*Ticket, error)
+}
+
+type RedisTicketStore struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+}
+
+func NewRedisTicketStore(redisClient *redis.Client, ttl time.Duration) *RedisTicketStore {
+	return &RedisTicketStore{redisClient: redisClient, ttl: ttl}
+}
+
+func (s *RedisTicketStore) Issue(ticket Ticket) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	value := hex.EncodeToString(buf)
+
+	data, err := json.Marshal(ticket)
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf(ticketKey, hashTicket(value))
+	if err := s.redisClient.Set(context.Background(), key, data, s.ttl).Err(); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+func (s *RedisTicketStore) Redeem(value string) (*Ticket, error) {
+	key := fmt.Sprintf(ticketKey, hashTicket(value))
+	data, err := s.redisClient.GetDel(context.Background(), key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ticket Ticket
+	if err := json.Unmarshal(data, &ticket); err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func hashTicket(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// SetTicketStore must be called before Run. Without it connections can
+// only authenticate with an Authorization header.
+func (h *Hub) SetTicketStore(store TicketStore) {
+	h.tickets = store
+}
+
+// HandleTicket issues a ticket for the authenticated user, to be passed as
+// ?ticket= when opening a connection within HUB_TICKET_TTL.
+func (h *Hub) HandleTicket(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+	if h.tickets == nil {
+		apierror.Respond(c, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Connection tickets are unavailable")
+		return
+	}
+
+	ticket := Ticket{UserID: userID.(uuid.UUID), Username: c.GetString("username"), Scope: c.GetString("scope")}
+	if expiresAt, ok := c.Get("tokenExpiresAt"); ok {
+		ticket.ExpiresAt = expiresAt.(time.Time)
+	}
+
+	value, err := h.tickets.Issue(ticket)
+	if err != nil {
+		log.Printf("Error issuing connection ticket for user %s: %v", ticket.UserID, err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to issue ticket")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"ticket":     value,
+		"expires_in": int(h.cfg.TicketTTL.Seconds()),
+	})
+}
+
+// authenticate identifies the user opening a connection, consuming the
+// ?ticket= if one was given. It responds and returns false if the
+// connection must be refused.
+func (h *Hub) authenticate(c *gin.Context) (uuid.UUID, bool) {
+	value := c.Query("ticket")
+	if value == "" {
+		userID, exists := c.Get("userID")
+		if !exists {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+			return uuid.Nil, false
+		}
+		return userID.(uuid.UUID), true
+	}
+
+	if h.tickets == nil {
+		apierror.Respond(c, http.StatusUnauthorized, "invalid_ticket", "Invalid or expired ticket")
+		return uuid.Nil, false
+	}
+	ticket, err := h.tickets.Redeem(value)
+	if err != nil {
+		log.Printf("Error redeeming connection ticket: %v", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to redeem ticket")
+		return uuid.Nil, false
+	}
+	if ticket == nil || (!ticket.ExpiresAt.IsZero() && time.Now().After(ticket.ExpiresAt)) {
+		apierror.Respond(c, http.StatusUnauthorized, "invalid_ticket", "Invalid or expired ticket")
+		return uuid.Nil, false
+	}
+
+	c.Set("userID", ticket.UserID)
+	c.Set("username", ticket.Username)
+	if ticket.Scope != "" {
+		c.Set("scope", ticket.Scope)
+	}
+	if !ticket.ExpiresAt.IsZero() {
+		c.Set("tokenExpiresAt", ticket.ExpiresAt)
+	}
+	return ticket.UserID, true
+}
@@ -3,29 +3,60 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Redis       RedisConfig
+	JWT         JWTConfig
+	Game        GameConfig
+	Hub         HubConfig
+	Lobby       LobbyConfig
+	Leaderboard LeaderboardConfig
+	Retention   RetentionConfig
+	API         APIConfig
+	Account     AccountConfig
+	Mail        MailConfig
+	OAuth       OAuthConfig
+	Password    PasswordConfig
 }
 
 type ServerConfig struct {
-	Port         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	Port            string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	ShutdownTimeout time.Duration
+	// TrustedProxies are the CIDRs whose X-Forwarded-For headers are
+	// believed when resolving a client's IP
+	TrustedProxies []string
 }
 
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Name     string
-	SSLMode  string
+	// Driver is "postgres" or "sqlite"; SQLite is for local development
+	// and tests, and uses SQLitePath instead of the connection settings
+	Driver     string
+	SQLitePath string // ":memory:" for a throwaway database
+	Host       string
+	Port       string
+	User       string
+	Password   string
+	Name       string
+	SSLMode    string
+	// AutoMigrate applies pending migrations when the server starts; turn
+	// it off to run `server migrate up` as a separate deploy step
+	AutoMigrate bool
+	// Connection pool limits; keep MaxOpenConns times the number of
+	// servers under the database's max_connections
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// ReplicaDSNs are read replicas for listings, leaderboards and profiles;
+	// each gets its own pool with the limits above
+	ReplicaDSNs []string
 }
 
 type RedisConfig struct {
@@ -39,22 +70,219 @@ type JWTConfig struct {
 	Secret          string
 	AccessTokenTTL  time.Duration
 	RefreshTokenTTL time.Duration
+	// KeyFile is an RSA or Ed25519 private key to sign with instead of
+	// Secret. RetiredKeyFiles are earlier keys whose tokens are still
+	// accepted until they expire.
+	KeyFile         string
+	RetiredKeyFiles []string
+	// Issuer and Audience are stamped on tokens and required of tokens
+	// presented to this API; empty values are neither set nor checked
+	Issuer   string
+	Audience string
+	// ScopedTokenMaxTTL caps how long scoped tokens for kiosks and
+	// integrations last
+	ScopedTokenMaxTTL time.Duration
+}
+
+type GameConfig struct {
+	TurnTimeout   time.Duration
+	StarterPolicy string // "random", "alternate" or "rating"
+	RatingSystem  string // "elo" or "glicko2"
+	// RatingSystems overrides RatingSystem for game types, as
+	// "type:system" entries
+	RatingSystems []string
+	// AbandonTimeout is how long a player may be disconnected from an
+	// in-progress game before it is forfeited
+	AbandonTimeout time.Duration
+	// SnapshotInterval is how many moves apart a game's state is
+	// snapshotted; zero disables snapshots
+	SnapshotInterval int
+	// CorrespondenceInterval is how often correspondence games' move
+	// deadlines are checked; zero disables them
+	CorrespondenceInterval time.Duration
+	// CorrespondenceReminder is how long before a move deadline the player
+	// to move is reminded; zero disables reminders
+	CorrespondenceReminder time.Duration
+	// TurnReminderInterval is how far into a live game's turn, and how often
+	// after, the player to move is reminded; zero disables reminders
+	TurnReminderInterval time.Duration
+	// TurnReminderLimit caps the reminders of a live turn
+	TurnReminderLimit int
+	// SeasonInterval is how often ended seasons are checked for rewards to
+	// grant; zero disables it
+	SeasonInterval time.Duration
+	// SeasonResetFactor is how much of a player's distance from 1000 their
+	// season rating keeps into the next season
+	SeasonResetFactor float64
+	// TournamentReminder is how long before each tournament round its
+	// players are reminded; zero disables reminders
+	TournamentReminder time.Duration
+	RatingDecay        RatingDecayConfig
+}
+
+// RatingDecayConfig controls how inactive players' ratings decay.
+type RatingDecayConfig struct {
+	Mode string // "none", "rating" or "deviation"
+	// After is how long since their last rated game players start to decay
+	After time.Duration
+	// Interval is how often an inactive player decays
+	Interval time.Duration
+	// Points is how far a rating decays each interval, never below Floor
+	Points int
+	Floor  int
+}
+
+type HubConfig struct {
+	Backplane             string // "redis" or "none"
+	ChatRateLimit         RateLimitConfig
+	MoveRateLimit         RateLimitConfig
+	DefaultRateLimit      RateLimitConfig
+	RateLimitWarnAt       int // violations per minute before the client is warned
+	RateLimitDisconnectAt int // violations per minute before the client is disconnected
+	MaxSpectators         int
+	Compression           bool
+	CompressionLevel      int // flate level, 1 (fastest) to 9 (smallest)
+	CompressionThreshold  int // minimum payload size in bytes to compress
+	PersistDirectMessages bool
+	PersistRoomChat       bool
+	AllowedOrigins        []string // origins allowed to open WebSocket connections
+	AllowAnyOrigin        bool     // disables the origin check; development only
+	ReadyTimeout          time.Duration
+	SessionPolicy         string        // "multi" or "single"
+	StallTimeout          time.Duration // how long a client's send queue may stay full before it is disconnected
+	TicketTTL             time.Duration // how long a connection ticket may wait to be used
+	GameChangeEvents      bool          // follow game changes from other instances via Postgres LISTEN
+}
+
+type LobbyConfig struct {
+	JoinCodeTTL      time.Duration
+	JoinCodeAttempts int // code redemptions per user per minute
+	JoinCodesPerHour int // private games a user may create per hour
+	// MatchConfirmTimeout is how long matched players have to accept; zero
+	// creates games without asking
+	MatchConfirmTimeout time.Duration
+}
+
+// LeaderboardConfig controls how often the ranked rating leaderboards are
+// rebuilt.
+type LeaderboardConfig struct {
+	// RefreshInterval is the scheduled refresh; zero leaves only the
+	// refreshes after games complete
+	RefreshInterval time.Duration
+	// RefreshDelay batches the results of games completing close together
+	// into one refresh
+	RefreshDelay time.Duration
+}
+
+// RetentionConfig controls how long old data is kept. A zero age keeps
+// that kind of data forever.
+type RetentionConfig struct {
+	// Interval is how often the janitor runs; zero disables it
+	Interval time.Duration
+	// WaitingGames is how long a game nobody joined stays open
+	WaitingGames time.Duration
+	// ChatMessages is how long room chat is kept, unless it was reported
+	// and the report is still open
+	ChatMessages time.Duration
+	// DirectMessages is how long direct messages are kept
+	DirectMessages time.Duration
+	// LoginEvents is how long login history is kept
+	LoginEvents time.Duration
+	// TrustedDevices is how long an unused device stays trusted
+	TrustedDevices time.Duration
+}
+
+// APIConfig holds the HTTP API's request quotas, shared across instances
+// through Redis. A zero limit disables that quota.
+type APIConfig struct {
+	RateLimitWindow time.Duration
+	UserRateLimit   int // requests per window for each authenticated user
+	IPRateLimit     int // requests per window for each IP on unauthenticated routes
+}
+
+type AccountConfig struct {
+	// EmailVerificationURL is linked from the mail that confirms a new
+	// address, with the token appended as ?token=
+	EmailVerificationURL string
+	EmailVerificationTTL time.Duration
+	UsernameCooldown     time.Duration // minimum time between username changes
+	// LoginLinkURL is linked from magic-link login mail, with the token
+	// appended as ?token=
+	LoginLinkURL      string
+	LoginLinkTTL      time.Duration
+	LoginLinkInterval time.Duration // minimum time between login links sent to one address
+	// VerifyNewDevices makes password logins from a new device or an
+	// improbable location confirm a mailed code; when off they are only
+	// recorded
+	VerifyNewDevices  bool
+	LoginChallengeTTL time.Duration
+	// CountryHeader is a header set by the CDN or proxy with the client's
+	// ISO country code, e.g. CF-IPCountry; empty skips location checks
+	CountryHeader string
+	// ImpossibleTravelWindow is how soon after a login one from another
+	// country is considered improbable
+	ImpossibleTravelWindow time.Duration
+}
+
+type MailConfig struct {
+	SMTPHost string // empty logs mail instead of sending it
+	SMTPPort string
+	Username string
+	Password string
+	From     string
+}
+
+type PasswordConfig struct {
+	MinLength           int
+	MinCharacterClasses int // of lowercase, uppercase, digits and symbols
+	// BreachAPIURL is a k-anonymity range API that the first five
+	// characters of a password's SHA-1 are appended to; empty checks the
+	// offline list only
+	BreachAPIURL   string
+	BreachTimeout  time.Duration
+	BreachListFile string // extra breached passwords for the offline list, one per line
+}
+
+// OAuthConfig holds social login credentials. Providers without a client
+// ID are disabled.
+type OAuthConfig struct {
+	GoogleClientID     string
+	GoogleClientSecret string
+	AppleClientID      string // the Services ID
+	AppleTeamID        string
+	AppleKeyID         string
+	ApplePrivateKey    string // PEM contents of the .p8 key
+}
+
+type RateLimitConfig struct {
+	Rate  float64 // messages per second
+	Burst int
 }
 
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:         getEnv("SERVER_PORT", "8181"),
-			ReadTimeout:  getDurationEnv("SERVER_READ_TIMEOUT", 15*time.Second),
-			WriteTimeout: getDurationEnv("SERVER_WRITE_TIMEOUT", 15*time.Second),
+			Port:            getEnv("SERVER_PORT", "8181"),
+			ReadTimeout:     getDurationEnv("SERVER_READ_TIMEOUT", 15*time.Second),
+			WriteTimeout:    getDurationEnv("SERVER_WRITE_TIMEOUT", 15*time.Second),
+			ShutdownTimeout: getDurationEnv("SERVER_SHUTDOWN_TIMEOUT", 15*time.Second),
+			TrustedProxies:  getListEnv("SERVER_TRUSTED_PROXIES", []string{"127.0.0.0/8", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", ""),
-			Name:     getEnv("DB_NAME", "vibe_arcade"),
-			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+			Driver:          getEnv("DB_DRIVER", "postgres"),
+			SQLitePath:      getEnv("DB_SQLITE_PATH", "vibeboard.db"),
+			Host:            getEnv("DB_HOST", "localhost"),
+			Port:            getEnv("DB_PORT", "5432"),
+			User:            getEnv("DB_USER", "postgres"),
+			Password:        getEnv("DB_PASSWORD", ""),
+			Name:            getEnv("DB_NAME", "vibe_arcade"),
+			SSLMode:         getEnv("DB_SSL_MODE", "disable"),
+			AutoMigrate:     getBoolEnv("DB_AUTO_MIGRATE", true),
+			MaxOpenConns:    getIntEnv("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    getIntEnv("DB_MAX_IDLE_CONNS", 10),
+			ConnMaxLifetime: getDurationEnv("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+			ConnMaxIdleTime: getDurationEnv("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+			ReplicaDSNs:     getListEnv("DB_REPLICA_DSNS", nil),
 		},
 		Redis: RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
@@ -63,9 +291,123 @@ func Load() *Config {
 			DB:       getIntEnv("REDIS_DB", 0),
 		},
 		JWT: JWTConfig{
-			Secret:          getEnv("JWT_SECRET", "your-secret-key"),
-			AccessTokenTTL:  getDurationEnv("JWT_ACCESS_TTL", 15*time.Minute),
-			RefreshTokenTTL: getDurationEnv("JWT_REFRESH_TTL", 24*time.Hour*7),
+			Secret:            getEnv("JWT_SECRET", "your-secret-key"),
+			AccessTokenTTL:    getDurationEnv("JWT_ACCESS_TTL", 15*time.Minute),
+			RefreshTokenTTL:   getDurationEnv("JWT_REFRESH_TTL", 24*time.Hour*7),
+			KeyFile:           getEnv("JWT_KEY_FILE", ""),
+			RetiredKeyFiles:   getListEnv("JWT_RETIRED_KEY_FILES", nil),
+			Issuer:            getEnv("JWT_ISSUER", "vibe-arcade"),
+			Audience:          getEnv("JWT_AUDIENCE", "vibe-arcade-api"),
+			ScopedTokenMaxTTL: getDurationEnv("JWT_SCOPED_TOKEN_MAX_TTL", 30*24*time.Hour),
+		},
+		Game: GameConfig{
+			TurnTimeout:            getDurationEnv("GAME_TURN_TIMEOUT", 10*time.Minute),
+			StarterPolicy:          getEnv("GAME_STARTER_POLICY", "random"),
+			RatingSystem:           getEnv("GAME_RATING_SYSTEM", "elo"),
+			RatingSystems:          getListEnv("GAME_RATING_SYSTEMS", nil),
+			AbandonTimeout:         getDurationEnv("GAME_ABANDON_TIMEOUT", 5*time.Minute),
+			SnapshotInterval:       getIntEnv("GAME_SNAPSHOT_INTERVAL", 20),
+			CorrespondenceInterval: getDurationEnv("GAME_CORRESPONDENCE_INTERVAL", time.Minute),
+			CorrespondenceReminder: getDurationEnv("GAME_CORRESPONDENCE_REMINDER", 24*time.Hour),
+			TurnReminderInterval:   getDurationEnv("GAME_TURN_REMINDER_INTERVAL", 3*time.Minute),
+			TurnReminderLimit:      getIntEnv("GAME_TURN_REMINDER_LIMIT", 2),
+			SeasonInterval:         getDurationEnv("GAME_SEASON_INTERVAL", time.Minute),
+			SeasonResetFactor:      getFloatEnv("GAME_SEASON_RESET_FACTOR", 0.5),
+			TournamentReminder:     getDurationEnv("GAME_TOURNAMENT_REMINDER", 10*time.Minute),
+			RatingDecay: RatingDecayConfig{
+				Mode:     getEnv("GAME_RATING_DECAY", "none"),
+				After:    getDurationEnv("GAME_RATING_DECAY_AFTER", 30*24*time.Hour),
+				Interval: getDurationEnv("GAME_RATING_DECAY_INTERVAL", 7*24*time.Hour),
+				Points:   getIntEnv("GAME_RATING_DECAY_POINTS", 15),
+				Floor:    getIntEnv("GAME_RATING_DECAY_FLOOR", 1500),
+			},
+		},
+		Hub: HubConfig{
+			Backplane: getEnv("HUB_BACKPLANE", "redis"),
+			ChatRateLimit: RateLimitConfig{
+				Rate:  getFloatEnv("HUB_CHAT_RATE", 1),
+				Burst: getIntEnv("HUB_CHAT_BURST", 5),
+			},
+			MoveRateLimit: RateLimitConfig{
+				Rate:  getFloatEnv("HUB_MOVE_RATE", 2),
+				Burst: getIntEnv("HUB_MOVE_BURST", 5),
+			},
+			DefaultRateLimit: RateLimitConfig{
+				Rate:  getFloatEnv("HUB_DEFAULT_RATE", 5),
+				Burst: getIntEnv("HUB_DEFAULT_BURST", 20),
+			},
+			RateLimitWarnAt:       getIntEnv("HUB_RATE_LIMIT_WARN_AT", 3),
+			RateLimitDisconnectAt: getIntEnv("HUB_RATE_LIMIT_DISCONNECT_AT", 20),
+			MaxSpectators:         getIntEnv("HUB_MAX_SPECTATORS", 50),
+			Compression:           getBoolEnv("HUB_COMPRESSION", true),
+			CompressionLevel:      getIntEnv("HUB_COMPRESSION_LEVEL", 1),
+			CompressionThreshold:  getIntEnv("HUB_COMPRESSION_THRESHOLD", 512),
+			PersistDirectMessages: getBoolEnv("HUB_PERSIST_DIRECT_MESSAGES", true),
+			PersistRoomChat:       getBoolEnv("HUB_PERSIST_ROOM_CHAT", true),
+			AllowedOrigins:        getListEnv("HUB_ALLOWED_ORIGINS", nil),
+			AllowAnyOrigin:        getBoolEnv("HUB_ALLOW_ANY_ORIGIN", false),
+			ReadyTimeout:          getDurationEnv("HUB_READY_TIMEOUT", 30*time.Second),
+			SessionPolicy:         getEnv("HUB_SESSION_POLICY", "multi"),
+			StallTimeout:          getDurationEnv("HUB_STALL_TIMEOUT", 15*time.Second),
+			TicketTTL:             getDurationEnv("HUB_TICKET_TTL", 30*time.Second),
+			GameChangeEvents:      getBoolEnv("HUB_GAME_CHANGE_EVENTS", true),
+		},
+		Lobby: LobbyConfig{
+			JoinCodeTTL:         getDurationEnv("LOBBY_JOIN_CODE_TTL", 15*time.Minute),
+			JoinCodeAttempts:    getIntEnv("LOBBY_JOIN_CODE_ATTEMPTS", 10),
+			JoinCodesPerHour:    getIntEnv("LOBBY_JOIN_CODES_PER_HOUR", 20),
+			MatchConfirmTimeout: getDurationEnv("LOBBY_MATCH_CONFIRM_TIMEOUT", 0),
+		},
+		Leaderboard: LeaderboardConfig{
+			RefreshInterval: getDurationEnv("LEADERBOARD_REFRESH_INTERVAL", 5*time.Minute),
+			RefreshDelay:    getDurationEnv("LEADERBOARD_REFRESH_DELAY", 10*time.Second),
+		},
+		Retention: RetentionConfig{
+			Interval:       getDurationEnv("RETENTION_INTERVAL", time.Hour),
+			WaitingGames:   getDurationEnv("RETENTION_WAITING_GAMES", 24*time.Hour),
+			ChatMessages:   getDurationEnv("RETENTION_CHAT_MESSAGES", 90*24*time.Hour),
+			DirectMessages: getDurationEnv("RETENTION_DIRECT_MESSAGES", 0),
+			LoginEvents:    getDurationEnv("RETENTION_LOGIN_EVENTS", 180*24*time.Hour),
+			TrustedDevices: getDurationEnv("RETENTION_TRUSTED_DEVICES", 180*24*time.Hour),
+		},
+		API: APIConfig{
+			RateLimitWindow: getDurationEnv("API_RATE_LIMIT_WINDOW", time.Minute),
+			UserRateLimit:   getIntEnv("API_USER_RATE_LIMIT", 300),
+			IPRateLimit:     getIntEnv("API_IP_RATE_LIMIT", 60),
+		},
+		Account: AccountConfig{
+			EmailVerificationURL:   getEnv("ACCOUNT_EMAIL_VERIFICATION_URL", "http://localhost:3000/verify-email"),
+			EmailVerificationTTL:   getDurationEnv("ACCOUNT_EMAIL_VERIFICATION_TTL", 24*time.Hour),
+			UsernameCooldown:       getDurationEnv("ACCOUNT_USERNAME_COOLDOWN", 30*24*time.Hour),
+			LoginLinkURL:           getEnv("ACCOUNT_LOGIN_LINK_URL", "http://localhost:3000/magic-link"),
+			LoginLinkTTL:           getDurationEnv("ACCOUNT_LOGIN_LINK_TTL", 15*time.Minute),
+			LoginLinkInterval:      getDurationEnv("ACCOUNT_LOGIN_LINK_INTERVAL", time.Minute),
+			VerifyNewDevices:       getBoolEnv("ACCOUNT_VERIFY_NEW_DEVICES", true),
+			LoginChallengeTTL:      getDurationEnv("ACCOUNT_LOGIN_CHALLENGE_TTL", 10*time.Minute),
+			CountryHeader:          getEnv("ACCOUNT_COUNTRY_HEADER", ""),
+			ImpossibleTravelWindow: getDurationEnv("ACCOUNT_IMPOSSIBLE_TRAVEL_WINDOW", 2*time.Hour),
+		},
+		Mail: MailConfig{
+			SMTPHost: getEnv("MAIL_SMTP_HOST", ""),
+			SMTPPort: getEnv("MAIL_SMTP_PORT", "587"),
+			Username: getEnv("MAIL_SMTP_USERNAME", ""),
+			Password: getEnv("MAIL_SMTP_PASSWORD", ""),
+			From:     getEnv("MAIL_FROM", "Vibe Arcade <no-reply@vibearcade.local>"),
+		},
+		Password: PasswordConfig{
+			MinLength:           getIntEnv("PASSWORD_MIN_LENGTH", 8),
+			MinCharacterClasses: getIntEnv("PASSWORD_MIN_CHARACTER_CLASSES", 2),
+			BreachAPIURL:        getEnv("PASSWORD_BREACH_API_URL", "https://api.pwnedpasswords.com/range/"),
+			BreachTimeout:       getDurationEnv("PASSWORD_BREACH_TIMEOUT", 2*time.Second),
+			BreachListFile:      getEnv("PASSWORD_BREACH_LIST_FILE", ""),
+		},
+		OAuth: OAuthConfig{
+			GoogleClientID:     getEnv("OAUTH_GOOGLE_CLIENT_ID", ""),
+			GoogleClientSecret: getEnv("OAUTH_GOOGLE_CLIENT_SECRET", ""),
+			AppleClientID:      getEnv("OAUTH_APPLE_CLIENT_ID", ""),
+			AppleTeamID:        getEnv("OAUTH_APPLE_TEAM_ID", ""),
+			AppleKeyID:         getEnv("OAUTH_APPLE_KEY_ID", ""),
+			ApplePrivateKey:    getEnv("OAUTH_APPLE_PRIVATE_KEY", ""),
 		},
 	}
 }
@@ -86,6 +428,40 @@ func getIntEnv(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getBoolEnv(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getListEnv parses a comma-separated list, dropping empty entries.
+func getListEnv(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
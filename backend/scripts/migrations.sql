@@ -1,79 +0,0 @@
--- Create database schema for Vibe Arcade
-
--- Users table
-CREATE TABLE IF NOT EXISTS users (
-    id UUID PRIMARY KEY,
-    email VARCHAR(255) UNIQUE NOT NULL,
-    username VARCHAR(50) UNIQUE NOT NULL,
-    password_hash VARCHAR(255) NOT NULL,
-    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
-    is_active BOOLEAN NOT NULL DEFAULT true
-);
-
--- User stats table
-CREATE TABLE IF NOT EXISTS user_stats (
-    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
-    games_played INTEGER NOT NULL DEFAULT 0,
-    games_won INTEGER NOT NULL DEFAULT 0,
-    games_lost INTEGER NOT NULL DEFAULT 0,
-    rating INTEGER NOT NULL DEFAULT 1000,
-    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
-);
-
--- Games table
-CREATE TABLE IF NOT EXISTS games (
-    id UUID PRIMARY KEY,
-    game_type VARCHAR(20) NOT NULL CHECK (game_type IN ('dominoes', 'chess')),
-    status VARCHAR(20) NOT NULL CHECK (status IN ('waiting', 'in_progress', 'completed', 'abandoned')),
-    player1_id UUID NOT NULL REFERENCES users(id),
-    player2_id UUID REFERENCES users(id),
-    winner_id UUID REFERENCES users(id),
-    current_turn UUID REFERENCES users(id),
-    game_state JSONB NOT NULL DEFAULT '{}',
-    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
-    started_at TIMESTAMP,
-    ended_at TIMESTAMP
-);
-
--- Moves table
-CREATE TABLE IF NOT EXISTS moves (
-    id UUID PRIMARY KEY,
-    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
-    player_id UUID NOT NULL REFERENCES users(id),
-    move_data JSONB NOT NULL,
-    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-    is_valid BOOLEAN NOT NULL DEFAULT true
-);
-
--- Indexes for better performance
-CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
-CREATE INDEX IF NOT EXISTS idx_users_username ON users(username);
-CREATE INDEX IF NOT EXISTS idx_games_status ON games(status);
-CREATE INDEX IF NOT EXISTS idx_games_type ON games(game_type);
-CREATE INDEX IF NOT EXISTS idx_games_player1 ON games(player1_id);
-CREATE INDEX IF NOT EXISTS idx_games_player2 ON games(player2_id);
-CREATE INDEX IF NOT EXISTS idx_games_created_at ON games(created_at);
-CREATE INDEX IF NOT EXISTS idx_moves_game_id ON moves(game_id);
-CREATE INDEX IF NOT EXISTS idx_moves_player_id ON moves(player_id);
-CREATE INDEX IF NOT EXISTS idx_moves_created_at ON moves(created_at);
-
--- Function to update updated_at timestamp
-CREATE OR REPLACE FUNCTION update_updated_at_column()
-RETURNS TRIGGER AS $$
-BEGIN
-    NEW.updated_at = NOW();
-    RETURN NEW;
-END;
-$$ language 'plpgsql';
-
--- Triggers to automatically update updated_at
-CREATE TRIGGER update_users_updated_at BEFORE UPDATE ON users
-    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
-
-CREATE TRIGGER update_user_stats_updated_at BEFORE UPDATE ON user_stats
-    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
-
-CREATE TRIGGER update_games_updated_at BEFORE UPDATE ON games
-    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
\ No newline at end of file